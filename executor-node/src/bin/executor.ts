@@ -11,6 +11,7 @@
  * - job_id (string, optional)
  * - parent_run_id (string, optional)
  * - job (any, required) - the job payload
+ * - seed_state (object, optional) - initial script state, exposed as ctx.seedState
  *
  * Events are written to stdout as length-prefixed msgpack frames.
  * Stderr is used for executor diagnostics (not protocol).
@@ -27,8 +28,9 @@ import { unlinkSync } from 'node:fs'
 import type { ProxyEndpoint } from '@pithecene-io/quarry-sdk'
 import { chromiumArgs } from '../browser-args.js'
 import { evaluateIdlePoll, type IdlePollState } from '../browser-idle.js'
-import { errorMessage, execute, parseRunMeta } from '../executor.js'
+import { errorMessage, execute, parseRunMeta, parseSeedState } from '../executor.js'
 import { AckReader } from '../ipc/ack-reader.js'
+import { negotiateFrameCompression, setFrameCompression } from '../ipc/frame.js'
 import { drainStdout } from '../ipc/sink.js'
 import { installStdoutGuard } from '../ipc/stdout-guard.js'
 import { type LoadedScript, loadScript, ScriptLoadError } from '../loader.js'
@@ -458,6 +460,14 @@ async function main(): Promise<never> {
   }
   const job = inputObj.job
 
+  // Parse optional seed state (--seed-state), kept apart from the job
+  let seedState: Record<string, unknown> | undefined
+  try {
+    seedState = parseSeedState(inputObj)
+  } catch (err) {
+    fatalError(`parsing seed state: ${errorMessage(err)}`)
+  }
+
   // Parse optional proxy
   let proxy: ProxyEndpoint | undefined
   try {
@@ -477,6 +487,9 @@ async function main(): Promise<never> {
       ? inputObj.browser_ws_endpoint
       : undefined
 
+  // Compress large frames when the runtime advertises a codec it can decode
+  setFrameCompression(negotiateFrameCompression(inputObj))
+
   // Start AckReader on stdin (phase 2 of two-phase stdin).
   // stdin remains open after metadata read for runtime→executor ack frames.
   const ackReader = new AckReader(process.stdin)
@@ -486,6 +499,7 @@ async function main(): Promise<never> {
   const result = await execute({
     scriptPath,
     job,
+    seedState,
     run,
     proxy,
     storagePartition,
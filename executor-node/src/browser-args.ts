@@ -9,3 +9,57 @@ export function chromiumArgs(extra: string[] = []): string[] {
   }
   return args
 }
+
+/** A proxy server parsed from a Chromium --proxy-server switch. */
+export type ProxyServer = {
+  readonly protocol: 'http' | 'https' | 'socks5'
+  readonly host: string
+  readonly port: number
+}
+
+const DEFAULT_PROXY_PORTS: Record<ProxyServer['protocol'], number> = {
+  http: 80,
+  https: 443,
+  socks5: 1080
+}
+
+/**
+ * Parse the proxy server from a browser's command-line switches.
+ * Returns null when there is no --proxy-server switch (direct connection)
+ * and undefined when its value is not an http, https or socks5 server.
+ * Per-scheme rule lists (http=a:8080;https=b:8443) report their first entry.
+ */
+export function parseProxyServerArg(args: readonly string[]): ProxyServer | null | undefined {
+  const prefix = '--proxy-server='
+  const arg = args.find((a) => a.startsWith(prefix))
+  if (arg === undefined) {
+    return null
+  }
+  let value = arg.slice(prefix.length).split(';')[0]?.trim() ?? ''
+  // "<url-scheme>=<proxy>" names the traffic the rule applies to, not the proxy scheme
+  const eq = value.indexOf('=')
+  if (eq !== -1 && !value.slice(0, eq).includes('://')) {
+    value = value.slice(eq + 1)
+  }
+  if (!value.includes('://')) {
+    value = `http://${value}`
+  }
+  let url: URL
+  try {
+    url = new URL(value)
+  } catch {
+    return undefined
+  }
+  const protocol = url.protocol.slice(0, -1)
+  if (protocol !== 'http' && protocol !== 'https' && protocol !== 'socks5') {
+    return undefined
+  }
+  if (url.hostname === '') {
+    return undefined
+  }
+  return {
+    protocol,
+    host: url.hostname,
+    port: url.port ? Number(url.port) : DEFAULT_PROXY_PORTS[protocol]
+  }
+}
@@ -15,6 +15,9 @@ export type IdlePollAction =
  *
  * Caller supplies the fetch result and current state;
  * function returns the action and updated state.
+ *
+ * An idleTimeoutMs of 0 (or less) disables idle shutdown; the server then
+ * runs until signalled (persistent mode, used by `quarry browser start`).
  */
 export function evaluateIdlePoll(
   fetchResult: { ok: true; activePages: number } | { ok: false },
@@ -41,6 +44,14 @@ export function evaluateIdlePoll(
     }
   }
 
+  // Persistent mode — never shut down on idle
+  if (config.idleTimeoutMs <= 0) {
+    return {
+      type: 'continue',
+      nextState: { idleStartedAt: null, consecutiveFailures: 0 }
+    }
+  }
+
   // No active pages — start or continue idle countdown
   const idleStartedAt = state.idleStartedAt ?? now
   if (now - idleStartedAt >= config.idleTimeoutMs) {
@@ -34,6 +34,7 @@ import {
   type TerminalSignal
 } from '@pithecene-io/quarry-sdk'
 import type { Browser, BrowserContext, LaunchOptions, Page } from 'puppeteer'
+import { type ProxyServer, parseProxyServerArg } from './browser-args.js'
 import type { AckReader } from './ipc/ack-reader.js'
 import type { ProxyEndpointRedactedFrame, RunResultOutcome } from './ipc/frame.js'
 import { ObservingSink, type SinkState } from './ipc/observing-sink.js'
@@ -214,6 +215,8 @@ export type ExecutorConfig<Job = unknown> = {
   readonly job: Job
   /** Run metadata */
   readonly run: RunMeta
+  /** Optional initial script state (seed_state input field), exposed as ctx.seedState */
+  readonly seedState?: Record<string, unknown>
   /** Output stream for IPC frames (defaults to process.stdout) */
   readonly output?: Writable
   /** Write function for IPC frame data. When provided, bypasses `output.write()`
@@ -306,6 +309,26 @@ export function parseRunMeta(input: unknown): RunMeta {
   return run
 }
 
+/**
+ * Parse the optional seed_state field from raw input.
+ *
+ * @param input - Raw input object
+ * @returns The seed state object, or undefined when absent or null
+ * @throws Error if seed_state is present but not a plain object
+ */
+export function parseSeedState(
+  input: Record<string, unknown>
+): Record<string, unknown> | undefined {
+  const raw = input.seed_state
+  if (raw === undefined || raw === null) {
+    return undefined
+  }
+  if (typeof raw !== 'object' || Array.isArray(raw)) {
+    throw new Error('seed_state must be an object')
+  }
+  return raw as Record<string, unknown>
+}
+
 /**
  * Build Puppeteer launch options with proxy configuration.
  * Per CONTRACT_PROXY.md: Apply proxy host/port/protocol at browser launch.
@@ -341,17 +364,56 @@ function buildPuppeteerLaunchOptions(
 async function emitRunResult(
   stdioSink: StdioSink,
   outcome: ExecutionOutcome,
-  proxy: ProxyEndpoint | undefined
+  proxyUsed: ProxyEndpointRedactedFrame | undefined
 ): Promise<void> {
   try {
     const runResultOutcome = toRunResultOutcome(outcome)
-    const proxyUsed = proxy ? redactProxy(proxy) : undefined
     await stdioSink.writeRunResult(runResultOutcome, proxyUsed)
   } catch {
     // Best effort — don't fail the run if run_result emission fails
   }
 }
 
+/**
+ * Read the proxy a connected browser was launched with.
+ * Uses CDP Browser.getBrowserCommandLine, which Chromium only answers when
+ * started with --enable-automation (Puppeteer launches are). Returns null
+ * for a direct connection and undefined when the proxy cannot be determined.
+ */
+async function detectBrowserProxy(browser: Browser): Promise<ProxyServer | null | undefined> {
+  try {
+    const session = await browser.target().createCDPSession()
+    try {
+      const { arguments: args } = await session.send('Browser.getBrowserCommandLine')
+      return parseProxyServerArg(args)
+    } finally {
+      await session.detach().catch(() => {
+        // Session may already be gone with the connection
+      })
+    }
+  } catch {
+    return undefined
+  }
+}
+
+/**
+ * Build proxy_used for a connected browser from the proxy it reports.
+ * Launch args do not apply in connect mode, so the configured endpoint is
+ * not assumed; only its page.authenticate credentials (the username) are.
+ */
+function connectedProxyUsed(
+  server: ProxyServer | null | undefined,
+  proxy: ProxyEndpoint | undefined
+): ProxyEndpointRedactedFrame | undefined {
+  if (!server) {
+    return undefined
+  }
+  if (proxy?.username && proxy.password) {
+    return { ...server, username: proxy.username }
+  }
+  return server
+}
+
 /**
  * Safely close a resource, ignoring errors.
  */
@@ -409,6 +471,13 @@ function redactProxy(proxy: ProxyEndpoint): ProxyEndpointRedactedFrame {
   }
 }
 
+/**
+ * Whether two endpoints dial the same proxy server, regardless of credentials.
+ */
+export function sameProxyServer(a: ProxyEndpoint, b: ProxyEndpoint): boolean {
+  return a.protocol === b.protocol && a.host === b.host && a.port === b.port
+}
+
 /**
  * Execute a script with full lifecycle management.
  *
@@ -450,6 +519,15 @@ export async function execute<Job = unknown>(config: ExecutorConfig<Job>): Promi
   let browser: Browser | null = null
   let browserContext: BrowserContext | null = null
   let page: Page | null = null
+  // Endpoint in use; replaced by proxy_update frames during the run
+  let activeProxy: ProxyEndpoint | undefined = config.proxy
+  // Proxy the connected browser reports (browser-ws-endpoint only)
+  let connectedProxy: ProxyServer | null | undefined
+  // proxy_used for run_result: what the browser actually dials
+  const proxyUsed = (): ProxyEndpointRedactedFrame | undefined =>
+    config.browserWSEndpoint
+      ? connectedProxyUsed(connectedProxy, activeProxy)
+      : activeProxy && redactProxy(activeProxy)
   let script: LoadedScript<Job> | null = null
   let ctx: ReturnType<typeof createContext<Job>> | null = null
   let scriptThrew = false
@@ -534,7 +612,7 @@ export async function execute<Job = unknown>(config: ExecutorConfig<Job>): Promi
       } catch (err) {
         const message = errorMessage(err)
         const crashOutcome: ExecutionOutcome = { status: 'crash', message }
-        await emitRunResult(stdioSink, crashOutcome, config.proxy)
+        await emitRunResult(stdioSink, crashOutcome, proxyUsed())
         return { outcome: crashOutcome, terminalEmitted: false }
       }
 
@@ -550,7 +628,7 @@ export async function execute<Job = unknown>(config: ExecutorConfig<Job>): Promi
           status: 'crash',
           message: `prepare hook must return { action: 'continue' | 'skip' }, got: ${String(prepareResult)}`
         }
-        await emitRunResult(stdioSink, crashOutcome, config.proxy)
+        await emitRunResult(stdioSink, crashOutcome, proxyUsed())
         return { outcome: crashOutcome, terminalEmitted: false }
       }
 
@@ -567,7 +645,7 @@ export async function execute<Job = unknown>(config: ExecutorConfig<Job>): Promi
           // Sink failure — determineOutcome will handle
         }
         const result = determineOutcome(sink)
-        await emitRunResult(stdioSink, result.outcome, config.proxy)
+        await emitRunResult(stdioSink, result.outcome, proxyUsed())
         return result
       }
 
@@ -581,7 +659,7 @@ export async function execute<Job = unknown>(config: ExecutorConfig<Job>): Promi
           status: 'crash',
           message: `prepare hook returned unrecognized action: ${(prepareResult as Record<string, unknown>).action}`
         }
-        await emitRunResult(stdioSink, crashOutcome, config.proxy)
+        await emitRunResult(stdioSink, crashOutcome, proxyUsed())
         return { outcome: crashOutcome, terminalEmitted: false }
       }
     }
@@ -592,6 +670,7 @@ export async function execute<Job = unknown>(config: ExecutorConfig<Job>): Promi
       const puppeteer = await getVanillaPuppeteer(config.scriptPath)
       browser = await puppeteer.connect({ browserWSEndpoint: config.browserWSEndpoint })
       isConnected = true
+      connectedProxy = await detectBrowserProxy(browser)
     } else {
       // Launch mode: use puppeteer-extra with stealth/adblocker plugins
       const plugins: PluginConfig = {
@@ -613,9 +692,35 @@ export async function execute<Job = unknown>(config: ExecutorConfig<Job>): Promi
       })
     }
 
+    // Mid-run rotation (proxy_update from the runtime). Chromium fixes the
+    // proxy server at launch, so only credentials can be swapped in place;
+    // this suits rotating gateways that pick the exit IP per username.
+    // Updates for another server are ignored so proxy_used stays truthful.
+    config.ackReader?.onProxyUpdate((update) => {
+      if (activeProxy && !sameProxyServer(activeProxy, update.endpoint)) {
+        process.stderr.write(
+          `[quarry] ignoring proxy_update to ${update.endpoint.host}:${update.endpoint.port}: proxy server is fixed at browser launch\n`
+        )
+        return
+      }
+      activeProxy = update.endpoint
+      const currentPage = page
+      if (currentPage && update.endpoint.username && update.endpoint.password) {
+        currentPage
+          .authenticate({
+            username: update.endpoint.username,
+            password: update.endpoint.password
+          })
+          .catch(() => {
+            // Page may be closing; the next request reports the auth failure
+          })
+      }
+    })
+
     // 4. Create context (single instance, reused throughout lifecycle)
     ctx = createContext<Job>({
       job: effectiveJob,
+      seedState: config.seedState,
       run: config.run,
       page,
       browser,
@@ -711,7 +816,7 @@ export async function execute<Job = unknown>(config: ExecutorConfig<Job>): Promi
 
     // 10. Emit run_result control frame per CONTRACT_IPC.md
     // This is emitted exactly once, after terminal event emission attempt
-    await emitRunResult(stdioSink, result.outcome, config.proxy)
+    await emitRunResult(stdioSink, result.outcome, proxyUsed())
 
     return result
   } catch (err) {
@@ -720,7 +825,7 @@ export async function execute<Job = unknown>(config: ExecutorConfig<Job>): Promi
     const crashOutcome: ExecutionOutcome = { status: 'crash', message }
 
     // Emit run_result even for executor-level crashes if possible
-    await emitRunResult(stdioSink, crashOutcome, config.proxy)
+    await emitRunResult(stdioSink, crashOutcome, proxyUsed())
 
     return {
       outcome: crashOutcome,
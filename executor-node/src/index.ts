@@ -29,6 +29,7 @@ export {
   LENGTH_PREFIX_SIZE,
   MAX_CHUNK_SIZE,
   MAX_FRAME_SIZE,
+  MAX_FRAME_SIZE_CEILING,
   MAX_PAYLOAD_SIZE,
   // Sink
   ObservingSink,
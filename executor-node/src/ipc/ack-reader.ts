@@ -4,11 +4,13 @@
  * After the executor reads JSON metadata (phase 1), stdin remains open for
  * the runtime to send file_write_ack frames back. AckReader attaches to
  * stdin and matches incoming acks to pending promises by write_id.
+ * proxy_update frames on the same stream are passed to the handler
+ * registered with onProxyUpdate.
  *
  * @module
  */
 import type { Readable } from 'node:stream'
-import { decodeFileWriteAck } from './frame.js'
+import { decodeFileWriteAck, decodeProxyUpdate, type ProxyUpdateFrame } from './frame.js'
 
 /** Minimum frame size: 4-byte length prefix + at least 1 byte payload. */
 const LENGTH_PREFIX_SIZE = 4
@@ -36,6 +38,7 @@ export class AckReader {
   /** True after at least one ack frame has been successfully dispatched. */
   private receivedAnyAck = false
   private readonly stream: Readable
+  private proxyUpdateHandler: ((update: ProxyUpdateFrame) => void) | undefined
 
   constructor(stream: Readable) {
     this.stream = stream
@@ -66,6 +69,14 @@ export class AckReader {
     return !this.noAckSupport
   }
 
+  /**
+   * Register a handler for proxy_update frames (mid-run proxy rotation).
+   * Replaces any previous handler. Updates received with no handler are dropped.
+   */
+  onProxyUpdate(handler: (update: ProxyUpdateFrame) => void): void {
+    this.proxyUpdateHandler = handler
+  }
+
   /**
    * Start reading frames from the stream.
    * Attaches data/end/error listeners.
@@ -138,13 +149,15 @@ export class AckReader {
     }
   }
 
-  /** Decode and dispatch a single ack frame. */
+  /** Decode and dispatch a single frame (ack or proxy update). */
   private processPayload(payload: Uint8Array): void {
     let ack: ReturnType<typeof decodeFileWriteAck>
     try {
       ack = decodeFileWriteAck(payload)
     } catch {
-      // Unknown or malformed frame — ignore (future frame types may appear)
+      // Not an ack: proxy_update, or an unknown/malformed frame — ignore
+      // the latter (future frame types may appear)
+      this.dispatchProxyUpdate(payload)
       return
     }
 
@@ -164,6 +177,18 @@ export class AckReader {
     }
   }
 
+  /** Decode a proxy_update frame and pass it to the registered handler. */
+  private dispatchProxyUpdate(payload: Uint8Array): void {
+    if (!this.proxyUpdateHandler) return
+    let update: ProxyUpdateFrame
+    try {
+      update = decodeProxyUpdate(payload)
+    } catch {
+      return
+    }
+    this.proxyUpdateHandler(update)
+  }
+
   /** Resolve all pending promises (fire-and-forget fallback). */
   private resolveAll(): void {
     for (const [, entry] of this.pending) {
@@ -21,8 +21,14 @@
  * @remarks Node.js only. Uses Buffer for transport efficiency.
  */
 
+import * as zlib from 'node:zlib'
 import { decode as msgpackDecode, encode as msgpackEncode } from '@msgpack/msgpack'
-import type { ArtifactId, EventEnvelope } from '@pithecene-io/quarry-sdk'
+import {
+  type ArtifactId,
+  CONTRACT_VERSION,
+  type EventEnvelope,
+  type ProxyEndpoint
+} from '@pithecene-io/quarry-sdk'
 
 /**
  * Maximum frame size in bytes (16 MiB), including length prefix.
@@ -35,6 +41,96 @@ export const MAX_FRAME_SIZE = 16 * 1024 * 1024
  */
 export const MAX_PAYLOAD_SIZE = MAX_FRAME_SIZE - 4
 
+/**
+ * Hard ceiling (256 MiB) for a frame size override via QUARRY_MAX_FRAME_BYTES.
+ */
+export const MAX_FRAME_SIZE_CEILING = 256 * 1024 * 1024
+
+/**
+ * Resolve the maximum payload size from QUARRY_MAX_FRAME_BYTES, set by the
+ * runtime's --max-frame-bytes. The value includes the length prefix.
+ * Missing, zero or out-of-range values fall back to MAX_PAYLOAD_SIZE; the
+ * runtime validates the flag before spawning the executor.
+ *
+ * @param env - Environment to read (defaults to process.env)
+ * @returns Maximum payload size in bytes
+ */
+export function resolveMaxPayloadSize(env: NodeJS.ProcessEnv = process.env): number {
+  const raw = env.QUARRY_MAX_FRAME_BYTES
+  if (raw === undefined || raw === '') {
+    return MAX_PAYLOAD_SIZE
+  }
+  const frameBytes = Number(raw)
+  if (
+    !Number.isInteger(frameBytes) ||
+    frameBytes <= LENGTH_PREFIX_SIZE ||
+    frameBytes > MAX_FRAME_SIZE_CEILING
+  ) {
+    return MAX_PAYLOAD_SIZE
+  }
+  return frameBytes - LENGTH_PREFIX_SIZE
+}
+
+/**
+ * Maximum payload size in effect for this process.
+ */
+const activeMaxPayloadSize = resolveMaxPayloadSize()
+
+/**
+ * Length prefix flag (high bit) marking a zstd-compressed payload. The low
+ * 31 bits are the compressed length. Only set after the runtime advertises
+ * zstd in the run input (`ipc.compression`).
+ */
+export const FRAME_FLAG_ZSTD = 0x80000000
+
+/**
+ * Payloads smaller than this are never compressed (1 KiB); the saving does
+ * not pay for the CPU.
+ */
+export const COMPRESSION_MIN_PAYLOAD_SIZE = 1024
+
+/**
+ * Frame compression codec negotiated with the runtime.
+ */
+export type FrameCompression = 'zstd'
+
+/**
+ * Compression codec in effect for this process (undefined = uncompressed).
+ */
+let activeCompression: FrameCompression | undefined
+
+/**
+ * Pick the frame compression codec from the run input. The runtime lists
+ * the codecs its decoder accepts in `ipc.compression`; an older runtime
+ * omits the field, and this Node.js may lack zstd, in which case frames
+ * stay uncompressed.
+ *
+ * @param input - Parsed run input from stdin
+ * @returns The codec to use, or undefined for uncompressed frames
+ */
+export function negotiateFrameCompression(
+  input: Record<string, unknown>
+): FrameCompression | undefined {
+  const ipc = input.ipc
+  if (ipc === null || typeof ipc !== 'object') {
+    return undefined
+  }
+  const codecs = (ipc as Record<string, unknown>).compression
+  if (!Array.isArray(codecs) || !codecs.includes('zstd')) {
+    return undefined
+  }
+  return typeof zlib.zstdCompressSync === 'function' ? 'zstd' : undefined
+}
+
+/**
+ * Enable or disable frame compression for all subsequently encoded frames.
+ *
+ * @param codec - Negotiated codec, or undefined to send uncompressed frames
+ */
+export function setFrameCompression(codec: FrameCompression | undefined): void {
+  activeCompression = codec
+}
+
 /**
  * Maximum artifact chunk size in bytes (8 MiB).
  * This is the limit on raw bytes before msgpack encoding.
@@ -75,6 +171,8 @@ export type ArtifactChunkFrame = {
   readonly is_last: boolean
   /** Raw binary data (msgpack bin type) */
   readonly data: Uint8Array
+  /** CRC-32C (Castagnoli) of data, checked by `quarry run --verify-artifacts` */
+  readonly crc32c?: number
 }
 
 /**
@@ -119,6 +217,8 @@ export type RunResultFrame = {
   readonly outcome: RunResultOutcome
   /** Proxy endpoint used (redacted, no password) */
   readonly proxy_used?: ProxyEndpointRedactedFrame
+  /** Contract version the executor was built against */
+  readonly executor_version?: string
 }
 
 /**
@@ -152,12 +252,25 @@ export type FileWriteAckFrame = {
   readonly error?: string
 }
 
+/**
+ * Proxy update frame sent by runtime to executor via stdin in response to
+ * a rotate_proxy event. Carries the endpoint to use for the rest of the run.
+ */
+export type ProxyUpdateFrame = {
+  readonly type: 'proxy_update'
+  /** Newly selected endpoint, including credentials */
+  readonly endpoint: ProxyEndpoint
+  /** Echo of the rotate_proxy reason, if any */
+  readonly reason?: string
+}
+
 /**
  * Union of all frame payload types for decoding.
  * Discriminate using type field:
  * - 'artifact_chunk' → ArtifactChunkFrame
  * - 'file_write' → FileWriteFrame (sidecar file upload)
  * - 'file_write_ack' → FileWriteAckFrame (runtime→executor ack)
+ * - 'proxy_update' → ProxyUpdateFrame (runtime→executor proxy rotation)
  * - 'run_result' → RunResultFrame (control, not counted in seq)
  * - other (item, log, etc.) → EventEnvelope
  */
@@ -167,6 +280,7 @@ export type Frame =
   | RunResultFrame
   | FileWriteFrame
   | FileWriteAckFrame
+  | ProxyUpdateFrame
 
 /**
  * Error thrown when a frame exceeds the maximum size.
@@ -185,15 +299,36 @@ export class FrameSizeError extends Error {
  * Encode data into a framed buffer with length prefix.
  *
  * @param payload - The payload bytes to frame
+ * @param maxPayloadSize - Payload limit (defaults to the QUARRY_MAX_FRAME_BYTES
+ *   override, else MAX_PAYLOAD_SIZE)
+ * @param compression - Compression codec (defaults to the negotiated codec)
  * @returns Buffer containing length prefix + payload
- * @throws FrameSizeError if payload exceeds MAX_PAYLOAD_SIZE
+ * @throws FrameSizeError if payload exceeds maxPayloadSize
  *
  * @remarks
- * The total frame size (prefix + payload) is bounded by MAX_FRAME_SIZE.
+ * The total frame size (prefix + payload) is bounded by MAX_FRAME_SIZE unless
+ * the runtime overrides it with --max-frame-bytes. The limit applies to the
+ * uncompressed payload. With compression, payloads of at least
+ * COMPRESSION_MIN_PAYLOAD_SIZE are sent zstd-compressed (FRAME_FLAG_ZSTD)
+ * when that makes them smaller.
  */
-export function encodeFrame(payload: Uint8Array): Buffer {
-  if (payload.length > MAX_PAYLOAD_SIZE) {
-    throw new FrameSizeError(payload.length, MAX_PAYLOAD_SIZE)
+export function encodeFrame(
+  payload: Uint8Array,
+  maxPayloadSize: number = activeMaxPayloadSize,
+  compression: FrameCompression | undefined = activeCompression
+): Buffer {
+  if (payload.length > maxPayloadSize) {
+    throw new FrameSizeError(payload.length, maxPayloadSize)
+  }
+
+  if (compression === 'zstd' && payload.length >= COMPRESSION_MIN_PAYLOAD_SIZE) {
+    const compressed = zlib.zstdCompressSync(payload)
+    if (compressed.length < payload.length) {
+      const frame = Buffer.allocUnsafe(LENGTH_PREFIX_SIZE + compressed.length)
+      frame.writeUInt32BE((compressed.length | FRAME_FLAG_ZSTD) >>> 0, 0)
+      frame.set(compressed, LENGTH_PREFIX_SIZE)
+      return frame
+    }
   }
 
   const frame = Buffer.allocUnsafe(LENGTH_PREFIX_SIZE + payload.length)
@@ -258,12 +393,40 @@ export function encodeArtifactChunkFrame(
     artifact_id: artifactId,
     seq,
     is_last: isLast,
-    data
+    data,
+    crc32c: crc32c(data)
   }
   const payload = msgpackEncode(frame)
   return encodeFrame(payload)
 }
 
+/**
+ * CRC-32C (Castagnoli, reflected polynomial 0x82F63B78) lookup table.
+ */
+const CRC32C_TABLE = (() => {
+  const table = new Uint32Array(256)
+  for (let i = 0; i < 256; i++) {
+    let c = i
+    for (let k = 0; k < 8; k++) {
+      c = c & 1 ? (c >>> 1) ^ 0x82f63b78 : c >>> 1
+    }
+    table[i] = c >>> 0
+  }
+  return table
+})()
+
+/**
+ * Compute the CRC-32C (Castagnoli) checksum of data, as an unsigned 32-bit
+ * integer. Matches Go's crc32.Checksum with crc32.Castagnoli.
+ */
+export function crc32c(data: Uint8Array): number {
+  let crc = 0xffffffff
+  for (let i = 0; i < data.length; i++) {
+    crc = CRC32C_TABLE[(crc ^ data[i]) & 0xff] ^ (crc >>> 8)
+  }
+  return (crc ^ 0xffffffff) >>> 0
+}
+
 /**
  * Metadata for a single artifact chunk (without data).
  * Used by chunk iterators to avoid data copying.
@@ -334,7 +497,7 @@ export function* encodeArtifactChunks(
  * Per CONTRACT_IPC.md, this is a control frame that:
  * - Is emitted once after terminal event emission attempt
  * - Does NOT affect seq ordering (not counted as an event)
- * - Contains outcome and optional proxy_used (redacted)
+ * - Contains outcome, optional proxy_used (redacted) and executor_version
  *
  * @param outcome - The run outcome
  * @param proxyUsed - Optional redacted proxy endpoint (no password)
@@ -348,7 +511,8 @@ export function encodeRunResultFrame(
   const frame: RunResultFrame = {
     type: 'run_result',
     outcome,
-    ...(proxyUsed && { proxy_used: proxyUsed })
+    ...(proxyUsed && { proxy_used: proxyUsed }),
+    executor_version: CONTRACT_VERSION
   }
   const payload = msgpackEncode(frame)
   return encodeFrame(payload)
@@ -410,3 +574,22 @@ export function decodeFileWriteAck(payload: Uint8Array): FileWriteAckFrame {
     ...(decoded.error != null && { error: decoded.error as string })
   }
 }
+
+/**
+ * Decode a proxy update frame from a msgpack payload.
+ *
+ * @param payload - Raw msgpack payload (without length prefix)
+ * @returns Decoded ProxyUpdateFrame
+ * @throws Error if payload is not a valid proxy_update frame
+ */
+export function decodeProxyUpdate(payload: Uint8Array): ProxyUpdateFrame {
+  const decoded = msgpackDecode(payload) as Record<string, unknown>
+  if (decoded.type !== 'proxy_update') {
+    throw new Error(`Expected proxy_update frame, got type: ${String(decoded.type)}`)
+  }
+  return {
+    type: 'proxy_update',
+    endpoint: decoded.endpoint as ProxyEndpoint,
+    ...(decoded.reason != null && { reason: decoded.reason as string })
+  }
+}
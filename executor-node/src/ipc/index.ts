@@ -12,7 +12,9 @@ export {
   type ArtifactChunkType,
   type ChunkMeta,
   ChunkValidationError,
+  COMPRESSION_MIN_PAYLOAD_SIZE,
   calculateChunks,
+  crc32c,
   decodeFileWriteAck,
   encodeArtifactChunkFrame,
   encodeArtifactChunks,
@@ -21,12 +23,18 @@ export {
   encodeFrame,
   type FileWriteAckFrame,
   type FileWriteFrame,
+  FRAME_FLAG_ZSTD,
   type Frame,
+  type FrameCompression,
   FrameSizeError,
   LENGTH_PREFIX_SIZE,
   MAX_CHUNK_SIZE,
   MAX_FRAME_SIZE,
-  MAX_PAYLOAD_SIZE
+  MAX_FRAME_SIZE_CEILING,
+  MAX_PAYLOAD_SIZE,
+  negotiateFrameCompression,
+  resolveMaxPayloadSize,
+  setFrameCompression
 } from './frame.js'
 export {
   ObservingSink,
@@ -40,11 +40,14 @@ export function parseStoragePartition(
     return undefined
   }
 
+  // hour is optional (hourly partitions only); ignore it unless well-formed
+  const hour = typeof sp.hour === 'string' && sp.hour !== '' ? sp.hour : undefined
   return {
     dataset: sp.dataset as string,
     source: sp.source as string,
     category: sp.category as string,
     day: sp.day as string,
+    ...(hour !== undefined && { hour }),
     run_id: sp.run_id as string
   }
 }
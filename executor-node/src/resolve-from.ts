@@ -2,7 +2,7 @@
  * ESM resolve hook for --resolve-from support.
  *
  * Registers a module.register() hook that retries bare-specifier resolution
- * with a fallback parentURL pointing at the --resolve-from directory.
+ * with a fallback parentURL pointing at each --resolve-from directory in turn.
  * This preserves ESM import conditions (not CJS require conditions).
  *
  * @module
@@ -17,13 +17,13 @@
 export const resolveFromHookCode = `
   import { pathToFileURL } from 'node:url';
 
-  let fallbackParentURL;
+  let fallbackParentURLs = [];
 
   export function initialize(data) {
-    // Construct a file:// URL pointing into the resolve-from directory.
+    // Construct a file:// URL pointing into each resolve-from directory.
     // nextResolve uses parentURL as the resolution base, so ESM
     // "imports" conditions are applied (not CJS "require" conditions).
-    fallbackParentURL = pathToFileURL(data.resolveFrom + '/noop.js').href;
+    fallbackParentURLs = data.resolveFrom.map((dir) => pathToFileURL(dir + '/noop.js').href);
   }
 
   export async function resolve(specifier, context, nextResolve) {
@@ -36,22 +36,26 @@ export const resolveFromHookCode = `
     try {
       return await nextResolve(specifier, context);
     } catch (defaultErr) {
-      // Retry with parentURL pointing at --resolve-from directory.
-      // This preserves ESM import conditions (package.json "exports"
-      // with "import" key) unlike CJS require-based resolution.
-      try {
-        const result = await nextResolve(specifier, {
-          ...context,
-          parentURL: fallbackParentURL,
-        });
-        process.stderr.write(
-          'quarry: resolved "' + specifier + '" via --resolve-from fallback\\n'
-        );
-        return result;
-      } catch {
-        // Re-throw the original error if fallback also fails
-        throw defaultErr;
+      // Retry with parentURL pointing at each --resolve-from directory,
+      // first match wins. This preserves ESM import conditions
+      // (package.json "exports" with "import" key) unlike CJS
+      // require-based resolution.
+      for (const fallbackParentURL of fallbackParentURLs) {
+        try {
+          const result = await nextResolve(specifier, {
+            ...context,
+            parentURL: fallbackParentURL,
+          });
+          process.stderr.write(
+            'quarry: resolved "' + specifier + '" via --resolve-from fallback\\n'
+          );
+          return result;
+        } catch {
+          // Try the next directory
+        }
       }
+      // Re-throw the original error if every fallback also fails
+      throw defaultErr;
     }
   }
 `
@@ -60,11 +64,13 @@ export const resolveFromHookCode = `
  * Register the ESM resolve-from hook via module.register().
  *
  * The hook tries default resolution first, then falls back to resolving
- * bare specifiers from the given directory using nextResolve with a
- * substituted parentURL.
+ * bare specifiers from each directory in order using nextResolve with a
+ * substituted parentURL. resolveFrom is a path list joined with the
+ * platform delimiter, as in QUARRY_RESOLVE_FROM.
  */
 export async function registerResolveFromHook(resolveFrom: string): Promise<void> {
   const { register } = await import('node:module')
+  const { delimiter } = await import('node:path')
   const hookUrl = `data:text/javascript;base64,${Buffer.from(resolveFromHookCode).toString('base64')}`
-  register(hookUrl, { data: { resolveFrom } })
+  register(hookUrl, { data: { resolveFrom: resolveFrom.split(delimiter).filter(Boolean) } })
 }
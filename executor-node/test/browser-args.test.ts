@@ -1,5 +1,5 @@
 import { afterEach, describe, expect, it } from 'vitest'
-import { chromiumArgs } from '../src/browser-args.js'
+import { chromiumArgs, parseProxyServerArg } from '../src/browser-args.js'
 
 describe('chromiumArgs', () => {
   afterEach(() => {
@@ -40,3 +40,40 @@ describe('chromiumArgs', () => {
     )
   })
 })
+
+describe('parseProxyServerArg', () => {
+  it('returns null without a --proxy-server switch', () => {
+    expect(parseProxyServerArg(['--headless', '--enable-automation'])).toBeNull()
+  })
+
+  it('parses a scheme-qualified server', () => {
+    expect(parseProxyServerArg(['--proxy-server=socks5://proxy.example.com:1081'])).toEqual({
+      protocol: 'socks5',
+      host: 'proxy.example.com',
+      port: 1081
+    })
+  })
+
+  it('defaults to http and the scheme port', () => {
+    expect(parseProxyServerArg(['--proxy-server=proxy.example.com'])).toEqual({
+      protocol: 'http',
+      host: 'proxy.example.com',
+      port: 80
+    })
+  })
+
+  it('reports the first entry of a per-scheme rule list', () => {
+    expect(
+      parseProxyServerArg(['--proxy-server=https=a.example.com:8443;http=b.example.com:8080'])
+    ).toEqual({
+      protocol: 'http',
+      host: 'a.example.com',
+      port: 8443
+    })
+  })
+
+  it('returns undefined for an unsupported server', () => {
+    expect(parseProxyServerArg(['--proxy-server=quic://proxy.example.com:443'])).toBeUndefined()
+    expect(parseProxyServerArg(['--proxy-server=direct://'])).toBeUndefined()
+  })
+})
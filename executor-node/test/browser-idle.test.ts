@@ -159,4 +159,29 @@ describe('evaluateIdlePoll', () => {
       expect(action.type).toBe('shutdown')
     })
   })
+
+  describe('persistent mode', () => {
+    it('never shuts down when idleTimeoutMs is 0', () => {
+      const state: IdlePollState = { idleStartedAt: 1_000_000, consecutiveFailures: 0 }
+      const action = evaluateIdlePoll(
+        { ok: true, activePages: 0 },
+        state,
+        { idleTimeoutMs: 0, maxConsecutiveFailures: 3 },
+        10_000_000
+      )
+
+      expect(action.type).toBe('continue')
+    })
+
+    it('still exits on consecutive health failures', () => {
+      const state: IdlePollState = { idleStartedAt: null, consecutiveFailures: 2 }
+      const action = evaluateIdlePoll(
+        { ok: false },
+        state,
+        { idleTimeoutMs: 0, maxConsecutiveFailures: 3 }
+      )
+
+      expect(action.type).toBe('crash-exit')
+    })
+  })
 })
@@ -15,7 +15,9 @@ import {
   type ExecutorConfig,
   type ExecutorResult,
   execute,
-  parseRunMeta
+  parseRunMeta,
+  parseSeedState,
+  sameProxyServer
 } from '../src/executor.js'
 import type { RunResultFrame } from '../src/ipc/frame.js'
 import { ObservingSink, SinkAlreadyFailedError } from '../src/ipc/observing-sink.js'
@@ -37,7 +39,8 @@ vi.mock('../src/loader.js', () => ({
 
 vi.mock('puppeteer', () => ({
   default: {
-    launch: vi.fn()
+    launch: vi.fn(),
+    connect: vi.fn()
   }
 }))
 
@@ -217,6 +220,36 @@ describe('parseRunMeta', () => {
   })
 })
 
+describe('parseSeedState', () => {
+  it('returns undefined when seed_state is absent or null', () => {
+    expect(parseSeedState({ job: {} })).toBeUndefined()
+    expect(parseSeedState({ job: {}, seed_state: null })).toBeUndefined()
+  })
+
+  it('returns the seed_state object', () => {
+    expect(parseSeedState({ seed_state: { cursor: 'tok-42' } })).toEqual({ cursor: 'tok-42' })
+  })
+
+  it('throws on non-object seed_state', () => {
+    expect(() => parseSeedState({ seed_state: [1] })).toThrow('seed_state must be an object')
+    expect(() => parseSeedState({ seed_state: 'tok' })).toThrow('seed_state must be an object')
+  })
+})
+
+describe('sameProxyServer', () => {
+  const gw: ProxyEndpoint = { protocol: 'http', host: 'gw.example.com', port: 8080, username: 'u1', password: 'p' }
+
+  it('ignores credentials', () => {
+    expect(sameProxyServer(gw, { ...gw, username: 'u2' })).toBe(true)
+  })
+
+  it('differs on protocol, host, or port', () => {
+    expect(sameProxyServer(gw, { ...gw, protocol: 'https' })).toBe(false)
+    expect(sameProxyServer(gw, { ...gw, host: 'other.example.com' })).toBe(false)
+    expect(sameProxyServer(gw, { ...gw, port: 8081 })).toBe(false)
+  })
+})
+
 describe('execute()', () => {
   let mockPuppeteer: ReturnType<typeof createMockPuppeteer>
   let mockOutput: PassThrough
@@ -523,6 +556,17 @@ describe('execute()', () => {
       expect(ctx.job).toEqual(transformedJob)
     })
 
+    it('passes seedState to the script context beside the job', async () => {
+      const scriptFn = vi.fn().mockResolvedValue(undefined)
+      ;(loadScript as Mock).mockResolvedValue(createMockScript({ script: scriptFn }))
+
+      await execute(createConfig({ seedState: { cursor: 'tok-42' } }))
+
+      const ctx = scriptFn.mock.calls[0][0]
+      expect(ctx.seedState).toEqual({ cursor: 'tok-42' })
+      expect(ctx.job).toEqual({ test: true })
+    })
+
     it('skips run when prepare returns skip with reason', async () => {
       const scriptFn = vi.fn().mockResolvedValue(undefined)
       const mockScript = createMockScript({
@@ -1006,6 +1050,70 @@ describe('run_result frame emission', () => {
     expect((runResult!.proxy_used as Record<string, unknown>).password).toBeUndefined()
   })
 
+  describe('connect mode (browserWSEndpoint)', () => {
+    const proxy: ProxyEndpoint = {
+      protocol: 'http',
+      host: 'configured.example.com',
+      port: 8080,
+      username: 'user',
+      password: 'secret123'
+    }
+
+    function connectBrowser(commandLine: string[] | Error) {
+      const send = vi.fn()
+      if (commandLine instanceof Error) {
+        send.mockRejectedValue(commandLine)
+      } else {
+        send.mockResolvedValue({ arguments: commandLine })
+      }
+      const session = { send, detach: vi.fn().mockResolvedValue(undefined) }
+      Object.assign(mockPuppeteer.page, { authenticate: vi.fn().mockResolvedValue(undefined) })
+      ;(puppeteer.connect as Mock).mockResolvedValue({
+        ...mockPuppeteer.browser,
+        target: () => ({ createCDPSession: vi.fn().mockResolvedValue(session) }),
+        disconnect: vi.fn()
+      })
+    }
+
+    beforeEach(() => {
+      ;(loadScript as Mock).mockResolvedValue(createMockScript())
+    })
+
+    it('reports the proxy the external browser was launched with', async () => {
+      connectBrowser(['--enable-automation', '--proxy-server=http://gw.example.com:3128'])
+
+      await execute(createConfig({ proxy, browserWSEndpoint: 'ws://127.0.0.1:9222/devtools' }))
+
+      const runResult = extractRunResultFrame(mockOutput)
+      expect(runResult!.proxy_used).toEqual({
+        protocol: 'http',
+        host: 'gw.example.com',
+        port: 3128,
+        username: 'user'
+      })
+    })
+
+    it('omits proxy_used when the external browser has no proxy', async () => {
+      connectBrowser(['--enable-automation'])
+
+      await execute(createConfig({ proxy, browserWSEndpoint: 'ws://127.0.0.1:9222/devtools' }))
+
+      const runResult = extractRunResultFrame(mockOutput)
+      expect(runResult!.outcome.status).toBe('completed')
+      expect(runResult!.proxy_used).toBeUndefined()
+    })
+
+    it('omits proxy_used when the browser command line is unavailable', async () => {
+      connectBrowser(new Error('Command line not returned because --enable-automation not set'))
+
+      await execute(createConfig({ proxy, browserWSEndpoint: 'ws://127.0.0.1:9222/devtools' }))
+
+      const runResult = extractRunResultFrame(mockOutput)
+      expect(runResult!.outcome.status).toBe('completed')
+      expect(runResult!.proxy_used).toBeUndefined()
+    })
+  })
+
   it('omits proxy_used when no proxy is configured', async () => {
     const mockScript = createMockScript({
       script: vi.fn().mockResolvedValue(undefined)
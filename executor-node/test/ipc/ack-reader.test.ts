@@ -234,4 +234,29 @@ describe('AckReader', () => {
       expect(reader.hasAckSupport).toBe(true)
     })
   })
+  describe('proxy_update', () => {
+    it('passes proxy_update frames to the registered handler', async () => {
+      const stream = new PassThrough()
+      const reader = new AckReader(stream)
+      const updates: string[] = []
+      reader.onProxyUpdate((update) => {
+        updates.push(`${update.endpoint.host}:${update.endpoint.port}/${update.reason}`)
+      })
+      reader.start()
+
+      const p1 = reader.waitForAck(1)
+      const payload = msgpackEncode({
+        type: 'proxy_update',
+        endpoint: { protocol: 'http', host: 'b.example.com', port: 8080 },
+        reason: 'captcha'
+      })
+      stream.write(Buffer.from(encodeFrame(payload)))
+      stream.write(encodeAck(1, true))
+
+      // Ack after the update still resolves; the update is not an ack
+      await expect(p1).resolves.toBeUndefined()
+      expect(updates).toEqual(['b.example.com:8080/captcha'])
+      reader.stop()
+    })
+  })
 })
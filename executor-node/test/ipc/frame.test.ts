@@ -1,10 +1,20 @@
+import { zstdDecompressSync } from 'node:zlib'
 import { decode as msgpackDecode, encode as msgpackEncode } from '@msgpack/msgpack'
-import type { ArtifactId, EventEnvelope, EventId, JobId, RunId } from '@pithecene-io/quarry-sdk'
+import {
+  type ArtifactId,
+  CONTRACT_VERSION,
+  type EventEnvelope,
+  type EventId,
+  type JobId,
+  type RunId
+} from '@pithecene-io/quarry-sdk'
 import { describe, expect, it } from 'vitest'
 import {
   type ArtifactChunkFrame,
+  COMPRESSION_MIN_PAYLOAD_SIZE,
   ChunkValidationError,
   calculateChunks,
+  crc32c,
   decodeFileWriteAck,
   encodeArtifactChunkFrame,
   encodeArtifactChunks,
@@ -14,13 +24,17 @@ import {
   encodeRunResultFrame,
   type FileWriteAckFrame,
   type FileWriteFrame,
+  FRAME_FLAG_ZSTD,
   FrameSizeError,
   LENGTH_PREFIX_SIZE,
   MAX_CHUNK_SIZE,
   MAX_FRAME_SIZE,
+  MAX_FRAME_SIZE_CEILING,
   MAX_PAYLOAD_SIZE,
   type RunResultFrame,
-  type RunResultOutcome
+  negotiateFrameCompression,
+  type RunResultOutcome,
+  resolveMaxPayloadSize
 } from '../../src/ipc/frame.js'
 
 /**
@@ -105,6 +119,72 @@ describe('encodeFrame', () => {
       expect(frameErr.maxPayloadSize).toBe(MAX_PAYLOAD_SIZE)
     }
   })
+
+  it('honours an explicit payload limit', () => {
+    const payload = new Uint8Array(17)
+
+    expect(encodeFrame(payload, 17).length).toBe(LENGTH_PREFIX_SIZE + 17)
+    expect(() => encodeFrame(payload, 16)).toThrow(FrameSizeError)
+  })
+})
+
+describe('frame compression', () => {
+  it('compresses large payloads with FRAME_FLAG_ZSTD', () => {
+    const payload = new Uint8Array(Buffer.from('verbose item payload '.repeat(512)))
+    const frame = encodeFrame(payload, MAX_PAYLOAD_SIZE, 'zstd')
+
+    const prefix = frame.readUInt32BE(0)
+    expect(prefix & FRAME_FLAG_ZSTD).not.toBe(0)
+    const compressedLength = (prefix & ~FRAME_FLAG_ZSTD) >>> 0
+    expect(frame.length).toBe(LENGTH_PREFIX_SIZE + compressedLength)
+    expect(compressedLength).toBeLessThan(payload.length)
+    expect(zstdDecompressSync(frame.subarray(LENGTH_PREFIX_SIZE))).toEqual(Buffer.from(payload))
+  })
+
+  it('leaves small and incompressible payloads uncompressed', () => {
+    const small = new Uint8Array(COMPRESSION_MIN_PAYLOAD_SIZE - 1)
+    expect(encodeFrame(small, MAX_PAYLOAD_SIZE, 'zstd').readUInt32BE(0)).toBe(small.length)
+
+    const random = new Uint8Array(4096).map(() => Math.floor(Math.random() * 256))
+    expect(encodeFrame(random, MAX_PAYLOAD_SIZE, 'zstd').readUInt32BE(0)).toBe(random.length)
+  })
+
+  it('enforces the frame limit on the uncompressed payload', () => {
+    const payload = new Uint8Array(4096)
+    expect(() => encodeFrame(payload, 1024, 'zstd')).toThrow(FrameSizeError)
+  })
+
+  it('is uncompressed by default', () => {
+    const payload = new Uint8Array(Buffer.from('verbose item payload '.repeat(512)))
+    expect(encodeFrame(payload).readUInt32BE(0)).toBe(payload.length)
+  })
+
+  it('negotiates zstd only when the runtime advertises it', () => {
+    expect(negotiateFrameCompression({ ipc: { compression: ['zstd'] } })).toBe('zstd')
+    expect(negotiateFrameCompression({})).toBeUndefined()
+    expect(negotiateFrameCompression({ ipc: null })).toBeUndefined()
+    expect(negotiateFrameCompression({ ipc: { compression: ['gzip'] } })).toBeUndefined()
+  })
+})
+
+describe('resolveMaxPayloadSize', () => {
+  it('defaults to MAX_PAYLOAD_SIZE when unset', () => {
+    expect(resolveMaxPayloadSize({})).toBe(MAX_PAYLOAD_SIZE)
+    expect(resolveMaxPayloadSize({ QUARRY_MAX_FRAME_BYTES: '' })).toBe(MAX_PAYLOAD_SIZE)
+  })
+
+  it('subtracts the length prefix from QUARRY_MAX_FRAME_BYTES', () => {
+    expect(resolveMaxPayloadSize({ QUARRY_MAX_FRAME_BYTES: '1024' })).toBe(1020)
+    expect(resolveMaxPayloadSize({ QUARRY_MAX_FRAME_BYTES: String(MAX_FRAME_SIZE_CEILING) })).toBe(
+      MAX_FRAME_SIZE_CEILING - LENGTH_PREFIX_SIZE
+    )
+  })
+
+  it('falls back to MAX_PAYLOAD_SIZE for invalid values', () => {
+    for (const value of ['0', '4', 'abc', '1.5', String(MAX_FRAME_SIZE_CEILING + 1)]) {
+      expect(resolveMaxPayloadSize({ QUARRY_MAX_FRAME_BYTES: value })).toBe(MAX_PAYLOAD_SIZE)
+    }
+  })
 })
 
 describe('encodeEventFrame', () => {
@@ -157,6 +237,12 @@ describe('encodeArtifactChunkFrame', () => {
     expect(decoded.seq).toBe(1)
     expect(decoded.is_last).toBe(false)
     expect(new Uint8Array(decoded.data)).toEqual(data)
+    expect(decoded.crc32c).toBe(crc32c(data))
+  })
+
+  it('computes CRC-32C (Castagnoli) check values', () => {
+    expect(crc32c(new TextEncoder().encode('123456789'))).toBe(0xe3069283)
+    expect(crc32c(new Uint8Array(0))).toBe(0)
   })
 
   it('encodes is_last=true correctly', () => {
@@ -451,6 +537,16 @@ describe('encodeRunResultFrame', () => {
     expect(decoded.proxy_used).toBeUndefined()
   })
 
+  it('tags the frame with the executor contract version', () => {
+    const frame = encodeRunResultFrame({ status: 'completed' })
+
+    const payloadLength = frame.readUInt32BE(0)
+    const payload = frame.subarray(LENGTH_PREFIX_SIZE, LENGTH_PREFIX_SIZE + payloadLength)
+    const decoded = msgpackDecode(payload) as RunResultFrame
+
+    expect(decoded.executor_version).toBe(CONTRACT_VERSION)
+  })
+
   it('encodes error outcome with all fields', () => {
     const outcome: RunResultOutcome = {
       status: 'error',
@@ -24,6 +24,14 @@ describe('parseStoragePartition()', () => {
     expect(warn).not.toHaveBeenCalled()
   })
 
+  it('parses the optional hour for hourly partitions', () => {
+    const warn = vi.fn()
+    const result = parseStoragePartition({ storage: { ...validStorage, hour: '07' } }, warn)
+
+    expect(result).toEqual({ ...validStorage, hour: '07' })
+    expect(warn).not.toHaveBeenCalled()
+  })
+
   it('returns undefined when storage is absent', () => {
     const warn = vi.fn()
     const result = parseStoragePartition({}, warn)
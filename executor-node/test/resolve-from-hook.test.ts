@@ -60,6 +60,12 @@ describe('resolve-from hook', () => {
     expect(resolveFromHookCode).not.toMatch(/createRequire\(/)
   })
 
+  it('hook tries every fallback directory before rethrowing', () => {
+    expect(resolveFromHookCode).toContain('data.resolveFrom.map(')
+    expect(resolveFromHookCode).toContain('for (const fallbackParentURL of fallbackParentURLs)')
+    expect(resolveFromHookCode).toContain('throw defaultErr')
+  })
+
   it('hook logs to stderr on fallback resolution', () => {
     expect(resolveFromHookCode).toContain('process.stderr.write')
     expect(resolveFromHookCode).toContain('--resolve-from fallback')
@@ -7,12 +7,13 @@
  * - The bare specifier `@test/greet` resolves via the fallback hook
  * - The fallback observability message appears on stderr
  * - The module's export is usable (correct value on stdout)
+ * - With a path list, later directories are tried when earlier ones miss
  * - Without QUARRY_RESOLVE_FROM, the import fails (control case)
  */
 import { type ChildProcess, spawn } from 'node:child_process'
 import { mkdirSync, mkdtempSync, rmSync, writeFileSync } from 'node:fs'
 import { tmpdir } from 'node:os'
-import { dirname, join, resolve } from 'node:path'
+import { delimiter, dirname, join, resolve } from 'node:path'
 import { fileURLToPath } from 'node:url'
 import { afterAll, beforeAll, describe, expect, it } from 'vitest'
 
@@ -129,6 +130,17 @@ describe('resolve-from integration', () => {
     expect(parsed.greeting).toBe('hello from esm')
   }, 15_000)
 
+  it('falls through a path list to the directory that has the package', async () => {
+    const nodeModulesDir = join(tmpDir, 'node_modules')
+    const { stdout, exitCode, stderr } = await runFixture({
+      QUARRY_RESOLVE_FROM: [testDir, nodeModulesDir].join(delimiter)
+    })
+
+    expect(exitCode, `child stderr: ${stderr}`).toBe(0)
+    const parsed = JSON.parse(stdout.trim())
+    expect(parsed.greeting).toBe('hello from esm')
+  }, 15_000)
+
   it('fails without QUARRY_RESOLVE_FROM (control case)', async () => {
     // Without the env var, the child exits with code 2
     const { exitCode, stderr } = await runFixture({
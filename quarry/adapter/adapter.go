@@ -4,28 +4,93 @@
 // The runtime owns adapter lifecycle; users provide configuration only.
 package adapter
 
-import "context"
+import (
+	"context"
+	"unicode/utf8"
+)
+
+// DefaultErrorMaxLen is the default byte bound for ErrorMessage and ErrorStack.
+const DefaultErrorMaxLen = 1024
+
+// truncatedSuffix marks a field cut by TruncateError.
+const truncatedSuffix = "...(truncated)"
+
+// Event types published through Adapter.Publish.
+const (
+	// EventTypeRunCompleted is published once per run when it finishes.
+	EventTypeRunCompleted = "run_completed"
+	// EventTypeArtifactCommitted is published per committed artifact
+	// (opt-in, --adapter-on-artifact).
+	EventTypeArtifactCommitted = "artifact_committed"
+)
 
 // RunCompletedEvent is the payload published when a run finishes.
 // Shape matches the event payload defined in docs/guides/integration.md.
+// The same shape carries artifact_committed events, which set the artifact
+// fields and leave the outcome and error fields empty.
 type RunCompletedEvent struct {
 	ContractVersion string `json:"contract_version"`
-	EventType       string `json:"event_type"` // always "run_completed"
+	EventType       string `json:"event_type"` // run_completed or artifact_committed
 	RunID           string `json:"run_id"`
 	Source          string `json:"source"`
 	Category        string `json:"category"`
 	Day             string `json:"day"`
-	Outcome         string `json:"outcome"`      // success, script_error, etc.
+	Hour            string `json:"hour,omitempty"`    // set for hourly partitions
+	Outcome         string `json:"outcome,omitempty"` // success, script_error, etc.
+	Reason          string `json:"reason,omitempty"`  // refines outcome, e.g. stream_truncated
 	StoragePath     string `json:"storage_path"`
-	Timestamp       string `json:"timestamp"`     // ISO 8601
+	Timestamp       string `json:"timestamp"` // ISO 8601
 	JobID           string `json:"job_id,omitempty"`
 	Attempt         int    `json:"attempt"`
 	EventCount      int64  `json:"event_count"`
 	DurationMs      int64  `json:"duration_ms"`
+
+	// Labels are the user run labels (--label key=value), if any.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// MetricsPending is set when the event is published before the run's
+	// metrics and partition manifest are written (--notify-early).
+	MetricsPending bool `json:"metrics_pending,omitempty"`
+
+	// Error classification, present only for non-success outcomes.
+	// ErrorType is the script error class when known (e.g. "TypeError"),
+	// otherwise the outcome status. Message and stack are byte-bounded.
+	ErrorType    string `json:"error_type,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+	ErrorStack   string `json:"error_stack,omitempty"`
+
+	// Artifact fields, present only on artifact_committed events.
+	// StoragePath then names the run's artifact partition.
+	ArtifactID   string `json:"artifact_id,omitempty"`
+	ArtifactName string `json:"artifact_name,omitempty"`
+	ContentType  string `json:"content_type,omitempty"`
+	SizeBytes    int64  `json:"size_bytes,omitempty"`
+}
+
+// TruncateError bounds s to at most maxLen bytes, cutting on a UTF-8 rune
+// boundary and appending a truncation marker. Strings within the bound are
+// returned unchanged. maxLen <= 0 returns s unchanged.
+func TruncateError(s string, maxLen int) string {
+	if maxLen <= 0 || len(s) <= maxLen {
+		return s
+	}
+	keep := maxLen - len(truncatedSuffix)
+	if keep <= 0 {
+		// Bound too small for the marker; hard cut
+		keep = maxLen
+	}
+	for keep > 0 && !utf8.RuneStart(s[keep]) {
+		keep--
+	}
+	if keep+len(truncatedSuffix) > maxLen {
+		return s[:keep]
+	}
+	return s[:keep] + truncatedSuffix
 }
 
 // Adapter publishes run completion events to a downstream system.
-// Implementations must be safe for single-use per run.
+// Implementations must be safe for concurrent Publish calls: the CLI shares
+// one instance across a run and its fan-out children, and closes it once.
 type Adapter interface {
 	// Publish sends a run completion event to the downstream system.
 	// Must respect context cancellation and deadlines.
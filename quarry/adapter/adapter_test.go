@@ -0,0 +1,43 @@
+package adapter
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateError(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		maxLen int
+		want   string
+	}{
+		{name: "within bound", in: "short", maxLen: 10, want: "short"},
+		{name: "exact bound", in: "0123456789", maxLen: 10, want: "0123456789"},
+		{name: "disabled", in: "anything", maxLen: 0, want: "anything"},
+		{name: "truncated with marker", in: strings.Repeat("a", 40), maxLen: 20, want: "aaaaaa...(truncated)"},
+		{name: "bound smaller than marker", in: "abcdefghij", maxLen: 4, want: "abcd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateError(tt.in, tt.maxLen)
+			if got != tt.want {
+				t.Errorf("TruncateError(%q, %d) = %q, want %q", tt.in, tt.maxLen, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateError_RuneBoundary(t *testing.T) {
+	in := strings.Repeat("é", 30) // 2 bytes per rune
+	got := TruncateError(in, 25)
+
+	if len(got) > 25 {
+		t.Errorf("len = %d, want <= 25", len(got))
+	}
+	if !utf8.ValidString(got) {
+		t.Errorf("result is not valid UTF-8: %q", got)
+	}
+}
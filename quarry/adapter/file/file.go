@@ -0,0 +1,171 @@
+// Package file implements a local file outbox adapter per CONTRACT_INTEGRATION.md.
+//
+// Appends each run completion event as a JSON line to a local file that
+// another process drains, for environments without a network event bus.
+// Every append is fsync'd before Publish returns. The file is rotated by
+// size; rotated files keep a sortable UTC timestamp suffix.
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/pithecene-io/quarry/adapter"
+	"github.com/pithecene-io/quarry/iox"
+)
+
+// DefaultMaxBytes is the default size at which the outbox file is rotated.
+const DefaultMaxBytes int64 = 64 << 20
+
+// rotatedSuffixLayout is the UTC timestamp appended to rotated files.
+// Lexical order of rotated names is chronological order.
+const rotatedSuffixLayout = "20060102T150405.000000000Z"
+
+// Config configures the file adapter.
+type Config struct {
+	// Path is the outbox file (required). Created if missing; its directory
+	// must exist.
+	Path string
+	// MaxBytes rotates the outbox before an append would grow it past this
+	// size (default 64 MiB). Set to -1 to disable rotation. Zero applies
+	// the default.
+	MaxBytes int64
+	// Encoder renders the published body (default: canonical JSON).
+	Encoder adapter.Encoder
+}
+
+// Adapter appends run completion events to a JSON Lines outbox file.
+type Adapter struct {
+	config Config
+	now    func() time.Time // rotation timestamp source, overridable in tests
+}
+
+// New creates a file adapter from the given config.
+// Returns an error if the path is empty or its directory does not exist.
+func New(cfg Config) (*Adapter, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("file adapter requires a path")
+	}
+	if cfg.MaxBytes == 0 {
+		cfg.MaxBytes = DefaultMaxBytes
+	}
+	dir := filepath.Dir(cfg.Path)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("file adapter: directory %s does not exist", dir)
+	}
+
+	return &Adapter{config: cfg, now: time.Now}, nil
+}
+
+// Publish appends the event as one JSON line and fsyncs the file.
+// Appends from concurrent processes sharing the outbox are serialized by an
+// advisory lock on a sibling .lock file, so lines never interleave and
+// rotation never races an append.
+func (a *Adapter) Publish(ctx context.Context, event *adapter.RunCompletedEvent) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("file: context canceled: %w", err)
+	}
+	line, err := adapter.Marshal(a.config.Encoder, event)
+	if err != nil {
+		return fmt.Errorf("file: marshal event: %w", err)
+	}
+	line = append(line, '\n')
+
+	unlock, err := a.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	created, err := a.rotateIfNeeded(int64(len(line)))
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(a.config.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("file: open outbox: %w", err)
+	}
+	// A single write with O_APPEND lands the whole line at the end of file
+	if _, err := f.Write(line); err != nil {
+		iox.DiscardClose(f)
+		return fmt.Errorf("file: append event: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		iox.DiscardClose(f)
+		return fmt.Errorf("file: fsync outbox: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("file: close outbox: %w", err)
+	}
+	if created {
+		// Persist the new directory entry so the file survives a crash
+		return syncDir(filepath.Dir(a.config.Path))
+	}
+	return nil
+}
+
+// lock takes the exclusive advisory lock on <path>.lock.
+func (a *Adapter) lock() (func(), error) {
+	lockFile, err := os.OpenFile(a.config.Path+".lock", os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("file: open lock: %w", err)
+	}
+	if err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX); err != nil {
+		iox.DiscardClose(lockFile)
+		return nil, fmt.Errorf("file: acquire lock: %w", err)
+	}
+	return func() {
+		_ = syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
+		iox.DiscardClose(lockFile)
+	}, nil
+}
+
+// rotateIfNeeded renames the outbox aside when appending n bytes would take
+// it past MaxBytes. An outbox is never rotated while empty, so a single
+// oversized event still lands. Reports whether the next open creates the file.
+func (a *Adapter) rotateIfNeeded(n int64) (bool, error) {
+	info, err := os.Stat(a.config.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("file: stat outbox: %w", err)
+	}
+	if a.config.MaxBytes < 0 || info.Size() == 0 || info.Size()+n <= a.config.MaxBytes {
+		return false, nil
+	}
+
+	rotated := a.config.Path + "." + a.now().UTC().Format(rotatedSuffixLayout)
+	if err := os.Rename(a.config.Path, rotated); err != nil {
+		return false, fmt.Errorf("file: rotate outbox: %w", err)
+	}
+	return true, nil
+}
+
+// syncDir fsyncs a directory so renames and file creation are durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("file: open outbox directory: %w", err)
+	}
+	defer iox.DiscardClose(d)
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("file: fsync outbox directory: %w", err)
+	}
+	return nil
+}
+
+// Close releases adapter resources. The adapter holds no open files
+// between publishes.
+func (a *Adapter) Close() error {
+	return nil
+}
+
+// Verify Adapter implements the adapter interface.
+var _ adapter.Adapter = (*Adapter)(nil)
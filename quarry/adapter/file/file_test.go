@@ -0,0 +1,214 @@
+package file
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pithecene-io/quarry/adapter"
+)
+
+func testEvent(runID string) *adapter.RunCompletedEvent {
+	return &adapter.RunCompletedEvent{
+		ContractVersion: "0.4.0",
+		EventType:       "run_completed",
+		RunID:           runID,
+		Source:          "test-source",
+		Category:        "default",
+		Day:             "2026-02-07",
+		Outcome:         "success",
+		StoragePath:     "file:///data/source=test-source/category=default/day=2026-02-07/run_id=" + runID,
+		Timestamp:       "2026-02-07T12:00:00Z",
+		Attempt:         1,
+		EventCount:      42,
+		DurationMs:      1500,
+	}
+}
+
+// readLines decodes every JSON line in path.
+func readLines(t *testing.T, path string) []adapter.RunCompletedEvent {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open %s: %v", path, err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var events []adapter.RunCompletedEvent
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var ev adapter.RunCompletedEvent
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			t.Fatalf("line is not valid JSON: %q: %v", scanner.Text(), err)
+		}
+		events = append(events, ev)
+	}
+	return events
+}
+
+func TestPublish_AppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	a, err := New(Config{Path: path})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer func() { _ = a.Close() }()
+
+	for _, id := range []string{"run-001", "run-002"} {
+		if err := a.Publish(t.Context(), testEvent(id)); err != nil {
+			t.Fatalf("Publish %s: %v", id, err)
+		}
+	}
+
+	events := readLines(t, path)
+	if len(events) != 2 || events[0].RunID != "run-001" || events[1].RunID != "run-002" {
+		t.Fatalf("events = %+v, want run-001 then run-002", events)
+	}
+	if events[0].EventType != "run_completed" || events[0].EventCount != 42 {
+		t.Errorf("payload not preserved: %+v", events[0])
+	}
+}
+
+func TestPublish_RotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "outbox.jsonl")
+	line, _ := json.Marshal(testEvent("run-001"))
+
+	// Room for two lines per file
+	a, err := New(Config{Path: path, MaxBytes: int64(2*(len(line)+1) + 1)})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	tick := time.Date(2026, 2, 7, 12, 0, 0, 0, time.UTC)
+	a.now = func() time.Time {
+		tick = tick.Add(time.Second)
+		return tick
+	}
+
+	for _, id := range []string{"run-001", "run-002", "run-003", "run-004", "run-005"} {
+		if err := a.Publish(t.Context(), testEvent(id)); err != nil {
+			t.Fatalf("Publish %s: %v", id, err)
+		}
+	}
+
+	rotated, _ := filepath.Glob(path + ".2*")
+	sort.Strings(rotated)
+	if len(rotated) != 2 {
+		t.Fatalf("expected 2 rotated files, got %v", rotated)
+	}
+	var got []string
+	for _, p := range append(rotated, path) {
+		for _, ev := range readLines(t, p) {
+			got = append(got, ev.RunID)
+		}
+	}
+	if want := "run-001,run-002,run-003,run-004,run-005"; strings.Join(got, ",") != want {
+		t.Errorf("events across files = %v, want %s in order", got, want)
+	}
+}
+
+func TestPublish_OversizedEventNotRotatedAlone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	a, err := New(Config{Path: path, MaxBytes: 10})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := a.Publish(t.Context(), testEvent("run-001")); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if rotated, _ := filepath.Glob(path + ".2*"); len(rotated) != 0 {
+		t.Errorf("an empty outbox must not rotate, got %v", rotated)
+	}
+	if n := len(readLines(t, path)); n != 1 {
+		t.Errorf("expected 1 line, got %d", n)
+	}
+}
+
+func TestPublish_RotationDisabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	a, err := New(Config{Path: path, MaxBytes: -1})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	for range 3 {
+		if err := a.Publish(t.Context(), testEvent("run-001")); err != nil {
+			t.Fatalf("Publish: %v", err)
+		}
+	}
+	if rotated, _ := filepath.Glob(path + ".2*"); len(rotated) != 0 {
+		t.Errorf("rotation disabled, got %v", rotated)
+	}
+}
+
+func TestPublish_ConcurrentAppendsDoNotInterleave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Separate adapters model separate processes sharing the outbox
+			a, err := New(Config{Path: path})
+			if err != nil {
+				t.Errorf("New: %v", err)
+				return
+			}
+			for range 10 {
+				if err := a.Publish(t.Context(), testEvent(strings.Repeat("x", 100*i+1))); err != nil {
+					t.Errorf("Publish: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := len(readLines(t, path)); n != 80 {
+		t.Errorf("expected 80 intact lines, got %d", n)
+	}
+}
+
+func TestPublish_ContextCanceled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	a, err := New(Config{Path: path})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if err := a.Publish(ctx, testEvent("run-001")); err == nil {
+		t.Fatal("expected error for canceled context")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("canceled publish must not create the outbox")
+	}
+}
+
+func TestNew_RequiresPath(t *testing.T) {
+	if _, err := New(Config{}); err == nil {
+		t.Fatal("expected error for empty path")
+	}
+}
+
+func TestNew_MissingDirectory(t *testing.T) {
+	if _, err := New(Config{Path: filepath.Join(t.TempDir(), "missing", "outbox.jsonl")}); err == nil {
+		t.Fatal("expected error for missing directory")
+	}
+}
+
+func TestNew_DefaultsApplied(t *testing.T) {
+	a, err := New(Config{Path: filepath.Join(t.TempDir(), "outbox.jsonl")})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if a.config.MaxBytes != DefaultMaxBytes {
+		t.Errorf("MaxBytes = %d, want %d", a.config.MaxBytes, DefaultMaxBytes)
+	}
+}
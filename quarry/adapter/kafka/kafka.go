@@ -0,0 +1,237 @@
+// Package kafka implements a Kafka adapter per CONTRACT_INTEGRATION.md.
+//
+// Produces run completion events as JSON to a configurable Kafka topic,
+// keyed by run_id so all events for a run land on the same partition.
+// Retries with exponential backoff on produce errors.
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+
+	"github.com/pithecene-io/quarry/adapter"
+)
+
+// DefaultTopic is the default topic name.
+const DefaultTopic = "quarry.run_completed"
+
+// DefaultTimeout is the default per-produce timeout (produce + broker ack).
+const DefaultTimeout = 10 * time.Second
+
+// DefaultRetries is the default number of retry attempts.
+const DefaultRetries = 3
+
+// Supported SASL mechanisms.
+const (
+	MechanismPlain       = "plain"
+	MechanismScramSHA256 = "scram-sha-256"
+	MechanismScramSHA512 = "scram-sha-512"
+)
+
+// SASLConfig configures SASL authentication.
+type SASLConfig struct {
+	// Mechanism is one of plain, scram-sha-256, scram-sha-512.
+	Mechanism string
+	// Username is the SASL username (required).
+	Username string
+	// Password is the SASL password (required).
+	Password string
+}
+
+// Config configures the Kafka adapter.
+type Config struct {
+	// Brokers is the list of seed brokers (host:port, required).
+	Brokers []string
+	// Topic is the destination topic (default: quarry.run_completed).
+	Topic string
+	// Timeout bounds each produce attempt, including the broker ack (default 10s).
+	Timeout time.Duration
+	// Retries is the number of retry attempts on failure (default 3).
+	Retries int
+	// SASL enables SASL authentication when non-nil.
+	SASL *SASLConfig
+	// TLS enables TLS to the brokers.
+	TLS bool
+	// TLSCAFile is an optional PEM CA bundle (default: system roots). Implies TLS.
+	TLSCAFile string
+	// Encoder renders the published body (default: canonical JSON).
+	Encoder adapter.Encoder
+}
+
+// producer is the subset of *kgo.Client used by the adapter.
+type producer interface {
+	ProduceSync(ctx context.Context, rs ...*kgo.Record) kgo.ProduceResults
+	Close()
+}
+
+// Adapter publishes run completion events to a Kafka topic.
+type Adapter struct {
+	config   Config
+	producer producer
+}
+
+// New creates a Kafka adapter from the given config.
+// Returns an error if no brokers are configured or SASL/TLS settings are invalid.
+// Broker connections are established lazily on first publish.
+func New(cfg Config) (*Adapter, error) {
+	cfg, err := normalize(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(cfg.Brokers...),
+		kgo.DefaultProduceTopic(cfg.Topic),
+		// Retries are handled by Publish so attempts are bounded by Timeout
+		kgo.RecordRetries(1),
+	}
+	if cfg.SASL != nil {
+		mech, err := saslMechanism(*cfg.SASL)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, kgo.SASL(mech))
+	}
+	if cfg.TLS {
+		tlsCfg, err := tlsConfig(cfg.TLSCAFile)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, kgo.DialTLSConfig(tlsCfg))
+	}
+
+	client, err := kgo.NewClient(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("kafka adapter: %w", err)
+	}
+	return &Adapter{config: cfg, producer: client}, nil
+}
+
+// normalize validates cfg and applies defaults.
+func normalize(cfg Config) (Config, error) {
+	brokers := make([]string, 0, len(cfg.Brokers))
+	for _, b := range cfg.Brokers {
+		if b = strings.TrimSpace(b); b != "" {
+			brokers = append(brokers, b)
+		}
+	}
+	if len(brokers) == 0 {
+		return cfg, errors.New("kafka adapter requires at least one broker")
+	}
+	cfg.Brokers = brokers
+
+	if cfg.Topic == "" {
+		cfg.Topic = DefaultTopic
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.Retries < 0 {
+		return cfg, fmt.Errorf("retries must be >= 0, got %d", cfg.Retries)
+	}
+	if cfg.TLSCAFile != "" {
+		cfg.TLS = true
+	}
+	return cfg, nil
+}
+
+// saslMechanism builds the franz-go SASL mechanism for cfg.
+func saslMechanism(cfg SASLConfig) (sasl.Mechanism, error) {
+	if cfg.Username == "" || cfg.Password == "" {
+		return nil, errors.New("kafka adapter: sasl requires username and password")
+	}
+	switch strings.ToLower(cfg.Mechanism) {
+	case MechanismPlain:
+		return plain.Auth{User: cfg.Username, Pass: cfg.Password}.AsMechanism(), nil
+	case MechanismScramSHA256:
+		return scram.Auth{User: cfg.Username, Pass: cfg.Password}.AsSha256Mechanism(), nil
+	case MechanismScramSHA512:
+		return scram.Auth{User: cfg.Username, Pass: cfg.Password}.AsSha512Mechanism(), nil
+	default:
+		return nil, fmt.Errorf("kafka adapter: unsupported sasl mechanism %q (supported: %s, %s, %s)",
+			cfg.Mechanism, MechanismPlain, MechanismScramSHA256, MechanismScramSHA512)
+	}
+}
+
+// tlsConfig builds a TLS config, optionally trusting a PEM CA bundle.
+func tlsConfig(caFile string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caFile == "" {
+		return cfg, nil
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("kafka adapter: read tls ca file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("kafka adapter: no certificates found in %s", caFile)
+	}
+	cfg.RootCAs = pool
+	return cfg, nil
+}
+
+// Publish produces the event as JSON to the configured topic, keyed by run_id.
+// Each attempt waits for the broker ack within Timeout.
+// Retries with exponential backoff on failures.
+func (a *Adapter) Publish(ctx context.Context, event *adapter.RunCompletedEvent) error {
+	body, err := adapter.Marshal(a.config.Encoder, event)
+	if err != nil {
+		return fmt.Errorf("kafka: marshal event: %w", err)
+	}
+
+	var lastErr error
+	// attempts = 1 initial + retries
+	attempts := 1 + a.config.Retries
+
+	for i := range attempts {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("kafka: context canceled: %w", err)
+		}
+
+		// Exponential backoff before retries (not before first attempt)
+		if i > 0 {
+			backoff := time.Duration(1<<uint(i-1)) * 500 * time.Millisecond
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("kafka: context canceled during backoff: %w", ctx.Err())
+			case <-time.After(backoff):
+			}
+		}
+
+		record := &kgo.Record{
+			Topic: a.config.Topic,
+			Key:   []byte(event.RunID),
+			Value: body,
+		}
+		produceCtx, cancel := context.WithTimeout(ctx, a.config.Timeout)
+		lastErr = a.producer.ProduceSync(produceCtx, record).FirstErr()
+		cancel()
+
+		if lastErr == nil {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("kafka: failed after %d attempts: %w", attempts, lastErr)
+}
+
+// Close releases adapter resources.
+func (a *Adapter) Close() error {
+	a.producer.Close()
+	return nil
+}
+
+// Verify Adapter implements the adapter interface.
+var _ adapter.Adapter = (*Adapter)(nil)
@@ -0,0 +1,252 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+
+	"github.com/pithecene-io/quarry/adapter"
+	"github.com/pithecene-io/quarry/iox"
+)
+
+func testEvent() *adapter.RunCompletedEvent {
+	return &adapter.RunCompletedEvent{
+		ContractVersion: "0.4.0",
+		EventType:       "run_completed",
+		RunID:           "run-001",
+		Source:          "test-source",
+		Category:        "default",
+		Day:             "2026-02-07",
+		Outcome:         "success",
+		StoragePath:     "file:///data/source=test-source/category=default/day=2026-02-07/run_id=run-001",
+		Timestamp:       "2026-02-07T12:00:00Z",
+		Attempt:         1,
+		EventCount:      42,
+		DurationMs:      1500,
+	}
+}
+
+// fakeProducer records produced records and fails the first failN attempts.
+type fakeProducer struct {
+	mu       sync.Mutex
+	records  []*kgo.Record
+	attempts int
+	failN    int
+	closed   bool
+	block    bool
+}
+
+func (f *fakeProducer) ProduceSync(ctx context.Context, rs ...*kgo.Record) kgo.ProduceResults {
+	f.mu.Lock()
+	f.attempts++
+	attempt := f.attempts
+	f.mu.Unlock()
+
+	if f.block {
+		<-ctx.Done()
+		return kgo.ProduceResults{{Record: rs[0], Err: ctx.Err()}}
+	}
+	if attempt <= f.failN {
+		return kgo.ProduceResults{{Record: rs[0], Err: errors.New("broker unavailable")}}
+	}
+
+	f.mu.Lock()
+	f.records = append(f.records, rs...)
+	f.mu.Unlock()
+	results := make(kgo.ProduceResults, len(rs))
+	for i, r := range rs {
+		results[i] = kgo.ProduceResult{Record: r}
+	}
+	return results
+}
+
+func (f *fakeProducer) Close() { f.closed = true }
+
+func newTestAdapter(t *testing.T, cfg Config, p *fakeProducer) *Adapter {
+	t.Helper()
+	cfg, err := normalize(cfg)
+	if err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	return &Adapter{config: cfg, producer: p}
+}
+
+func TestPublish_Success(t *testing.T) {
+	p := &fakeProducer{}
+	a := newTestAdapter(t, Config{Brokers: []string{"localhost:9092"}, Topic: "runs"}, p)
+	defer iox.DiscardClose(a)
+
+	event := testEvent()
+	if err := a.Publish(t.Context(), event); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	if len(p.records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(p.records))
+	}
+	r := p.records[0]
+	if r.Topic != "runs" {
+		t.Errorf("topic = %q, want runs", r.Topic)
+	}
+	if string(r.Key) != "run-001" {
+		t.Errorf("key = %q, want run_id run-001", r.Key)
+	}
+	var got adapter.RunCompletedEvent
+	if err := json.Unmarshal(r.Value, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got.RunID != event.RunID || got.EventCount != event.EventCount {
+		t.Errorf("payload mismatch: got %+v", got)
+	}
+}
+
+func TestPublish_RetriesThenSucceeds(t *testing.T) {
+	p := &fakeProducer{failN: 1}
+	a := newTestAdapter(t, Config{Brokers: []string{"localhost:9092"}, Retries: 2}, p)
+
+	if err := a.Publish(t.Context(), testEvent()); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if p.attempts != 2 {
+		t.Errorf("attempts = %d, want 2", p.attempts)
+	}
+}
+
+func TestPublish_FailsAfterRetries(t *testing.T) {
+	p := &fakeProducer{failN: 10}
+	a := newTestAdapter(t, Config{Brokers: []string{"localhost:9092"}, Retries: 1}, p)
+
+	err := a.Publish(t.Context(), testEvent())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(err.Error(), "failed after 2 attempts") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestPublish_TimeoutBoundsAttempt(t *testing.T) {
+	p := &fakeProducer{block: true}
+	a := newTestAdapter(t, Config{Brokers: []string{"localhost:9092"}, Timeout: 50 * time.Millisecond}, p)
+
+	start := time.Now()
+	err := a.Publish(t.Context(), testEvent())
+	if err == nil {
+		t.Fatal("expected timeout error")
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("publish should honor timeout, took %s", elapsed)
+	}
+}
+
+func TestPublish_ContextCanceled(t *testing.T) {
+	p := &fakeProducer{}
+	a := newTestAdapter(t, Config{Brokers: []string{"localhost:9092"}}, p)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if err := a.Publish(ctx, testEvent()); err == nil {
+		t.Fatal("expected error for canceled context")
+	}
+	if p.attempts != 0 {
+		t.Errorf("no produce attempt expected, got %d", p.attempts)
+	}
+}
+
+func TestNew_Defaults(t *testing.T) {
+	a, err := New(Config{Brokers: []string{" broker1:9092 ", "", "broker2:9092"}})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	defer iox.DiscardClose(a)
+
+	if a.config.Topic != DefaultTopic {
+		t.Errorf("topic = %q, want %q", a.config.Topic, DefaultTopic)
+	}
+	if a.config.Timeout != DefaultTimeout {
+		t.Errorf("timeout = %s, want %s", a.config.Timeout, DefaultTimeout)
+	}
+	if len(a.config.Brokers) != 2 || a.config.Brokers[0] != "broker1:9092" {
+		t.Errorf("brokers = %v, want trimmed non-empty entries", a.config.Brokers)
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	dir := t.TempDir()
+	badCA := filepath.Join(dir, "bad.pem")
+	if err := os.WriteFile(badCA, []byte("not a cert"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{name: "no brokers", cfg: Config{}, wantErr: "at least one broker"},
+		{name: "blank brokers", cfg: Config{Brokers: []string{" ", ""}}, wantErr: "at least one broker"},
+		{name: "negative retries", cfg: Config{Brokers: []string{"b:9092"}, Retries: -1}, wantErr: "retries must be >= 0"},
+		{
+			name:    "sasl unknown mechanism",
+			cfg:     Config{Brokers: []string{"b:9092"}, SASL: &SASLConfig{Mechanism: "gssapi", Username: "u", Password: "p"}},
+			wantErr: "unsupported sasl mechanism",
+		},
+		{
+			name:    "sasl missing password",
+			cfg:     Config{Brokers: []string{"b:9092"}, SASL: &SASLConfig{Mechanism: MechanismPlain, Username: "u"}},
+			wantErr: "requires username and password",
+		},
+		{name: "missing ca file", cfg: Config{Brokers: []string{"b:9092"}, TLSCAFile: filepath.Join(dir, "missing.pem")}, wantErr: "read tls ca file"},
+		{name: "invalid ca file", cfg: Config{Brokers: []string{"b:9092"}, TLSCAFile: badCA}, wantErr: "no certificates found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg)
+			if err == nil {
+				t.Fatalf("expected error containing %q", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want substring %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNew_SASLMechanisms(t *testing.T) {
+	for _, mech := range []string{MechanismPlain, MechanismScramSHA256, "SCRAM-SHA-512"} {
+		t.Run(mech, func(t *testing.T) {
+			a, err := New(Config{
+				Brokers: []string{"b:9092"},
+				SASL:    &SASLConfig{Mechanism: mech, Username: "u", Password: "p"},
+				TLS:     true,
+			})
+			if err != nil {
+				t.Fatalf("new: %v", err)
+			}
+			iox.DiscardClose(a)
+		})
+	}
+}
+
+func TestClose_ClosesProducer(t *testing.T) {
+	p := &fakeProducer{}
+	a := newTestAdapter(t, Config{Brokers: []string{"localhost:9092"}}, p)
+	if err := a.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if !p.closed {
+		t.Error("producer should be closed")
+	}
+}
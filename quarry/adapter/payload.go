@@ -0,0 +1,175 @@
+package adapter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Encoder renders a RunCompletedEvent into the JSON body an adapter
+// publishes. Implementations must be safe for concurrent use.
+type Encoder interface {
+	Encode(event *RunCompletedEvent) ([]byte, error)
+}
+
+// Marshal encodes event with enc, or as the canonical JSON shape when enc
+// is nil. Adapters call it in place of json.Marshal.
+func Marshal(enc Encoder, event *RunCompletedEvent) ([]byte, error) {
+	if enc == nil {
+		return json.Marshal(event)
+	}
+	return enc.Encode(event)
+}
+
+// eventKeys lists the canonical JSON keys of RunCompletedEvent, including
+// omitempty keys, so templates and field maps can be checked up front.
+var eventKeys = func() map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeOf(RunCompletedEvent{})
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		keys[name] = true
+	}
+	return keys
+}()
+
+// eventData returns the canonical JSON shape of event as a map, with every
+// known key present (omitted keys are nil, labels an empty object).
+func eventData(event *RunCompletedEvent) (map[string]any, error) {
+	raw, err := json.Marshal(event)
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string]any, len(eventKeys))
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	for k := range eventKeys {
+		if _, ok := data[k]; !ok {
+			data[k] = nil
+		}
+	}
+	// Labels stay indexable on runs without labels: {{index .labels "env"}}
+	if data["labels"] == nil {
+		data["labels"] = map[string]any{}
+	}
+	return data, nil
+}
+
+// sampleEvent exercises every key when validating an encoder.
+var sampleEvent = &RunCompletedEvent{
+	ContractVersion: "0.0.0",
+	EventType:       EventTypeRunCompleted,
+	RunID:           "run-sample",
+	Source:          "source",
+	Category:        "default",
+	Day:             "2006-01-02",
+	Hour:            "15",
+	Outcome:         "script_error",
+	Reason:          "reason",
+	StoragePath:     "file:///sample",
+	Timestamp:       "2006-01-02T15:04:05Z",
+	JobID:           "job",
+	Attempt:         1,
+	Labels:          map[string]string{"env": "sample"},
+	MetricsPending:  true,
+	ErrorType:       "Error",
+	ErrorMessage:    "message",
+	ErrorStack:      "stack",
+	ArtifactID:      "artifact",
+	ArtifactName:    "name",
+	ContentType:     "application/octet-stream",
+	SizeBytes:       1,
+}
+
+// Template renders the event through a Go text/template. The template data
+// is the canonical JSON object, keyed by its JSON names ({{.run_id}}), and
+// the json function quotes a value as JSON ({{json .run_id}}). The output
+// must be a JSON document; it is compacted to a single line.
+type Template struct {
+	tmpl *template.Template
+}
+
+// NewTemplate parses text and renders it once against a sample event, so
+// syntax errors, unknown keys, and non-JSON output fail at config time.
+func NewTemplate(text string) (*Template, error) {
+	tmpl, err := template.New("payload").
+		Option("missingkey=error").
+		Funcs(template.FuncMap{"json": templateJSON}).
+		Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("payload template: %w", err)
+	}
+	t := &Template{tmpl: tmpl}
+	if _, err := t.Encode(sampleEvent); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Encode implements Encoder.
+func (t *Template) Encode(event *RunCompletedEvent) ([]byte, error) {
+	data, err := eventData(event)
+	if err != nil {
+		return nil, fmt.Errorf("payload template: %w", err)
+	}
+	var out bytes.Buffer
+	if err := t.tmpl.Execute(&out, data); err != nil {
+		return nil, fmt.Errorf("payload template: %w", err)
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, out.Bytes()); err != nil {
+		return nil, fmt.Errorf("payload template: output is not valid JSON: %w", err)
+	}
+	return compact.Bytes(), nil
+}
+
+func templateJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// FieldMap renders a flat JSON object whose keys are the map keys and whose
+// values are the canonical event fields they name, e.g. {"id": "run_id"}.
+// Fields absent from the event (omitempty) render as null.
+type FieldMap map[string]string
+
+// NewFieldMap validates that every source field names a RunCompletedEvent
+// JSON key.
+func NewFieldMap(fields map[string]string) (FieldMap, error) {
+	if len(fields) == 0 {
+		return nil, errors.New("payload field map is empty")
+	}
+	var unknown []string
+	for out, src := range fields {
+		if out == "" {
+			return nil, errors.New("payload field map: empty output key")
+		}
+		if !eventKeys[src] {
+			unknown = append(unknown, src)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("payload field map: unknown event field(s): %s", strings.Join(unknown, ", "))
+	}
+	return FieldMap(fields), nil
+}
+
+// Encode implements Encoder.
+func (m FieldMap) Encode(event *RunCompletedEvent) ([]byte, error) {
+	data, err := eventData(event)
+	if err != nil {
+		return nil, fmt.Errorf("payload field map: %w", err)
+	}
+	out := make(map[string]any, len(m))
+	for k, src := range m {
+		out[k] = data[src]
+	}
+	return json.Marshal(out)
+}
@@ -0,0 +1,118 @@
+package adapter
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func payloadTestEvent() *RunCompletedEvent {
+	return &RunCompletedEvent{
+		ContractVersion: "0.4.0",
+		EventType:       "run_completed",
+		RunID:           "run-001",
+		Source:          "shop",
+		Category:        "default",
+		Day:             "2026-02-07",
+		Outcome:         "success",
+		Timestamp:       "2026-02-07T12:00:00Z",
+		Attempt:         1,
+		EventCount:      42,
+		Labels:          map[string]string{"env": "prod"},
+	}
+}
+
+func TestMarshal_NilEncoderIsCanonical(t *testing.T) {
+	event := payloadTestEvent()
+	got, err := Marshal(nil, event)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	want, _ := json.Marshal(event)
+	if string(got) != string(want) {
+		t.Errorf("body = %s, want canonical %s", got, want)
+	}
+}
+
+func TestTemplate_Encode(t *testing.T) {
+	tmpl, err := NewTemplate(`{
+  "id": {{json .run_id}},
+  "status": {{json .outcome}},
+  "env": {{json .labels.env}},
+  "count": {{.event_count}},
+  "error": {{json .error_message}}
+}`)
+	if err != nil {
+		t.Fatalf("new template: %v", err)
+	}
+
+	got, err := tmpl.Encode(payloadTestEvent())
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	want := `{"id":"run-001","status":"success","env":"prod","count":42,"error":null}`
+	if string(got) != want {
+		t.Errorf("body = %s, want %s", got, want)
+	}
+}
+
+func TestTemplate_AbsentLabel(t *testing.T) {
+	tmpl, err := NewTemplate(`{"env": {{json (index .labels "env")}}}`)
+	if err != nil {
+		t.Fatalf("new template: %v", err)
+	}
+	event := payloadTestEvent()
+	event.Labels = nil
+
+	got, err := tmpl.Encode(event)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if string(got) != `{"env":null}` {
+		t.Errorf("body = %s, want null env", got)
+	}
+}
+
+func TestNewTemplate_Rejects(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "syntax error", text: `{"id": {{json .run_id}`, want: "payload template"},
+		{name: "unknown key", text: `{"id": {{json .runid}}}`, want: "runid"},
+		{name: "not JSON", text: `id={{.run_id}}`, want: "not valid JSON"},
+		{name: "unknown function", text: `{"id": {{yaml .run_id}}}`, want: "yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewTemplate(tt.text)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewTemplate error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldMap_Encode(t *testing.T) {
+	fm, err := NewFieldMap(map[string]string{"id": "run_id", "status": "outcome", "job": "job_id"})
+	if err != nil {
+		t.Fatalf("new field map: %v", err)
+	}
+
+	got, err := fm.Encode(payloadTestEvent())
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	want := `{"id":"run-001","job":null,"status":"success"}`
+	if string(got) != want {
+		t.Errorf("body = %s, want %s", got, want)
+	}
+}
+
+func TestNewFieldMap_RejectsUnknownField(t *testing.T) {
+	_, err := NewFieldMap(map[string]string{"id": "runid", "status": "state"})
+	if err == nil || !strings.Contains(err.Error(), "runid, state") {
+		t.Errorf("NewFieldMap error = %v, want unknown fields listed", err)
+	}
+}
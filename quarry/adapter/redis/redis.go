@@ -1,14 +1,16 @@
 // Package redis implements a Redis pub/sub adapter per CONTRACT_INTEGRATION.md.
 //
 // Publishes run completion events as JSON to a configurable Redis channel.
-// Retries with exponential backoff on connection errors.
+// Retries with exponential backoff on connection errors. One Adapter holds
+// one connection pool and is safe for concurrent publishes; with Pipeline,
+// publishes that arrive within PipelineWindow share a single round trip.
 package redis
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	goredis "github.com/redis/go-redis/v9"
@@ -25,6 +27,10 @@ const DefaultTimeout = 5 * time.Second
 // DefaultRetries is the default number of retry attempts.
 const DefaultRetries = 3
 
+// DefaultPipelineWindow is how long a pipelined publish waits for others
+// to join its batch.
+const DefaultPipelineWindow = 10 * time.Millisecond
+
 // Config configures the Redis pub/sub adapter.
 type Config struct {
 	// URL is the Redis connection URL (required).
@@ -36,12 +42,32 @@ type Config struct {
 	Timeout time.Duration
 	// Retries is the number of retry attempts on failure (default 3).
 	Retries int
+	// Pipeline batches concurrent publishes into one pipelined round trip.
+	// Retries of a failed pipelined publish are sent individually.
+	Pipeline bool
+	// PipelineWindow is the batching delay (default 10ms, Pipeline only).
+	PipelineWindow time.Duration
+	// Encoder renders the published body (default: canonical JSON).
+	Encoder adapter.Encoder
 }
 
 // Adapter publishes run completion events via Redis PUBLISH.
 type Adapter struct {
 	config Config
 	client *goredis.Client
+
+	// Pipeline batching state
+	mu       sync.Mutex
+	pending  []pipelinedPublish
+	timer    *time.Timer
+	flushing sync.WaitGroup
+	closed   bool
+}
+
+// pipelinedPublish is a publish waiting for its batch to be sent.
+type pipelinedPublish struct {
+	body []byte
+	done chan error
 }
 
 // New creates a Redis pub/sub adapter from the given config.
@@ -65,6 +91,9 @@ func New(cfg Config) (*Adapter, error) {
 	if cfg.Retries < 0 {
 		return nil, fmt.Errorf("retries must be >= 0, got %d", cfg.Retries)
 	}
+	if cfg.PipelineWindow <= 0 {
+		cfg.PipelineWindow = DefaultPipelineWindow
+	}
 
 	return &Adapter{
 		config: cfg,
@@ -75,7 +104,7 @@ func New(cfg Config) (*Adapter, error) {
 // Publish sends the event as a JSON PUBLISH to the configured channel.
 // Retries with exponential backoff on failures.
 func (a *Adapter) Publish(ctx context.Context, event *adapter.RunCompletedEvent) error {
-	body, err := json.Marshal(event)
+	body, err := adapter.Marshal(a.config.Encoder, event)
 	if err != nil {
 		return fmt.Errorf("redis: marshal event: %w", err)
 	}
@@ -99,9 +128,13 @@ func (a *Adapter) Publish(ctx context.Context, event *adapter.RunCompletedEvent)
 			}
 		}
 
-		publishCtx, cancel := context.WithTimeout(ctx, a.config.Timeout)
-		lastErr = a.client.Publish(publishCtx, a.config.Channel, body).Err()
-		cancel()
+		if i == 0 && a.config.Pipeline {
+			lastErr = a.publishPipelined(ctx, body)
+		} else {
+			publishCtx, cancel := context.WithTimeout(ctx, a.config.Timeout)
+			lastErr = a.client.Publish(publishCtx, a.config.Channel, body).Err()
+			cancel()
+		}
 
 		if lastErr == nil {
 			return nil
@@ -111,8 +144,80 @@ func (a *Adapter) Publish(ctx context.Context, event *adapter.RunCompletedEvent)
 	return fmt.Errorf("redis: failed after %d attempts: %w", attempts, lastErr)
 }
 
-// Close releases adapter resources.
+// publishPipelined queues body for the next pipeline batch and waits for
+// the batch result. The first publish of a batch schedules its flush.
+func (a *Adapter) publishPipelined(ctx context.Context, body []byte) error {
+	done := make(chan error, 1)
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return errors.New("redis: adapter closed")
+	}
+	a.pending = append(a.pending, pipelinedPublish{body: body, done: done})
+	if len(a.pending) == 1 {
+		a.flushing.Add(1)
+		a.timer = time.AfterFunc(a.config.PipelineWindow, func() {
+			defer a.flushing.Done()
+			a.flush(a.takePending())
+		})
+	}
+	a.mu.Unlock()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		// The batch may still deliver it; consumers dedupe on run_id
+		return fmt.Errorf("redis: context canceled awaiting pipeline: %w", ctx.Err())
+	}
+}
+
+// takePending detaches the current batch.
+func (a *Adapter) takePending() []pipelinedPublish {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	batch := a.pending
+	a.pending = nil
+	a.timer = nil
+	return batch
+}
+
+// flush sends batch as one pipeline and reports each command's result.
+func (a *Adapter) flush(batch []pipelinedPublish) {
+	if len(batch) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), a.config.Timeout)
+	defer cancel()
+
+	pipe := a.client.Pipeline()
+	cmds := make([]*goredis.IntCmd, len(batch))
+	for i, p := range batch {
+		cmds[i] = pipe.Publish(ctx, a.config.Channel, p.body)
+	}
+	// Exec's error repeats the first failed command; per-command errors below
+	_, _ = pipe.Exec(ctx)
+	for i, p := range batch {
+		p.done <- cmds[i].Err()
+	}
+}
+
+// Close sends any queued pipeline batch, then releases the connection pool.
 func (a *Adapter) Close() error {
+	a.mu.Lock()
+	a.closed = true
+	var batch []pipelinedPublish
+	if a.timer != nil && a.timer.Stop() {
+		// The scheduled flush will not run; send its batch here
+		batch = a.pending
+		a.pending = nil
+		a.timer = nil
+		a.flushing.Done()
+	}
+	a.mu.Unlock()
+
+	a.flush(batch)
+	a.flushing.Wait()
 	return a.client.Close()
 }
 
@@ -3,6 +3,8 @@ package redis
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -253,3 +255,109 @@ func TestClose_ClosesConnection(t *testing.T) {
 		t.Fatal("expected error after close")
 	}
 }
+
+// collectMessages reads n messages from the subscriber in the background.
+func collectMessages(sub *miniredis.Subscriber, n int) <-chan []miniredis.PubsubMessage {
+	ch := make(chan []miniredis.PubsubMessage, 1)
+	go func() {
+		msgs := make([]miniredis.PubsubMessage, 0, n)
+		for range n {
+			msgs = append(msgs, <-sub.Messages())
+		}
+		ch <- msgs
+	}()
+	return ch
+}
+
+func TestPublish_PipelinedConcurrent(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	a, err := New(Config{URL: "redis://" + mr.Addr(), Pipeline: true, PipelineWindow: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	defer iox.DiscardClose(a)
+
+	const n = 8
+	sub := mr.NewSubscriber()
+	sub.Subscribe(DefaultChannel)
+	ch := collectMessages(sub, n)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := range n {
+		wg.Go(func() {
+			event := testEvent()
+			event.RunID = fmt.Sprintf("run-%03d", i)
+			errs <- a.Publish(t.Context(), event)
+		})
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("publish: %v", err)
+		}
+	}
+
+	select {
+	case msgs := <-ch:
+		seen := make(map[string]bool)
+		for _, msg := range msgs {
+			var received adapter.RunCompletedEvent
+			if err := json.Unmarshal([]byte(msg.Message), &received); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			seen[received.RunID] = true
+		}
+		if len(seen) != n {
+			t.Errorf("received %d distinct events, want %d", len(seen), n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for pipelined messages")
+	}
+}
+
+func TestClose_FlushesPipelineBatch(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	// A window far longer than the test: only Close can send the batch
+	a, err := New(Config{URL: "redis://" + mr.Addr(), Pipeline: true, PipelineWindow: time.Hour})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	sub := mr.NewSubscriber()
+	sub.Subscribe(DefaultChannel)
+	ch := asyncReceive(sub)
+
+	published := make(chan error, 1)
+	go func() { published <- a.Publish(t.Context(), testEvent()) }()
+
+	// Wait until the publish is queued
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		a.mu.Lock()
+		queued := len(a.pending)
+		a.mu.Unlock()
+		if queued == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("publish was never queued")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := a.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := <-published; err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	waitMessage(t, ch)
+
+	if err := a.Publish(t.Context(), testEvent()); err == nil {
+		t.Fatal("expected error after close")
+	}
+}
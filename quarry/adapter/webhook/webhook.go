@@ -1,19 +1,28 @@
 // Package webhook implements an HTTP POST adapter per CONTRACT_INTEGRATION.md.
 //
 // Publishes run completion events as JSON to a configurable URL.
-// Retries with exponential backoff on transient failures.
+// Retries with exponential backoff on network errors and retryable status
+// codes (default: 429 and 5xx).
 package webhook
 
 import (
 	"bytes"
+	"compress/gzip"
 	"context"
-	"encoding/json"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/klauspost/compress/zstd"
+
 	"github.com/pithecene-io/quarry/adapter"
 	"github.com/pithecene-io/quarry/iox"
 )
@@ -24,6 +33,17 @@ const DefaultTimeout = 10 * time.Second
 // DefaultRetries is the default number of retry attempts.
 const DefaultRetries = 3
 
+// DefaultRetryStatus is the retry classification used when
+// Config.RetryStatus is empty: rate limiting and server errors. Every other
+// non-2xx status fails fast.
+var DefaultRetryStatus = []string{"429", "5xx"}
+
+// Body encodings for Config.Compress.
+const (
+	CompressGzip = "gzip"
+	CompressZstd = "zstd"
+)
+
 // Config configures the webhook adapter.
 type Config struct {
 	// URL is the HTTP endpoint to POST to (required).
@@ -34,16 +54,148 @@ type Config struct {
 	Timeout time.Duration
 	// Retries is the number of retry attempts on failure (default 3).
 	Retries int
+	// RetryStatus lists the HTTP statuses that are retried, as codes
+	// ("409") or classes ("5xx"). Empty means DefaultRetryStatus. Network
+	// errors are always retried.
+	RetryStatus []string
+	// ClientCert and ClientKey are PEM paths of a client certificate pair
+	// presented for mTLS. Both or neither must be set.
+	ClientCert string
+	ClientKey  string
+	// CAFile is an optional PEM CA bundle for the server certificate
+	// (default: system roots).
+	CAFile string
+	// Compress is the request body Content-Encoding: CompressGzip,
+	// CompressZstd, or empty for an uncompressed body (default).
+	Compress string
+	// Encoder renders the published body (default: canonical JSON).
+	Encoder adapter.Encoder
+	// EgressProxy routes requests through this HTTP(S) proxy, overriding
+	// HTTPS_PROXY / HTTP_PROXY / NO_PROXY. Nil uses the environment.
+	EgressProxy *url.URL
+}
+
+// TLSConfig builds the client TLS config from the mTLS settings, loading and
+// checking the files. Returns nil when no TLS settings are configured.
+func (c Config) TLSConfig() (*tls.Config, error) {
+	if c.ClientCert == "" && c.ClientKey == "" && c.CAFile == "" {
+		return nil, nil
+	}
+	if (c.ClientCert == "") != (c.ClientKey == "") {
+		return nil, errors.New("webhook adapter: client cert and client key must be set together")
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.ClientCert != "" {
+		pair, err := tls.LoadX509KeyPair(c.ClientCert, c.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("webhook adapter: load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{pair}
+	}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("webhook adapter: read ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("webhook adapter: no certificates found in %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// ValidateCompress reports whether name is a supported body encoding.
+func ValidateCompress(name string) error {
+	switch name {
+	case "", CompressGzip, CompressZstd:
+		return nil
+	default:
+		return fmt.Errorf("webhook adapter: unsupported compression %q (supported: gzip, zstd)", name)
+	}
+}
+
+// retryStatus is a parsed Config.RetryStatus.
+type retryStatus struct {
+	codes   map[int]bool
+	classes map[int]bool // leading digit of an "Nxx" class
+	specs   []string     // normalized, for error messages
+}
+
+// parseRetryStatus parses status codes (100-599) and classes ("4xx").
+// Empty specs parse as DefaultRetryStatus.
+func parseRetryStatus(specs []string) (retryStatus, error) {
+	if len(specs) == 0 {
+		specs = DefaultRetryStatus
+	}
+	rs := retryStatus{codes: make(map[int]bool), classes: make(map[int]bool)}
+	for _, spec := range specs {
+		spec = strings.ToLower(strings.TrimSpace(spec))
+		if len(spec) == 3 && strings.HasSuffix(spec, "xx") && spec[0] >= '1' && spec[0] <= '5' {
+			rs.classes[int(spec[0]-'0')] = true
+			rs.specs = append(rs.specs, spec)
+			continue
+		}
+		code, err := strconv.Atoi(spec)
+		if err != nil || code < 100 || code > 599 {
+			return rs, fmt.Errorf("webhook adapter: invalid retry status %q (want a code like 429 or a class like 5xx)", spec)
+		}
+		rs.codes[code] = true
+		rs.specs = append(rs.specs, spec)
+	}
+	return rs, nil
+}
+
+// retryable reports whether a response with the status should be retried.
+func (rs retryStatus) retryable(code int) bool {
+	return rs.codes[code] || rs.classes[code/100]
+}
+
+// ValidateRetryStatus reports whether every entry is a status code or class.
+func ValidateRetryStatus(specs []string) error {
+	_, err := parseRetryStatus(specs)
+	return err
+}
+
+// compress encodes body with the named encoding. Empty name returns body.
+func compress(name string, body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var zw io.WriteCloser
+	switch name {
+	case "":
+		return body, nil
+	case CompressGzip:
+		zw = gzip.NewWriter(&buf)
+	case CompressZstd:
+		w, err := zstd.NewWriter(&buf)
+		if err != nil {
+			return nil, err
+		}
+		zw = w
+	default:
+		return nil, ValidateCompress(name)
+	}
+	if _, err := zw.Write(body); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
 // Adapter publishes run completion events via HTTP POST.
 type Adapter struct {
-	config Config
-	client *http.Client
+	config      Config
+	retryStatus retryStatus
+	client      *http.Client
 }
 
 // New creates a webhook adapter from the given config.
-// Returns an error if the URL is empty.
+// Returns an error if the URL is empty, or the TLS settings or retry
+// statuses are invalid.
 func New(cfg Config) (*Adapter, error) {
 	if cfg.URL == "" {
 		return nil, errors.New("webhook adapter requires a URL")
@@ -54,21 +206,52 @@ func New(cfg Config) (*Adapter, error) {
 	if cfg.Retries < 0 {
 		return nil, fmt.Errorf("retries must be >= 0, got %d", cfg.Retries)
 	}
+	if err := ValidateCompress(cfg.Compress); err != nil {
+		return nil, err
+	}
+	rs, err := parseRetryStatus(cfg.RetryStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsCfg, err := cfg.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: cfg.Timeout}
+	if tlsCfg != nil || cfg.EgressProxy != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if tlsCfg != nil {
+			transport.TLSClientConfig = tlsCfg
+		}
+		if cfg.EgressProxy != nil {
+			transport.Proxy = http.ProxyURL(cfg.EgressProxy)
+		}
+		client.Transport = transport
+	}
 
 	return &Adapter{
-		config: cfg,
-		client: &http.Client{Timeout: cfg.Timeout},
+		config:      cfg,
+		retryStatus: rs,
+		client:      client,
 	}, nil
 }
 
 // Publish sends the event as a JSON POST request.
-// Retries with exponential backoff on 5xx responses and network errors.
-// 4xx responses are non-retriable and fail immediately.
+// Retries with exponential backoff on network errors and on statuses in
+// Config.RetryStatus (default 429 and 5xx). Any other non-2xx status is
+// non-retriable and fails immediately.
+// With Config.Compress set, the body is compressed once and reused by
+// every attempt.
 func (a *Adapter) Publish(ctx context.Context, event *adapter.RunCompletedEvent) error {
-	body, err := json.Marshal(event)
+	body, err := adapter.Marshal(a.config.Encoder, event)
 	if err != nil {
 		return fmt.Errorf("webhook: marshal event: %w", err)
 	}
+	body, err = compress(a.config.Compress, body)
+	if err != nil {
+		return fmt.Errorf("webhook: compress body: %w", err)
+	}
 
 	var lastErr error
 	// attempts = 1 initial + retries
@@ -94,10 +277,11 @@ func (a *Adapter) Publish(ctx context.Context, event *adapter.RunCompletedEvent)
 			return nil
 		}
 
-		// 4xx errors are non-retriable — stop immediately
+		// Statuses outside the retry set are non-retriable — stop immediately
 		var statusErr *StatusError
-		if errors.As(lastErr, &statusErr) && statusErr.Code >= 400 && statusErr.Code < 500 {
-			return fmt.Errorf("webhook: non-retriable error: %w", lastErr)
+		if errors.As(lastErr, &statusErr) && !a.retryStatus.retryable(statusErr.Code) {
+			return fmt.Errorf("webhook: non-retriable status %d (retried: %s): %w",
+				statusErr.Code, strings.Join(a.retryStatus.specs, ", "), lastErr)
 		}
 	}
 
@@ -105,8 +289,8 @@ func (a *Adapter) Publish(ctx context.Context, event *adapter.RunCompletedEvent)
 }
 
 // StatusError is returned for non-2xx HTTP responses.
-// Wrapping the status code allows callers to distinguish retriable (5xx)
-// from non-retriable (4xx) failures.
+// Wrapping the status code allows callers to distinguish retriable (by
+// default 429 and 5xx) from non-retriable failures.
 type StatusError struct {
 	Code int
 }
@@ -126,6 +310,10 @@ func (a *Adapter) doRequest(ctx context.Context, body []byte) error {
 	for k, v := range a.config.Headers {
 		req.Header.Set(k, v)
 	}
+	if a.config.Compress != "" {
+		// After custom headers: the encoding must match the body
+		req.Header.Set("Content-Encoding", a.config.Compress)
+	}
 
 	resp, err := a.client.Do(req)
 	if err != nil {
@@ -1,15 +1,32 @@
 package webhook
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
+	"fmt"
 	"io"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/klauspost/compress/zstd"
+
 	"github.com/pithecene-io/quarry/adapter"
 	"github.com/pithecene-io/quarry/iox"
 )
@@ -97,6 +114,97 @@ func TestPublish_CustomHeaders(t *testing.T) {
 	}
 }
 
+func TestPublish_TemplatedBody(t *testing.T) {
+	var body []byte
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	enc, err := adapter.NewTemplate(`{"id": {{json .run_id}}, "status": {{json .outcome}}}`)
+	if err != nil {
+		t.Fatalf("template: %v", err)
+	}
+	a, err := New(Config{URL: ts.URL, Encoder: enc})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	defer iox.DiscardClose(a)
+
+	if err := a.Publish(t.Context(), testEvent()); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+	if string(body) != `{"id":"run-001","status":"success"}` {
+		t.Errorf("body = %s, want templated shape", body)
+	}
+}
+
+func TestPublish_Compressed(t *testing.T) {
+	decoders := map[string]func(io.Reader) (io.Reader, error){
+		CompressGzip: func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		CompressZstd: func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) },
+	}
+	for name, decode := range decoders {
+		t.Run(name, func(t *testing.T) {
+			var bodies [][]byte
+			var encodings []string
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				bodies = append(bodies, body)
+				encodings = append(encodings, r.Header.Get("Content-Encoding"))
+				if len(bodies) == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer ts.Close()
+
+			a, err := New(Config{URL: ts.URL, Retries: 1, Compress: name})
+			if err != nil {
+				t.Fatalf("new: %v", err)
+			}
+			defer iox.DiscardClose(a)
+
+			if err := a.Publish(t.Context(), testEvent()); err != nil {
+				t.Fatalf("publish: %v", err)
+			}
+
+			if len(bodies) != 2 {
+				t.Fatalf("expected 2 attempts, got %d", len(bodies))
+			}
+			if !bytes.Equal(bodies[0], bodies[1]) {
+				t.Error("retry must resend the same compressed body")
+			}
+			for i, enc := range encodings {
+				if enc != name {
+					t.Errorf("attempt %d Content-Encoding = %q, want %q", i+1, enc, name)
+				}
+			}
+
+			r, err := decode(bytes.NewReader(bodies[1]))
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			var received adapter.RunCompletedEvent
+			if err := json.NewDecoder(r).Decode(&received); err != nil {
+				t.Fatalf("unmarshal: %v", err)
+			}
+			if received.RunID != "run-001" {
+				t.Errorf("expected run-001, got %s", received.RunID)
+			}
+		})
+	}
+}
+
+func TestNew_RejectsUnknownCompress(t *testing.T) {
+	_, err := New(Config{URL: "http://localhost", Compress: "br"})
+	if err == nil || !strings.Contains(err.Error(), "unsupported compression") {
+		t.Errorf("expected unsupported compression error, got %v", err)
+	}
+}
+
 func TestPublish_RetriesOnFailure(t *testing.T) {
 	var attempts atomic.Int32
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
@@ -288,3 +396,231 @@ func TestPublish_5xxRetriesAndFails(t *testing.T) {
 		})
 	}
 }
+
+func TestPublish_429Retries(t *testing.T) {
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+
+	a, err := New(Config{URL: ts.URL, Retries: 1})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	defer iox.DiscardClose(a)
+
+	if err := a.Publish(t.Context(), testEvent()); err != nil {
+		t.Fatalf("expected 429 to be retried, got %v", err)
+	}
+	if got := attempts.Load(); got != 2 {
+		t.Errorf("expected 2 attempts, got %d", got)
+	}
+}
+
+func TestPublish_RetryStatusOverride(t *testing.T) {
+	tests := []struct {
+		code     int
+		attempts int32
+	}{
+		{http.StatusConflict, 2},        // listed code retries
+		{http.StatusBadGateway, 2},      // listed class retries
+		{http.StatusNotFound, 1},        // unlisted 4xx fails fast
+		{http.StatusTooManyRequests, 1}, // override replaces the default set
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.code), func(t *testing.T) {
+			var attempts atomic.Int32
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				attempts.Add(1)
+				w.WriteHeader(tt.code)
+			}))
+			defer ts.Close()
+
+			a, err := New(Config{URL: ts.URL, Retries: 1, RetryStatus: []string{"409", "5xx"}})
+			if err != nil {
+				t.Fatalf("new: %v", err)
+			}
+			defer iox.DiscardClose(a)
+
+			err = a.Publish(t.Context(), testEvent())
+			if err == nil {
+				t.Fatalf("expected error for %d", tt.code)
+			}
+			if got := attempts.Load(); got != tt.attempts {
+				t.Errorf("expected %d attempts for %d, got %d", tt.attempts, tt.code, got)
+			}
+			if tt.attempts == 1 && !strings.Contains(err.Error(), fmt.Sprintf("non-retriable status %d (retried: 409, 5xx)", tt.code)) {
+				t.Errorf("error should name the status and retry set, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateRetryStatus(t *testing.T) {
+	for _, ok := range [][]string{nil, {"429", "5xx"}, {" 4XX "}, {"100"}} {
+		if err := ValidateRetryStatus(ok); err != nil {
+			t.Errorf("ValidateRetryStatus(%q): %v", ok, err)
+		}
+	}
+	for _, bad := range [][]string{{"6xx"}, {"99"}, {"600"}, {"abc"}, {"5x"}, {""}} {
+		if err := ValidateRetryStatus(bad); err == nil {
+			t.Errorf("ValidateRetryStatus(%q) should fail", bad)
+		}
+	}
+	if _, err := New(Config{URL: "http://example.com", RetryStatus: []string{"6xx"}}); err == nil {
+		t.Error("New should reject an invalid retry status")
+	}
+}
+
+// writeTestCert writes a self-signed ECDSA certificate and key as PEM files
+// in dir and returns their paths.
+func writeTestCert(t *testing.T, dir, name string) (certPath, keyPath string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	certPath = filepath.Join(dir, name+".crt")
+	keyPath = filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("write cert: %v", err)
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+	return certPath, keyPath
+}
+
+func TestNew_TLSValidation(t *testing.T) {
+	dir := t.TempDir()
+	certA, keyA := writeTestCert(t, dir, "a")
+	_, keyB := writeTestCert(t, dir, "b")
+	badCA := filepath.Join(dir, "bad-ca.pem")
+	if err := os.WriteFile(badCA, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr string
+	}{
+		{name: "cert without key", cfg: Config{ClientCert: certA}, wantErr: "must be set together"},
+		{name: "key without cert", cfg: Config{ClientKey: keyA}, wantErr: "must be set together"},
+		{name: "mismatched pair", cfg: Config{ClientCert: certA, ClientKey: keyB}, wantErr: "load client certificate"},
+		{name: "missing ca file", cfg: Config{CAFile: filepath.Join(dir, "missing.pem")}, wantErr: "read ca file"},
+		{name: "invalid ca file", cfg: Config{CAFile: badCA}, wantErr: "no certificates found"},
+		{name: "valid pair and ca", cfg: Config{ClientCert: certA, ClientKey: keyA, CAFile: certA}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.URL = "https://example.com/hook"
+			_, err := New(tt.cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPublish_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, clientKey := writeTestCert(t, dir, "client")
+	clientPEM, err := os.ReadFile(clientCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(clientPEM)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != "client" {
+			t.Errorf("expected client certificate, got %v", r.TLS.PeerCertificates)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	ts.StartTLS()
+	defer ts.Close()
+
+	// Trust the test server's certificate via the CA bundle
+	caPath := filepath.Join(dir, "server-ca.pem")
+	if err := os.WriteFile(caPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := New(Config{URL: ts.URL, Retries: 0, ClientCert: clientCert, ClientKey: clientKey, CAFile: caPath})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	defer iox.DiscardClose(a)
+	if err := a.Publish(t.Context(), testEvent()); err != nil {
+		t.Fatalf("publish with client cert: %v", err)
+	}
+
+	// Without the client certificate the server rejects the handshake
+	noCert, err := New(Config{URL: ts.URL, Retries: 0, CAFile: caPath})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	defer iox.DiscardClose(noCert)
+	if err := noCert.Publish(t.Context(), testEvent()); err == nil {
+		t.Error("expected publish without client certificate to fail")
+	}
+}
+
+func TestPublish_EgressProxy(t *testing.T) {
+	var proxied atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy receives the absolute target URL
+		proxied.Store(r.URL.String())
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := New(Config{URL: "http://hooks.internal.invalid/quarry", Retries: 0, EgressProxy: proxyURL})
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	defer iox.DiscardClose(a)
+	if err := a.Publish(t.Context(), testEvent()); err != nil {
+		t.Fatalf("publish via proxy: %v", err)
+	}
+	if got, _ := proxied.Load().(string); got != "http://hooks.internal.invalid/quarry" {
+		t.Errorf("proxy saw %q, want the webhook URL", got)
+	}
+}
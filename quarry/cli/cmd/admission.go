@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pithecene-io/quarry/iox"
+)
+
+// admissionPollInterval is how often a waiting run retries the slot locks.
+// A var so tests can shorten it.
+var admissionPollInterval = 250 * time.Millisecond
+
+// errSlotHeld is returned by tryLockSlot when another process holds the slot.
+var errSlotHeld = errors.New("admission slot held")
+
+// defaultAdmissionLock is the --admission-lock default, shared by every
+// quarry process on the host that does not set its own lock path.
+func defaultAdmissionLock() string {
+	return filepath.Join(os.TempDir(), "quarry-admission.lock")
+}
+
+// admissionSlot is one held --admission-slots slot: an exclusive flock on
+// the slot file <lock>.<index>. The kernel drops the lock when the process
+// exits, so a crashed run never leaks its slot. A nil admissionSlot is
+// disabled admission.
+type admissionSlot struct {
+	file  *os.File
+	index int
+}
+
+// acquireAdmission waits until one of slots slot files under lockPath can
+// be locked, polling every admissionPollInterval. Waiting ends early when
+// ctx is canceled (e.g. SIGINT) or after timeout (0 = no limit). Returns
+// nil when slots is 0.
+func acquireAdmission(ctx context.Context, lockPath string, slots int, timeout time.Duration, quiet bool) (*admissionSlot, error) {
+	if slots <= 0 {
+		return nil, nil
+	}
+	slot, err := tryAdmission(lockPath, slots)
+	if slot != nil || err != nil {
+		return slot, err
+	}
+
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "Waiting for an admission slot (%d slots, lock %s)...\n", slots, lockPath)
+	}
+	waitStart := time.Now()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	ticker := time.NewTicker(admissionPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, fmt.Errorf("no admission slot free after %s", timeout)
+			}
+			return nil, fmt.Errorf("waiting for an admission slot: %w", context.Cause(ctx))
+		case <-ticker.C:
+		}
+		slot, err := tryAdmission(lockPath, slots)
+		if err != nil {
+			return nil, err
+		}
+		if slot != nil {
+			if !quiet {
+				fmt.Fprintf(os.Stderr, "Admitted to slot %d after %s\n", slot.index, time.Since(waitStart).Round(time.Millisecond))
+			}
+			return slot, nil
+		}
+	}
+}
+
+// tryAdmission makes one non-blocking pass over the slot files. Returns
+// nil, nil when every slot is held.
+func tryAdmission(lockPath string, slots int) (*admissionSlot, error) {
+	for i := range slots {
+		path := fmt.Sprintf("%s.%d", lockPath, i)
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("opening admission lock: %w", err)
+		}
+		err = tryLockSlot(f)
+		if err == nil {
+			return &admissionSlot{file: f, index: i}, nil
+		}
+		iox.DiscardClose(f)
+		if !errors.Is(err, errSlotHeld) {
+			return nil, fmt.Errorf("locking %s: %w", path, err)
+		}
+	}
+	return nil, nil
+}
+
+// release frees the slot for the next waiting run. Nil-receiver safe.
+func (s *admissionSlot) release() {
+	if s == nil {
+		return
+	}
+	unlockSlot(s.file)
+	iox.DiscardClose(s.file)
+}
@@ -0,0 +1,20 @@
+//go:build !unix
+
+package cmd
+
+import (
+	"errors"
+	"os"
+)
+
+// errAdmissionUnsupported is returned for --admission-slots on platforms
+// without flock.
+var errAdmissionUnsupported = errors.New("--admission-slots is only supported on unix platforms")
+
+// tryLockSlot always fails: admission slots rely on flock.
+func tryLockSlot(*os.File) error {
+	return errAdmissionUnsupported
+}
+
+// unlockSlot is a no-op; tryLockSlot never succeeds here.
+func unlockSlot(*os.File) {}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAcquireAdmission_Slots(t *testing.T) {
+	lock := filepath.Join(t.TempDir(), "quarry.lock")
+	ctx := t.Context()
+
+	// Each acquire opens its own file description, so flocks conflict
+	// within one process exactly as they do across processes.
+	first, err := acquireAdmission(ctx, lock, 2, 0, true)
+	if err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+	second, err := acquireAdmission(ctx, lock, 2, 0, true)
+	if err != nil {
+		t.Fatalf("second acquire: %v", err)
+	}
+	if first.index == second.index {
+		t.Fatalf("both runs admitted to slot %d", first.index)
+	}
+	if slot, err := tryAdmission(lock, 2); slot != nil || err != nil {
+		t.Fatalf("tryAdmission with all slots held = %v, %v; want nil, nil", slot, err)
+	}
+
+	first.release()
+	third, err := tryAdmission(lock, 2)
+	if err != nil || third == nil {
+		t.Fatalf("tryAdmission after release = %v, %v; want a slot", third, err)
+	}
+	if third.index != first.index {
+		t.Errorf("admitted to slot %d, want freed slot %d", third.index, first.index)
+	}
+	second.release()
+	third.release()
+}
+
+func TestAcquireAdmission_WaitsForRelease(t *testing.T) {
+	restore := admissionPollInterval
+	admissionPollInterval = 10 * time.Millisecond
+	defer func() { admissionPollInterval = restore }()
+
+	lock := filepath.Join(t.TempDir(), "quarry.lock")
+	held, err := acquireAdmission(t.Context(), lock, 1, 0, true)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	time.AfterFunc(50*time.Millisecond, held.release)
+
+	slot, err := acquireAdmission(t.Context(), lock, 1, 5*time.Second, true)
+	if err != nil {
+		t.Fatalf("waiting acquire: %v", err)
+	}
+	slot.release()
+}
+
+func TestAcquireAdmission_Timeout(t *testing.T) {
+	restore := admissionPollInterval
+	admissionPollInterval = 10 * time.Millisecond
+	defer func() { admissionPollInterval = restore }()
+
+	lock := filepath.Join(t.TempDir(), "quarry.lock")
+	held, err := acquireAdmission(t.Context(), lock, 1, 0, true)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	defer held.release()
+
+	_, err = acquireAdmission(t.Context(), lock, 1, 30*time.Millisecond, true)
+	if err == nil || !strings.Contains(err.Error(), "no admission slot free after 30ms") {
+		t.Errorf("err = %v, want a timeout error", err)
+	}
+}
+
+func TestAcquireAdmission_Canceled(t *testing.T) {
+	lock := filepath.Join(t.TempDir(), "quarry.lock")
+	held, err := acquireAdmission(t.Context(), lock, 1, 0, true)
+	if err != nil {
+		t.Fatalf("acquire: %v", err)
+	}
+	defer held.release()
+
+	interrupted := errors.New("interrupted")
+	ctx, cancel := context.WithCancelCause(t.Context())
+	cancel(interrupted)
+	_, err = acquireAdmission(ctx, lock, 1, 0, true)
+	if !errors.Is(err, interrupted) {
+		t.Errorf("err = %v, want the context's cancel cause", err)
+	}
+}
+
+func TestAcquireAdmission_Disabled(t *testing.T) {
+	slot, err := acquireAdmission(t.Context(), filepath.Join(t.TempDir(), "missing", "quarry.lock"), 0, 0, true)
+	if slot != nil || err != nil {
+		t.Fatalf("acquireAdmission(0 slots) = %v, %v; want nil, nil", slot, err)
+	}
+	slot.release() // nil-safe
+}
+
+func TestRunAction_NegativeAdmissionSlots(t *testing.T) {
+	dir := t.TempDir()
+	storageDir := filepath.Join(dir, "data")
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := newTestApp().Run([]string{"quarry", "run",
+		"--script", "./test.ts",
+		"--run-id", "run-001",
+		"--source", "test",
+		"--storage-backend", "fs",
+		"--storage-path", storageDir,
+		"--admission-slots", "-1",
+	})
+	if err == nil || !strings.Contains(err.Error(), "--admission-slots must be >= 0") {
+		t.Errorf("err = %v, want a negative-slots error", err)
+	}
+}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package cmd
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// tryLockSlot takes a non-blocking exclusive flock on a slot file.
+// Returns errSlotHeld when another process holds it.
+func tryLockSlot(f *os.File) error {
+	err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return errSlotHeld
+	}
+	return err
+}
+
+// unlockSlot releases the flock taken by tryLockSlot.
+func unlockSlot(f *os.File) {
+	_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pithecene-io/quarry/adapter"
+	"github.com/pithecene-io/quarry/runtime"
+	"github.com/pithecene-io/quarry/types"
+)
+
+// artifactEventQueueSize bounds artifact_committed events awaiting publish.
+// Events arriving while the queue is full are dropped, never blocking
+// ingestion.
+const artifactEventQueueSize = 256
+
+// artifactPublisher publishes an artifact_committed adapter event per
+// committed artifact (--adapter-on-artifact). Events are published in
+// commit order by one background worker through the shared adapter, so the
+// ingestion loop never waits on the bus. At most rate events per second are
+// accepted process-wide (0 = unlimited); the excess is dropped and counted.
+// A nil artifactPublisher publishes nothing.
+type artifactPublisher struct {
+	adapter *sharedAdapter
+	storage storageChoice
+	dataset string
+	rate    int
+
+	queue   chan artifactEventItem
+	done    chan struct{}
+	dropped atomic.Int64
+
+	mu          sync.Mutex
+	windowStart time.Time
+	windowCount int
+
+	closeOnce sync.Once
+}
+
+// artifactEventItem is a queued event and the run waiting on its publish.
+type artifactEventItem struct {
+	event   *adapter.RunCompletedEvent
+	pending *sync.WaitGroup
+}
+
+// newArtifactPublisher starts the publisher when choice enables
+// --adapter-on-artifact; otherwise it returns nil.
+func newArtifactPublisher(notifier *sharedAdapter, choice *adapterChoice, storage storageChoice, dataset string) *artifactPublisher {
+	if notifier == nil || choice == nil || !choice.onArtifact {
+		return nil
+	}
+	p := &artifactPublisher{
+		adapter: notifier,
+		storage: storage,
+		dataset: dataset,
+		rate:    choice.artifactRate,
+		queue:   make(chan artifactEventItem, artifactEventQueueSize),
+		done:    make(chan struct{}),
+	}
+	go p.work()
+	return p
+}
+
+// work publishes queued events until the queue is closed.
+func (p *artifactPublisher) work() {
+	defer close(p.done)
+	for item := range p.queue {
+		p.publish(item.event)
+		item.pending.Done()
+	}
+}
+
+// publish sends one event. Failures are warnings, like run_completed.
+func (p *artifactPublisher) publish(event *adapter.RunCompletedEvent) {
+	adpt, err := p.adapter.get()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: adapter creation failed: %v\n", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), p.adapter.choice.timeout)
+	defer cancel()
+	if err := adpt.Publish(ctx, event); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: artifact_committed notification failed for %s/%s: %v\n", event.RunID, event.ArtifactID, err)
+	}
+}
+
+// allow reports whether the rate limit admits one more event now, using a
+// fixed one-second window.
+func (p *artifactPublisher) allow(now time.Time) bool {
+	if p.rate <= 0 {
+		return true
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if now.Sub(p.windowStart) >= time.Second {
+		p.windowStart, p.windowCount = now, 0
+	}
+	if p.windowCount >= p.rate {
+		return false
+	}
+	p.windowCount++
+	return true
+}
+
+// artifactRun is one run's view of the publisher: it stamps events with the
+// run's identity and partition, and tracks the run's unpublished events.
+type artifactRun struct {
+	pub      *artifactPublisher
+	meta     *types.RunMeta
+	source   string
+	category string
+	day      string
+	hour     string
+	pending  sync.WaitGroup
+}
+
+// forRun returns the per-run handle for meta. Nil-safe.
+func (p *artifactPublisher) forRun(meta *types.RunMeta, source, category, day, hour string) *artifactRun {
+	if p == nil {
+		return nil
+	}
+	return &artifactRun{pub: p, meta: meta, source: source, category: category, day: day, hour: hour}
+}
+
+// observer returns the run's runtime.ArtifactObserver (nil when disabled).
+func (r *artifactRun) observer() runtime.ArtifactObserver {
+	if r == nil {
+		return nil
+	}
+	return r.observe
+}
+
+// observe enqueues an artifact_committed event without blocking.
+func (r *artifactRun) observe(commit runtime.ArtifactCommit) {
+	if !r.pub.allow(time.Now()) {
+		r.pub.dropped.Add(1)
+		return
+	}
+	r.pending.Add(1)
+	select {
+	case r.pub.queue <- artifactEventItem{event: r.event(commit), pending: &r.pending}:
+	default:
+		r.pending.Done()
+		r.pub.dropped.Add(1)
+	}
+}
+
+// event builds the artifact_committed adapter event for commit.
+func (r *artifactRun) event(commit runtime.ArtifactCommit) *adapter.RunCompletedEvent {
+	event := &adapter.RunCompletedEvent{
+		ContractVersion: types.ContractVersion,
+		EventType:       adapter.EventTypeArtifactCommitted,
+		RunID:           r.meta.RunID,
+		Source:          r.source,
+		Category:        r.category,
+		Day:             r.day,
+		Hour:            r.hour,
+		StoragePath:     buildStoragePath(r.pub.storage, r.pub.dataset, r.source, r.category, r.day, r.hour, r.meta.RunID) + "/event_type=artifact",
+		Timestamp:       time.Now().UTC().Format(time.RFC3339),
+		Attempt:         r.meta.Attempt,
+		Labels:          r.meta.Labels,
+		ArtifactID:      commit.ArtifactID,
+		ArtifactName:    commit.Name,
+		ContentType:     commit.ContentType,
+		SizeBytes:       commit.SizeBytes,
+	}
+	if r.meta.JobID != nil {
+		event.JobID = *r.meta.JobID
+	}
+	return event
+}
+
+// wait blocks until the run's queued events are published, so they precede
+// its run_completed event. Nil-safe. Call after the run's ingestion ends.
+func (r *artifactRun) wait() {
+	if r != nil {
+		r.pending.Wait()
+	}
+}
+
+// Close drains the queue and warns about dropped events. Idempotent.
+func (p *artifactPublisher) Close() error {
+	if p == nil {
+		return nil
+	}
+	p.closeOnce.Do(func() {
+		close(p.queue)
+		<-p.done
+		if n := p.dropped.Load(); n > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: dropped %d artifact_committed event(s) (--adapter-artifact-rate or queue full)\n", n)
+		}
+	})
+	return nil
+}
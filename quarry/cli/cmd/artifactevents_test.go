@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pithecene-io/quarry/adapter"
+	fileadapter "github.com/pithecene-io/quarry/adapter/file"
+	"github.com/pithecene-io/quarry/runtime"
+	"github.com/pithecene-io/quarry/types"
+)
+
+// newFileArtifactPublisher starts a publisher over a file adapter outbox.
+func newFileArtifactPublisher(t *testing.T, rate int) (*artifactPublisher, string) {
+	t.Helper()
+	outbox := filepath.Join(t.TempDir(), "outbox.jsonl")
+	choice := &adapterChoice{
+		adapterType:  "file",
+		url:          outbox,
+		timeout:      5 * time.Second,
+		fileMaxBytes: fileadapter.DefaultMaxBytes,
+		onArtifact:   true,
+		artifactRate: rate,
+	}
+	notifier := newSharedAdapter(choice)
+	t.Cleanup(func() { _ = notifier.Close() })
+	storage := storageChoice{backend: "s3", path: "bucket/prefix"}
+	return newArtifactPublisher(notifier, choice, storage, "quarry"), outbox
+}
+
+// readOutbox decodes every event in the file adapter outbox.
+func readOutbox(t *testing.T, path string) []adapter.RunCompletedEvent {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read outbox: %v", err)
+	}
+	var events []adapter.RunCompletedEvent
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var event adapter.RunCompletedEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("decode %q: %v", line, err)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+func TestArtifactPublisher_PublishesCommits(t *testing.T) {
+	pub, outbox := newFileArtifactPublisher(t, 0)
+	jobID := "job-1"
+	run := pub.forRun(&types.RunMeta{RunID: "run-001", Attempt: 2, JobID: &jobID}, "src", "cat", "2026-10-15", "")
+
+	observe := run.observer()
+	observe(runtime.ArtifactCommit{ArtifactID: "a1", Name: "shot.png", ContentType: "image/png", SizeBytes: 42})
+	observe(runtime.ArtifactCommit{ArtifactID: "a2", Name: "page.html", ContentType: "text/html", SizeBytes: 7})
+	run.wait()
+
+	events := readOutbox(t, outbox)
+	if len(events) != 2 {
+		t.Fatalf("events = %d, want 2", len(events))
+	}
+	got := events[0]
+	if got.EventType != adapter.EventTypeArtifactCommitted || got.ArtifactID != "a1" || got.ArtifactName != "shot.png" ||
+		got.ContentType != "image/png" || got.SizeBytes != 42 || got.JobID != "job-1" || got.Attempt != 2 {
+		t.Errorf("event = %+v", got)
+	}
+	wantPath := "s3://bucket/prefix/datasets/quarry/partitions/source=src/category=cat/day=2026-10-15/run_id=run-001/event_type=artifact"
+	if got.StoragePath != wantPath {
+		t.Errorf("StoragePath = %q, want %q", got.StoragePath, wantPath)
+	}
+	if got.Outcome != "" {
+		t.Errorf("Outcome = %q, want empty for artifact events", got.Outcome)
+	}
+	if events[1].ArtifactID != "a2" {
+		t.Errorf("second event = %s, want a2 (commit order)", events[1].ArtifactID)
+	}
+
+	if err := pub.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func TestArtifactPublisher_RateLimitDrops(t *testing.T) {
+	pub, outbox := newFileArtifactPublisher(t, 2)
+	run := pub.forRun(&types.RunMeta{RunID: "run-001", Attempt: 1}, "src", "cat", "2026-10-15", "")
+
+	for i := range 5 {
+		run.observe(runtime.ArtifactCommit{ArtifactID: string(rune('a' + i))})
+	}
+	run.wait()
+
+	if events := readOutbox(t, outbox); len(events) != 2 {
+		t.Errorf("published = %d, want 2 within the one-second window", len(events))
+	}
+	if got := pub.dropped.Load(); got != 3 {
+		t.Errorf("dropped = %d, want 3", got)
+	}
+}
+
+func TestArtifactPublisher_Disabled(t *testing.T) {
+	choice := &adapterChoice{adapterType: "file"}
+	pub := newArtifactPublisher(newSharedAdapter(choice), choice, storageChoice{}, "quarry")
+	if pub != nil {
+		t.Fatal("expected nil publisher without --adapter-on-artifact")
+	}
+	run := pub.forRun(&types.RunMeta{RunID: "run-001"}, "src", "cat", "2026-10-15", "")
+	if run.observer() != nil {
+		t.Error("expected nil observer")
+	}
+	run.wait()
+	if err := pub.Close(); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
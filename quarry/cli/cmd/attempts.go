@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+// retryableStatuses are the outcome statuses --retry-on accepts.
+var retryableStatuses = []types.OutcomeStatus{
+	types.OutcomeExecutorCrash,
+	types.OutcomeScriptError,
+	types.OutcomePolicyFailure,
+}
+
+// attemptChoice holds the in-process retry settings (--max-attempts,
+// --retry-on) for a single (non fan-out) run.
+type attemptChoice struct {
+	maxAttempts int
+	retryOn     []types.OutcomeStatus
+}
+
+// parseAttemptChoice validates --max-attempts and --retry-on. An empty
+// retryOn retries executor_crash only.
+func parseAttemptChoice(maxAttempts int, retryOn []string) (attemptChoice, error) {
+	if maxAttempts < 1 {
+		return attemptChoice{}, fmt.Errorf("--max-attempts must be >= 1, got %d", maxAttempts)
+	}
+	choice := attemptChoice{maxAttempts: maxAttempts}
+	for _, s := range retryOn {
+		status := types.OutcomeStatus(strings.TrimSpace(s))
+		if !slices.Contains(retryableStatuses, status) {
+			return attemptChoice{}, fmt.Errorf("--retry-on %q: must be one of executor_crash, script_error, policy_failure", s)
+		}
+		if !slices.Contains(choice.retryOn, status) {
+			choice.retryOn = append(choice.retryOn, status)
+		}
+	}
+	if len(choice.retryOn) == 0 {
+		choice.retryOn = []types.OutcomeStatus{types.OutcomeExecutorCrash}
+	}
+	return choice, nil
+}
+
+// shouldRetry reports whether another attempt follows the attempts made so
+// far, given the last outcome. Invalid input, cancellation, interruption,
+// and drain are never retried, whatever --retry-on says.
+func (a attemptChoice) shouldRetry(outcome *types.RunOutcome, made int) bool {
+	if made >= a.maxAttempts || outcome == nil {
+		return false
+	}
+	switch outcome.Reason {
+	case types.ReasonInvalidInput, types.ReasonCanceled, types.ReasonInterrupted, types.ReasonDrained:
+		return false
+	}
+	return slices.Contains(a.retryOn, outcome.Status)
+}
+
+// nextAttemptMeta derives the RunMeta of the attempt after prev: a new run
+// ID (its own partition), the next attempt number, and prev as parent.
+// Job ID and labels carry over.
+func nextAttemptMeta(prev *types.RunMeta, runID string) *types.RunMeta {
+	parent := prev.RunID
+	return &types.RunMeta{
+		RunID:       runID,
+		JobID:       prev.JobID,
+		ParentRunID: &parent,
+		Attempt:     prev.Attempt + 1,
+		Labels:      prev.Labels,
+	}
+}
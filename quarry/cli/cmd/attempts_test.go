@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+func TestParseAttemptChoice(t *testing.T) {
+	choice, err := parseAttemptChoice(3, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(choice.retryOn) != 1 || choice.retryOn[0] != types.OutcomeExecutorCrash {
+		t.Errorf("retryOn = %v, want [executor_crash] by default", choice.retryOn)
+	}
+
+	choice, err = parseAttemptChoice(2, []string{"executor_crash", "script_error", "script_error"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(choice.retryOn) != 2 {
+		t.Errorf("retryOn = %v, want deduplicated [executor_crash script_error]", choice.retryOn)
+	}
+
+	if _, err := parseAttemptChoice(0, nil); err == nil {
+		t.Error("expected error for --max-attempts 0")
+	}
+	for _, bad := range []string{"success", "version_mismatch", "crash"} {
+		if _, err := parseAttemptChoice(2, []string{bad}); err == nil {
+			t.Errorf("expected error for --retry-on %s", bad)
+		}
+	}
+}
+
+func TestAttemptChoice_ShouldRetry(t *testing.T) {
+	crash := &types.RunOutcome{Status: types.OutcomeExecutorCrash, Reason: types.ReasonExecutorCrash}
+	scriptErr := &types.RunOutcome{Status: types.OutcomeScriptError, Reason: types.ReasonScriptError}
+	defaults, _ := parseAttemptChoice(3, nil)
+	broad, _ := parseAttemptChoice(3, []string{"executor_crash", "script_error"})
+
+	tests := []struct {
+		name    string
+		choice  attemptChoice
+		outcome *types.RunOutcome
+		made    int
+		want    bool
+	}{
+		{"crash retried", defaults, crash, 1, true},
+		{"limit reached", defaults, crash, 3, false},
+		{"script error not retried by default", defaults, scriptErr, 1, false},
+		{"script error retried with --retry-on", broad, scriptErr, 2, true},
+		{"success never retried", broad, &types.RunOutcome{Status: types.OutcomeSuccess, Reason: types.ReasonCompleted}, 1, false},
+		{"invalid input never retried", defaults, &types.RunOutcome{Status: types.OutcomeExecutorCrash, Reason: types.ReasonInvalidInput}, 1, false},
+		{"canceled never retried", defaults, &types.RunOutcome{Status: types.OutcomeExecutorCrash, Reason: types.ReasonCanceled}, 1, false},
+		{"drained never retried", defaults, &types.RunOutcome{Status: types.OutcomeExecutorCrash, Reason: types.ReasonDrained}, 1, false},
+		{"interrupted never retried", defaults, &types.RunOutcome{Status: types.OutcomeExecutorCrash, Reason: types.ReasonInterrupted}, 1, false},
+		{"single attempt", attemptChoice{maxAttempts: 1, retryOn: defaults.retryOn}, crash, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.choice.shouldRetry(tt.outcome, tt.made); got != tt.want {
+				t.Errorf("shouldRetry = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextAttemptMeta(t *testing.T) {
+	jobID := "job-1"
+	prev := &types.RunMeta{RunID: "run-1", JobID: &jobID, Attempt: 2, Labels: map[string]string{"team": "growth"}}
+
+	next := nextAttemptMeta(prev, "run-2")
+	if next.RunID != "run-2" || next.Attempt != 3 {
+		t.Errorf("next = %s attempt %d, want run-2 attempt 3", next.RunID, next.Attempt)
+	}
+	if next.ParentRunID == nil || *next.ParentRunID != "run-1" {
+		t.Errorf("ParentRunID = %v, want run-1", next.ParentRunID)
+	}
+	if next.JobID != prev.JobID || next.Labels["team"] != "growth" {
+		t.Error("job ID and labels should carry over")
+	}
+	if err := next.Validate(); err != nil {
+		t.Errorf("next attempt metadata invalid: %v", err)
+	}
+}
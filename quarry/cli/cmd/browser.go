@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/pithecene-io/quarry/runtime"
+)
+
+// BrowserCommand returns the browser command with start/stop subcommands.
+// Manages an opt-in warm browser server shared across sequential
+// `quarry run` invocations. Runs discover the server automatically via the
+// browser reuse discovery file (unless --no-browser-reuse is set).
+func BrowserCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "browser",
+		Usage: "Manage a persistent browser server shared across runs",
+		Subcommands: []*cli.Command{
+			browserStartCommand(),
+			browserStopCommand(),
+		},
+	}
+}
+
+func browserStartCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "start",
+		Usage: "Start a persistent browser server (prints its WebSocket endpoint)",
+		UsageText: `quarry browser start --script <path> [options]
+
+The script path is used to resolve puppeteer (same as quarry run).
+Subsequent quarry run invocations connect to this server automatically.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "script",
+				Usage:    "Path to a script file (used to resolve puppeteer)",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "executor",
+				Usage: "Path to executor binary (advanced: auto-resolved by default)",
+			},
+			&cli.DurationFlag{
+				Name:  "idle-timeout",
+				Usage: "Shut down after this long with no active pages (0 = run until quarry browser stop)",
+				Value: 0,
+			},
+		},
+		Action: browserStartAction,
+	}
+}
+
+func browserStopCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "stop",
+		Usage:  "Stop the persistent browser server",
+		Action: browserStopAction,
+	}
+}
+
+func browserStartAction(c *cli.Context) error {
+	idleTimeout := c.Duration("idle-timeout")
+	if idleTimeout < 0 {
+		return cli.Exit(fmt.Sprintf("--idle-timeout must be >= 0, got %s", idleTimeout), exitConfigError)
+	}
+	// The executor reads whole seconds, where 0 means never expire
+	if idleTimeout > 0 && idleTimeout < time.Second {
+		return cli.Exit(fmt.Sprintf("--idle-timeout must be 0 or at least 1s, got %s", idleTimeout), exitConfigError)
+	}
+
+	executorPath, err := resolveExecutor(c.String("executor"))
+	if err != nil {
+		return cli.Exit(err.Error(), exitConfigError)
+	}
+
+	// Cancel the launch on SIGINT/SIGTERM; the server itself is detached.
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	disc, reused, err := runtime.StartBrowserServer(ctx, runtime.ReusableBrowserConfig{
+		ExecutorPath: executorPath,
+		ScriptPath:   c.String("script"),
+		IdleTimeout:  idleTimeout,
+		Persistent:   idleTimeout == 0,
+	})
+	if err != nil {
+		printBrowserStderr(err)
+		return cli.Exit(fmt.Sprintf("failed to start browser server: %v", err), exitExecutorCrash)
+	}
+
+	if reused {
+		fmt.Fprintf(os.Stderr, "Browser server already running (pid=%d)\n", disc.PID)
+	}
+	fmt.Println(disc.WSEndpoint)
+	return nil
+}
+
+func browserStopAction(_ *cli.Context) error {
+	disc, err := runtime.StopBrowserServer()
+	if err != nil {
+		if errors.Is(err, runtime.ErrNoBrowserServer) {
+			fmt.Fprintf(os.Stderr, "No browser server running\n")
+			return nil
+		}
+		return cli.Exit(fmt.Sprintf("failed to stop browser server: %v", err), exitExecutorCrash)
+	}
+
+	fmt.Fprintf(os.Stderr, "Stopped browser server (pid=%d)\n", disc.PID)
+	return nil
+}
+
+// printBrowserStderr prints the browser server's stderr tail carried by a
+// *runtime.BrowserLaunchError, where Chromium reports why it failed.
+func printBrowserStderr(err error) {
+	var launchErr *runtime.BrowserLaunchError
+	if errors.As(err, &launchErr) && launchErr.Stderr != "" {
+		fmt.Fprintf(os.Stderr, "Browser stderr:\n%s\n", strings.TrimRight(launchErr.Stderr, "\n"))
+	}
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+func TestBrowserStart_SubSecondIdleTimeoutRejected(t *testing.T) {
+	app := cli.NewApp()
+	app.Commands = []*cli.Command{BrowserCommand()}
+	app.ExitErrHandler = func(c *cli.Context, err error) {} // suppress os.Exit
+
+	err := app.Run([]string{"quarry", "browser", "start", "--script", "./test.ts", "--idle-timeout", "500ms"})
+	exitErr, ok := err.(cli.ExitCoder)
+	if !ok {
+		t.Fatalf("expected cli.ExitCoder, got %T: %v", err, err)
+	}
+	if exitErr.ExitCode() != exitConfigError {
+		t.Errorf("exit code = %d, want %d (exitConfigError)", exitErr.ExitCode(), exitConfigError)
+	}
+	if !strings.Contains(err.Error(), "--idle-timeout must be 0 or at least 1s") {
+		t.Errorf("err = %v, want a minimum idle timeout error", err)
+	}
+}
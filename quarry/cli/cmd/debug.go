@@ -52,7 +52,7 @@ func debugResolveProxyCommand() *cli.Command {
 			},
 			&cli.StringFlag{
 				Name:  "strategy",
-				Usage: "Strategy override: round_robin, random, or sticky",
+				Usage: "Strategy override: round_robin, weighted_round_robin, random, or sticky",
 			},
 			&cli.StringFlag{
 				Name:  "sticky-key",
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// parseEgressProxy validates the --egress-proxy URL: an http or https
+// scheme and a host, optionally with userinfo and port. Empty returns nil,
+// leaving quarry's own clients on HTTPS_PROXY / HTTP_PROXY / NO_PROXY.
+func parseEgressProxy(raw string) (*url.URL, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --egress-proxy %q: %w", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid --egress-proxy %q: scheme must be http or https", raw)
+	}
+	if u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid --egress-proxy %q: missing host", raw)
+	}
+	if u.Path != "" && u.Path != "/" || u.RawQuery != "" || u.Fragment != "" {
+		return nil, fmt.Errorf("invalid --egress-proxy %q: must not have a path, query, or fragment", raw)
+	}
+	return u, nil
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// settingSource identifies which layer supplied a resolved setting.
+type settingSource string
+
+const (
+	sourceFlag    settingSource = "flag"
+	sourceConfig  settingSource = "config"
+	sourceDefault settingSource = "default"
+)
+
+// explainEntry is one resolved setting recorded for --explain.
+type explainEntry struct {
+	name   string
+	value  any
+	source settingSource
+}
+
+// explainer records resolved settings in resolution order for --explain.
+type explainer struct {
+	entries []explainEntry
+	index   map[string]int
+}
+
+// explainKey is the context key for the active explainer.
+type explainKey struct{}
+
+// withExplainer attaches a new explainer to c so the resolve helpers record
+// into it.
+func withExplainer(c *cli.Context) *explainer {
+	ex := &explainer{index: make(map[string]int)}
+	parent := c.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	c.Context = context.WithValue(parent, explainKey{}, ex)
+	return ex
+}
+
+// explainFrom returns the explainer attached to c, or nil when --explain is off.
+func explainFrom(c *cli.Context) *explainer {
+	if c == nil || c.Context == nil {
+		return nil
+	}
+	ex, _ := c.Context.Value(explainKey{}).(*explainer)
+	return ex
+}
+
+// explained records a resolved value on c's explainer (if any) and returns it.
+func explained[T any](c *cli.Context, name string, value T, source settingSource) T {
+	explainFrom(c).record(name, value, source)
+	return value
+}
+
+// explainCLIOnly records flags that have no config equivalent.
+func explainCLIOnly(c *cli.Context, flags ...string) {
+	ex := explainFrom(c)
+	if ex == nil {
+		return
+	}
+	for _, flag := range flags {
+		source := sourceDefault
+		if c.IsSet(flag) {
+			source = sourceFlag
+		}
+		ex.record(flag, c.Value(flag), source)
+	}
+}
+
+// record stores a resolved setting. Re-resolving a setting keeps its original
+// position. Safe to call on a nil explainer.
+func (e *explainer) record(name string, value any, source settingSource) {
+	if e == nil {
+		return
+	}
+	entry := explainEntry{name: name, value: value, source: source}
+	if i, ok := e.index[name]; ok {
+		e.entries[i] = entry
+		return
+	}
+	e.index[name] = len(e.entries)
+	e.entries = append(e.entries, entry)
+}
+
+// print writes the effective configuration table to w.
+func (e *explainer) print(w io.Writer, configPath string) {
+	if configPath == "" {
+		configPath = "none"
+	}
+	fmt.Fprintf(w, "Effective configuration (config file: %s):\n", configPath)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  SETTING\tVALUE\tSOURCE")
+	for _, entry := range e.entries {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", entry.name, formatExplainValue(entry.value), entry.source)
+	}
+	_ = tw.Flush()
+	fmt.Fprintln(w)
+}
+
+// formatExplainValue renders a setting value for display. URL credentials are
+// redacted so --explain output is safe to paste into bug reports.
+func formatExplainValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return "(unset)"
+		}
+		if u, err := url.Parse(v); err == nil && u.User != nil {
+			return u.Redacted()
+		}
+		return v
+	case []string:
+		if len(v) == 0 {
+			return "(none)"
+		}
+		return strings.Join(v, ",")
+	case time.Duration:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -12,6 +12,12 @@ var (
 		Usage:   "Output format: json, table, yaml",
 	}
 
+	// JSONFlag is shorthand for --format json, for scripts parsing output.
+	JSONFlag = &cli.BoolFlag{
+		Name:  "json",
+		Usage: "Emit JSON (shorthand for --format json)",
+	}
+
 	// NoColorFlag disables colored output in table format.
 	// Note: Currently a placeholder - table output doesn't emit ANSI colors yet.
 	// The flag is defined for forward compatibility when table coloring is added.
@@ -35,6 +41,7 @@ var (
 func ReadOnlyFlags() []cli.Flag {
 	return []cli.Flag{
 		FormatFlag,
+		JSONFlag,
 		NoColorFlag,
 		TUIFlag,
 	}
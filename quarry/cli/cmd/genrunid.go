@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/pithecene-io/quarry/lode"
+	"github.com/pithecene-io/quarry/runtime"
+)
+
+// GenRunIDCommand returns the gen-run-id command.
+// Prints the run ID that quarry run --run-id auto (or random) would use,
+// so pipelines can pre-compute it. It must not contact the executor.
+func GenRunIDCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "gen-run-id",
+		Usage: "Print a generated run ID (same derivation as quarry run --run-id auto|random)",
+		UsageText: `quarry gen-run-id --source <name> [--category <name>] [--day YYYY-MM-DD] [--job <json> | --job-json <path>]
+quarry gen-run-id --mode random`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "mode",
+				Usage: "auto (stable ID from source, category, day, and job) or random (ULID)",
+				Value: runtime.RunIDAuto,
+			},
+			&cli.StringFlag{
+				Name:  "source",
+				Usage: "Source partition key (required for --mode auto)",
+			},
+			&cli.StringFlag{
+				Name:  "category",
+				Usage: "Category partition key",
+				Value: "default",
+			},
+			&cli.StringFlag{
+				Name:  "day",
+				Usage: "Partition day as YYYY-MM-DD (default: today in UTC)",
+			},
+			&cli.StringFlag{
+				Name:  "job",
+				Usage: "Inline JSON job payload (must be a JSON object)",
+			},
+			&cli.StringFlag{
+				Name:  "job-json",
+				Usage: "Path to JSON file containing job payload (must be a JSON object)",
+			},
+		},
+		Action: genRunIDAction,
+	}
+}
+
+func genRunIDAction(c *cli.Context) error {
+	now := time.Now()
+	switch mode := c.String("mode"); mode {
+	case runtime.RunIDRandom:
+		id, err := runtime.NewRandomRunID(now)
+		if err != nil {
+			return cli.Exit(err.Error(), 1)
+		}
+		fmt.Println(id)
+		return nil
+	case runtime.RunIDAuto:
+	default:
+		return cli.Exit(fmt.Sprintf("--mode must be auto or random, got %q", mode), exitConfigError)
+	}
+
+	source := c.String("source")
+	if source == "" {
+		return cli.Exit("--source is required for --mode auto", exitConfigError)
+	}
+	day := c.String("day")
+	if day == "" {
+		day = lode.DeriveDay(now)
+	} else if err := lode.ValidateDay(day); err != nil {
+		return cli.Exit(fmt.Sprintf("invalid --day: %v", err), exitConfigError)
+	}
+	job, err := parseJobPayload(c.String("job"), c.String("job-json"))
+	if err != nil {
+		return cli.Exit(err.Error(), exitConfigError)
+	}
+
+	id, err := runtime.DeriveRunID(source, c.String("category"), day, job)
+	if err != nil {
+		return cli.Exit(err.Error(), exitConfigError)
+	}
+	fmt.Println(id)
+	return nil
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pithecene-io/quarry/runtime"
+)
+
+func TestResolveRunID_Literal(t *testing.T) {
+	got, err := resolveRunID("run-001", "shop", "products", "2026-03-01", nil, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "run-001" {
+		t.Errorf("run ID = %q, want literal run-001", got)
+	}
+}
+
+func TestResolveRunID_AutoMatchesDerivation(t *testing.T) {
+	job := map[string]any{"url": "https://example.com", "page": 1}
+
+	got, err := resolveRunID(runtime.RunIDAuto, "shop", "products", "2026-03-01", job, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want, _ := runtime.DeriveRunID("shop", "products", "2026-03-01", job)
+	if got != want {
+		t.Errorf("run ID = %q, want %q (same as gen-run-id --day 2026-03-01)", got, want)
+	}
+}
+
+func TestResolveRunID_Random(t *testing.T) {
+	got, err := resolveRunID(runtime.RunIDRandom, "shop", "products", "2026-03-01", nil, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 26 || strings.HasPrefix(got, "run-") {
+		t.Errorf("run ID = %q, want 26-char ULID", got)
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pithecene-io/quarry/iox"
+)
+
+// maxInputURLLine bounds a single --input-urls line (a .jsonl params object).
+const maxInputURLLine = 1 << 20
+
+// readInputURLs reads the --input-urls seed list into one params map per
+// work item. A .jsonl file holds one JSON object per line, used as the
+// item's params and required to carry a string "url". Any other file holds
+// one URL per line and yields params {"url": <line>}. Blank lines and, in
+// plain lists, lines starting with # are skipped.
+func readInputURLs(path string) ([]map[string]any, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read --input-urls file: %w", err)
+	}
+	defer iox.DiscardClose(f)
+	return parseInputURLs(f, strings.EqualFold(filepath.Ext(path), ".jsonl"))
+}
+
+// parseInputURLs parses a seed list; see readInputURLs.
+func parseInputURLs(r io.Reader, jsonl bool) ([]map[string]any, error) {
+	var seeds []map[string]any
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxInputURLLine)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if !jsonl {
+			if strings.HasPrefix(line, "#") {
+				continue
+			}
+			seeds = append(seeds, map[string]any{"url": line})
+			continue
+		}
+
+		var params map[string]any
+		if err := json.Unmarshal([]byte(line), &params); err != nil || params == nil {
+			return nil, fmt.Errorf("--input-urls line %d: want a JSON object of params", lineNo)
+		}
+		if url, _ := params["url"].(string); url == "" {
+			return nil, fmt.Errorf("--input-urls line %d: missing string \"url\"", lineNo)
+		}
+		seeds = append(seeds, params)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read --input-urls file: %w", err)
+	}
+	return seeds, nil
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseInputURLs_Plain(t *testing.T) {
+	input := "https://a.example/1\n\n# comment\n  https://b.example/2  \n"
+	seeds, err := parseInputURLs(strings.NewReader(input), false)
+	if err != nil {
+		t.Fatalf("parseInputURLs: %v", err)
+	}
+	want := []map[string]any{{"url": "https://a.example/1"}, {"url": "https://b.example/2"}}
+	if !reflect.DeepEqual(seeds, want) {
+		t.Errorf("seeds = %v, want %v", seeds, want)
+	}
+}
+
+func TestParseInputURLs_JSONL(t *testing.T) {
+	input := `{"url": "https://a.example/1", "page": 2}` + "\n\n" + `{"url": "https://b.example/2"}` + "\n"
+	seeds, err := parseInputURLs(strings.NewReader(input), true)
+	if err != nil {
+		t.Fatalf("parseInputURLs: %v", err)
+	}
+	want := []map[string]any{
+		{"url": "https://a.example/1", "page": float64(2)},
+		{"url": "https://b.example/2"},
+	}
+	if !reflect.DeepEqual(seeds, want) {
+		t.Errorf("seeds = %v, want %v", seeds, want)
+	}
+}
+
+func TestParseInputURLs_JSONLErrors(t *testing.T) {
+	tests := []struct {
+		name, input, errContains string
+	}{
+		{"not an object", "{\"url\": \"https://a.example\"}\n[1, 2]\n", "line 2: want a JSON object"},
+		{"null", "null\n", "line 1: want a JSON object"},
+		{"missing url", "{\"page\": 1}\n", "line 1: missing string \"url\""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseInputURLs(strings.NewReader(tt.input), true)
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("err = %v, want containing %q", err, tt.errContains)
+			}
+		})
+	}
+}
+
+func TestReadInputURLs_SelectsFormatByExtension(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "urls.JSONL")
+	if err := os.WriteFile(path, []byte(`{"url": "https://a.example", "id": "x"}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	seeds, err := readInputURLs(path)
+	if err != nil {
+		t.Fatalf("readInputURLs: %v", err)
+	}
+	if len(seeds) != 1 || seeds[0]["id"] != "x" {
+		t.Errorf("seeds = %v, want one jsonl params object", seeds)
+	}
+
+	if _, err := readInputURLs(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -10,11 +10,16 @@ import (
 )
 
 // InspectCommand returns the inspect command with subcommands.
-// Inspect returns a deep view of a single entity per CONTRACT_CLI.md.
+// Inspect returns a deep view of a single entity per CONTRACT_CLI.md;
+// with --diff it compares two persisted runs instead.
 func InspectCommand() *cli.Command {
 	return &cli.Command{
 		Name:  "inspect",
-		Usage: "Inspect a single entity (run, job, task, proxy, executor)",
+		Usage: "Inspect a single entity (run, job, task, proxy, executor), or compare two runs with --diff",
+		UsageText: "quarry inspect <run|job|task|proxy|executor> <id>\n" +
+			"quarry inspect --diff --run-id <id> --baseline-run-id <id> --storage-backend <fs|s3> --storage-path <path> [--diff-item-key <field>] [--max-drift <pct>]",
+		Flags:  inspectDiffFlags(),
+		Action: inspectDiffAction,
 		Subcommands: []*cli.Command{
 			inspectRunCommand(),
 			inspectJobCommand(),
@@ -0,0 +1,264 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/pithecene-io/quarry/lode"
+	"github.com/pithecene-io/quarry/types"
+)
+
+// inspectDiffFlags are the inspect command's own flags, used by
+// quarry inspect --diff.
+func inspectDiffFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{Name: "diff", Usage: "Compare a run's partition against a baseline run's (event counts by type, artifacts, item keys)"},
+		&cli.StringFlag{Name: "run-id", Usage: "Run ID to compare (with --diff)"},
+		&cli.StringFlag{Name: "baseline-run-id", Usage: "Run ID to compare against (with --diff)"},
+		&cli.StringFlag{Name: "storage-dataset", Usage: "Lode dataset ID (default: \"quarry\")", Value: lode.DefaultDataset},
+		&cli.StringFlag{Name: "storage-backend", Usage: "Storage backend: fs or s3"},
+		&cli.StringFlag{Name: "storage-path", Usage: "Storage path (fs: directory, s3: bucket/prefix)"},
+		&cli.StringFlag{Name: "storage-region", Usage: "AWS region for S3 backend"},
+		&cli.StringFlag{Name: "diff-item-key", Usage: "Item data field whose values are compared as key sets, e.g. url"},
+		&cli.Float64Flag{Name: "max-drift", Usage: "Exit 1 when any count or the item key set drifts by more than this percentage (0 = report only)"},
+	}
+}
+
+// inspectDiffAction runs quarry inspect --diff. Without --diff, inspect
+// needs a subcommand.
+func inspectDiffAction(c *cli.Context) error {
+	if !c.Bool("diff") {
+		return cli.ShowSubcommandHelp(c)
+	}
+	runID, baselineRunID := c.String("run-id"), c.String("baseline-run-id")
+	if runID == "" || baselineRunID == "" {
+		return cli.Exit("--diff requires --run-id and --baseline-run-id", exitConfigError)
+	}
+	if c.String("storage-backend") == "" || c.String("storage-path") == "" {
+		return cli.Exit("--diff requires --storage-backend and --storage-path", exitConfigError)
+	}
+	maxDrift := c.Float64("max-drift")
+	if maxDrift < 0 {
+		return cli.Exit(fmt.Sprintf("--max-drift must be >= 0, got %g", maxDrift), exitConfigError)
+	}
+
+	ds, err := buildReadDataset(c.String("storage-dataset"), c.String("storage-backend"), c.String("storage-path"), c.String("storage-region"))
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("failed to initialize storage reader: %v", err), exitConfigError)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	current, err := lode.NewRunPartitionReader(ds, runID).ReadRunRecords(ctx)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+	baseline, err := lode.NewRunPartitionReader(ds, baselineRunID).ReadRunRecords(ctx)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+	if current.Source != baseline.Source {
+		fmt.Fprintf(os.Stderr, "Warning: comparing runs of different sources (%s vs %s)\n", current.Source, baseline.Source)
+	}
+
+	d := diffRuns(runID, baselineRunID, current, baseline, c.String("diff-item-key"))
+	d.print(c.App.Writer)
+	if maxDrift > 0 && d.maxDrift() > maxDrift {
+		return cli.Exit(fmt.Sprintf("drift %.1f%% exceeds --max-drift %g%%", d.maxDrift(), maxDrift), 1)
+	}
+	return nil
+}
+
+// countDiff is one compared count, e.g. the item events of both runs.
+type countDiff struct {
+	name              string
+	current, baseline int
+}
+
+// drift is the relative change from the baseline in percent. A count that
+// appears or disappears entirely drifts 100%.
+func (d countDiff) drift() float64 {
+	if d.baseline == 0 {
+		if d.current == 0 {
+			return 0
+		}
+		return 100
+	}
+	return math.Abs(float64(d.current-d.baseline)) / float64(d.baseline) * 100
+}
+
+// status labels the change for the summary.
+func (d countDiff) status() string {
+	switch {
+	case d.baseline == 0 && d.current > 0:
+		return "added"
+	case d.current == 0 && d.baseline > 0:
+		return "removed"
+	case d.current != d.baseline:
+		return "changed"
+	default:
+		return ""
+	}
+}
+
+// keySetDiff compares the item key sets of both runs.
+type keySetDiff struct {
+	field                    string
+	added, removed, common   int
+	keyless, baselineKeyless int
+}
+
+// drift is the share of the baseline key set that was added or removed,
+// in percent. Keys appearing over an empty baseline drift 100%.
+func (d keySetDiff) drift() float64 {
+	baseline := d.removed + d.common
+	if baseline == 0 {
+		if d.added == 0 {
+			return 0
+		}
+		return 100
+	}
+	return float64(d.added+d.removed) / float64(baseline) * 100
+}
+
+// runDiff is the comparison of a run's persisted records against a
+// baseline run's.
+type runDiff struct {
+	runID, baselineRunID string
+	// eventTypes holds one count per event type seen in either run, sorted
+	// by type.
+	eventTypes []countDiff
+	// artifacts counts committed artifacts (gated through the artifact
+	// event type); their total sizes are reported, not gated.
+	artifacts                            countDiff
+	artifactBytes, baselineArtifactBytes int64
+	// keys is nil unless an item key field was given.
+	keys *keySetDiff
+}
+
+// diffRuns compares current against baseline. With itemKey set, the
+// values of that item data field are compared as sets.
+func diffRuns(runID, baselineRunID string, current, baseline *lode.RunRecords, itemKey string) *runDiff {
+	d := &runDiff{runID: runID, baselineRunID: baselineRunID}
+
+	counts := make(map[string]*countDiff)
+	count := func(events []*types.EventEnvelope, isBaseline bool) {
+		for _, e := range events {
+			c, ok := counts[string(e.Type)]
+			if !ok {
+				c = &countDiff{name: string(e.Type)}
+				counts[string(e.Type)] = c
+			}
+			if isBaseline {
+				c.baseline++
+			} else {
+				c.current++
+			}
+		}
+	}
+	count(current.Events, false)
+	count(baseline.Events, true)
+	for _, c := range counts {
+		d.eventTypes = append(d.eventTypes, *c)
+	}
+	sort.Slice(d.eventTypes, func(i, j int) bool { return d.eventTypes[i].name < d.eventTypes[j].name })
+
+	d.artifacts = countDiff{name: "artifacts"}
+	d.artifacts.current, d.artifactBytes = artifactTotals(current.Events)
+	d.artifacts.baseline, d.baselineArtifactBytes = artifactTotals(baseline.Events)
+
+	if itemKey != "" {
+		keys, keyless := itemKeys(current.Events, itemKey)
+		baseKeys, baseKeyless := itemKeys(baseline.Events, itemKey)
+		k := &keySetDiff{field: itemKey, keyless: keyless, baselineKeyless: baseKeyless}
+		for key := range keys {
+			if _, ok := baseKeys[key]; ok {
+				k.common++
+			} else {
+				k.added++
+			}
+		}
+		k.removed = len(baseKeys) - k.common
+		d.keys = k
+	}
+	return d
+}
+
+// artifactTotals counts committed artifacts and sums their sizes.
+func artifactTotals(events []*types.EventEnvelope) (int, int64) {
+	var n int
+	var size int64
+	for _, e := range events {
+		if e.Type != types.EventTypeArtifact {
+			continue
+		}
+		n++
+		if v, ok := e.Payload["size_bytes"].(float64); ok {
+			size += int64(v)
+		}
+	}
+	return n, size
+}
+
+// itemKeys collects the distinct values of field in item data, and counts
+// items without it.
+func itemKeys(events []*types.EventEnvelope, field string) (map[string]struct{}, int) {
+	keys := make(map[string]struct{})
+	keyless := 0
+	for _, e := range events {
+		if e.Type != types.EventTypeItem {
+			continue
+		}
+		data, _ := e.Payload["data"].(map[string]any)
+		v, ok := data[field]
+		if !ok || v == nil {
+			keyless++
+			continue
+		}
+		keys[fmt.Sprint(v)] = struct{}{}
+	}
+	return keys, keyless
+}
+
+// maxDrift is the largest drift of any event type count or the item key
+// set, in percent.
+func (d *runDiff) maxDrift() float64 {
+	var drift float64
+	for _, c := range d.eventTypes {
+		drift = math.Max(drift, c.drift())
+	}
+	if d.keys != nil {
+		drift = math.Max(drift, d.keys.drift())
+	}
+	return drift
+}
+
+// print writes the diff summary, baseline counts first.
+func (d *runDiff) print(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "diff: %s vs baseline %s\n", d.runID, d.baselineRunID)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "\tBASELINE\tCURRENT\tDRIFT\t")
+	for _, c := range d.eventTypes {
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%.1f%%\t%s\n", c.name, c.baseline, c.current, c.drift(), c.status())
+	}
+	_ = tw.Flush()
+	_, _ = fmt.Fprintf(w, "artifacts: %d -> %d (%d -> %d bytes)\n",
+		d.artifacts.baseline, d.artifacts.current, d.baselineArtifactBytes, d.artifactBytes)
+	if k := d.keys; k != nil {
+		_, _ = fmt.Fprintf(w, "item keys (%s): %d added, %d removed, %d unchanged (%.1f%% drift)\n",
+			k.field, k.added, k.removed, k.common, k.drift())
+		if k.keyless > 0 || k.baselineKeyless > 0 {
+			_, _ = fmt.Fprintf(w, "items without %s: %d -> %d\n", k.field, k.baselineKeyless, k.keyless)
+		}
+	}
+	_, _ = fmt.Fprintf(w, "max drift: %.1f%%\n", d.maxDrift())
+}
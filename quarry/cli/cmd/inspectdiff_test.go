@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/pithecene-io/quarry/lode"
+	"github.com/pithecene-io/quarry/types"
+)
+
+func diffItem(url string) *types.EventEnvelope {
+	data := map[string]any{}
+	if url != "" {
+		data["url"] = url
+	}
+	return &types.EventEnvelope{Type: types.EventTypeItem, Payload: map[string]any{"item_type": "product", "data": data}}
+}
+
+func diffEvent(t types.EventType, payload map[string]any) *types.EventEnvelope {
+	return &types.EventEnvelope{Type: t, Payload: payload}
+}
+
+func TestDiffRuns(t *testing.T) {
+	baseline := &lode.RunRecords{Events: []*types.EventEnvelope{
+		diffItem("/a"), diffItem("/b"), diffItem("/c"), diffItem("/d"),
+		diffEvent(types.EventTypeLog, nil),
+		diffEvent(types.EventTypeArtifact, map[string]any{"size_bytes": float64(100)}),
+		diffEvent(types.EventTypeRunComplete, nil),
+	}}
+	current := &lode.RunRecords{Events: []*types.EventEnvelope{
+		diffItem("/a"), diffItem("/b"), diffItem("/e"), diffItem(""), diffItem("/a"),
+		diffEvent(types.EventTypeArtifact, map[string]any{"size_bytes": float64(100)}),
+		diffEvent(types.EventTypeArtifact, map[string]any{"size_bytes": float64(50)}),
+		diffEvent(types.EventTypeRunComplete, nil),
+	}}
+
+	d := diffRuns("run-b", "run-a", current, baseline, "url")
+
+	want := map[string]countDiff{
+		"artifact":     {name: "artifact", current: 2, baseline: 1},
+		"item":         {name: "item", current: 5, baseline: 4},
+		"log":          {name: "log", current: 0, baseline: 1},
+		"run_complete": {name: "run_complete", current: 1, baseline: 1},
+	}
+	if len(d.eventTypes) != len(want) {
+		t.Fatalf("eventTypes = %+v, want %d types", d.eventTypes, len(want))
+	}
+	for _, c := range d.eventTypes {
+		if c != want[c.name] {
+			t.Errorf("%s = %+v, want %+v", c.name, c, want[c.name])
+		}
+	}
+	if d.eventTypes[0].name != "artifact" || d.eventTypes[3].name != "run_complete" {
+		t.Errorf("eventTypes not sorted: %+v", d.eventTypes)
+	}
+	if d.artifacts.current != 2 || d.artifactBytes != 150 || d.baselineArtifactBytes != 100 {
+		t.Errorf("artifacts = %+v (%d -> %d bytes)", d.artifacts, d.baselineArtifactBytes, d.artifactBytes)
+	}
+
+	k := d.keys
+	if k.added != 1 || k.removed != 2 || k.common != 2 || k.keyless != 1 || k.baselineKeyless != 0 {
+		t.Errorf("keys = %+v, want 1 added, 2 removed, 2 common, 1 keyless", k)
+	}
+	if got := k.drift(); got != 75 {
+		t.Errorf("key drift = %v, want 75", got)
+	}
+	// artifact 1 -> 2 and log 1 -> 0 both drift 100%
+	if got := d.maxDrift(); got != 100 {
+		t.Errorf("maxDrift = %v, want 100", got)
+	}
+}
+
+func TestCountDiff(t *testing.T) {
+	tests := []struct {
+		c      countDiff
+		drift  float64
+		status string
+	}{
+		{countDiff{current: 0, baseline: 0}, 0, ""},
+		{countDiff{current: 10, baseline: 10}, 0, ""},
+		{countDiff{current: 9, baseline: 10}, 10, "changed"},
+		{countDiff{current: 15, baseline: 10}, 50, "changed"},
+		{countDiff{current: 3, baseline: 0}, 100, "added"},
+		{countDiff{current: 0, baseline: 3}, 100, "removed"},
+	}
+	for _, tt := range tests {
+		if got := tt.c.drift(); got != tt.drift {
+			t.Errorf("%+v drift = %v, want %v", tt.c, got, tt.drift)
+		}
+		if got := tt.c.status(); got != tt.status {
+			t.Errorf("%+v status = %q, want %q", tt.c, got, tt.status)
+		}
+	}
+}
+
+func newInspectTestApp(out *bytes.Buffer) *cli.App {
+	return &cli.App{
+		Writer:         out,
+		Commands:       []*cli.Command{InspectCommand()},
+		ExitErrHandler: func(c *cli.Context, err error) {}, // suppress os.Exit
+	}
+}
+
+func writeDiffRun(t *testing.T, dir, runID string, items int) {
+	t.Helper()
+	client, err := lode.NewLodeClient(lode.Config{
+		Dataset: "quarry", Source: "shop", Category: "products", Day: "2026-03-01", RunID: runID,
+	}, dir)
+	if err != nil {
+		t.Fatalf("NewLodeClient: %v", err)
+	}
+	var events []*types.EventEnvelope
+	for seq := int64(1); seq <= int64(items); seq++ {
+		events = append(events, &types.EventEnvelope{
+			ContractVersion: "1.0.0", EventID: "evt", RunID: runID, Seq: seq,
+			Type: types.EventTypeItem, Ts: "2026-03-01T12:00:00Z", Attempt: 1,
+			Payload: map[string]any{"item_type": "product", "data": map[string]any{"sku": float64(seq)}},
+		})
+	}
+	if err := client.WriteEvents(t.Context(), "quarry", runID, events); err != nil {
+		t.Fatalf("WriteEvents: %v", err)
+	}
+}
+
+func TestInspectDiff_FS(t *testing.T) {
+	dir := t.TempDir()
+	writeDiffRun(t, dir, "run-a", 10)
+	writeDiffRun(t, dir, "run-b", 8)
+	args := []string{"quarry", "inspect", "--diff",
+		"--run-id", "run-b", "--baseline-run-id", "run-a",
+		"--storage-backend", "fs", "--storage-path", dir,
+		"--diff-item-key", "sku",
+	}
+
+	var out bytes.Buffer
+	if err := newInspectTestApp(&out).Run(args); err != nil {
+		t.Fatalf("inspect --diff: %v", err)
+	}
+	for _, want := range []string{
+		"diff: run-b vs baseline run-a",
+		"20.0%  changed",
+		"item keys (sku): 0 added, 2 removed, 8 unchanged (20.0% drift)",
+		"max drift: 20.0%",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	err := newInspectTestApp(&bytes.Buffer{}).Run(append(args, "--max-drift", "25"))
+	if err != nil {
+		t.Errorf("drift under --max-drift: %v", err)
+	}
+	err = newInspectTestApp(&bytes.Buffer{}).Run(append(args, "--max-drift", "10"))
+	if err == nil || !strings.Contains(err.Error(), "drift 20.0% exceeds --max-drift 10%") {
+		t.Errorf("err = %v, want a drift gate failure", err)
+	}
+}
+
+func TestInspectDiff_RequiresRunIDs(t *testing.T) {
+	err := newInspectTestApp(&bytes.Buffer{}).Run([]string{"quarry", "inspect", "--diff",
+		"--run-id", "run-b", "--storage-backend", "fs", "--storage-path", t.TempDir(),
+	})
+	if err == nil || !strings.Contains(err.Error(), "--diff requires --run-id and --baseline-run-id") {
+		t.Errorf("err = %v, want missing baseline error", err)
+	}
+}
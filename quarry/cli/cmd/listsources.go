@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/pithecene-io/quarry/cli/render"
+	"github.com/pithecene-io/quarry/lode"
+)
+
+// ListSourcesCommand returns the list-sources command.
+// Enumerates the sources of a storage location from its partition
+// directories (per CONTRACT_CLI.md), without reading event data.
+func ListSourcesCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "list-sources",
+		Usage:     "List the distinct sources (and optionally categories/days) in a storage location",
+		UsageText: "quarry list-sources --storage-backend <fs|s3> --storage-path <path> [--categories] [--days] [--json]",
+		Flags: append(ReadOnlyFlags(),
+			&cli.StringFlag{Name: "storage-dataset", Usage: "Lode dataset ID (default: \"quarry\")", Value: lode.DefaultDataset},
+			&cli.StringFlag{Name: "storage-backend", Usage: "Storage backend: fs or s3", Required: true},
+			&cli.StringFlag{Name: "storage-path", Usage: "Storage path (fs: directory, s3: bucket/prefix)", Required: true},
+			&cli.StringFlag{Name: "storage-region", Usage: "AWS region for S3 backend"},
+			&cli.BoolFlag{Name: "categories", Usage: "Also list the categories of each source"},
+			&cli.BoolFlag{Name: "days", Usage: "Also list the days of each source and category (implies --categories)"},
+		),
+		Action: listSourcesAction,
+	}
+}
+
+// sourceRow, sourceCategoryRow and sourceDayRow are the list-sources
+// output rows, one type per depth so tables carry no empty columns.
+type sourceRow struct {
+	Source string `json:"source"`
+}
+
+type sourceCategoryRow struct {
+	Source   string `json:"source"`
+	Category string `json:"category"`
+}
+
+type sourceDayRow struct {
+	Source   string `json:"source"`
+	Category string `json:"category"`
+	Day      string `json:"day"`
+}
+
+func listSourcesAction(c *cli.Context) error {
+	r, err := render.NewRenderer(c)
+	if err != nil {
+		return err
+	}
+	if c.Bool("tui") {
+		return cli.Exit("--tui is not supported for list commands", 1)
+	}
+
+	lister, err := buildDirLister(c.String("storage-backend"), c.String("storage-path"), c.String("storage-region"))
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("failed to initialize storage reader: %v", err), exitConfigError)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	opts := lode.ListSourcesOptions{Categories: c.Bool("categories"), Days: c.Bool("days")}
+	listings, err := lode.ListSources(ctx, lister, c.String("storage-dataset"), opts)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+	return r.Render(sourceRows(listings, opts))
+}
+
+// sourceRows converts listings to the output rows for the requested depth.
+func sourceRows(listings []lode.SourceListing, opts lode.ListSourcesOptions) any {
+	switch {
+	case opts.Days:
+		rows := make([]sourceDayRow, 0, len(listings))
+		for _, l := range listings {
+			rows = append(rows, sourceDayRow(l))
+		}
+		return rows
+	case opts.Categories:
+		rows := make([]sourceCategoryRow, 0, len(listings))
+		for _, l := range listings {
+			rows = append(rows, sourceCategoryRow{Source: l.Source, Category: l.Category})
+		}
+		return rows
+	default:
+		rows := make([]sourceRow, 0, len(listings))
+		for _, l := range listings {
+			rows = append(rows, sourceRow{Source: l.Source})
+		}
+		return rows
+	}
+}
+
+// buildDirLister creates a partition directory lister based on CLI flags.
+func buildDirLister(backend, path, region string) (lode.DirLister, error) {
+	switch backend {
+	case "fs":
+		return lode.NewFSDirLister(path), nil
+	case "s3":
+		bucket, prefix := lode.ParseS3Path(path)
+		return lode.NewS3DirLister(lode.S3Config{Bucket: bucket, Prefix: prefix, Region: region})
+	default:
+		return nil, fmt.Errorf("unsupported storage-backend: %s (must be fs or s3)", backend)
+	}
+}
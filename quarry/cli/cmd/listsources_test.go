@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/pithecene-io/quarry/cli/render"
+	"github.com/pithecene-io/quarry/lode"
+)
+
+func TestSourceRows(t *testing.T) {
+	listings := []lode.SourceListing{
+		{Source: "news", Category: "articles", Day: "2026-03-01"},
+		{Source: "shop", Category: "products", Day: "2026-03-02"},
+	}
+
+	tests := []struct {
+		name string
+		opts lode.ListSourcesOptions
+		want string
+	}{
+		{"sources", lode.ListSourcesOptions{}, "source\nnews\nshop\n"},
+		{"categories", lode.ListSourcesOptions{Categories: true}, "source  category\nnews    articles\nshop    products\n"},
+		{"days", lode.ListSourcesOptions{Days: true}, "source  category  day\nnews    articles  2026-03-01\nshop    products  2026-03-02\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := render.NewRendererWithWriter(render.FormatTable, true, &out).Render(sourceRows(listings, tt.opts)); err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("table =\n%s\nwant\n%s", out.String(), tt.want)
+			}
+		})
+	}
+
+	var out bytes.Buffer
+	if err := render.NewRendererWithWriter(render.FormatJSON, true, &out).Render(sourceRows(nil, lode.ListSourcesOptions{})); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if strings.TrimSpace(out.String()) != "[]" {
+		t.Errorf("empty JSON = %q, want []", out.String())
+	}
+}
+
+func TestListSourcesAction_UnsupportedBackend(t *testing.T) {
+	app := &cli.App{
+		Commands:       []*cli.Command{ListSourcesCommand()},
+		ExitErrHandler: func(c *cli.Context, err error) {}, // suppress os.Exit
+	}
+	err := app.Run([]string{"quarry", "list-sources",
+		"--storage-backend", "gcs", "--storage-path", "bucket",
+	})
+	if err == nil || !strings.Contains(err.Error(), "unsupported storage-backend: gcs") {
+		t.Errorf("err = %v, want unsupported backend", err)
+	}
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+)
+
+// parallelAuto is the --parallel value that sizes fan-out workers from the
+// container CPU limit.
+const parallelAuto = "auto"
+
+// defaultParallelMax caps --parallel auto on large hosts.
+const defaultParallelMax = 8
+
+// cgroupRoot is the cgroup filesystem mount; a variable for tests.
+var cgroupRoot = "/sys/fs/cgroup"
+
+// parseParallel parses --parallel: a positive integer or "auto".
+// auto reports true with n = 0; validateFanOutConfig resolves it.
+func parseParallel(s string) (n int, auto bool, err error) {
+	if strings.EqualFold(strings.TrimSpace(s), parallelAuto) {
+		return 0, true, nil
+	}
+	n, err = strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0, false, fmt.Errorf("--parallel must be an integer or %q, got %q", parallelAuto, s)
+	}
+	return n, false, nil
+}
+
+// autoParallel returns the CPU limit of the current cgroup, rounded up,
+// or GOMAXPROCS when no limit is set, clamped to [1, maxWorkers].
+func autoParallel(maxWorkers int) int {
+	n := goruntime.GOMAXPROCS(0)
+	if quota, ok := cgroupCPULimit(cgroupRoot); ok {
+		n = int(math.Ceil(quota))
+	}
+	return min(max(n, 1), maxWorkers)
+}
+
+// cgroupCPULimit reads the CPU bandwidth limit in CPUs from cgroup v2
+// (cpu.max) or, failing that, cgroup v1 (cpu.cfs_quota_us / cpu.cfs_period_us).
+// ok is false when no limit is set or the files are unreadable.
+func cgroupCPULimit(root string) (cpus float64, ok bool) {
+	if data, err := os.ReadFile(filepath.Join(root, "cpu.max")); err == nil {
+		fields := strings.Fields(string(data))
+		if len(fields) == 2 && fields[0] != "max" {
+			return cpuRatio(fields[0], fields[1])
+		}
+		return 0, false
+	}
+	quota, err := os.ReadFile(filepath.Join(root, "cpu", "cpu.cfs_quota_us"))
+	if err != nil {
+		return 0, false
+	}
+	period, err := os.ReadFile(filepath.Join(root, "cpu", "cpu.cfs_period_us"))
+	if err != nil {
+		return 0, false
+	}
+	return cpuRatio(strings.TrimSpace(string(quota)), strings.TrimSpace(string(period)))
+}
+
+// cpuRatio returns quota/period; a non-positive quota (v1 uses -1) means
+// unlimited.
+func cpuRatio(quota, period string) (float64, bool) {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil || q <= 0 {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil || p <= 0 {
+		return 0, false
+	}
+	return q / p, true
+}
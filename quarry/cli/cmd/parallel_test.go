@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeCgroupFile(t *testing.T, root, name, content string) {
+	t.Helper()
+	path := filepath.Join(root, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseParallel(t *testing.T) {
+	tests := []struct {
+		in       string
+		wantN    int
+		wantAuto bool
+		wantErr  bool
+	}{
+		{in: "4", wantN: 4},
+		{in: "auto", wantAuto: true},
+		{in: "AUTO", wantAuto: true},
+		{in: "0", wantN: 0},
+		{in: "many", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			n, auto, err := parseParallel(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseParallel(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+			if n != tt.wantN || auto != tt.wantAuto {
+				t.Errorf("parseParallel(%q) = (%d, %v), want (%d, %v)", tt.in, n, auto, tt.wantN, tt.wantAuto)
+			}
+		})
+	}
+}
+
+func TestCgroupCPULimit(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		want   float64
+		wantOK bool
+	}{
+		{name: "v2 limit", files: map[string]string{"cpu.max": "250000 100000\n"}, want: 2.5, wantOK: true},
+		{name: "v2 unlimited", files: map[string]string{"cpu.max": "max 100000\n"}},
+		{name: "v1 limit", files: map[string]string{"cpu/cpu.cfs_quota_us": "50000\n", "cpu/cpu.cfs_period_us": "100000\n"}, want: 0.5, wantOK: true},
+		{name: "v1 unlimited", files: map[string]string{"cpu/cpu.cfs_quota_us": "-1\n", "cpu/cpu.cfs_period_us": "100000\n"}},
+		{name: "no cgroup files"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for name, content := range tt.files {
+				writeCgroupFile(t, root, name, content)
+			}
+			got, ok := cgroupCPULimit(root)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("cgroupCPULimit = (%v, %v), want (%v, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestAutoParallel_UsesCgroupLimit(t *testing.T) {
+	root := t.TempDir()
+	writeCgroupFile(t, root, "cpu.max", "150000 100000\n")
+	orig := cgroupRoot
+	cgroupRoot = root
+	t.Cleanup(func() { cgroupRoot = orig })
+
+	// 1.5 CPUs rounds up to 2
+	if got := autoParallel(8); got != 2 {
+		t.Errorf("autoParallel(8) = %d, want 2", got)
+	}
+	// Clamped to the max
+	writeCgroupFile(t, root, "cpu.max", "3200000 100000\n")
+	if got := autoParallel(8); got != 8 {
+		t.Errorf("autoParallel(8) = %d, want 8", got)
+	}
+}
+
+func TestValidateFanOutConfig_ResolvesParallelAuto(t *testing.T) {
+	choice := fanOutChoice{depth: 1, maxRuns: 10, parallelAuto: true, parallelMax: 1}
+	if err := validateFanOutConfig(&choice); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if choice.parallel != 1 {
+		t.Errorf("parallel = %d, want 1 (clamped by parallel-max)", choice.parallel)
+	}
+
+	choice = fanOutChoice{depth: 1, maxRuns: 10, parallelAuto: true, parallelMax: 0}
+	if err := validateFanOutConfig(&choice); err == nil {
+		t.Error("expected error for --parallel-max 0")
+	}
+}
@@ -248,7 +248,27 @@ func TestCLIParityInspectCommand(t *testing.T) {
 		t.Fatal("parity artifact missing 'inspect' command")
 	}
 
-	// Inspect has subcommands, not top-level flags
+	// Top-level flags belong to inspect --diff
+	actualDiffFlags := extractFlags(inspectCmd)
+	for flagName, parityFlag := range parityInspect.Flags {
+		actualFlag, exists := actualDiffFlags[flagName]
+		if !exists {
+			t.Errorf("parity declares flag --%s for 'inspect' but it does not exist", flagName)
+			continue
+		}
+		if actualType := getFlagType(actualFlag); actualType != parityFlag.Type {
+			t.Errorf("flag --%s: parity says type %q but actual is %q", flagName, parityFlag.Type, actualType)
+		}
+		if actualDefault := getFlagDefault(actualFlag); parityFlag.Default != nil && actualDefault != parityFlag.Default {
+			t.Errorf("flag --%s: parity says default=%v but actual is %v", flagName, parityFlag.Default, actualDefault)
+		}
+	}
+	for flagName := range actualDiffFlags {
+		if _, exists := parityInspect.Flags[flagName]; !exists {
+			t.Errorf("CLI 'inspect' has flag --%s but it is not in parity artifact", flagName)
+		}
+	}
+
 	for _, subCmd := range inspectCmd.Subcommands {
 		subName := subCmd.Name
 		paritySubCmd, ok := parityInspect.Subcommands[subName]
@@ -308,6 +328,139 @@ func TestCLIParityStatsCommand(t *testing.T) {
 	}
 }
 
+// TestCLIParityGenRunIDCommand validates the gen-run-id command flags against the parity artifact.
+func TestCLIParityGenRunIDCommand(t *testing.T) {
+	artifact := loadParityArtifact(t)
+	actualFlags := extractFlags(GenRunIDCommand())
+
+	parityGen, ok := artifact.Commands["gen-run-id"]
+	if !ok {
+		t.Fatal("parity artifact missing 'gen-run-id' command")
+	}
+
+	for flagName, parityFlag := range parityGen.Flags {
+		actualFlag, exists := actualFlags[flagName]
+		if !exists {
+			t.Errorf("parity declares flag --%s for 'gen-run-id' but it does not exist", flagName)
+			continue
+		}
+		if actualType := getFlagType(actualFlag); actualType != parityFlag.Type {
+			t.Errorf("flag --%s: parity says type %q but actual is %q", flagName, parityFlag.Type, actualType)
+		}
+		if actualDefault := getFlagDefault(actualFlag); parityFlag.Default != nil && actualDefault != parityFlag.Default {
+			t.Errorf("flag --%s: parity says default=%v but actual is %v", flagName, parityFlag.Default, actualDefault)
+		}
+	}
+
+	for flagName := range actualFlags {
+		if _, exists := parityGen.Flags[flagName]; !exists {
+			t.Errorf("CLI 'gen-run-id' has flag --%s but it is not in parity artifact", flagName)
+		}
+	}
+}
+
+// TestCLIParityVerifyCommand validates the verify command flags against the parity artifact.
+func TestCLIParityVerifyCommand(t *testing.T) {
+	artifact := loadParityArtifact(t)
+	actualFlags := extractFlags(VerifyCommand())
+
+	parityVerify, ok := artifact.Commands["verify"]
+	if !ok {
+		t.Fatal("parity artifact missing 'verify' command")
+	}
+
+	for flagName, parityFlag := range parityVerify.Flags {
+		actualFlag, exists := actualFlags[flagName]
+		if !exists {
+			t.Errorf("parity declares flag --%s for 'verify' but it does not exist", flagName)
+			continue
+		}
+		if actualType := getFlagType(actualFlag); actualType != parityFlag.Type {
+			t.Errorf("flag --%s: parity says type %q but actual is %q", flagName, parityFlag.Type, actualType)
+		}
+		if actualRequired := isFlagRequired(actualFlag); actualRequired != parityFlag.Required {
+			t.Errorf("flag --%s: parity says required=%v but actual is %v", flagName, parityFlag.Required, actualRequired)
+		}
+		if actualDefault := getFlagDefault(actualFlag); parityFlag.Default != nil && actualDefault != parityFlag.Default {
+			t.Errorf("flag --%s: parity says default=%v but actual is %v", flagName, parityFlag.Default, actualDefault)
+		}
+	}
+
+	for flagName := range actualFlags {
+		if _, exists := parityVerify.Flags[flagName]; !exists {
+			t.Errorf("CLI 'verify' has flag --%s but it is not in parity artifact", flagName)
+		}
+	}
+}
+
+// TestCLIParityListSourcesCommand validates the list-sources command flags against the parity artifact.
+func TestCLIParityListSourcesCommand(t *testing.T) {
+	artifact := loadParityArtifact(t)
+	actualFlags := extractFlags(ListSourcesCommand())
+
+	parityList, ok := artifact.Commands["list-sources"]
+	if !ok {
+		t.Fatal("parity artifact missing 'list-sources' command")
+	}
+
+	for flagName, parityFlag := range parityList.Flags {
+		actualFlag, exists := actualFlags[flagName]
+		if !exists {
+			t.Errorf("parity declares flag --%s for 'list-sources' but it does not exist", flagName)
+			continue
+		}
+		if actualType := getFlagType(actualFlag); actualType != parityFlag.Type {
+			t.Errorf("flag --%s: parity says type %q but actual is %q", flagName, parityFlag.Type, actualType)
+		}
+		if actualRequired := isFlagRequired(actualFlag); actualRequired != parityFlag.Required {
+			t.Errorf("flag --%s: parity says required=%v but actual is %v", flagName, parityFlag.Required, actualRequired)
+		}
+		if actualDefault := getFlagDefault(actualFlag); parityFlag.Default != nil && actualDefault != parityFlag.Default {
+			t.Errorf("flag --%s: parity says default=%v but actual is %v", flagName, parityFlag.Default, actualDefault)
+		}
+	}
+
+	for flagName := range actualFlags {
+		if _, exists := parityList.Flags[flagName]; !exists {
+			t.Errorf("CLI 'list-sources' has flag --%s but it is not in parity artifact", flagName)
+		}
+	}
+}
+
+// TestCLIParityReplayIntoCommand validates the replay-into command flags against the parity artifact.
+func TestCLIParityReplayIntoCommand(t *testing.T) {
+	artifact := loadParityArtifact(t)
+	actualFlags := extractFlags(ReplayIntoCommand())
+
+	parityReplay, ok := artifact.Commands["replay-into"]
+	if !ok {
+		t.Fatal("parity artifact missing 'replay-into' command")
+	}
+
+	for flagName, parityFlag := range parityReplay.Flags {
+		actualFlag, exists := actualFlags[flagName]
+		if !exists {
+			t.Errorf("parity declares flag --%s for 'replay-into' but it does not exist", flagName)
+			continue
+		}
+		if actualType := getFlagType(actualFlag); actualType != parityFlag.Type {
+			t.Errorf("flag --%s: parity says type %q but actual is %q", flagName, parityFlag.Type, actualType)
+		}
+		if actualRequired := isFlagRequired(actualFlag); actualRequired != parityFlag.Required {
+			t.Errorf("flag --%s: parity says required=%v but actual is %v", flagName, parityFlag.Required, actualRequired)
+		}
+		if actualDefault := getFlagDefault(actualFlag); parityFlag.Default != nil && actualDefault != parityFlag.Default {
+			t.Errorf("flag --%s: parity says default=%v but actual is %v", flagName, parityFlag.Default, actualDefault)
+		}
+	}
+
+	for flagName := range actualFlags {
+		if _, exists := parityReplay.Flags[flagName]; !exists {
+			t.Errorf("CLI 'replay-into' has flag --%s but it is not in parity artifact", flagName)
+		}
+	}
+}
+
 // TestCLIParityVersionCommand validates the version command flags against the parity artifact.
 func TestCLIParityVersionCommand(t *testing.T) {
 	artifact := loadParityArtifact(t)
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	goruntime "runtime"
+	"runtime/pprof"
+
+	"github.com/pithecene-io/quarry/iox"
+)
+
+// Profile kinds accepted by --profile.
+const (
+	profileCPU  = "cpu"
+	profileHeap = "heap"
+)
+
+// runProfile is an in-progress --profile capture of the quarry process
+// (not the executor). A nil runProfile is disabled and costs nothing.
+type runProfile struct {
+	kind string
+	file *os.File
+}
+
+// startProfile validates --profile/--profile-out and starts the capture.
+// The output file is created up front so a bad path fails before the run.
+// A CPU profile samples from now until stop; a heap profile is taken at
+// stop. Returns nil when kind is empty.
+func startProfile(kind, path string) (*runProfile, error) {
+	if kind == "" {
+		if path != "" {
+			fmt.Fprintf(os.Stderr, "Warning: --profile-out is ignored without --profile\n")
+		}
+		return nil, nil
+	}
+	if kind != profileCPU && kind != profileHeap {
+		return nil, fmt.Errorf("invalid --profile %q (supported: cpu, heap)", kind)
+	}
+	if path == "" {
+		return nil, errors.New("--profile-out is required with --profile")
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("--profile-out: %w", err)
+	}
+	if kind == profileCPU {
+		if err := pprof.StartCPUProfile(f); err != nil {
+			iox.DiscardClose(f)
+			return nil, fmt.Errorf("--profile cpu: %w", err)
+		}
+	}
+	return &runProfile{kind: kind, file: f}, nil
+}
+
+// stop finishes the capture and closes the file. Failures are warnings:
+// profiling never changes the run's exit code. Nil-receiver safe.
+func (p *runProfile) stop() {
+	if p == nil {
+		return
+	}
+	var err error
+	switch p.kind {
+	case profileCPU:
+		pprof.StopCPUProfile()
+	case profileHeap:
+		// Up-to-date allocation statistics, as go test -memprofile does
+		goruntime.GC()
+		err = pprof.WriteHeapProfile(p.file)
+	}
+	if closeErr := p.file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write %s profile: %v\n", p.kind, err)
+	}
+}
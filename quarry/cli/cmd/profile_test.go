@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStartProfile_WritesPprof(t *testing.T) {
+	for _, kind := range []string{profileCPU, profileHeap} {
+		t.Run(kind, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), kind+".pprof")
+			profile, err := startProfile(kind, path)
+			if err != nil {
+				t.Fatalf("startProfile: %v", err)
+			}
+			profile.stop()
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read profile: %v", err)
+			}
+			// pprof profiles are gzip-compressed protobuf
+			if !bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+				t.Errorf("%s profile is not gzip (%d bytes)", kind, len(data))
+			}
+		})
+	}
+}
+
+func TestStartProfile_Disabled(t *testing.T) {
+	profile, err := startProfile("", "")
+	if profile != nil || err != nil {
+		t.Fatalf("startProfile(\"\") = %v, %v; want nil, nil", profile, err)
+	}
+	profile.stop() // nil-safe
+}
+
+func TestStartProfile_Errors(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name, kind, path, errContains string
+	}{
+		{"unknown kind", "mutex", filepath.Join(dir, "p.pprof"), "supported: cpu, heap"},
+		{"missing out", profileCPU, "", "--profile-out is required"},
+		{"bad out", profileHeap, filepath.Join(dir, "missing", "p.pprof"), "--profile-out:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := startProfile(tt.kind, tt.path)
+			if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("err = %v, want containing %q", err, tt.errContains)
+			}
+		})
+	}
+}
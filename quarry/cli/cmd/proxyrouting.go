@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// proxyRoutePrefixRegex marks a proxy_routing key as a regular expression
+// (matched against the whole source) instead of a glob.
+const proxyRoutePrefixRegex = "re:"
+
+// proxyRoute is one compiled proxy_routing entry.
+type proxyRoute struct {
+	pattern string
+	pool    string
+	re      *regexp.Regexp // nil for glob patterns
+}
+
+// compileProxyRoutes validates proxy_routing patterns. Keys are path.Match
+// globs (amazon-*), or regular expressions when prefixed with "re:".
+func compileProxyRoutes(routing map[string]string) ([]proxyRoute, error) {
+	routes := make([]proxyRoute, 0, len(routing))
+	for pattern, pool := range routing {
+		if pool == "" {
+			return nil, fmt.Errorf("proxy_routing %q: pool name is empty", pattern)
+		}
+		route := proxyRoute{pattern: pattern, pool: pool}
+		if expr, ok := strings.CutPrefix(pattern, proxyRoutePrefixRegex); ok {
+			re, err := regexp.Compile(`^(?:` + expr + `)$`)
+			if err != nil {
+				return nil, fmt.Errorf("proxy_routing %q: %w", pattern, err)
+			}
+			route.re = re
+		} else if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("proxy_routing %q: %w", pattern, err)
+		}
+		routes = append(routes, route)
+	}
+	sort.Slice(routes, func(i, j int) bool { return routes[i].pattern < routes[j].pattern })
+	return routes, nil
+}
+
+// routeProxyPool returns the pool whose proxy_routing pattern matches
+// source, and that pattern. No match returns empty strings. More than one
+// matching pattern is an error, even when they name the same pool, so
+// overlapping routes are caught when they are written.
+func routeProxyPool(routing map[string]string, source string) (pool, pattern string, err error) {
+	routes, err := compileProxyRoutes(routing)
+	if err != nil {
+		return "", "", err
+	}
+	var matched []proxyRoute
+	for _, r := range routes {
+		if r.matches(source) {
+			matched = append(matched, r)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return "", "", nil
+	case 1:
+		return matched[0].pool, matched[0].pattern, nil
+	default:
+		patterns := make([]string, len(matched))
+		for i, r := range matched {
+			patterns[i] = fmt.Sprintf("%q", r.pattern)
+		}
+		return "", "", fmt.Errorf("proxy_routing: source %q matches %s; use --proxy-pool or make the patterns disjoint",
+			source, strings.Join(patterns, ", "))
+	}
+}
+
+func (r proxyRoute) matches(source string) bool {
+	if r.re != nil {
+		return r.re.MatchString(source)
+	}
+	ok, _ := path.Match(r.pattern, source) // syntax checked by compileProxyRoutes
+	return ok
+}
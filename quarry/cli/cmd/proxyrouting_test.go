@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRouteProxyPool(t *testing.T) {
+	routing := map[string]string{
+		"amazon-*":          "residential",
+		"re:(ebay|etsy)-.+": "datacenter",
+		"walmart-[0-9]":     "residential",
+	}
+	tests := []struct {
+		source      string
+		wantPool    string
+		wantPattern string
+	}{
+		{source: "amazon-us", wantPool: "residential", wantPattern: "amazon-*"},
+		{source: "etsy-eu", wantPool: "datacenter", wantPattern: "re:(ebay|etsy)-.+"},
+		{source: "walmart-1", wantPool: "residential", wantPattern: "walmart-[0-9]"},
+		{source: "target", wantPool: ""},
+		// Regexes match the whole source
+		{source: "my-ebay-us", wantPool: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			pool, pattern, err := routeProxyPool(routing, tt.source)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if pool != tt.wantPool || pattern != tt.wantPattern {
+				t.Errorf("routeProxyPool(%q) = (%q, %q), want (%q, %q)", tt.source, pool, pattern, tt.wantPool, tt.wantPattern)
+			}
+		})
+	}
+}
+
+func TestRouteProxyPool_AmbiguousMatch(t *testing.T) {
+	routing := map[string]string{
+		"amazon-*":  "residential",
+		"*-us":      "datacenter",
+		"unrelated": "datacenter",
+	}
+	_, _, err := routeProxyPool(routing, "amazon-us")
+	if err == nil {
+		t.Fatal("expected ambiguity error")
+	}
+	if !strings.Contains(err.Error(), `"*-us", "amazon-*"`) {
+		t.Errorf("error should list both patterns, got: %v", err)
+	}
+}
+
+func TestCompileProxyRoutes_Invalid(t *testing.T) {
+	tests := []map[string]string{
+		{"amazon-[": "residential"},
+		{"re:(": "residential"},
+		{"amazon-*": ""},
+	}
+	for _, routing := range tests {
+		if _, err := compileProxyRoutes(routing); err == nil {
+			t.Errorf("compileProxyRoutes(%v) = nil error, want error", routing)
+		}
+	}
+}
@@ -0,0 +1,345 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/pithecene-io/quarry/runtime"
+	"github.com/pithecene-io/quarry/types"
+)
+
+// replayExcluded lists settings that identify a particular run or only
+// control output, so they are neither recorded in the manifest nor replayed.
+var replayExcluded = map[string]bool{
+	"config":          true,
+	"script":          true,
+	"job":             true,
+	"job-json":        true,
+	"run-id":          true,
+	"attempt":         true,
+	"parent-run-id":   true,
+	"replay":          true,
+	"explain":         true,
+	"dry-run":         true,
+	"quiet":           true,
+	"overwrite":       true,
+	"report":          true,
+	"output-manifest": true,
+}
+
+// replayRunIDLayout is the UTC timestamp suffix of generated replay run IDs.
+const replayRunIDLayout = "20060102T150405Z"
+
+// manifestInputs returns the run inputs recorded in --output-manifest.
+// job is the payload as passed to the script (after --since-checkpoint
+// injection), so a replay sees identical input.
+func (e *explainer) manifestInputs(script, configPath string, job map[string]any) *runtime.ManifestInputs {
+	inputs := &runtime.ManifestInputs{
+		Script:   absPath(script),
+		Job:      job,
+		Settings: e.manifestSettings(),
+	}
+	if configPath != "" {
+		inputs.ConfigFile = absPath(configPath)
+	}
+	return inputs
+}
+
+// manifestSettings converts the recorded settings, in resolution order.
+func (e *explainer) manifestSettings() []runtime.ManifestSetting {
+	settings := make([]runtime.ManifestSetting, 0, len(e.entries))
+	for _, entry := range e.entries {
+		if replayExcluded[entry.name] {
+			continue
+		}
+		settings = append(settings, manifestSetting(entry))
+	}
+	return settings
+}
+
+// manifestSetting renders one setting. URL credentials are stripped, as in
+// --explain output, and the setting is marked redacted.
+func manifestSetting(entry explainEntry) runtime.ManifestSetting {
+	s := runtime.ManifestSetting{Name: entry.name, Source: string(entry.source)}
+	switch v := entry.value.(type) {
+	case string:
+		s.Value, s.Redacted = redactSettingValue(v)
+	case []string:
+		s.Values, s.Redacted = redactSettingValues(v)
+	case cli.StringSlice:
+		s.Values, s.Redacted = redactSettingValues(v.Value())
+	case time.Duration:
+		s.Value = v.String()
+	default:
+		s.Value = fmt.Sprint(v)
+	}
+	return s
+}
+
+func redactSettingValue(v string) (string, bool) {
+	if u, err := url.Parse(v); err == nil && u.User != nil {
+		return u.Redacted(), true
+	}
+	return v, false
+}
+
+func redactSettingValues(vs []string) ([]string, bool) {
+	if len(vs) == 0 {
+		return nil, false
+	}
+	out := make([]string, len(vs))
+	redacted := false
+	for i, v := range vs {
+		var r bool
+		out[i], r = redactSettingValue(v)
+		redacted = redacted || r
+	}
+	return out, redacted
+}
+
+// settingDisplay renders a manifest setting the way --explain prints it.
+func settingDisplay(s runtime.ManifestSetting) string {
+	if s.Values != nil {
+		return formatExplainValue(s.Values)
+	}
+	return formatExplainValue(s.Value)
+}
+
+func absPath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return p
+}
+
+// replayDiff is a recorded setting the replay could not reproduce.
+type replayDiff struct {
+	name     string
+	recorded string
+	now      string
+	note     string
+}
+
+// replayPlan is a --replay manifest applied to the run command's flags.
+type replayPlan struct {
+	path          string
+	originalRunID string
+	recorded      []runtime.ManifestSetting
+	notes         map[string]string // why a setting was not applied
+	diffs         []replayDiff
+	reported      map[string]bool
+}
+
+// loadReplay reads the --replay manifest and sets every recorded flag the
+// user did not pass explicitly. Settings that came from defaults are not
+// pinned; compare reports any that resolve differently now. Returns nil
+// without --replay.
+func loadReplay(c *cli.Context) (*replayPlan, error) {
+	path := c.String("replay")
+	if path == "" {
+		return nil, nil
+	}
+	manifest, err := runtime.ReadRunManifest(path)
+	if err != nil {
+		return nil, err
+	}
+	in := manifest.Inputs
+	if in == nil {
+		return nil, fmt.Errorf("manifest %s has no inputs section; it was written by a quarry version without --replay support", path)
+	}
+
+	plan := &replayPlan{
+		path:          path,
+		originalRunID: manifest.RunID,
+		recorded:      in.Settings,
+		notes:         make(map[string]string),
+		reported:      make(map[string]bool),
+	}
+	if !c.IsSet("script") && in.Script != "" {
+		if err := c.Set("script", in.Script); err != nil {
+			return nil, err
+		}
+	}
+	if !c.IsSet("job") && !c.IsSet("job-json") && len(in.Job) > 0 {
+		data, err := json.Marshal(in.Job)
+		if err != nil {
+			return nil, fmt.Errorf("manifest %s: cannot encode recorded job: %w", path, err)
+		}
+		if err := c.Set("job", string(data)); err != nil {
+			return nil, err
+		}
+	}
+	if in.ConfigFile != "" && !c.IsSet("config") {
+		if _, err := os.Stat(in.ConfigFile); err == nil {
+			if err := c.Set("config", in.ConfigFile); err != nil {
+				return nil, err
+			}
+		} else {
+			plan.report("config", in.ConfigFile, "(none)", "config file not found")
+		}
+	}
+	if !c.IsSet("run-id") {
+		if err := c.Set("run-id", replayRunID(manifest.RunID, time.Now())); err != nil {
+			return nil, err
+		}
+	}
+	// The recorded job already carries any resume state
+	if !c.IsSet("since-checkpoint") {
+		if err := c.Set("since-checkpoint", "false"); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, s := range in.Settings {
+		switch {
+		case s.Name == "since-checkpoint":
+		case c.IsSet(s.Name):
+			plan.notes[s.Name] = "overridden by flag"
+		case s.Redacted:
+			plan.notes[s.Name] = "recorded value is redacted; supply it via flag or config"
+		case s.Name == "adapter-header":
+			plan.notes[s.Name] = "header values are not recorded"
+		case s.Source == string(sourceDefault):
+		default:
+			if err := applyReplaySetting(c, s); err != nil {
+				plan.notes[s.Name] = err.Error()
+			}
+		}
+	}
+	return plan, nil
+}
+
+// applyReplaySetting sets a recorded setting as if passed on the command
+// line. Settings without a flag of the same name are left to the config file.
+func applyReplaySetting(c *cli.Context, s runtime.ManifestSetting) error {
+	if !hasRunFlag(c, s.Name) {
+		return nil
+	}
+	if s.Values == nil {
+		return c.Set(s.Name, s.Value)
+	}
+	for _, v := range s.Values {
+		// Slice flags split each value on commas
+		if strings.Contains(v, ",") {
+			return fmt.Errorf("value %q contains a comma and cannot be replayed", v)
+		}
+	}
+	for _, v := range s.Values {
+		if err := c.Set(s.Name, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasRunFlag reports whether name is a flag on the current command.
+func hasRunFlag(c *cli.Context, name string) bool {
+	for _, cc := range c.Lineage() {
+		if cc.Command == nil {
+			continue
+		}
+		for _, f := range cc.Command.Flags {
+			for _, n := range f.Names() {
+				if n == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// replayRunID derives a fresh run ID from the replayed one.
+func replayRunID(original string, now time.Time) string {
+	suffix := "replay-" + now.UTC().Format(replayRunIDLayout)
+	if original == "" {
+		return suffix
+	}
+	return original + "-" + suffix
+}
+
+// report records a setting that could not be reproduced. The first report
+// for a setting wins.
+func (p *replayPlan) report(name, recorded, now, note string) {
+	if p.reported[name] {
+		return
+	}
+	p.reported[name] = true
+	p.diffs = append(p.diffs, replayDiff{name: name, recorded: recorded, now: now, note: note})
+}
+
+// compare reports every recorded setting whose resolved value differs in
+// this run.
+func (p *replayPlan) compare(e *explainer) {
+	current := make(map[string]runtime.ManifestSetting)
+	for _, s := range e.manifestSettings() {
+		current[s.Name] = s
+	}
+	for _, rec := range p.recorded {
+		if rec.Name == "since-checkpoint" {
+			continue
+		}
+		recorded, now := settingDisplay(rec), "(not resolved)"
+		cur, ok := current[rec.Name]
+		if ok {
+			now = settingDisplay(cur)
+		}
+		if recorded == now {
+			continue
+		}
+		note := p.notes[rec.Name]
+		switch {
+		case note != "":
+		case ok && cur.Source != rec.Source:
+			note = fmt.Sprintf("source changed from %s to %s", rec.Source, cur.Source)
+		default:
+			note = "resolved differently"
+		}
+		p.report(rec.Name, recorded, now, note)
+	}
+}
+
+// checkProxyPool reports a recorded proxy pool that is not defined in this
+// environment. The replay then runs without a proxy rather than failing.
+func (p *replayPlan) checkProxyPool(poolName string, configPools []types.ProxyPool, proxyConfigPath string) bool {
+	if p == nil || poolName == "" {
+		return true
+	}
+	pools := configPools
+	if len(pools) == 0 && proxyConfigPath != "" {
+		pools, _ = loadProxyPools(proxyConfigPath)
+	}
+	for _, pool := range pools {
+		if pool.Name == poolName {
+			return true
+		}
+	}
+	p.report("proxy-pool", poolName, "(unset)", "pool not defined; running without a proxy")
+	return false
+}
+
+// print writes the replay header and any unreproduced settings to w.
+func (p *replayPlan) print(w io.Writer, runID string) {
+	fmt.Fprintf(w, "Replaying run %s from %s as run_id=%s\n", p.originalRunID, p.path, runID)
+	if len(p.diffs) == 0 {
+		fmt.Fprintf(w, "All recorded settings reproduced.\n\n")
+		return
+	}
+	fmt.Fprintln(w, "Settings that could not be reproduced:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  SETTING\tRECORDED\tNOW\tNOTE")
+	for _, d := range p.diffs {
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", d.name, d.recorded, d.now, d.note)
+	}
+	_ = tw.Flush()
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/pithecene-io/quarry/clock"
+	"github.com/pithecene-io/quarry/iox"
+	"github.com/pithecene-io/quarry/lode"
+	"github.com/pithecene-io/quarry/runtime"
+)
+
+// ReplayIntoCommand returns the replay-into command.
+// Feeds a persisted run's events and chunks through a chosen policy and
+// storage sink without an executor, e.g. to compare policies on identical
+// input.
+func ReplayIntoCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "replay-into",
+		Usage:     "Replay a persisted run's events and chunks through a policy and sink, without an executor",
+		UsageText: "quarry replay-into --run-id <id> --storage-backend <fs|s3> --storage-path <path> [--policy <name> ...] [--into-backend <memory|fs|s3>]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "run-id", Usage: "Run ID whose partition to replay", Required: true},
+			&cli.StringFlag{Name: "storage-dataset", Usage: "Lode dataset ID (default: \"quarry\")", Value: lode.DefaultDataset},
+			&cli.StringFlag{Name: "storage-backend", Usage: "Storage backend to read from: fs or s3", Required: true},
+			&cli.StringFlag{Name: "storage-path", Usage: "Storage path to read from (fs: directory, s3: bucket/prefix)", Required: true},
+			&cli.StringFlag{Name: "storage-region", Usage: "AWS region for S3 (source and target)"},
+			&cli.StringFlag{Name: "into-backend", Usage: "Storage backend to write the replay to: memory (discarded at exit), fs, or s3", Value: "memory"},
+			&cli.StringFlag{Name: "into-path", Usage: "Storage path to write the replay to (required for fs and s3)"},
+			&cli.StringFlag{Name: "into-run-id", Usage: "Run ID of the replayed partition (default: <run-id>-replay-<timestamp>)"},
+			&cli.StringFlag{Name: "policy", Usage: "Ingestion policy: strict, buffered, or streaming", Value: "strict"},
+			&cli.StringFlag{Name: "flush-mode", Usage: "Flush mode for buffered policy: at_least_once, chunks_first, two_phase", Value: "at_least_once"},
+			&cli.BoolFlag{Name: "parallel-flush", Usage: "Write chunks and independent events concurrently during flush (buffered policy, two_phase only)"},
+			&cli.IntFlag{Name: "buffer-events", Usage: "Max buffered events (buffered policy)"},
+			&cli.Int64Flag{Name: "buffer-bytes", Usage: "Max buffer size in bytes (buffered policy)"},
+			&cli.IntFlag{Name: "flush-count", Usage: "Flush after N events accumulate (streaming policy)"},
+			&cli.DurationFlag{Name: "flush-interval", Usage: "Flush every duration, e.g. 5s, 30s (streaming policy)"},
+			&cli.DurationFlag{Name: "flush-idle", Usage: "Flush once no events have arrived for this duration, e.g. 2s (streaming policy)"},
+			&cli.IntFlag{Name: "events-batch-size", Usage: "Write events in batches of up to N (strict policy)"},
+			&cli.DurationFlag{Name: "strict-batch-window", Usage: "Write a pending event batch once its oldest event is this old, e.g. 100ms (strict policy)"},
+			&cli.IntFlag{Name: "strict-degrade-buffer", Usage: "On a retryable sink error, buffer up to N unwritten events and retry with backoff (strict policy)"},
+		},
+		Action: replayIntoAction,
+	}
+}
+
+func replayIntoAction(c *cli.Context) error {
+	clk := clock.FromContext(c.Context)
+	choice := policyChoice{
+		name:          c.String("policy"),
+		flushMode:     c.String("flush-mode"),
+		parallelFlush: c.Bool("parallel-flush"),
+		maxEvents:     c.Int("buffer-events"),
+		maxBytes:      c.Int64("buffer-bytes"),
+		flushCount:    c.Int("flush-count"),
+		flushInterval: c.Duration("flush-interval"),
+		flushIdle:     c.Duration("flush-idle"),
+		batchSize:     c.Int("events-batch-size"),
+		batchWindow:   c.Duration("strict-batch-window"),
+		degradeBuffer: c.Int("strict-degrade-buffer"),
+		clock:         clk,
+	}
+	if err := validatePolicyConfig(choice); err != nil {
+		return cli.Exit(fmt.Sprintf("invalid policy config: %v", err), exitConfigError)
+	}
+	target := storageChoice{
+		backend: c.String("into-backend"),
+		path:    c.String("into-path"),
+		region:  c.String("storage-region"),
+	}
+	switch target.backend {
+	case "memory":
+	case "fs", "s3":
+		if target.path == "" {
+			return cli.Exit(fmt.Sprintf("--into-path is required for --into-backend %s", target.backend), exitConfigError)
+		}
+	default:
+		return cli.Exit(fmt.Sprintf("unsupported --into-backend: %s (must be memory, fs, or s3)", target.backend), exitConfigError)
+	}
+	runID := c.String("run-id")
+	intoRunID := c.String("into-run-id")
+	if intoRunID == "" {
+		intoRunID = replayRunID(runID, clk.Now())
+	}
+
+	ds, err := buildReadDataset(c.String("storage-dataset"), c.String("storage-backend"), c.String("storage-path"), c.String("storage-region"))
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("failed to initialize storage reader: %v", err), exitConfigError)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	// Read before building the policy: the target partition takes the
+	// source run's partition keys, and events are relabeled to its run ID
+	records, err := lode.NewRunPartitionReader(ds, runID).ReadRunRecords(ctx)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+	for _, e := range records.Events {
+		e.RunID = intoRunID
+	}
+
+	pol, _, _, err := buildPolicy(choice, target, c.String("storage-dataset"),
+		records.Source, records.Category, intoRunID, clk.Now(), nil, nil)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("failed to create policy: %v", err), exitConfigError)
+	}
+	defer iox.DiscardClose(pol)
+
+	result, err := runtime.ReplayPartition(ctx, loadedRecords{records}, pol, clk)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("replay failed: %v", err), 1)
+	}
+	printReplayResult(c.App.Writer, runID, intoRunID, target.backend, choice.name, result)
+	return nil
+}
+
+// loadedRecords is a runtime.PartitionReader over records already read.
+type loadedRecords struct {
+	records *lode.RunRecords
+}
+
+func (l loadedRecords) ReadRunRecords(context.Context) (*lode.RunRecords, error) {
+	return l.records, nil
+}
+
+// printReplayResult prints the replay summary.
+func printReplayResult(w io.Writer, runID, intoRunID, backend, policyName string, result *runtime.ReplayResult) {
+	s := result.PolicyStats
+	_, _ = fmt.Fprintf(w, "replayed %s into %s (%s)\n", runID, intoRunID, backend)
+	_, _ = fmt.Fprintf(w, "events: %d  chunks: %d  duration: %s\n", result.Events, result.Chunks, result.Duration)
+	_, _ = fmt.Fprintf(w, "policy=%s  persisted=%d  dropped=%d  chunks_persisted=%d  flushes=%d  errors=%d\n",
+		policyName, s.EventsPersisted, s.EventsDropped, s.ChunksPersisted, s.FlushCount, s.Errors)
+}
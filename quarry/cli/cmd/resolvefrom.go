@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+
+	quarryconfig "github.com/pithecene-io/quarry/cli/config"
+)
+
+// resolveModulePaths resolves the executor's bare-specifier fallback
+// directories: --resolve-from entries (CLI > config) in order, then, with
+// --resolve-workspace, the detected workspace root and each of its local
+// packages. Every directory is absolutized and must exist. Duplicates keep
+// their first position. Returns nil when neither flag is set.
+func resolveModulePaths(c *cli.Context, cfg *quarryconfig.Config) ([]string, error) {
+	entries := c.StringSlice("resolve-from")
+	source := sourceFlag
+	if !c.IsSet("resolve-from") {
+		entries, source = nil, sourceDefault
+		if cfg != nil && len(cfg.ResolveFrom) > 0 {
+			entries, source = cfg.ResolveFrom, sourceConfig
+		}
+	}
+	explainFrom(c).record("resolve-from", entries, source)
+
+	var dirs []string
+	for _, entry := range entries {
+		dir, err := resolveFromDir(entry)
+		if err != nil {
+			return nil, err
+		}
+		dirs = appendUnique(dirs, dir)
+	}
+
+	workspace := resolveString(c, "resolve-workspace", configVal(cfg, func(c *quarryconfig.Config) string { return c.ResolveWorkspace }))
+	if workspace == "" {
+		return dirs, nil
+	}
+	start, err := filepath.Abs(workspace)
+	if err != nil {
+		return nil, fmt.Errorf("--resolve-workspace: cannot resolve path %q: %w", workspace, err)
+	}
+	root, patterns, err := findWorkspaceRoot(start)
+	if err != nil {
+		return nil, fmt.Errorf("--resolve-workspace: %w", err)
+	}
+	packages, err := workspacePackages(root, patterns)
+	if err != nil {
+		return nil, fmt.Errorf("--resolve-workspace: %w", err)
+	}
+	dirs = appendUnique(dirs, root)
+	for _, dir := range packages {
+		dirs = appendUnique(dirs, dir)
+	}
+	return dirs, nil
+}
+
+// resolveFromDir absolutizes one --resolve-from entry and checks that it
+// is an existing directory.
+func resolveFromDir(entry string) (string, error) {
+	abs, err := filepath.Abs(entry)
+	if err != nil {
+		return "", fmt.Errorf("--resolve-from: cannot resolve path %q: %w", entry, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("--resolve-from: directory does not exist: %s", abs)
+		}
+		return "", fmt.Errorf("--resolve-from: cannot access %q: %w", abs, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("--resolve-from: not a directory: %s", abs)
+	}
+	return abs, nil
+}
+
+// appendUnique appends s unless list already holds it.
+func appendUnique(list []string, s string) []string {
+	if slices.Contains(list, s) {
+		return list
+	}
+	return append(list, s)
+}
+
+// findWorkspaceRoot walks up from start to the nearest pnpm or yarn/npm
+// workspace root: a directory holding pnpm-workspace.yaml, or a
+// package.json with a "workspaces" field. Returns the root and its package
+// glob patterns.
+func findWorkspaceRoot(start string) (string, []string, error) {
+	for dir := start; ; dir = filepath.Dir(dir) {
+		patterns, found, err := readWorkspacePatterns(dir)
+		if err != nil {
+			return "", nil, err
+		}
+		if found {
+			return dir, patterns, nil
+		}
+		if filepath.Dir(dir) == dir {
+			return "", nil, fmt.Errorf("no pnpm-workspace.yaml or package.json workspaces found in %s or its parents", start)
+		}
+	}
+}
+
+// readWorkspacePatterns reads the workspace package patterns declared in
+// dir, preferring pnpm-workspace.yaml over package.json. found is false if
+// dir declares no workspace.
+func readWorkspacePatterns(dir string) (patterns []string, found bool, err error) {
+	pnpmFile := filepath.Join(dir, "pnpm-workspace.yaml")
+	data, err := os.ReadFile(pnpmFile)
+	switch {
+	case err == nil:
+		var manifest struct {
+			Packages []string `yaml:"packages"`
+		}
+		if err := yaml.Unmarshal(data, &manifest); err != nil {
+			return nil, false, fmt.Errorf("parsing %s: %w", pnpmFile, err)
+		}
+		return manifest.Packages, true, nil
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, false, fmt.Errorf("reading %s: %w", pnpmFile, err)
+	}
+
+	pkgFile := filepath.Join(dir, "package.json")
+	data, err = os.ReadFile(pkgFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("reading %s: %w", pkgFile, err)
+	}
+	var pkg struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, false, fmt.Errorf("parsing %s: %w", pkgFile, err)
+	}
+	if len(pkg.Workspaces) == 0 {
+		return nil, false, nil
+	}
+	// "workspaces" is either a pattern list or {"packages": [...]} (yarn)
+	if err := json.Unmarshal(pkg.Workspaces, &patterns); err == nil {
+		return patterns, true, nil
+	}
+	var nested struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(pkg.Workspaces, &nested); err != nil {
+		return nil, false, fmt.Errorf("parsing %s workspaces: %w", pkgFile, err)
+	}
+	return nested.Packages, true, nil
+}
+
+// workspacePackages returns the sorted absolute directories under root that
+// hold a package.json and match the workspace patterns. Patterns are
+// slash-separated globs relative to root where "**" matches any number of
+// directories; a leading "!" excludes. node_modules and dot directories
+// are never searched.
+func workspacePackages(root string, patterns []string) ([]string, error) {
+	var include, exclude [][]string
+	for _, pattern := range patterns {
+		negated := strings.HasPrefix(pattern, "!")
+		segments := strings.Split(path.Clean(strings.TrimPrefix(strings.TrimPrefix(pattern, "!"), "./")), "/")
+		for _, seg := range segments {
+			if _, err := path.Match(seg, ""); err != nil {
+				return nil, fmt.Errorf("invalid workspace pattern %q: %w", pattern, err)
+			}
+		}
+		if negated {
+			exclude = append(exclude, segments)
+		} else {
+			include = append(include, segments)
+		}
+	}
+
+	found := make(map[string]struct{})
+	for _, segments := range include {
+		// Only walk below the pattern's literal prefix
+		prefix := 0
+		for prefix < len(segments) && !strings.ContainsAny(segments[prefix], "*?[") {
+			prefix++
+		}
+		base := filepath.Join(root, filepath.FromSlash(path.Join(segments[:prefix]...)))
+		err := filepath.WalkDir(base, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) && p == base {
+					return nil
+				}
+				return err
+			}
+			if !d.IsDir() {
+				return nil
+			}
+			if p != base && (d.Name() == "node_modules" || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+			relSegments := strings.Split(filepath.ToSlash(rel), "/")
+			if !matchSegments(segments, relSegments) {
+				return nil
+			}
+			for _, ex := range exclude {
+				if matchSegments(ex, relSegments) {
+					return nil
+				}
+			}
+			if _, err := os.Stat(filepath.Join(p, "package.json")); err == nil {
+				found[p] = struct{}{}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("scanning workspace packages: %w", err)
+		}
+	}
+
+	dirs := make([]string, 0, len(found))
+	for dir := range found {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
+
+// matchSegments matches path segments against pattern segments, where a
+// "**" pattern segment matches zero or more path segments.
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], segments[0])
+	return ok && matchSegments(pattern[1:], segments[1:])
+}
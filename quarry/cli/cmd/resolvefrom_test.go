@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeWorkspaceFiles creates files (relative path -> content) under root.
+func writeWorkspaceFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindWorkspaceRoot_Pnpm(t *testing.T) {
+	root := t.TempDir()
+	writeWorkspaceFiles(t, root, map[string]string{
+		"pnpm-workspace.yaml":                    "packages:\n  - 'packages/*'\n  - 'tools/**'\n  - '!packages/skip'\n",
+		"package.json":                           `{"name": "root"}`,
+		"packages/a/package.json":                `{"name": "a"}`,
+		"packages/b/package.json":                `{"name": "b"}`,
+		"packages/skip/package.json":             `{"name": "skip"}`,
+		"packages/no-manifest/README.md":         "",
+		"packages/a/node_modules/x/package.json": `{"name": "x"}`,
+		"tools/deep/lint/package.json":           `{"name": "lint"}`,
+		"scripts/package.json":                   `{"name": "scripts"}`,
+	})
+
+	found, patterns, err := findWorkspaceRoot(filepath.Join(root, "packages", "a"))
+	if err != nil {
+		t.Fatalf("findWorkspaceRoot: %v", err)
+	}
+	if found != root {
+		t.Errorf("root = %s, want %s", found, root)
+	}
+	dirs, err := workspacePackages(found, patterns)
+	if err != nil {
+		t.Fatalf("workspacePackages: %v", err)
+	}
+	want := []string{
+		filepath.Join(root, "packages", "a"),
+		filepath.Join(root, "packages", "b"),
+		filepath.Join(root, "tools", "deep", "lint"),
+	}
+	if !reflect.DeepEqual(dirs, want) {
+		t.Errorf("packages = %v, want %v", dirs, want)
+	}
+}
+
+func TestFindWorkspaceRoot_PackageJSONWorkspaces(t *testing.T) {
+	tests := []struct {
+		name, manifest string
+	}{
+		{"array", `{"workspaces": ["apps/*"]}`},
+		{"yarn object", `{"workspaces": {"packages": ["apps/*"], "nohoist": ["**/x"]}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			writeWorkspaceFiles(t, root, map[string]string{
+				"package.json":          tt.manifest,
+				"apps/web/package.json": `{"name": "web"}`,
+			})
+
+			found, patterns, err := findWorkspaceRoot(filepath.Join(root, "apps", "web"))
+			if err != nil {
+				t.Fatalf("findWorkspaceRoot: %v", err)
+			}
+			// apps/web/package.json has no workspaces, so the walk continues up
+			if found != root {
+				t.Errorf("root = %s, want %s", found, root)
+			}
+			dirs, err := workspacePackages(found, patterns)
+			if err != nil {
+				t.Fatalf("workspacePackages: %v", err)
+			}
+			if want := []string{filepath.Join(root, "apps", "web")}; !reflect.DeepEqual(dirs, want) {
+				t.Errorf("packages = %v, want %v", dirs, want)
+			}
+		})
+	}
+}
+
+func TestFindWorkspaceRoot_NotFound(t *testing.T) {
+	if _, _, err := findWorkspaceRoot(t.TempDir()); err == nil {
+		t.Fatal("expected error outside any workspace")
+	}
+}
+
+func TestRunAction_ResolveFromMultiplePaths(t *testing.T) {
+	dir := t.TempDir()
+	storageDir := filepath.Join(dir, "data")
+	first := filepath.Join(dir, "node_modules")
+	for _, d := range []string{storageDir, first} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	missing := filepath.Join(dir, "missing")
+
+	err := newTestApp().Run([]string{"quarry", "run",
+		"--script", "./test.ts",
+		"--run-id", "run-001",
+		"--source", "test",
+		"--storage-backend", "fs",
+		"--storage-path", storageDir,
+		"--resolve-from", first + "," + missing,
+	})
+	if err == nil || !strings.Contains(err.Error(), "directory does not exist: "+missing) {
+		t.Errorf("err = %v, want each comma-separated path validated", err)
+	}
+}
+
+func TestRunAction_ResolveWorkspaceNotFound(t *testing.T) {
+	dir := t.TempDir()
+	storageDir := filepath.Join(dir, "data")
+	if err := os.MkdirAll(storageDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := newTestApp().Run([]string{"quarry", "run",
+		"--script", "./test.ts",
+		"--run-id", "run-001",
+		"--source", "test",
+		"--storage-backend", "fs",
+		"--storage-path", storageDir,
+		"--resolve-workspace", dir,
+	})
+	if err == nil || !strings.Contains(err.Error(), "--resolve-workspace: no pnpm-workspace.yaml") {
+		t.Errorf("err = %v, want a missing-workspace error", err)
+	}
+}
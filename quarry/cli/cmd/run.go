@@ -5,24 +5,37 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
+	"net/url"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	lodelibrary "github.com/pithecene-io/lode/lode"
+	"github.com/santhosh-tekuri/jsonschema/v6"
 	"github.com/urfave/cli/v2"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
 
 	"github.com/pithecene-io/quarry/adapter"
+	fileadapter "github.com/pithecene-io/quarry/adapter/file"
+	kafkaadapter "github.com/pithecene-io/quarry/adapter/kafka"
 	redisadapter "github.com/pithecene-io/quarry/adapter/redis"
 	"github.com/pithecene-io/quarry/adapter/redisstream"
 	"github.com/pithecene-io/quarry/adapter/webhook"
 	quarryconfig "github.com/pithecene-io/quarry/cli/config"
+	"github.com/pithecene-io/quarry/clock"
 	"github.com/pithecene-io/quarry/executor"
 	"github.com/pithecene-io/quarry/iox"
+	"github.com/pithecene-io/quarry/ipc"
 	"github.com/pithecene-io/quarry/lode"
 	"github.com/pithecene-io/quarry/metrics"
 	"github.com/pithecene-io/quarry/policy"
@@ -51,7 +64,7 @@ func RunCommand() *cli.Command {
 		Name:  "run",
 		Usage: "Execute a script run (the only execution entrypoint)",
 		UsageText: `quarry run --script <path> --run-id <id> --source <name> \
-    --storage-backend <fs|s3> --storage-path <path> [options]
+    --storage-backend <fs|s3|memory> --storage-path <path> [options]
 
 EXAMPLES:
   # Run a script with filesystem storage
@@ -68,6 +81,10 @@ EXAMPLES:
     --storage-backend fs --storage-path ./data \
     --job-json ./jobs/crawl-config.json
 
+  # Smoke-test a script without storage (everything is discarded at exit)
+  quarry run --script ./script.ts --run-id smoke --source my-source \
+    --storage-backend memory
+
   # Run with S3 storage
   quarry run --script ./script.ts --run-id run-004 --source my-source \
     --storage-backend s3 --storage-path my-bucket/prefix \
@@ -79,9 +96,15 @@ EXAMPLES:
     --storage-endpoint https://ACCOUNT_ID.r2.cloudflarestorage.com \
     --storage-s3-path-style
 
+  # Run with S3 SSE-KMS encryption and infrequent-access storage
+  quarry run --script ./script.ts --run-id run-006 --source my-source \
+    --storage-backend s3 --storage-path my-bucket/prefix \
+    --storage-s3-sse aws:kms --storage-s3-kms-key alias/quarry \
+    --storage-s3-storage-class STANDARD_IA
+
 ADVANCED:
   # Override executor path (troubleshooting)
-  quarry run --script ./script.ts --run-id run-006 --source my-source \
+  quarry run --script ./script.ts --run-id run-007 --source my-source \
     --storage-backend fs --storage-path ./data \
     --executor /custom/path/to/executor.js`,
 		Flags: []cli.Flag{
@@ -92,14 +115,12 @@ ADVANCED:
 			},
 			// Execution flags
 			&cli.StringFlag{
-				Name:     "script",
-				Usage:    "Path to script file",
-				Required: true,
+				Name:  "script",
+				Usage: "Path to script file",
 			},
 			&cli.StringFlag{
-				Name:     "run-id",
-				Usage:    "Run ID",
-				Required: true,
+				Name:  "run-id",
+				Usage: "Run ID, or auto (derived from source, category, day, and job) or random (ULID)",
 			},
 			&cli.IntFlag{
 				Name:  "attempt",
@@ -114,6 +135,23 @@ ADVANCED:
 				Name:  "parent-run-id",
 				Usage: "Parent run ID (required for retries)",
 			},
+			&cli.IntFlag{
+				Name:  "max-attempts",
+				Usage: "Run up to N attempts in this invocation, retrying a retryable outcome as a new run (attempt+1, parent run ID set)",
+				Value: 1,
+			},
+			&cli.StringSliceFlag{
+				Name:  "retry-on",
+				Usage: "Outcome status retried by --max-attempts: executor_crash (default), script_error, policy_failure (repeatable)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "label",
+				Usage: "Run label as key=value, propagated to storage, adapter events, and metrics (repeatable)",
+			},
+			&cli.BoolFlag{
+				Name:  "overwrite",
+				Usage: "Write into a run partition that already holds a completed run",
+			},
 			&cli.StringFlag{
 				Name:  "job",
 				Usage: "Job payload as inline JSON object (mutually exclusive with --job-json)",
@@ -122,15 +160,102 @@ ADVANCED:
 				Name:  "job-json",
 				Usage: "Path to JSON file containing job payload object (mutually exclusive with --job)",
 			},
+			&cli.StringFlag{
+				Name:  "job-schema",
+				Usage: "Path to JSON Schema file; the job payload must conform before execution",
+			},
+			&cli.BoolFlag{
+				Name:  "since-checkpoint",
+				Usage: "Inject the final checkpoint payload of the latest prior run for this source/category into the job as resume_state",
+			},
+			&cli.StringFlag{
+				Name:  "seed-state",
+				Usage: "Path to JSON object file passed to the root run's script as ctx.seedState, separate from the job payload",
+			},
 			&cli.StringFlag{
 				Name:  "executor",
 				Usage: "Path to executor binary (advanced: auto-resolved by default)",
 			},
+			&cli.DurationFlag{
+				Name:  "stall-timeout",
+				Usage: "Kill the executor if no frame arrives within this duration, e.g. 15s (0 = disabled)",
+				Value: 0,
+			},
+			&cli.Int64Flag{
+				Name:  "max-event-bytes",
+				Usage: "Fail the run (stream error) on any single event whose payload exceeds this many bytes (0 = disabled)",
+			},
+			&cli.Int64Flag{
+				Name:  "max-run-bytes",
+				Usage: "Fail the run (policy_failure, quota exceeded) once its persisted event and artifact bytes exceed this cap (0 = unlimited)",
+			},
+			&cli.StringFlag{
+				Name:  "log-min-level",
+				Usage: "Drop log events below this payload.level before the policy: debug, info, warn, or error (default: keep all)",
+			},
+			&cli.Float64Flag{
+				Name:  "sample-rate",
+				Usage: "Persist only this fraction (0 < r <= 1) of item events, chosen deterministically by event_id (counted in events_sampled_out_total; unset = keep all)",
+			},
+			&cli.StringFlag{
+				Name:  "events-filter",
+				Usage: "Persist only events matching this expression, e.g. 'type == \"item\" && payload.item_type == \"product\"' (artifacts, checkpoints and terminals are always kept; counted in events_filtered_total)",
+			},
+			&cli.BoolFlag{
+				Name:  "telemetry-mode",
+				Usage: "Log-only run: skip seq and terminal-event enforcement, treat a clean exit as success, and reject any non-log event",
+			},
+			&cli.StringFlag{
+				Name:  "pre-run-hook",
+				Usage: "Shell command run before the executor with job and run metadata as JSON on stdin; nonzero exit vetoes the run",
+			},
+			&cli.DurationFlag{
+				Name:  "pre-run-hook-timeout",
+				Usage: "Timeout for --pre-run-hook, independent of the run (expiry vetoes the run)",
+				Value: runtime.DefaultPreRunHookTimeout,
+			},
+			&cli.StringFlag{
+				Name:  "on-complete",
+				Usage: "Shell command run as the last step after a successful run, with the run manifest as JSON on stdin; nonzero exit is a warning",
+			},
+			&cli.DurationFlag{
+				Name:  "on-complete-timeout",
+				Usage: "Timeout for --on-complete (expiry counts as a hook failure)",
+				Value: runtime.DefaultOnCompleteHookTimeout,
+			},
+			&cli.BoolFlag{
+				Name:  "on-complete-required",
+				Usage: "Fail the process with the policy_failure exit code when the --on-complete hook fails",
+			},
+			&cli.DurationFlag{
+				Name:  "shutdown-grace",
+				Usage: "On SIGINT/SIGTERM, drain and flush for up to this duration before canceling, e.g. 10s (0 = cancel immediately)",
+				Value: 0,
+			},
+			&cli.IntFlag{
+				Name:  "admission-slots",
+				Usage: "Wait for one of N host-wide slots (shared through --admission-lock) before launching the executor (0 = disabled)",
+				Value: 0,
+			},
+			&cli.StringFlag{
+				Name:  "admission-lock",
+				Usage: "Lock path shared by the quarry processes that split --admission-slots (default: quarry-admission.lock in the temp dir)",
+			},
+			&cli.DurationFlag{
+				Name:  "admission-timeout",
+				Usage: "Fail if no admission slot frees up within this duration (0 = wait until a slot frees or the run is interrupted)",
+				Value: 0,
+			},
 			&cli.StringFlag{
 				Name:    "browser-ws-endpoint",
 				Usage:   "WebSocket URL of an externally managed browser (connect instead of launch)",
 				EnvVars: []string{"QUARRY_BROWSER_ENDPOINT"},
 			},
+			&cli.DurationFlag{
+				Name:  "browser-launch-timeout",
+				Usage: "Fail with reason browser_launch if a launched browser does not report its endpoint within this duration",
+				Value: runtime.DefaultBrowserLaunchTimeout,
+			},
 			&cli.BoolFlag{
 				Name:  "quiet",
 				Usage: "Suppress result output",
@@ -139,10 +264,50 @@ ADVANCED:
 				Name:  "dry-run",
 				Usage: "Validate script loadability without executing a run (no browser, no storage)",
 			},
+			&cli.BoolFlag{
+				Name:  "explain",
+				Usage: "Print each resolved setting with its source (flag, config, default) before running",
+			},
 			&cli.StringFlag{
 				Name:  "report",
 				Usage: "Write structured JSON report to path on exit (use - for stderr)",
 			},
+			&cli.IntFlag{
+				Name:  "exit-code-success",
+				Usage: "Process exit code for a success outcome (0-255)",
+				Value: exitSuccess,
+			},
+			&cli.IntFlag{
+				Name:  "exit-code-script-error",
+				Usage: "Process exit code for a script_error outcome (0-255)",
+				Value: exitScriptError,
+			},
+			&cli.IntFlag{
+				Name:  "exit-code-executor-crash",
+				Usage: "Process exit code for an executor_crash outcome (0-255)",
+				Value: exitExecutorCrash,
+			},
+			&cli.IntFlag{
+				Name:  "exit-code-policy-failure",
+				Usage: "Process exit code for a policy_failure or version_mismatch outcome (0-255)",
+				Value: exitPolicyFailure,
+			},
+			&cli.StringFlag{
+				Name:  "output-manifest",
+				Usage: "Write a machine-readable JSON run manifest (report plus lineage, storage path, and fan-out children) to path at finalization",
+			},
+			&cli.StringFlag{
+				Name:  "replay",
+				Usage: "Rerun a prior run from its --output-manifest with a new run ID, reporting any setting that cannot be reproduced",
+			},
+			&cli.StringFlag{
+				Name:  "metrics-addr",
+				Usage: "Serve live /metrics (Prometheus) and /healthz on this address during the run, e.g. :9900",
+			},
+			&cli.StringFlag{
+				Name:  "egress-proxy",
+				Usage: "HTTP(S) proxy for quarry's own S3 and webhook traffic, e.g. http://corp:3128 (default: HTTPS_PROXY/HTTP_PROXY env); not used for scraping",
+			},
 			// Partition key flags
 			&cli.StringFlag{
 				Name:  "source",
@@ -164,6 +329,10 @@ ADVANCED:
 				Usage: "Flush mode for buffered policy: at_least_once, chunks_first, two_phase",
 				Value: "at_least_once",
 			},
+			&cli.BoolFlag{
+				Name:  "parallel-flush",
+				Usage: "Write chunks and independent events concurrently during flush (buffered policy, two_phase only)",
+			},
 			&cli.IntFlag{
 				Name:  "buffer-events",
 				Usage: "Max buffered events (buffered policy)",
@@ -184,6 +353,105 @@ ADVANCED:
 				Usage: "Flush every duration, e.g. 5s, 30s (streaming policy)",
 				Value: 0,
 			},
+			&cli.DurationFlag{
+				Name:  "flush-idle",
+				Usage: "Flush once no events have arrived for this duration, e.g. 2s (streaming policy)",
+			},
+			&cli.BoolFlag{
+				Name:  "flush-seq",
+				Usage: "Tag each flush's event snapshot with a gap-free flush_seq and write a _complete marker on the final flush (streaming policy)",
+			},
+			&cli.IntFlag{
+				Name:  "events-batch-size",
+				Usage: "Write events in batches of up to N, each durably written before the next (strict policy)",
+				Value: 0,
+			},
+			&cli.DurationFlag{
+				Name:  "strict-batch-window",
+				Usage: "Write a pending event batch once its oldest event is this old, e.g. 100ms (strict policy)",
+				Value: 0,
+			},
+			&cli.IntFlag{
+				Name:  "strict-degrade-buffer",
+				Usage: "On a retryable sink error, buffer up to N unwritten events and retry with backoff instead of failing (strict policy; 0 = fail fast)",
+			},
+			&cli.BoolFlag{
+				Name:  "fail-on-drops",
+				Usage: "Fail the run with policy_failure if any events were dropped",
+			},
+			&cli.BoolFlag{
+				Name:  "allow-seq-gaps",
+				Usage: "Tolerate forward seq jumps (logged, counted in seq_gaps_total); buffered/streaming only",
+			},
+			&cli.StringSliceFlag{
+				Name:  "redact",
+				Usage: "Payload keys to replace with [REDACTED] before persistence (comma-separated or repeatable; dotted paths match from the root)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "allow-domain",
+				Usage: "Only allow job and enqueue URLs on these domains and their subdomains (comma-separated or repeatable)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "deny-domain",
+				Usage: "Block job and enqueue URLs on these domains and their subdomains; fails the run with policy_failure (comma-separated or repeatable)",
+			},
+			&cli.BoolFlag{
+				Name:  "events-only",
+				Usage: "Persist events only; discard artifact chunks and commits (counted in artifacts_discarded_total)",
+			},
+			&cli.BoolFlag{
+				Name:  "artifacts-only",
+				Usage: "Persist artifacts and terminal events only; discard other events (counted in events_discarded_total)",
+			},
+			&cli.BoolFlag{
+				Name:  "persist-stderr",
+				Usage: "Write executor stderr to the run partition as _stderr.log for every run (default: failed runs only)",
+			},
+			&cli.IntFlag{
+				Name:  "stderr-tail",
+				Usage: "Keep only the last N lines of executor stderr for the run summary and report (the _stderr.log sidecar keeps up to 1 MiB)",
+				Value: runtime.DefaultStderrTailLines,
+			},
+			&cli.BoolFlag{
+				Name:  "verify-artifacts",
+				Usage: "Require and verify per-chunk CRC32C and per-artifact sha256 checksums (mismatch fails the run)",
+			},
+			&cli.BoolFlag{
+				Name:  "sniff-content-type",
+				Usage: "Detect an artifact's content type from its first chunk when the declared type is empty or application/octet-stream",
+			},
+			&cli.Int64Flag{
+				Name:  "artifact-spill-threshold",
+				Usage: "Move an artifact's reassembly buffer to a temp file once it exceeds this many bytes (0 = always in memory)",
+			},
+			&cli.Int64Flag{
+				Name:  "max-frame-bytes",
+				Usage: "Override the IPC frame size limit for trusted executors; each frame is buffered whole (0 = 16 MiB, max 256 MiB)",
+			},
+			&cli.IntFlag{
+				Name:  "max-decode-depth",
+				Usage: "Maximum map/array nesting depth of an IPC frame; deeper frames fail to decode (0 = 128, max 4096)",
+			},
+			&cli.IntFlag{
+				Name:  "max-decode-container-len",
+				Usage: "Maximum entries in any single map or array of an IPC frame; longer frames fail to decode (0 = 1048576)",
+			},
+			&cli.StringFlag{
+				Name:  "dump-ipc",
+				Usage: "Capture the raw executor stdout frame stream of the root run to this file (best effort, for debugging)",
+			},
+			&cli.Int64Flag{
+				Name:  "dump-ipc-max-bytes",
+				Usage: "Cap the --dump-ipc capture; later bytes are dropped with a warning (0 = 256 MiB)",
+			},
+			&cli.StringFlag{
+				Name:  "profile",
+				Usage: "Write a pprof profile of the quarry process (not the executor) over the run: cpu or heap (requires --profile-out)",
+			},
+			&cli.StringFlag{
+				Name:  "profile-out",
+				Usage: "Output file for --profile",
+			},
 			// Proxy flags
 			&cli.StringFlag{
 				Name:  "proxy-config",
@@ -195,7 +463,7 @@ ADVANCED:
 			},
 			&cli.StringFlag{
 				Name:  "proxy-strategy",
-				Usage: "Strategy override: round_robin, random, or sticky",
+				Usage: "Strategy override: round_robin, weighted_round_robin, random, or sticky",
 			},
 			&cli.StringFlag{
 				Name:  "proxy-sticky-key",
@@ -209,6 +477,19 @@ ADVANCED:
 				Name:  "proxy-origin",
 				Usage: "Origin for sticky scope derivation (when scope=origin, format: scheme://host:port)",
 			},
+			&cli.BoolFlag{
+				Name:  "proxy-health-check",
+				Usage: "TCP-probe the selected proxy before the run; skip unreachable endpoints in pool order",
+			},
+			&cli.DurationFlag{
+				Name:  "proxy-health-timeout",
+				Usage: "Per-endpoint timeout for --proxy-health-check",
+				Value: proxy.DefaultHealthTimeout,
+			},
+			&cli.BoolFlag{
+				Name:  "proxy-rotate-on-block",
+				Usage: "Answer rotate_proxy events with a fresh endpoint from the pool, sent to the executor mid-run",
+			},
 			// Storage flags
 			&cli.StringFlag{
 				Name:  "storage-dataset",
@@ -217,11 +498,11 @@ ADVANCED:
 			},
 			&cli.StringFlag{
 				Name:  "storage-backend",
-				Usage: "Storage backend: fs (filesystem) or s3 (Amazon S3)",
+				Usage: "Storage backend: fs (filesystem), s3 (Amazon S3), or memory (in-process, discarded at exit)",
 			},
 			&cli.StringFlag{
 				Name:  "storage-path",
-				Usage: "Storage path (fs: writable directory, s3: bucket/prefix)",
+				Usage: "Storage path (fs: writable directory, s3: bucket/prefix, memory: not used)",
 			},
 			&cli.StringFlag{
 				Name:  "storage-region",
@@ -235,15 +516,73 @@ ADVANCED:
 				Name:  "storage-s3-path-style",
 				Usage: "Force path-style addressing for S3 (required by R2, MinIO)",
 			},
+			&cli.StringFlag{
+				Name:  "storage-s3-sse",
+				Usage: "S3 server-side encryption for every write: AES256, aws:kms, or aws:kms:dsse (default: bucket setting)",
+			},
+			&cli.StringFlag{
+				Name:  "storage-s3-kms-key",
+				Usage: "KMS key ID or ARN for --storage-s3-sse aws:kms / aws:kms:dsse (default: AWS-managed key)",
+			},
+			&cli.StringFlag{
+				Name:  "storage-s3-storage-class",
+				Usage: "S3 storage class for every write, e.g. STANDARD_IA, GLACIER_IR (default: STANDARD)",
+			},
+			&cli.BoolFlag{
+				Name:  "storage-s3-skip-existing",
+				Usage: "HEAD before each S3 sidecar object write and skip objects that already exist with the same size and digest (counted in lode_writes_skipped_total)",
+			},
+			&cli.StringFlag{
+				Name:  "storage-day",
+				Usage: "Partition day as YYYY-MM-DD, overriding the run start date (for backfills)",
+			},
+			&cli.StringFlag{
+				Name:  "storage-prefix-template",
+				Usage: "Partition layout as key={{.Field}} segments (fields: Source, Category, Day, RunID, Year, Month; must include RunID)",
+			},
+			&cli.StringFlag{
+				Name:  "partition-granularity",
+				Usage: "Partition time granularity: day or hour (adds an hour=HH segment after day=, from the same run start time)",
+				Value: "day",
+			},
+			&cli.StringFlag{
+				Name:  "tenant",
+				Usage: "Tenant ID prepended to the partition path as tenant=<id> (validated against the config tenant_pattern allowlist)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "storage-sink",
+				Usage: "Extra storage sink receiving the same events and chunks, as <backend>:<path>, e.g. fs:/var/cache/quarry (repeatable; s3 sinks share the --storage-* S3 options)",
+			},
+			&cli.IntFlag{
+				Name:  "storage-sink-quorum",
+				Usage: "Storage sinks (primary included) that must accept each write with --storage-sink: 0 = all (default), 1 = best-effort",
+			},
+			&cli.BoolFlag{
+				Name:  "partition-manifest",
+				Usage: "Write files/_manifest.json listing every object the run wrote (size, sha256) plus the outcome; check it with 'quarry verify'",
+			},
+			&cli.StringFlag{
+				Name:  "storage-format",
+				Usage: "Encoding for item event records: jsonl (default) or parquet (flattened envelope columns, payload as a JSON column; other records stay JSON Lines)",
+			},
 			// Browser reuse flags
 			&cli.BoolFlag{
 				Name:  "no-browser-reuse",
 				Usage: "Disable transparent browser reuse across runs",
 			},
 			// Module resolution flags
-			&cli.StringFlag{
+			&cli.StringSliceFlag{
 				Name:  "resolve-from",
-				Usage: "Path to node_modules directory for bare-specifier ESM resolution fallback (monorepo/container support)",
+				Usage: "Directory for bare-specifier ESM resolution fallback, tried in order (repeatable or comma-separated; monorepo/container support)",
+			},
+			&cli.StringFlag{
+				Name:  "resolve-workspace",
+				Usage: "Detect the pnpm/yarn workspace containing this path and add its root and local packages to --resolve-from",
+			},
+			// Executor passthrough flags
+			&cli.StringSliceFlag{
+				Name:  "executor-arg",
+				Usage: "Extra argument appended to the executor's argv after quarry's own (repeatable; not interpreted by quarry)",
 			},
 			// Fan-out flags
 			&cli.IntFlag{
@@ -255,19 +594,65 @@ ADVANCED:
 				Name:  "max-runs",
 				Usage: "Maximum total child runs (required when --depth > 0)",
 			},
-			&cli.IntFlag{
+			&cli.StringFlag{
 				Name:  "parallel",
-				Usage: "Maximum concurrent child runs",
-				Value: 1,
+				Usage: "Maximum concurrent child runs, or auto to size from the container CPU limit (GOMAXPROCS when unset)",
+				Value: "1",
+			},
+			&cli.IntFlag{
+				Name:  "parallel-max",
+				Usage: "Upper bound for --parallel auto",
+				Value: defaultParallelMax,
+			},
+			&cli.Int64Flag{
+				Name:  "max-bytes-per-child",
+				Usage: "Fail a child run that writes more artifact bytes than this (0 = unlimited)",
+			},
+			&cli.IntFlag{
+				Name:  "max-artifacts-per-child",
+				Usage: "Fail a child run that commits more artifacts than this (0 = unlimited)",
+			},
+			&cli.StringFlag{
+				Name:  "dedupe-enqueues",
+				Usage: "Enqueue dedup identity: exact (target+params), target, or param:<field> (default: exact)",
+			},
+			&cli.IntFlag{
+				Name:  "dedupe-capacity",
+				Usage: "Bound the enqueue dedup set, evicting the oldest keys when full (0 = unbounded)",
+			},
+			&cli.IntFlag{
+				Name:  "retry-per-item",
+				Usage: "Re-dispatch a child that crashes (executor_crash) up to N times, each with a freshly selected proxy (0 = no retries)",
+			},
+			&cli.IntFlag{
+				Name:  "per-origin-concurrency",
+				Usage: "Maximum concurrent child runs per origin (scheme+host+port of params.url), independent of --parallel (0 = unlimited)",
+			},
+			&cli.DurationFlag{
+				Name:  "origin-stagger",
+				Usage: "Space child starts against the same origin by a random gap of up to this duration, e.g. 500ms (0 = no stagger)",
+			},
+			&cli.StringFlag{
+				Name:  "fanout-on-error",
+				Usage: "On a child failure: continue (default) keeps the fan-out going; abort cancels in-flight and pending children and the root",
+				Value: fanOutOnErrorContinue,
+			},
+			&cli.StringFlag{
+				Name:  "warmup-script",
+				Usage: "Script run once on the shared browser before fan-out; its final checkpoint is injected into every job as shared_state",
+			},
+			&cli.StringFlag{
+				Name:  "input-urls",
+				Usage: "Seed fan-out from a URL list instead of running --script as the discovery root: one URL per line, or one params object per line for .jsonl (requires --depth > 0)",
 			},
 			// Adapter flags (event-bus notification)
 			&cli.StringFlag{
 				Name:  "adapter",
-				Usage: "Event-bus adapter type (webhook, redis)",
+				Usage: "Event-bus adapter type (webhook, redis, kafka, file)",
 			},
 			&cli.StringFlag{
 				Name:  "adapter-url",
-				Usage: "Adapter endpoint URL (required when --adapter is set)",
+				Usage: "Adapter endpoint URL (required when --adapter is set; kafka: comma-separated brokers; file: outbox path)",
 			},
 			&cli.StringSliceFlag{
 				Name:  "adapter-header",
@@ -283,9 +668,67 @@ ADVANCED:
 				Usage: "Adapter retry attempts",
 				Value: webhook.DefaultRetries,
 			},
+			&cli.IntFlag{
+				Name:  "adapter-error-max-len",
+				Usage: "Max bytes of error_message / error_stack in the run_completed event",
+				Value: adapter.DefaultErrorMaxLen,
+			},
 			&cli.StringFlag{
 				Name:  "adapter-channel",
-				Usage: "Pub/sub channel name for Redis adapter (default: quarry:run_completed)",
+				Usage: "Redis pub/sub channel (default: quarry:run_completed) or Kafka topic (default: quarry.run_completed)",
+			},
+			&cli.StringFlag{
+				Name:  "adapter-webhook-client-cert",
+				Usage: "PEM client certificate presented to the webhook for mTLS (requires --adapter-webhook-client-key)",
+			},
+			&cli.StringFlag{
+				Name:  "adapter-webhook-client-key",
+				Usage: "PEM private key for --adapter-webhook-client-cert",
+			},
+			&cli.StringFlag{
+				Name:  "adapter-webhook-ca",
+				Usage: "PEM CA bundle for verifying the webhook server certificate (default: system roots)",
+			},
+			&cli.StringFlag{
+				Name:  "adapter-webhook-compress",
+				Usage: "Compress the webhook body with this Content-Encoding: gzip or zstd (default: uncompressed)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "adapter-webhook-retry-status",
+				Usage: "HTTP statuses the webhook retries, as codes or classes, e.g. 409,5xx (repeatable or comma-separated; default: 429,5xx; others fail fast)",
+			},
+			&cli.StringFlag{
+				Name:  "adapter-template",
+				Usage: "Go text/template rendering the run_completed event into the published JSON body (data keys are the event's JSON names)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "adapter-field",
+				Usage: "Publish a flat JSON body mapping output=event_field, e.g. id=run_id (repeatable; mutually exclusive with --adapter-template)",
+			},
+			&cli.Int64Flag{
+				Name:  "adapter-file-max-bytes",
+				Usage: "Rotate the file adapter outbox before it grows past this many bytes (-1 = never rotate)",
+				Value: fileadapter.DefaultMaxBytes,
+			},
+			&cli.BoolFlag{
+				Name:  "adapter-redis-pipeline",
+				Usage: "Pipeline concurrent redis adapter publishes (fan-out children) into one round trip",
+			},
+			&cli.BoolFlag{
+				Name:  "adapter-on-artifact",
+				Usage: "Also publish an artifact_committed event per artifact as soon as it is committed",
+			},
+			&cli.IntFlag{
+				Name:  "adapter-artifact-rate",
+				Usage: "Max artifact_committed events per second; excess events are dropped (0 = unlimited)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "adapter-on",
+				Usage: "Publish run_completed only for these outcomes: success, failure, always, or outcome statuses (repeatable or comma-separated; default: always)",
+			},
+			&cli.BoolFlag{
+				Name:  "notify-early",
+				Usage: "Publish run_completed as soon as the outcome is known, before metrics persistence (the event carries metrics_pending: true)",
 			},
 			// Event sink flags
 			&cli.StringSliceFlag{
@@ -340,20 +783,30 @@ ADVANCED:
 type policyChoice struct {
 	name          string
 	flushMode     string
+	parallelFlush bool
 	maxEvents     int
 	maxBytes      int64
 	flushCount    int
 	flushInterval time.Duration
+	flushIdle     time.Duration // idle flush trigger (streaming only)
+	flushSeq      bool          // flush_seq snapshot tagging (streaming only)
+	batchSize     int           // strict micro-batch size (0: unbatched)
+	batchWindow   time.Duration // strict micro-batch window (0: unbatched)
+	degradeBuffer int           // strict degraded-mode event buffer (0: fail fast)
+	clock         clock.Clock   // drives streaming flush timers (nil: real)
 }
 
 // proxyChoice holds parsed proxy configuration.
 type proxyChoice struct {
-	configPath string
-	poolName   string
-	strategy   string
-	stickyKey  string
-	domain     string
-	origin     string
+	configPath    string
+	poolName      string
+	strategy      string
+	stickyKey     string
+	domain        string
+	origin        string
+	healthCheck   bool
+	healthTimeout time.Duration
+	rotateOnBlock bool
 }
 
 // storageChoice holds parsed storage configuration.
@@ -363,16 +816,103 @@ type storageChoice struct {
 	region       string // AWS region for S3 (optional)
 	endpoint     string // custom S3 endpoint for S3-compatible providers (optional)
 	usePathStyle bool   // force path-style addressing for S3 (optional)
+	sse          string // S3 server-side encryption (optional)
+	kmsKeyID     string // KMS key for SSE-KMS (optional)
+	storageClass string // S3 storage class (optional)
+	skipExisting bool   // skip S3 writes of objects that already exist unchanged (optional)
+	// partitionTemplate overrides the Hive partition layout (nil: default layout)
+	partitionTemplate *lode.PartitionTemplate
+	// tenant prefixes the partition layout with tenant=<id> (empty: no prefix)
+	tenant string
+	// day overrides the partition day (--storage-day; empty: derive from start time)
+	day string
+	// hourly adds an hour=HH partition after day= (--partition-granularity hour)
+	hourly bool
+	// partitionManifest writes files/_manifest.json at finalization
+	partitionManifest bool
+	// format is the item event record encoding (--storage-format)
+	format lode.StorageFormat
+	// egressProxy routes S3 traffic through a proxy (nil: environment)
+	egressProxy *url.URL
+	// sinks are extra storage sinks teed with the primary (--storage-sink)
+	sinks []storageSinkSpec
+	// sinkQuorum is the number of tee sinks a write needs (0: all)
+	sinkQuorum int
+}
+
+// partitionDay returns the partition day for a run started at startTime:
+// the --storage-day override when set, else the UTC day of startTime.
+// Every consumer of the day (storage sink, executor StorageDay, adapter,
+// manifest) must go through here so they stay aligned.
+func (s storageChoice) partitionDay(startTime time.Time) string {
+	if s.day != "" {
+		return s.day
+	}
+	return lode.DeriveDay(startTime)
+}
+
+// partitionHour returns the partition hour for a run started at startTime,
+// or "" for daily partitions. Derived from the same instant as
+// partitionDay, so the hour always falls within the partition day.
+func (s storageChoice) partitionHour(startTime time.Time) string {
+	if !s.hourly {
+		return ""
+	}
+	return lode.DeriveHour(startTime)
+}
+
+// applyPartitionGranularity validates --partition-granularity and, for
+// hour, inserts the hour segment into the partition layout.
+func applyPartitionGranularity(config *storageChoice, granularity string) error {
+	switch granularity {
+	case "", "day":
+		return nil
+	case "hour":
+	default:
+		return fmt.Errorf("invalid --partition-granularity %q: must be day or hour", granularity)
+	}
+	if config.day != "" {
+		return errors.New("--partition-granularity hour cannot be combined with --storage-day: the hour derives from the run start time")
+	}
+	pt := config.partitionTemplate
+	if pt == nil {
+		var err error
+		if pt, err = lode.ParsePartitionTemplate(lode.DefaultPartitionTemplate); err != nil {
+			return err
+		}
+	}
+	hourly, err := pt.WithHour()
+	if err != nil {
+		return fmt.Errorf("invalid --partition-granularity: %w", err)
+	}
+	config.partitionTemplate = hourly
+	config.hourly = true
+	return nil
 }
 
 // adapterChoice holds parsed adapter configuration.
 type adapterChoice struct {
-	adapterType string
-	url         string
-	channel     string
-	headers     map[string]string
-	timeout     time.Duration
-	retries     int
+	adapterType  string
+	url          string
+	channel      string
+	headers      map[string]string
+	timeout      time.Duration
+	retries      int
+	errorMaxLen  int                              // byte bound for error_message / error_stack
+	kafka        *quarryconfig.KafkaAdapterConfig // SASL/TLS settings (kafka only)
+	clientCert   string                           // mTLS client certificate (webhook only)
+	clientKey    string                           // mTLS client key (webhook only)
+	caFile       string                           // server CA bundle (webhook only)
+	compress     string                           // body Content-Encoding (webhook only)
+	retryStatus  []string                         // retried HTTP statuses (webhook only; nil = default)
+	fileMaxBytes int64                            // outbox rotation size (file only)
+	pipeline     bool                             // batch concurrent publishes (redis only)
+	encoder      adapter.Encoder                  // payload template or field map (nil = canonical JSON)
+	egressProxy  *url.URL                         // proxy for outbound HTTP (webhook only; nil = environment)
+	onArtifact   bool                             // publish artifact_committed per artifact
+	artifactRate int                              // artifact_committed events per second (0 = unlimited)
+	on           map[types.OutcomeStatus]bool     // outcomes that publish run_completed (nil = always)
+	notifyEarly  bool                             // publish run_completed before metrics persistence
 }
 
 // eventSinkChoice holds parsed event sink configuration.
@@ -387,26 +927,81 @@ type eventSinkChoice struct {
 	retries   int           // redis only
 }
 
+// --fanout-on-error modes.
+const (
+	fanOutOnErrorContinue = "continue"
+	fanOutOnErrorAbort    = "abort"
+)
+
 // fanOutChoice holds parsed fan-out configuration.
 type fanOutChoice struct {
-	depth    int
-	maxRuns  int
-	parallel int
+	depth                int
+	maxRuns              int
+	parallel             int
+	parallelAuto         bool // --parallel auto; resolved by validateFanOutConfig
+	parallelMax          int  // clamp for parallelAuto
+	maxBytesPerChild     int64
+	maxArtifactsPerChild int
+	dedupeBy             string
+	dedupeCapacity       int
+	retryPerItem         int
+	perOriginConcurrency int
+	originStagger        time.Duration
+	onError              string // --fanout-on-error: continue or abort
+	warmupScript         string
+	inputURLs            string // --input-urls seed list; replaces the root run
 }
 
-func validateFanOutConfig(choice fanOutChoice) error {
+// validateFanOutConfig checks fan-out settings and resolves --parallel auto
+// to a concrete worker count in choice.parallel.
+func validateFanOutConfig(choice *fanOutChoice) error {
 	if choice.depth < 0 {
 		return fmt.Errorf("--depth must be >= 0, got %d", choice.depth)
 	}
+	if choice.inputURLs != "" && choice.depth == 0 {
+		return errors.New("--input-urls requires --depth > 0 (seeded items run as depth-1 children)")
+	}
 	if choice.depth > 0 && choice.maxRuns == 0 {
 		return errors.New("--max-runs is required when --depth > 0 (safety rail to prevent unbounded fan-out)")
 	}
 	if choice.maxRuns < 0 {
 		return fmt.Errorf("--max-runs must be >= 0, got %d", choice.maxRuns)
 	}
+	if choice.parallelAuto {
+		if choice.parallelMax < 1 {
+			return fmt.Errorf("--parallel-max must be >= 1, got %d", choice.parallelMax)
+		}
+		choice.parallel = autoParallel(choice.parallelMax)
+	}
 	if choice.parallel < 1 {
 		return fmt.Errorf("--parallel must be >= 1, got %d", choice.parallel)
 	}
+	if choice.maxBytesPerChild < 0 {
+		return fmt.Errorf("--max-bytes-per-child must be >= 0, got %d", choice.maxBytesPerChild)
+	}
+	if choice.maxArtifactsPerChild < 0 {
+		return fmt.Errorf("--max-artifacts-per-child must be >= 0, got %d", choice.maxArtifactsPerChild)
+	}
+	if _, err := runtime.ParseDedupeBy(choice.dedupeBy); err != nil {
+		return fmt.Errorf("--dedupe-enqueues: %w", err)
+	}
+	if choice.dedupeCapacity < 0 {
+		return fmt.Errorf("--dedupe-capacity must be >= 0, got %d", choice.dedupeCapacity)
+	}
+	if choice.retryPerItem < 0 {
+		return fmt.Errorf("--retry-per-item must be >= 0, got %d", choice.retryPerItem)
+	}
+	if choice.perOriginConcurrency < 0 {
+		return fmt.Errorf("--per-origin-concurrency must be >= 0, got %d", choice.perOriginConcurrency)
+	}
+	if choice.originStagger < 0 {
+		return fmt.Errorf("--origin-stagger must be >= 0, got %s", choice.originStagger)
+	}
+	switch choice.onError {
+	case "", fanOutOnErrorContinue, fanOutOnErrorAbort:
+	default:
+		return fmt.Errorf("invalid --fanout-on-error %q (supported: continue, abort)", choice.onError)
+	}
 	return nil
 }
 
@@ -415,26 +1010,75 @@ func validateFanOutConfig(choice fanOutChoice) error {
 // policy, sink, and metrics collector for the child.
 type childFactory struct {
 	policyChoice      policyChoice
+	clock             clock.Clock
 	executorPath      string
 	storage           storageChoice
 	storageDataset    string
 	source            string
 	category          string
 	proxy             *types.ProxyEndpoint
+	proxySelection    *proxySelection
+	proxyRotator      runtime.ProxyRotator
 	browserWSEndpoint string
-	resolveFrom       string
+	resolveFrom       []string
+	executorArgs      []string
 	eventSinks        []eventSinkChoice
+	failOnDrops       bool
+	allowSeqGaps      bool
+	stallTimeout      time.Duration
+	maxEventBytes     int64
+	logMinLevel       types.LogLevel
+	sampleRate        float64
+	eventFilter       *runtime.EventFilter
+	redactor          *runtime.Redactor
+	domainPolicy      *runtime.DomainPolicy
+	ingestMode        runtime.IngestMode
+	drain             <-chan struct{}
+	verifyArtifacts   bool
+	sniffContentType  bool
+	persistStderr     bool
+	stderrTail        int
+	spillThreshold    int64
+	maxFrameBytes     int64
+	decodeLimits      ipc.DecodeLimits
+	metricsServer     *metrics.Server
+	preRunHook        *runtime.PreRunHook
+	labels            map[string]string
+	sharedState       map[string]any // --warmup-script checkpoint, may be nil
+	adapter           *sharedAdapter
+	artifacts         *artifactPublisher // --adapter-on-artifact, may be nil
 }
 
 // Run constructs and executes a single child run for the fan-out operator.
 func (cf *childFactory) Run(ctx context.Context, item runtime.WorkItem, observer runtime.EnqueueObserver) (*runtime.RunResult, error) {
+	// Do not start new children once a graceful shutdown has begun
+	select {
+	case <-cf.drain:
+		return nil, fmt.Errorf("child %s not started: %w", item.RunID, runtime.ErrDrained)
+	default:
+	}
+
 	childMeta := &types.RunMeta{
 		RunID:   item.RunID,
-		Attempt: 1,
+		Attempt: max(item.Attempt, 1),
+		Labels:  cf.labels,
+	}
+	if item.ParentRunID != "" {
+		childMeta.ParentRunID = &item.ParentRunID
 	}
 
-	childSource := cf.source
-	if item.Source != "" {
+	// Retries draw a fresh endpoint rather than reusing the one that failed
+	childProxy := cf.proxy
+	if item.Attempt > 1 && cf.proxySelection != nil {
+		endpoint, err := cf.proxySelection.reselect(item.PreviousProxy)
+		if err != nil {
+			return nil, fmt.Errorf("proxy reselection for retry %s failed: %w", item.RunID, err)
+		}
+		childProxy = endpoint
+	}
+
+	childSource := cf.source
+	if item.Source != "" {
 		childSource = item.Source
 	}
 	childCategory := cf.category
@@ -449,8 +1093,12 @@ func (cf *childFactory) Run(ctx context.Context, item runtime.WorkItem, observer
 		item.RunID,
 		"",
 	)
+	childCollector.SetLabels(cf.labels)
+	cf.metricsServer.Register(childCollector)
+	defer cf.metricsServer.Retire(childCollector)
 
-	childStartTime := time.Now()
+	childStartTime := cf.clock.Now()
+	childArtifacts := cf.artifacts.forRun(childMeta, childSource, childCategory, cf.storage.partitionDay(childStartTime), cf.storage.partitionHour(childStartTime))
 	childPol, childLodeClient, childFileWriter, err := buildPolicy(
 		cf.policyChoice, cf.storage, cf.storageDataset,
 		childSource, childCategory, item.RunID,
@@ -462,21 +1110,46 @@ func (cf *childFactory) Run(ctx context.Context, item runtime.WorkItem, observer
 	defer iox.DiscardClose(childPol)
 
 	config := &runtime.RunConfig{
-		ExecutorPath:      cf.executorPath,
-		ScriptPath:        item.Target,
-		Job:               item.Params,
-		RunMeta:           childMeta,
-		Policy:            childPol,
-		Proxy:             cf.proxy,
-		FileWriter:        childFileWriter,
-		EnqueueObserver:   observer,
-		BrowserWSEndpoint: cf.browserWSEndpoint,
-		ResolveFrom:       cf.resolveFrom,
-		Source:            childSource,
-		Category:          childCategory,
-		StorageDataset:    cf.storageDataset,
-		StorageDay:        lode.DeriveDay(childStartTime),
-		Collector:         childCollector,
+		ExecutorPath:           cf.executorPath,
+		ScriptPath:             item.Target,
+		Job:                    withSharedState(item.Params, cf.sharedState),
+		RunMeta:                childMeta,
+		Policy:                 childPol,
+		Proxy:                  childProxy,
+		FileWriter:             childFileWriter,
+		EnqueueObserver:        observer,
+		BrowserWSEndpoint:      cf.browserWSEndpoint,
+		ResolveFrom:            cf.resolveFrom,
+		ExecutorArgs:           cf.executorArgs,
+		Source:                 childSource,
+		Category:               childCategory,
+		StorageDataset:         cf.storageDataset,
+		StorageDay:             cf.storage.partitionDay(childStartTime),
+		StorageHour:            cf.storage.partitionHour(childStartTime),
+		Collector:              childCollector,
+		FailOnDrops:            cf.failOnDrops,
+		AllowSeqGaps:           cf.allowSeqGaps,
+		StallTimeout:           cf.stallTimeout,
+		MaxEventBytes:          cf.maxEventBytes,
+		LogMinLevel:            cf.logMinLevel,
+		SampleRate:             cf.sampleRate,
+		EventFilter:            cf.eventFilter,
+		ArtifactBudget:         item.ArtifactBudget,
+		Redactor:               cf.redactor,
+		DomainPolicy:           cf.domainPolicy,
+		IngestMode:             cf.ingestMode,
+		Drain:                  cf.drain,
+		ProxyRotator:           cf.proxyRotator,
+		VerifyArtifacts:        cf.verifyArtifacts,
+		SniffContentType:       cf.sniffContentType,
+		PersistStderr:          cf.persistStderr,
+		StderrTailLines:        cf.stderrTail,
+		PreRunHook:             cf.preRunHook,
+		ArtifactSpillThreshold: cf.spillThreshold,
+		MaxFrameBytes:          cf.maxFrameBytes,
+		DecodeLimits:           cf.decodeLimits,
+		ArtifactObserver:       childArtifacts.observer(),
+		Clock:                  cf.clock,
 	}
 
 	orchestrator, err := runtime.NewRunOrchestrator(config)
@@ -489,16 +1162,34 @@ func (cf *childFactory) Run(ctx context.Context, item runtime.WorkItem, observer
 		return nil, fmt.Errorf("child execution failed: %w", err)
 	}
 
-	// Persist child metrics (best effort)
-	if childLodeClient != nil {
+	cf.finishChild(ctx, item.RunID, result, childLodeClient, childCollector, func() {
+		childArtifacts.wait()
+		cf.adapter.notify(result, cf.storage, cf.storageDataset, childSource, childCategory, cf.storage.partitionDay(childStartTime), cf.storage.partitionHour(childStartTime), cf.clock.Now().Sub(childStartTime))
+	})
+	return result, nil
+}
+
+// finishChild persists a finished child's metrics and partition manifest
+// (best effort) and calls notify: first under --notify-early, last otherwise.
+func (cf *childFactory) finishChild(ctx context.Context, runID string, result *runtime.RunResult, client lode.Client, collector *metrics.Collector, notify func()) {
+	if cf.adapter.notifiesEarly() {
+		notify()
+	}
+
+	if client != nil {
 		metricsCtx, metricsCancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
-		if writeErr := childLodeClient.WriteMetrics(metricsCtx, childCollector.Snapshot(), time.Now()); writeErr != nil {
-			fmt.Fprintf(os.Stderr, "Warning: failed to persist child metrics for %s: %v\n", item.RunID, writeErr)
+		if writeErr := client.WriteMetrics(metricsCtx, collector.Snapshot(), cf.clock.Now()); writeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to persist child metrics for %s: %v\n", runID, writeErr)
+		}
+		if writeErr := writePartitionManifest(metricsCtx, client, result.Outcome, cf.clock.Now()); writeErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to write partition manifest for %s: %v\n", runID, writeErr)
 		}
 		metricsCancel()
 	}
 
-	return result, nil
+	if !cf.adapter.notifiesEarly() {
+		notify()
+	}
 }
 
 // runFinalizer handles post-execution concerns: metrics persistence,
@@ -507,31 +1198,61 @@ func (cf *childFactory) Run(ctx context.Context, item runtime.WorkItem, observer
 type runFinalizer struct {
 	lodeClient     lode.Client
 	collector      *metrics.Collector
-	adapter        *adapterChoice
+	adapter        *sharedAdapter
+	artifacts      *artifactRun // the current attempt's artifact_committed events, may be nil
 	storage        storageChoice
 	storageDataset string
 	source         string
 	category       string
 	policyChoice   policyChoice
+	clock          clock.Clock // measures duration from startTime
 	startTime      time.Time
 	quiet          bool
 	reportPath     string
+	manifestPath   string
+	inputs         *runtime.ManifestInputs
+	exitCodes      exitCodeMap
+	onComplete     *runtime.OnCompleteHook // --on-complete, may be nil
 }
 
-// Finalize persists metrics, notifies the adapter, writes the report, and prints results.
-// duration is computed from startTime internally.
+// Finalize persists metrics, notifies the adapter (first, under
+// --notify-early), writes the report and manifest, and prints results.
+// duration is computed from startTime internally. fanOut is the fan-out
+// summary for the manifest (nil for single runs).
 //
 // Note: run_completed events reach all configured event sinks (including Redis Streams)
 // through the normal policy path — no separate terminal publish is needed.
-func (f *runFinalizer) Finalize(result *runtime.RunResult) {
-	duration := time.Since(f.startTime)
-	f.persistMetrics(duration)
-	f.notifyAdapter(result, duration)
+func (f *runFinalizer) Finalize(result *runtime.RunResult, fanOut *runtime.FanOutResult) {
+	duration := f.clock.Now().Sub(f.startTime)
+	f.persistAndNotify(result, duration)
 	f.writeReport(result)
+	f.writeManifest(result, fanOut)
 	f.printResults(result, duration)
 }
 
-func (f *runFinalizer) persistMetrics(duration time.Duration) {
+// finalizeAttempt persists metrics and notifies the adapter for an attempt
+// that --max-attempts is about to retry. The report, manifest, and printed
+// results describe the final attempt only.
+func (f *runFinalizer) finalizeAttempt(result *runtime.RunResult) {
+	f.persistAndNotify(result, f.clock.Now().Sub(f.startTime))
+}
+
+// persistAndNotify persists metrics and then notifies the adapter, or the
+// other way round under --notify-early so downstream triggers do not wait
+// on storage writes.
+func (f *runFinalizer) persistAndNotify(result *runtime.RunResult, duration time.Duration) {
+	if f.adapter.notifiesEarly() {
+		f.notifyAdapter(result, duration)
+		f.persistMetrics(result, duration)
+		return
+	}
+	f.persistMetrics(result, duration)
+	f.notifyAdapter(result, duration)
+}
+
+// persistMetrics writes the metrics snapshot and then the partition
+// manifest, which must come last so it lists the metrics file.
+func (f *runFinalizer) persistMetrics(result *runtime.RunResult, duration time.Duration) {
 	if f.lodeClient == nil {
 		return
 	}
@@ -541,47 +1262,198 @@ func (f *runFinalizer) persistMetrics(duration time.Duration) {
 	if err := f.lodeClient.WriteMetrics(ctx, f.collector.Snapshot(), completedAt); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to persist metrics: %v\n", err)
 	}
+	if err := writePartitionManifest(ctx, f.lodeClient, result.Outcome, completedAt); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write partition manifest: %v\n", err)
+	}
+}
+
+// writePartitionManifest writes the run partition's _manifest.json when the
+// client supports it (a no-op unless --partition-manifest is set).
+func writePartitionManifest(ctx context.Context, client lode.Client, outcome *types.RunOutcome, completedAt time.Time) error {
+	mw, ok := client.(lode.PartitionManifestWriter)
+	if !ok {
+		return nil
+	}
+	return mw.WritePartitionManifest(ctx, outcome, completedAt)
 }
 
 func (f *runFinalizer) notifyAdapter(result *runtime.RunResult, duration time.Duration) {
-	if f.adapter == nil {
+	f.artifacts.wait()
+	f.adapter.notify(result, f.storage, f.storageDataset, f.source, f.category, f.storage.partitionDay(f.startTime), f.storage.partitionHour(f.startTime), duration)
+}
+
+// sharedAdapter is the run's notification adapter, built on first use and
+// reused by the root finalizer and every fan-out child so connection pools
+// (redis, kafka, webhook keep-alives) are opened once per process. A nil
+// sharedAdapter notifies nothing.
+type sharedAdapter struct {
+	choice adapterChoice
+
+	once sync.Once
+	adpt adapter.Adapter
+	err  error
+
+	closeOnce sync.Once
+}
+
+func newSharedAdapter(choice *adapterChoice) *sharedAdapter {
+	if choice == nil {
+		return nil
+	}
+	return &sharedAdapter{choice: *choice}
+}
+
+// notifiesEarly reports whether run_completed is published before metrics
+// persistence (--notify-early).
+func (s *sharedAdapter) notifiesEarly() bool {
+	return s != nil && s.choice.notifyEarly
+}
+
+// get builds the adapter once; later calls return the same instance or error.
+func (s *sharedAdapter) get() (adapter.Adapter, error) {
+	s.once.Do(func() {
+		s.adpt, s.err = buildAdapter(s.choice)
+	})
+	return s.adpt, s.err
+}
+
+// notify publishes the run_completed event for result, unless --adapter-on
+// excludes its outcome. Failures are warnings and never change the run
+// outcome. Safe for concurrent use.
+func (s *sharedAdapter) notify(result *runtime.RunResult, storage storageChoice, dataset, source, category, day, hour string, duration time.Duration) {
+	if s == nil || !s.choice.notifies(result.Outcome.Status) {
 		return
 	}
-	adpt, err := buildAdapter(*f.adapter)
+	adpt, err := s.get()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: adapter creation failed: %v\n", err)
 		return
 	}
-	defer iox.DiscardClose(adpt)
 
-	event := buildRunCompletedEvent(result, f.storage, f.storageDataset, f.source, f.category, lode.DeriveDay(f.startTime), duration)
-	ctx, cancel := context.WithTimeout(context.Background(), f.adapter.timeout)
+	event := buildRunCompletedEvent(result, storage, dataset, source, category, day, hour, duration, s.choice.errorMaxLen)
+	event.MetricsPending = s.choice.notifyEarly
+	ctx, cancel := context.WithTimeout(context.Background(), s.choice.timeout)
 	defer cancel()
 	if err := adpt.Publish(ctx, event); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: adapter notification failed: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Warning: adapter notification failed for %s: %v\n", event.RunID, err)
 	}
 }
 
+// Close releases the adapter if it was built. Idempotent.
+func (s *sharedAdapter) Close() error {
+	if s == nil {
+		return nil
+	}
+	var err error
+	s.closeOnce.Do(func() {
+		// Blocks a racing first get until the build finishes
+		s.once.Do(func() {})
+		if s.adpt != nil {
+			err = s.adpt.Close()
+		}
+	})
+	return err
+}
+
 func (f *runFinalizer) writeReport(result *runtime.RunResult) {
 	if f.reportPath == "" {
 		return
 	}
-	exitCode := outcomeToExitCode(result.Outcome.Status)
+	exitCode := outcomeToExitCode(result.Outcome.Status, f.exitCodes)
 	report := runtime.BuildRunReport(result, f.collector.Snapshot(), f.policyChoice.name, exitCode)
 	if err := runtime.WriteRunReport(report, f.reportPath); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: failed to write report: %v\n", err)
 	}
 }
 
+func (f *runFinalizer) writeManifest(result *runtime.RunResult, fanOut *runtime.FanOutResult) {
+	if f.manifestPath == "" {
+		return
+	}
+	if err := runtime.WriteRunManifest(f.buildManifest(result, fanOut), f.manifestPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write manifest: %v\n", err)
+	}
+}
+
+// buildManifest composes the run manifest for --output-manifest and the
+// --on-complete hook.
+func (f *runFinalizer) buildManifest(result *runtime.RunResult, fanOut *runtime.FanOutResult) *runtime.RunManifest {
+	exitCode := outcomeToExitCode(result.Outcome.Status, f.exitCodes)
+	report := runtime.BuildRunReport(result, f.collector.Snapshot(), f.policyChoice.name, exitCode)
+	day, hour := f.storage.partitionDay(f.startTime), f.storage.partitionHour(f.startTime)
+	storage := &runtime.ManifestStorage{
+		Tenant:   f.storage.tenant,
+		Backend:  f.storage.backend,
+		Dataset:  f.storageDataset,
+		Source:   f.source,
+		Category: f.category,
+		Day:      day,
+		Hour:     hour,
+		Path:     buildStoragePath(f.storage, f.storageDataset, f.source, f.category, day, hour, result.RunMeta.RunID),
+	}
+	manifest := runtime.BuildRunManifest(report, result.RunMeta, storage, fanOut)
+	manifest.Inputs = f.inputs
+	return manifest
+}
+
+// finish runs the --on-complete hook, the last step of a run invocation,
+// and returns the process exit code. The hook runs only after a successful
+// run. Its failure is a warning, unless --on-complete-required turns it into
+// the policy_failure exit code; the report and manifest keep the run outcome.
+func (f *runFinalizer) finish(result *runtime.RunResult, fanOut *runtime.FanOutResult) int {
+	code := outcomeToExitCode(result.Outcome.Status, f.exitCodes)
+	if f.onComplete == nil || result.Outcome.Status != types.OutcomeSuccess {
+		return code
+	}
+	if err := f.onComplete.Run(context.Background(), f.buildManifest(result, fanOut)); err != nil {
+		if f.onComplete.Required {
+			fmt.Fprintf(os.Stderr, "Error: %v (--on-complete-required)\n", err)
+			return f.exitCodes.policyFailure
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return code
+}
+
 func (f *runFinalizer) printResults(result *runtime.RunResult, duration time.Duration) {
 	if f.quiet {
 		return
 	}
 	printRunResult(result, f.policyChoice, duration)
 	printMetrics(f.collector.Snapshot())
+	if f.storage.backend == "memory" {
+		f.printMemorySummary()
+	}
+}
+
+// printMemorySummary prints what the memory backend held for the run.
+// The data itself is discarded when the process exits.
+func (f *runFinalizer) printMemorySummary() {
+	lc, ok := f.lodeClient.(*lode.LodeClient)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	summary, err := lc.SummarizePartition(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to summarize memory storage: %v\n", err)
+		return
+	}
+	fmt.Printf("\n=== Storage (memory, DISCARDED at exit) ===\n")
+	for _, k := range summary.Kinds {
+		fmt.Printf("%-32s %d objects, %d bytes\n", k.Kind+":", k.Objects, k.Bytes)
+	}
+	fmt.Printf("%-32s %d objects, %d bytes\n", "total:", summary.Objects, summary.Bytes)
 }
 
 func runAction(c *cli.Context) error {
+	// --replay presets flags from a prior run's manifest, so it runs first
+	replay, err := loadReplay(c)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("--replay: %v", err), exitConfigError)
+	}
+
 	// Load config file if --config is provided
 	var cfg *quarryconfig.Config
 	if configPath := c.String("config"); configPath != "" {
@@ -590,6 +1462,19 @@ func runAction(c *cli.Context) error {
 			return cli.Exit(fmt.Sprintf("failed to load config: %v", err), exitConfigError)
 		}
 		cfg = loaded
+		for _, w := range cfg.Warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+		}
+	}
+	for _, w := range quarryconfig.DeprecatedFlags(c.IsSet) {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+
+	// --explain records every resolved setting and its winning source;
+	// --output-manifest and --replay use the same record
+	var explain *explainer
+	if c.Bool("explain") || c.String("output-manifest") != "" || replay != nil {
+		explain = withExplainer(c)
 	}
 
 	// Resolve values with precedence: CLI flag > config file > flag default
@@ -597,38 +1482,38 @@ func runAction(c *cli.Context) error {
 	category := resolveString(c, "category", configVal(cfg, func(c *quarryconfig.Config) string { return c.Category }))
 	executor := resolveString(c, "executor", configVal(cfg, func(c *quarryconfig.Config) string { return c.Executor }))
 	browserWSEndpoint := resolveString(c, "browser-ws-endpoint", configVal(cfg, func(c *quarryconfig.Config) string { return c.BrowserWSEndpoint }))
-	resolveFrom := resolveString(c, "resolve-from", configVal(cfg, func(c *quarryconfig.Config) string { return c.ResolveFrom }))
 
 	dryRun := c.Bool("dry-run")
 
 	// Manual validation for fields that were previously Required:true
+	if c.String("script") == "" {
+		return cli.Exit("--script is required (or use --replay)", exitConfigError)
+	}
+	if c.String("run-id") == "" {
+		return cli.Exit("--run-id is required (or use --replay)", exitConfigError)
+	}
 	// In dry-run mode, --source is not required (script validation only)
 	if source == "" && !dryRun {
 		return cli.Exit("--source is required (provide via CLI flag or config file)", exitConfigError)
 	}
 
-	// Validate and absolutize --resolve-from
-	if resolveFrom != "" {
-		absResolveFrom, err := filepath.Abs(resolveFrom)
-		if err != nil {
-			return cli.Exit(fmt.Sprintf("--resolve-from: cannot resolve path %q: %v", resolveFrom, err), exitConfigError)
-		}
-		info, err := os.Stat(absResolveFrom)
-		if err != nil {
-			if os.IsNotExist(err) {
-				return cli.Exit(fmt.Sprintf("--resolve-from: directory does not exist: %s", absResolveFrom), exitConfigError)
-			}
-			return cli.Exit(fmt.Sprintf("--resolve-from: cannot access %q: %v", absResolveFrom, err), exitConfigError)
-		}
-		if !info.IsDir() {
-			return cli.Exit(fmt.Sprintf("--resolve-from: not a directory: %s", absResolveFrom), exitConfigError)
-		}
-		resolveFrom = absResolveFrom
+	// Validate and absolutize --resolve-from, expanding --resolve-workspace
+	resolveFrom, err := resolveModulePaths(c, cfg)
+	if err != nil {
+		return cli.Exit(err.Error(), exitConfigError)
+	}
+
+	// Executor passthrough args are opaque to quarry; only reject empty ones
+	explainCLIOnly(c, "executor-arg")
+	executorArgs := c.StringSlice("executor-arg")
+	if slices.Contains(executorArgs, "") {
+		return cli.Exit("--executor-arg: value must not be empty", exitConfigError)
 	}
 
 	// Dry-run mode: validate script loadability only.
-	// Skip policy, storage, proxy, adapter, and fan-out config entirely.
-	if dryRun {
+	// Skip policy, storage, proxy, adapter, and fan-out config entirely,
+	// unless --explain asks for the full resolved configuration first.
+	if dryRun && !c.Bool("explain") {
 		executorPath, err := resolveExecutor(executor)
 		if err != nil {
 			return cli.Exit(err.Error(), exitConfigError)
@@ -636,20 +1521,183 @@ func runAction(c *cli.Context) error {
 		return runDryRun(c.Context, executorPath, c.String("script"), resolveFrom)
 	}
 
+	// Run timing and partition day derivation read this clock; tests
+	// inject a fake one via clock.NewContext
+	clk := clock.FromContext(c.Context)
+
 	// Parse policy config with precedence
 	choice := policyChoice{
+		clock:         clk,
 		name:          resolveString(c, "policy", configVal(cfg, func(c *quarryconfig.Config) string { return c.Policy.Name })),
 		flushMode:     resolveString(c, "flush-mode", configVal(cfg, func(c *quarryconfig.Config) string { return c.Policy.FlushMode })),
+		parallelFlush: resolveBool(c, "parallel-flush", configBoolVal(cfg, func(c *quarryconfig.Config) bool { return c.Policy.ParallelFlush })),
 		maxEvents:     resolveInt(c, "buffer-events", configIntVal(cfg, func(c *quarryconfig.Config) int { return c.Policy.BufferEvents })),
 		maxBytes:      resolveInt64(c, "buffer-bytes", configInt64Val(cfg, func(c *quarryconfig.Config) int64 { return c.Policy.BufferBytes })),
 		flushCount:    resolveInt(c, "flush-count", configIntVal(cfg, func(c *quarryconfig.Config) int { return c.Policy.FlushCount })),
 		flushInterval: resolveDuration(c, "flush-interval", configPolicyDurationVal(cfg)),
+		flushIdle:     resolveDuration(c, "flush-idle", configDurationField(cfg, func(c *quarryconfig.Config) time.Duration { return c.Policy.FlushIdle.Duration })),
+		flushSeq:      resolveBool(c, "flush-seq", configBoolVal(cfg, func(c *quarryconfig.Config) bool { return c.Policy.FlushSeq })),
+		batchSize:     resolveInt(c, "events-batch-size", configIntVal(cfg, func(c *quarryconfig.Config) int { return c.Policy.EventsBatchSize })),
+		batchWindow:   resolveDuration(c, "strict-batch-window", configDurationField(cfg, func(c *quarryconfig.Config) time.Duration { return c.Policy.StrictBatchWindow.Duration })),
+		degradeBuffer: resolveInt(c, "strict-degrade-buffer", configIntVal(cfg, func(c *quarryconfig.Config) int { return c.Policy.StrictDegradeBuffer })),
 	}
 
 	// Validate policy config
 	if err := validatePolicyConfig(choice); err != nil {
-		return cli.Exit(fmt.Sprintf("invalid policy config: %v", err), exitExecutorCrash)
+		return cli.Exit(fmt.Sprintf("invalid policy config: %v", err), exitConfigError)
+	}
+	failOnDrops := resolveBool(c, "fail-on-drops", configBoolVal(cfg, func(c *quarryconfig.Config) bool { return c.Policy.FailOnDrops }))
+	allowSeqGaps := resolveBool(c, "allow-seq-gaps", configBoolVal(cfg, func(c *quarryconfig.Config) bool { return c.Policy.AllowSeqGaps }))
+	if allowSeqGaps && choice.name == "strict" {
+		return cli.Exit("--allow-seq-gaps requires --policy buffered or streaming (strict policy enforces contiguous seq)", exitConfigError)
+	}
+	stallTimeout := resolveDuration(c, "stall-timeout", configDurationField(cfg, func(c *quarryconfig.Config) time.Duration { return c.StallTimeout.Duration }))
+	if stallTimeout < 0 {
+		return cli.Exit(fmt.Sprintf("--stall-timeout must be >= 0, got %s", stallTimeout), exitConfigError)
+	}
+	maxEventBytes := resolveInt64(c, "max-event-bytes", configInt64Val(cfg, func(c *quarryconfig.Config) int64 { return c.MaxEventBytes }))
+	if maxEventBytes < 0 {
+		return cli.Exit(fmt.Sprintf("--max-event-bytes must be >= 0, got %d", maxEventBytes), exitConfigError)
+	}
+	maxRunBytes := resolveInt64(c, "max-run-bytes", configInt64Val(cfg, func(c *quarryconfig.Config) int64 { return c.MaxRunBytes }))
+	if maxRunBytes < 0 {
+		return cli.Exit(fmt.Sprintf("--max-run-bytes must be >= 0, got %d", maxRunBytes), exitConfigError)
+	}
+	var logMinLevel types.LogLevel
+	if s := resolveString(c, "log-min-level", configVal(cfg, func(c *quarryconfig.Config) string { return c.LogMinLevel })); s != "" {
+		logMinLevel, err = types.ParseLogLevel(s)
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("--log-min-level: %v", err), exitConfigError)
+		}
+	}
+	// Sample rate: CLI > config > unset (keep all). An explicit rate must
+	// be in (0, 1], so "0" cannot be mistaken for "drop every item".
+	sampleRate, sampleSet := 0.0, true
+	if c.IsSet("sample-rate") {
+		sampleRate = explained(c, "sample-rate", c.Float64("sample-rate"), sourceFlag)
+	} else if cfg != nil && cfg.SampleRate != nil {
+		sampleRate = explained(c, "sample-rate", *cfg.SampleRate, sourceConfig)
+	} else {
+		sampleRate, sampleSet = explained(c, "sample-rate", c.Float64("sample-rate"), sourceDefault), false
+	}
+	if sampleSet && (sampleRate <= 0 || sampleRate > 1) {
+		return cli.Exit(fmt.Sprintf("--sample-rate must be greater than 0 and at most 1, got %g", sampleRate), exitConfigError)
+	}
+	eventFilter, err := runtime.NewEventFilter(resolveString(c, "events-filter", configVal(cfg, func(c *quarryconfig.Config) string { return c.EventsFilter })))
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("--events-filter: %v", err), exitConfigError)
+	}
+	shutdownGrace := resolveDuration(c, "shutdown-grace", configDurationField(cfg, func(c *quarryconfig.Config) time.Duration { return c.ShutdownGrace.Duration }))
+	if shutdownGrace < 0 {
+		return cli.Exit(fmt.Sprintf("--shutdown-grace must be >= 0, got %s", shutdownGrace), exitConfigError)
+	}
+	admissionSlots := resolveInt(c, "admission-slots", configIntVal(cfg, func(c *quarryconfig.Config) int { return c.Admission.Slots }))
+	if admissionSlots < 0 {
+		return cli.Exit(fmt.Sprintf("--admission-slots must be >= 0, got %d", admissionSlots), exitConfigError)
+	}
+	admissionLock := resolveString(c, "admission-lock", configVal(cfg, func(c *quarryconfig.Config) string { return c.Admission.Lock }))
+	admissionTimeout := resolveDuration(c, "admission-timeout", configDurationField(cfg, func(c *quarryconfig.Config) time.Duration { return c.Admission.Timeout.Duration }))
+	if admissionTimeout < 0 {
+		return cli.Exit(fmt.Sprintf("--admission-timeout must be >= 0, got %s", admissionTimeout), exitConfigError)
+	}
+	if admissionSlots == 0 && (admissionLock != "" || admissionTimeout > 0) {
+		fmt.Fprintf(os.Stderr, "Warning: --admission-lock and --admission-timeout are ignored without --admission-slots\n")
+	}
+	if admissionLock == "" {
+		admissionLock = defaultAdmissionLock()
+	}
+	exitCodes, err := resolveExitCodes(c, cfg)
+	if err != nil {
+		return cli.Exit(err.Error(), exitConfigError)
+	}
+	preRunHook, err := resolvePreRunHook(c, cfg)
+	if err != nil {
+		return cli.Exit(err.Error(), exitConfigError)
+	}
+	onComplete, err := resolveOnCompleteHook(c, cfg)
+	if err != nil {
+		return cli.Exit(err.Error(), exitConfigError)
+	}
+
+	// Redaction keys: CLI > config
+	redactKeys := c.StringSlice("redact")
+	redactSource := sourceFlag
+	if !c.IsSet("redact") {
+		redactSource = sourceDefault
+		if cfg != nil && len(cfg.Redact) > 0 {
+			redactKeys, redactSource = cfg.Redact, sourceConfig
+		}
+	}
+	explainFrom(c).record("redact", redactKeys, redactSource)
+	redactor, err := runtime.NewRedactor(redactKeys)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("invalid --redact: %v", err), exitConfigError)
+	}
+	domainPolicy, err := resolveDomainPolicy(c, cfg)
+	if err != nil {
+		return cli.Exit(err.Error(), exitConfigError)
+	}
+	ingestMode, err := parseIngestMode(c.Bool("events-only"), c.Bool("artifacts-only"))
+	if err != nil {
+		return cli.Exit(err.Error(), exitConfigError)
+	}
+	telemetryMode := resolveBool(c, "telemetry-mode", configBoolVal(cfg, func(c *quarryconfig.Config) bool { return c.TelemetryMode }))
+	if telemetryMode && ingestMode == runtime.IngestArtifactsOnly {
+		return cli.Exit("--telemetry-mode cannot be combined with --artifacts-only (telemetry runs carry only log events)", exitConfigError)
+	}
+	verifyArtifacts := resolveBool(c, "verify-artifacts", configBoolVal(cfg, func(c *quarryconfig.Config) bool { return c.VerifyArtifacts }))
+	if sampleRate > 0 && ingestMode == runtime.IngestArtifactsOnly {
+		fmt.Fprintf(os.Stderr, "Warning: --sample-rate has no effect with --artifacts-only (item events are discarded)\n")
+	}
+	if eventFilter != nil && ingestMode == runtime.IngestArtifactsOnly {
+		fmt.Fprintf(os.Stderr, "Warning: --events-filter has no effect with --artifacts-only (filterable events are discarded)\n")
+	}
+	if verifyArtifacts && ingestMode == runtime.IngestEventsOnly {
+		fmt.Fprintf(os.Stderr, "Warning: --verify-artifacts has no effect with --events-only (artifacts are discarded)\n")
+	}
+	sniffContentType := resolveBool(c, "sniff-content-type", configBoolVal(cfg, func(c *quarryconfig.Config) bool { return c.SniffContentType }))
+	if sniffContentType && ingestMode == runtime.IngestEventsOnly {
+		fmt.Fprintf(os.Stderr, "Warning: --sniff-content-type has no effect with --events-only (artifacts are discarded)\n")
+	}
+	persistStderr := resolveBool(c, "persist-stderr", configBoolVal(cfg, func(c *quarryconfig.Config) bool { return c.PersistStderr }))
+	stderrTail := resolveInt(c, "stderr-tail", configIntVal(cfg, func(c *quarryconfig.Config) int { return c.StderrTail }))
+	if stderrTail < 1 {
+		return cli.Exit(fmt.Sprintf("--stderr-tail must be at least 1, got %d", stderrTail), exitConfigError)
+	}
+	spillThreshold := resolveInt64(c, "artifact-spill-threshold", configInt64Val(cfg, func(c *quarryconfig.Config) int64 { return c.ArtifactSpillThreshold }))
+	if spillThreshold < 0 {
+		return cli.Exit(fmt.Sprintf("--artifact-spill-threshold must be >= 0, got %d", spillThreshold), exitConfigError)
+	}
+	maxFrameBytes := resolveInt64(c, "max-frame-bytes", configInt64Val(cfg, func(c *quarryconfig.Config) int64 { return c.MaxFrameBytes }))
+	if err := ipc.ValidateMaxFrameSize(maxFrameBytes); err != nil {
+		return cli.Exit(fmt.Sprintf("--max-frame-bytes: %v", err), exitConfigError)
+	}
+	if maxFrameBytes > ipc.MaxFrameSize {
+		fmt.Fprintf(os.Stderr, "Warning: --max-frame-bytes %d exceeds the %d-byte default; each run may buffer a frame this large in memory\n", maxFrameBytes, ipc.MaxFrameSize)
+	}
+	decodeLimits := ipc.DecodeLimits{
+		MaxDepth:        resolveInt(c, "max-decode-depth", configIntVal(cfg, func(c *quarryconfig.Config) int { return c.MaxDecodeDepth })),
+		MaxContainerLen: resolveInt(c, "max-decode-container-len", configIntVal(cfg, func(c *quarryconfig.Config) int { return c.MaxDecodeContainerLen })),
+	}
+	if err := decodeLimits.Validate(); err != nil {
+		return cli.Exit(fmt.Sprintf("--max-decode-depth/--max-decode-container-len: %v", err), exitConfigError)
+	}
+	dumpIPCPath, dumpIPCMaxBytes := c.String("dump-ipc"), c.Int64("dump-ipc-max-bytes")
+	if dumpIPCMaxBytes < 0 {
+		return cli.Exit(fmt.Sprintf("--dump-ipc-max-bytes must be >= 0, got %d", dumpIPCMaxBytes), exitConfigError)
+	}
+	if dumpIPCPath == "" && c.IsSet("dump-ipc-max-bytes") {
+		fmt.Fprintf(os.Stderr, "Warning: --dump-ipc-max-bytes is ignored without --dump-ipc\n")
+	}
+	explainCLIOnly(c, "dump-ipc", "dump-ipc-max-bytes")
+
+	// Go-side profile of this process (--profile), finished on return
+	explainCLIOnly(c, "profile", "profile-out")
+	profile, err := startProfile(c.String("profile"), c.String("profile-out"))
+	if err != nil {
+		return cli.Exit(err.Error(), exitConfigError)
 	}
+	defer profile.stop()
 
 	// Parse job payload (--job or --job-json, not both)
 	job, err := parseJobPayload(c.String("job"), c.String("job-json"))
@@ -657,9 +1705,38 @@ func runAction(c *cli.Context) error {
 		return cli.Exit(err.Error(), exitConfigError)
 	}
 
+	// Validate job payload against schema (--job-schema or config job_schema:)
+	if jobSchema := resolveString(c, "job-schema", configVal(cfg, func(c *quarryconfig.Config) string { return c.JobSchema })); jobSchema != "" {
+		if err := validateJobSchema(job, jobSchema); err != nil {
+			return cli.Exit(err.Error(), exitConfigError)
+		}
+	}
+
+	// Initial script state (--seed-state), passed beside the job payload
+	explainCLIOnly(c, "seed-state")
+	seedState, err := parseSeedState(c.String("seed-state"))
+	if err != nil {
+		return cli.Exit(err.Error(), exitConfigError)
+	}
+
 	// Build run metadata
+	explainCLIOnly(c, "script", "run-id", "attempt", "job-id", "parent-run-id", "events-only", "artifacts-only")
+	storageDay := c.String("storage-day")
+	if storageDay != "" {
+		if err := lode.ValidateDay(storageDay); err != nil {
+			return cli.Exit(fmt.Sprintf("invalid --storage-day: %v", err), exitConfigError)
+		}
+	}
+	now := clk.Now()
+	runID, err := resolveRunID(c.String("run-id"), source, category, storageChoice{day: storageDay}.partitionDay(now), job, now)
+	if err != nil {
+		return cli.Exit(err.Error(), exitConfigError)
+	}
+	if runID != c.String("run-id") && !c.Bool("quiet") {
+		fmt.Fprintf(os.Stderr, "Generated run ID %s (--run-id %s)\n", runID, c.String("run-id"))
+	}
 	runMeta := &types.RunMeta{
-		RunID:   c.String("run-id"),
+		RunID:   runID,
 		Attempt: c.Int("attempt"),
 	}
 	if jobID := c.String("job-id"); jobID != "" {
@@ -668,6 +1745,19 @@ func runAction(c *cli.Context) error {
 	if parentRunID := c.String("parent-run-id"); parentRunID != "" {
 		runMeta.ParentRunID = &parentRunID
 	}
+	labels, err := resolveLabels(c, cfg)
+	if err != nil {
+		return cli.Exit(err.Error(), exitConfigError)
+	}
+	runMeta.Labels = labels
+	if err := runMeta.Validate(); err != nil {
+		return cli.Exit(fmt.Sprintf("invalid run metadata: %v", err), exitConfigError)
+	}
+	explainCLIOnly(c, "max-attempts", "retry-on")
+	attempts, err := parseAttemptChoice(c.Int("max-attempts"), c.StringSlice("retry-on"))
+	if err != nil {
+		return cli.Exit(err.Error(), exitConfigError)
+	}
 
 	// Parse and validate storage config with precedence
 	storageBackend := resolveString(c, "storage-backend", configVal(cfg, func(c *quarryconfig.Config) string { return c.Storage.Backend }))
@@ -676,7 +1766,7 @@ func runAction(c *cli.Context) error {
 	if storageBackend == "" {
 		return cli.Exit("--storage-backend is required (provide via CLI flag or config file)", exitConfigError)
 	}
-	if storagePath == "" {
+	if storagePath == "" && storageBackend != "memory" {
 		return cli.Exit("--storage-path is required (provide via CLI flag or config file)", exitConfigError)
 	}
 
@@ -686,13 +1776,50 @@ func runAction(c *cli.Context) error {
 		region:       resolveString(c, "storage-region", configVal(cfg, func(c *quarryconfig.Config) string { return c.Storage.Region })),
 		endpoint:     resolveString(c, "storage-endpoint", configVal(cfg, func(c *quarryconfig.Config) string { return c.Storage.Endpoint })),
 		usePathStyle: resolveBool(c, "storage-s3-path-style", configBoolVal(cfg, func(c *quarryconfig.Config) bool { return c.Storage.S3PathStyle })),
+		sse:          resolveString(c, "storage-s3-sse", configVal(cfg, func(c *quarryconfig.Config) string { return c.Storage.S3SSE })),
+		kmsKeyID:     resolveString(c, "storage-s3-kms-key", configVal(cfg, func(c *quarryconfig.Config) string { return c.Storage.S3KMSKeyID })),
+		storageClass: resolveString(c, "storage-s3-storage-class", configVal(cfg, func(c *quarryconfig.Config) string { return c.Storage.S3StorageClass })),
+		skipExisting: resolveBool(c, "storage-s3-skip-existing", configBoolVal(cfg, func(c *quarryconfig.Config) bool { return c.Storage.S3SkipExisting })),
+		day:          storageDay,
+
+		partitionManifest: resolveBool(c, "partition-manifest", configBoolVal(cfg, func(c *quarryconfig.Config) bool { return c.Storage.PartitionManifest })),
 	}
 	if err := validateStorageConfig(storageConfig); err != nil {
 		return cli.Exit(err.Error(), exitConfigError)
 	}
+	if tmpl := resolveString(c, "storage-prefix-template", configVal(cfg, func(c *quarryconfig.Config) string { return c.Storage.PrefixTemplate })); tmpl != "" {
+		pt, err := lode.ParsePartitionTemplate(tmpl)
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("invalid --storage-prefix-template: %v", err), exitConfigError)
+		}
+		storageConfig.partitionTemplate = pt
+	}
+	if err := applyPartitionGranularity(&storageConfig, resolveString(c, "partition-granularity", configVal(cfg, func(c *quarryconfig.Config) string { return c.Storage.PartitionGranularity }))); err != nil {
+		return cli.Exit(err.Error(), exitConfigError)
+	}
+	storageConfig.format, err = lode.ParseStorageFormat(resolveString(c, "storage-format", configVal(cfg, func(c *quarryconfig.Config) string { return c.Storage.Format })))
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("invalid --storage-format: %v", err), exitConfigError)
+	}
+	explainCLIOnly(c, "storage-day")
+	storageConfig.sinks, storageConfig.sinkQuorum, err = resolveStorageSinks(c, cfg, storageConfig)
+	if err != nil {
+		return cli.Exit(err.Error(), exitConfigError)
+	}
+	storageConfig.tenant, err = resolveTenant(c, cfg)
+	if err != nil {
+		return cli.Exit(err.Error(), exitConfigError)
+	}
 
 	storageDataset := resolveString(c, "storage-dataset", configVal(cfg, func(c *quarryconfig.Config) string { return c.Storage.Dataset }))
 
+	// Egress proxy for quarry's own S3 and webhook clients (not the scraping proxy)
+	egressProxy, err := parseEgressProxy(resolveString(c, "egress-proxy", configVal(cfg, func(c *quarryconfig.Config) string { return c.EgressProxy })))
+	if err != nil {
+		return cli.Exit(err.Error(), exitConfigError)
+	}
+	storageConfig.egressProxy = egressProxy
+
 	// Parse and validate adapter config (pre-execution: fail fast on bad config)
 	var adptConfig *adapterChoice
 	adapterType := resolveString(c, "adapter", configVal(cfg, func(c *quarryconfig.Config) string { return c.Adapter.Type }))
@@ -701,8 +1828,13 @@ func runAction(c *cli.Context) error {
 		if err != nil {
 			return cli.Exit(fmt.Sprintf("invalid adapter config: %v", err), exitConfigError)
 		}
+		ac.egressProxy = egressProxy
 		adptConfig = &ac
+	} else if c.Bool("notify-early") {
+		fmt.Fprintf(os.Stderr, "Warning: --notify-early is ignored without --adapter\n")
 	}
+	notifier := newSharedAdapter(adptConfig)
+	defer iox.DiscardClose(notifier)
 
 	// Parse and validate event sink config
 	eventSinks, err := parseEventSinkConfig(c, cfg)
@@ -711,17 +1843,134 @@ func runAction(c *cli.Context) error {
 	}
 
 	// Parse and validate fan-out config
-	fanOut := fanOutChoice{
-		depth:    c.Int("depth"),
-		maxRuns:  c.Int("max-runs"),
-		parallel: c.Int("parallel"),
+	explainCLIOnly(c, "depth", "max-runs", "parallel", "parallel-max", "max-bytes-per-child", "max-artifacts-per-child", "dedupe-enqueues", "dedupe-capacity", "retry-per-item", "per-origin-concurrency", "origin-stagger", "fanout-on-error", "warmup-script", "input-urls")
+	parallel, parallelIsAuto, err := parseParallel(c.String("parallel"))
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("invalid fan-out config: %v", err), exitConfigError)
 	}
-	if err := validateFanOutConfig(fanOut); err != nil {
+	fanOut := fanOutChoice{
+		depth:                c.Int("depth"),
+		maxRuns:              c.Int("max-runs"),
+		parallel:             parallel,
+		parallelAuto:         parallelIsAuto,
+		parallelMax:          c.Int("parallel-max"),
+		maxBytesPerChild:     c.Int64("max-bytes-per-child"),
+		maxArtifactsPerChild: c.Int("max-artifacts-per-child"),
+		dedupeBy:             c.String("dedupe-enqueues"),
+		dedupeCapacity:       c.Int("dedupe-capacity"),
+		retryPerItem:         c.Int("retry-per-item"),
+		perOriginConcurrency: c.Int("per-origin-concurrency"),
+		originStagger:        c.Duration("origin-stagger"),
+		onError:              c.String("fanout-on-error"),
+		warmupScript:         c.String("warmup-script"),
+		inputURLs:            c.String("input-urls"),
+	}
+	if err := validateFanOutConfig(&fanOut); err != nil {
 		return cli.Exit(fmt.Sprintf("invalid fan-out config: %v", err), exitConfigError)
 	}
-	if fanOut.depth == 0 && c.IsSet("parallel") && fanOut.parallel > 1 {
+	if fanOut.parallelAuto && fanOut.depth > 0 && !c.Bool("quiet") {
+		fmt.Fprintf(os.Stderr, "--parallel auto: %d workers\n", fanOut.parallel)
+	}
+	if telemetryMode && fanOut.depth > 0 {
+		return cli.Exit("--telemetry-mode cannot be combined with --depth > 0 (enqueue events are rejected in telemetry mode)", exitConfigError)
+	}
+	if fanOut.depth == 0 && c.IsSet("parallel") && (fanOut.parallel > 1 || fanOut.parallelAuto) {
 		fmt.Fprintf(os.Stderr, "Warning: --parallel > 1 has no effect without --depth > 0\n")
 	}
+	if fanOut.depth == 0 && (fanOut.maxBytesPerChild > 0 || fanOut.maxArtifactsPerChild > 0) {
+		fmt.Fprintf(os.Stderr, "Warning: per-child budgets have no effect without --depth > 0\n")
+	}
+	if fanOut.depth == 0 && (c.IsSet("dedupe-enqueues") || fanOut.dedupeCapacity > 0) {
+		fmt.Fprintf(os.Stderr, "Warning: --dedupe-enqueues/--dedupe-capacity have no effect without --depth > 0\n")
+	}
+	if fanOut.depth > 0 && attempts.maxAttempts > 1 {
+		return cli.Exit("--max-attempts is not supported with --depth > 0 (use --retry-per-item to retry children)", exitConfigError)
+	}
+	if fanOut.depth == 0 && fanOut.retryPerItem > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: --retry-per-item has no effect without --depth > 0\n")
+	}
+	if fanOut.depth == 0 && fanOut.warmupScript != "" {
+		fmt.Fprintf(os.Stderr, "Warning: --warmup-script has no effect without --depth > 0\n")
+	}
+	if fanOut.depth == 0 && (fanOut.perOriginConcurrency > 0 || fanOut.originStagger > 0) {
+		fmt.Fprintf(os.Stderr, "Warning: --per-origin-concurrency/--origin-stagger have no effect without --depth > 0\n")
+	}
+	var seeds []map[string]any
+	if fanOut.inputURLs != "" {
+		if seeds, err = readInputURLs(fanOut.inputURLs); err != nil {
+			return cli.Exit(err.Error(), exitConfigError)
+		}
+		if len(seeds) > fanOut.maxRuns {
+			fmt.Fprintf(os.Stderr, "Warning: --input-urls has %d entries but --max-runs is %d; the rest are skipped\n", len(seeds), fanOut.maxRuns)
+		}
+	}
+
+	// Resolve proxy pools from config file (inline proxies: key)
+	var configPools []types.ProxyPool
+	if cfg != nil {
+		configPools = cfg.ProxyPools()
+		for i := range configPools {
+			if err := proxy.ResolveSecrets(&configPools[i]); err != nil {
+				return cli.Exit(fmt.Sprintf("invalid proxies config: %v", err), exitConfigError)
+			}
+		}
+	}
+
+	// Conflict check: --proxy-config and config proxies: cannot both be present
+	cliProxyConfig := c.String("proxy-config")
+	if cliProxyConfig != "" && len(configPools) > 0 {
+		return cli.Exit("cannot use --proxy-config and config file proxies: together (use one source for proxy pools)", exitConfigError)
+	}
+
+	// Parse proxy config with precedence
+	proxyConfig := proxyChoice{
+		configPath:    cliProxyConfig,
+		poolName:      resolveString(c, "proxy-pool", configVal(cfg, func(c *quarryconfig.Config) string { return c.Proxy.Pool })),
+		strategy:      resolveString(c, "proxy-strategy", configVal(cfg, func(c *quarryconfig.Config) string { return c.Proxy.Strategy })),
+		stickyKey:     c.String("proxy-sticky-key"),
+		domain:        c.String("proxy-domain"),
+		origin:        c.String("proxy-origin"),
+		healthCheck:   resolveBool(c, "proxy-health-check", configBoolVal(cfg, func(c *quarryconfig.Config) bool { return c.Proxy.HealthCheck })),
+		healthTimeout: resolveDuration(c, "proxy-health-timeout", configDurationField(cfg, func(c *quarryconfig.Config) time.Duration { return c.Proxy.HealthTimeout.Duration })),
+		rotateOnBlock: resolveBool(c, "proxy-rotate-on-block", configBoolVal(cfg, func(c *quarryconfig.Config) bool { return c.Proxy.RotateOnBlock })),
+	}
+	// Source routing: --proxy-pool > proxy_routing match > proxy.pool
+	if cfg != nil && len(cfg.ProxyRouting) > 0 {
+		if c.IsSet("proxy-pool") {
+			if _, err := compileProxyRoutes(cfg.ProxyRouting); err != nil {
+				return cli.Exit(err.Error(), exitConfigError)
+			}
+		} else {
+			pool, pattern, err := routeProxyPool(cfg.ProxyRouting, source)
+			if err != nil {
+				return cli.Exit(err.Error(), exitConfigError)
+			}
+			if pool != "" {
+				explainFrom(c).record("proxy-pool", fmt.Sprintf("%s (proxy_routing %q)", pool, pattern), sourceConfig)
+				proxyConfig.poolName = pool
+			}
+		}
+	}
+	if !replay.checkProxyPool(proxyConfig.poolName, configPools, cliProxyConfig) {
+		proxyConfig.poolName = explained(c, "proxy-pool", "", sourceDefault)
+	}
+	if proxyConfig.healthTimeout <= 0 {
+		return cli.Exit(fmt.Sprintf("--proxy-health-timeout must be > 0, got %s", proxyConfig.healthTimeout), exitConfigError)
+	}
+	if proxyConfig.rotateOnBlock && proxyConfig.poolName == "" {
+		fmt.Fprintf(os.Stderr, "Warning: --proxy-rotate-on-block has no effect without a proxy pool\n")
+	}
+
+	explainCLIOnly(c, "proxy-config", "proxy-sticky-key", "proxy-domain", "proxy-origin")
+
+	// Resolved ahead of browser acquisition so --explain can report it
+	noBrowserReuse := resolveBool(c, "no-browser-reuse", configBoolVal(cfg, func(c *quarryconfig.Config) bool { return c.NoBrowserReuse }))
+	browserLaunchTimeout := resolveDuration(c, "browser-launch-timeout", configDurationField(cfg, func(c *quarryconfig.Config) time.Duration { return c.BrowserLaunchTimeout.Duration }))
+	if browserLaunchTimeout < 0 {
+		return cli.Exit(fmt.Sprintf("--browser-launch-timeout must be >= 0, got %s", browserLaunchTimeout), exitConfigError)
+	}
+	explainCLIOnly(c, "overwrite", "quiet", "report", "output-manifest")
+	metricsAddr := resolveString(c, "metrics-addr", configVal(cfg, func(c *quarryconfig.Config) string { return c.MetricsAddr }))
 
 	// Resolve executor path (needed for metrics dimension before policy build)
 	executorPath, err := resolveExecutor(executor)
@@ -729,6 +1978,35 @@ func runAction(c *cli.Context) error {
 		return cli.Exit(err.Error(), exitConfigError)
 	}
 
+	// Print the resolved configuration before any storage, proxy, or browser
+	// side effects. Combined with --dry-run, stop after script validation.
+	if explain != nil {
+		if executor == "" {
+			explain.record("executor", executorPath+" (auto-resolved)", sourceDefault)
+		}
+		if c.Bool("explain") {
+			explain.print(os.Stderr, c.String("config"))
+		}
+		if replay != nil {
+			replay.compare(explain)
+			replay.print(os.Stderr, runMeta.RunID)
+		}
+		if dryRun {
+			return runDryRun(c.Context, executorPath, c.String("script"), resolveFrom)
+		}
+	}
+
+	// Incremental mode: resume from the latest prior run's final checkpoint
+	if resolveBool(c, "since-checkpoint", configBoolVal(cfg, func(c *quarryconfig.Config) bool { return c.SinceCheckpoint })) {
+		if storageConfig.backend == "memory" {
+			return cli.Exit("--since-checkpoint requires persistent storage (memory backend holds no prior runs)", exitConfigError)
+		}
+		job, err = injectResumeState(c.Context, job, storageConfig, storageDataset, source, category, runMeta.RunID, c.Bool("quiet"))
+		if err != nil {
+			return err
+		}
+	}
+
 	// Create metrics collector per CONTRACT_METRICS.md
 	var jobID string
 	if runMeta.JobID != nil {
@@ -736,84 +2014,101 @@ func runAction(c *cli.Context) error {
 	}
 	// Use basename for stable executor identity (avoids high-cardinality from absolute paths)
 	collector := metrics.NewCollector(choice.name, filepath.Base(executorPath), storageConfig.backend, runMeta.RunID, jobID)
+	collector.SetLabels(runMeta.Labels)
+
+	// Live metrics endpoint: aggregates root and child collectors, closed on return
+	var metricsServer *metrics.Server
+	if metricsAddr != "" {
+		metricsServer, err = metrics.NewServer(metricsAddr)
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("invalid --metrics-addr: %v", err), exitConfigError)
+		}
+		defer iox.DiscardClose(metricsServer)
+		metricsServer.Register(collector)
+		if !c.Bool("quiet") {
+			fmt.Fprintf(os.Stderr, "Serving metrics on http://%s/metrics\n", metricsServer.Addr())
+		}
+	}
 
 	// Build policy with storage sink and optional event sinks
 	// Start time is "now" - used to derive partition day
-	startTime := time.Now()
+	startTime := clk.Now()
 	pol, lodeClient, fileWriter, err := buildPolicy(choice, storageConfig, storageDataset, source, category, runMeta.RunID, startTime, collector, eventSinks)
 	if err != nil {
 		return fmt.Errorf("failed to create policy: %w", err)
 	}
 	defer iox.DiscardClose(pol)
 
-	// Resolve proxy pools from config file (inline proxies: key)
-	var configPools []types.ProxyPool
-	if cfg != nil {
-		configPools = cfg.ProxyPools()
-	}
-
-	// Conflict check: --proxy-config and config proxies: cannot both be present
-	cliProxyConfig := c.String("proxy-config")
-	if cliProxyConfig != "" && len(configPools) > 0 {
-		return cli.Exit("cannot use --proxy-config and config file proxies: together (use one source for proxy pools)", exitConfigError)
-	}
-
-	// Deprecation warning for --proxy-config
-	if cliProxyConfig != "" {
-		fmt.Fprintf(os.Stderr, "Warning: --proxy-config is deprecated; define proxy pools in quarry.yaml under the proxies: key instead\n")
-	}
-
-	// Parse proxy config with precedence
-	proxyConfig := proxyChoice{
-		configPath: cliProxyConfig,
-		poolName:   resolveString(c, "proxy-pool", configVal(cfg, func(c *quarryconfig.Config) string { return c.Proxy.Pool })),
-		strategy:   resolveString(c, "proxy-strategy", configVal(cfg, func(c *quarryconfig.Config) string { return c.Proxy.Strategy })),
-		stickyKey:  c.String("proxy-sticky-key"),
-		domain:     c.String("proxy-domain"),
-		origin:     c.String("proxy-origin"),
+	// Idempotency guard: refuse to mix a second run into a finished partition
+	if !c.Bool("overwrite") {
+		if err := checkRunPartition(lodeClient, runMeta.RunID); err != nil {
+			return err
+		}
 	}
 
 	// Select proxy if configured
 	var resolvedProxy *types.ProxyEndpoint
+	var proxySel *proxySelection
 	if proxyConfig.poolName != "" {
-		endpoint, err := selectProxy(proxyConfig, runMeta, configPools)
+		sel, err := newProxySelection(proxyConfig, runMeta, configPools)
 		if err != nil {
-			return cli.Exit(fmt.Sprintf("proxy selection failed: %v", err), exitExecutorCrash)
+			return cli.Exit(fmt.Sprintf("proxy selection failed: %v", err), exitCodes.executorCrash)
+		}
+		endpoint, err := sel.selectEndpoint()
+		if err != nil {
+			return cli.Exit(fmt.Sprintf("proxy selection failed: %v", err), exitCodes.executorCrash)
 		}
 		resolvedProxy = endpoint
+		proxySel = sel
 	}
 
-	// Warn if proxy and browser-ws-endpoint both set (launch args ignored; page.authenticate still applies)
+	// Warn if proxy and browser-ws-endpoint both set (launch args ignored; page.authenticate still applies).
+	// The reported proxy_used is then whatever the external browser reports.
 	if resolvedProxy != nil && browserWSEndpoint != "" {
-		fmt.Fprintf(os.Stderr, "Warning: --proxy-* launch args are ignored with --browser-ws-endpoint; only page.authenticate() credentials apply\n")
+		fmt.Fprintf(os.Stderr, "Warning: --proxy-* launch args are ignored with --browser-ws-endpoint; only page.authenticate() credentials apply (the reported proxy is the one the browser was launched with)\n")
 	}
 
-	// Set up context with signal handling
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Set up context with signal handling. The cancel cause names why the
+	// run stopped in its outcome reason (see runtime.CancelCause).
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
 
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigCh
-		cancel()
-	}()
+	defer signal.Stop(sigCh)
+	// Nil without a grace period: draining is disabled, so ingestion never
+	// selects on it.
+	var drain chan struct{}
+	if shutdownGrace > 0 {
+		drain = make(chan struct{})
+	}
+	go handleShutdownSignals(ctx, sigCh, shutdownGrace, drain, cancel)
+
+	// Wait for a host-wide admission slot before anything launches a
+	// browser or executor. Held until the process exits, so fan-out
+	// children share their root's slot.
+	admission, err := acquireAdmission(ctx, admissionLock, admissionSlots, admissionTimeout, c.Bool("quiet"))
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("admission: %v", err), exitCodes.executorCrash)
+	}
+	defer admission.release()
 
 	// Resolve browser reuse:
 	// Priority: explicit --browser-ws-endpoint > browser reuse > per-run launch
-	noBrowserReuse := resolveBool(c, "no-browser-reuse", configBoolVal(cfg, func(c *quarryconfig.Config) bool { return c.NoBrowserReuse }))
 	if browserWSEndpoint == "" && !noBrowserReuse {
 		idleTimeout := runtime.IdleTimeoutFromEnv()
 		reuseCfg := runtime.ReusableBrowserConfig{
-			ExecutorPath: executorPath,
-			ScriptPath:   c.String("script"),
-			Proxy:        resolvedProxy,
-			IdleTimeout:  idleTimeout,
+			ExecutorPath:  executorPath,
+			ScriptPath:    c.String("script"),
+			Proxy:         resolvedProxy,
+			IdleTimeout:   idleTimeout,
+			LaunchTimeout: browserLaunchTimeout,
 		}
 		if ws, err := runtime.AcquireReusableBrowser(ctx, reuseCfg); err == nil {
 			browserWSEndpoint = ws
 		} else {
 			fmt.Fprintf(os.Stderr, "Browser reuse unavailable: %v\n", err)
+			printBrowserStderr(err)
 		}
 	}
 
@@ -821,33 +2116,72 @@ func runAction(c *cli.Context) error {
 	finalizer := &runFinalizer{
 		lodeClient:     lodeClient,
 		collector:      collector,
-		adapter:        adptConfig,
+		adapter:        notifier,
 		storage:        storageConfig,
 		storageDataset: storageDataset,
 		source:         source,
 		category:       category,
 		policyChoice:   choice,
+		clock:          clk,
 		startTime:      startTime,
 		quiet:          c.Bool("quiet"),
 		reportPath:     c.String("report"),
+		manifestPath:   c.String("output-manifest"),
+		exitCodes:      exitCodes,
+		onComplete:     onComplete,
 	}
+	if finalizer.manifestPath != "" || onComplete != nil {
+		finalizer.inputs = explain.manifestInputs(c.String("script"), c.String("config"), job)
+	}
+	artifactPub := newArtifactPublisher(notifier, adptConfig, storageConfig, storageDataset)
+	defer iox.DiscardClose(artifactPub)
+	finalizer.artifacts = artifactPub.forRun(runMeta, source, category, storageConfig.partitionDay(startTime), storageConfig.partitionHour(startTime))
 
 	// Build root run config
 	rootConfig := &runtime.RunConfig{
-		ExecutorPath:      executorPath,
-		ScriptPath:        c.String("script"),
-		Job:               job,
-		RunMeta:           runMeta,
-		Policy:            pol,
-		Proxy:             resolvedProxy,
-		FileWriter:        fileWriter,
-		BrowserWSEndpoint: browserWSEndpoint,
-		ResolveFrom:       resolveFrom,
-		Source:            source,
-		Category:          category,
-		StorageDataset:    storageDataset,
-		StorageDay:        lode.DeriveDay(startTime),
-		Collector:         collector,
+		ExecutorPath:           executorPath,
+		ScriptPath:             c.String("script"),
+		Job:                    job,
+		SeedState:              seedState,
+		RunMeta:                runMeta,
+		Policy:                 pol,
+		Proxy:                  resolvedProxy,
+		FileWriter:             fileWriter,
+		BrowserWSEndpoint:      browserWSEndpoint,
+		ResolveFrom:            resolveFrom,
+		ExecutorArgs:           executorArgs,
+		Source:                 source,
+		Category:               category,
+		StorageDataset:         storageDataset,
+		StorageDay:             storageConfig.partitionDay(startTime),
+		StorageHour:            storageConfig.partitionHour(startTime),
+		Collector:              collector,
+		FailOnDrops:            failOnDrops,
+		AllowSeqGaps:           allowSeqGaps,
+		StallTimeout:           stallTimeout,
+		MaxEventBytes:          maxEventBytes,
+		MaxRunBytes:            maxRunBytes,
+		LogMinLevel:            logMinLevel,
+		SampleRate:             sampleRate,
+		EventFilter:            eventFilter,
+		Redactor:               redactor,
+		DomainPolicy:           domainPolicy,
+		IngestMode:             ingestMode,
+		Drain:                  drain,
+		VerifyArtifacts:        verifyArtifacts,
+		SniffContentType:       sniffContentType,
+		PersistStderr:          persistStderr,
+		StderrTailLines:        stderrTail,
+		TelemetryMode:          telemetryMode,
+		PreRunHook:             preRunHook,
+		ArtifactSpillThreshold: spillThreshold,
+		MaxFrameBytes:          maxFrameBytes,
+		DecodeLimits:           decodeLimits,
+		ProxyRotator:           proxySel.rotator(proxyConfig.rotateOnBlock),
+		DumpIPCPath:            dumpIPCPath,
+		DumpIPCMaxBytes:        dumpIPCMaxBytes,
+		ArtifactObserver:       finalizer.artifacts.observer(),
+		Clock:                  clk,
 	}
 
 	// Branch: fan-out or single run
@@ -855,9 +2189,14 @@ func runAction(c *cli.Context) error {
 		// Use reusable browser if already acquired; otherwise launch a managed
 		// browser for fan-out to avoid N cold startups (one per child run).
 		if browserWSEndpoint == "" {
-			managedBrowser, err := runtime.LaunchManagedBrowser(ctx, executorPath, c.String("script"))
+			managedBrowser, err := runtime.LaunchManagedBrowser(ctx, executorPath, c.String("script"), browserLaunchTimeout)
 			if err != nil {
-				return cli.Exit(fmt.Sprintf("failed to launch shared browser: %v", err), exitExecutorCrash)
+				// Report a browser_launch outcome (not a bare crash) so the
+				// failure is persisted and recognizable as retryable.
+				printBrowserStderr(err)
+				result := runtime.BrowserLaunchResult(rootConfig, err)
+				finalizer.Finalize(result, nil)
+				return cli.Exit("", finalizer.finish(result, nil))
 			}
 			defer iox.DiscardClose(managedBrowser)
 			browserWSEndpoint = managedBrowser.WSEndpoint
@@ -866,15 +2205,50 @@ func runAction(c *cli.Context) error {
 
 		factory := &childFactory{
 			policyChoice:      choice,
+			clock:             clk,
 			executorPath:      executorPath,
 			storage:           storageConfig,
 			storageDataset:    storageDataset,
 			source:            source,
 			category:          category,
 			proxy:             resolvedProxy,
+			proxySelection:    proxySel,
+			proxyRotator:      proxySel.rotator(proxyConfig.rotateOnBlock),
 			browserWSEndpoint: browserWSEndpoint,
 			resolveFrom:       resolveFrom,
+			executorArgs:      executorArgs,
 			eventSinks:        eventSinks,
+			failOnDrops:       failOnDrops,
+			allowSeqGaps:      allowSeqGaps,
+			stallTimeout:      stallTimeout,
+			maxEventBytes:     maxEventBytes,
+			logMinLevel:       logMinLevel,
+			sampleRate:        sampleRate,
+			eventFilter:       eventFilter,
+			redactor:          redactor,
+			domainPolicy:      domainPolicy,
+			ingestMode:        ingestMode,
+			drain:             drain,
+			verifyArtifacts:   verifyArtifacts,
+			sniffContentType:  sniffContentType,
+			persistStderr:     persistStderr,
+			stderrTail:        stderrTail,
+			spillThreshold:    spillThreshold,
+			maxFrameBytes:     maxFrameBytes,
+			decodeLimits:      decodeLimits,
+			metricsServer:     metricsServer,
+			preRunHook:        preRunHook,
+			labels:            runMeta.Labels,
+			adapter:           notifier,
+			artifacts:         artifactPub,
+		}
+		if fanOut.warmupScript != "" {
+			if err := warmupFanOut(ctx, rootConfig, factory, fanOut.warmupScript, c.Bool("quiet")); err != nil {
+				return cli.Exit(err.Error(), exitCodes.executorCrash)
+			}
+		}
+		if fanOut.inputURLs != "" {
+			return runSeededFanOut(ctx, fanOut, seeds, rootConfig, factory, finalizer)
 		}
 		return runWithFanOut(ctx, fanOut, rootConfig, factory, finalizer)
 	}
@@ -891,8 +2265,122 @@ func runAction(c *cli.Context) error {
 		return fmt.Errorf("execution failed: %w", err)
 	}
 
-	finalizer.Finalize(result)
-	return cli.Exit("", outcomeToExitCode(result.Outcome.Status))
+	// --max-attempts: each retry is a new run in its own partition, linked
+	// to the failed attempt via parent_run_id. The last attempt's outcome
+	// is final. endAttempt folds a finished attempt's collector into the
+	// metrics server total and closes a retry attempt's policy (the first
+	// attempt's policy closes with its defer above).
+	endAttempt := func() { metricsServer.Retire(collector) }
+	defer func() {
+		if endAttempt != nil {
+			endAttempt()
+		}
+	}()
+	for made := 1; attempts.shouldRetry(result.Outcome, made) && ctx.Err() == nil; made++ {
+		finalizer.finalizeAttempt(result)
+		endAttempt()
+		endAttempt = nil
+
+		nextRunID, err := runtime.NewRandomRunID(clk.Now())
+		if err != nil {
+			return fmt.Errorf("failed to generate retry run ID: %w", err)
+		}
+		nextMeta := nextAttemptMeta(result.RunMeta, nextRunID)
+		if !c.Bool("quiet") {
+			fmt.Fprintf(os.Stderr, "Attempt %d/%d failed (%s/%s); retrying as run %s (attempt %d)\n",
+				made, attempts.maxAttempts, result.Outcome.Status, result.Outcome.Reason, nextMeta.RunID, nextMeta.Attempt)
+		}
+
+		attemptCollector := metrics.NewCollector(choice.name, filepath.Base(executorPath), storageConfig.backend, nextMeta.RunID, jobID)
+		attemptCollector.SetLabels(nextMeta.Labels)
+		metricsServer.Register(attemptCollector)
+		attemptStart := clk.Now()
+		attemptPol, attemptLodeClient, attemptFileWriter, err := buildPolicy(choice, storageConfig, storageDataset, source, category, nextMeta.RunID, attemptStart, attemptCollector, eventSinks)
+		if err != nil {
+			metricsServer.Retire(attemptCollector)
+			return fmt.Errorf("failed to create policy: %w", err)
+		}
+		endAttempt = func() {
+			iox.DiscardClose(attemptPol)
+			metricsServer.Retire(attemptCollector)
+		}
+
+		// Like fan-out retries, draw a fresh endpoint rather than the one that failed
+		attemptConfig := *rootConfig
+		if proxySel != nil {
+			endpoint, err := proxySel.reselect(result.ProxyUsed)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("proxy reselection for attempt %d failed: %v", nextMeta.Attempt, err), exitCodes.executorCrash)
+			}
+			attemptConfig.Proxy = endpoint
+		}
+		attemptConfig.RunMeta = nextMeta
+		attemptConfig.Policy = attemptPol
+		attemptConfig.FileWriter = attemptFileWriter
+		attemptConfig.Collector = attemptCollector
+		attemptConfig.StorageDay = storageConfig.partitionDay(attemptStart)
+		attemptConfig.StorageHour = storageConfig.partitionHour(attemptStart)
+		attemptArtifacts := artifactPub.forRun(nextMeta, source, category, attemptConfig.StorageDay, attemptConfig.StorageHour)
+		attemptConfig.ArtifactObserver = attemptArtifacts.observer()
+
+		orchestrator, err := runtime.NewRunOrchestrator(&attemptConfig)
+		if err != nil {
+			return fmt.Errorf("failed to create orchestrator: %w", err)
+		}
+		result, err = orchestrator.Execute(ctx)
+		if err != nil {
+			return fmt.Errorf("execution failed: %w", err)
+		}
+		finalizer.lodeClient = attemptLodeClient
+		finalizer.collector = attemptCollector
+		finalizer.startTime = attemptStart
+		finalizer.artifacts = attemptArtifacts
+	}
+
+	finalizer.Finalize(result, nil)
+	return cli.Exit("", finalizer.finish(result, nil))
+}
+
+// handleShutdownSignals implements graceful shutdown. With grace == 0 the
+// first signal cancels ctx immediately and drain (nil) is unused.
+// Otherwise the first signal closes drain (ingestion stops after the
+// in-flight frame, then the policy is flushed and the run finalized), and
+// ctx is canceled when the grace period elapses or a second signal arrives.
+// Every cancel carries a runtime.CancelCause with reason interrupted.
+// Returns when ctx is done.
+func handleShutdownSignals(ctx context.Context, sigCh <-chan os.Signal, grace time.Duration, drain chan<- struct{}, cancel context.CancelCauseFunc) {
+	var first os.Signal
+	select {
+	case first = <-sigCh:
+		if grace <= 0 {
+			cancel(interruptedCause("received %s", first))
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Received %s: draining for up to %s (signal again to force)\n", first, grace)
+		close(drain)
+	case <-ctx.Done():
+		return
+	}
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case sig := <-sigCh:
+		fmt.Fprintf(os.Stderr, "Received %s again: canceling run\n", sig)
+		cancel(interruptedCause("received %s again during drain", sig))
+	case <-timer.C:
+		fmt.Fprintf(os.Stderr, "Warning: shutdown grace %s elapsed: canceling run\n", grace)
+		cancel(interruptedCause("received %s, shutdown grace %s elapsed", first, grace))
+	case <-ctx.Done():
+	}
+}
+
+// interruptedCause builds the cancel cause for a signal-initiated stop.
+func interruptedCause(format string, args ...any) *runtime.CancelCause {
+	return &runtime.CancelCause{
+		Reason:  types.ReasonInterrupted,
+		Message: fmt.Sprintf(format, args...),
+	}
 }
 
 // runWithFanOut executes the root run with fan-out scheduling enabled.
@@ -905,12 +2393,7 @@ func runWithFanOut(
 	factory *childFactory,
 	finalizer *runFinalizer,
 ) error {
-	// Create operator
-	operator := runtime.NewOperator(runtime.FanOutConfig{
-		MaxDepth: fanOut.depth,
-		MaxRuns:  fanOut.maxRuns,
-		Parallel: fanOut.parallel,
-	}, factory.Run)
+	operator := newFanOutOperator(fanOut, factory, finalizer)
 
 	// Wire root run's enqueue observer into the operator
 	rootConfig.EnqueueObserver = operator.NewObserver(0)
@@ -927,38 +2410,102 @@ func runWithFanOut(
 	var rootResult *runtime.RunResult
 	var rootErr error
 
+	rootCtx, cancelRoot := context.WithCancelCause(ctx)
+	defer cancelRoot(nil)
 	go func() {
-		rootResult, rootErr = rootOrchestrator.Execute(ctx)
+		rootResult, rootErr = rootOrchestrator.Execute(rootCtx)
 		close(rootDone)
 	}()
 
-	// Operator blocks until root is done + queue drained + workers idle.
+	// Operator blocks until root is done + queue drained + workers idle,
+	// or until --fanout-on-error abort stops it at the first child failure.
 	operator.Run(ctx, rootDone)
 
+	if runID := operator.FirstFailure(); runID != "" {
+		cancelRoot(&runtime.CancelCause{
+			Reason:  types.ReasonCanceled,
+			Message: fmt.Sprintf("fan-out aborted: child %s failed", runID),
+		})
+	}
+	<-rootDone
+
 	if rootErr != nil {
 		return fmt.Errorf("execution failed: %w", rootErr)
 	}
 
-	finalizer.Finalize(rootResult)
+	fanOutResult := operator.Results()
+	finalizer.Finalize(rootResult, &fanOutResult)
 
 	// Print fan-out summary
 	if !finalizer.quiet {
-		fanOutResult := operator.Results()
 		runtime.PrintFanOutSummary(fanOutResult)
 	}
 
 	// Exit code is determined by root run outcome only
-	return cli.Exit("", outcomeToExitCode(rootResult.Outcome.Status))
+	return cli.Exit("", finalizer.finish(rootResult, &fanOutResult))
+}
+
+// runSeededFanOut executes fan-out over an --input-urls seed list. The
+// seeds replace the root run's enqueue observer as the work source: each
+// becomes a depth-1 child running the root script, and the root script
+// itself does not run.
+func runSeededFanOut(
+	ctx context.Context,
+	fanOut fanOutChoice,
+	seeds []map[string]any,
+	rootConfig *runtime.RunConfig,
+	factory *childFactory,
+	finalizer *runFinalizer,
+) error {
+	operator := newFanOutOperator(fanOut, factory, finalizer)
+	for _, params := range seeds {
+		operator.Seed(rootConfig.ScriptPath, params)
+	}
+
+	// No root run: the queue is complete before the workers start
+	rootDone := make(chan struct{})
+	close(rootDone)
+	operator.Run(ctx, rootDone)
+
+	fanOutResult := operator.Results()
+	rootResult := runtime.SeededRootResult(rootConfig, len(seeds))
+	finalizer.Finalize(rootResult, &fanOutResult)
+
+	if !finalizer.quiet {
+		runtime.PrintFanOutSummary(fanOutResult)
+	}
+
+	return cli.Exit("", finalizer.finish(rootResult, &fanOutResult))
+}
+
+// newFanOutOperator creates the fan-out operator for a validated fanOutChoice.
+func newFanOutOperator(fanOut fanOutChoice, factory *childFactory, finalizer *runFinalizer) *runtime.Operator {
+	// Dedupe identity was validated by validateFanOutConfig
+	dedupeBy, _ := runtime.ParseDedupeBy(fanOut.dedupeBy)
+	return runtime.NewOperator(runtime.FanOutConfig{
+		MaxDepth:             fanOut.depth,
+		MaxRuns:              fanOut.maxRuns,
+		Parallel:             fanOut.parallel,
+		MaxBytesPerChild:     fanOut.maxBytesPerChild,
+		MaxArtifactsPerChild: fanOut.maxArtifactsPerChild,
+		DedupeBy:             dedupeBy,
+		DedupeCapacity:       fanOut.dedupeCapacity,
+		Collector:            finalizer.collector,
+		RetryPerItem:         fanOut.retryPerItem,
+		PerOriginConcurrency: fanOut.perOriginConcurrency,
+		OriginStagger:        fanOut.originStagger,
+		AbortOnError:         fanOut.onError == fanOutOnErrorAbort,
+	}, factory.Run)
 }
 
 // runDryRun validates script loadability via the executor's --validate mode.
 // It prints a human-readable summary to stderr and exits 0 (valid) or 1 (invalid).
-func runDryRun(ctx context.Context, executorPath, scriptPath, resolveFrom string) error {
+func runDryRun(ctx context.Context, executorPath, scriptPath string, resolveFrom []string) error {
 	fmt.Fprintf(os.Stderr, "Dry-run validation:\n")
 	fmt.Fprintf(os.Stderr, "  script:   %s\n", scriptPath)
 	fmt.Fprintf(os.Stderr, "  executor: %s\n", executorPath)
-	if resolveFrom != "" {
-		fmt.Fprintf(os.Stderr, "  resolve-from: %s\n", resolveFrom)
+	if len(resolveFrom) > 0 {
+		fmt.Fprintf(os.Stderr, "  resolve-from: %s\n", strings.Join(resolveFrom, ", "))
 	}
 	fmt.Fprintln(os.Stderr)
 
@@ -986,63 +2533,64 @@ func runDryRun(ctx context.Context, executorPath, scriptPath, resolveFrom string
 }
 
 // resolveString returns the CLI flag value if explicitly set, else the config
-// value if non-empty, else the urfave default.
+// value if non-empty, else the urfave default. Like the other resolve helpers,
+// it records the winning source for --explain.
 func resolveString(c *cli.Context, flag string, configVal string) string {
 	if c.IsSet(flag) {
-		return c.String(flag)
+		return explained(c, flag, c.String(flag), sourceFlag)
 	}
 	if configVal != "" {
-		return configVal
+		return explained(c, flag, configVal, sourceConfig)
 	}
-	return c.String(flag)
+	return explained(c, flag, c.String(flag), sourceDefault)
 }
 
 // resolveInt returns the CLI flag value if explicitly set, else the config
 // value if non-zero, else the urfave default.
 func resolveInt(c *cli.Context, flag string, configVal int) int {
 	if c.IsSet(flag) {
-		return c.Int(flag)
+		return explained(c, flag, c.Int(flag), sourceFlag)
 	}
 	if configVal != 0 {
-		return configVal
+		return explained(c, flag, configVal, sourceConfig)
 	}
-	return c.Int(flag)
+	return explained(c, flag, c.Int(flag), sourceDefault)
 }
 
 // resolveInt64 returns the CLI flag value if explicitly set, else the config
 // value if non-zero, else the urfave default.
 func resolveInt64(c *cli.Context, flag string, configVal int64) int64 {
 	if c.IsSet(flag) {
-		return c.Int64(flag)
+		return explained(c, flag, c.Int64(flag), sourceFlag)
 	}
 	if configVal != 0 {
-		return configVal
+		return explained(c, flag, configVal, sourceConfig)
 	}
-	return c.Int64(flag)
+	return explained(c, flag, c.Int64(flag), sourceDefault)
 }
 
 // resolveBool returns the CLI flag value if explicitly set, else the config
 // value if true, else the urfave default.
 func resolveBool(c *cli.Context, flag string, configVal bool) bool {
 	if c.IsSet(flag) {
-		return c.Bool(flag)
+		return explained(c, flag, c.Bool(flag), sourceFlag)
 	}
 	if configVal {
-		return configVal
+		return explained(c, flag, configVal, sourceConfig)
 	}
-	return c.Bool(flag)
+	return explained(c, flag, c.Bool(flag), sourceDefault)
 }
 
 // resolveDuration returns the CLI flag value if explicitly set, else the config
 // value if non-zero, else the urfave default.
 func resolveDuration(c *cli.Context, flag string, configVal time.Duration) time.Duration {
 	if c.IsSet(flag) {
-		return c.Duration(flag)
+		return explained(c, flag, c.Duration(flag), sourceFlag)
 	}
 	if configVal != 0 {
-		return configVal
+		return explained(c, flag, configVal, sourceConfig)
 	}
-	return c.Duration(flag)
+	return explained(c, flag, c.Duration(flag), sourceDefault)
 }
 
 // configVal safely extracts a string value from an optional config.
@@ -1069,6 +2617,14 @@ func configInt64Val(cfg *quarryconfig.Config, fn func(*quarryconfig.Config) int6
 	return fn(cfg)
 }
 
+// configDurationField safely extracts a duration value from an optional config.
+func configDurationField(cfg *quarryconfig.Config, fn func(*quarryconfig.Config) time.Duration) time.Duration {
+	if cfg == nil {
+		return 0
+	}
+	return fn(cfg)
+}
+
 // configBoolVal safely extracts a bool value from an optional config.
 func configBoolVal(cfg *quarryconfig.Config, fn func(*quarryconfig.Config) bool) bool {
 	if cfg == nil {
@@ -1086,61 +2642,412 @@ func parseAdapterConfigWithPrecedence(c *cli.Context, cfg *quarryconfig.Config,
 		headers:     make(map[string]string),
 	}
 
-	// Retries: CLI > config > urfave default
-	if c.IsSet("adapter-retries") {
-		ac.retries = c.Int("adapter-retries")
-	} else if cfg != nil && cfg.Adapter.Retries != nil {
-		ac.retries = *cfg.Adapter.Retries
-	} else {
-		ac.retries = c.Int("adapter-retries")
+	// Retries: CLI > config > urfave default
+	if c.IsSet("adapter-retries") {
+		ac.retries = explained(c, "adapter-retries", c.Int("adapter-retries"), sourceFlag)
+	} else if cfg != nil && cfg.Adapter.Retries != nil {
+		ac.retries = explained(c, "adapter-retries", *cfg.Adapter.Retries, sourceConfig)
+	} else {
+		ac.retries = explained(c, "adapter-retries", c.Int("adapter-retries"), sourceDefault)
+	}
+
+	if ac.retries < 0 {
+		return ac, fmt.Errorf("--adapter-retries must be >= 0, got %d", ac.retries)
+	}
+
+	ac.errorMaxLen = resolveInt(c, "adapter-error-max-len", configIntVal(cfg, func(c *quarryconfig.Config) int { return c.Adapter.ErrorMaxLen }))
+	if ac.errorMaxLen <= 0 {
+		return ac, fmt.Errorf("--adapter-error-max-len must be > 0, got %d", ac.errorMaxLen)
+	}
+
+	ac.clientCert = resolveString(c, "adapter-webhook-client-cert", configVal(cfg, func(c *quarryconfig.Config) string { return c.Adapter.Webhook.ClientCert }))
+	ac.clientKey = resolveString(c, "adapter-webhook-client-key", configVal(cfg, func(c *quarryconfig.Config) string { return c.Adapter.Webhook.ClientKey }))
+	ac.caFile = resolveString(c, "adapter-webhook-ca", configVal(cfg, func(c *quarryconfig.Config) string { return c.Adapter.Webhook.CAFile }))
+	ac.compress = resolveString(c, "adapter-webhook-compress", configVal(cfg, func(c *quarryconfig.Config) string { return c.Adapter.Webhook.Compress }))
+
+	switch ac.adapterType {
+	case "webhook":
+		if ac.url == "" {
+			return ac, errors.New("--adapter-url is required when --adapter=webhook")
+		}
+		// Load the mTLS files now so a bad pair fails validation, not the first publish
+		tlsCheck := webhook.Config{ClientCert: ac.clientCert, ClientKey: ac.clientKey, CAFile: ac.caFile}
+		if _, err := tlsCheck.TLSConfig(); err != nil {
+			return ac, err
+		}
+		if err := webhook.ValidateCompress(ac.compress); err != nil {
+			return ac, fmt.Errorf("invalid --adapter-webhook-compress: %w", err)
+		}
+		ac.retryStatus = c.StringSlice("adapter-webhook-retry-status")
+		retrySource := sourceFlag
+		if !c.IsSet("adapter-webhook-retry-status") {
+			ac.retryStatus, retrySource = nil, sourceDefault
+			if cfg != nil && len(cfg.Adapter.Webhook.RetryStatus) > 0 {
+				ac.retryStatus, retrySource = cfg.Adapter.Webhook.RetryStatus, sourceConfig
+			}
+		}
+		explainFrom(c).record("adapter-webhook-retry-status", ac.retryStatus, retrySource)
+		if err := webhook.ValidateRetryStatus(ac.retryStatus); err != nil {
+			return ac, fmt.Errorf("invalid --adapter-webhook-retry-status: %w", err)
+		}
+	case "redis":
+		if ac.url == "" {
+			return ac, errors.New("--adapter-url is required when --adapter=redis")
+		}
+		ac.channel = resolveString(c, "adapter-channel", configVal(cfg, func(c *quarryconfig.Config) string { return c.Adapter.Channel }))
+		ac.pipeline = resolveBool(c, "adapter-redis-pipeline", configBoolVal(cfg, func(c *quarryconfig.Config) bool { return c.Adapter.Redis.Pipeline }))
+	case "kafka":
+		if ac.url == "" {
+			return ac, errors.New("--adapter-url is required when --adapter=kafka (comma-separated brokers)")
+		}
+		ac.channel = resolveString(c, "adapter-channel", configVal(cfg, func(c *quarryconfig.Config) string { return c.Adapter.Channel }))
+		if cfg != nil {
+			ac.kafka = cfg.Adapter.Kafka
+		}
+	case "file":
+		if ac.url == "" {
+			return ac, errors.New("--adapter-url is required when --adapter=file (outbox file path)")
+		}
+		ac.fileMaxBytes = resolveInt64(c, "adapter-file-max-bytes", configInt64Val(cfg, func(c *quarryconfig.Config) int64 { return c.Adapter.File.MaxBytes }))
+		if ac.fileMaxBytes == 0 || ac.fileMaxBytes < -1 {
+			return ac, fmt.Errorf("--adapter-file-max-bytes must be > 0 or -1 (never rotate), got %d", ac.fileMaxBytes)
+		}
+	default:
+		return ac, fmt.Errorf("unknown adapter type: %q (supported: webhook, redis, kafka, file)", ac.adapterType)
+	}
+
+	encoder, err := resolveAdapterEncoder(c, cfg)
+	if err != nil {
+		return ac, err
+	}
+	ac.encoder = encoder
+
+	ac.onArtifact = resolveBool(c, "adapter-on-artifact", configBoolVal(cfg, func(c *quarryconfig.Config) bool { return c.Adapter.OnArtifact }))
+	ac.artifactRate = resolveInt(c, "adapter-artifact-rate", configIntVal(cfg, func(c *quarryconfig.Config) int { return c.Adapter.ArtifactRate }))
+	if ac.artifactRate < 0 {
+		return ac, fmt.Errorf("--adapter-artifact-rate must be >= 0, got %d", ac.artifactRate)
+	}
+	if !ac.onArtifact && ac.artifactRate > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: --adapter-artifact-rate is ignored without --adapter-on-artifact\n")
+	}
+
+	ac.notifyEarly = resolveBool(c, "notify-early", configBoolVal(cfg, func(c *quarryconfig.Config) bool { return c.Adapter.NotifyEarly }))
+
+	onValues := c.StringSlice("adapter-on")
+	onSource := sourceFlag
+	if !c.IsSet("adapter-on") {
+		onValues, onSource = []string{adapterOnAlways}, sourceDefault
+		if cfg != nil && len(cfg.Adapter.On) > 0 {
+			onValues, onSource = cfg.Adapter.On, sourceConfig
+		}
+	}
+	explainFrom(c).record("adapter-on", onValues, onSource)
+	if ac.on, err = parseAdapterOn(onValues); err != nil {
+		return ac, err
+	}
+
+	// Merge config headers first, then CLI headers override
+	if cfg != nil {
+		for k, v := range cfg.Adapter.Headers {
+			ac.headers[k] = v
+		}
+	}
+	for _, h := range c.StringSlice("adapter-header") {
+		k, v, ok := strings.Cut(h, "=")
+		if !ok || k == "" {
+			return ac, fmt.Errorf("invalid --adapter-header %q: expected key=value", h)
+		}
+		ac.headers[k] = v
+	}
+	if len(ac.headers) > 0 {
+		// Header names only: values commonly carry credentials
+		names := make([]string, 0, len(ac.headers))
+		for k := range ac.headers {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		source := sourceConfig
+		if c.IsSet("adapter-header") {
+			source = sourceFlag
+		}
+		explainFrom(c).record("adapter-header", names, source)
+	}
+
+	// Warn about irrelevant flags for the chosen adapter type
+	if ac.adapterType != "webhook" && len(ac.headers) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: --adapter-header is ignored for %s adapter\n", ac.adapterType)
+	}
+	if ac.adapterType != "kafka" && cfg != nil && cfg.Adapter.Kafka != nil {
+		fmt.Fprintf(os.Stderr, "Warning: adapter.kafka config is ignored for %s adapter\n", ac.adapterType)
+	}
+	if ac.adapterType != "redis" && c.IsSet("adapter-redis-pipeline") {
+		fmt.Fprintf(os.Stderr, "Warning: --adapter-redis-pipeline is ignored for %s adapter\n", ac.adapterType)
+	}
+	if ac.adapterType != "file" && c.IsSet("adapter-file-max-bytes") {
+		fmt.Fprintf(os.Stderr, "Warning: --adapter-file-max-bytes is ignored for %s adapter\n", ac.adapterType)
+	}
+	if ac.adapterType != "webhook" && (ac.clientCert != "" || ac.clientKey != "" || ac.caFile != "") {
+		fmt.Fprintf(os.Stderr, "Warning: --adapter-webhook-client-cert/--adapter-webhook-client-key/--adapter-webhook-ca are ignored for %s adapter\n", ac.adapterType)
+	}
+	if ac.adapterType != "webhook" && ac.compress != "" {
+		fmt.Fprintf(os.Stderr, "Warning: --adapter-webhook-compress is ignored for %s adapter\n", ac.adapterType)
+	}
+
+	return ac, nil
+}
+
+// --adapter-on shorthands.
+const (
+	adapterOnAlways  = "always"
+	adapterOnSuccess = "success"
+	adapterOnFailure = "failure"
+)
+
+// failureOutcomes are the statuses --adapter-on failure expands to.
+var failureOutcomes = []types.OutcomeStatus{
+	types.OutcomeScriptError,
+	types.OutcomeExecutorCrash,
+	types.OutcomePolicyFailure,
+	types.OutcomeVersionMismatch,
+}
+
+// parseAdapterOn parses --adapter-on values into the set of outcome
+// statuses that publish run_completed. "always" anywhere in the list
+// returns nil (publish for every outcome); "failure" expands to every
+// non-success status.
+func parseAdapterOn(values []string) (map[types.OutcomeStatus]bool, error) {
+	on := make(map[types.OutcomeStatus]bool)
+	for _, v := range values {
+		switch v = strings.TrimSpace(v); v {
+		case adapterOnAlways:
+			return nil, nil
+		case adapterOnFailure:
+			for _, status := range failureOutcomes {
+				on[status] = true
+			}
+		case adapterOnSuccess:
+			on[types.OutcomeSuccess] = true
+		default:
+			status := types.OutcomeStatus(v)
+			if !slices.Contains(failureOutcomes, status) {
+				return nil, fmt.Errorf("invalid --adapter-on %q (supported: always, success, failure, script_error, executor_crash, policy_failure, version_mismatch)", v)
+			}
+			on[status] = true
+		}
+	}
+	if len(on) == 0 {
+		return nil, errors.New("--adapter-on must list at least one outcome")
+	}
+	return on, nil
+}
+
+// notifies reports whether a run with status publishes run_completed.
+func (ac *adapterChoice) notifies(status types.OutcomeStatus) bool {
+	return ac.on == nil || ac.on[status]
+}
+
+// resolveAdapterEncoder builds the payload encoder from --adapter-template
+// or --adapter-field (CLI > config). Templates are parsed and test-rendered
+// here so a bad template fails before the run. Returns nil for the
+// canonical shape.
+func resolveAdapterEncoder(c *cli.Context, cfg *quarryconfig.Config) (adapter.Encoder, error) {
+	text := resolveString(c, "adapter-template", configVal(cfg, func(c *quarryconfig.Config) string { return c.Adapter.Template }))
+
+	fields := make(map[string]string)
+	source := sourceDefault
+	if c.IsSet("adapter-field") {
+		for _, f := range c.StringSlice("adapter-field") {
+			k, v, ok := strings.Cut(f, "=")
+			if !ok || k == "" {
+				return nil, fmt.Errorf("invalid --adapter-field %q: expected output=event_field", f)
+			}
+			fields[k] = v
+		}
+		source = sourceFlag
+	} else if cfg != nil && len(cfg.Adapter.Fields) > 0 {
+		maps.Copy(fields, cfg.Adapter.Fields)
+		source = sourceConfig
+	}
+	if len(fields) > 0 {
+		explainFrom(c).record("adapter-field", fields, source)
+	}
+
+	switch {
+	case text != "" && len(fields) > 0:
+		return nil, errors.New("--adapter-template and --adapter-field are mutually exclusive")
+	case text != "":
+		enc, err := adapter.NewTemplate(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --adapter-template: %w", err)
+		}
+		return enc, nil
+	case len(fields) > 0:
+		enc, err := adapter.NewFieldMap(fields)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --adapter-field: %w", err)
+		}
+		return enc, nil
+	default:
+		return nil, nil
+	}
+}
+
+// configDurationVal extracts the adapter timeout duration from config.
+func configDurationVal(cfg *quarryconfig.Config) time.Duration {
+	if cfg == nil {
+		return 0
+	}
+	return cfg.Adapter.Timeout.Duration
+}
+
+// resolveDomainPolicy resolves --allow-domain and --deny-domain over the
+// config domain_policy block, each list independently (CLI > config).
+// Returns nil when neither list is set.
+func resolveDomainPolicy(c *cli.Context, cfg *quarryconfig.Config) (*runtime.DomainPolicy, error) {
+	var fromConfig quarryconfig.DomainPolicyConfig
+	if cfg != nil {
+		fromConfig = cfg.DomainPolicy
+	}
+	resolveList := func(flag string, configured []string) []string {
+		if c.IsSet(flag) {
+			explainFrom(c).record(flag, c.StringSlice(flag), sourceFlag)
+			return c.StringSlice(flag)
+		}
+		if len(configured) > 0 {
+			explainFrom(c).record(flag, configured, sourceConfig)
+			return configured
+		}
+		explainFrom(c).record(flag, []string(nil), sourceDefault)
+		return nil
+	}
+	allow := resolveList("allow-domain", fromConfig.Allow)
+	deny := resolveList("deny-domain", fromConfig.Deny)
+	domainPolicy, err := runtime.NewDomainPolicy(allow, deny)
+	if err != nil {
+		return nil, fmt.Errorf("invalid domain policy: %w", err)
+	}
+	return domainPolicy, nil
+}
+
+// resolvePreRunHook resolves --pre-run-hook and its timeout (CLI > config).
+// Returns nil when no hook is configured.
+func resolvePreRunHook(c *cli.Context, cfg *quarryconfig.Config) (*runtime.PreRunHook, error) {
+	command := resolveString(c, "pre-run-hook", configVal(cfg, func(c *quarryconfig.Config) string { return c.PreRunHook }))
+	timeout := resolveDuration(c, "pre-run-hook-timeout", configDurationField(cfg, func(c *quarryconfig.Config) time.Duration { return c.PreRunHookTimeout.Duration }))
+	if timeout <= 0 {
+		return nil, fmt.Errorf("--pre-run-hook-timeout must be > 0, got %s", timeout)
 	}
+	if strings.TrimSpace(command) == "" {
+		return nil, nil
+	}
+	return &runtime.PreRunHook{Command: command, Timeout: timeout}, nil
+}
 
-	if ac.retries < 0 {
-		return ac, fmt.Errorf("--adapter-retries must be >= 0, got %d", ac.retries)
+// resolveOnCompleteHook resolves --on-complete, its timeout, and
+// --on-complete-required (CLI > config). Returns nil when no hook is
+// configured.
+func resolveOnCompleteHook(c *cli.Context, cfg *quarryconfig.Config) (*runtime.OnCompleteHook, error) {
+	command := resolveString(c, "on-complete", configVal(cfg, func(c *quarryconfig.Config) string { return c.OnComplete }))
+	timeout := resolveDuration(c, "on-complete-timeout", configDurationField(cfg, func(c *quarryconfig.Config) time.Duration { return c.OnCompleteTimeout.Duration }))
+	required := resolveBool(c, "on-complete-required", configBoolVal(cfg, func(c *quarryconfig.Config) bool { return c.OnCompleteRequired }))
+	if timeout <= 0 {
+		return nil, fmt.Errorf("--on-complete-timeout must be > 0, got %s", timeout)
+	}
+	if strings.TrimSpace(command) == "" {
+		if required {
+			fmt.Fprintf(os.Stderr, "Warning: --on-complete-required is ignored without --on-complete\n")
+		}
+		return nil, nil
 	}
+	return &runtime.OnCompleteHook{Command: command, Timeout: timeout, Required: required, Output: os.Stderr}, nil
+}
 
-	switch ac.adapterType {
-	case "webhook":
-		if ac.url == "" {
-			return ac, errors.New("--adapter-url is required when --adapter=webhook")
+// resolveTenant resolves and validates --tenant. With require_tenant set in
+// the config, a missing tenant is an error; tenant_pattern, when set, is an
+// allowlist the whole ID must match.
+func resolveTenant(c *cli.Context, cfg *quarryconfig.Config) (string, error) {
+	tenant := resolveString(c, "tenant", configVal(cfg, func(c *quarryconfig.Config) string { return c.Tenant }))
+	if tenant == "" {
+		if cfg != nil && cfg.RequireTenant {
+			return "", errors.New("--tenant is required (config sets require_tenant: true)")
 		}
-	case "redis":
-		if ac.url == "" {
-			return ac, errors.New("--adapter-url is required when --adapter=redis")
+		return "", nil
+	}
+
+	var allow *regexp.Regexp
+	if cfg != nil && cfg.TenantPattern != "" {
+		var err error
+		if allow, err = lode.CompileTenantPattern(cfg.TenantPattern); err != nil {
+			return "", fmt.Errorf("invalid tenant_pattern: %w", err)
 		}
-		ac.channel = resolveString(c, "adapter-channel", configVal(cfg, func(c *quarryconfig.Config) string { return c.Adapter.Channel }))
-	default:
-		return ac, fmt.Errorf("unknown adapter type: %q (supported: webhook, redis)", ac.adapterType)
 	}
+	if err := lode.ValidateTenant(tenant, allow); err != nil {
+		return "", fmt.Errorf("invalid --tenant: %w", err)
+	}
+	return tenant, nil
+}
 
-	// Merge config headers first, then CLI headers override
+// resolveLabels merges config labels with --label flags (CLI overrides per
+// key). Returns nil when no labels are set.
+func resolveLabels(c *cli.Context, cfg *quarryconfig.Config) (map[string]string, error) {
+	var configLabels map[string]string
 	if cfg != nil {
-		for k, v := range cfg.Adapter.Headers {
-			ac.headers[k] = v
-		}
+		configLabels = cfg.Labels
 	}
-	for _, h := range c.StringSlice("adapter-header") {
-		k, v, ok := strings.Cut(h, "=")
-		if !ok || k == "" {
-			return ac, fmt.Errorf("invalid --adapter-header %q: expected key=value", h)
-		}
-		ac.headers[k] = v
+	labels, err := parseLabels(configLabels, c.StringSlice("label"))
+	if err != nil || labels == nil {
+		return nil, err
 	}
 
-	// Warn about irrelevant flags for the chosen adapter type
-	if ac.adapterType == "redis" && len(ac.headers) > 0 {
-		fmt.Fprintf(os.Stderr, "Warning: --adapter-header is ignored for redis adapter\n")
+	pairs := make([]string, 0, len(labels))
+	for k, v := range labels {
+		pairs = append(pairs, k+"="+v)
 	}
+	sort.Strings(pairs)
+	source := sourceConfig
+	if c.IsSet("label") {
+		source = sourceFlag
+	}
+	explainFrom(c).record("label", pairs, source)
+	return labels, nil
+}
 
-	return ac, nil
+// parseLabels merges configLabels with key=value flag values and validates
+// the result. Returns nil when both are empty.
+func parseLabels(configLabels map[string]string, flags []string) (map[string]string, error) {
+	if len(configLabels) == 0 && len(flags) == 0 {
+		return nil, nil
+	}
+	labels := make(map[string]string, len(configLabels)+len(flags))
+	for k, v := range configLabels {
+		labels[k] = v
+	}
+	for _, l := range flags {
+		k, v, ok := strings.Cut(l, "=")
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid --label %q: expected key=value", l)
+		}
+		labels[k] = v
+	}
+	if err := types.ValidateLabels(labels); err != nil {
+		return nil, fmt.Errorf("invalid --label: %w", err)
+	}
+	return labels, nil
 }
 
-// configDurationVal extracts the adapter timeout duration from config.
-func configDurationVal(cfg *quarryconfig.Config) time.Duration {
-	if cfg == nil {
-		return 0
+// parseIngestMode maps the --events-only / --artifacts-only flags to an
+// ingestion mode. The flags are mutually exclusive.
+func parseIngestMode(eventsOnly, artifactsOnly bool) (runtime.IngestMode, error) {
+	switch {
+	case eventsOnly && artifactsOnly:
+		return runtime.IngestAll, errors.New("--events-only and --artifacts-only are mutually exclusive")
+	case eventsOnly:
+		return runtime.IngestEventsOnly, nil
+	case artifactsOnly:
+		return runtime.IngestArtifactsOnly, nil
+	default:
+		return runtime.IngestAll, nil
 	}
-	return cfg.Adapter.Timeout.Duration
 }
 
 // configPolicyDurationVal extracts the policy flush interval from config.
@@ -1152,9 +3059,34 @@ func configPolicyDurationVal(cfg *quarryconfig.Config) time.Duration {
 }
 
 func validatePolicyConfig(choice policyChoice) error {
+	if choice.batchSize < 0 {
+		return fmt.Errorf("--events-batch-size must be >= 0, got %d", choice.batchSize)
+	}
+	if choice.batchWindow < 0 {
+		return fmt.Errorf("--strict-batch-window must be >= 0, got %s", choice.batchWindow)
+	}
+	if (choice.name == "buffered" || choice.name == "streaming") && (choice.batchSize > 0 || choice.batchWindow > 0) {
+		fmt.Fprintf(os.Stderr, "Warning: --events-batch-size and --strict-batch-window are ignored for %s policy\n", choice.name)
+	}
+	if choice.degradeBuffer < 0 {
+		return fmt.Errorf("--strict-degrade-buffer must be >= 0, got %d", choice.degradeBuffer)
+	}
+	if choice.degradeBuffer > 0 && choice.name != "strict" {
+		fmt.Fprintf(os.Stderr, "Warning: --strict-degrade-buffer is ignored for %s policy (strict only)\n", choice.name)
+	}
+	if choice.flushIdle < 0 {
+		return fmt.Errorf("--flush-idle must be >= 0, got %s", choice.flushIdle)
+	}
+	if choice.flushSeq && choice.name != "streaming" {
+		fmt.Fprintf(os.Stderr, "Warning: --flush-seq is ignored for %s policy (streaming only)\n", choice.name)
+	}
+	if choice.flushIdle > 0 && choice.name != "streaming" {
+		fmt.Fprintf(os.Stderr, "Warning: --flush-idle is ignored for %s policy (streaming only)\n", choice.name)
+	}
+
 	switch choice.name {
 	case "strict":
-		if choice.maxEvents > 0 || choice.maxBytes > 0 || choice.flushMode != "at_least_once" {
+		if choice.maxEvents > 0 || choice.maxBytes > 0 || choice.flushMode != "at_least_once" || choice.parallelFlush {
 			fmt.Fprintf(os.Stderr, "Warning: buffer/flush flags ignored for strict policy\n")
 		}
 		return nil
@@ -1169,6 +3101,9 @@ Add one or both of:
 		}
 		switch policy.FlushMode(choice.flushMode) {
 		case policy.FlushAtLeastOnce, policy.FlushChunksFirst, policy.FlushTwoPhase:
+			if choice.parallelFlush && policy.FlushMode(choice.flushMode) != policy.FlushTwoPhase {
+				return fmt.Errorf("--parallel-flush requires --flush-mode two_phase, got %q", choice.flushMode)
+			}
 			return nil
 		default:
 			return fmt.Errorf(`invalid --flush-mode: %q
@@ -1180,15 +3115,16 @@ Valid options:
 		}
 
 	case "streaming":
-		if choice.flushCount <= 0 && choice.flushInterval <= 0 {
+		if choice.flushCount <= 0 && choice.flushInterval <= 0 && choice.flushIdle <= 0 {
 			return fmt.Errorf(`streaming policy requires at least one flush trigger
 
-Add one or both of:
+Add one or more of:
   --flush-count <n>       Flush after N events (e.g., --flush-count 100)
-  --flush-interval <d>    Flush every duration (e.g., --flush-interval 5s)`)
+  --flush-interval <d>    Flush every duration (e.g., --flush-interval 5s)
+  --flush-idle <d>        Flush after a quiet period (e.g., --flush-idle 2s)`)
 		}
 		// Warn about irrelevant buffered flags
-		if choice.maxEvents > 0 || choice.maxBytes > 0 || choice.flushMode != "at_least_once" {
+		if choice.maxEvents > 0 || choice.maxBytes > 0 || choice.flushMode != "at_least_once" || choice.parallelFlush {
 			fmt.Fprintf(os.Stderr, "Warning: buffer/flush-mode flags ignored for streaming policy\n")
 		}
 		return nil
@@ -1209,6 +3145,9 @@ func validateStorageConfig(config storageChoice) error {
 		if config.endpoint != "" || config.usePathStyle {
 			fmt.Fprintf(os.Stderr, "Warning: --storage-endpoint and --storage-s3-path-style are ignored for fs backend\n")
 		}
+		if config.sse != "" || config.kmsKeyID != "" || config.storageClass != "" || config.skipExisting {
+			fmt.Fprintf(os.Stderr, "Warning: --storage-s3-sse, --storage-s3-kms-key, --storage-s3-storage-class and --storage-s3-skip-existing are ignored for fs backend\n")
+		}
 		// Validate path exists and is a directory
 		info, err := os.Stat(config.path)
 		if os.IsNotExist(err) {
@@ -1233,15 +3172,45 @@ Create the directory first:
 Format: bucket-name/optional-prefix
 Example: --storage-path my-bucket/quarry-data`)
 		}
+		opts := lode.S3Config{SSE: config.sse, KMSKeyID: config.kmsKeyID, StorageClass: config.storageClass}
+		if err := opts.ValidateWriteOptions(); err != nil {
+			return err
+		}
 		// S3 credentials are validated at runtime by AWS SDK
 		return nil
 
+	case "memory":
+		if config.path != "" {
+			fmt.Fprintf(os.Stderr, "Warning: --storage-path is ignored for memory backend\n")
+		}
+		if config.endpoint != "" || config.usePathStyle || config.sse != "" || config.kmsKeyID != "" || config.storageClass != "" || config.skipExisting {
+			fmt.Fprintf(os.Stderr, "Warning: --storage-endpoint and --storage-s3-* options are ignored for memory backend\n")
+		}
+		fmt.Fprintf(os.Stderr, "Warning: memory storage backend: run data is not persisted and is discarded at exit\n")
+		return nil
+
 	default:
 		return fmt.Errorf(`invalid --storage-backend: %q
 
 Valid options:
-  fs   Filesystem storage (requires writable directory)
-  s3   Amazon S3 storage (requires AWS credentials)`, config.backend)
+  fs       Filesystem storage (requires writable directory)
+  s3       Amazon S3 storage (requires AWS credentials)
+  memory   In-process storage, discarded at exit (smoke tests)`, config.backend)
+	}
+}
+
+// resolveRunID expands the --run-id keywords auto and random; any other
+// value is used as given. auto hashes the user job payload before
+// resume_state or shared_state injection, so quarry gen-run-id with the
+// same inputs yields the same ID.
+func resolveRunID(flag, source, category, day string, job map[string]any, now time.Time) (string, error) {
+	switch flag {
+	case runtime.RunIDAuto:
+		return runtime.DeriveRunID(source, category, day, job)
+	case runtime.RunIDRandom:
+		return runtime.NewRandomRunID(now)
+	default:
+		return flag, nil
 	}
 }
 
@@ -1343,6 +3312,132 @@ Received: %s`, describeJSONType(raw))
 	return map[string]any{}, nil
 }
 
+// parseSeedState reads the --seed-state file, which must hold a JSON
+// object. Returns nil when path is empty.
+func parseSeedState(path string) (map[string]any, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("--seed-state: file not found: %s", path)
+		}
+		return nil, fmt.Errorf("--seed-state: cannot read %q: %v", path, err)
+	}
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("--seed-state: malformed JSON in %s: %v", path, err)
+	}
+	state, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("--seed-state: %s must contain a JSON object, got %s", path, describeJSONType(raw))
+	}
+	return state, nil
+}
+
+// resumeStateKey is the job payload key that carries a prior checkpoint
+// under --since-checkpoint.
+const resumeStateKey = "resume_state"
+
+// injectResumeState sets job[resume_state] to the final checkpoint payload
+// of the latest prior run for source/category. With no prior checkpoint the
+// job runs fresh. An explicit resume_state in the job payload wins.
+func injectResumeState(ctx context.Context, job map[string]any, storage storageChoice, dataset, source, category, runID string, quiet bool) (map[string]any, error) {
+	if _, ok := job[resumeStateKey]; ok {
+		fmt.Fprintf(os.Stderr, "Warning: job payload already sets %s; --since-checkpoint ignored\n", resumeStateKey)
+		return job, nil
+	}
+
+	ds, err := buildCheckpointReadDataset(storage, dataset)
+	if err != nil {
+		return nil, fmt.Errorf("--since-checkpoint: failed to open dataset: %w", err)
+	}
+	checkpoint, err := lode.QueryLatestCheckpoint(ctx, ds, storage.tenant, source, category, runID)
+	if errors.Is(err, lode.ErrNoCheckpointFound) {
+		if !quiet {
+			fmt.Fprintf(os.Stderr, "No prior checkpoint for source=%s category=%s; running fresh\n", source, category)
+		}
+		return job, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("--since-checkpoint: failed to read prior checkpoint: %w", err)
+	}
+
+	if job == nil {
+		job = map[string]any{}
+	}
+	job[resumeStateKey] = checkpoint.Payload
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "Resuming from checkpoint of run %s (seq %d)\n", checkpoint.RunID, checkpoint.Seq)
+	}
+	return job, nil
+}
+
+// buildCheckpointReadDataset opens the run's storage for reading, with the
+// same S3 endpoint and egress proxy settings the run writes with.
+func buildCheckpointReadDataset(storage storageChoice, dataset string) (lodelibrary.Dataset, error) {
+	if storage.backend != "s3" {
+		return buildReadDataset(dataset, storage.backend, storage.path, storage.region)
+	}
+	bucket, prefix := lode.ParseS3Path(storage.path)
+	return lode.NewReadDatasetS3(dataset, lode.S3Config{
+		Bucket:       bucket,
+		Prefix:       prefix,
+		Region:       storage.region,
+		Endpoint:     storage.endpoint,
+		UsePathStyle: storage.usePathStyle,
+		EgressProxy:  storage.egressProxy,
+	})
+}
+
+// validateJobSchema validates a parsed job payload against a JSON Schema file.
+// Errors are path-qualified (JSON pointer into the job object).
+func validateJobSchema(job map[string]any, schemaPath string) error {
+	absPath, err := filepath.Abs(schemaPath)
+	if err != nil {
+		return fmt.Errorf("--job-schema: cannot resolve path %q: %v", schemaPath, err)
+	}
+	if _, err := os.Stat(absPath); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("--job-schema: schema file not found: %s", absPath)
+		}
+		return fmt.Errorf("--job-schema: cannot access %q: %v", absPath, err)
+	}
+
+	schema, err := jsonschema.NewCompiler().Compile(absPath)
+	if err != nil {
+		return fmt.Errorf("--job-schema: invalid schema %s: %v", absPath, err)
+	}
+
+	if err := schema.Validate(map[string]any(job)); err != nil {
+		var verr *jsonschema.ValidationError
+		if !errors.As(err, &verr) {
+			return fmt.Errorf("job payload validation failed: %v", err)
+		}
+		return fmt.Errorf("job payload does not conform to schema %s:\n%s", absPath, formatSchemaErrors(verr))
+	}
+	return nil
+}
+
+// formatSchemaErrors renders leaf validation errors as "  at /path: message" lines.
+func formatSchemaErrors(verr *jsonschema.ValidationError) string {
+	printer := message.NewPrinter(language.English)
+	var lines []string
+	var walk func(e *jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) == 0 {
+			lines = append(lines, fmt.Sprintf("  at /%s: %s", strings.Join(e.InstanceLocation, "/"), e.ErrorKind.LocalizedString(printer)))
+			return
+		}
+		for _, cause := range e.Causes {
+			walk(cause)
+		}
+	}
+	walk(verr)
+	return strings.Join(lines, "\n")
+}
+
 // describeJSONType returns a human-readable description of a JSON value's type.
 func describeJSONType(v any) string {
 	switch v := v.(type) {
@@ -1441,13 +3536,21 @@ func buildPolicy(choice policyChoice, storageConfig storageChoice, dataset, sour
 
 	switch choice.name {
 	case "strict":
-		return policy.NewStrictPolicy(sink), client, fw, nil
+		config := policy.StrictConfig{
+			BatchSize:     choice.batchSize,
+			BatchWindow:   choice.batchWindow,
+			DegradeBuffer: choice.degradeBuffer,
+			Retryable:     lode.IsRetryable,
+			Clock:         choice.clock,
+		}
+		return policy.NewStrictPolicyWithConfig(sink, config), client, fw, nil
 
 	case "buffered":
 		config := policy.BufferedConfig{
 			MaxBufferEvents: choice.maxEvents,
 			MaxBufferBytes:  choice.maxBytes,
 			FlushMode:       policy.FlushMode(choice.flushMode),
+			ParallelFlush:   choice.parallelFlush,
 		}
 		p, err := policy.NewBufferedPolicy(sink, config)
 		return p, client, fw, err
@@ -1456,6 +3559,11 @@ func buildPolicy(choice policyChoice, storageConfig storageChoice, dataset, sour
 		config := policy.StreamingConfig{
 			FlushCount:    choice.flushCount,
 			FlushInterval: choice.flushInterval,
+			FlushIdle:     choice.flushIdle,
+			Clock:         choice.clock,
+		}
+		if choice.flushSeq {
+			config.FlushSequence = fw
 		}
 		p, err := policy.NewStreamingPolicy(sink, config)
 		return p, client, fw, err
@@ -1470,28 +3578,78 @@ func buildPolicy(choice policyChoice, storageConfig storageChoice, dataset, sour
 // If collector is non-nil, wraps the sink with metrics instrumentation.
 // Returns the sink, the underlying client (for metrics persistence),
 // a FileWriter for sidecar file uploads, and any error.
-func buildStorageSink(storageConfig storageChoice, dataset, source, category, runID, policy string, startTime time.Time, collector *metrics.Collector) (policy.Sink, lode.Client, lode.FileWriter, error) {
+func buildStorageSink(storageConfig storageChoice, dataset, source, category, runID, policyName string, startTime time.Time, collector *metrics.Collector) (policy.Sink, lode.Client, lode.FileWriter, error) {
 	// Build Lode config with partition keys
 	cfg := lode.Config{
 		Dataset:  dataset,
+		Tenant:   storageConfig.tenant,
 		Source:   source,
 		Category: category,
-		Day:      lode.DeriveDay(startTime),
+		Day:      storageConfig.partitionDay(startTime),
+		Hour:     storageConfig.partitionHour(startTime),
 		RunID:    runID,
-		Policy:   policy,
+		Policy:   policyName,
+
+		PartitionTemplate: storageConfig.partitionTemplate,
+		PartitionManifest: storageConfig.partitionManifest,
+		Format:            storageConfig.format,
 	}
 
 	// LodeClient implements both lode.Client and lode.FileWriter.
 	// Capture as concrete type so we can return both interfaces.
-	var lc *lode.LodeClient
-	var err error
+	lc, err := newLodeClient(cfg, storageConfig, collector)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var sink policy.Sink = lode.NewSink(cfg, lc)
+	if collector != nil {
+		sink = lode.NewInstrumentedSink(sink, collector)
+	}
+	if len(storageConfig.sinks) == 0 {
+		return sink, lc, lc, nil
+	}
+
+	// Tee: the primary keeps the metrics, manifest, and sidecar role; extra
+	// sinks receive the same event and chunk writes
+	entries := []policy.TeeEntry{{Sink: sink, Label: storageSinkLabel(storageConfig.backend, storageConfig.path)}}
+	for _, spec := range storageConfig.sinks {
+		extra := storageConfig
+		extra.backend, extra.path = spec.backend, spec.path
+		extraClient, err := newLodeClient(cfg, extra, nil)
+		if err != nil {
+			for _, e := range entries {
+				iox.DiscardClose(e.Sink)
+			}
+			return nil, nil, nil, fmt.Errorf("--storage-sink %s: %w", storageSinkLabel(spec.backend, spec.path), err)
+		}
+		entries = append(entries, policy.TeeEntry{Sink: lode.NewSink(cfg, extraClient), Label: storageSinkLabel(spec.backend, spec.path)})
+	}
+	var observer policy.TeeObserver
+	if collector != nil {
+		observer = collector
+	}
+	tee, err := policy.NewTeeSink(entries, storageConfig.sinkQuorum, observer)
+	if err != nil {
+		for _, e := range entries {
+			iox.DiscardClose(e.Sink)
+		}
+		return nil, nil, nil, err
+	}
+	return tee, lc, lc, nil
+}
 
+// newLodeClient creates the Lode client for storageConfig's backend and path.
+// S3 writes skipped by --storage-s3-skip-existing are counted on collector,
+// which may be nil.
+func newLodeClient(cfg lode.Config, storageConfig storageChoice, collector *metrics.Collector) (*lode.LodeClient, error) {
 	switch storageConfig.backend {
 	case "fs":
-		lc, err = lode.NewLodeClient(cfg, storageConfig.path)
+		lc, err := lode.NewLodeClient(cfg, storageConfig.path)
 		if err != nil {
-			return nil, nil, nil, fmt.Errorf("filesystem storage initialization failed: %w (ensure directory %s exists and is writable)", err, storageConfig.path)
+			return nil, fmt.Errorf("filesystem storage initialization failed: %w (ensure directory %s exists and is writable)", err, storageConfig.path)
 		}
+		return lc, nil
 	case "s3":
 		bucket, prefix := lode.ParseS3Path(storageConfig.path)
 		s3cfg := lode.S3Config{
@@ -1500,21 +3658,57 @@ func buildStorageSink(storageConfig storageChoice, dataset, source, category, ru
 			Region:       storageConfig.region,
 			Endpoint:     storageConfig.endpoint,
 			UsePathStyle: storageConfig.usePathStyle,
+			SSE:          storageConfig.sse,
+			KMSKeyID:     storageConfig.kmsKeyID,
+			StorageClass: storageConfig.storageClass,
+			EgressProxy:  storageConfig.egressProxy,
+			SkipExisting: storageConfig.skipExisting,
+		}
+		if storageConfig.skipExisting {
+			s3cfg.OnWriteSkipped = collector.IncLodeWriteSkipped
+		}
+		lc, err := lode.NewLodeS3Client(cfg, s3cfg)
+		if err != nil {
+			return nil, fmt.Errorf("S3 storage initialization failed: %w (check AWS credentials and bucket permissions)", err)
 		}
-		lc, err = lode.NewLodeS3Client(cfg, s3cfg)
+		return lc, nil
+	case "memory":
+		lc, err := lode.NewLodeMemoryClient(cfg)
 		if err != nil {
-			return nil, nil, nil, fmt.Errorf("S3 storage initialization failed: %w (check AWS credentials and bucket permissions)", err)
+			return nil, fmt.Errorf("memory storage initialization failed: %w", err)
 		}
+		return lc, nil
 	default:
 		// Should not reach here due to validateStorageConfig
-		return nil, nil, nil, fmt.Errorf("unknown storage-backend: %s", storageConfig.backend)
+		return nil, fmt.Errorf("unknown storage-backend: %s", storageConfig.backend)
 	}
+}
 
-	sink := lode.NewSink(cfg, lc)
-	if collector != nil {
-		return lode.NewInstrumentedSink(sink, collector), lc, lc, nil
+// checkRunPartition aborts when the run partition already contains a terminal
+// event, i.e. a previous run with the same run_id finished there.
+func checkRunPartition(client lode.Client, runID string) error {
+	guard, ok := client.(lode.PartitionGuard)
+	if !ok {
+		// Client cannot inspect storage (e.g. stub); nothing to guard
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	exists, err := guard.HasTerminalEvent(ctx)
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("failed to check run partition: %v", err), exitConfigError)
+	}
+	if exists {
+		return cli.Exit(fmt.Sprintf(`run partition for run_id %q already contains a completed run
+
+To retry, use a new run ID:
+  --run-id <new-id> --attempt <n> --parent-run-id %s
+
+To write into the existing partition anyway:
+  --overwrite`, runID, runID), exitConfigError)
 	}
-	return sink, lc, lc, nil
+	return nil
 }
 
 // buildAdapter creates an adapter from parsed config.
@@ -1522,17 +3716,52 @@ func buildAdapter(ac adapterChoice) (adapter.Adapter, error) {
 	switch ac.adapterType {
 	case "webhook":
 		return webhook.New(webhook.Config{
-			URL:     ac.url,
-			Headers: ac.headers,
-			Timeout: ac.timeout,
-			Retries: ac.retries,
+			URL:        ac.url,
+			Headers:    ac.headers,
+			Timeout:    ac.timeout,
+			Retries:    ac.retries,
+			ClientCert: ac.clientCert,
+			ClientKey:  ac.clientKey,
+			CAFile:     ac.caFile,
+			Compress:    ac.compress,
+			RetryStatus: ac.retryStatus,
+			Encoder:     ac.encoder,
+			EgressProxy: ac.egressProxy,
 		})
 	case "redis":
 		return redisadapter.New(redisadapter.Config{
-			URL:     ac.url,
-			Channel: ac.channel,
+			URL:      ac.url,
+			Channel:  ac.channel,
+			Timeout:  ac.timeout,
+			Retries:  ac.retries,
+			Pipeline: ac.pipeline,
+			Encoder:  ac.encoder,
+		})
+	case "kafka":
+		kc := kafkaadapter.Config{
+			Brokers: strings.Split(ac.url, ","),
+			Topic:   ac.channel,
 			Timeout: ac.timeout,
 			Retries: ac.retries,
+			Encoder: ac.encoder,
+		}
+		if ac.kafka != nil {
+			kc.TLS = ac.kafka.TLS
+			kc.TLSCAFile = ac.kafka.TLSCAFile
+			if ac.kafka.SASL != nil {
+				kc.SASL = &kafkaadapter.SASLConfig{
+					Mechanism: ac.kafka.SASL.Mechanism,
+					Username:  ac.kafka.SASL.Username,
+					Password:  ac.kafka.SASL.Password,
+				}
+			}
+		}
+		return kafkaadapter.New(kc)
+	case "file":
+		return fileadapter.New(fileadapter.Config{
+			Path:     ac.url,
+			MaxBytes: ac.fileMaxBytes,
+			Encoder:  ac.encoder,
 		})
 	default:
 		return nil, fmt.Errorf("unknown adapter type: %q", ac.adapterType)
@@ -1543,33 +3772,65 @@ func buildAdapter(ac adapterChoice) (adapter.Adapter, error) {
 func buildRunCompletedEvent(
 	result *runtime.RunResult,
 	storageConfig storageChoice,
-	dataset, source, category, day string,
+	dataset, source, category, day, hour string,
 	duration time.Duration,
+	errorMaxLen int,
 ) *adapter.RunCompletedEvent {
 	event := &adapter.RunCompletedEvent{
 		ContractVersion: types.ContractVersion,
-		EventType:       "run_completed",
+		EventType:       adapter.EventTypeRunCompleted,
 		RunID:           result.RunMeta.RunID,
 		Source:          source,
 		Category:        category,
 		Day:             day,
+		Hour:            hour,
 		Outcome:         string(result.Outcome.Status),
-		StoragePath:     buildStoragePath(storageConfig, dataset, source, category, day, result.RunMeta.RunID),
+		Reason:          string(result.Outcome.Reason),
+		StoragePath:     buildStoragePath(storageConfig, dataset, source, category, day, hour, result.RunMeta.RunID),
 		Timestamp:       time.Now().UTC().Format(time.RFC3339),
 		Attempt:         result.RunMeta.Attempt,
 		EventCount:      result.EventCount,
 		DurationMs:      duration.Milliseconds(),
+		Labels:          result.RunMeta.Labels,
 	}
 	if result.RunMeta.JobID != nil {
 		event.JobID = *result.RunMeta.JobID
 	}
+
+	// Error classification for failed runs, so consumers can route retries
+	// by class without parsing the message
+	if result.Outcome.Status != types.OutcomeSuccess {
+		event.ErrorType = string(result.Outcome.Status)
+		if result.Outcome.ErrorType != nil && *result.Outcome.ErrorType != "" {
+			event.ErrorType = *result.Outcome.ErrorType
+		}
+		event.ErrorMessage = adapter.TruncateError(result.Outcome.Message, errorMaxLen)
+		if result.Outcome.Stack != nil {
+			event.ErrorStack = adapter.TruncateError(*result.Outcome.Stack, errorMaxLen)
+		}
+	}
 	return event
 }
 
 // buildStoragePath constructs a human-readable storage path for the event payload.
-func buildStoragePath(storageConfig storageChoice, dataset, source, category, day, runID string) string {
-	partitions := fmt.Sprintf("datasets/%s/partitions/source=%s/category=%s/day=%s/run_id=%s",
-		dataset, source, category, day, runID)
+// Uses the same partition template as the sink write path.
+func buildStoragePath(storageConfig storageChoice, dataset, source, category, day, hour, runID string) string {
+	cfg := lode.Config{
+		Tenant:            storageConfig.tenant,
+		Source:            source,
+		Category:          category,
+		Day:               day,
+		Hour:              hour,
+		RunID:             runID,
+		PartitionTemplate: storageConfig.partitionTemplate,
+	}
+	partition, err := cfg.PartitionPath()
+	if err != nil {
+		// Unreachable in practice: the sink renders the same values at init
+		// and the run fails before completion if rendering fails.
+		partition = fmt.Sprintf("source=%s/category=%s/day=%s/run_id=%s", source, category, day, runID)
+	}
+	partitions := fmt.Sprintf("datasets/%s/partitions/%s", dataset, partition)
 
 	switch storageConfig.backend {
 	case "fs":
@@ -1584,6 +3845,8 @@ func buildStoragePath(storageConfig storageChoice, dataset, source, category, da
 			return fmt.Sprintf("s3://%s/%s/%s", bucket, prefix, partitions)
 		}
 		return fmt.Sprintf("s3://%s/%s", bucket, partitions)
+	case "memory":
+		return "memory://" + partitions
 	default:
 		return partitions
 	}
@@ -1606,8 +3869,14 @@ func parseEventSinkConfig(c *cli.Context, cfg *quarryconfig.Config) ([]eventSink
 
 	// CLI flags take precedence over config file
 	if len(cliSinks) > 0 {
+		explainFrom(c).record("event-sink", cliSinks, sourceFlag)
 		return parseEventSinkFromCLI(c, cliSinks)
 	}
+	configTypes := make([]string, len(configSinks))
+	for i, sink := range configSinks {
+		configTypes[i] = sink.Type
+	}
+	explainFrom(c).record("event-sink", configTypes, sourceConfig)
 	return parseEventSinkFromConfig(configSinks)
 }
 
@@ -1800,21 +4069,94 @@ func buildEventSinkEntries(configs []eventSinkChoice, lodeSink policy.Sink, sour
 	return entries, nil
 }
 
-func outcomeToExitCode(status types.OutcomeStatus) int {
+// exitCodeMap maps run outcomes to process exit codes. Pre-execution
+// failures (exitConfigError) are not remapped.
+type exitCodeMap struct {
+	success       int
+	scriptError   int
+	executorCrash int
+	policyFailure int
+}
+
+// defaultExitCodes is the CONTRACT_RUN.md mapping.
+var defaultExitCodes = exitCodeMap{
+	success:       exitSuccess,
+	scriptError:   exitScriptError,
+	executorCrash: exitExecutorCrash,
+	policyFailure: exitPolicyFailure,
+}
+
+func outcomeToExitCode(status types.OutcomeStatus, codes exitCodeMap) int {
 	switch status {
 	case types.OutcomeSuccess:
-		return exitSuccess
+		return codes.success
 	case types.OutcomeScriptError:
-		return exitScriptError
+		return codes.scriptError
 	case types.OutcomeExecutorCrash:
-		return exitExecutorCrash
+		return codes.executorCrash
 	case types.OutcomePolicyFailure:
-		return exitPolicyFailure
+		return codes.policyFailure
 	case types.OutcomeVersionMismatch:
-		return exitPolicyFailure // non-retryable configuration error, same as policy_failure
+		return codes.policyFailure // non-retryable configuration error, same as policy_failure
 	default:
-		return exitScriptError
+		return codes.scriptError
+	}
+}
+
+// resolveExitCodes resolves --exit-code-* over the config exit_codes block
+// and validates that each code is in 0-255 and that no two outcomes share
+// a code.
+func resolveExitCodes(c *cli.Context, cfg *quarryconfig.Config) (exitCodeMap, error) {
+	var fromConfig quarryconfig.ExitCodesConfig
+	if cfg != nil {
+		fromConfig = cfg.ExitCodes
+	}
+	resolve := func(flag string, configVal *int) int {
+		if c.IsSet(flag) {
+			return explained(c, flag, c.Int(flag), sourceFlag)
+		}
+		if configVal != nil {
+			return explained(c, flag, *configVal, sourceConfig)
+		}
+		return explained(c, flag, c.Int(flag), sourceDefault)
+	}
+	codes := exitCodeMap{
+		success:       resolve("exit-code-success", fromConfig.Success),
+		scriptError:   resolve("exit-code-script-error", fromConfig.ScriptError),
+		executorCrash: resolve("exit-code-executor-crash", fromConfig.ExecutorCrash),
+		policyFailure: resolve("exit-code-policy-failure", fromConfig.PolicyFailure),
+	}
+	return codes, codes.validate()
+}
+
+// validate checks that each code is a valid process exit status and that
+// the mapping is injective, so the outcome can be recovered from the code.
+// exitConfigError is reserved: only executor_crash, which it has always
+// shared, may map to it.
+func (m exitCodeMap) validate() error {
+	entries := []struct {
+		flag string
+		code int
+	}{
+		{"--exit-code-success", m.success},
+		{"--exit-code-script-error", m.scriptError},
+		{"--exit-code-executor-crash", m.executorCrash},
+		{"--exit-code-policy-failure", m.policyFailure},
+	}
+	seen := make(map[int]string, len(entries))
+	for _, e := range entries {
+		if e.code < 0 || e.code > 255 {
+			return fmt.Errorf("%s must be in 0-255, got %d", e.flag, e.code)
+		}
+		if e.code == exitConfigError && e.flag != "--exit-code-executor-crash" {
+			return fmt.Errorf("%s cannot be %d: exit code %d is reserved for configuration errors", e.flag, e.code, exitConfigError)
+		}
+		if prev, ok := seen[e.code]; ok {
+			return fmt.Errorf("%s and %s both map to exit code %d; codes must be distinct", prev, e.flag, e.code)
+		}
+		seen[e.code] = e.flag
 	}
+	return nil
 }
 
 // selectProxy loads proxy pools and selects an endpoint.
@@ -1825,6 +4167,26 @@ func outcomeToExitCode(status types.OutcomeStatus) int {
 // configPools are pools defined inline in a quarry.yaml config file.
 // They take priority over --proxy-config when present.
 func selectProxy(config proxyChoice, runMeta *types.RunMeta, configPools []types.ProxyPool) (*types.ProxyEndpoint, error) {
+	sel, err := newProxySelection(config, runMeta, configPools)
+	if err != nil {
+		return nil, err
+	}
+	return sel.selectEndpoint()
+}
+
+// proxySelection is a loaded selector plus the resolved selection request.
+// It is kept for the invocation so fan-out retries can draw fresh endpoints
+// from the same pool with the same strategy state.
+type proxySelection struct {
+	selector      *proxy.Selector
+	req           proxy.SelectRequest
+	healthCheck   bool
+	healthTimeout time.Duration
+	poolSize      int
+}
+
+// newProxySelection loads proxy pools and builds the selection request.
+func newProxySelection(config proxyChoice, runMeta *types.RunMeta, configPools []types.ProxyPool) (*proxySelection, error) {
 	var selector *proxy.Selector
 	var pools []types.ProxyPool
 
@@ -1851,7 +4213,11 @@ func selectProxy(config proxyChoice, runMeta *types.RunMeta, configPools []types
 	}
 
 	// Warn about domain/origin sticky scopes without the required input.
+	poolSize := 0
 	for _, pool := range pools {
+		if pool.Name == config.poolName {
+			poolSize = len(pool.Endpoints)
+		}
 		if pool.Name == config.poolName && pool.Sticky != nil {
 			scope := pool.Sticky.Scope
 			// Check if required input is missing for the scope
@@ -1888,8 +4254,31 @@ func selectProxy(config proxyChoice, runMeta *types.RunMeta, configPools []types
 		req.StrategyOverride = &strategy
 	}
 
-	// Select endpoint
-	endpoint, err := selector.Select(req)
+	return &proxySelection{
+		selector:      selector,
+		req:           req,
+		healthCheck:   config.healthCheck,
+		healthTimeout: config.healthTimeout,
+		poolSize:      poolSize,
+	}, nil
+}
+
+// selectEndpoint selects (and commits) the next endpoint.
+func (ps *proxySelection) selectEndpoint() (*types.ProxyEndpoint, error) {
+	if !ps.healthCheck {
+		endpoint, err := ps.selector.Select(ps.req)
+		if err != nil {
+			return nil, fmt.Errorf("selection failed: %w", err)
+		}
+		return endpoint, nil
+	}
+
+	// Health-checked selection: probe, skipping dead endpoints in pool order
+	endpoint, skipped, err := ps.selector.SelectHealthy(context.Background(), ps.req, proxy.TCPProber(ps.healthTimeout))
+	for _, sk := range skipped {
+		fmt.Fprintf(os.Stderr, "Warning: proxy health check: skipped %s://%s:%d: %v\n",
+			sk.Endpoint.Protocol, sk.Endpoint.Host, sk.Endpoint.Port, sk.Err)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("selection failed: %w", err)
 	}
@@ -1897,6 +4286,58 @@ func selectProxy(config proxyChoice, runMeta *types.RunMeta, configPools []types
 	return endpoint, nil
 }
 
+// reselect selects an endpoint for a retry, drawing again (at most once per
+// pool endpoint) while the strategy returns the endpoint that failed. Sticky
+// or single-endpoint pools may still return it.
+func (ps *proxySelection) reselect(avoid *types.ProxyEndpointRedacted) (*types.ProxyEndpoint, error) {
+	endpoint, err := ps.selectEndpoint()
+	for i := 1; err == nil && i < ps.poolSize && sameEndpoint(endpoint, avoid); i++ {
+		endpoint, err = ps.selectEndpoint()
+	}
+	return endpoint, err
+}
+
+// Rotate implements runtime.ProxyRotator for --proxy-rotate-on-block. The
+// browser's proxy server is fixed at launch, so it draws (at most once per
+// pool endpoint) until the strategy returns an endpoint on the same server
+// as current with other credentials.
+func (ps *proxySelection) Rotate(current *types.ProxyEndpoint) (*types.ProxyEndpoint, error) {
+	if current == nil {
+		return ps.selectEndpoint()
+	}
+	for range ps.poolSize {
+		endpoint, err := ps.selectEndpoint()
+		if err != nil {
+			return nil, err
+		}
+		if endpoint.Protocol == current.Protocol && endpoint.Host == current.Host &&
+			endpoint.Port == current.Port && !sameUsername(endpoint.Username, current.Username) {
+			return endpoint, nil
+		}
+	}
+	return nil, fmt.Errorf("no pool endpoint on %s:%d with other credentials", current.Host, current.Port)
+}
+
+// sameUsername reports whether two optional usernames are equal.
+func sameUsername(a, b *string) bool {
+	return (a == nil) == (b == nil) && (a == nil || *a == *b)
+}
+
+// rotator returns ps as a runtime.ProxyRotator when rotation is enabled,
+// and a nil interface otherwise (including when no pool is selected).
+func (ps *proxySelection) rotator(enabled bool) runtime.ProxyRotator {
+	if ps == nil || !enabled {
+		return nil
+	}
+	return ps
+}
+
+// sameEndpoint reports whether endpoint is the redacted endpoint prev.
+func sameEndpoint(endpoint *types.ProxyEndpoint, prev *types.ProxyEndpointRedacted) bool {
+	return prev != nil && endpoint.Protocol == prev.Protocol &&
+		endpoint.Host == prev.Host && endpoint.Port == prev.Port
+}
+
 // loadAndRegisterPools loads proxy pools from a config file and returns a ready selector.
 func loadAndRegisterPools(configPath string) (*proxy.Selector, error) {
 	pools, err := loadProxyPools(configPath)
@@ -1912,7 +4353,8 @@ func loadAndRegisterPools(configPath string) (*proxy.Selector, error) {
 	return selector, nil
 }
 
-// loadProxyPools loads proxy pools from a JSON config file.
+// loadProxyPools loads proxy pools from a JSON config file and resolves
+// endpoint secret references (see proxy.ResolveSecrets).
 func loadProxyPools(path string) ([]types.ProxyPool, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -1923,31 +4365,49 @@ func loadProxyPools(path string) ([]types.ProxyPool, error) {
 	if err := json.Unmarshal(data, &pools); err != nil {
 		return nil, fmt.Errorf("invalid JSON: %w", err)
 	}
+	for i := range pools {
+		if err := proxy.ResolveSecrets(&pools[i]); err != nil {
+			return nil, err
+		}
+	}
 
 	return pools, nil
 }
 
 func printRunResult(result *runtime.RunResult, choice policyChoice, duration time.Duration) {
-	fmt.Printf("\nrun_id=%s, attempt=%d, outcome=%s, duration=%s\n",
+	fmt.Printf("\nrun_id=%s, attempt=%d, outcome=%s, reason=%s, duration=%s\n",
 		result.RunMeta.RunID,
 		result.RunMeta.Attempt,
 		result.Outcome.Status,
+		result.Outcome.Reason,
 		duration.Round(time.Millisecond),
 	)
 
 	switch choice.name {
+	case "strict":
+		if choice.batchSize > 0 || choice.batchWindow > 0 {
+			fmt.Printf("policy=%s, events_batch_size=%d, strict_batch_window=%s\n",
+				choice.name,
+				choice.batchSize,
+				choice.batchWindow,
+			)
+		} else {
+			fmt.Printf("policy=%s\n", choice.name)
+		}
 	case "buffered":
-		fmt.Printf("policy=%s, flush_mode=%s, drops=%d, buffer_bytes=%d\n",
+		fmt.Printf("policy=%s, flush_mode=%s, parallel_flush=%t, drops=%d, buffer_bytes=%d\n",
 			choice.name,
 			choice.flushMode,
+			choice.parallelFlush,
 			result.PolicyStats.EventsDropped,
 			result.PolicyStats.BufferSize,
 		)
 	case "streaming":
-		fmt.Printf("policy=%s, flush_count=%d, flush_interval=%s, flushes=%d\n",
+		fmt.Printf("policy=%s, flush_count=%d, flush_interval=%s, flush_idle=%s, flushes=%d\n",
 			choice.name,
 			choice.flushCount,
 			choice.flushInterval,
+			choice.flushIdle,
 			result.PolicyStats.FlushCount,
 		)
 	default:
@@ -1963,7 +4423,18 @@ func printRunResult(result *runtime.RunResult, choice policyChoice, duration tim
 		fmt.Printf("Parent Run:   %s\n", *result.RunMeta.ParentRunID)
 	}
 	fmt.Printf("Attempt:      %d\n", result.RunMeta.Attempt)
+	if len(result.RunMeta.Labels) > 0 {
+		pairs := make([]string, 0, len(result.RunMeta.Labels))
+		for k, v := range result.RunMeta.Labels {
+			pairs = append(pairs, k+"="+v)
+		}
+		sort.Strings(pairs)
+		fmt.Printf("Labels:       %s\n", strings.Join(pairs, ", "))
+	}
 	fmt.Printf("Outcome:      %s\n", result.Outcome.Status)
+	if result.Outcome.Reason != "" {
+		fmt.Printf("Reason:       %s\n", result.Outcome.Reason)
+	}
 	fmt.Printf("Message:      %s\n", result.Outcome.Message)
 	fmt.Printf("Duration:     %s\n", result.Duration)
 	fmt.Printf("Events:       %d\n", result.EventCount)
@@ -1984,6 +4455,33 @@ func printRunResult(result *runtime.RunResult, choice policyChoice, duration tim
 	fmt.Printf("Events Dropped:   %d\n", result.PolicyStats.EventsDropped)
 	fmt.Printf("Chunks Total:     %d\n", result.PolicyStats.TotalChunks)
 	fmt.Printf("Flushes:          %d\n", result.PolicyStats.FlushCount)
+	if result.PolicyStats.FlushLatencyTotal > 0 {
+		fmt.Printf("Flush Latency:    total=%s, max=%s\n",
+			result.PolicyStats.FlushLatencyTotal.Round(time.Microsecond),
+			result.PolicyStats.FlushLatencyMax.Round(time.Microsecond),
+		)
+	}
+	if result.PolicyStats.Degradations > 0 {
+		fmt.Printf("Degraded:         %d time(s) (--strict-degrade-buffer)\n", result.PolicyStats.Degradations)
+	}
+	if result.RedactedFields > 0 {
+		fmt.Printf("Fields Redacted:  %d\n", result.RedactedFields)
+	}
+	if result.ArtifactsDiscarded > 0 {
+		fmt.Printf("Artifacts Discarded: %d (--events-only)\n", result.ArtifactsDiscarded)
+	}
+	if result.EventsDiscarded > 0 {
+		fmt.Printf("Events Discarded: %d (--artifacts-only)\n", result.EventsDiscarded)
+	}
+	if result.LogsFiltered > 0 {
+		fmt.Printf("Logs Filtered:    %d (--log-min-level)\n", result.LogsFiltered)
+	}
+	if result.EventsSampledOut > 0 {
+		fmt.Printf("Items Sampled Out: %d (--sample-rate)\n", result.EventsSampledOut)
+	}
+	if result.EventsFiltered > 0 {
+		fmt.Printf("Events Filtered:  %d (--events-filter)\n", result.EventsFiltered)
+	}
 
 	if result.ArtifactStats.TotalArtifacts > 0 {
 		fmt.Printf("\n=== Artifact Stats ===\n")
@@ -1992,6 +4490,12 @@ func printRunResult(result *runtime.RunResult, choice policyChoice, duration tim
 		fmt.Printf("Orphaned:          %d\n", result.ArtifactStats.OrphanedArtifacts)
 		fmt.Printf("Total Chunks:      %d\n", result.ArtifactStats.TotalChunks)
 		fmt.Printf("Total Bytes:       %d\n", result.ArtifactStats.TotalBytes)
+		if result.ArtifactStats.SniffedArtifacts > 0 {
+			fmt.Printf("Sniffed Types:     %d\n", result.ArtifactStats.SniffedArtifacts)
+			for _, contentType := range slices.Sorted(maps.Keys(result.ArtifactStats.SniffedContentTypes)) {
+				fmt.Printf("  %s: %d\n", contentType, result.ArtifactStats.SniffedContentTypes[contentType])
+			}
+		}
 	}
 
 	if len(result.OrphanIDs) > 0 {
@@ -2017,32 +4521,68 @@ func printMetrics(snap metrics.Snapshot) {
 	fmt.Printf("runs_completed_total:            %d\n", snap.RunsCompleted)
 	fmt.Printf("runs_failed_total:               %d\n", snap.RunsFailed)
 	fmt.Printf("runs_crashed_total:              %d\n", snap.RunsCrashed)
+	for _, reason := range sortedKeys(snap.RunsByReason) {
+		fmt.Printf("  runs{reason=%s}:      %d\n", reason, snap.RunsByReason[reason])
+	}
 
 	// Ingestion policy
 	fmt.Printf("events_received_total:           %d\n", snap.EventsReceived)
 	fmt.Printf("events_persisted_total:          %d\n", snap.EventsPersisted)
 	fmt.Printf("events_dropped_total:            %d\n", snap.EventsDropped)
+	fmt.Printf("policy_degradations_total:       %d\n", snap.Degradations)
 	// Deterministic output order for dropped-by-type breakdown
 	droppedTypes := sortedKeys(snap.DroppedByType)
 	for _, eventType := range droppedTypes {
 		fmt.Printf("  events_dropped{type=%s}:      %d\n", eventType, snap.DroppedByType[eventType])
 	}
+	printHistogram("flush_latency", snap.FlushLatency)
 
 	// Executor
 	fmt.Printf("executor_launch_success_total:   %d\n", snap.ExecutorLaunchSuccess)
 	fmt.Printf("executor_launch_failure_total:   %d\n", snap.ExecutorLaunchFailure)
 	fmt.Printf("executor_crash_total:            %d\n", snap.ExecutorCrash)
 	fmt.Printf("ipc_decode_errors_total:         %d\n", snap.IPCDecodeErrors)
+	fmt.Printf("seq_gaps_total:                  %d\n", snap.SeqGaps)
+	fmt.Printf("artifacts_discarded_total:       %d\n", snap.ArtifactsDiscarded)
+	fmt.Printf("events_discarded_total:          %d\n", snap.EventsDiscarded)
+	fmt.Printf("events_sampled_out_total:        %d\n", snap.EventsSampledOut)
+	fmt.Printf("events_filtered_total:           %d\n", snap.EventsFiltered)
+	for _, level := range sortedKeys(snap.LogFilteredByLevel) {
+		fmt.Printf("  log_filtered{level=%s}:      %d\n", level, snap.LogFilteredByLevel[level])
+	}
+	printHistogram("time_to_first_event", snap.TimeToFirstEvent)
+	fmt.Printf("enqueues_deduplicated_total:     %d\n", snap.EnqueuesDeduplicated)
+	fmt.Printf("proxy_rotations_total:           %d\n", snap.ProxyRotations)
 
 	// Lode / Storage (per-call granularity)
 	fmt.Printf("lode_write_success_total:        %d\n", snap.LodeWriteSuccess)
 	fmt.Printf("lode_write_failure_total:        %d\n", snap.LodeWriteFailure)
 	fmt.Printf("lode_write_retry_total:          %d (not implemented)\n", snap.LodeWriteRetry)
+	fmt.Printf("lode_writes_skipped_total:       %d\n", snap.LodeWritesSkipped)
+	for _, sink := range sortedKeys(snap.StorageSinkWrites) {
+		writes := snap.StorageSinkWrites[sink]
+		fmt.Printf("  storage_sink{sink=%s}:      writes=%d failures=%d latency_avg=%s\n",
+			sink, writes, snap.StorageSinkFailures[sink],
+			time.Duration(snap.StorageSinkLatencyUs[sink]/writes)*time.Microsecond)
+	}
 
 	// Dimensions
 	fmt.Printf("\n  policy=%s executor=%s storage_backend=%s\n", snap.Policy, snap.Executor, snap.StorageBackend)
 }
 
+// printHistogram prints a latency histogram as its count and mean, then the
+// per-bucket (non-cumulative) counts over every fixed metrics.LatencyBuckets
+// bound, so lines compare across runs.
+func printHistogram(name string, h metrics.Histogram) {
+	fmt.Printf("%-33s count=%d mean=%s\n", name+":", h.Count, h.Mean().Round(time.Microsecond))
+	var b strings.Builder
+	for i, bound := range metrics.LatencyBuckets {
+		fmt.Fprintf(&b, " le_%s=%d", bound, h.Counts[i])
+	}
+	fmt.Fprintf(&b, " le_inf=%d", h.Counts[len(metrics.LatencyBuckets)])
+	fmt.Printf("  buckets:%s\n", b.String())
+}
+
 // sortedKeys returns map keys in sorted order for deterministic output.
 func sortedKeys(m map[string]int64) []string {
 	keys := make([]string, 0, len(m))
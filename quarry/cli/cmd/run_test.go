@@ -1,15 +1,24 @@
 package cmd
 
 import (
+	"context"
 	"flag"
+	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
+	"syscall"
 	"testing"
 	"time"
 
+	"github.com/pithecene-io/quarry/adapter"
+	fileadapter "github.com/pithecene-io/quarry/adapter/file"
 	"github.com/pithecene-io/quarry/adapter/redisstream"
 	quarryconfig "github.com/pithecene-io/quarry/cli/config"
+	"github.com/pithecene-io/quarry/clock"
 	"github.com/pithecene-io/quarry/iox"
 	"github.com/pithecene-io/quarry/lode"
 	"github.com/pithecene-io/quarry/metrics"
@@ -31,6 +40,34 @@ func TestValidatePolicyConfig(t *testing.T) {
 			choice:  policyChoice{name: "strict", flushMode: "at_least_once"},
 			wantErr: false,
 		},
+		{
+			name:    "strict with batch size and window valid",
+			choice:  policyChoice{name: "strict", flushMode: "at_least_once", batchSize: 50, batchWindow: 100 * time.Millisecond},
+			wantErr: false,
+		},
+		{
+			name:        "negative events batch size invalid",
+			choice:      policyChoice{name: "strict", flushMode: "at_least_once", batchSize: -1},
+			wantErr:     true,
+			errContains: "--events-batch-size must be >= 0",
+		},
+		{
+			name:        "negative strict batch window invalid",
+			choice:      policyChoice{name: "strict", flushMode: "at_least_once", batchWindow: -time.Second},
+			wantErr:     true,
+			errContains: "--strict-batch-window must be >= 0",
+		},
+		{
+			name:    "strict with degrade buffer valid",
+			choice:  policyChoice{name: "strict", flushMode: "at_least_once", degradeBuffer: 1000},
+			wantErr: false,
+		},
+		{
+			name:        "negative strict degrade buffer invalid",
+			choice:      policyChoice{name: "strict", flushMode: "at_least_once", degradeBuffer: -1},
+			wantErr:     true,
+			errContains: "--strict-degrade-buffer must be >= 0",
+		},
 		{
 			name:    "buffered with events limit valid",
 			choice:  policyChoice{name: "buffered", flushMode: "at_least_once", maxEvents: 1000},
@@ -69,6 +106,17 @@ func TestValidatePolicyConfig(t *testing.T) {
 			choice:  policyChoice{name: "buffered", flushMode: "two_phase", maxBytes: 1000},
 			wantErr: false,
 		},
+		{
+			name:    "buffered two_phase with parallel flush valid",
+			choice:  policyChoice{name: "buffered", flushMode: "two_phase", parallelFlush: true, maxBytes: 1000},
+			wantErr: false,
+		},
+		{
+			name:        "parallel flush without two_phase invalid",
+			choice:      policyChoice{name: "buffered", flushMode: "chunks_first", parallelFlush: true, maxBytes: 1000},
+			wantErr:     true,
+			errContains: "--parallel-flush requires --flush-mode two_phase",
+		},
 	}
 
 	for _, tt := range tests {
@@ -122,6 +170,28 @@ func TestValidateStorageConfig(t *testing.T) {
 			wantErr:     true,
 			errContains: "--storage-path required",
 		},
+		{
+			name:    "s3 with sse-kms and storage class",
+			config:  storageChoice{backend: "s3", path: "my-bucket", sse: "aws:kms", kmsKeyID: "alias/quarry", storageClass: "STANDARD_IA"},
+			wantErr: false,
+		},
+		{
+			name:        "s3 kms key without sse",
+			config:      storageChoice{backend: "s3", path: "my-bucket", kmsKeyID: "alias/quarry"},
+			wantErr:     true,
+			errContains: "KMS key requires",
+		},
+		{
+			name:        "s3 invalid storage class",
+			config:      storageChoice{backend: "s3", path: "my-bucket", storageClass: "COLD"},
+			wantErr:     true,
+			errContains: "invalid S3 storage class",
+		},
+		{
+			name:    "memory without path",
+			config:  storageChoice{backend: "memory"},
+			wantErr: false,
+		},
 		{
 			name:        "invalid backend",
 			config:      storageChoice{backend: "invalid", path: "/tmp"},
@@ -552,6 +622,46 @@ func TestParseJobPayloadErrorsAreActionable(t *testing.T) {
 	}
 }
 
+func TestParseSeedState(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	state, err := parseSeedState(write("state.json", `{"cursor": "tok-42", "page": 3}`))
+	if err != nil {
+		t.Fatalf("parseSeedState: %v", err)
+	}
+	if state["cursor"] != "tok-42" || state["page"] != float64(3) {
+		t.Errorf("state = %v", state)
+	}
+
+	if state, err := parseSeedState(""); state != nil || err != nil {
+		t.Errorf("empty path = %v, %v; want nil, nil", state, err)
+	}
+
+	tests := []struct {
+		name, path, errContains string
+	}{
+		{"missing file", filepath.Join(dir, "missing.json"), "file not found"},
+		{"malformed", write("bad.json", `{broken`), "malformed JSON"},
+		{"array", write("array.json", `[1]`), "must contain a JSON object, got array"},
+		{"null", write("null.json", `null`), "must contain a JSON object, got null"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseSeedState(tt.path)
+			if err == nil || !strings.Contains(err.Error(), "--seed-state: ") || !strings.Contains(err.Error(), tt.errContains) {
+				t.Errorf("err = %v, want containing %q", err, tt.errContains)
+			}
+		})
+	}
+}
+
 func TestDescribeJSONType(t *testing.T) {
 	tests := []struct {
 		input    any
@@ -938,149 +1048,877 @@ proxy:
 	}
 }
 
-// --- outcomeToExitCode ---
+func TestRunAction_AllowSeqGapsRejectedForStrict(t *testing.T) {
+	dir := t.TempDir()
+	app := newTestApp()
 
-func TestOutcomeToExitCode(t *testing.T) {
-	tests := []struct {
-		status types.OutcomeStatus
-		want   int
-	}{
-		{types.OutcomeSuccess, exitSuccess},
-		{types.OutcomeScriptError, exitScriptError},
-		{types.OutcomeExecutorCrash, exitExecutorCrash},
-		{types.OutcomePolicyFailure, exitPolicyFailure},
+	err := app.Run([]string{"quarry", "run",
+		"--script", "./test.ts",
+		"--run-id", "run-001",
+		"--source", "test",
+		"--storage-backend", "fs",
+		"--storage-path", dir,
+		"--allow-seq-gaps",
+	})
+	if err == nil {
+		t.Fatal("expected error for --allow-seq-gaps with strict policy")
 	}
-	for _, tt := range tests {
-		t.Run(string(tt.status), func(t *testing.T) {
-			if got := outcomeToExitCode(tt.status); got != tt.want {
-				t.Errorf("outcomeToExitCode(%q) = %d, want %d", tt.status, got, tt.want)
-			}
-		})
+	exitErr, ok := err.(cli.ExitCoder)
+	if !ok {
+		t.Fatalf("expected cli.ExitCoder, got %T: %v", err, err)
 	}
-}
-
-func TestOutcomeToExitCode_UnknownDefaultsToScriptError(t *testing.T) {
-	got := outcomeToExitCode(types.OutcomeStatus("unknown_status"))
-	if got != exitScriptError {
-		t.Errorf("unknown status should map to exitScriptError (%d), got %d", exitScriptError, got)
+	if exitErr.ExitCode() != exitConfigError {
+		t.Errorf("exit code = %d, want %d (exitConfigError)", exitErr.ExitCode(), exitConfigError)
+	}
+	if !strings.Contains(err.Error(), "--allow-seq-gaps requires --policy buffered or streaming") {
+		t.Errorf("error should explain policy requirement, got: %v", err)
 	}
 }
 
-func TestOutcomeToExitCode_ContractValues(t *testing.T) {
-	// Verify the actual numeric values per CONTRACT_RUN.md
-	if exitSuccess != 0 {
-		t.Errorf("exitSuccess should be 0, got %d", exitSuccess)
+func TestRunAction_StoragePrefixTemplateRequiresRunID(t *testing.T) {
+	dir := t.TempDir()
+	app := newTestApp()
+
+	err := app.Run([]string{"quarry", "run",
+		"--script", "./test.ts",
+		"--run-id", "run-001",
+		"--source", "test",
+		"--storage-backend", "fs",
+		"--storage-path", dir,
+		"--storage-prefix-template", "year={{.Year}}/month={{.Month}}/source={{.Source}}",
+	})
+	if err == nil {
+		t.Fatal("expected error for template without RunID")
 	}
-	if exitScriptError != 1 {
-		t.Errorf("exitScriptError should be 1, got %d", exitScriptError)
+	exitErr, ok := err.(cli.ExitCoder)
+	if !ok {
+		t.Fatalf("expected cli.ExitCoder, got %T: %v", err, err)
 	}
-	if exitExecutorCrash != 2 {
-		t.Errorf("exitExecutorCrash should be 2, got %d", exitExecutorCrash)
+	if exitErr.ExitCode() != exitConfigError {
+		t.Errorf("exit code = %d, want %d (exitConfigError)", exitErr.ExitCode(), exitConfigError)
 	}
-	if exitPolicyFailure != 3 {
-		t.Errorf("exitPolicyFailure should be 3, got %d", exitPolicyFailure)
+	if !strings.Contains(err.Error(), "invalid --storage-prefix-template") {
+		t.Errorf("error should name the flag, got: %v", err)
 	}
 }
 
-// --- buildStoragePath ---
-
-func TestBuildStoragePath_FS(t *testing.T) {
-	sc := storageChoice{backend: "fs", path: "/var/quarry/data"}
-	got := buildStoragePath(sc, "quarry", "my-source", "default", "2026-02-08", "run-001")
+func TestRunAction_ParentRunIDMustDiffer(t *testing.T) {
+	dir := t.TempDir()
+	app := newTestApp()
 
-	// Must be file:// scheme with absolute path
-	if !strings.HasPrefix(got, "file:///") {
-		t.Errorf("fs path should start with file:///, got %q", got)
+	err := app.Run([]string{"quarry", "run",
+		"--script", "./test.ts",
+		"--run-id", "run-001",
+		"--attempt", "2",
+		"--parent-run-id", "run-001",
+		"--source", "test",
+		"--storage-backend", "fs",
+		"--storage-path", dir,
+	})
+	if err == nil {
+		t.Fatal("expected error for retry reusing the parent run_id")
 	}
-	// Must contain Hive-partitioned segments
-	for _, segment := range []string{
-		"datasets/quarry/partitions",
-		"source=my-source",
-		"category=default",
-		"day=2026-02-08",
-		"run_id=run-001",
-	} {
-		if !strings.Contains(got, segment) {
-			t.Errorf("fs path should contain %q, got %q", segment, got)
-		}
+	exitErr, ok := err.(cli.ExitCoder)
+	if !ok {
+		t.Fatalf("expected cli.ExitCoder, got %T: %v", err, err)
 	}
-}
-
-func TestBuildStoragePath_S3WithPrefix(t *testing.T) {
-	sc := storageChoice{backend: "s3", path: "my-bucket/quarry-data"}
-	got := buildStoragePath(sc, "quarry", "src", "cat", "2026-01-01", "run-x")
-
-	want := "s3://my-bucket/quarry-data/datasets/quarry/partitions/source=src/category=cat/day=2026-01-01/run_id=run-x"
-	if got != want {
-		t.Errorf("s3 with prefix:\ngot  %q\nwant %q", got, want)
+	if exitErr.ExitCode() != exitConfigError {
+		t.Errorf("exit code = %d, want %d (exitConfigError)", exitErr.ExitCode(), exitConfigError)
+	}
+	if !strings.Contains(err.Error(), "must differ from parent_run_id") {
+		t.Errorf("error should explain run_id reuse, got: %v", err)
 	}
 }
 
-func TestBuildStoragePath_S3BucketOnly(t *testing.T) {
-	sc := storageChoice{backend: "s3", path: "my-bucket"}
-	got := buildStoragePath(sc, "quarry", "src", "cat", "2026-01-01", "run-x")
+func TestRunAction_EventsOnlyArtifactsOnlyExclusive(t *testing.T) {
+	dir := t.TempDir()
+	app := newTestApp()
 
-	want := "s3://my-bucket/datasets/quarry/partitions/source=src/category=cat/day=2026-01-01/run_id=run-x"
-	if got != want {
-		t.Errorf("s3 bucket only:\ngot  %q\nwant %q", got, want)
+	err := app.Run([]string{"quarry", "run",
+		"--script", "./test.ts",
+		"--run-id", "run-001",
+		"--source", "test",
+		"--storage-backend", "fs",
+		"--storage-path", dir,
+		"--events-only",
+		"--artifacts-only",
+	})
+	if err == nil {
+		t.Fatal("expected error for --events-only with --artifacts-only")
+	}
+	exitErr, ok := err.(cli.ExitCoder)
+	if !ok {
+		t.Fatalf("expected cli.ExitCoder, got %T: %v", err, err)
+	}
+	if exitErr.ExitCode() != exitConfigError {
+		t.Errorf("exit code = %d, want %d (exitConfigError)", exitErr.ExitCode(), exitConfigError)
+	}
+	if !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Errorf("error should explain exclusivity, got: %v", err)
 	}
 }
 
-func TestBuildStoragePath_UnknownBackend(t *testing.T) {
-	sc := storageChoice{backend: "gcs", path: "/tmp"}
-	got := buildStoragePath(sc, "quarry", "src", "cat", "2026-01-01", "run-x")
-
-	// Unknown backend returns bare partition path (no scheme prefix)
-	if strings.Contains(got, "://") {
-		t.Errorf("unknown backend should not include scheme, got %q", got)
+func TestRunAction_ProxySelectionFailureUsesExecutorCrashCode(t *testing.T) {
+	err := newTestApp().Run([]string{"quarry", "run",
+		"--script", "./test.ts",
+		"--run-id", "run-001",
+		"--source", "test",
+		"--storage-backend", "fs",
+		"--storage-path", t.TempDir(),
+		"--proxy-pool", "residential",
+		"--proxy-config", filepath.Join(t.TempDir(), "missing.json"),
+		"--exit-code-executor-crash", "70",
+	})
+	exitErr, ok := err.(cli.ExitCoder)
+	if !ok {
+		t.Fatalf("expected cli.ExitCoder, got %T: %v", err, err)
 	}
-	if !strings.HasPrefix(got, "datasets/") {
-		t.Errorf("unknown backend should return bare partition path, got %q", got)
+	if exitErr.ExitCode() != 70 {
+		t.Errorf("exit code = %d, want 70 (--exit-code-executor-crash)", exitErr.ExitCode())
+	}
+	if !strings.Contains(err.Error(), "proxy selection failed") {
+		t.Errorf("err = %v, want a proxy selection failure", err)
 	}
 }
 
-// --- buildRunCompletedEvent ---
-
-func TestBuildRunCompletedEvent_BasicFields(t *testing.T) {
-	result := &runtime.RunResult{
-		RunMeta: &types.RunMeta{
-			RunID:   "run-001",
-			Attempt: 1,
-		},
-		Outcome: &types.RunOutcome{
-			Status: types.OutcomeSuccess,
-		},
-		EventCount: 42,
+func TestRunAction_SampleRateOutOfRange(t *testing.T) {
+	for _, rate := range []string{"-0.1", "0", "1.5"} {
+		t.Run(rate, func(t *testing.T) {
+			err := newTestApp().Run([]string{"quarry", "run",
+				"--script", "./test.ts",
+				"--run-id", "run-001",
+				"--source", "test",
+				"--storage-backend", "fs",
+				"--storage-path", t.TempDir(),
+				"--sample-rate", rate,
+			})
+			exitErr, ok := err.(cli.ExitCoder)
+			if !ok {
+				t.Fatalf("expected cli.ExitCoder, got %T: %v", err, err)
+			}
+			if exitErr.ExitCode() != exitConfigError {
+				t.Errorf("exit code = %d, want %d (exitConfigError)", exitErr.ExitCode(), exitConfigError)
+			}
+			if !strings.Contains(err.Error(), "--sample-rate must be greater than 0 and at most 1") {
+				t.Errorf("err = %v, want a range error", err)
+			}
+		})
 	}
-	sc := storageChoice{backend: "fs", path: "/tmp/data"}
-	event := buildRunCompletedEvent(result, sc, "quarry", "src", "cat", "2026-02-08", 5*time.Second)
+}
 
-	if event.ContractVersion != types.ContractVersion {
-		t.Errorf("ContractVersion = %q, want %q", event.ContractVersion, types.ContractVersion)
-	}
-	if event.EventType != "run_completed" {
-		t.Errorf("EventType = %q, want %q", event.EventType, "run_completed")
-	}
-	if event.RunID != "run-001" {
-		t.Errorf("RunID = %q, want %q", event.RunID, "run-001")
+func TestRunAction_SampleRateZeroInConfig(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "quarry.yaml")
+	if err := os.WriteFile(configPath, []byte("sample_rate: 0\n"), 0o644); err != nil {
+		t.Fatal(err)
 	}
-	if event.Source != "src" {
-		t.Errorf("Source = %q, want %q", event.Source, "src")
+	err := newTestApp().Run([]string{"quarry", "run",
+		"--config", configPath,
+		"--script", "./test.ts",
+		"--run-id", "run-001",
+		"--source", "test",
+		"--storage-backend", "fs",
+		"--storage-path", dir,
+	})
+	exitErr, ok := err.(cli.ExitCoder)
+	if !ok {
+		t.Fatalf("expected cli.ExitCoder, got %T: %v", err, err)
 	}
-	if event.Category != "cat" {
-		t.Errorf("Category = %q, want %q", event.Category, "cat")
+	if exitErr.ExitCode() != exitConfigError || !strings.Contains(err.Error(), "--sample-rate must be greater than 0") {
+		t.Errorf("err = %v (exit %d), want a range error with exitConfigError", err, exitErr.ExitCode())
 	}
-	if event.Day != "2026-02-08" {
-		t.Errorf("Day = %q, want %q", event.Day, "2026-02-08")
+}
+
+func TestRunAction_InvalidStorageFormat(t *testing.T) {
+	err := newTestApp().Run([]string{"quarry", "run",
+		"--script", "./test.ts",
+		"--run-id", "run-001",
+		"--source", "test",
+		"--storage-backend", "fs",
+		"--storage-path", t.TempDir(),
+		"--storage-format", "csv",
+	})
+	exitErr, ok := err.(cli.ExitCoder)
+	if !ok {
+		t.Fatalf("expected cli.ExitCoder, got %T: %v", err, err)
 	}
-	if event.Outcome != "success" {
-		t.Errorf("Outcome = %q, want %q", event.Outcome, "success")
+	if exitErr.ExitCode() != exitConfigError {
+		t.Errorf("exit code = %d, want %d (exitConfigError)", exitErr.ExitCode(), exitConfigError)
 	}
-	if event.Attempt != 1 {
-		t.Errorf("Attempt = %d, want %d", event.Attempt, 1)
+	if !strings.Contains(err.Error(), "invalid --storage-format") {
+		t.Errorf("err = %v, want a storage format error", err)
 	}
-	if event.EventCount != 42 {
-		t.Errorf("EventCount = %d, want %d", event.EventCount, 42)
+}
+
+func TestRunAction_InvalidDecodeLimits(t *testing.T) {
+	err := newTestApp().Run([]string{"quarry", "run",
+		"--script", "./test.ts",
+		"--run-id", "run-001",
+		"--source", "test",
+		"--storage-backend", "fs",
+		"--storage-path", t.TempDir(),
+		"--max-decode-depth", "5000",
+	})
+	exitErr, ok := err.(cli.ExitCoder)
+	if !ok {
+		t.Fatalf("expected cli.ExitCoder, got %T: %v", err, err)
+	}
+	if exitErr.ExitCode() != exitConfigError {
+		t.Errorf("exit code = %d, want %d (exitConfigError)", exitErr.ExitCode(), exitConfigError)
+	}
+	if !strings.Contains(err.Error(), "max decode depth must be between 1 and 4096") {
+		t.Errorf("err = %v, want a decode depth error", err)
+	}
+}
+
+func TestRunAction_InvalidEventsFilter(t *testing.T) {
+	err := newTestApp().Run([]string{"quarry", "run",
+		"--script", "./test.ts",
+		"--run-id", "run-001",
+		"--source", "test",
+		"--storage-backend", "fs",
+		"--storage-path", t.TempDir(),
+		"--events-filter", `kind == "item"`,
+	})
+	exitErr, ok := err.(cli.ExitCoder)
+	if !ok {
+		t.Fatalf("expected cli.ExitCoder, got %T: %v", err, err)
+	}
+	if exitErr.ExitCode() != exitConfigError {
+		t.Errorf("exit code = %d, want %d (exitConfigError)", exitErr.ExitCode(), exitConfigError)
+	}
+	if !strings.Contains(err.Error(), `--events-filter: invalid events filter: unknown field "kind"`) {
+		t.Errorf("err = %v, want a parse error", err)
+	}
+}
+
+func TestRunAction_MetricsAddrBindFailure(t *testing.T) {
+	dir := t.TempDir()
+	app := newTestApp()
+
+	err := app.Run([]string{"quarry", "run",
+		"--script", "./test.ts",
+		"--run-id", "run-001",
+		"--source", "test",
+		"--storage-backend", "fs",
+		"--storage-path", dir,
+		"--metrics-addr", "not-an-address",
+	})
+	if err == nil {
+		t.Fatal("expected error for unbindable --metrics-addr")
+	}
+	exitErr, ok := err.(cli.ExitCoder)
+	if !ok {
+		t.Fatalf("expected cli.ExitCoder, got %T: %v", err, err)
+	}
+	if exitErr.ExitCode() != exitConfigError {
+		t.Errorf("exit code = %d, want %d (exitConfigError)", exitErr.ExitCode(), exitConfigError)
+	}
+	if !strings.Contains(err.Error(), "--metrics-addr") {
+		t.Errorf("error should name the flag, got: %v", err)
+	}
+}
+
+func TestParseIngestMode(t *testing.T) {
+	tests := []struct {
+		eventsOnly, artifactsOnly bool
+		want                      runtime.IngestMode
+	}{
+		{false, false, runtime.IngestAll},
+		{true, false, runtime.IngestEventsOnly},
+		{false, true, runtime.IngestArtifactsOnly},
+	}
+	for _, tt := range tests {
+		got, err := parseIngestMode(tt.eventsOnly, tt.artifactsOnly)
+		if err != nil {
+			t.Fatalf("parseIngestMode(%t, %t): %v", tt.eventsOnly, tt.artifactsOnly, err)
+		}
+		if got != tt.want {
+			t.Errorf("parseIngestMode(%t, %t) = %q, want %q", tt.eventsOnly, tt.artifactsOnly, got, tt.want)
+		}
+	}
+}
+
+// --- handleShutdownSignals ---
+
+func TestHandleShutdownSignals_NoGraceCancelsImmediately(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(t.Context())
+	defer cancel(nil)
+	sigCh := make(chan os.Signal, 1)
+
+	// Without a grace period the drain channel is nil and never closed
+	sigCh <- syscall.SIGTERM
+	handleShutdownSignals(ctx, sigCh, 0, nil, cancel)
+
+	if ctx.Err() == nil {
+		t.Error("context should be canceled without a grace period")
+	}
+	assertInterrupted(t, ctx, "received terminated")
+}
+
+func TestHandleShutdownSignals_GraceDrainsThenSecondSignalCancels(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(t.Context())
+	defer cancel(nil)
+	sigCh := make(chan os.Signal, 1)
+	drain := make(chan struct{})
+
+	done := make(chan struct{})
+	go func() {
+		handleShutdownSignals(ctx, sigCh, time.Hour, drain, cancel)
+		close(done)
+	}()
+
+	sigCh <- syscall.SIGTERM
+	<-drain
+	if ctx.Err() != nil {
+		t.Fatal("first signal must drain, not cancel")
+	}
+
+	sigCh <- syscall.SIGTERM
+	<-done
+	if ctx.Err() == nil {
+		t.Error("second signal should cancel the context")
+	}
+	assertInterrupted(t, ctx, "received terminated again during drain")
+}
+
+func TestHandleShutdownSignals_GraceElapsedCancels(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(t.Context())
+	defer cancel(nil)
+	sigCh := make(chan os.Signal, 1)
+	drain := make(chan struct{})
+
+	sigCh <- syscall.SIGINT
+	handleShutdownSignals(ctx, sigCh, 20*time.Millisecond, drain, cancel)
+
+	if ctx.Err() == nil {
+		t.Error("context should be canceled once the grace period elapses")
+	}
+	assertInterrupted(t, ctx, "received interrupt, shutdown grace 20ms elapsed")
+	select {
+	case <-drain:
+	default:
+		t.Error("drain should be closed on the first signal")
+	}
+}
+
+// assertInterrupted checks that ctx was canceled with an interrupted cause.
+func assertInterrupted(t *testing.T, ctx context.Context, wantMessage string) {
+	t.Helper()
+	cause, ok := context.Cause(ctx).(*runtime.CancelCause)
+	if !ok {
+		t.Fatalf("cause = %v, want *runtime.CancelCause", context.Cause(ctx))
+	}
+	if cause.Reason != types.ReasonInterrupted || cause.Message != wantMessage {
+		t.Errorf("cause = %s %q, want %s %q", cause.Reason, cause.Message, types.ReasonInterrupted, wantMessage)
+	}
+}
+
+// --- checkRunPartition ---
+
+func TestCheckRunPartition(t *testing.T) {
+	dir := t.TempDir()
+	cfg := lode.Config{
+		Dataset:  "quarry",
+		Source:   "src",
+		Category: "default",
+		Day:      "2026-02-08",
+		RunID:    "run-001",
+		Policy:   "strict",
+	}
+	client, err := lode.NewLodeClient(cfg, dir)
+	if err != nil {
+		t.Fatalf("NewLodeClient failed: %v", err)
+	}
+
+	if err := checkRunPartition(client, cfg.RunID); err != nil {
+		t.Fatalf("empty partition should pass, got: %v", err)
+	}
+
+	complete := &types.EventEnvelope{
+		ContractVersion: types.ContractVersion,
+		EventID:         "evt-1",
+		RunID:           cfg.RunID,
+		Seq:             1,
+		Type:            types.EventTypeRunComplete,
+		Ts:              "2026-02-08T12:00:00Z",
+		Attempt:         1,
+	}
+	if err := client.WriteEvents(t.Context(), cfg.Dataset, cfg.RunID, []*types.EventEnvelope{complete}); err != nil {
+		t.Fatalf("WriteEvents failed: %v", err)
+	}
+
+	err = checkRunPartition(client, cfg.RunID)
+	if err == nil {
+		t.Fatal("expected error for partition with a completed run")
+	}
+	exitErr, ok := err.(cli.ExitCoder)
+	if !ok {
+		t.Fatalf("expected cli.ExitCoder, got %T: %v", err, err)
+	}
+	if exitErr.ExitCode() != exitConfigError {
+		t.Errorf("exit code = %d, want %d (exitConfigError)", exitErr.ExitCode(), exitConfigError)
+	}
+	for _, want := range []string{"already contains a completed run", "--parent-run-id run-001", "--overwrite"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should contain %q, got: %v", want, err)
+		}
+	}
+}
+
+func TestCheckRunPartition_StubClientSkipped(t *testing.T) {
+	if err := checkRunPartition(lode.NewStubClient(), "run-001"); err != nil {
+		t.Errorf("stub client should not be guarded, got: %v", err)
+	}
+}
+
+// --- outcomeToExitCode ---
+
+func TestOutcomeToExitCode(t *testing.T) {
+	tests := []struct {
+		status types.OutcomeStatus
+		want   int
+	}{
+		{types.OutcomeSuccess, exitSuccess},
+		{types.OutcomeScriptError, exitScriptError},
+		{types.OutcomeExecutorCrash, exitExecutorCrash},
+		{types.OutcomePolicyFailure, exitPolicyFailure},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			if got := outcomeToExitCode(tt.status, defaultExitCodes); got != tt.want {
+				t.Errorf("outcomeToExitCode(%q) = %d, want %d", tt.status, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunFinalizer_FinishOnComplete(t *testing.T) {
+	newFinalizer := func(command string, required bool) *runFinalizer {
+		return &runFinalizer{
+			collector: metrics.NewCollector("strict", "executor", "fs", "run-001", ""),
+			storage:   storageChoice{backend: "fs", path: "/data"},
+			source:    "src",
+			category:  "cat",
+			exitCodes: defaultExitCodes,
+			onComplete: &runtime.OnCompleteHook{
+				Command:  command,
+				Timeout:  5 * time.Second,
+				Required: required,
+			},
+		}
+	}
+	result := func(status types.OutcomeStatus) *runtime.RunResult {
+		return &runtime.RunResult{
+			RunMeta: &types.RunMeta{RunID: "run-001", Attempt: 1},
+			Outcome: &types.RunOutcome{Status: status},
+		}
+	}
+
+	// The hook receives the manifest and runs only after success
+	marker := filepath.Join(t.TempDir(), "manifest.json")
+	f := newFinalizer(`cat > "`+marker+`"`, false)
+	if got := f.finish(result(types.OutcomeScriptError), nil); got != exitScriptError {
+		t.Errorf("finish(script_error) = %d, want %d", got, exitScriptError)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatalf("hook ran after a failed run (stat err = %v)", err)
+	}
+	if got := f.finish(result(types.OutcomeSuccess), nil); got != exitSuccess {
+		t.Errorf("finish(success) = %d, want %d", got, exitSuccess)
+	}
+	manifest, err := runtime.ReadRunManifest(marker)
+	if err != nil {
+		t.Fatalf("hook stdin: %v", err)
+	}
+	if manifest.RunID != "run-001" || manifest.Storage == nil || manifest.Storage.Source != "src" {
+		t.Errorf("hook manifest = %+v", manifest)
+	}
+
+	// A failing hook is a warning unless required
+	if got := newFinalizer("exit 1", false).finish(result(types.OutcomeSuccess), nil); got != exitSuccess {
+		t.Errorf("optional hook failure: exit = %d, want %d", got, exitSuccess)
+	}
+	if got := newFinalizer("exit 1", true).finish(result(types.OutcomeSuccess), nil); got != exitPolicyFailure {
+		t.Errorf("required hook failure: exit = %d, want %d", got, exitPolicyFailure)
+	}
+}
+
+func TestOutcomeToExitCode_UnknownDefaultsToScriptError(t *testing.T) {
+	got := outcomeToExitCode(types.OutcomeStatus("unknown_status"), defaultExitCodes)
+	if got != exitScriptError {
+		t.Errorf("unknown status should map to exitScriptError (%d), got %d", exitScriptError, got)
+	}
+}
+
+// newExitCodeContext builds a context with the --exit-code-* flags at their
+// defaults, with set applied as explicit CLI values.
+func newExitCodeContext(t *testing.T, set map[string]string) *cli.Context {
+	t.Helper()
+	defaults := map[string]int{
+		"exit-code-success":        exitSuccess,
+		"exit-code-script-error":   exitScriptError,
+		"exit-code-executor-crash": exitExecutorCrash,
+		"exit-code-policy-failure": exitPolicyFailure,
+	}
+	app := cli.NewApp()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	for name, val := range defaults {
+		app.Flags = append(app.Flags, &cli.IntFlag{Name: name, Value: val})
+		fs.Int(name, val, "")
+	}
+	for name, val := range set {
+		if err := fs.Set(name, val); err != nil {
+			t.Fatalf("failed to set flag %s: %v", name, err)
+		}
+	}
+	return cli.NewContext(app, fs, nil)
+}
+
+func TestResolveExitCodes_DefaultUnchanged(t *testing.T) {
+	got, err := resolveExitCodes(newExitCodeContext(t, nil), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != defaultExitCodes {
+		t.Errorf("got %+v, want %+v", got, defaultExitCodes)
+	}
+}
+
+func TestResolveExitCodes_ConfigThenFlag(t *testing.T) {
+	ten, twenty, thirty := 10, 20, 30
+	cfg := &quarryconfig.Config{ExitCodes: quarryconfig.ExitCodesConfig{
+		ScriptError:   &ten,
+		ExecutorCrash: &twenty,
+		PolicyFailure: &thirty,
+	}}
+	c := newExitCodeContext(t, map[string]string{"exit-code-script-error": "11"})
+
+	got, err := resolveExitCodes(c, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := exitCodeMap{success: 0, scriptError: 11, executorCrash: 20, policyFailure: 30}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if code := outcomeToExitCode(types.OutcomeVersionMismatch, got); code != 30 {
+		t.Errorf("version_mismatch = %d, want policy_failure code 30", code)
+	}
+	if code := outcomeToExitCode(types.OutcomeStatus("unknown_status"), got); code != 11 {
+		t.Errorf("unknown status = %d, want script_error code 11", code)
+	}
+}
+
+func TestResolveExitCodes_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		set     map[string]string
+		wantErr string
+	}{
+		{"negative", map[string]string{"exit-code-success": "-1"}, "--exit-code-success must be in 0-255"},
+		{"too large", map[string]string{"exit-code-policy-failure": "256"}, "--exit-code-policy-failure must be in 0-255"},
+		{"duplicate", map[string]string{"exit-code-executor-crash": "1"}, "--exit-code-script-error and --exit-code-executor-crash both map to exit code 1"},
+		{"config error code", map[string]string{"exit-code-executor-crash": "20", "exit-code-script-error": "2"}, "--exit-code-script-error cannot be 2: exit code 2 is reserved for configuration errors"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := resolveExitCodes(newExitCodeContext(t, tt.set), nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOutcomeToExitCode_ContractValues(t *testing.T) {
+	// Verify the actual numeric values per CONTRACT_RUN.md
+	if exitSuccess != 0 {
+		t.Errorf("exitSuccess should be 0, got %d", exitSuccess)
+	}
+	if exitScriptError != 1 {
+		t.Errorf("exitScriptError should be 1, got %d", exitScriptError)
+	}
+	if exitExecutorCrash != 2 {
+		t.Errorf("exitExecutorCrash should be 2, got %d", exitExecutorCrash)
+	}
+	if exitPolicyFailure != 3 {
+		t.Errorf("exitPolicyFailure should be 3, got %d", exitPolicyFailure)
+	}
+}
+
+// --- buildStoragePath ---
+
+func TestBuildStoragePath_FS(t *testing.T) {
+	sc := storageChoice{backend: "fs", path: "/var/quarry/data"}
+	got := buildStoragePath(sc, "quarry", "my-source", "default", "2026-02-08", "", "run-001")
+
+	// Must be file:// scheme with absolute path
+	if !strings.HasPrefix(got, "file:///") {
+		t.Errorf("fs path should start with file:///, got %q", got)
+	}
+	// Must contain Hive-partitioned segments
+	for _, segment := range []string{
+		"datasets/quarry/partitions",
+		"source=my-source",
+		"category=default",
+		"day=2026-02-08",
+		"run_id=run-001",
+	} {
+		if !strings.Contains(got, segment) {
+			t.Errorf("fs path should contain %q, got %q", segment, got)
+		}
+	}
+}
+
+func TestBuildStoragePath_S3WithPrefix(t *testing.T) {
+	sc := storageChoice{backend: "s3", path: "my-bucket/quarry-data"}
+	got := buildStoragePath(sc, "quarry", "src", "cat", "2026-01-01", "", "run-x")
+
+	want := "s3://my-bucket/quarry-data/datasets/quarry/partitions/source=src/category=cat/day=2026-01-01/run_id=run-x"
+	if got != want {
+		t.Errorf("s3 with prefix:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestBuildStoragePath_S3BucketOnly(t *testing.T) {
+	sc := storageChoice{backend: "s3", path: "my-bucket"}
+	got := buildStoragePath(sc, "quarry", "src", "cat", "2026-01-01", "", "run-x")
+
+	want := "s3://my-bucket/datasets/quarry/partitions/source=src/category=cat/day=2026-01-01/run_id=run-x"
+	if got != want {
+		t.Errorf("s3 bucket only:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestBuildStoragePath_Memory(t *testing.T) {
+	sc := storageChoice{backend: "memory"}
+	got := buildStoragePath(sc, "quarry", "src", "cat", "2026-01-01", "", "run-x")
+
+	want := "memory://datasets/quarry/partitions/source=src/category=cat/day=2026-01-01/run_id=run-x"
+	if got != want {
+		t.Errorf("memory:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestBuildStorageSink_Memory(t *testing.T) {
+	sc := storageChoice{backend: "memory"}
+	sink, client, fw, err := buildStorageSink(sc, "quarry", "src", "cat", "run-mem", "strict", time.Now(), nil)
+	if err != nil {
+		t.Fatalf("buildStorageSink: %v", err)
+	}
+	defer func() { _ = sink.Close() }()
+
+	if err := fw.PutFile(t.Context(), "page.html", "text/html", []byte("<html></html>")); err != nil {
+		t.Fatalf("PutFile: %v", err)
+	}
+	lc, ok := client.(*lode.LodeClient)
+	if !ok {
+		t.Fatalf("client is %T, want *lode.LodeClient", client)
+	}
+	summary, err := lc.SummarizePartition(t.Context())
+	if err != nil {
+		t.Fatalf("SummarizePartition: %v", err)
+	}
+	if summary.Objects != 2 {
+		t.Errorf("memory partition holds %d objects, want 2 (file and .meta.json)", summary.Objects)
+	}
+}
+
+func TestBuildStoragePath_UnknownBackend(t *testing.T) {
+	sc := storageChoice{backend: "gcs", path: "/tmp"}
+	got := buildStoragePath(sc, "quarry", "src", "cat", "2026-01-01", "", "run-x")
+
+	// Unknown backend returns bare partition path (no scheme prefix)
+	if strings.Contains(got, "://") {
+		t.Errorf("unknown backend should not include scheme, got %q", got)
+	}
+	if !strings.HasPrefix(got, "datasets/") {
+		t.Errorf("unknown backend should return bare partition path, got %q", got)
+	}
+}
+
+func TestBuildStoragePath_PrefixTemplate(t *testing.T) {
+	pt, err := lode.ParsePartitionTemplate("year={{.Year}}/month={{.Month}}/day={{.Day}}/source={{.Source}}/run_id={{.RunID}}")
+	if err != nil {
+		t.Fatalf("ParsePartitionTemplate failed: %v", err)
+	}
+	sc := storageChoice{backend: "s3", path: "my-bucket", partitionTemplate: pt}
+	got := buildStoragePath(sc, "quarry", "src", "cat", "2026-01-01", "", "run-x")
+
+	want := "s3://my-bucket/datasets/quarry/partitions/year=2026/month=01/day=2026-01-01/source=src/run_id=run-x"
+	if got != want {
+		t.Errorf("s3 with prefix template:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestBuildStoragePath_HourlyPartitions(t *testing.T) {
+	sc := storageChoice{backend: "s3", path: "my-bucket"}
+	if err := applyPartitionGranularity(&sc, "hour"); err != nil {
+		t.Fatalf("applyPartitionGranularity: %v", err)
+	}
+	// Day and hour derive from the same instant, even across UTC midnight
+	start := time.Date(2026, 1, 1, 19, 30, 0, 0, time.FixedZone("EST", -5*3600))
+	got := buildStoragePath(sc, "quarry", "src", "cat", sc.partitionDay(start), sc.partitionHour(start), "run-x")
+
+	want := "s3://my-bucket/datasets/quarry/partitions/source=src/category=cat/day=2026-01-02/hour=00/run_id=run-x"
+	if got != want {
+		t.Errorf("s3 hourly:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestApplyPartitionGranularity(t *testing.T) {
+	yearLayout, err := lode.ParsePartitionTemplate("year={{.Year}}/day={{.Day}}/source={{.Source}}/run_id={{.RunID}}")
+	if err != nil {
+		t.Fatalf("ParsePartitionTemplate failed: %v", err)
+	}
+	noDay, err := lode.ParsePartitionTemplate("source={{.Source}}/run_id={{.RunID}}")
+	if err != nil {
+		t.Fatalf("ParsePartitionTemplate failed: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		config      storageChoice
+		granularity string
+		wantLayout  string // empty: default layout left unset
+		wantErr     string
+	}{
+		{name: "default", granularity: "day"},
+		{name: "unset", granularity: ""},
+		{name: "hour", granularity: "hour", wantLayout: "source={{.Source}}/category={{.Category}}/day={{.Day}}/hour={{.Hour}}/run_id={{.RunID}}"},
+		{name: "hour with template", config: storageChoice{partitionTemplate: yearLayout}, granularity: "hour", wantLayout: "year={{.Year}}/day={{.Day}}/hour={{.Hour}}/source={{.Source}}/run_id={{.RunID}}"},
+		{name: "template without day", config: storageChoice{partitionTemplate: noDay}, granularity: "hour", wantErr: "require a day key"},
+		{name: "storage day override", config: storageChoice{day: "2026-01-01"}, granularity: "hour", wantErr: "--storage-day"},
+		{name: "unknown", granularity: "minute", wantErr: "must be day or hour"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := tt.config
+			err := applyPartitionGranularity(&sc, tt.granularity)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if sc.hourly != (tt.granularity == "hour") {
+				t.Errorf("hourly = %v", sc.hourly)
+			}
+			if tt.wantLayout == "" {
+				if sc.partitionTemplate != tt.config.partitionTemplate {
+					t.Error("daily granularity must leave the layout unchanged")
+				}
+				if h := sc.partitionHour(time.Now()); h != "" {
+					t.Errorf("daily partitionHour = %q, want empty", h)
+				}
+				return
+			}
+			if got := sc.partitionTemplate.String(); got != tt.wantLayout {
+				t.Errorf("layout = %q, want %q", got, tt.wantLayout)
+			}
+		})
+	}
+}
+
+func TestBuildStoragePath_Tenant(t *testing.T) {
+	sc := storageChoice{backend: "s3", path: "my-bucket/prefix", tenant: "acme"}
+	got := buildStoragePath(sc, "quarry", "src", "cat", "2026-01-01", "", "run-x")
+
+	want := "s3://my-bucket/prefix/datasets/quarry/partitions/tenant=acme/source=src/category=cat/day=2026-01-01/run_id=run-x"
+	if got != want {
+		t.Errorf("s3 with tenant:\ngot  %q\nwant %q", got, want)
+	}
+}
+
+func TestResolveTenant(t *testing.T) {
+	tests := []struct {
+		name    string
+		flag    string
+		cfg     *quarryconfig.Config
+		want    string
+		wantErr string
+	}{
+		{name: "unset", want: ""},
+		{name: "flag", flag: "acme", want: "acme"},
+		{name: "config", cfg: &quarryconfig.Config{Tenant: "globex"}, want: "globex"},
+		{name: "flag overrides config", flag: "acme", cfg: &quarryconfig.Config{Tenant: "globex"}, want: "acme"},
+		{name: "required but missing", cfg: &quarryconfig.Config{RequireTenant: true}, wantErr: "require_tenant"},
+		{name: "allowlisted", flag: "acme", cfg: &quarryconfig.Config{TenantPattern: "acme|globex"}, want: "acme"},
+		{name: "not allowlisted", flag: "initech", cfg: &quarryconfig.Config{TenantPattern: "acme|globex"}, wantErr: "allowlist"},
+		{name: "unsafe id", flag: "../acme", wantErr: "invalid --tenant"},
+		{name: "bad pattern", flag: "acme", cfg: &quarryconfig.Config{TenantPattern: "("}, wantErr: "invalid tenant_pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c *cli.Context
+			if tt.flag != "" {
+				c = newTestCLIContext(t, map[string]string{"tenant": tt.flag}, nil)
+			} else {
+				c = newTestCLIContext(t, nil, map[string]string{"tenant": ""})
+			}
+			got, err := resolveTenant(c, tt.cfg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("tenant = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// --- buildRunCompletedEvent ---
+
+func TestBuildRunCompletedEvent_BasicFields(t *testing.T) {
+	result := &runtime.RunResult{
+		RunMeta: &types.RunMeta{
+			RunID:   "run-001",
+			Attempt: 1,
+		},
+		Outcome: &types.RunOutcome{
+			Status: types.OutcomeSuccess,
+		},
+		EventCount: 42,
+	}
+	sc := storageChoice{backend: "fs", path: "/tmp/data"}
+	event := buildRunCompletedEvent(result, sc, "quarry", "src", "cat", "2026-02-08", "", 5*time.Second, adapter.DefaultErrorMaxLen)
+
+	if event.ContractVersion != types.ContractVersion {
+		t.Errorf("ContractVersion = %q, want %q", event.ContractVersion, types.ContractVersion)
+	}
+	if event.EventType != "run_completed" {
+		t.Errorf("EventType = %q, want %q", event.EventType, "run_completed")
+	}
+	if event.RunID != "run-001" {
+		t.Errorf("RunID = %q, want %q", event.RunID, "run-001")
+	}
+	if event.Source != "src" {
+		t.Errorf("Source = %q, want %q", event.Source, "src")
+	}
+	if event.Category != "cat" {
+		t.Errorf("Category = %q, want %q", event.Category, "cat")
+	}
+	if event.Day != "2026-02-08" {
+		t.Errorf("Day = %q, want %q", event.Day, "2026-02-08")
+	}
+	if event.Outcome != "success" {
+		t.Errorf("Outcome = %q, want %q", event.Outcome, "success")
+	}
+	if event.Attempt != 1 {
+		t.Errorf("Attempt = %d, want %d", event.Attempt, 1)
+	}
+	if event.EventCount != 42 {
+		t.Errorf("EventCount = %d, want %d", event.EventCount, 42)
 	}
 	if event.DurationMs != 5000 {
 		t.Errorf("DurationMs = %d, want %d", event.DurationMs, 5000)
@@ -1104,7 +1942,7 @@ func TestBuildRunCompletedEvent_WithJobID(t *testing.T) {
 		Outcome: &types.RunOutcome{Status: types.OutcomeSuccess},
 	}
 	sc := storageChoice{backend: "fs", path: "/tmp"}
-	event := buildRunCompletedEvent(result, sc, "quarry", "src", "cat", "2026-02-08", time.Second)
+	event := buildRunCompletedEvent(result, sc, "quarry", "src", "cat", "2026-02-08", "", time.Second, adapter.DefaultErrorMaxLen)
 
 	if event.JobID != "job-abc" {
 		t.Errorf("JobID = %q, want %q", event.JobID, "job-abc")
@@ -1120,7 +1958,7 @@ func TestBuildRunCompletedEvent_WithoutJobID(t *testing.T) {
 		Outcome: &types.RunOutcome{Status: types.OutcomeScriptError},
 	}
 	sc := storageChoice{backend: "fs", path: "/tmp"}
-	event := buildRunCompletedEvent(result, sc, "quarry", "src", "cat", "2026-02-08", time.Second)
+	event := buildRunCompletedEvent(result, sc, "quarry", "src", "cat", "2026-02-08", "", time.Second, adapter.DefaultErrorMaxLen)
 
 	if event.JobID != "" {
 		t.Errorf("JobID should be empty when RunMeta.JobID is nil, got %q", event.JobID)
@@ -1140,7 +1978,7 @@ func TestBuildRunCompletedEvent_OutcomeMapsCorrectly(t *testing.T) {
 				Outcome: &types.RunOutcome{Status: status},
 			}
 			sc := storageChoice{backend: "fs", path: "/tmp"}
-			event := buildRunCompletedEvent(result, sc, "q", "s", "c", "d", 0)
+			event := buildRunCompletedEvent(result, sc, "q", "s", "c", "d", "", 0, adapter.DefaultErrorMaxLen)
 
 			if event.Outcome != string(status) {
 				t.Errorf("Outcome = %q, want %q", event.Outcome, string(status))
@@ -1149,6 +1987,167 @@ func TestBuildRunCompletedEvent_OutcomeMapsCorrectly(t *testing.T) {
 	}
 }
 
+func TestBuildRunCompletedEvent_SuccessOmitsErrorFields(t *testing.T) {
+	result := &runtime.RunResult{
+		RunMeta: &types.RunMeta{RunID: "r", Attempt: 1},
+		Outcome: &types.RunOutcome{Status: types.OutcomeSuccess, Message: "run completed"},
+	}
+	sc := storageChoice{backend: "fs", path: "/tmp"}
+	event := buildRunCompletedEvent(result, sc, "q", "s", "c", "d", "", 0, adapter.DefaultErrorMaxLen)
+
+	if event.ErrorType != "" || event.ErrorMessage != "" || event.ErrorStack != "" {
+		t.Errorf("success should omit error fields, got type=%q message=%q stack=%q",
+			event.ErrorType, event.ErrorMessage, event.ErrorStack)
+	}
+}
+
+func TestBuildRunCompletedEvent_ScriptErrorDetails(t *testing.T) {
+	errType := "TypeError"
+	stack := "TypeError: boom\n    at main (script.ts:1:1)"
+	result := &runtime.RunResult{
+		RunMeta: &types.RunMeta{RunID: "r", Attempt: 1},
+		Outcome: &types.RunOutcome{
+			Status:    types.OutcomeScriptError,
+			Message:   "boom",
+			ErrorType: &errType,
+			Stack:     &stack,
+		},
+	}
+	sc := storageChoice{backend: "fs", path: "/tmp"}
+	event := buildRunCompletedEvent(result, sc, "q", "s", "c", "d", "", 0, adapter.DefaultErrorMaxLen)
+
+	if event.ErrorType != "TypeError" {
+		t.Errorf("ErrorType = %q, want %q", event.ErrorType, "TypeError")
+	}
+	if event.ErrorMessage != "boom" {
+		t.Errorf("ErrorMessage = %q, want %q", event.ErrorMessage, "boom")
+	}
+	if event.ErrorStack != stack {
+		t.Errorf("ErrorStack = %q, want %q", event.ErrorStack, stack)
+	}
+}
+
+func TestBuildRunCompletedEvent_ErrorTypeFallsBackToOutcome(t *testing.T) {
+	result := &runtime.RunResult{
+		RunMeta: &types.RunMeta{RunID: "r", Attempt: 1},
+		Outcome: &types.RunOutcome{Status: types.OutcomeExecutorCrash, Message: "stream error: EOF"},
+	}
+	sc := storageChoice{backend: "fs", path: "/tmp"}
+	event := buildRunCompletedEvent(result, sc, "q", "s", "c", "d", "", 0, adapter.DefaultErrorMaxLen)
+
+	if event.ErrorType != "executor_crash" {
+		t.Errorf("ErrorType = %q, want %q", event.ErrorType, "executor_crash")
+	}
+	if event.ErrorStack != "" {
+		t.Errorf("ErrorStack should be empty without a stack, got %q", event.ErrorStack)
+	}
+}
+
+func TestBuildRunCompletedEvent_CarriesReason(t *testing.T) {
+	result := &runtime.RunResult{
+		RunMeta: &types.RunMeta{RunID: "r", Attempt: 1},
+		Outcome: &types.RunOutcome{
+			Status:  types.OutcomeExecutorCrash,
+			Reason:  types.ReasonStreamTruncated,
+			Message: "stream error: frame error: short read",
+		},
+	}
+	sc := storageChoice{backend: "fs", path: "/tmp"}
+	event := buildRunCompletedEvent(result, sc, "q", "s", "c", "d", "", 0, adapter.DefaultErrorMaxLen)
+
+	if event.Outcome != "executor_crash" {
+		t.Errorf("Outcome = %q, want executor_crash", event.Outcome)
+	}
+	if event.Reason != "stream_truncated" {
+		t.Errorf("Reason = %q, want stream_truncated", event.Reason)
+	}
+}
+
+func TestBuildRunCompletedEvent_CarriesLabels(t *testing.T) {
+	result := &runtime.RunResult{
+		RunMeta: &types.RunMeta{RunID: "r", Attempt: 1, Labels: map[string]string{"team": "growth"}},
+		Outcome: &types.RunOutcome{Status: types.OutcomeSuccess},
+	}
+	sc := storageChoice{backend: "fs", path: "/tmp"}
+	event := buildRunCompletedEvent(result, sc, "q", "s", "c", "d", "", 0, adapter.DefaultErrorMaxLen)
+
+	if event.Labels["team"] != "growth" {
+		t.Errorf("Labels = %v, want team=growth", event.Labels)
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      map[string]string
+		flags       []string
+		want        map[string]string
+		errContains string
+	}{
+		{name: "none", want: nil},
+		{
+			name:   "config and flags merge, flag wins",
+			config: map[string]string{"team": "core", "campaign": "spring"},
+			flags:  []string{"team=growth", "cost-center=cc42"},
+			want:   map[string]string{"team": "growth", "campaign": "spring", "cost-center": "cc42"},
+		},
+		{
+			name:  "value may contain equals",
+			flags: []string{"query=a=b"},
+			want:  map[string]string{"query": "a=b"},
+		},
+		{name: "missing equals", flags: []string{"team"}, errContains: "expected key=value"},
+		{name: "empty key", flags: []string{"=growth"}, errContains: "expected key=value"},
+		{name: "invalid key", flags: []string{"my team=growth"}, errContains: "invalid label key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLabels(tt.config, tt.flags)
+			if tt.errContains != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
+					t.Fatalf("error = %v, want containing %q", err, tt.errContains)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("labels = %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("labels[%q] = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildRunCompletedEvent_TruncatesErrorFields(t *testing.T) {
+	stack := strings.Repeat("at frame\n", 100)
+	result := &runtime.RunResult{
+		RunMeta: &types.RunMeta{RunID: "r", Attempt: 1},
+		Outcome: &types.RunOutcome{
+			Status:  types.OutcomeScriptError,
+			Message: strings.Repeat("x", 500),
+			Stack:   &stack,
+		},
+	}
+	sc := storageChoice{backend: "fs", path: "/tmp"}
+	event := buildRunCompletedEvent(result, sc, "q", "s", "c", "d", "", 0, 64)
+
+	if len(event.ErrorMessage) > 64 {
+		t.Errorf("ErrorMessage length = %d, want <= 64", len(event.ErrorMessage))
+	}
+	if len(event.ErrorStack) > 64 {
+		t.Errorf("ErrorStack length = %d, want <= 64", len(event.ErrorStack))
+	}
+	if !strings.HasSuffix(event.ErrorMessage, "(truncated)") {
+		t.Errorf("truncated message should carry marker, got %q", event.ErrorMessage)
+	}
+}
+
 // --- validateFanOutConfig ---
 
 func TestValidateFanOutConfig(t *testing.T) {
@@ -1185,6 +2184,12 @@ func TestValidateFanOutConfig(t *testing.T) {
 			wantErr:     true,
 			errContains: "--max-runs is required when --depth > 0",
 		},
+		{
+			name:        "input-urls without depth rejected",
+			choice:      fanOutChoice{depth: 0, maxRuns: 10, parallel: 1, inputURLs: "urls.txt"},
+			wantErr:     true,
+			errContains: "--input-urls requires --depth > 0",
+		},
 		{
 			name:        "negative max-runs rejected",
 			choice:      fanOutChoice{depth: 0, maxRuns: -1, parallel: 1},
@@ -1197,6 +2202,69 @@ func TestValidateFanOutConfig(t *testing.T) {
 			wantErr:     true,
 			errContains: "--parallel must be >= 1",
 		},
+		{
+			name:        "negative max-bytes-per-child rejected",
+			choice:      fanOutChoice{depth: 1, maxRuns: 10, parallel: 1, maxBytesPerChild: -1},
+			wantErr:     true,
+			errContains: "--max-bytes-per-child must be >= 0",
+		},
+		{
+			name:        "negative max-artifacts-per-child rejected",
+			choice:      fanOutChoice{depth: 1, maxRuns: 10, parallel: 1, maxArtifactsPerChild: -1},
+			wantErr:     true,
+			errContains: "--max-artifacts-per-child must be >= 0",
+		},
+		{
+			name:    "per-child budgets are valid",
+			choice:  fanOutChoice{depth: 1, maxRuns: 10, parallel: 1, maxBytesPerChild: 1 << 30, maxArtifactsPerChild: 100},
+			wantErr: false,
+		},
+		{
+			name:    "dedupe param identity is valid",
+			choice:  fanOutChoice{depth: 1, maxRuns: 10, parallel: 1, dedupeBy: "param:url", dedupeCapacity: 1000},
+			wantErr: false,
+		},
+		{
+			name:        "unknown dedupe identity rejected",
+			choice:      fanOutChoice{depth: 1, maxRuns: 10, parallel: 1, dedupeBy: "url"},
+			wantErr:     true,
+			errContains: "--dedupe-enqueues",
+		},
+		{
+			name:        "negative dedupe-capacity rejected",
+			choice:      fanOutChoice{depth: 1, maxRuns: 10, parallel: 1, dedupeCapacity: -1},
+			wantErr:     true,
+			errContains: "--dedupe-capacity must be >= 0",
+		},
+		{
+			name:        "negative retry-per-item rejected",
+			choice:      fanOutChoice{depth: 1, maxRuns: 10, parallel: 1, retryPerItem: -1},
+			wantErr:     true,
+			errContains: "--retry-per-item must be >= 0",
+		},
+		{
+			name:        "negative per-origin-concurrency rejected",
+			choice:      fanOutChoice{depth: 1, maxRuns: 10, parallel: 1, perOriginConcurrency: -1},
+			wantErr:     true,
+			errContains: "--per-origin-concurrency must be >= 0",
+		},
+		{
+			name:        "negative origin-stagger rejected",
+			choice:      fanOutChoice{depth: 1, maxRuns: 10, parallel: 1, originStagger: -time.Second},
+			wantErr:     true,
+			errContains: "--origin-stagger must be >= 0",
+		},
+		{
+			name:    "fanout-on-error abort is valid",
+			choice:  fanOutChoice{depth: 1, maxRuns: 10, parallel: 1, onError: fanOutOnErrorAbort},
+			wantErr: false,
+		},
+		{
+			name:        "unknown fanout-on-error rejected",
+			choice:      fanOutChoice{depth: 1, maxRuns: 10, parallel: 1, onError: "stop"},
+			wantErr:     true,
+			errContains: "invalid --fanout-on-error",
+		},
 		{
 			name:    "depth=0 max-runs=0 parallel=1 is default valid state",
 			choice:  fanOutChoice{depth: 0, maxRuns: 0, parallel: 1},
@@ -1206,7 +2274,7 @@ func TestValidateFanOutConfig(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := validateFanOutConfig(tt.choice)
+			err := validateFanOutConfig(&tt.choice)
 			if tt.wantErr {
 				if err == nil {
 					t.Error("expected error, got nil")
@@ -1231,59 +2299,252 @@ func newAdapterTestContext(t *testing.T, flags map[string]string, sliceFlags map
 		&cli.StringFlag{Name: "adapter-channel"},
 		&cli.DurationFlag{Name: "adapter-timeout", Value: 10 * time.Second},
 		&cli.IntFlag{Name: "adapter-retries", Value: 3},
+		&cli.IntFlag{Name: "adapter-error-max-len", Value: adapter.DefaultErrorMaxLen},
 		&cli.StringSliceFlag{Name: "adapter-header"},
 	}
 
-	fs := flag.NewFlagSet("test", flag.ContinueOnError)
-	fs.String("adapter-url", "", "")
-	fs.String("adapter-channel", "", "")
-	fs.Duration("adapter-timeout", 10*time.Second, "")
-	fs.Int("adapter-retries", 3, "")
-
-	// Register the string slice in the flagset via a multi-value approach.
-	// urfave/cli uses its own internal plumbing for slices, so we handle
-	// headers through the config path or by setting the flag manually.
-	for name, val := range flags {
-		if err := fs.Set(name, val); err != nil {
-			t.Fatalf("failed to set flag %s: %v", name, err)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("adapter-url", "", "")
+	fs.String("adapter-channel", "", "")
+	fs.Duration("adapter-timeout", 10*time.Second, "")
+	fs.Int("adapter-retries", 3, "")
+	fs.Int("adapter-error-max-len", adapter.DefaultErrorMaxLen, "")
+	fs.String("adapter-webhook-client-cert", "", "")
+	fs.String("adapter-webhook-client-key", "", "")
+	fs.String("adapter-webhook-ca", "", "")
+	fs.Int64("adapter-file-max-bytes", fileadapter.DefaultMaxBytes, "")
+	fs.Bool("adapter-redis-pipeline", false, "")
+	fs.String("adapter-template", "", "")
+
+	// Register the string slice in the flagset via a multi-value approach.
+	// urfave/cli uses its own internal plumbing for slices, so we handle
+	// headers through the config path or by setting the flag manually.
+	for name, val := range flags {
+		if err := fs.Set(name, val); err != nil {
+			t.Fatalf("failed to set flag %s: %v", name, err)
+		}
+	}
+
+	c := cli.NewContext(app, fs, nil)
+
+	// For string slice flags, we need to work within urfave's model.
+	// The test cases that exercise headers use config-based headers instead
+	// since urfave slices require the full app.Run() path.
+	_ = sliceFlags
+	return c
+}
+
+func TestParseAdapterConfig_WebhookValid(t *testing.T) {
+	c := newAdapterTestContext(t, map[string]string{
+		"adapter-url": "https://hooks.example.com/quarry",
+	}, nil)
+
+	ac, err := parseAdapterConfigWithPrecedence(c, nil, "webhook")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ac.adapterType != "webhook" {
+		t.Errorf("adapterType = %q, want %q", ac.adapterType, "webhook")
+	}
+	if ac.url != "https://hooks.example.com/quarry" {
+		t.Errorf("url = %q, want %q", ac.url, "https://hooks.example.com/quarry")
+	}
+}
+
+func TestParseAdapterConfig_WebhookMissingURL(t *testing.T) {
+	c := newAdapterTestContext(t, nil, nil)
+
+	_, err := parseAdapterConfigWithPrecedence(c, nil, "webhook")
+	if err == nil {
+		t.Fatal("expected error for missing URL")
+	}
+	if !strings.Contains(err.Error(), "--adapter-url is required") {
+		t.Errorf("error should mention --adapter-url, got: %v", err)
+	}
+}
+
+func TestParseAdapterConfig_WebhookTLSValidatedAtParse(t *testing.T) {
+	// A cert without its key fails config validation, not the first publish
+	c := newAdapterTestContext(t, map[string]string{
+		"adapter-url":                 "https://hooks.example.com/quarry",
+		"adapter-webhook-client-cert": "/etc/quarry/client.crt",
+	}, nil)
+	if _, err := parseAdapterConfigWithPrecedence(c, nil, "webhook"); err == nil || !strings.Contains(err.Error(), "must be set together") {
+		t.Errorf("expected unpaired cert error, got %v", err)
+	}
+
+	// Config-provided files are loaded at parse time too
+	cfg := &quarryconfig.Config{Adapter: quarryconfig.AdapterConfig{
+		Webhook: quarryconfig.WebhookAdapterConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")},
+	}}
+	c = newAdapterTestContext(t, map[string]string{"adapter-url": "https://hooks.example.com/quarry"}, nil)
+	if _, err := parseAdapterConfigWithPrecedence(c, cfg, "webhook"); err == nil || !strings.Contains(err.Error(), "read ca file") {
+		t.Errorf("expected missing CA error, got %v", err)
+	}
+}
+
+func TestParseAdapterConfig_WebhookRetryStatusFromConfig(t *testing.T) {
+	cfg := &quarryconfig.Config{Adapter: quarryconfig.AdapterConfig{
+		Webhook: quarryconfig.WebhookAdapterConfig{RetryStatus: quarryconfig.StringList{"409", "5xx"}},
+	}}
+	c := newAdapterTestContext(t, map[string]string{"adapter-url": "https://hooks.example.com/quarry"}, nil)
+	ac, err := parseAdapterConfigWithPrecedence(c, cfg, "webhook")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(ac.retryStatus, []string{"409", "5xx"}) {
+		t.Errorf("retryStatus = %v, want [409 5xx]", ac.retryStatus)
+	}
+
+	// Invalid statuses fail config validation
+	cfg.Adapter.Webhook.RetryStatus = quarryconfig.StringList{"6xx"}
+	if _, err := parseAdapterConfigWithPrecedence(c, cfg, "webhook"); err == nil || !strings.Contains(err.Error(), "--adapter-webhook-retry-status") {
+		t.Errorf("expected invalid retry status error, got %v", err)
+	}
+}
+
+func TestParseAdapterConfig_PayloadTemplate(t *testing.T) {
+	c := newAdapterTestContext(t, map[string]string{
+		"adapter-url":      "https://hooks.example.com/quarry",
+		"adapter-template": `{"id": {{json .run_id}}, "status": {{json .outcome}}}`,
+	}, nil)
+	ac, err := parseAdapterConfigWithPrecedence(c, nil, "webhook")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := ac.encoder.(*adapter.Template); !ok {
+		t.Errorf("encoder = %T, want *adapter.Template", ac.encoder)
+	}
+
+	// Invalid templates fail config validation
+	c = newAdapterTestContext(t, map[string]string{
+		"adapter-url":      "https://hooks.example.com/quarry",
+		"adapter-template": `{"id": {{json .runid}}}`,
+	}, nil)
+	if _, err := parseAdapterConfigWithPrecedence(c, nil, "webhook"); err == nil || !strings.Contains(err.Error(), "--adapter-template") {
+		t.Errorf("expected invalid template error, got %v", err)
+	}
+}
+
+func TestParseAdapterConfig_PayloadFieldsFromConfig(t *testing.T) {
+	cfg := &quarryconfig.Config{Adapter: quarryconfig.AdapterConfig{
+		Fields: map[string]string{"id": "run_id", "status": "outcome"},
+	}}
+	c := newAdapterTestContext(t, map[string]string{"adapter-url": "redis://localhost:6379"}, nil)
+	ac, err := parseAdapterConfigWithPrecedence(c, cfg, "redis")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := ac.encoder.(adapter.FieldMap); !ok {
+		t.Errorf("encoder = %T, want adapter.FieldMap", ac.encoder)
+	}
+
+	// Template and field map together are rejected
+	c = newAdapterTestContext(t, map[string]string{
+		"adapter-url":      "redis://localhost:6379",
+		"adapter-template": `{"id": {{json .run_id}}}`,
+	}, nil)
+	if _, err := parseAdapterConfigWithPrecedence(c, cfg, "redis"); err == nil || !strings.Contains(err.Error(), "mutually exclusive") {
+		t.Errorf("expected mutually exclusive error, got %v", err)
+	}
+}
+
+func TestParseAdapterConfig_NoPayloadEncoder(t *testing.T) {
+	c := newAdapterTestContext(t, map[string]string{"adapter-url": "redis://localhost:6379"}, nil)
+	ac, err := parseAdapterConfigWithPrecedence(c, nil, "redis")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ac.encoder != nil {
+		t.Errorf("encoder = %T, want nil (canonical shape)", ac.encoder)
+	}
+}
+
+func TestParseAdapterOn(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   []types.OutcomeStatus // nil = always
+	}{
+		{"always", []string{"always"}, nil},
+		{"always wins in a list", []string{"success", "always"}, nil},
+		{"success", []string{"success"}, []types.OutcomeStatus{types.OutcomeSuccess}},
+		{"failure", []string{"failure"}, failureOutcomes},
+		{"statuses", []string{"script_error", " policy_failure"}, []types.OutcomeStatus{types.OutcomeScriptError, types.OutcomePolicyFailure}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			on, err := parseAdapterOn(tt.values)
+			if err != nil {
+				t.Fatalf("parseAdapterOn: %v", err)
+			}
+			if tt.want == nil {
+				if on != nil {
+					t.Errorf("on = %v, want nil (always)", on)
+				}
+				return
+			}
+			if len(on) != len(tt.want) {
+				t.Errorf("on = %v, want %v", on, tt.want)
+			}
+			for _, status := range tt.want {
+				if !on[status] {
+					t.Errorf("on = %v, missing %s", on, status)
+				}
+			}
+		})
+	}
+
+	for _, values := range [][]string{{"sometimes"}, {"completed"}, {""}} {
+		if _, err := parseAdapterOn(values); err == nil {
+			t.Errorf("parseAdapterOn(%q): expected error", values)
 		}
 	}
-
-	c := cli.NewContext(app, fs, nil)
-
-	// For string slice flags, we need to work within urfave's model.
-	// The test cases that exercise headers use config-based headers instead
-	// since urfave slices require the full app.Run() path.
-	_ = sliceFlags
-	return c
 }
 
-func TestParseAdapterConfig_WebhookValid(t *testing.T) {
-	c := newAdapterTestContext(t, map[string]string{
-		"adapter-url": "https://hooks.example.com/quarry",
-	}, nil)
-
-	ac, err := parseAdapterConfigWithPrecedence(c, nil, "webhook")
+func TestParseAdapterConfig_OnFromConfig(t *testing.T) {
+	cfg := &quarryconfig.Config{Adapter: quarryconfig.AdapterConfig{On: quarryconfig.StringList{"failure"}}}
+	c := newAdapterTestContext(t, map[string]string{"adapter-url": "redis://localhost:6379"}, nil)
+	ac, err := parseAdapterConfigWithPrecedence(c, cfg, "redis")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if ac.adapterType != "webhook" {
-		t.Errorf("adapterType = %q, want %q", ac.adapterType, "webhook")
+	if ac.notifies(types.OutcomeSuccess) || !ac.notifies(types.OutcomeExecutorCrash) {
+		t.Errorf("on = %v, want failures only", ac.on)
 	}
-	if ac.url != "https://hooks.example.com/quarry" {
-		t.Errorf("url = %q, want %q", ac.url, "https://hooks.example.com/quarry")
+
+	ac, err = parseAdapterConfigWithPrecedence(c, nil, "redis")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ac.on != nil {
+		t.Errorf("default on = %v, want nil (always)", ac.on)
 	}
 }
 
-func TestParseAdapterConfig_WebhookMissingURL(t *testing.T) {
-	c := newAdapterTestContext(t, nil, nil)
+func TestSharedAdapter_SkipsFilteredOutcomes(t *testing.T) {
+	outbox := filepath.Join(t.TempDir(), "outbox.jsonl")
+	on, _ := parseAdapterOn([]string{"success"})
+	notifier := newSharedAdapter(&adapterChoice{
+		adapterType:  "file",
+		url:          outbox,
+		timeout:      5 * time.Second,
+		fileMaxBytes: fileadapter.DefaultMaxBytes,
+		on:           on,
+	})
+	t.Cleanup(func() { _ = notifier.Close() })
 
-	_, err := parseAdapterConfigWithPrecedence(c, nil, "webhook")
-	if err == nil {
-		t.Fatal("expected error for missing URL")
+	for _, status := range []types.OutcomeStatus{types.OutcomeScriptError, types.OutcomeSuccess} {
+		result := &runtime.RunResult{
+			RunMeta: &types.RunMeta{RunID: "run-" + string(status), Attempt: 1},
+			Outcome: &types.RunOutcome{Status: status},
+		}
+		notifier.notify(result, storageChoice{backend: "fs", path: "/data"}, "quarry", "src", "cat", "2026-10-15", "", time.Second)
 	}
-	if !strings.Contains(err.Error(), "--adapter-url is required") {
-		t.Errorf("error should mention --adapter-url, got: %v", err)
+
+	events := readOutbox(t, outbox)
+	if len(events) != 1 || events[0].RunID != "run-success" {
+		t.Errorf("published %v, want only the success run", events)
 	}
 }
 
@@ -1322,18 +2583,281 @@ func TestParseAdapterConfig_UnknownType(t *testing.T) {
 		"adapter-url": "https://example.com",
 	}, nil)
 
-	_, err := parseAdapterConfigWithPrecedence(c, nil, "kafka")
+	_, err := parseAdapterConfigWithPrecedence(c, nil, "nats")
 	if err == nil {
 		t.Fatal("expected error for unknown adapter type")
 	}
 	if !strings.Contains(err.Error(), "unknown adapter type") {
 		t.Errorf("error should mention unknown type, got: %v", err)
 	}
-	if !strings.Contains(err.Error(), "kafka") {
+	if !strings.Contains(err.Error(), "nats") {
 		t.Errorf("error should include the bad type name, got: %v", err)
 	}
 }
 
+func TestParseAdapterConfig_KafkaValid(t *testing.T) {
+	c := newAdapterTestContext(t, map[string]string{
+		"adapter-url":     "broker1:9092,broker2:9092",
+		"adapter-channel": "runs",
+	}, nil)
+	cfg := &quarryconfig.Config{
+		Adapter: quarryconfig.AdapterConfig{
+			Kafka: &quarryconfig.KafkaAdapterConfig{
+				TLS:  true,
+				SASL: &quarryconfig.KafkaSASLConfig{Mechanism: "scram-sha-512", Username: "u", Password: "p"},
+			},
+		},
+	}
+
+	ac, err := parseAdapterConfigWithPrecedence(c, cfg, "kafka")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ac.channel != "runs" {
+		t.Errorf("channel = %q, want topic %q", ac.channel, "runs")
+	}
+	if ac.kafka == nil || ac.kafka.SASL == nil || !ac.kafka.TLS {
+		t.Fatalf("kafka security config should come from config, got %+v", ac.kafka)
+	}
+
+	adpt, err := buildAdapter(ac)
+	if err != nil {
+		t.Fatalf("buildAdapter: %v", err)
+	}
+	iox.DiscardClose(adpt)
+}
+
+func TestParseAdapterConfig_KafkaMissingURL(t *testing.T) {
+	c := newAdapterTestContext(t, nil, nil)
+
+	_, err := parseAdapterConfigWithPrecedence(c, nil, "kafka")
+	if err == nil {
+		t.Fatal("expected error for missing brokers")
+	}
+	if !strings.Contains(err.Error(), "--adapter-url is required when --adapter=kafka") {
+		t.Errorf("error should mention kafka URL requirement, got: %v", err)
+	}
+}
+
+func TestBuildAdapter_KafkaInvalidSASL(t *testing.T) {
+	ac := adapterChoice{
+		adapterType: "kafka",
+		url:         "broker1:9092",
+		kafka: &quarryconfig.KafkaAdapterConfig{
+			SASL: &quarryconfig.KafkaSASLConfig{Mechanism: "gssapi", Username: "u", Password: "p"},
+		},
+	}
+	if _, err := buildAdapter(ac); err == nil || !strings.Contains(err.Error(), "unsupported sasl mechanism") {
+		t.Errorf("expected unsupported sasl mechanism error, got: %v", err)
+	}
+}
+
+func TestParseAdapterConfig_FileValid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	c := newAdapterTestContext(t, map[string]string{"adapter-url": path}, nil)
+
+	ac, err := parseAdapterConfigWithPrecedence(c, nil, "file")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ac.fileMaxBytes != fileadapter.DefaultMaxBytes {
+		t.Errorf("fileMaxBytes = %d, want default %d", ac.fileMaxBytes, fileadapter.DefaultMaxBytes)
+	}
+	a, err := buildAdapter(ac)
+	if err != nil {
+		t.Fatalf("buildAdapter: %v", err)
+	}
+	if _, ok := a.(*fileadapter.Adapter); !ok {
+		t.Errorf("expected *file.Adapter, got %T", a)
+	}
+}
+
+func TestParseAdapterConfig_FileMissingURL(t *testing.T) {
+	c := newAdapterTestContext(t, nil, nil)
+	if _, err := parseAdapterConfigWithPrecedence(c, nil, "file"); err == nil || !strings.Contains(err.Error(), "--adapter-url is required") {
+		t.Errorf("expected missing URL error, got: %v", err)
+	}
+}
+
+func TestParseAdapterConfig_FileMaxBytes(t *testing.T) {
+	c := newAdapterTestContext(t, map[string]string{"adapter-url": "/tmp/outbox.jsonl"}, nil)
+	cfg := &quarryconfig.Config{Adapter: quarryconfig.AdapterConfig{File: quarryconfig.FileAdapterConfig{MaxBytes: -1}}}
+	ac, err := parseAdapterConfigWithPrecedence(c, cfg, "file")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ac.fileMaxBytes != -1 {
+		t.Errorf("fileMaxBytes = %d, want -1 from config", ac.fileMaxBytes)
+	}
+
+	c = newAdapterTestContext(t, map[string]string{"adapter-url": "/tmp/outbox.jsonl", "adapter-file-max-bytes": "-5"}, nil)
+	if _, err := parseAdapterConfigWithPrecedence(c, nil, "file"); err == nil || !strings.Contains(err.Error(), "--adapter-file-max-bytes") {
+		t.Errorf("expected max-bytes validation error, got: %v", err)
+	}
+}
+
+func TestParseAdapterConfig_RedisPipeline(t *testing.T) {
+	c := newAdapterTestContext(t, map[string]string{"adapter-url": "redis://localhost:6379"}, nil)
+	ac, err := parseAdapterConfigWithPrecedence(c, nil, "redis")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ac.pipeline {
+		t.Error("pipeline should default to off")
+	}
+
+	cfg := &quarryconfig.Config{Adapter: quarryconfig.AdapterConfig{Redis: quarryconfig.RedisAdapterConfig{Pipeline: true}}}
+	ac, err = parseAdapterConfigWithPrecedence(c, cfg, "redis")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !ac.pipeline {
+		t.Error("pipeline should come from config adapter.redis.pipeline")
+	}
+}
+
+func TestSharedAdapter_ReusesOneInstance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.jsonl")
+	notifier := newSharedAdapter(&adapterChoice{adapterType: "file", url: path, timeout: 5 * time.Second, errorMaxLen: adapter.DefaultErrorMaxLen})
+
+	first, err := notifier.get()
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	var wg sync.WaitGroup
+	for i := range 5 {
+		wg.Go(func() {
+			result := &runtime.RunResult{
+				RunMeta: &types.RunMeta{RunID: fmt.Sprintf("run-%d", i), Attempt: 1},
+				Outcome: &types.RunOutcome{Status: types.OutcomeSuccess},
+			}
+			notifier.notify(result, storageChoice{backend: "fs", path: "/data"}, "quarry", "src", "cat", "2026-02-08", "", time.Second)
+		})
+	}
+	wg.Wait()
+	if again, _ := notifier.get(); again != first {
+		t.Error("get should return the same adapter instance")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read outbox: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 5 {
+		t.Errorf("outbox has %d events, want 5", lines)
+	}
+
+	if err := notifier.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := notifier.Close(); err != nil {
+		t.Fatalf("second close: %v", err)
+	}
+}
+
+// outboxProbeClient records how many events the adapter outbox held when
+// metrics were written. Only WriteMetrics is implemented.
+type outboxProbeClient struct {
+	lode.Client
+	outbox       string
+	eventsBefore int
+}
+
+func (p *outboxProbeClient) WriteMetrics(context.Context, metrics.Snapshot, time.Time) error {
+	data, _ := os.ReadFile(p.outbox)
+	p.eventsBefore = strings.Count(string(data), "\n")
+	return nil
+}
+
+func TestRunFinalizer_NotifyEarly(t *testing.T) {
+	for _, early := range []bool{false, true} {
+		t.Run(fmt.Sprintf("early=%v", early), func(t *testing.T) {
+			outbox := filepath.Join(t.TempDir(), "outbox.jsonl")
+			notifier := newSharedAdapter(&adapterChoice{
+				adapterType:  "file",
+				url:          outbox,
+				timeout:      5 * time.Second,
+				fileMaxBytes: fileadapter.DefaultMaxBytes,
+				notifyEarly:  early,
+			})
+			t.Cleanup(func() { _ = notifier.Close() })
+			probe := &outboxProbeClient{outbox: outbox}
+			f := &runFinalizer{
+				lodeClient: probe,
+				collector:  metrics.NewCollector("strict", "executor", "fs", "run-001", ""),
+				adapter:    notifier,
+				storage:    storageChoice{backend: "fs", path: "/data"},
+				source:     "src",
+				category:   "cat",
+				clock:      clock.Real,
+				startTime:  time.Now(),
+			}
+
+			f.finalizeAttempt(&runtime.RunResult{
+				RunMeta: &types.RunMeta{RunID: "run-001", Attempt: 1},
+				Outcome: &types.RunOutcome{Status: types.OutcomeSuccess},
+			})
+
+			if want := map[bool]int{false: 0, true: 1}[early]; probe.eventsBefore != want {
+				t.Errorf("events published before metrics = %d, want %d", probe.eventsBefore, want)
+			}
+			events := readOutbox(t, outbox)
+			if len(events) != 1 || events[0].MetricsPending != early {
+				t.Errorf("events = %+v, want one with metrics_pending=%v", events, early)
+			}
+		})
+	}
+}
+
+func TestChildFactory_NotifyEarly(t *testing.T) {
+	for _, early := range []bool{false, true} {
+		t.Run(fmt.Sprintf("early=%v", early), func(t *testing.T) {
+			outbox := filepath.Join(t.TempDir(), "outbox.jsonl")
+			notifier := newSharedAdapter(&adapterChoice{
+				adapterType:  "file",
+				url:          outbox,
+				timeout:      5 * time.Second,
+				fileMaxBytes: fileadapter.DefaultMaxBytes,
+				notifyEarly:  early,
+			})
+			t.Cleanup(func() { _ = notifier.Close() })
+			probe := &outboxProbeClient{outbox: outbox}
+			cf := &childFactory{
+				adapter: notifier,
+				storage: storageChoice{backend: "fs", path: "/data"},
+				clock:   clock.Real,
+			}
+			result := &runtime.RunResult{
+				RunMeta: &types.RunMeta{RunID: "run-child", Attempt: 1},
+				Outcome: &types.RunOutcome{Status: types.OutcomeSuccess},
+			}
+
+			cf.finishChild(t.Context(), "run-child", result, probe, metrics.NewCollector("strict", "executor", "fs", "run-child", ""), func() {
+				cf.adapter.notify(result, cf.storage, "", "src", "cat", "2026-01-01", "", time.Second)
+			})
+
+			if want := map[bool]int{false: 0, true: 1}[early]; probe.eventsBefore != want {
+				t.Errorf("events published before metrics = %d, want %d", probe.eventsBefore, want)
+			}
+			events := readOutbox(t, outbox)
+			if len(events) != 1 || events[0].MetricsPending != early {
+				t.Errorf("events = %+v, want one with metrics_pending=%v", events, early)
+			}
+		})
+	}
+}
+
+func TestSharedAdapter_NilIsNoop(t *testing.T) {
+	notifier := newSharedAdapter(nil)
+	if notifier != nil {
+		t.Fatal("no adapter choice should yield a nil notifier")
+	}
+	notifier.notify(nil, storageChoice{}, "", "", "", "", "", 0)
+	if err := notifier.Close(); err != nil {
+		t.Errorf("close: %v", err)
+	}
+}
+
 func TestParseAdapterConfig_ConfigProvidesURL(t *testing.T) {
 	// CLI has no --adapter-url set; config provides it
 	c := newAdapterTestContext(t, nil, nil)
@@ -1426,6 +2950,7 @@ func TestParseAdapterConfig_MalformedHeader(t *testing.T) {
 		&cli.StringSliceFlag{Name: "adapter-header"},
 		&cli.DurationFlag{Name: "adapter-timeout", Value: 10 * time.Second},
 		&cli.IntFlag{Name: "adapter-retries", Value: 3},
+		&cli.IntFlag{Name: "adapter-error-max-len", Value: adapter.DefaultErrorMaxLen},
 		&cli.StringFlag{Name: "adapter-channel"},
 	}
 
@@ -1783,6 +3308,43 @@ func TestChildRun_StorageDayAlignedWithBuildPolicy(t *testing.T) {
 	}
 }
 
+// TestStorageDayOverride_AlignedWithBuildPolicy extends the day-drift
+// invariant to --storage-day: the sink partition and the executor's
+// StorageDay both use the override instead of the start time.
+func TestStorageDayOverride_AlignedWithBuildPolicy(t *testing.T) {
+	startTime := time.Date(2026, 2, 23, 12, 0, 0, 0, time.UTC)
+	storageDir := t.TempDir()
+	storage := storageChoice{backend: "fs", path: storageDir, day: "2026-01-15"}
+	pol := policyChoice{name: "strict", flushMode: "at_least_once"}
+	collector := metrics.NewCollector("strict", "executor.mjs", "fs", "run-001", "")
+
+	p, _, fw, err := buildPolicy(pol, storage, "quarry", "src", "cat", "run-001", startTime, collector, nil)
+	if err != nil {
+		t.Fatalf("buildPolicy: %v", err)
+	}
+	defer iox.DiscardClose(p)
+	if err := fw.PutFile(t.Context(), "probe.txt", "text/plain", []byte("x")); err != nil {
+		t.Fatalf("PutFile: %v", err)
+	}
+
+	dayDirs, err := filepath.Glob(filepath.Join(storageDir, "datasets", "quarry", "partitions",
+		"source=src", "category=cat", "day=*"))
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	if len(dayDirs) != 1 || filepath.Base(dayDirs[0]) != "day=2026-01-15" {
+		t.Fatalf("partition dirs = %v, want day=2026-01-15", dayDirs)
+	}
+	if got := storage.partitionDay(startTime); got != "2026-01-15" {
+		t.Errorf("StorageDay = %q, want the override", got)
+	}
+
+	// Without the override the start time decides, as before
+	if got := (storageChoice{}).partitionDay(startTime); got != "2026-02-23" {
+		t.Errorf("derived day = %q, want 2026-02-23", got)
+	}
+}
+
 // --- Event sink config parsing tests ---
 
 func TestParseEventSinkConfig_NoConfigDefaultsNil(t *testing.T) {
@@ -1963,3 +3525,222 @@ func TestChildFactory_InheritsEventSinks(t *testing.T) {
 		t.Errorf("expected second sink best_effort, got %v", factory.eventSinks[1].delivery)
 	}
 }
+
+func TestSelectProxy_HealthCheckSkipsDeadEndpoint(t *testing.T) {
+	// Live endpoint: a local listener. Dead endpoint: a closed listener's port.
+	live, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer iox.DiscardClose(live)
+
+	dead, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	deadPort := dead.Addr().(*net.TCPAddr).Port
+	iox.DiscardClose(dead)
+
+	pools := []types.ProxyPool{{
+		Name:     "pool",
+		Strategy: types.ProxyStrategyRoundRobin,
+		Endpoints: []types.ProxyEndpoint{
+			{Protocol: types.ProxyProtocolHTTP, Host: "127.0.0.1", Port: deadPort},
+			{Protocol: types.ProxyProtocolHTTP, Host: "127.0.0.1", Port: live.Addr().(*net.TCPAddr).Port},
+		},
+	}}
+
+	config := proxyChoice{poolName: "pool", healthCheck: true, healthTimeout: time.Second}
+	ep, err := selectProxy(config, &types.RunMeta{RunID: "run-1", Attempt: 1}, pools)
+	if err != nil {
+		t.Fatalf("selectProxy failed: %v", err)
+	}
+	if ep.Port == deadPort {
+		t.Errorf("health check selected dead endpoint port %d", deadPort)
+	}
+
+	// Without health check, round-robin picks the first (dead) endpoint
+	config.healthCheck = false
+	ep, err = selectProxy(config, &types.RunMeta{RunID: "run-1", Attempt: 1}, pools)
+	if err != nil {
+		t.Fatalf("selectProxy failed: %v", err)
+	}
+	if ep.Port != deadPort {
+		t.Errorf("expected unprobed selection of first endpoint, got port %d", ep.Port)
+	}
+}
+
+func TestProxySelection_ReselectAvoidsFailedEndpoint(t *testing.T) {
+	pools := []types.ProxyPool{{
+		Name:     "pool",
+		Strategy: types.ProxyStrategyRoundRobin,
+		Endpoints: []types.ProxyEndpoint{
+			{Protocol: types.ProxyProtocolHTTP, Host: "a.example", Port: 8080},
+			{Protocol: types.ProxyProtocolHTTP, Host: "b.example", Port: 8080},
+			{Protocol: types.ProxyProtocolHTTP, Host: "c.example", Port: 8080},
+		},
+	}}
+	sel, err := newProxySelection(proxyChoice{poolName: "pool"}, &types.RunMeta{RunID: "run-1", Attempt: 1}, pools)
+	if err != nil {
+		t.Fatalf("newProxySelection failed: %v", err)
+	}
+	if ep, err := sel.selectEndpoint(); err != nil || ep.Host != "a.example" {
+		t.Fatalf("first selection = %v, %v; want a.example", ep, err)
+	}
+
+	// Round-robin would hand out b next; b is the endpoint that failed
+	failed := &types.ProxyEndpointRedacted{Protocol: types.ProxyProtocolHTTP, Host: "b.example", Port: 8080}
+	ep, err := sel.reselect(failed)
+	if err != nil {
+		t.Fatalf("reselect failed: %v", err)
+	}
+	if ep.Host != "c.example" {
+		t.Errorf("reselect = %s, want c.example (skipping the failed endpoint)", ep.Host)
+	}
+
+	// Without a failed endpoint, reselect is a plain selection
+	if ep, err := sel.reselect(nil); err != nil || ep.Host != "a.example" {
+		t.Errorf("reselect(nil) = %v, %v; want a.example", ep, err)
+	}
+}
+
+func TestProxySelection_RotateKeepsProxyServer(t *testing.T) {
+	user1, user2, pass := "session-1", "session-2", "secret"
+	pools := []types.ProxyPool{{
+		Name:     "pool",
+		Strategy: types.ProxyStrategyRoundRobin,
+		Endpoints: []types.ProxyEndpoint{
+			{Protocol: types.ProxyProtocolHTTP, Host: "gw.example", Port: 8080, Username: &user1, Password: &pass},
+			{Protocol: types.ProxyProtocolHTTP, Host: "other.example", Port: 8080},
+			{Protocol: types.ProxyProtocolHTTP, Host: "gw.example", Port: 8080, Username: &user2, Password: &pass},
+		},
+	}}
+	sel, err := newProxySelection(proxyChoice{poolName: "pool"}, &types.RunMeta{RunID: "run-1", Attempt: 1}, pools)
+	if err != nil {
+		t.Fatalf("newProxySelection failed: %v", err)
+	}
+	current, err := sel.selectEndpoint()
+	if err != nil {
+		t.Fatalf("first selection failed: %v", err)
+	}
+
+	// Round-robin would hand out other.example next; the browser cannot
+	// switch servers, so rotation skips it for gw.example's other user
+	ep, err := sel.Rotate(current)
+	if err != nil {
+		t.Fatalf("Rotate failed: %v", err)
+	}
+	if ep.Host != "gw.example" || ep.Username == nil || *ep.Username != user2 {
+		t.Errorf("Rotate = %+v, want gw.example as %s", ep, user2)
+	}
+
+	// No endpoint on other.example other than itself
+	if _, err := sel.Rotate(&pools[0].Endpoints[1]); err == nil {
+		t.Error("expected error rotating away from a single-user proxy server")
+	}
+}
+
+func TestValidateJobSchema(t *testing.T) {
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "schema.json")
+	schema := `{
+		"type": "object",
+		"required": ["url"],
+		"properties": {
+			"url": {"type": "string"},
+			"page": {"type": "integer", "minimum": 1}
+		},
+		"additionalProperties": false
+	}`
+	if err := os.WriteFile(schemaPath, []byte(schema), 0o644); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		job     map[string]any
+		wantErr string
+	}{
+		{"valid", map[string]any{"url": "https://example.com", "page": float64(2)}, ""},
+		{"missing required", map[string]any{"page": float64(2)}, "missing property 'url'"},
+		{"wrong type is path-qualified", map[string]any{"url": "x", "page": "two"}, "at /page:"},
+		{"typo'd key rejected", map[string]any{"url": "x", "pgae": float64(1)}, "pgae"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateJobSchema(tt.job, schemaPath)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error containing %q", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %q does not contain %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateJobSchema_SchemaErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := validateJobSchema(map[string]any{}, filepath.Join(dir, "missing.json")); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected not-found error, got %v", err)
+	}
+
+	badPath := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(badPath, []byte(`{"type": 42}`), 0o644); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+	if err := validateJobSchema(map[string]any{}, badPath); err == nil || !strings.Contains(err.Error(), "invalid schema") {
+		t.Errorf("expected invalid-schema error, got %v", err)
+	}
+}
+
+func TestInjectResumeState(t *testing.T) {
+	dir := t.TempDir()
+	storage := storageChoice{backend: "fs", path: dir}
+
+	// No prior runs: job runs fresh
+	job, err := injectResumeState(t.Context(), map[string]any{"url": "https://example.com"}, storage, "quarry", "shop", "default", "run-002", true)
+	if err != nil {
+		t.Fatalf("injectResumeState (fresh): %v", err)
+	}
+	if _, ok := job["resume_state"]; ok {
+		t.Errorf("fresh run should not carry resume_state, got %v", job)
+	}
+
+	client, err := lode.NewLodeClient(lode.Config{Dataset: "quarry", Source: "shop", Category: "default", Day: "2026-02-03", RunID: "run-001"}, dir)
+	if err != nil {
+		t.Fatalf("NewLodeClient: %v", err)
+	}
+	err = client.WriteEvents(t.Context(), "", "", []*types.EventEnvelope{
+		{EventID: "c1", RunID: "run-001", Seq: 1, Type: types.EventTypeCheckpoint, Payload: map[string]any{"checkpoint_id": "page-3", "cursor": "abc"}},
+	})
+	if err != nil {
+		t.Fatalf("WriteEvents: %v", err)
+	}
+
+	job, err = injectResumeState(t.Context(), nil, storage, "quarry", "shop", "default", "run-002", true)
+	if err != nil {
+		t.Fatalf("injectResumeState: %v", err)
+	}
+	state, _ := job["resume_state"].(map[string]any)
+	if state["checkpoint_id"] != "page-3" || state["cursor"] != "abc" {
+		t.Errorf("resume_state = %v, want prior checkpoint payload", job["resume_state"])
+	}
+
+	// An explicit resume_state in the job wins
+	job, err = injectResumeState(t.Context(), map[string]any{"resume_state": "manual"}, storage, "quarry", "shop", "default", "run-002", true)
+	if err != nil {
+		t.Fatalf("injectResumeState (explicit): %v", err)
+	}
+	if job["resume_state"] != "manual" {
+		t.Errorf("explicit resume_state overwritten: %v", job["resume_state"])
+	}
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+
+	quarryconfig "github.com/pithecene-io/quarry/cli/config"
+)
+
+// storageSinkSpec is one extra storage sink teed with the primary storage
+// (--storage-sink <backend>:<path>).
+type storageSinkSpec struct {
+	backend string // "fs" or "s3"
+	path    string // fs: directory, s3: bucket/prefix
+}
+
+// parseStorageSinkSpec parses a --storage-sink spec of the form
+// <backend>:<path>, e.g. fs:/var/cache/quarry or s3:bucket/prefix.
+func parseStorageSinkSpec(spec string) (storageSinkSpec, error) {
+	backend, path, ok := strings.Cut(spec, ":")
+	if !ok || path == "" {
+		return storageSinkSpec{}, fmt.Errorf("invalid --storage-sink %q: want <backend>:<path>, e.g. fs:/var/cache/quarry", spec)
+	}
+	if backend != "fs" && backend != "s3" {
+		return storageSinkSpec{}, fmt.Errorf("invalid --storage-sink %q: backend must be fs or s3", spec)
+	}
+	return storageSinkSpec{backend: backend, path: path}, nil
+}
+
+// storageSinkLabel names a storage sink in tee errors, logs, and metrics.
+func storageSinkLabel(backend, path string) string {
+	if path == "" {
+		return backend
+	}
+	return backend + ":" + path
+}
+
+// resolveStorageSinks resolves the extra tee sinks (CLI > config) and the
+// write quorum for primary, validating each sink like the primary storage.
+// S3 sinks share the primary's region, endpoint, and write options.
+func resolveStorageSinks(c *cli.Context, cfg *quarryconfig.Config, primary storageChoice) ([]storageSinkSpec, int, error) {
+	specs := c.StringSlice("storage-sink")
+	source := sourceFlag
+	if !c.IsSet("storage-sink") {
+		specs, source = nil, sourceDefault
+		if cfg != nil && len(cfg.Storage.Sinks) > 0 {
+			specs, source = cfg.Storage.Sinks, sourceConfig
+		}
+	}
+	explainFrom(c).record("storage-sink", specs, source)
+	quorum := resolveInt(c, "storage-sink-quorum", configIntVal(cfg, func(c *quarryconfig.Config) int { return c.Storage.SinkQuorum }))
+
+	if len(specs) == 0 {
+		if quorum != 0 {
+			fmt.Fprintf(os.Stderr, "Warning: --storage-sink-quorum is ignored without --storage-sink\n")
+		}
+		return nil, 0, nil
+	}
+
+	seen := map[string]bool{storageSinkLabel(primary.backend, primary.path): true}
+	sinks := make([]storageSinkSpec, 0, len(specs))
+	for _, raw := range specs {
+		spec, err := parseStorageSinkSpec(raw)
+		if err != nil {
+			return nil, 0, err
+		}
+		label := storageSinkLabel(spec.backend, spec.path)
+		if seen[label] {
+			return nil, 0, fmt.Errorf("duplicate --storage-sink %s (already the primary storage or another sink)", label)
+		}
+		seen[label] = true
+
+		// S3 options were validated with the primary; fs sinks ignore them
+		if err := validateStorageConfig(storageChoice{backend: spec.backend, path: spec.path}); err != nil {
+			return nil, 0, fmt.Errorf("--storage-sink %s: %w", label, err)
+		}
+		sinks = append(sinks, spec)
+	}
+
+	if total := len(sinks) + 1; quorum < 0 || quorum > total {
+		return nil, 0, fmt.Errorf("--storage-sink-quorum must be between 0 (all) and %d, got %d", total, quorum)
+	}
+	return sinks, quorum, nil
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	quarryconfig "github.com/pithecene-io/quarry/cli/config"
+	"github.com/pithecene-io/quarry/iox"
+	"github.com/pithecene-io/quarry/metrics"
+	"github.com/pithecene-io/quarry/types"
+)
+
+func TestParseStorageSinkSpec(t *testing.T) {
+	spec, err := parseStorageSinkSpec("s3:bucket/prefix:with-colon")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spec.backend != "s3" || spec.path != "bucket/prefix:with-colon" {
+		t.Errorf("spec = %+v, want s3 bucket/prefix:with-colon", spec)
+	}
+
+	for raw, want := range map[string]string{
+		"/var/cache":      "want <backend>:<path>",
+		"fs:":             "want <backend>:<path>",
+		"memory:anything": "backend must be fs or s3",
+	} {
+		if _, err := parseStorageSinkSpec(raw); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("parseStorageSinkSpec(%q) err = %v, want %q", raw, err, want)
+		}
+	}
+}
+
+// runResolveStorageSinks runs resolveStorageSinks under a minimal app with args.
+func runResolveStorageSinks(t *testing.T, cfg *quarryconfig.Config, primary storageChoice, args ...string) ([]storageSinkSpec, int, error) {
+	t.Helper()
+	var sinks []storageSinkSpec
+	var quorum int
+	var resolveErr error
+	app := &cli.App{
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{Name: "storage-sink"},
+			&cli.IntFlag{Name: "storage-sink-quorum"},
+		},
+		Action: func(c *cli.Context) error {
+			sinks, quorum, resolveErr = resolveStorageSinks(c, cfg, primary)
+			return nil
+		},
+	}
+	if err := app.Run(append([]string{"quarry"}, args...)); err != nil {
+		t.Fatalf("app.Run: %v", err)
+	}
+	return sinks, quorum, resolveErr
+}
+
+func TestResolveStorageSinks(t *testing.T) {
+	cache := t.TempDir()
+	primary := storageChoice{backend: "s3", path: "bucket/quarry"}
+
+	sinks, quorum, err := runResolveStorageSinks(t, nil, primary, "--storage-sink", "fs:"+cache, "--storage-sink-quorum", "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sinks) != 1 || sinks[0].backend != "fs" || sinks[0].path != cache || quorum != 1 {
+		t.Errorf("sinks = %+v quorum = %d, want fs:%s quorum 1", sinks, quorum, cache)
+	}
+
+	// Config is used when the flag is absent
+	cfg := &quarryconfig.Config{Storage: quarryconfig.StorageConfig{Sinks: []string{"fs:" + cache}, SinkQuorum: 2}}
+	sinks, quorum, err = runResolveStorageSinks(t, cfg, primary)
+	if err != nil || len(sinks) != 1 || quorum != 2 {
+		t.Errorf("config: sinks = %+v quorum = %d err = %v", sinks, quorum, err)
+	}
+}
+
+func TestResolveStorageSinks_Invalid(t *testing.T) {
+	cache := t.TempDir()
+	primary := storageChoice{backend: "fs", path: cache}
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"same as primary", []string{"--storage-sink", "fs:" + cache}, "duplicate --storage-sink"},
+		{"missing directory", []string{"--storage-sink", "fs:" + filepath.Join(cache, "missing")}, "storage path does not exist"},
+		{"quorum too large", []string{"--storage-sink", "s3:bucket", "--storage-sink-quorum", "3"}, "between 0 (all) and 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := runResolveStorageSinks(t, nil, primary, tt.args...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildStorageSink_Tee(t *testing.T) {
+	primaryDir, cacheDir := t.TempDir(), t.TempDir()
+	storage := storageChoice{
+		backend: "fs",
+		path:    primaryDir,
+		sinks:   []storageSinkSpec{{backend: "fs", path: cacheDir}},
+	}
+	collector := metrics.NewCollector("strict", "executor.mjs", "fs", "run-001", "")
+
+	sink, _, _, err := buildStorageSink(storage, "quarry", "src", "cat", "run-001", "strict", time.Now(), collector)
+	if err != nil {
+		t.Fatalf("buildStorageSink: %v", err)
+	}
+	defer iox.DiscardClose(sink)
+	events := []*types.EventEnvelope{{ContractVersion: "0.1.0", EventID: "e1", RunID: "run-001", Seq: 1, Type: types.EventTypeItem, Ts: "2026-02-23T12:00:00Z", Attempt: 1}}
+	if err := sink.WriteEvents(t.Context(), events); err != nil {
+		t.Fatalf("WriteEvents: %v", err)
+	}
+
+	for _, dir := range []string{primaryDir, cacheDir} {
+		runDirs, _ := filepath.Glob(filepath.Join(dir, "datasets", "quarry", "partitions", "source=src", "category=cat", "day=*", "run_id=run-001"))
+		if len(runDirs) != 1 {
+			t.Errorf("%s: run partitions = %v, want one", dir, runDirs)
+		}
+	}
+	snap := collector.Snapshot()
+	if snap.StorageSinkWrites["fs:"+primaryDir] != 1 || snap.StorageSinkWrites["fs:"+cacheDir] != 1 {
+		t.Errorf("StorageSinkWrites = %v, want one write per sink", snap.StorageSinkWrites)
+	}
+	if snap.LodeWriteSuccess != 1 {
+		t.Errorf("LodeWriteSuccess = %d, want 1 (primary only)", snap.LodeWriteSuccess)
+	}
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	lodelibrary "github.com/pithecene-io/lode/lode"
+	"github.com/urfave/cli/v2"
+
+	"github.com/pithecene-io/quarry/lode"
+)
+
+// VerifyCommand returns the verify command.
+// Re-reads every object listed in a run's partition _manifest.json
+// (written by quarry run --partition-manifest) and checks size and sha256.
+func VerifyCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "verify",
+		Usage:     "Verify a run partition against its _manifest.json (size and sha256 of every object)",
+		UsageText: "quarry verify --run-id <id> --storage-backend <fs|s3> --storage-path <path> [--storage-dataset <id>]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{Name: "run-id", Usage: "Run ID whose partition to verify", Required: true},
+			&cli.StringFlag{Name: "storage-dataset", Usage: "Lode dataset ID (default: \"quarry\")", Value: lode.DefaultDataset},
+			&cli.StringFlag{Name: "storage-backend", Usage: "Storage backend: fs or s3", Required: true},
+			&cli.StringFlag{Name: "storage-path", Usage: "Storage path (fs: directory, s3: bucket/prefix)", Required: true},
+			&cli.StringFlag{Name: "storage-region", Usage: "AWS region for S3 backend"},
+		},
+		Action: verifyAction,
+	}
+}
+
+func verifyAction(c *cli.Context) error {
+	store, err := buildReadStore(c.String("storage-backend"), c.String("storage-path"), c.String("storage-region"))
+	if err != nil {
+		return cli.Exit(fmt.Sprintf("failed to initialize storage reader: %v", err), exitConfigError)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	ok, err := verifyRun(ctx, c.App.Writer, store, c.String("storage-dataset"), c.String("run-id"))
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+	if !ok {
+		return cli.Exit("verification failed", 1)
+	}
+	return nil
+}
+
+// verifyRun verifies the run's partition and prints one line per object.
+// Returns false when any object does not match its manifest entry.
+func verifyRun(ctx context.Context, w io.Writer, store lodelibrary.Store, dataset, runID string) (bool, error) {
+	path, err := lode.FindPartitionManifest(ctx, store, dataset, runID)
+	if err != nil {
+		if errors.Is(err, lode.ErrPartitionManifestNotFound) {
+			return false, fmt.Errorf("%w (was the run started with --partition-manifest?)", err)
+		}
+		return false, err
+	}
+	result, err := lode.VerifyPartition(ctx, store, path)
+	if err != nil {
+		return false, err
+	}
+
+	m := result.Manifest
+	_, _ = fmt.Fprintf(w, "manifest: %s\n", result.Path)
+	_, _ = fmt.Fprintf(w, "run_id: %s  outcome: %s  completed_at: %s\n", m.RunID, m.Outcome, m.CompletedAt)
+	failed := 0
+	for _, o := range result.Objects {
+		if o.Problem == "" {
+			_, _ = fmt.Fprintf(w, "ok    %s\n", o.Name)
+			continue
+		}
+		failed++
+		_, _ = fmt.Fprintf(w, "FAIL  %s: %s\n", o.Name, o.Problem)
+	}
+	_, _ = fmt.Fprintf(w, "%d objects, %d failed\n", len(result.Objects), failed)
+	return result.OK(), nil
+}
+
+// buildReadStore creates a raw Lode Store for reading based on CLI flags.
+func buildReadStore(backend, path, region string) (lodelibrary.Store, error) {
+	switch backend {
+	case "fs":
+		return lodelibrary.NewFSFactory(path)()
+	case "s3":
+		bucket, prefix := lode.ParseS3Path(path)
+		return lode.NewReadStoreS3(lode.S3Config{Bucket: bucket, Prefix: prefix, Region: region})
+	default:
+		return nil, fmt.Errorf("unsupported storage-backend: %s (must be fs or s3)", backend)
+	}
+}
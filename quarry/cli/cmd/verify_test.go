@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pithecene-io/quarry/lode"
+	"github.com/pithecene-io/quarry/metrics"
+	"github.com/pithecene-io/quarry/types"
+)
+
+// writeVerifiedRun writes a small fs partition with --partition-manifest
+// semantics and returns the storage root.
+func writeVerifiedRun(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	client, err := lode.NewLodeClient(lode.Config{
+		Dataset:           "quarry",
+		Source:            "shop",
+		Category:          "products",
+		Day:               "2026-03-01",
+		RunID:             "run-verify",
+		PartitionManifest: true,
+	}, root)
+	if err != nil {
+		t.Fatalf("NewLodeClient: %v", err)
+	}
+	ctx := t.Context()
+	if err := client.PutFile(ctx, "page.html", "text/html", []byte("<html></html>")); err != nil {
+		t.Fatalf("PutFile: %v", err)
+	}
+	if err := client.WriteMetrics(ctx, metrics.NewCollector("strict", "node", "fs", "run-verify", "").Snapshot(), time.Now()); err != nil {
+		t.Fatalf("WriteMetrics: %v", err)
+	}
+	outcome := &types.RunOutcome{Status: types.OutcomeSuccess}
+	if err := writePartitionManifest(ctx, client, outcome, time.Now()); err != nil {
+		t.Fatalf("writePartitionManifest: %v", err)
+	}
+	return root
+}
+
+func TestVerifyRun_OK(t *testing.T) {
+	root := writeVerifiedRun(t)
+	store, err := buildReadStore("fs", root, "")
+	if err != nil {
+		t.Fatalf("buildReadStore: %v", err)
+	}
+
+	var out bytes.Buffer
+	ok, err := verifyRun(t.Context(), &out, store, "quarry", "run-verify")
+	if err != nil {
+		t.Fatalf("verifyRun: %v", err)
+	}
+	if !ok {
+		t.Fatalf("verifyRun = false, want true; output:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "0 failed") {
+		t.Errorf("output missing summary:\n%s", out.String())
+	}
+}
+
+func TestVerifyRun_TamperedFile(t *testing.T) {
+	root := writeVerifiedRun(t)
+	matches, _ := filepath.Glob(filepath.Join(root, "datasets/quarry/partitions/*/*/*/run_id=run-verify/files/page.html"))
+	if len(matches) != 1 {
+		t.Fatalf("sidecar file not found, got %v", matches)
+	}
+	if err := os.WriteFile(matches[0], []byte("<html>changed</html>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store, err := buildReadStore("fs", root, "")
+	if err != nil {
+		t.Fatalf("buildReadStore: %v", err)
+	}
+
+	var out bytes.Buffer
+	ok, err := verifyRun(t.Context(), &out, store, "quarry", "run-verify")
+	if err != nil {
+		t.Fatalf("verifyRun: %v", err)
+	}
+	if ok {
+		t.Fatal("verifyRun = true, want false for a modified object")
+	}
+	if !strings.Contains(out.String(), "FAIL") || !strings.Contains(out.String(), "page.html") {
+		t.Errorf("output does not flag page.html:\n%s", out.String())
+	}
+}
+
+func TestVerifyRun_NoManifest(t *testing.T) {
+	store, err := buildReadStore("fs", t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("buildReadStore: %v", err)
+	}
+	_, err = verifyRun(t.Context(), &bytes.Buffer{}, store, "quarry", "run-missing")
+	if err == nil || !strings.Contains(err.Error(), "--partition-manifest") {
+		t.Errorf("err = %v, want not-found hint about --partition-manifest", err)
+	}
+}
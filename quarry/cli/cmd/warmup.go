@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+
+	"github.com/pithecene-io/quarry/metrics"
+	"github.com/pithecene-io/quarry/policy"
+	"github.com/pithecene-io/quarry/runtime"
+	"github.com/pithecene-io/quarry/types"
+)
+
+// sharedStateKey is the job payload key that carries the --warmup-script
+// checkpoint into the root and child runs of a fan-out.
+const sharedStateKey = "shared_state"
+
+// runWarmup executes the --warmup-script once against the shared browser
+// and returns its final checkpoint payload. The warmup run is not persisted:
+// its events go to a no-op policy, since the checkpoint typically carries
+// session material. Any outcome other than success, or a success without a
+// checkpoint, is an error.
+func runWarmup(ctx context.Context, rootConfig *runtime.RunConfig, script string) (map[string]any, error) {
+	runID := rootConfig.RunMeta.RunID + "-warmup"
+	collector := metrics.NewCollector("noop", filepath.Base(rootConfig.ExecutorPath), "", runID, "")
+
+	config := &runtime.RunConfig{
+		ExecutorPath:      rootConfig.ExecutorPath,
+		ScriptPath:        script,
+		Job:               rootConfig.Job,
+		RunMeta:           &types.RunMeta{RunID: runID, Attempt: 1, Labels: rootConfig.RunMeta.Labels},
+		Policy:            policy.NewNoopPolicy(),
+		Proxy:             rootConfig.Proxy,
+		BrowserWSEndpoint: rootConfig.BrowserWSEndpoint,
+		ResolveFrom:       rootConfig.ResolveFrom,
+		ExecutorArgs:      rootConfig.ExecutorArgs,
+		Source:            rootConfig.Source,
+		Category:          rootConfig.Category,
+		Collector:         collector,
+		StallTimeout:      rootConfig.StallTimeout,
+		MaxEventBytes:     rootConfig.MaxEventBytes,
+		Redactor:          rootConfig.Redactor,
+	}
+
+	orchestrator, err := runtime.NewRunOrchestrator(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create warmup orchestrator: %w", err)
+	}
+	result, err := orchestrator.Execute(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("warmup execution failed: %w", err)
+	}
+
+	if result.Outcome.Status != types.OutcomeSuccess {
+		return nil, fmt.Errorf("warmup run ended with %s: %s", result.Outcome.Status, result.Outcome.Message)
+	}
+	if result.FinalCheckpoint == nil {
+		return nil, errors.New("warmup run completed without emitting a checkpoint")
+	}
+	return result.FinalCheckpoint, nil
+}
+
+// withSharedState returns a copy of job with job[shared_state] set to state.
+// An explicit shared_state in the job payload wins. The input map is not
+// modified, since enqueue params may be shared with dedup bookkeeping.
+func withSharedState(job map[string]any, state map[string]any) map[string]any {
+	if state == nil {
+		return job
+	}
+	if _, ok := job[sharedStateKey]; ok {
+		return job
+	}
+	out := make(map[string]any, len(job)+1)
+	maps.Copy(out, job)
+	out[sharedStateKey] = state
+	return out
+}
+
+// warmupFanOut runs the warmup script and injects its checkpoint into the
+// root job; the factory injects it into each child. Failures abort the
+// fan-out before any run starts.
+func warmupFanOut(ctx context.Context, rootConfig *runtime.RunConfig, factory *childFactory, script string, quiet bool) error {
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "Running warmup script %s\n", script)
+	}
+	state, err := runWarmup(ctx, rootConfig, script)
+	if err != nil {
+		return fmt.Errorf("--warmup-script failed, aborting fan-out: %w", err)
+	}
+	job, _ := rootConfig.Job.(map[string]any) // parseJobPayload yields a map or nil
+	if _, ok := job[sharedStateKey]; ok {
+		fmt.Fprintf(os.Stderr, "Warning: job payload already sets %s; warmup state not injected into the root run\n", sharedStateKey)
+	}
+	rootConfig.Job = withSharedState(job, state)
+	factory.sharedState = state
+	return nil
+}
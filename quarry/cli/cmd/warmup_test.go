@@ -0,0 +1,44 @@
+package cmd
+
+import "testing"
+
+func TestWithSharedState_InjectsCopy(t *testing.T) {
+	params := map[string]any{"url": "https://example.com/a"}
+	state := map[string]any{"checkpoint_id": "login", "cookies": "sid=1"}
+
+	got := withSharedState(params, state)
+
+	shared, ok := got[sharedStateKey].(map[string]any)
+	if !ok || shared["cookies"] != "sid=1" {
+		t.Fatalf("shared_state = %v, want warmup checkpoint", got[sharedStateKey])
+	}
+	if got["url"] != "https://example.com/a" {
+		t.Errorf("url = %v, want original param preserved", got["url"])
+	}
+	if _, ok := params[sharedStateKey]; ok {
+		t.Error("input params must not be modified")
+	}
+}
+
+func TestWithSharedState_NilJob(t *testing.T) {
+	got := withSharedState(nil, map[string]any{"checkpoint_id": "login"})
+	if _, ok := got[sharedStateKey]; !ok {
+		t.Errorf("expected shared_state on nil job, got %v", got)
+	}
+}
+
+func TestWithSharedState_ExplicitWins(t *testing.T) {
+	params := map[string]any{sharedStateKey: "explicit"}
+	got := withSharedState(params, map[string]any{"checkpoint_id": "login"})
+	if got[sharedStateKey] != "explicit" {
+		t.Errorf("shared_state = %v, want explicit value kept", got[sharedStateKey])
+	}
+}
+
+func TestWithSharedState_NoWarmup(t *testing.T) {
+	params := map[string]any{"url": "https://example.com/a"}
+	got := withSharedState(params, nil)
+	if _, ok := got[sharedStateKey]; ok {
+		t.Error("expected no shared_state without a warmup checkpoint")
+	}
+}
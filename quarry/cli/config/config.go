@@ -12,38 +12,129 @@ import (
 // All values are optional and act as defaults for quarry run flags.
 // CLI flags always override config values.
 type Config struct {
-	Source            string                     `yaml:"source"`
-	Category          string                     `yaml:"category"`
-	Executor          string                     `yaml:"executor"`
-	BrowserWSEndpoint string                     `yaml:"browser_ws_endpoint"`
-	NoBrowserReuse    bool                       `yaml:"no_browser_reuse"`
-	ResolveFrom       string                     `yaml:"resolve_from"`
-	Storage           StorageConfig              `yaml:"storage"`
-	Policy            PolicyConfig               `yaml:"policy"`
-	Proxies           map[string]ProxyPoolConfig `yaml:"proxies"`
-	Proxy             ProxySelection             `yaml:"proxy"`
-	Adapter           AdapterConfig              `yaml:"adapter"`
-	Events            EventSinksConfig           `yaml:"events"`
+	// Version is the config schema version (default 1; see CurrentVersion).
+	Version                int                        `yaml:"version"`
+	Source                 string                     `yaml:"source"`
+	Category               string                     `yaml:"category"`
+	Executor               string                     `yaml:"executor"`
+	BrowserWSEndpoint      string                     `yaml:"browser_ws_endpoint"`
+	NoBrowserReuse         bool                       `yaml:"no_browser_reuse"`
+	BrowserLaunchTimeout   Duration                   `yaml:"browser_launch_timeout"`
+	ResolveFrom            StringList                 `yaml:"resolve_from"`
+	ResolveWorkspace       string                     `yaml:"resolve_workspace"`
+	JobSchema              string                     `yaml:"job_schema"`
+	SinceCheckpoint        bool                       `yaml:"since_checkpoint"`
+	StallTimeout           Duration                   `yaml:"stall_timeout"`
+	MaxEventBytes          int64                      `yaml:"max_event_bytes"`
+	MaxRunBytes            int64                      `yaml:"max_run_bytes"`
+	LogMinLevel            string                     `yaml:"log_min_level"`
+	SampleRate             *float64                   `yaml:"sample_rate,omitempty"` // nil: unset; an explicit 0 is rejected
+	EventsFilter           string                     `yaml:"events_filter"`
+	TelemetryMode          bool                       `yaml:"telemetry_mode"`
+	ShutdownGrace          Duration                   `yaml:"shutdown_grace"`
+	Admission              AdmissionConfig            `yaml:"admission"`
+	VerifyArtifacts        bool                       `yaml:"verify_artifacts"`
+	SniffContentType       bool                       `yaml:"sniff_content_type"`
+	PersistStderr          bool                       `yaml:"persist_stderr"`
+	StderrTail             int                        `yaml:"stderr_tail"`
+	ArtifactSpillThreshold int64                      `yaml:"artifact_spill_threshold"`
+	MaxFrameBytes          int64                      `yaml:"max_frame_bytes"`
+	MaxDecodeDepth         int                        `yaml:"max_decode_depth"`
+	MaxDecodeContainerLen  int                        `yaml:"max_decode_container_len"`
+	MetricsAddr            string                     `yaml:"metrics_addr"`
+	EgressProxy            string                     `yaml:"egress_proxy"`
+	PreRunHook             string                     `yaml:"pre_run_hook"`
+	PreRunHookTimeout      Duration                   `yaml:"pre_run_hook_timeout"`
+	OnComplete             string                     `yaml:"on_complete"`
+	OnCompleteTimeout      Duration                   `yaml:"on_complete_timeout"`
+	OnCompleteRequired     bool                       `yaml:"on_complete_required"`
+	Redact                 []string                   `yaml:"redact"`
+	DomainPolicy           DomainPolicyConfig         `yaml:"domain_policy"`
+	Labels                 map[string]string          `yaml:"labels"`
+	DefaultLabels          map[string]string          `yaml:"default_labels"` // alias of labels; Load moves it there
+	Tenant                 string                     `yaml:"tenant"`
+	RequireTenant          bool                       `yaml:"require_tenant"`
+	TenantPattern          string                     `yaml:"tenant_pattern"`
+	Storage                StorageConfig              `yaml:"storage"`
+	Policy                 PolicyConfig               `yaml:"policy"`
+	Proxies                map[string]ProxyPoolConfig `yaml:"proxies"`
+	Proxy                  ProxySelection             `yaml:"proxy"`
+	ProxyRouting           map[string]string          `yaml:"proxy_routing"` // source glob or "re:" regex -> pool
+	Adapter                AdapterConfig              `yaml:"adapter"`
+	Events                 EventSinksConfig           `yaml:"events"`
+	ExitCodes              ExitCodesConfig            `yaml:"exit_codes"`
+
+	// Warnings are deprecation and version warnings found by Load.
+	Warnings []string `yaml:"-"`
+}
+
+// DomainPolicyConfig is the runtime-enforced domain allowlist/denylist.
+// Entries match the domain and its subdomains; deny wins over allow.
+type DomainPolicyConfig struct {
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+}
+
+// AdmissionConfig caps concurrent runs on one host: each run waits for
+// one of Slots slots shared through the Lock path. Zero Slots disables it.
+type AdmissionConfig struct {
+	Slots   int      `yaml:"slots"`
+	Lock    string   `yaml:"lock"`
+	Timeout Duration `yaml:"timeout"`
+}
+
+// ExitCodesConfig remaps run outcomes to process exit codes.
+// Nil fields keep the default mapping (0/1/2/3).
+type ExitCodesConfig struct {
+	Success       *int `yaml:"success,omitempty"`
+	ScriptError   *int `yaml:"script_error,omitempty"`
+	ExecutorCrash *int `yaml:"executor_crash,omitempty"`
+	PolicyFailure *int `yaml:"policy_failure,omitempty"`
 }
 
 // StorageConfig holds storage defaults from the config file.
 type StorageConfig struct {
-	Dataset     string `yaml:"dataset"`
-	Backend     string `yaml:"backend"`
-	Path        string `yaml:"path"`
-	Region      string `yaml:"region"`
-	Endpoint    string `yaml:"endpoint"`
-	S3PathStyle bool   `yaml:"s3_path_style"`
+	Dataset        string `yaml:"dataset"`
+	Backend        string `yaml:"backend"`
+	Path           string `yaml:"path"`
+	Region         string `yaml:"region"`
+	Endpoint       string `yaml:"endpoint"`
+	S3PathStyle    bool   `yaml:"s3_path_style"`
+	S3SSE          string `yaml:"s3_sse"`
+	S3KMSKeyID     string `yaml:"s3_kms_key"`
+	S3StorageClass string `yaml:"s3_storage_class"`
+	S3SkipExisting bool   `yaml:"s3_skip_existing"`
+	PrefixTemplate string `yaml:"prefix_template"`
+	// PartitionManifest writes files/_manifest.json at the end of each run.
+	PartitionManifest bool `yaml:"partition_manifest"`
+	// Format is the item event record encoding: jsonl (default) or parquet.
+	Format string `yaml:"format"`
+	// PartitionGranularity is day (default) or hour (adds hour=HH after day=).
+	PartitionGranularity string `yaml:"partition_granularity"`
+	// Sinks are extra storage sinks teed with the primary, as <backend>:<path>.
+	Sinks []string `yaml:"sinks"`
+	// SinkQuorum is the number of sinks each write needs (0: all).
+	SinkQuorum int `yaml:"sink_quorum"`
 }
 
 // PolicyConfig holds policy defaults from the config file.
 type PolicyConfig struct {
 	Name          string   `yaml:"name"`
 	FlushMode     string   `yaml:"flush_mode"`
+	ParallelFlush bool     `yaml:"parallel_flush"`
 	BufferEvents  int      `yaml:"buffer_events"`
 	BufferBytes   int64    `yaml:"buffer_bytes"`
 	FlushCount    int      `yaml:"flush_count"`
 	FlushInterval Duration `yaml:"flush_interval"`
+	FlushIdle     Duration `yaml:"flush_idle"`
+	FlushSeq      bool     `yaml:"flush_seq"`
+	FailOnDrops   bool     `yaml:"fail_on_drops"`
+	AllowSeqGaps  bool     `yaml:"allow_seq_gaps"`
+	// EventsBatchSize and StrictBatchWindow micro-batch strict policy writes;
+	// StrictDegradeBuffer holds events through retryable sink errors.
+	EventsBatchSize     int      `yaml:"events_batch_size"`
+	StrictBatchWindow   Duration `yaml:"strict_batch_window"`
+	StrictDegradeBuffer int      `yaml:"strict_degrade_buffer"`
 }
 
 // ProxyPoolConfig is a proxy pool definition within the config file.
@@ -57,8 +148,11 @@ type ProxyPoolConfig struct {
 
 // ProxySelection holds proxy selection defaults from the config file.
 type ProxySelection struct {
-	Pool     string `yaml:"pool"`
-	Strategy string `yaml:"strategy"`
+	Pool          string   `yaml:"pool"`
+	Strategy      string   `yaml:"strategy"`
+	HealthCheck   bool     `yaml:"health_check"`
+	HealthTimeout Duration `yaml:"health_timeout,omitempty"`
+	RotateOnBlock bool     `yaml:"rotate_on_block"`
 }
 
 // AdapterConfig holds adapter defaults from the config file.
@@ -69,6 +163,74 @@ type AdapterConfig struct {
 	Headers map[string]string `yaml:"headers,omitempty"`
 	Timeout Duration          `yaml:"timeout,omitempty"`
 	Retries *int              `yaml:"retries,omitempty"`
+	// ErrorMaxLen bounds error_message / error_stack in run_completed (bytes).
+	ErrorMaxLen int `yaml:"error_max_len,omitempty"`
+	// Kafka holds Kafka-specific settings (type=kafka only).
+	Kafka *KafkaAdapterConfig `yaml:"kafka,omitempty"`
+	// Webhook holds webhook-specific settings (type=webhook only).
+	Webhook WebhookAdapterConfig `yaml:"webhook,omitempty"`
+	// File holds file outbox settings (type=file only).
+	File FileAdapterConfig `yaml:"file,omitempty"`
+	// Redis holds redis pub/sub settings (type=redis only).
+	Redis RedisAdapterConfig `yaml:"redis,omitempty"`
+	// Template is a Go text/template rendering the published JSON body.
+	Template string `yaml:"template,omitempty"`
+	// Fields maps output keys to run_completed fields for a flat JSON body.
+	// Mutually exclusive with Template.
+	Fields map[string]string `yaml:"fields,omitempty"`
+	// OnArtifact also publishes an artifact_committed event per artifact.
+	OnArtifact bool `yaml:"on_artifact,omitempty"`
+	// ArtifactRate caps artifact_committed events per second (0 = unlimited).
+	ArtifactRate int `yaml:"artifact_rate,omitempty"`
+	// On limits run_completed publishes to these outcomes: always (default),
+	// success, failure, or outcome statuses.
+	On StringList `yaml:"on,omitempty"`
+	// NotifyEarly publishes run_completed before metrics persistence.
+	NotifyEarly bool `yaml:"notify_early,omitempty"`
+}
+
+// RedisAdapterConfig holds redis pub/sub adapter settings.
+type RedisAdapterConfig struct {
+	// Pipeline batches concurrent publishes (fan-out children) into one
+	// round trip.
+	Pipeline bool `yaml:"pipeline,omitempty"`
+}
+
+// FileAdapterConfig holds file outbox adapter settings.
+type FileAdapterConfig struct {
+	// MaxBytes is the outbox rotation size (-1 disables rotation).
+	MaxBytes int64 `yaml:"max_bytes,omitempty"`
+}
+
+// WebhookAdapterConfig holds webhook adapter mTLS and body encoding settings.
+type WebhookAdapterConfig struct {
+	// ClientCert and ClientKey are PEM paths of the mTLS client certificate pair.
+	ClientCert string `yaml:"client_cert,omitempty"`
+	ClientKey  string `yaml:"client_key,omitempty"`
+	// CAFile is a PEM CA bundle for the server certificate.
+	CAFile string `yaml:"ca_file,omitempty"`
+	// Compress is the body Content-Encoding: gzip or zstd (empty = none).
+	Compress string `yaml:"compress,omitempty"`
+	// RetryStatus lists the retried HTTP statuses, e.g. [409, 5xx]
+	// (empty = 429 and 5xx).
+	RetryStatus StringList `yaml:"retry_status,omitempty"`
+}
+
+// KafkaAdapterConfig holds Kafka adapter security settings.
+type KafkaAdapterConfig struct {
+	SASL *KafkaSASLConfig `yaml:"sasl,omitempty"`
+	// TLS enables TLS to the brokers (system roots unless TLSCAFile is set).
+	TLS bool `yaml:"tls,omitempty"`
+	// TLSCAFile is a PEM CA bundle for broker certificates. Implies TLS.
+	TLSCAFile string `yaml:"tls_ca_file,omitempty"`
+}
+
+// KafkaSASLConfig configures Kafka SASL authentication.
+type KafkaSASLConfig struct {
+	// Mechanism is one of plain, scram-sha-256, scram-sha-512.
+	Mechanism string `yaml:"mechanism"`
+	Username  string `yaml:"username"`
+	Password  string `yaml:"password"`
 }
 
 // EventSinksConfig holds the optional events.sinks configuration.
@@ -97,6 +259,29 @@ type EventSinkConfig struct {
 	Retries *int `yaml:"retries,omitempty"`
 }
 
+// StringList is a list of strings that also accepts a single scalar, so a
+// key that grew from one value to many keeps its original form.
+type StringList []string
+
+// UnmarshalYAML parses either a string or a sequence of strings.
+func (l *StringList) UnmarshalYAML(unmarshal func(any) error) error {
+	var list []string
+	if err := unmarshal(&list); err == nil {
+		*l = list
+		return nil
+	}
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	if s == "" {
+		*l = nil
+		return nil
+	}
+	*l = StringList{s}
+	return nil
+}
+
 // Duration wraps time.Duration for YAML string parsing (e.g. "10s", "5m").
 type Duration struct {
 	time.Duration
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pithecene-io/quarry/proxy"
 	"github.com/pithecene-io/quarry/types"
 )
 
@@ -367,6 +368,28 @@ func TestDuration_UnmarshalYAML(t *testing.T) {
 	}
 }
 
+func TestStringList_ScalarOrSequence(t *testing.T) {
+	cfg, err := Load(writeTemp(t, "resolve_from: /app/node_modules\n"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(cfg.ResolveFrom) != 1 || cfg.ResolveFrom[0] != "/app/node_modules" {
+		t.Errorf("scalar resolve_from = %v", cfg.ResolveFrom)
+	}
+
+	cfg, err = Load(writeTemp(t, "resolve_from:\n  - /app/node_modules\n  - /app/packages\n"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(cfg.ResolveFrom) != 2 || cfg.ResolveFrom[1] != "/app/packages" {
+		t.Errorf("sequence resolve_from = %v", cfg.ResolveFrom)
+	}
+
+	if _, err := Load(writeTemp(t, "resolve_from:\n  dir: /app\n")); err == nil {
+		t.Error("expected error for a mapping")
+	}
+}
+
 func TestLoad_RedisAdapterConfig(t *testing.T) {
 	yaml := `adapter:
   type: redis
@@ -391,6 +414,24 @@ func TestLoad_RedisAdapterConfig(t *testing.T) {
 	}
 }
 
+func TestLoad_WebhookRetryStatus(t *testing.T) {
+	yaml := `adapter:
+  type: webhook
+  url: https://hooks.example.com/quarry
+  webhook:
+    retry_status: [409, 5xx]
+`
+	path := writeTemp(t, yaml)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	got := cfg.Adapter.Webhook.RetryStatus
+	if len(got) != 2 || got[0] != "409" || got[1] != "5xx" {
+		t.Errorf("expected adapter.webhook.retry_status=[409 5xx], got %v", got)
+	}
+}
+
 func TestLoad_RedisAdapterChannelOmitted(t *testing.T) {
 	yaml := `adapter:
   type: redis
@@ -504,3 +545,68 @@ func assertEqual(t *testing.T, field, got, want string) {
 		t.Errorf("%s: got %q, want %q", field, got, want)
 	}
 }
+
+func TestLoad_ProxyCredentialsNotExpanded(t *testing.T) {
+	t.Setenv("TEST_PROXY_HOST", "proxy.example.com")
+
+	path := writeTemp(t, `proxies:
+  pool:
+    strategy: round_robin
+    endpoints:
+      - protocol: http
+        host: ${TEST_PROXY_HOST}
+        port: 8080
+        username: ${UNSET_PROXY_USER_12345}
+        password: "${UNSET_PROXY_PASS_12345}"
+      - {protocol: http, host: b.example.com, port: 8080, username: "${UNSET_PROXY_USER_12345}", password: x}
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	// Credentials keep the reference for proxy.ResolveSecrets, which fails
+	// on the unset variable; other fields expand as usual
+	eps := cfg.Proxies["pool"].Endpoints
+	assertEqual(t, "host", eps[0].Host, "proxy.example.com")
+	assertEqual(t, "username", *eps[0].Username, "${UNSET_PROXY_USER_12345}")
+	assertEqual(t, "password", *eps[0].Password, "${UNSET_PROXY_PASS_12345}")
+	assertEqual(t, "flow username", *eps[1].Username, "${UNSET_PROXY_USER_12345}")
+
+	pools := cfg.ProxyPools()
+	err = proxy.ResolveSecrets(&pools[0])
+	if err == nil || !strings.Contains(err.Error(), "UNSET_PROXY_USER_12345") {
+		t.Errorf("ResolveSecrets err = %v, want unset variable error", err)
+	}
+}
+
+func TestLoad_DefaultLabelsAlias(t *testing.T) {
+	path := writeTemp(t, `default_labels:
+  team: growth
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Labels["team"] != "growth" || len(cfg.Labels) != 1 {
+		t.Errorf("Labels = %v, want map[team:growth]", cfg.Labels)
+	}
+	if cfg.DefaultLabels != nil {
+		t.Errorf("DefaultLabels = %v, want nil after moving into Labels", cfg.DefaultLabels)
+	}
+}
+
+func TestLoad_DefaultLabelsAndLabelsRejected(t *testing.T) {
+	path := writeTemp(t, `labels:
+  team: growth
+default_labels:
+  campaign: spring
+`)
+	_, err := Load(path)
+	if err == nil {
+		t.Fatal("expected error when both labels and default_labels are set, got nil")
+	}
+	if !strings.Contains(err.Error(), "default_labels") {
+		t.Errorf("error should mention default_labels, got: %v", err)
+	}
+}
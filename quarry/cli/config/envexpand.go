@@ -4,6 +4,9 @@ package config
 import (
 	"os"
 	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 // envVarPattern matches ${VAR} and ${VAR:-default} patterns.
@@ -18,24 +21,102 @@ var envVarPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*)
 // This is intentional: required secrets will fail at downstream validation
 // (e.g., proxy endpoint auth pair validation).
 func ExpandEnv(input string) string {
-	return envVarPattern.ReplaceAllStringFunc(input, func(match string) string {
-		groups := envVarPattern.FindStringSubmatch(match)
-		if len(groups) < 2 {
-			return match
+	return expandEnvExcept(input, nil)
+}
+
+// span is a half-open byte range [start, end) of the input.
+type span struct{ start, end int }
+
+// expandEnvExcept is ExpandEnv, leaving patterns that start inside any of
+// skip verbatim.
+func expandEnvExcept(input string, skip []span) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range envVarPattern.FindAllStringSubmatchIndex(input, -1) {
+		if inSpans(m[0], skip) {
+			continue
+		}
+		b.WriteString(input[last:m[0]])
+		last = m[1]
+
+		varName := input[m[2]:m[3]]
+		if value, ok := os.LookupEnv(varName); ok && value != "" {
+			b.WriteString(value)
+			continue
+		}
+		// Use default if provided (m[4:6] is the default value)
+		if m[4] >= 0 {
+			b.WriteString(input[m[4]:m[5]])
+		}
+		// Unset without default: empty string
+	}
+	b.WriteString(input[last:])
+	return b.String()
+}
+
+func inSpans(offset int, spans []span) bool {
+	for _, s := range spans {
+		if offset >= s.start && offset < s.end {
+			return true
 		}
+	}
+	return false
+}
 
-		varName := groups[1]
-		value, ok := os.LookupEnv(varName)
-		if ok && value != "" {
-			return value
+// proxyCredentialSpans locates the username and password values of every
+// proxies.<pool>.endpoints[] entry in raw YAML. Load leaves their ${VAR}
+// references unexpanded for proxy.ResolveSecrets, which rejects an unset
+// variable instead of silently producing empty credentials. Returns nil if
+// the raw text does not parse; the expanded text's parse reports the error.
+func proxyCredentialSpans(data string) []span {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(data), &doc); err != nil || len(doc.Content) == 0 {
+		return nil
+	}
+	lineStarts := []int{0}
+	for i := range len(data) {
+		if data[i] == '\n' {
+			lineStarts = append(lineStarts, i+1)
 		}
+	}
 
-		// Use default if provided (groups[2] is the default value)
-		if len(groups) >= 3 && groups[2] != "" {
-			return groups[2]
+	var spans []span
+	pools := mappingValue(doc.Content[0], "proxies")
+	if pools == nil || pools.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 1; i < len(pools.Content); i += 2 {
+		endpoints := mappingValue(pools.Content[i], "endpoints")
+		if endpoints == nil || endpoints.Kind != yaml.SequenceNode {
+			continue
 		}
+		for _, ep := range endpoints.Content {
+			for _, field := range []string{"username", "password"} {
+				v := mappingValue(ep, field)
+				if v == nil || v.Kind != yaml.ScalarNode || v.Line > len(lineStarts) {
+					continue
+				}
+				start := lineStarts[v.Line-1] + v.Column - 1
+				end := start + len(v.Value)
+				if v.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+					end += 2
+				}
+				spans = append(spans, span{start, end})
+			}
+		}
+	}
+	return spans
+}
 
-		// Unset without default: empty string
-		return ""
-	})
+// mappingValue returns the value node for key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
 }
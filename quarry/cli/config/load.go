@@ -10,9 +10,12 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-// Load reads a YAML config file, expands environment variables, and
+// Load reads a YAML config file, expands environment variables (except in
+// proxy endpoint credentials, left for proxy.ResolveSecrets), and
 // unmarshals into a Config struct. Unknown keys are rejected to catch
-// typos early.
+// typos early. The version: field and deprecated keys are checked first,
+// so a removed key reports its replacement; warnings land in
+// Config.Warnings.
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -22,7 +25,16 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("cannot read config file %q: %w", path, err)
 	}
 
-	expanded := ExpandEnv(string(data))
+	expanded := expandEnvExcept(string(data), proxyCredentialSpans(string(data)))
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(expanded), &doc); err != nil {
+		return nil, fmt.Errorf("invalid YAML in %s: %w", path, err)
+	}
+	warnings, err := checkVersion(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
 
 	var cfg Config
 	dec := yaml.NewDecoder(bytes.NewReader([]byte(expanded)))
@@ -30,6 +42,13 @@ func Load(path string) (*Config, error) {
 	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
 		return nil, fmt.Errorf("invalid YAML in %s: %w", path, err)
 	}
+	if cfg.DefaultLabels != nil {
+		if cfg.Labels != nil {
+			return nil, fmt.Errorf("%s: labels and default_labels are aliases; set only one", path)
+		}
+		cfg.Labels, cfg.DefaultLabels = cfg.DefaultLabels, nil
+	}
+	cfg.Warnings = warnings
 
 	return &cfg, nil
 }
@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the newest config schema version this binary
+// understands. A config without version: is treated as version 1.
+const CurrentVersion = 1
+
+// Deprecation records a config key or CLI flag superseded by a replacement.
+// Config keys are checked by Load, flags by DeprecatedFlags.
+type Deprecation struct {
+	// Key is the dotted config key path, e.g. "storage.endpoint".
+	// Empty for flag-only entries.
+	Key string
+	// Flag is the CLI flag name without dashes. Empty for config-only entries.
+	Flag string
+	// Since is the schema version that deprecated the key or flag.
+	Since int
+	// RemovedIn is the schema version from which a config declaring it (or
+	// later) may no longer use the key; 0 means never removed.
+	RemovedIn int
+	// Replacement tells the user what to use instead.
+	Replacement string
+}
+
+// deprecations is the single registry of deprecated config keys and flags.
+var deprecations = []Deprecation{
+	{Flag: "proxy-config", Since: 1, Replacement: "define proxy pools in quarry.yaml under the proxies: key"},
+}
+
+// name renders the deprecated key or flag for messages.
+func (d Deprecation) name() string {
+	if d.Key != "" {
+		return "config key " + d.Key
+	}
+	return "--" + d.Flag
+}
+
+// warning renders the deprecation warning, without a "Warning:" prefix.
+func (d Deprecation) warning() string {
+	return fmt.Sprintf("%s is deprecated; %s instead", d.name(), d.Replacement)
+}
+
+// DeprecatedFlags returns a warning for every deprecated flag that isSet
+// reports as set.
+func DeprecatedFlags(isSet func(name string) bool) []string {
+	var warnings []string
+	for _, d := range deprecations {
+		if d.Flag != "" && isSet(d.Flag) {
+			warnings = append(warnings, d.warning())
+		}
+	}
+	return warnings
+}
+
+// checkVersion validates the declared schema version and deprecated keys of
+// a parsed config document. It returns warnings for deprecated keys and a
+// too-new version, and an error for an invalid version or a key removed in
+// the declared version.
+func checkVersion(doc *yaml.Node) ([]string, error) {
+	keys := make(map[string]bool)
+	collectKeys(doc, "", keys)
+
+	version := 1
+	var warnings []string
+	if node := lookupKey(doc, "version"); node != nil {
+		if node.ShortTag() != "!!int" || node.Decode(&version) != nil || version < 1 {
+			return nil, fmt.Errorf("invalid config version %q (must be an integer >= 1)", node.Value)
+		}
+		if version > CurrentVersion {
+			warnings = append(warnings, fmt.Sprintf(
+				"config version %d is newer than this quarry supports (up to %d); the binary may be too old for this config", version, CurrentVersion))
+		}
+	}
+
+	for _, d := range deprecations {
+		if d.Key == "" || !keys[d.Key] {
+			continue
+		}
+		if d.RemovedIn > 0 && version >= d.RemovedIn {
+			return nil, fmt.Errorf("%s was removed in config version %d; %s instead", d.name(), d.RemovedIn, d.Replacement)
+		}
+		warnings = append(warnings, d.warning())
+	}
+	return warnings, nil
+}
+
+// collectKeys records the dotted path of every mapping key under node.
+func collectKeys(node *yaml.Node, prefix string, keys map[string]bool) {
+	if node == nil {
+		return
+	}
+	if node.Kind == yaml.DocumentNode {
+		for _, child := range node.Content {
+			collectKeys(child, prefix, keys)
+		}
+		return
+	}
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		path := node.Content[i].Value
+		if prefix != "" {
+			path = prefix + "." + path
+		}
+		keys[path] = true
+		collectKeys(node.Content[i+1], path, keys)
+	}
+}
+
+// lookupKey returns the value node of a top-level key, or nil.
+func lookupKey(doc *yaml.Node, key string) *yaml.Node {
+	if doc == nil || len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == key {
+			return root.Content[i+1]
+		}
+	}
+	return nil
+}
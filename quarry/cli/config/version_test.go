@@ -0,0 +1,82 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+// withDeprecations replaces the deprecation registry for one test.
+func withDeprecations(t *testing.T, entries []Deprecation) {
+	t.Helper()
+	saved := deprecations
+	deprecations = entries
+	t.Cleanup(func() { deprecations = saved })
+}
+
+func TestLoad_VersionDefaultsWithoutWarnings(t *testing.T) {
+	cfg, err := Load(writeTemp(t, "source: my-source\n"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Version != 0 || len(cfg.Warnings) != 0 {
+		t.Errorf("Version = %d, Warnings = %v, want 0 and none", cfg.Version, cfg.Warnings)
+	}
+
+	cfg, err = Load(writeTemp(t, "version: 1\nsource: my-source\n"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Version != 1 || len(cfg.Warnings) != 0 {
+		t.Errorf("Version = %d, Warnings = %v, want 1 and none", cfg.Version, cfg.Warnings)
+	}
+}
+
+func TestLoad_FutureVersionWarns(t *testing.T) {
+	cfg, err := Load(writeTemp(t, "version: 99\n"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.Warnings) != 1 || !strings.Contains(cfg.Warnings[0], "binary may be too old") {
+		t.Errorf("Warnings = %v, want a too-old binary warning", cfg.Warnings)
+	}
+}
+
+func TestLoad_InvalidVersion(t *testing.T) {
+	for _, v := range []string{"0", "-1", "two", "1.5"} {
+		if _, err := Load(writeTemp(t, "version: "+v+"\n")); err == nil || !strings.Contains(err.Error(), "invalid config version") {
+			t.Errorf("version %s: err = %v, want invalid config version", v, err)
+		}
+	}
+}
+
+func TestLoad_DeprecatedKey(t *testing.T) {
+	withDeprecations(t, []Deprecation{
+		{Key: "storage.endpoint", Since: 1, RemovedIn: 2, Replacement: "use storage.s3_endpoint"},
+	})
+	yaml := "storage:\n  endpoint: https://example.com\n"
+
+	cfg, err := Load(writeTemp(t, yaml))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.Warnings) != 1 || !strings.Contains(cfg.Warnings[0], "config key storage.endpoint is deprecated; use storage.s3_endpoint") {
+		t.Errorf("Warnings = %v, want deprecation with replacement", cfg.Warnings)
+	}
+
+	// Declaring the version that removed the key makes it an error
+	_, err = Load(writeTemp(t, "version: 2\n"+yaml))
+	if err == nil || !strings.Contains(err.Error(), "removed in config version 2") {
+		t.Errorf("err = %v, want removed-key error", err)
+	}
+}
+
+func TestDeprecatedFlags(t *testing.T) {
+	set := map[string]bool{"proxy-config": true}
+	warnings := DeprecatedFlags(func(name string) bool { return set[name] })
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "--proxy-config is deprecated") {
+		t.Errorf("warnings = %v, want --proxy-config deprecation", warnings)
+	}
+	if got := DeprecatedFlags(func(string) bool { return false }); len(got) != 0 {
+		t.Errorf("warnings = %v, want none", got)
+	}
+}
@@ -22,17 +22,26 @@ func ParseMetricsRecord(record map[string]any) (*MetricsSnapshot, error) {
 		EventsReceived:  toInt64(record["events_received_total"]),
 		EventsPersisted: toInt64(record["events_persisted_total"]),
 		EventsDropped:   toInt64(record["events_dropped_total"]),
+		Degradations:    toInt64(record["policy_degradations_total"]),
 
 		// Executor
 		ExecutorLaunchSuccess: toInt64(record["executor_launch_success_total"]),
 		ExecutorLaunchFailure: toInt64(record["executor_launch_failure_total"]),
 		ExecutorCrash:         toInt64(record["executor_crash_total"]),
 		IPCDecodeErrors:       toInt64(record["ipc_decode_errors_total"]),
+		SeqGaps:               toInt64(record["seq_gaps_total"]),
+		ArtifactsDiscarded:    toInt64(record["artifacts_discarded_total"]),
+		EventsDiscarded:       toInt64(record["events_discarded_total"]),
+		EventsSampledOut:      toInt64(record["events_sampled_out_total"]),
+		EventsFiltered:        toInt64(record["events_filtered_total"]),
+		EnqueuesDeduplicated:  toInt64(record["enqueues_deduplicated_total"]),
+		ProxyRotations:        toInt64(record["proxy_rotations_total"]),
 
 		// Lode / Storage
-		LodeWriteSuccess: toInt64(record["lode_write_success_total"]),
-		LodeWriteFailure: toInt64(record["lode_write_failure_total"]),
-		LodeWriteRetry:   toInt64(record["lode_write_retry_total"]),
+		LodeWriteSuccess:  toInt64(record["lode_write_success_total"]),
+		LodeWriteFailure:  toInt64(record["lode_write_failure_total"]),
+		LodeWriteRetry:    toInt64(record["lode_write_retry_total"]),
+		LodeWritesSkipped: toInt64(record["lode_writes_skipped_total"]),
 
 		// Dimensions
 		Policy:         toString(record["policy"]),
@@ -47,6 +56,21 @@ func ParseMetricsRecord(record map[string]any) (*MetricsSnapshot, error) {
 		snap.DroppedByType = parseDroppedByType(dbt)
 	}
 
+	// Parse log_filtered_by_level if present (same map shape as dropped_by_type)
+	if lf, ok := record["log_filtered_by_level"]; ok && lf != nil {
+		snap.LogFilteredByLevel = parseDroppedByType(lf)
+	}
+
+	// Parse runs_by_reason if present (same map shape as dropped_by_type)
+	if rbr, ok := record["runs_by_reason"]; ok && rbr != nil {
+		snap.RunsByReason = parseDroppedByType(rbr)
+	}
+
+	// Parse user run labels if present
+	if l, ok := record["labels"]; ok && l != nil {
+		snap.Labels = parseLabels(l)
+	}
+
 	// Validate contract-required fields per CONTRACT_CLI.md.
 	// The write path always populates these; missing values indicate
 	// data corruption or a malformed record.
@@ -91,6 +115,23 @@ func toString(v any) string {
 	return ""
 }
 
+// parseLabels converts labels from Lode record format.
+// Handles both map[string]string (direct) and map[string]any (JSON round-trip).
+func parseLabels(v any) map[string]string {
+	switch m := v.(type) {
+	case map[string]string:
+		return m
+	case map[string]any:
+		result := make(map[string]string, len(m))
+		for k, val := range m {
+			result[k] = toString(val)
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
 // parseDroppedByType converts dropped_by_type from Lode record format.
 // Handles both map[string]int64 (direct) and map[string]any (JSON round-trip).
 func parseDroppedByType(v any) map[string]int64 {
@@ -30,6 +30,7 @@ func TestParseMetricsRecord(t *testing.T) {
 		"run_id":                        "run-abc",
 		"job_id":                        "job-def",
 		"dropped_by_type":               map[string]any{"log": float64(2)},
+		"labels":                        map[string]any{"team": "growth"},
 	}
 
 	parsed, err := ParseMetricsRecord(record)
@@ -40,6 +41,9 @@ func TestParseMetricsRecord(t *testing.T) {
 	if parsed.Ts != "2026-02-03T15:00:00Z" {
 		t.Errorf("Ts = %q, want %q", parsed.Ts, "2026-02-03T15:00:00Z")
 	}
+	if parsed.Labels["team"] != "growth" {
+		t.Errorf("Labels[team] = %q, want %q", parsed.Labels["team"], "growth")
+	}
 	if parsed.RunsStarted != 5 {
 		t.Errorf("RunsStarted = %d, want 5", parsed.RunsStarted)
 	}
@@ -148,34 +148,46 @@ type MetricsSnapshot struct {
 	Ts string `json:"ts"`
 
 	// Run lifecycle
-	RunsStarted   int64 `json:"runs_started_total"`
-	RunsCompleted int64 `json:"runs_completed_total"`
-	RunsFailed    int64 `json:"runs_failed_total"`
-	RunsCrashed   int64 `json:"runs_crashed_total"`
+	RunsStarted   int64            `json:"runs_started_total"`
+	RunsCompleted int64            `json:"runs_completed_total"`
+	RunsFailed    int64            `json:"runs_failed_total"`
+	RunsCrashed   int64            `json:"runs_crashed_total"`
+	RunsByReason  map[string]int64 `json:"runs_by_reason,omitempty"`
 
 	// Ingestion
 	EventsReceived  int64            `json:"events_received_total"`
 	EventsPersisted int64            `json:"events_persisted_total"`
 	EventsDropped   int64            `json:"events_dropped_total"`
 	DroppedByType   map[string]int64 `json:"dropped_by_type,omitempty"`
+	Degradations    int64            `json:"policy_degradations_total"`
 
 	// Executor
-	ExecutorLaunchSuccess int64 `json:"executor_launch_success_total"`
-	ExecutorLaunchFailure int64 `json:"executor_launch_failure_total"`
-	ExecutorCrash         int64 `json:"executor_crash_total"`
-	IPCDecodeErrors       int64 `json:"ipc_decode_errors_total"`
+	ExecutorLaunchSuccess int64            `json:"executor_launch_success_total"`
+	ExecutorLaunchFailure int64            `json:"executor_launch_failure_total"`
+	ExecutorCrash         int64            `json:"executor_crash_total"`
+	IPCDecodeErrors       int64            `json:"ipc_decode_errors_total"`
+	SeqGaps               int64            `json:"seq_gaps_total"`
+	ArtifactsDiscarded    int64            `json:"artifacts_discarded_total"`
+	EventsDiscarded       int64            `json:"events_discarded_total"`
+	EventsSampledOut      int64            `json:"events_sampled_out_total"`
+	EventsFiltered        int64            `json:"events_filtered_total"`
+	LogFilteredByLevel    map[string]int64 `json:"log_filtered_by_level,omitempty"`
+	EnqueuesDeduplicated  int64            `json:"enqueues_deduplicated_total"`
+	ProxyRotations        int64            `json:"proxy_rotations_total"`
 
 	// Lode / Storage
-	LodeWriteSuccess int64 `json:"lode_write_success_total"`
-	LodeWriteFailure int64 `json:"lode_write_failure_total"`
-	LodeWriteRetry   int64 `json:"lode_write_retry_total"`
+	LodeWriteSuccess  int64 `json:"lode_write_success_total"`
+	LodeWriteFailure  int64 `json:"lode_write_failure_total"`
+	LodeWriteRetry    int64 `json:"lode_write_retry_total"`
+	LodeWritesSkipped int64 `json:"lode_writes_skipped_total"`
 
 	// Dimensions per CONTRACT_METRICS.md
-	Policy         string `json:"policy"`
-	Executor       string `json:"executor"`
-	StorageBackend string `json:"storage_backend"`
-	RunID          string `json:"run_id"`
-	JobID          string `json:"job_id,omitempty"`
+	Policy         string            `json:"policy"`
+	Executor       string            `json:"executor"`
+	StorageBackend string            `json:"storage_backend"`
+	RunID          string            `json:"run_id"`
+	JobID          string            `json:"job_id,omitempty"`
+	Labels         map[string]string `json:"labels,omitempty"`
 }
 
 // ListRunsOptions for filtering list runs.
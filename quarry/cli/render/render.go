@@ -4,6 +4,7 @@
 //   - If output is a TTY, default to table
 //   - If output is not a TTY, default to json
 //   - --format flag always overrides defaults
+//   - --json is shorthand for --format json and conflicts with any other --format
 //   - Invalid formats are errors
 //
 // Color handling:
@@ -63,21 +64,11 @@ type Renderer struct {
 // NewRenderer creates a renderer from CLI context.
 // Applies format selection rules per CONTRACT_CLI.md.
 func NewRenderer(c *cli.Context) (*Renderer, error) {
-	formatStr := c.String("format")
-	format, err := ParseFormat(formatStr)
+	format, err := SelectFormat(c.String("format"), c.Bool("json"), isTTY(os.Stdout))
 	if err != nil {
 		return nil, err
 	}
 
-	// Apply default format based on TTY detection
-	if format == "" {
-		if isTTY(os.Stdout) {
-			format = FormatTable
-		} else {
-			format = FormatJSON
-		}
-	}
-
 	return &Renderer{
 		format:  format,
 		noColor: c.Bool("no-color"),
@@ -85,6 +76,31 @@ func NewRenderer(c *cli.Context) (*Renderer, error) {
 	}, nil
 }
 
+// SelectFormat applies the format selection rules to the --format and
+// --json flag values. tty reports whether stdout is a terminal.
+func SelectFormat(formatStr string, jsonFlag, tty bool) (Format, error) {
+	format, err := ParseFormat(formatStr)
+	if err != nil {
+		return "", err
+	}
+
+	if jsonFlag {
+		if format != "" && format != FormatJSON {
+			return "", fmt.Errorf("--json conflicts with --format %s", format)
+		}
+		return FormatJSON, nil
+	}
+
+	// Apply default format based on TTY detection
+	if format == "" {
+		if tty {
+			return FormatTable, nil
+		}
+		return FormatJSON, nil
+	}
+	return format, nil
+}
+
 // NewRendererWithWriter creates a renderer with a custom writer (for testing).
 func NewRendererWithWriter(format Format, noColor bool, out io.Writer) *Renderer {
 	return &Renderer{
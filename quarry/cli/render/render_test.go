@@ -46,6 +46,37 @@ func TestParseFormat_InvalidErrorMessage(t *testing.T) {
 	}
 }
 
+func TestSelectFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		json    bool
+		tty     bool
+		want    Format
+		wantErr bool
+	}{
+		{"tty default", "", false, true, FormatTable, false},
+		{"pipe default", "", false, false, FormatJSON, false},
+		{"explicit format wins", "yaml", false, true, FormatYAML, false},
+		{"json flag on tty", "", true, true, FormatJSON, false},
+		{"json flag with format json", "json", true, true, FormatJSON, false},
+		{"json flag conflicts with table", "table", true, false, "", true},
+		{"invalid format", "xml", true, false, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectFormat(tt.format, tt.json, tt.tty)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SelectFormat(%q, %v, %v) error = %v, wantErr %v", tt.format, tt.json, tt.tty, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SelectFormat(%q, %v, %v) = %v, want %v", tt.format, tt.json, tt.tty, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestRenderer_JSON(t *testing.T) {
 	var buf bytes.Buffer
 	r := NewRendererWithWriter(FormatJSON, false, &buf)
@@ -0,0 +1,88 @@
+// Package clock abstracts the wall clock and timers so time-dependent
+// behavior (partition day derivation, flush timers, run durations) can be
+// driven deterministically in tests.
+//
+// Production code uses Real. Tests inject a Fake.
+package clock
+
+import (
+	"context"
+	"time"
+)
+
+// Clock reads the current time and creates timers.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// NewTicker returns a Ticker that fires every d. Panics if d <= 0.
+	NewTicker(d time.Duration) Ticker
+	// NewTimer returns a Timer that fires once after d.
+	NewTimer(d time.Duration) Timer
+}
+
+// Ticker is the Clock counterpart of *time.Ticker.
+type Ticker interface {
+	// C returns the channel on which ticks are delivered.
+	C() <-chan time.Time
+	// Stop turns off the ticker. No more ticks are sent after Stop returns.
+	Stop()
+}
+
+// Timer is the Clock counterpart of *time.Timer.
+type Timer interface {
+	// C returns the channel on which the timer fires.
+	C() <-chan time.Time
+	// Stop prevents the timer from firing. Reports whether it was active.
+	Stop() bool
+	// Reset re-arms the timer to fire after d, discarding any pending
+	// value. Reports whether it was active.
+	Reset(d time.Duration) bool
+}
+
+// Real is the Clock backed by package time.
+var Real Clock = realClock{}
+
+// Or returns c, or Real when c is nil. Use it where a Clock is optional
+// config.
+func Or(c Clock) Clock {
+	if c == nil {
+		return Real
+	}
+	return c
+}
+
+type clockKey struct{}
+
+// NewContext returns a copy of ctx carrying c.
+func NewContext(ctx context.Context, c Clock) context.Context {
+	return context.WithValue(ctx, clockKey{}, c)
+}
+
+// FromContext returns the Clock carried by ctx, or Real if none.
+func FromContext(ctx context.Context) Clock {
+	if ctx != nil {
+		if c, ok := ctx.Value(clockKey{}).(Clock); ok && c != nil {
+			return c
+		}
+	}
+	return Real
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTicker(d time.Duration) Ticker { return realTicker{time.NewTicker(d)} }
+
+func (realClock) NewTimer(d time.Duration) Timer { return realTimer{time.NewTimer(d)} }
+
+type realTicker struct{ t *time.Ticker }
+
+func (r realTicker) C() <-chan time.Time { return r.t.C }
+func (r realTicker) Stop()               { r.t.Stop() }
+
+type realTimer struct{ t *time.Timer }
+
+func (r realTimer) C() <-chan time.Time        { return r.t.C }
+func (r realTimer) Stop() bool                 { return r.t.Stop() }
+func (r realTimer) Reset(d time.Duration) bool { return r.t.Reset(d) }
@@ -0,0 +1,110 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2026, 2, 23, 23, 59, 0, 0, time.UTC)
+
+// fired reports whether c holds a value, without blocking.
+func fired(c <-chan time.Time) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestFake_Advance(t *testing.T) {
+	f := NewFake(epoch)
+	f.Advance(90 * time.Second)
+	if got, want := f.Now(), epoch.Add(90*time.Second); !got.Equal(want) {
+		t.Errorf("Now = %v, want %v", got, want)
+	}
+}
+
+func TestFake_Ticker(t *testing.T) {
+	f := NewFake(epoch)
+	ticker := f.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	f.Advance(59 * time.Second)
+	if fired(ticker.C()) {
+		t.Fatal("ticker fired before its interval")
+	}
+	f.Advance(time.Second)
+	if !fired(ticker.C()) {
+		t.Fatal("ticker did not fire at its interval")
+	}
+
+	// Missed ticks are dropped, not queued
+	f.Advance(3 * time.Minute)
+	if !fired(ticker.C()) || fired(ticker.C()) {
+		t.Fatal("want exactly one pending tick after several intervals")
+	}
+
+	ticker.Stop()
+	f.Advance(time.Minute)
+	if fired(ticker.C()) {
+		t.Fatal("stopped ticker fired")
+	}
+}
+
+func TestFake_Timer(t *testing.T) {
+	f := NewFake(epoch)
+	timer := f.NewTimer(time.Second)
+	if !timer.Stop() {
+		t.Fatal("Stop on an armed timer = false")
+	}
+	f.Advance(time.Second)
+	if fired(timer.C()) {
+		t.Fatal("stopped timer fired")
+	}
+
+	if timer.Reset(time.Second) {
+		t.Fatal("Reset on a stopped timer = true")
+	}
+	f.Advance(time.Second)
+	if !fired(timer.C()) {
+		t.Fatal("reset timer did not fire")
+	}
+	f.Advance(time.Hour)
+	if fired(timer.C()) {
+		t.Fatal("timer fired twice")
+	}
+
+	// Reset discards a pending value
+	timer.Reset(time.Second)
+	f.Advance(time.Second)
+	timer.Reset(time.Second)
+	if fired(timer.C()) {
+		t.Fatal("Reset kept a stale value")
+	}
+}
+
+func TestFake_BlockUntil(t *testing.T) {
+	f := NewFake(epoch)
+	armed := make(chan Timer)
+	go func() { armed <- f.NewTimer(time.Second) }()
+
+	f.BlockUntil(1)
+	f.Advance(time.Second)
+	if timer := <-armed; !fired(timer.C()) {
+		t.Fatal("timer armed before BlockUntil returned did not fire")
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if got := FromContext(t.Context()); got != Real {
+		t.Errorf("FromContext(empty) = %v, want Real", got)
+	}
+	f := NewFake(epoch)
+	if got := FromContext(NewContext(t.Context(), f)); got != f {
+		t.Errorf("FromContext = %v, want the injected clock", got)
+	}
+	if Or(nil) != Real || Or(f) != f {
+		t.Error("Or must default only a nil clock to Real")
+	}
+}
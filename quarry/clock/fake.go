@@ -0,0 +1,170 @@
+package clock
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Fake is a manually advanced Clock for tests. Time only moves on Advance
+// or Set, and tickers and timers fire synchronously from those calls, so
+// tests need no sleeps. Like package time, a tick is dropped when the
+// previous one has not been received yet.
+//
+// Safe for concurrent use.
+type Fake struct {
+	mu      sync.Mutex
+	armed   *sync.Cond // broadcast when a ticker or timer is armed
+	now     time.Time
+	waiters []*fakeWaiter // armed tickers and timers
+}
+
+// NewFake returns a Fake clock reading now.
+func NewFake(now time.Time) *Fake {
+	f := &Fake{now: now}
+	f.armed = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now returns the fake current time.
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+// Advance moves the clock forward by d and fires every ticker and timer
+// that came due.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+	f.fireLocked()
+}
+
+// Set moves the clock to t and fires every ticker and timer that came
+// due. Moving backwards fires nothing.
+func (f *Fake) Set(t time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = t
+	f.fireLocked()
+}
+
+// BlockUntil blocks until at least n tickers and timers are armed. Use it
+// to wait for a goroutine to arm its timer before calling Advance.
+func (f *Fake) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for len(f.waiters) < n {
+		f.armed.Wait()
+	}
+}
+
+// NewTicker returns a Ticker driven by the fake clock. Panics if d <= 0.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive interval for NewTicker")
+	}
+	w := &fakeWaiter{f: f, c: make(chan time.Time, 1), period: d}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.armLocked(w, d)
+	return fakeTicker{w}
+}
+
+// NewTimer returns a Timer driven by the fake clock.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	w := &fakeWaiter{f: f, c: make(chan time.Time, 1)}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.armLocked(w, d)
+	f.fireLocked() // d <= 0 fires immediately
+	return fakeTimer{w}
+}
+
+// fakeWaiter is one ticker or timer. period is zero for timers.
+type fakeWaiter struct {
+	f        *Fake
+	c        chan time.Time
+	deadline time.Time
+	period   time.Duration
+}
+
+func (f *Fake) armLocked(w *fakeWaiter, d time.Duration) {
+	w.deadline = f.now.Add(d)
+	if !slices.Contains(f.waiters, w) {
+		f.waiters = append(f.waiters, w)
+	}
+	f.armed.Broadcast()
+}
+
+// disarmLocked removes w and reports whether it was armed.
+func (f *Fake) disarmLocked(w *fakeWaiter) bool {
+	i := slices.Index(f.waiters, w)
+	if i < 0 {
+		return false
+	}
+	f.waiters = slices.Delete(f.waiters, i, i+1)
+	return true
+}
+
+func (f *Fake) fireLocked() {
+	for _, w := range slices.Clone(f.waiters) {
+		if w.deadline.After(f.now) {
+			continue
+		}
+		select {
+		case w.c <- f.now:
+		default: // receiver is behind; drop like time.Ticker
+		}
+		if w.period == 0 {
+			f.disarmLocked(w)
+			continue
+		}
+		for !w.deadline.After(f.now) {
+			w.deadline = w.deadline.Add(w.period)
+		}
+	}
+}
+
+// drain discards a pending value, matching Stop/Reset on a Go 1.23+ timer.
+func (w *fakeWaiter) drain() {
+	select {
+	case <-w.c:
+	default:
+	}
+}
+
+type fakeTicker struct{ w *fakeWaiter }
+
+func (t fakeTicker) C() <-chan time.Time { return t.w.c }
+
+func (t fakeTicker) Stop() {
+	t.w.f.mu.Lock()
+	defer t.w.f.mu.Unlock()
+	t.w.f.disarmLocked(t.w)
+}
+
+type fakeTimer struct{ w *fakeWaiter }
+
+func (t fakeTimer) C() <-chan time.Time { return t.w.c }
+
+func (t fakeTimer) Stop() bool {
+	f := t.w.f
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t.w.drain()
+	return f.disarmLocked(t.w)
+}
+
+func (t fakeTimer) Reset(d time.Duration) bool {
+	f := t.w.f
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t.w.drain()
+	wasArmed := f.disarmLocked(t.w)
+	f.armLocked(t.w, d)
+	f.fireLocked()
+	return wasArmed
+}
@@ -40,6 +40,11 @@ func main() {
 			cmd.StatsCommand(),
 			cmd.ListCommand(),
 			cmd.DebugCommand(),
+			cmd.BrowserCommand(),
+			cmd.GenRunIDCommand(),
+			cmd.VerifyCommand(),
+			cmd.ReplayIntoCommand(),
+			cmd.ListSourcesCommand(),
 			cmd.VersionCommand("", commit),
 		},
 	}
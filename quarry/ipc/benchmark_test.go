@@ -2,9 +2,12 @@ package ipc
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"strings"
 	"testing"
 	"testing/iotest"
+	"time"
 
 	"github.com/vmihailenco/msgpack/v5"
 
@@ -324,3 +327,117 @@ func BenchmarkReadFrame_MixedStream(b *testing.B) {
 		}
 	}
 }
+
+// --- Frame compression benchmarks ---
+
+// pipeBandwidth models the executor stdout pipe as the bottleneck the
+// compression flag targets (bytes per second).
+const pipeBandwidth = 64 << 20
+
+// pacedReader limits reads from r to bytesPerSec, simulating a saturated pipe.
+type pacedReader struct {
+	r           io.Reader
+	bytesPerSec float64
+	start       time.Time
+	read        int64
+}
+
+func (p *pacedReader) Read(b []byte) (int, error) {
+	if p.start.IsZero() {
+		p.start = time.Now()
+	}
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	due := time.Duration(float64(p.read) / p.bytesPerSec * float64(time.Second))
+	if wait := due - time.Since(p.start); wait > 0 {
+		time.Sleep(wait)
+	}
+	return n, err
+}
+
+// buildVerboseStream encodes n verbose item events (long repetitive text, as
+// from scraped HTML) and returns the stream and the uncompressed payload
+// bytes. With compress, every frame is zstd-compressed.
+func buildVerboseStream(tb testing.TB, n int, compress bool) ([]byte, int) {
+	tb.Helper()
+	var buf bytes.Buffer
+	logical := 0
+	for i := range n {
+		env := &types.EventEnvelope{
+			ContractVersion: types.Version,
+			EventID:         fmt.Sprintf("evt-%04d", i),
+			RunID:           "run-001",
+			Seq:             int64(i + 1),
+			Type:            types.EventTypeItem,
+			Ts:              "2024-01-15T10:00:00Z",
+			Attempt:         1,
+			Payload: map[string]any{
+				"item_type":   "product",
+				"title":       fmt.Sprintf("Widget %d", i),
+				"description": strings.Repeat("A sturdy widget for everyday use. ", 64),
+				"html":        strings.Repeat(`<div class="product-card"><span class="price">$19.99</span></div>`, 32),
+			},
+		}
+		payload, err := msgpack.Marshal(env)
+		if err != nil {
+			tb.Fatalf("marshal: %v", err)
+		}
+		logical += len(payload)
+		if !compress {
+			buf.Write(EncodeFrame(payload))
+			continue
+		}
+		frame, err := EncodeCompressedFrame(payload)
+		if err != nil {
+			tb.Fatalf("EncodeCompressedFrame: %v", err)
+		}
+		buf.Write(frame)
+	}
+	return buf.Bytes(), logical
+}
+
+// TestVerboseStream_CompressionRatio checks that zstd frames cut the pipe
+// bytes for verbose payloads, which bounds the throughput gain when the
+// pipe is the bottleneck.
+func TestVerboseStream_CompressionRatio(t *testing.T) {
+	raw, logical := buildVerboseStream(t, 50, false)
+	compressed, _ := buildVerboseStream(t, 50, true)
+
+	ratio := float64(len(raw)) / float64(len(compressed))
+	t.Logf("payload %d bytes: uncompressed wire %d bytes, zstd wire %d bytes (%.1fx)", logical, len(raw), len(compressed), ratio)
+	if ratio < 4 {
+		t.Errorf("zstd wire reduction = %.1fx, want >= 4x for verbose payloads", ratio)
+	}
+}
+
+// BenchmarkReadFrame_VerboseStream measures payload throughput (MB/s of
+// uncompressed payload) for verbose items read through a pipe paced at
+// pipeBandwidth, with and without zstd frames.
+func BenchmarkReadFrame_VerboseStream(b *testing.B) {
+	for _, compress := range []bool{false, true} {
+		name := "uncompressed"
+		if compress {
+			name = "zstd"
+		}
+		b.Run(name, func(b *testing.B) {
+			data, logical := buildVerboseStream(b, 50, compress)
+			b.SetBytes(int64(logical))
+
+			b.ResetTimer()
+			b.ReportAllocs()
+			for range b.N {
+				decoder := NewFrameDecoder(&pacedReader{r: bytes.NewReader(data), bytesPerSec: pipeBandwidth})
+				for {
+					_, err := decoder.ReadFrame()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+			b.ReportMetric(float64(len(data)), "wire-B/op")
+		})
+	}
+}
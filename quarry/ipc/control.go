@@ -0,0 +1,202 @@
+package ipc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+// DrainType is the type discriminant for drain frames.
+// Sent runtime→executor via stdin to request a graceful finish.
+const DrainType = "drain"
+
+// PauseType is the type discriminant for pause frames.
+// Sent runtime→executor via stdin to hold emits until resume.
+const PauseType = "pause"
+
+// ResumeType is the type discriminant for resume frames.
+// Sent runtime→executor via stdin to lift a pause.
+const ResumeType = "resume"
+
+// ErrControlClosed is returned when sending on a closed control channel.
+var ErrControlClosed = errors.New("control channel closed")
+
+// ErrUnknownControlFrame is returned by DecodeControlFrame for a payload
+// whose type is not a runtime→executor frame type. Executors ignore such
+// frames (CONTRACT_IPC.md, Control Channel).
+var ErrUnknownControlFrame = errors.New("unknown control frame type")
+
+// ControlSender sends runtime→executor control frames over the executor's
+// stdin (CONTRACT_IPC.md, Control Channel). Every frame is advisory: an
+// executor that does not understand a frame type ignores it.
+// Implementations must be safe for concurrent use.
+type ControlSender interface {
+	// FileWriteAck settles the file_write frame with the given write ID.
+	FileWriteAck(writeID uint32, ok bool, errMsg string) error
+	// ProxyUpdate hands the executor a new proxy endpoint for the rest of
+	// the run. reason may be empty.
+	ProxyUpdate(endpoint types.ProxyEndpoint, reason string) error
+	// Drain asks the script to finish in-flight work and emit its terminal
+	// event. reason may be empty.
+	Drain(reason string) error
+	// Pause asks the script to hold emits until Resume. reason may be empty.
+	Pause(reason string) error
+	// Resume lifts a previous Pause.
+	Resume() error
+}
+
+var _ ControlSender = (*ControlWriter)(nil)
+
+// ControlWriter is the ControlSender over the executor's stdin pipe.
+// Each frame is encoded up front and written with a single Write under a
+// lock, so frames from concurrent senders never interleave.
+type ControlWriter struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closed bool
+}
+
+// NewControlWriter creates a ControlWriter over w (the executor's stdin,
+// after the JSON run input has been written).
+func NewControlWriter(w io.Writer) *ControlWriter {
+	return &ControlWriter{w: w}
+}
+
+// FileWriteAck implements ControlSender.
+func (c *ControlWriter) FileWriteAck(writeID uint32, ok bool, errMsg string) error {
+	ack := &types.FileWriteAckFrame{Type: FileWriteAckType, WriteID: writeID, OK: ok}
+	if errMsg != "" {
+		ack.Error = &errMsg
+	}
+	return c.Send(ack)
+}
+
+// ProxyUpdate implements ControlSender.
+func (c *ControlWriter) ProxyUpdate(endpoint types.ProxyEndpoint, reason string) error {
+	return c.Send(&types.ProxyUpdateFrame{Type: ProxyUpdateType, Endpoint: endpoint, Reason: optionalString(reason)})
+}
+
+// Drain implements ControlSender.
+func (c *ControlWriter) Drain(reason string) error {
+	return c.Send(&types.DrainFrame{Type: DrainType, Reason: optionalString(reason)})
+}
+
+// Pause implements ControlSender.
+func (c *ControlWriter) Pause(reason string) error {
+	return c.Send(&types.PauseFrame{Type: PauseType, Reason: optionalString(reason)})
+}
+
+// Resume implements ControlSender.
+func (c *ControlWriter) Resume() error {
+	return c.Send(&types.ResumeFrame{Type: ResumeType})
+}
+
+// Send encodes frame with EncodeControlFrame and writes it whole.
+// Returns ErrControlClosed after Close. A write error (typically EPIPE
+// once the executor has exited) is returned as is; the channel stays
+// usable.
+func (c *ControlWriter) Send(frame any) error {
+	buf, err := EncodeControlFrame(frame)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrControlClosed
+	}
+	_, err = c.w.Write(buf)
+	return err
+}
+
+// Close marks the channel closed and closes the underlying writer if it is
+// an io.Closer, signaling EOF to the executor. Later sends fail with
+// ErrControlClosed. Idempotent.
+func (c *ControlWriter) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	if closer, ok := c.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// EncodeControlFrame encodes a runtime→executor frame (one of the
+// *types.FileWriteAckFrame, *types.ProxyUpdateFrame, *types.DrainFrame,
+// *types.PauseFrame, or *types.ResumeFrame types) as a length-prefixed
+// msgpack frame.
+func EncodeControlFrame(frame any) ([]byte, error) {
+	switch frame.(type) {
+	case *types.FileWriteAckFrame, *types.ProxyUpdateFrame, *types.DrainFrame, *types.PauseFrame, *types.ResumeFrame:
+	default:
+		return nil, fmt.Errorf("%w: %T", ErrUnknownControlFrame, frame)
+	}
+	payload, err := msgpack.Marshal(frame)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode control frame: %w", err)
+	}
+	if len(payload) > MaxPayloadSize {
+		return nil, fmt.Errorf("control frame payload %d bytes exceeds maximum %d", len(payload), MaxPayloadSize)
+	}
+	return EncodeFrame(payload), nil
+}
+
+// DecodeControlFrame decodes a runtime→executor payload and returns a typed
+// frame, the downstream counterpart of DecodeFrameWithLimits. A payload
+// whose type is not a control frame type fails with a FrameErrorDecode
+// error wrapping ErrUnknownControlFrame.
+func DecodeControlFrame(payload []byte) (any, error) {
+	frameType, err := probeFrameType(payload)
+	if err != nil {
+		return nil, &FrameError{
+			Kind: FrameErrorDecode,
+			Msg:  "failed to decode control frame type",
+			Err:  err,
+		}
+	}
+
+	var frame any
+	switch frameType {
+	case FileWriteAckType:
+		return decodeFileWriteAck(payload)
+	case ProxyUpdateType:
+		return decodeProxyUpdate(payload)
+	case DrainType:
+		frame = &types.DrainFrame{}
+	case PauseType:
+		frame = &types.PauseFrame{}
+	case ResumeType:
+		frame = &types.ResumeFrame{}
+	default:
+		return nil, &FrameError{
+			Kind: FrameErrorDecode,
+			Msg:  fmt.Sprintf("control frame type %q", frameType),
+			Err:  ErrUnknownControlFrame,
+		}
+	}
+	if err := msgpack.Unmarshal(payload, frame); err != nil {
+		return nil, &FrameError{
+			Kind: FrameErrorDecode,
+			Msg:  "failed to decode " + frameType + " frame",
+			Err:  err,
+		}
+	}
+	return frame, nil
+}
+
+// optionalString returns nil for an empty s, for omitempty string fields.
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,163 @@
+package ipc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+// readControlFrames decodes every control frame in buf.
+func readControlFrames(t *testing.T, buf *bytes.Buffer) []any {
+	t.Helper()
+	decoder := NewFrameDecoder(buf)
+	var frames []any
+	for {
+		payload, err := decoder.ReadFrame()
+		if errors.Is(err, io.EOF) {
+			return frames
+		}
+		if err != nil {
+			t.Fatalf("ReadFrame failed: %v", err)
+		}
+		frame, err := DecodeControlFrame(payload)
+		if err != nil {
+			t.Fatalf("DecodeControlFrame failed: %v", err)
+		}
+		frames = append(frames, frame)
+	}
+}
+
+func TestControlWriter_Roundtrip(t *testing.T) {
+	var buf bytes.Buffer
+	control := NewControlWriter(&buf)
+
+	endpoint := types.ProxyEndpoint{Protocol: types.ProxyProtocolHTTP, Host: "proxy.example.com", Port: 8080}
+	sends := []func() error{
+		func() error { return control.FileWriteAck(7, false, "disk full") },
+		func() error { return control.ProxyUpdate(endpoint, "captcha") },
+		func() error { return control.Drain("shutdown") },
+		func() error { return control.Pause("") },
+		func() error { return control.Resume() },
+	}
+	for i, send := range sends {
+		if err := send(); err != nil {
+			t.Fatalf("send %d failed: %v", i, err)
+		}
+	}
+
+	frames := readControlFrames(t, &buf)
+	if len(frames) != len(sends) {
+		t.Fatalf("decoded %d frames, want %d", len(frames), len(sends))
+	}
+	if ack, ok := frames[0].(*types.FileWriteAckFrame); !ok || ack.WriteID != 7 || ack.OK || ack.Error == nil || *ack.Error != "disk full" {
+		t.Errorf("frame 0 = %#v, want error ack for write 7", frames[0])
+	}
+	if update, ok := frames[1].(*types.ProxyUpdateFrame); !ok || update.Endpoint.Host != "proxy.example.com" || update.Reason == nil || *update.Reason != "captcha" {
+		t.Errorf("frame 1 = %#v, want proxy_update", frames[1])
+	}
+	if drain, ok := frames[2].(*types.DrainFrame); !ok || drain.Type != DrainType || drain.Reason == nil || *drain.Reason != "shutdown" {
+		t.Errorf("frame 2 = %#v, want drain with reason", frames[2])
+	}
+	if pause, ok := frames[3].(*types.PauseFrame); !ok || pause.Type != PauseType || pause.Reason != nil {
+		t.Errorf("frame 3 = %#v, want pause without reason", frames[3])
+	}
+	if resume, ok := frames[4].(*types.ResumeFrame); !ok || resume.Type != ResumeType {
+		t.Errorf("frame 4 = %#v, want resume", frames[4])
+	}
+}
+
+// lockedBuffer records writes; each Write is one frame from ControlWriter.
+type lockedBuffer struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	closed bool
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestControlWriter_ConcurrentSends(t *testing.T) {
+	w := &lockedBuffer{}
+	control := NewControlWriter(w)
+
+	const senders, perSender = 8, 50
+	var wg sync.WaitGroup
+	for i := range senders {
+		wg.Go(func() {
+			for j := range perSender {
+				if err := control.FileWriteAck(uint32(i*perSender+j+1), true, ""); err != nil {
+					t.Errorf("FileWriteAck failed: %v", err)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	frames := readControlFrames(t, &w.buf)
+	if len(frames) != senders*perSender {
+		t.Fatalf("decoded %d frames, want %d", len(frames), senders*perSender)
+	}
+	seen := make(map[uint32]bool)
+	for _, f := range frames {
+		seen[f.(*types.FileWriteAckFrame).WriteID] = true
+	}
+	if len(seen) != senders*perSender {
+		t.Errorf("decoded %d distinct write IDs, want %d", len(seen), senders*perSender)
+	}
+}
+
+func TestControlWriter_Close(t *testing.T) {
+	w := &lockedBuffer{}
+	control := NewControlWriter(w)
+
+	if err := control.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !w.closed {
+		t.Error("Close must close the underlying writer")
+	}
+	if err := control.Close(); err != nil {
+		t.Errorf("second Close = %v, want nil", err)
+	}
+	if err := control.Drain(""); !errors.Is(err, ErrControlClosed) {
+		t.Errorf("Drain after Close = %v, want ErrControlClosed", err)
+	}
+	if w.buf.Len() != 0 {
+		t.Errorf("wrote %d bytes after Close", w.buf.Len())
+	}
+}
+
+func TestEncodeControlFrame_RejectsUpstreamFrames(t *testing.T) {
+	_, err := EncodeControlFrame(&types.FileWriteFrame{Type: FileWriteType})
+	if !errors.Is(err, ErrUnknownControlFrame) {
+		t.Errorf("EncodeControlFrame(file_write) = %v, want ErrUnknownControlFrame", err)
+	}
+}
+
+func TestDecodeControlFrame_UnknownType(t *testing.T) {
+	payload, err := msgpack.Marshal(map[string]any{"type": "item"})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	_, err = DecodeControlFrame(payload)
+	if !errors.Is(err, ErrUnknownControlFrame) {
+		t.Errorf("DecodeControlFrame(item) = %v, want ErrUnknownControlFrame", err)
+	}
+	if IsFatalFrameError(err) {
+		t.Error("unknown control frames must not be fatal")
+	}
+}
@@ -142,7 +142,7 @@ func TestE2E_FileWriteAck_Roundtrip(t *testing.T) {
 					WriteID: f.WriteID,
 					OK:      true,
 				}
-				ackFrame, encErr := EncodeFileWriteAck(ack)
+				ackFrame, encErr := EncodeControlFrame(ack)
 				if encErr != nil {
 					mu.Unlock()
 					readerDone <- encErr
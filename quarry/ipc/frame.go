@@ -8,7 +8,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sync"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/vmihailenco/msgpack/v5"
 
 	"github.com/pithecene-io/quarry/types"
@@ -24,8 +26,19 @@ const (
 	MaxChunkSize = 8 * 1024 * 1024
 	// LengthPrefixSize is the size of the length prefix in bytes.
 	LengthPrefixSize = 4
+	// MaxFrameSizeCeiling is the hard ceiling (256 MiB) for a frame size
+	// override (FrameDecoder.SetMaxFrameSize).
+	MaxFrameSizeCeiling = 256 * 1024 * 1024
+	// FrameFlagZstd is set in the high bit of the length prefix when the
+	// payload is zstd-compressed; the low 31 bits are the compressed length.
+	// Executors set it only after the runtime advertises CompressionZstd.
+	FrameFlagZstd = 1 << 31
 )
 
+// CompressionZstd is the frame compression codec the runtime advertises to
+// the executor in the run input (CONTRACT_IPC.md, Frame Compression).
+const CompressionZstd = "zstd"
+
 // ArtifactChunkType is the type discriminant for artifact chunk frames.
 const ArtifactChunkType = "artifact_chunk"
 
@@ -40,6 +53,10 @@ const FileWriteType = "file_write"
 // Sent runtime→executor via stdin after processing a file_write frame.
 const FileWriteAckType = "file_write_ack"
 
+// ProxyUpdateType is the type discriminant for proxy update frames.
+// Sent runtime→executor via stdin in response to a rotate_proxy event.
+const ProxyUpdateType = "proxy_update"
+
 // FrameErrorKind classifies frame decoding errors.
 type FrameErrorKind int
 
@@ -87,7 +104,9 @@ func IsFatalFrameError(err error) bool {
 
 // FrameDecoder decodes length-prefixed msgpack frames from a stream.
 type FrameDecoder struct {
-	reader io.Reader
+	reader     io.Reader
+	maxPayload uint32
+	zstd       *zstd.Decoder // created on the first compressed frame
 }
 
 // NewFrameDecoder creates a new frame decoder.
@@ -98,16 +117,47 @@ func NewFrameDecoder(r io.Reader) *FrameDecoder {
 	if !ok {
 		br = bufio.NewReader(r)
 	}
-	return &FrameDecoder{reader: br}
+	return &FrameDecoder{reader: br, maxPayload: MaxPayloadSize}
+}
+
+// ValidateMaxFrameSize checks a frame size override: 0 (the MaxFrameSize
+// default) or more than LengthPrefixSize up to MaxFrameSizeCeiling.
+func ValidateMaxFrameSize(frameBytes int64) error {
+	if frameBytes == 0 {
+		return nil
+	}
+	if frameBytes <= LengthPrefixSize || frameBytes > MaxFrameSizeCeiling {
+		return fmt.Errorf("max frame size must be between %d and %d bytes (0 = default %d), got %d",
+			LengthPrefixSize+1, MaxFrameSizeCeiling, MaxFrameSize, frameBytes)
+	}
+	return nil
+}
+
+// SetMaxFrameSize overrides the maximum frame size, including the length
+// prefix, for this decoder. 0 restores MaxFrameSize. Each frame is read
+// into a single allocation of up to this size. Must be called before the
+// first ReadFrame.
+func (d *FrameDecoder) SetMaxFrameSize(frameBytes int64) error {
+	if err := ValidateMaxFrameSize(frameBytes); err != nil {
+		return err
+	}
+	if frameBytes == 0 {
+		frameBytes = MaxFrameSize
+	}
+	d.maxPayload = uint32(frameBytes - LengthPrefixSize)
+	return nil
 }
 
 // ReadFrame reads a single frame from the stream.
-// Returns the raw payload bytes (msgpack-encoded).
+// Returns the raw payload bytes (msgpack-encoded). Compressed frames
+// (FrameFlagZstd) are decompressed transparently; the decompressed payload
+// is subject to the same size limit as an uncompressed one.
 //
 // Errors:
 //   - io.EOF: stream ended cleanly (no more frames)
 //   - *FrameError with Kind=FrameErrorPartial: incomplete frame (fatal)
 //   - *FrameError with Kind=FrameErrorTooLarge: frame exceeds limit (fatal)
+//   - *FrameError with Kind=FrameErrorDecode: corrupt compressed payload
 func (d *FrameDecoder) ReadFrame() ([]byte, error) {
 	// Read 4-byte big-endian length prefix
 	var lengthBuf [LengthPrefixSize]byte
@@ -124,13 +174,15 @@ func (d *FrameDecoder) ReadFrame() ([]byte, error) {
 		}
 	}
 
-	payloadSize := binary.BigEndian.Uint32(lengthBuf[:])
+	prefix := binary.BigEndian.Uint32(lengthBuf[:])
+	compressed := prefix&FrameFlagZstd != 0
+	payloadSize := prefix &^ FrameFlagZstd
 
 	// Validate frame size per CONTRACT_IPC.md
-	if payloadSize > MaxPayloadSize {
+	if payloadSize > d.maxPayload {
 		return nil, &FrameError{
 			Kind: FrameErrorTooLarge,
-			Msg:  fmt.Sprintf("payload size %d exceeds maximum %d", payloadSize, MaxPayloadSize),
+			Msg:  fmt.Sprintf("payload size %d exceeds maximum %d", payloadSize, d.maxPayload),
 		}
 	}
 
@@ -145,9 +197,39 @@ func (d *FrameDecoder) ReadFrame() ([]byte, error) {
 		}
 	}
 
+	if compressed {
+		return d.decompress(payload)
+	}
 	return payload, nil
 }
 
+// decompress inflates a FrameFlagZstd payload, refusing output larger than
+// the decoder's payload limit.
+func (d *FrameDecoder) decompress(payload []byte) ([]byte, error) {
+	if d.zstd == nil {
+		dec, err := zstd.NewReader(nil,
+			zstd.WithDecoderConcurrency(1),
+			zstd.WithDecoderMaxMemory(uint64(d.maxPayload)),
+		)
+		if err != nil {
+			return nil, &FrameError{Kind: FrameErrorDecode, Msg: "failed to create zstd decoder", Err: err}
+		}
+		d.zstd = dec
+	}
+
+	out, err := d.zstd.DecodeAll(payload, nil)
+	if errors.Is(err, zstd.ErrDecoderSizeExceeded) || (err == nil && len(out) > int(d.maxPayload)) {
+		return nil, &FrameError{
+			Kind: FrameErrorTooLarge,
+			Msg:  fmt.Sprintf("decompressed payload exceeds maximum %d", d.maxPayload),
+		}
+	}
+	if err != nil {
+		return nil, &FrameError{Kind: FrameErrorDecode, Msg: "failed to decompress zstd payload", Err: err}
+	}
+	return out, nil
+}
+
 // probeFrameType extracts the "type" field from a msgpack map without
 // fully unmarshaling the payload. Falls back to full probe on error.
 func probeFrameType(payload []byte) (string, error) {
@@ -171,10 +253,20 @@ func probeFrameType(payload []byte) (string, error) {
 	return "", errors.New("missing type field")
 }
 
-// DecodeFrame decodes a payload and returns a typed frame.
-// Discriminates based on the type field: "artifact_chunk", "run_result",
-// "file_write", or event types.
+// DecodeFrame decodes a payload and returns a typed frame, under the
+// default DecodeLimits.
 func DecodeFrame(payload []byte) (any, error) {
+	return DecodeFrameWithLimits(payload, DecodeLimits{})
+}
+
+// DecodeFrameWithLimits decodes a payload and returns a typed frame.
+// Discriminates based on the type field: "artifact_chunk", "run_result",
+// "file_write", or event types. A payload beyond limits fails with a
+// FrameErrorDecode error wrapping ErrDecodeLimit before anything is built.
+func DecodeFrameWithLimits(payload []byte, limits DecodeLimits) (any, error) {
+	if err := checkDecodeLimits(payload, limits); err != nil {
+		return nil, err
+	}
 	frameType, err := probeFrameType(payload)
 	if err != nil {
 		return nil, &FrameError{
@@ -186,20 +278,30 @@ func DecodeFrame(payload []byte) (any, error) {
 
 	switch frameType {
 	case ArtifactChunkType:
-		return DecodeArtifactChunk(payload)
+		return decodeArtifactChunk(payload)
 	case RunResultType:
-		return DecodeRunResult(payload)
+		return decodeRunResult(payload)
 	case FileWriteType:
-		return DecodeFileWrite(payload)
+		return decodeFileWrite(payload)
 	case FileWriteAckType:
-		return DecodeFileWriteAck(payload)
+		return decodeFileWriteAck(payload)
+	case ProxyUpdateType:
+		return decodeProxyUpdate(payload)
 	default:
-		return DecodeEventEnvelope(payload)
+		return decodeEventEnvelope(payload)
 	}
 }
 
-// DecodeEventEnvelope decodes a payload as an EventEnvelope.
+// DecodeEventEnvelope decodes a payload as an EventEnvelope under the
+// default DecodeLimits.
 func DecodeEventEnvelope(payload []byte) (*types.EventEnvelope, error) {
+	if err := checkDecodeLimits(payload, DecodeLimits{}); err != nil {
+		return nil, err
+	}
+	return decodeEventEnvelope(payload)
+}
+
+func decodeEventEnvelope(payload []byte) (*types.EventEnvelope, error) {
 	var envelope types.EventEnvelope
 	if err := msgpack.Unmarshal(payload, &envelope); err != nil {
 		return nil, &FrameError{
@@ -211,8 +313,16 @@ func DecodeEventEnvelope(payload []byte) (*types.EventEnvelope, error) {
 	return &envelope, nil
 }
 
-// DecodeArtifactChunk decodes a payload as an ArtifactChunkFrame.
+// DecodeArtifactChunk decodes a payload as an ArtifactChunkFrame under the
+// default DecodeLimits.
 func DecodeArtifactChunk(payload []byte) (*types.ArtifactChunkFrame, error) {
+	if err := checkDecodeLimits(payload, DecodeLimits{}); err != nil {
+		return nil, err
+	}
+	return decodeArtifactChunk(payload)
+}
+
+func decodeArtifactChunk(payload []byte) (*types.ArtifactChunkFrame, error) {
 	var chunk types.ArtifactChunkFrame
 	if err := msgpack.Unmarshal(payload, &chunk); err != nil {
 		return nil, &FrameError{
@@ -224,8 +334,16 @@ func DecodeArtifactChunk(payload []byte) (*types.ArtifactChunkFrame, error) {
 	return &chunk, nil
 }
 
-// DecodeRunResult decodes a payload as a RunResultFrame.
+// DecodeRunResult decodes a payload as a RunResultFrame under the
+// default DecodeLimits.
 func DecodeRunResult(payload []byte) (*types.RunResultFrame, error) {
+	if err := checkDecodeLimits(payload, DecodeLimits{}); err != nil {
+		return nil, err
+	}
+	return decodeRunResult(payload)
+}
+
+func decodeRunResult(payload []byte) (*types.RunResultFrame, error) {
 	var result types.RunResultFrame
 	if err := msgpack.Unmarshal(payload, &result); err != nil {
 		return nil, &FrameError{
@@ -237,8 +355,16 @@ func DecodeRunResult(payload []byte) (*types.RunResultFrame, error) {
 	return &result, nil
 }
 
-// DecodeFileWrite decodes a payload as a FileWriteFrame.
+// DecodeFileWrite decodes a payload as a FileWriteFrame under the
+// default DecodeLimits.
 func DecodeFileWrite(payload []byte) (*types.FileWriteFrame, error) {
+	if err := checkDecodeLimits(payload, DecodeLimits{}); err != nil {
+		return nil, err
+	}
+	return decodeFileWrite(payload)
+}
+
+func decodeFileWrite(payload []byte) (*types.FileWriteFrame, error) {
 	var frame types.FileWriteFrame
 	if err := msgpack.Unmarshal(payload, &frame); err != nil {
 		return nil, &FrameError{
@@ -250,8 +376,16 @@ func DecodeFileWrite(payload []byte) (*types.FileWriteFrame, error) {
 	return &frame, nil
 }
 
-// DecodeFileWriteAck decodes a payload as a FileWriteAckFrame.
+// DecodeFileWriteAck decodes a payload as a FileWriteAckFrame under the
+// default DecodeLimits.
 func DecodeFileWriteAck(payload []byte) (*types.FileWriteAckFrame, error) {
+	if err := checkDecodeLimits(payload, DecodeLimits{}); err != nil {
+		return nil, err
+	}
+	return decodeFileWriteAck(payload)
+}
+
+func decodeFileWriteAck(payload []byte) (*types.FileWriteAckFrame, error) {
 	var frame types.FileWriteAckFrame
 	if err := msgpack.Unmarshal(payload, &frame); err != nil {
 		return nil, &FrameError{
@@ -263,6 +397,27 @@ func DecodeFileWriteAck(payload []byte) (*types.FileWriteAckFrame, error) {
 	return &frame, nil
 }
 
+// DecodeProxyUpdate decodes a payload as a ProxyUpdateFrame under the
+// default DecodeLimits.
+func DecodeProxyUpdate(payload []byte) (*types.ProxyUpdateFrame, error) {
+	if err := checkDecodeLimits(payload, DecodeLimits{}); err != nil {
+		return nil, err
+	}
+	return decodeProxyUpdate(payload)
+}
+
+func decodeProxyUpdate(payload []byte) (*types.ProxyUpdateFrame, error) {
+	var frame types.ProxyUpdateFrame
+	if err := msgpack.Unmarshal(payload, &frame); err != nil {
+		return nil, &FrameError{
+			Kind: FrameErrorDecode,
+			Msg:  "failed to decode proxy update",
+			Err:  err,
+		}
+	}
+	return &frame, nil
+}
+
 // EncodeFrame encodes a payload with a 4-byte big-endian length prefix.
 // This is the public encoder counterpart to FrameDecoder.ReadFrame.
 func EncodeFrame(payload []byte) []byte {
@@ -272,11 +427,22 @@ func EncodeFrame(payload []byte) []byte {
 	return buf
 }
 
-// EncodeFileWriteAck encodes a FileWriteAckFrame as a length-prefixed msgpack frame.
-func EncodeFileWriteAck(ack *types.FileWriteAckFrame) ([]byte, error) {
-	payload, err := msgpack.Marshal(ack)
+// zstdEncoder is shared by EncodeCompressedFrame; EncodeAll is safe for
+// concurrent use.
+var zstdEncoder = sync.OnceValues(func() (*zstd.Encoder, error) {
+	return zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+})
+
+// EncodeCompressedFrame encodes a payload as a FrameFlagZstd frame. payload
+// is the uncompressed msgpack bytes; FrameDecoder.ReadFrame returns it
+// unchanged. The executor-side counterpart only compresses when it helps;
+// this encoder always compresses.
+func EncodeCompressedFrame(payload []byte) ([]byte, error) {
+	enc, err := zstdEncoder()
 	if err != nil {
-		return nil, fmt.Errorf("failed to encode file write ack: %w", err)
+		return nil, fmt.Errorf("failed to create zstd encoder: %w", err)
 	}
-	return EncodeFrame(payload), nil
+	buf := enc.EncodeAll(payload, make([]byte, LengthPrefixSize, LengthPrefixSize+len(payload)/2))
+	binary.BigEndian.PutUint32(buf[:LengthPrefixSize], uint32(len(buf)-LengthPrefixSize)|FrameFlagZstd)
+	return buf, nil
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"errors"
 	"io"
+	"strings"
 	"testing"
 
 	"github.com/vmihailenco/msgpack/v5"
@@ -469,6 +470,129 @@ func TestFrameDecoder_PartialFrame(t *testing.T) {
 	}
 }
 
+// TestFrameDecoder_SetMaxFrameSize validates raising and lowering the limit.
+func TestFrameDecoder_SetMaxFrameSize(t *testing.T) {
+	payload := bytes.Repeat([]byte{0xc0}, MaxPayloadSize+1)
+
+	// Raised: a payload over the default limit decodes
+	decoder := NewFrameDecoder(bytes.NewReader(EncodeFrame(payload)))
+	if err := decoder.SetMaxFrameSize(MaxFrameSize * 2); err != nil {
+		t.Fatalf("SetMaxFrameSize: %v", err)
+	}
+	got, err := decoder.ReadFrame()
+	if err != nil {
+		t.Fatalf("ReadFrame with raised limit: %v", err)
+	}
+	if len(got) != len(payload) {
+		t.Errorf("payload = %d bytes, want %d", len(got), len(payload))
+	}
+
+	// Lowered: the frame size includes the length prefix
+	decoder = NewFrameDecoder(bytes.NewReader(EncodeFrame(make([]byte, 16))))
+	if err := decoder.SetMaxFrameSize(16 + LengthPrefixSize - 1); err != nil {
+		t.Fatalf("SetMaxFrameSize: %v", err)
+	}
+	var frameErr *FrameError
+	if _, err := decoder.ReadFrame(); !errors.As(err, &frameErr) || frameErr.Kind != FrameErrorTooLarge {
+		t.Errorf("err = %v, want FrameErrorTooLarge", err)
+	}
+
+	for _, n := range []int64{-1, LengthPrefixSize, MaxFrameSizeCeiling + 1} {
+		if err := NewFrameDecoder(&bytes.Buffer{}).SetMaxFrameSize(n); err == nil {
+			t.Errorf("SetMaxFrameSize(%d): expected error", n)
+		}
+	}
+}
+
+// TestFrameDecoder_CompressedFrames validates that zstd frames decode
+// transparently alongside uncompressed frames in the same stream.
+func TestFrameDecoder_CompressedFrames(t *testing.T) {
+	env := &types.EventEnvelope{
+		ContractVersion: types.Version,
+		EventID:         "evt-001",
+		RunID:           "run-001",
+		Seq:             1,
+		Type:            types.EventTypeItem,
+		Ts:              "2024-01-15T10:00:00Z",
+		Attempt:         1,
+		Payload:         map[string]any{"item_type": "product", "description": strings.Repeat("verbose ", 512)},
+	}
+	payload, err := msgpack.Marshal(env)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	compressed, err := EncodeCompressedFrame(payload)
+	if err != nil {
+		t.Fatalf("EncodeCompressedFrame: %v", err)
+	}
+	if binary.BigEndian.Uint32(compressed)&FrameFlagZstd == 0 {
+		t.Fatal("compressed frame must set FrameFlagZstd")
+	}
+	if len(compressed) >= len(payload) {
+		t.Errorf("compressed frame = %d bytes, want fewer than the %d-byte payload", len(compressed), len(payload))
+	}
+
+	var stream bytes.Buffer
+	stream.Write(EncodeFrame(payload))
+	stream.Write(compressed)
+	decoder := NewFrameDecoder(&stream)
+	for i := range 2 {
+		got, err := decoder.ReadFrame()
+		if err != nil {
+			t.Fatalf("frame %d: ReadFrame: %v", i, err)
+		}
+		if !bytes.Equal(got, payload) {
+			t.Fatalf("frame %d: payload differs after decode", i)
+		}
+		decoded, err := DecodeFrame(got)
+		if err != nil {
+			t.Fatalf("frame %d: DecodeFrame: %v", i, err)
+		}
+		if got := decoded.(*types.EventEnvelope); got.Seq != 1 {
+			t.Errorf("frame %d: seq = %d, want 1", i, got.Seq)
+		}
+	}
+	if _, err := decoder.ReadFrame(); !errors.Is(err, io.EOF) {
+		t.Errorf("err = %v, want io.EOF", err)
+	}
+}
+
+// TestFrameDecoder_CompressedFrameLimit validates that the frame limit
+// applies to the decompressed payload, not just the wire size.
+func TestFrameDecoder_CompressedFrameLimit(t *testing.T) {
+	payload := bytes.Repeat([]byte{0xc0}, 4096)
+	frame, err := EncodeCompressedFrame(payload)
+	if err != nil {
+		t.Fatalf("EncodeCompressedFrame: %v", err)
+	}
+
+	decoder := NewFrameDecoder(bytes.NewReader(frame))
+	if err := decoder.SetMaxFrameSize(1024); err != nil {
+		t.Fatalf("SetMaxFrameSize: %v", err)
+	}
+	_, err = decoder.ReadFrame()
+	var frameErr *FrameError
+	if !errors.As(err, &frameErr) || frameErr.Kind != FrameErrorTooLarge {
+		t.Fatalf("err = %v, want FrameErrorTooLarge for the decompressed size", err)
+	}
+	if !frameErr.IsFatal() {
+		t.Error("oversized decompressed payload should be fatal")
+	}
+}
+
+// TestFrameDecoder_CorruptCompressedFrame validates that a flagged frame
+// whose payload is not zstd fails with a decode error.
+func TestFrameDecoder_CorruptCompressedFrame(t *testing.T) {
+	frame := EncodeFrame([]byte("not zstd"))
+	binary.BigEndian.PutUint32(frame, binary.BigEndian.Uint32(frame)|FrameFlagZstd)
+
+	_, err := NewFrameDecoder(bytes.NewReader(frame)).ReadFrame()
+	var frameErr *FrameError
+	if !errors.As(err, &frameErr) || frameErr.Kind != FrameErrorDecode {
+		t.Errorf("err = %v, want FrameErrorDecode", err)
+	}
+}
+
 // TestFrameDecoder_OversizedFrame validates fatal error for frames exceeding max size.
 // Per CONTRACT_IPC.md: "Maximum frame size: 16 MiB. Frames exceeding this limit
 // are invalid and must be rejected."
@@ -718,9 +842,9 @@ func TestEncodeDecodeFileWriteAck_Success(t *testing.T) {
 		OK:      true,
 	}
 
-	frame, err := EncodeFileWriteAck(ack)
+	frame, err := EncodeControlFrame(ack)
 	if err != nil {
-		t.Fatalf("EncodeFileWriteAck failed: %v", err)
+		t.Fatalf("EncodeControlFrame failed: %v", err)
 	}
 
 	decoder := NewFrameDecoder(bytes.NewReader(frame))
@@ -758,9 +882,9 @@ func TestEncodeDecodeFileWriteAck_Error(t *testing.T) {
 		Error:   &errMsg,
 	}
 
-	frame, err := EncodeFileWriteAck(ack)
+	frame, err := EncodeControlFrame(ack)
 	if err != nil {
-		t.Fatalf("EncodeFileWriteAck failed: %v", err)
+		t.Fatalf("EncodeControlFrame failed: %v", err)
 	}
 
 	decoder := NewFrameDecoder(bytes.NewReader(frame))
@@ -796,9 +920,9 @@ func TestDecodeFrame_FileWriteAck(t *testing.T) {
 		OK:      true,
 	}
 
-	frame, err := EncodeFileWriteAck(ack)
+	frame, err := EncodeControlFrame(ack)
 	if err != nil {
-		t.Fatalf("EncodeFileWriteAck failed: %v", err)
+		t.Fatalf("EncodeControlFrame failed: %v", err)
 	}
 
 	decoder := NewFrameDecoder(bytes.NewReader(frame))
@@ -825,6 +949,54 @@ func TestDecodeFrame_FileWriteAck(t *testing.T) {
 	}
 }
 
+// TestDecodeFrame_ProxyUpdate validates proxy_update roundtrip through DecodeFrame.
+func TestDecodeFrame_ProxyUpdate(t *testing.T) {
+	user, pass, reason := "u", "p", "captcha"
+	update := &types.ProxyUpdateFrame{
+		Type: ProxyUpdateType,
+		Endpoint: types.ProxyEndpoint{
+			Protocol: types.ProxyProtocolHTTP,
+			Host:     "proxy.example.com",
+			Port:     8080,
+			Username: &user,
+			Password: &pass,
+		},
+		Reason: &reason,
+	}
+
+	frame, err := EncodeControlFrame(update)
+	if err != nil {
+		t.Fatalf("EncodeControlFrame failed: %v", err)
+	}
+
+	decoder := NewFrameDecoder(bytes.NewReader(frame))
+	payload, err := decoder.ReadFrame()
+	if err != nil {
+		t.Fatalf("ReadFrame failed: %v", err)
+	}
+
+	result, err := DecodeFrame(payload)
+	if err != nil {
+		t.Fatalf("DecodeFrame failed: %v", err)
+	}
+
+	decoded, ok := result.(*types.ProxyUpdateFrame)
+	if !ok {
+		t.Fatalf("DecodeFrame returned %T, want *types.ProxyUpdateFrame", result)
+	}
+
+	ep := decoded.Endpoint
+	if ep.Host != "proxy.example.com" || ep.Port != 8080 || ep.Protocol != types.ProxyProtocolHTTP {
+		t.Errorf("Endpoint = %+v", ep)
+	}
+	if ep.Password == nil || *ep.Password != "p" {
+		t.Error("Password must be carried to the executor")
+	}
+	if decoded.Reason == nil || *decoded.Reason != "captcha" {
+		t.Errorf("Reason = %v, want captcha", decoded.Reason)
+	}
+}
+
 // TestIsFatalFrameError_NonFrameError validates IsFatalFrameError with non-FrameError.
 func TestIsFatalFrameError_NonFrameError(t *testing.T) {
 	regularErr := errors.New("regular error")
@@ -0,0 +1,115 @@
+package ipc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"github.com/vmihailenco/msgpack/v5/msgpcode"
+)
+
+// Decode limit defaults per CONTRACT_IPC.md. A frame within the size limit
+// can still declare a structure that is pathologically deep (stack
+// exhaustion) or long (allocation amplification); frames over either limit
+// fail to decode instead.
+const (
+	// DefaultMaxDecodeDepth is the maximum nesting depth of maps and
+	// arrays. The frame's top-level map is depth 1.
+	DefaultMaxDecodeDepth = 128
+	// DefaultMaxDecodeContainerLen is the maximum entry count of any single
+	// map or array.
+	DefaultMaxDecodeContainerLen = 1 << 20
+	// MaxDecodeDepthCeiling is the hard ceiling for a depth override.
+	MaxDecodeDepthCeiling = 4096
+)
+
+// ErrDecodeLimit is wrapped by the FrameErrorDecode error of a frame that
+// exceeds its DecodeLimits.
+var ErrDecodeLimit = errors.New("decode limit exceeded")
+
+// DecodeLimits bounds the msgpack structure of a frame payload. Zero fields
+// take the defaults.
+type DecodeLimits struct {
+	// MaxDepth is the maximum map/array nesting depth (0 = DefaultMaxDecodeDepth).
+	MaxDepth int
+	// MaxContainerLen is the maximum entries in one map or array
+	// (0 = DefaultMaxDecodeContainerLen).
+	MaxContainerLen int
+}
+
+// Validate checks a limits override: each field 0 (default) or positive,
+// and MaxDepth at most MaxDecodeDepthCeiling.
+func (l DecodeLimits) Validate() error {
+	if l.MaxDepth < 0 || l.MaxDepth > MaxDecodeDepthCeiling {
+		return fmt.Errorf("max decode depth must be between 1 and %d (0 = default %d), got %d",
+			MaxDecodeDepthCeiling, DefaultMaxDecodeDepth, l.MaxDepth)
+	}
+	if l.MaxContainerLen < 0 {
+		return fmt.Errorf("max decode container length must be >= 0 (0 = default %d), got %d",
+			DefaultMaxDecodeContainerLen, l.MaxContainerLen)
+	}
+	return nil
+}
+
+// withDefaults fills zero fields with the defaults.
+func (l DecodeLimits) withDefaults() DecodeLimits {
+	if l.MaxDepth == 0 {
+		l.MaxDepth = DefaultMaxDecodeDepth
+	}
+	if l.MaxContainerLen == 0 {
+		l.MaxContainerLen = DefaultMaxDecodeContainerLen
+	}
+	return l
+}
+
+// checkDecodeLimits walks payload's msgpack structure without building it
+// and fails on the first map or array beyond limits. Recursion is bounded
+// by MaxDepth, so the walk itself cannot exhaust the stack.
+func checkDecodeLimits(payload []byte, limits DecodeLimits) error {
+	limits = limits.withDefaults()
+	dec := msgpack.NewDecoder(bytes.NewReader(payload))
+	if err := walkDecodeLimits(dec, 1, limits); err != nil {
+		if errors.Is(err, ErrDecodeLimit) {
+			return &FrameError{Kind: FrameErrorDecode, Msg: "payload exceeds decode limits", Err: err}
+		}
+		return &FrameError{Kind: FrameErrorDecode, Msg: "failed to decode payload structure", Err: err}
+	}
+	return nil
+}
+
+// walkDecodeLimits checks the value at the decoder's position, at depth.
+func walkDecodeLimits(dec *msgpack.Decoder, depth int, limits DecodeLimits) error {
+	c, err := dec.PeekCode()
+	if err != nil {
+		return err
+	}
+
+	var n int
+	var entries int // values per entry: 2 for maps, 1 for arrays
+	switch {
+	case msgpcode.IsFixedMap(c) || c == msgpcode.Map16 || c == msgpcode.Map32:
+		n, err = dec.DecodeMapLen()
+		entries = 2
+	case msgpcode.IsFixedArray(c) || c == msgpcode.Array16 || c == msgpcode.Array32:
+		n, err = dec.DecodeArrayLen()
+		entries = 1
+	default:
+		return dec.Skip()
+	}
+	if err != nil {
+		return err
+	}
+	if depth > limits.MaxDepth {
+		return fmt.Errorf("%w: nesting depth exceeds %d", ErrDecodeLimit, limits.MaxDepth)
+	}
+	if n > limits.MaxContainerLen {
+		return fmt.Errorf("%w: container of %d entries exceeds %d", ErrDecodeLimit, n, limits.MaxContainerLen)
+	}
+	for range n * entries {
+		if err := walkDecodeLimits(dec, depth+1, limits); err != nil {
+			return err
+		}
+	}
+	return nil
+}
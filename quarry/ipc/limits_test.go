@@ -0,0 +1,104 @@
+package ipc
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+// nestedArrays encodes depth fixarrays of one element around a nil.
+func nestedArrays(depth int) []byte {
+	return append(bytes.Repeat([]byte{0x91}, depth), 0xc0)
+}
+
+// eventWithData encodes an item event whose payload data field is data.
+func eventWithData(t *testing.T, data any) []byte {
+	t.Helper()
+	payload, err := msgpack.Marshal(map[string]any{
+		"contract_version": "0.1.0", "event_id": "evt-1", "run_id": "run-1", "seq": 1,
+		"type": "item", "ts": "2026-01-01T00:00:00Z", "attempt": 1,
+		"payload": map[string]any{"item_type": "product", "data": data},
+	})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	return payload
+}
+
+func TestDecodeFrame_WithinLimits(t *testing.T) {
+	decoded, err := DecodeFrame(eventWithData(t, map[string]any{"tags": []any{"a", "b"}}))
+	if err != nil {
+		t.Fatalf("DecodeFrame: %v", err)
+	}
+	if e, ok := decoded.(*types.EventEnvelope); !ok || e.Type != types.EventTypeItem {
+		t.Errorf("decoded = %#v, want an item event", decoded)
+	}
+}
+
+func TestDecodeFrame_DepthLimit(t *testing.T) {
+	// A 16 MiB frame of nested one-element arrays would otherwise recurse
+	// millions of levels deep in the decoder
+	payload := nestedArrays(1 << 20)
+	_, err := DecodeFrame(payload)
+	assertDecodeLimitError(t, err, "nesting depth exceeds 128")
+
+	// Envelope map (1) > payload (2) > data (3) > list (4)
+	payload = eventWithData(t, map[string]any{"list": []any{1}})
+	if _, err := DecodeFrameWithLimits(payload, DecodeLimits{MaxDepth: 4}); err != nil {
+		t.Errorf("depth 4 at MaxDepth 4: %v", err)
+	}
+	_, err = DecodeFrameWithLimits(payload, DecodeLimits{MaxDepth: 3})
+	assertDecodeLimitError(t, err, "nesting depth exceeds 3")
+}
+
+func TestDecodeFrame_ContainerLenLimit(t *testing.T) {
+	payload := eventWithData(t, map[string]any{"list": make([]any, 10)})
+	if _, err := DecodeFrameWithLimits(payload, DecodeLimits{MaxContainerLen: 10}); err != nil {
+		t.Errorf("10 entries at MaxContainerLen 10: %v", err)
+	}
+	_, err := DecodeFrameWithLimits(payload, DecodeLimits{MaxContainerLen: 9})
+	assertDecodeLimitError(t, err, "container of 10 entries exceeds 9")
+
+	// A declared array32 length is rejected before any element is read
+	huge := []byte{0xdd, 0xff, 0xff, 0xff, 0xff}
+	_, err = DecodeEventEnvelope(huge)
+	assertDecodeLimitError(t, err, "exceeds 1048576")
+}
+
+func TestDecodeLimits_Validate(t *testing.T) {
+	tests := []struct {
+		limits  DecodeLimits
+		wantErr bool
+	}{
+		{DecodeLimits{}, false},
+		{DecodeLimits{MaxDepth: 1, MaxContainerLen: 1}, false},
+		{DecodeLimits{MaxDepth: MaxDecodeDepthCeiling}, false},
+		{DecodeLimits{MaxDepth: MaxDecodeDepthCeiling + 1}, true},
+		{DecodeLimits{MaxDepth: -1}, true},
+		{DecodeLimits{MaxContainerLen: -1}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.limits.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%+v.Validate() = %v, wantErr %v", tt.limits, err, tt.wantErr)
+		}
+	}
+}
+
+func assertDecodeLimitError(t *testing.T, err error, contains string) {
+	t.Helper()
+	var frameErr *FrameError
+	if !errors.As(err, &frameErr) || frameErr.Kind != FrameErrorDecode {
+		t.Fatalf("err = %v, want a FrameErrorDecode", err)
+	}
+	if frameErr.IsFatal() {
+		t.Error("decode limit errors must not be fatal framing errors")
+	}
+	if !errors.Is(err, ErrDecodeLimit) || !strings.Contains(err.Error(), contains) {
+		t.Errorf("err = %v, want ErrDecodeLimit containing %q", err, contains)
+	}
+}
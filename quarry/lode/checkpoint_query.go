@@ -0,0 +1,88 @@
+package lode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/pithecene-io/lode/lode"
+)
+
+// ErrNoCheckpointFound is returned when no checkpoint event exists for the
+// requested source and category.
+var ErrNoCheckpointFound = errors.New("no checkpoint found")
+
+// CheckpointRecord is the final checkpoint event of a prior run.
+type CheckpointRecord struct {
+	RunID   string
+	Seq     int64
+	Ts      string
+	Payload map[string]any
+}
+
+// QueryLatestCheckpoint finds the final checkpoint event of the most recent
+// run that wrote one for source and category (empty string matches any).
+// tenant always matches exactly, so "" matches only untenanted runs and one
+// tenant never resumes from another's checkpoint.
+// Runs in excludeRunID are skipped, so a run never resumes from itself.
+// Returns ErrNoCheckpointFound if no checkpoint exists or the dataset is empty.
+//
+// Snapshots are pre-filtered by the event_type=checkpoint partition only;
+// tenant, source, and category are matched on record fields, which are present
+// regardless of the partition template.
+func QueryLatestCheckpoint(ctx context.Context, ds lode.Dataset, tenant, source, category, excludeRunID string) (*CheckpointRecord, error) {
+	snapshots, err := ds.Snapshots(ctx)
+	if err != nil {
+		if errors.Is(err, lode.ErrNotFound) || errors.Is(err, lode.ErrNoSnapshots) {
+			return nil, ErrNoCheckpointFound
+		}
+		return nil, WrapReadError(err, "quarry/snapshots")
+	}
+
+	// Iterate in reverse (latest first) — snapshots are ordered by creation time
+	for i := len(snapshots) - 1; i >= 0; i-- {
+		snap := snapshots[i]
+		if !snapshotMatchesFilter(snap, "event_type", "checkpoint") {
+			continue
+		}
+
+		data, err := ds.Read(ctx, snap.ID)
+		if err != nil {
+			return nil, WrapReadError(err, fmt.Sprintf("quarry/snapshot/%s", snap.ID))
+		}
+
+		// The latest snapshot with a matching checkpoint belongs to the most
+		// recent run; its highest-seq checkpoint is that run's final one.
+		var latest *CheckpointRecord
+		for _, item := range data {
+			record, ok := item.(map[string]any)
+			if !ok || record["record_kind"] != RecordKindEvent || record["type"] != "checkpoint" {
+				continue
+			}
+			runID := toString(record["run_id"])
+			if runID == excludeRunID {
+				continue
+			}
+			if toString(record[TenantPartitionKey]) != tenant {
+				continue
+			}
+			if source != "" && toString(record["source"]) != source {
+				continue
+			}
+			if category != "" && toString(record["category"]) != category {
+				continue
+			}
+			seq := toInt64Any(record["seq"])
+			if latest != nil && (latest.RunID != runID || latest.Seq >= seq) {
+				continue
+			}
+			payload, _ := record["payload"].(map[string]any)
+			latest = &CheckpointRecord{RunID: runID, Seq: seq, Ts: toString(record["ts"]), Payload: payload}
+		}
+		if latest != nil {
+			return latest, nil
+		}
+	}
+
+	return nil, ErrNoCheckpointFound
+}
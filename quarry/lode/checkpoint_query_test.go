@@ -0,0 +1,115 @@
+package lode
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pithecene-io/lode/lode"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+func writeCheckpoints(t *testing.T, factory lode.StoreFactory, tenant, source, runID string, ids ...string) {
+	t.Helper()
+	client, err := NewLodeClientWithFactory(Config{
+		Dataset:  "quarry",
+		Tenant:   tenant,
+		Source:   source,
+		Category: "default",
+		Day:      "2026-02-03",
+		RunID:    runID,
+	}, factory)
+	if err != nil {
+		t.Fatalf("NewLodeClientWithFactory: %v", err)
+	}
+	events := []*types.EventEnvelope{{EventID: "item-1", RunID: runID, Seq: 1, Type: types.EventTypeItem, Payload: map[string]any{}}}
+	for i, id := range ids {
+		events = append(events, &types.EventEnvelope{
+			EventID: id,
+			RunID:   runID,
+			Seq:     int64(i + 2),
+			Type:    types.EventTypeCheckpoint,
+			Payload: map[string]any{"checkpoint_id": id},
+		})
+	}
+	if err := client.WriteEvents(t.Context(), "", "", events); err != nil {
+		t.Fatalf("WriteEvents: %v", err)
+	}
+}
+
+func TestQueryLatestCheckpoint_FinalCheckpointOfLatestRun(t *testing.T) {
+	factory := sharedFactory(lode.NewMemory())
+	writeCheckpoints(t, factory, "", "shop", "run-001", "cp-a", "cp-b")
+	writeCheckpoints(t, factory, "", "other", "run-002", "cp-other")
+
+	ds, err := NewReadDataset("quarry", factory)
+	if err != nil {
+		t.Fatalf("NewReadDataset: %v", err)
+	}
+
+	got, err := QueryLatestCheckpoint(t.Context(), ds, "", "shop", "default", "run-999")
+	if err != nil {
+		t.Fatalf("QueryLatestCheckpoint: %v", err)
+	}
+	if got.RunID != "run-001" || got.Seq != 3 || got.Payload["checkpoint_id"] != "cp-b" {
+		t.Errorf("got %+v, want run-001's final checkpoint cp-b", got)
+	}
+
+	// Any source: the most recent run wins
+	got, err = QueryLatestCheckpoint(t.Context(), ds, "", "", "", "")
+	if err != nil {
+		t.Fatalf("QueryLatestCheckpoint: %v", err)
+	}
+	if got.RunID != "run-002" {
+		t.Errorf("RunID = %s, want run-002", got.RunID)
+	}
+
+	// Excluding the only run for a source finds nothing
+	if _, err := QueryLatestCheckpoint(t.Context(), ds, "", "shop", "", "run-001"); !errors.Is(err, ErrNoCheckpointFound) {
+		t.Errorf("expected ErrNoCheckpointFound, got %v", err)
+	}
+}
+
+func TestQueryLatestCheckpoint_TenantIsolation(t *testing.T) {
+	factory := sharedFactory(lode.NewMemory())
+	writeCheckpoints(t, factory, "acme", "shop", "run-001", "cp-acme")
+	writeCheckpoints(t, factory, "globex", "shop", "run-002", "cp-globex")
+
+	ds, err := NewReadDataset("quarry", factory)
+	if err != nil {
+		t.Fatalf("NewReadDataset: %v", err)
+	}
+
+	got, err := QueryLatestCheckpoint(t.Context(), ds, "acme", "shop", "default", "")
+	if err != nil {
+		t.Fatalf("QueryLatestCheckpoint: %v", err)
+	}
+	if got.Payload["checkpoint_id"] != "cp-acme" {
+		t.Errorf("got %v, want acme's own checkpoint", got.Payload)
+	}
+
+	// Untenanted runs never see tenant checkpoints
+	if _, err := QueryLatestCheckpoint(t.Context(), ds, "", "shop", "", ""); !errors.Is(err, ErrNoCheckpointFound) {
+		t.Errorf("expected ErrNoCheckpointFound without a tenant, got %v", err)
+	}
+}
+
+func TestQueryLatestCheckpoint_EmptyDataset(t *testing.T) {
+	ds, err := NewReadDataset("quarry", sharedFactory(lode.NewMemory()))
+	if err != nil {
+		t.Fatalf("NewReadDataset: %v", err)
+	}
+	if _, err := QueryLatestCheckpoint(t.Context(), ds, "", "shop", "", ""); !errors.Is(err, ErrNoCheckpointFound) {
+		t.Errorf("expected ErrNoCheckpointFound, got %v", err)
+	}
+}
+
+func TestQueryLatestCheckpoint_FreshFSRoot(t *testing.T) {
+	ds, err := NewReadDatasetFS("quarry", t.TempDir())
+	if err != nil {
+		t.Fatalf("NewReadDatasetFS: %v", err)
+	}
+	if _, err := QueryLatestCheckpoint(t.Context(), ds, "", "shop", "", ""); !errors.Is(err, ErrNoCheckpointFound) {
+		t.Errorf("expected ErrNoCheckpointFound for a fresh storage path, got %v", err)
+	}
+}
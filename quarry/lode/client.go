@@ -12,6 +12,7 @@ import (
 	"github.com/pithecene-io/lode/lode"
 
 	"github.com/pithecene-io/quarry/metrics"
+	"github.com/pithecene-io/quarry/policy"
 	"github.com/pithecene-io/quarry/types"
 )
 
@@ -28,16 +29,26 @@ var ErrCommitWithoutChunks = errors.New("artifact commit rejected: no chunks wri
 var ErrMissingArtifactID = errors.New("artifact commit rejected: missing or empty artifact_id")
 
 // LodeClient is a real Lode-backed implementation of Client.
-// Uses Lode's HiveLayout with partition keys: source/category/day/run_id/event_type.
+// Uses Lode's HiveLayout with partition keys from Config.PartitionTemplate
+// (default: source/category/day/run_id), followed by event_type.
 type LodeClient struct { //nolint:revive // intentional naming for clarity
 	dataset      lode.Dataset
+	itemDataset  lode.Dataset // Parquet dataset for item events (FormatParquet); nil otherwise
 	config       Config
 	storeFactory lode.StoreFactory // for sidecar file writes via FileWriter
 
-	mu           sync.Mutex          // guards offsets, chunksSeen, and pendingFiles
+	partitionPath    string            // rendered partition template (without event_type)
+	partitionColumns map[string]string // template-only partition keys added to every record
+
+	mu           sync.Mutex          // guards offsets, chunksSeen, pendingFiles, written, and retention state
 	offsets      map[string]int64    // cumulative offset per artifact across batches
 	chunksSeen   map[string]struct{} // tracks artifacts that have had chunks written
 	pendingFiles []SidecarFileRef    // sidecar files written since last snapshot flush
+	written      []ManifestObject    // partition manifest write log (Config.PartitionManifest)
+
+	retention      retentionApplier          // records artifact retention hints on chunk files
+	chunkFiles     map[string][]string       // data files holding each uncommitted artifact's chunks
+	retentionFiles map[string]*retentionFile // chunk files awaiting their artifacts' commits
 
 	storeOnce sync.Once  // lazy store initialization for FileWriter
 	store     lode.Store // lazily created from storeFactory
@@ -46,14 +57,41 @@ type LodeClient struct { //nolint:revive // intentional naming for clarity
 
 // newClient creates a LodeClient from a dataset, config, and store factory.
 // All constructors must use this to ensure consistent initialization.
-func newClient(ds lode.Dataset, cfg Config, factory lode.StoreFactory) *LodeClient {
-	return &LodeClient{
-		dataset:      ds,
-		config:       cfg,
-		storeFactory: factory,
-		offsets:      make(map[string]int64),
-		chunksSeen:   make(map[string]struct{}),
+// Renders the partition template once; fails if it cannot be rendered.
+func newClient(ds lode.Dataset, cfg Config, factory lode.StoreFactory) (*LodeClient, error) {
+	partitionPath, err := cfg.PartitionPath()
+	if err != nil {
+		return nil, WrapInitError(err, cfg.Dataset)
+	}
+	columns, err := cfg.partitionColumns()
+	if err != nil {
+		return nil, WrapInitError(err, cfg.Dataset)
+	}
+	var itemDS lode.Dataset
+	if cfg.Format == FormatParquet {
+		codec, err := newItemParquetCodec()
+		if err != nil {
+			return nil, WrapInitError(err, cfg.Dataset)
+		}
+		itemDS, err = lode.NewDataset(lode.DatasetID(cfg.Dataset), factory, cfg.datasetOptions(codec)...)
+		if err != nil {
+			return nil, WrapInitError(err, cfg.Dataset)
+		}
+	}
+	c := &LodeClient{
+		dataset:          ds,
+		itemDataset:      itemDS,
+		config:           cfg,
+		storeFactory:     factory,
+		partitionPath:    partitionPath,
+		partitionColumns: columns,
+		offsets:          make(map[string]int64),
+		chunksSeen:       make(map[string]struct{}),
+		chunkFiles:       make(map[string][]string),
+		retentionFiles:   make(map[string]*retentionFile),
 	}
+	c.retention = sidecarRetention{client: c}
+	return c, nil
 }
 
 // NewLodeClient creates a new Lode client with filesystem storage.
@@ -65,18 +103,25 @@ func NewLodeClient(cfg Config, root string) (*LodeClient, error) {
 // NewLodeClientWithFactory creates a new Lode client with a custom store factory.
 // Use lode.NewMemoryFactory() for testing.
 func NewLodeClientWithFactory(cfg Config, factory lode.StoreFactory) (*LodeClient, error) {
-	ds, err := lode.NewDataset(
-		lode.DatasetID(cfg.Dataset),
-		factory,
-		lode.WithHiveLayout("source", "category", "day", "run_id", "event_type"),
-		lode.WithCodec(lode.NewJSONLCodec()),
-		lode.WithRetryCount(3),
-	)
+	ds, err := lode.NewDataset(lode.DatasetID(cfg.Dataset), factory, cfg.datasetOptions(lode.NewJSONLCodec())...)
 	if err != nil {
 		return nil, WrapInitError(err, cfg.Dataset)
 	}
 
-	return newClient(ds, cfg, factory), nil
+	return newClient(ds, cfg, factory)
+}
+
+// datasetOptions returns the write Dataset options shared by all backends.
+func (cfg Config) datasetOptions(codec lode.Codec) []lode.Option {
+	opts := []lode.Option{
+		lode.WithHiveLayout(cfg.hiveKeys()...),
+		lode.WithCodec(codec),
+		lode.WithRetryCount(3),
+	}
+	if cfg.PartitionManifest {
+		opts = append(opts, lode.WithChecksum(sha256Checksum{}))
+	}
+	return opts
 }
 
 // WriteEvents writes a batch of events to Lode.
@@ -87,6 +132,16 @@ func NewLodeClientWithFactory(cfg Config, factory lode.StoreFactory) (*LodeClien
 // Enforces "chunks before commit" invariant: artifact commit events are rejected
 // if no chunks have been written for that artifact. After a successful commit,
 // the artifact's offset and chunksSeen state are reset.
+//
+// Chunk files whose artifacts have all committed with the same retention
+// hint get that hint (object tags or a sidecar) before the commit records
+// are written.
+//
+// Under FormatParquet, item events are written to the Parquet item dataset
+// as a separate snapshot; the rest of the batch stays JSON Lines.
+//
+// A streaming flush sequence in ctx (policy.FlushInfoFromContext) is
+// recorded as flush_seq on every snapshot the batch writes.
 func (c *LodeClient) WriteEvents(ctx context.Context, _, _ string, events []*types.EventEnvelope) error {
 	if len(events) == 0 {
 		return nil
@@ -97,8 +152,10 @@ func (c *LodeClient) WriteEvents(ctx context.Context, _, _ string, events []*typ
 
 	// Collect artifact IDs being committed for post-write cleanup
 	var committedArtifacts []string
+	retention := make(map[string]artifactRetention)
 
 	records := make([]any, 0, len(events))
+	var items []any
 	for _, e := range events {
 		var record map[string]any
 		if e.Type == types.EventTypeArtifact {
@@ -110,17 +167,48 @@ func (c *LodeClient) WriteEvents(ctx context.Context, _, _ string, events []*typ
 			if _, seen := c.chunksSeen[artifactID]; !seen {
 				return fmt.Errorf("%w: %s", ErrCommitWithoutChunks, artifactID)
 			}
+			hint, err := ParseRetentionHint(e.Payload)
+			if err != nil {
+				return fmt.Errorf("artifact %s: %w", artifactID, err)
+			}
 			committedArtifacts = append(committedArtifacts, artifactID)
+			retention[artifactID] = artifactRetention{hint: hint, expires: hint.ExpiresAt(e.Ts)}
 			record = toArtifactCommitRecordMap(e, c.config)
 		} else {
 			record = toEventRecordMap(e, c.config)
 		}
+		c.addPartitionColumns(record)
+		if c.itemDataset != nil && isParquetItem(e) {
+			if err := encodeParquetPayload(record); err != nil {
+				return err
+			}
+			items = append(items, record)
+			continue
+		}
 		records = append(records, record)
 	}
 
-	_, err := c.dataset.Write(ctx, records, c.snapshotMetadata())
-	if err != nil {
-		return WrapWriteError(err, buildPartitionPath(c.config, string(events[0].Type)))
+	updates, ready := c.planRetention(committedArtifacts, retention)
+	if err := c.applyRetentionPlan(ctx, ready); err != nil {
+		return err
+	}
+
+	// Pending sidecar refs go on the first snapshot only
+	meta := withFlushSeq(ctx, c.snapshotMetadata())
+	if len(items) > 0 {
+		snap, err := c.itemDataset.Write(ctx, items, meta)
+		if err != nil {
+			return WrapWriteError(err, c.buildPartitionPath(string(types.EventTypeItem)))
+		}
+		c.recordDataFiles(snap.Manifest.Files)
+		meta = withFlushSeq(ctx, lode.Metadata{})
+	}
+	if len(records) > 0 {
+		snap, err := c.dataset.Write(ctx, records, meta)
+		if err != nil {
+			return WrapWriteError(err, c.buildPartitionPath(string(events[0].Type)))
+		}
+		c.recordDataFiles(snap.Manifest.Files)
 	}
 
 	// Reset state for committed artifacts
@@ -128,6 +216,7 @@ func (c *LodeClient) WriteEvents(ctx context.Context, _, _ string, events []*typ
 		delete(c.offsets, artifactID)
 		delete(c.chunksSeen, artifactID)
 	}
+	c.commitRetentionPlan(committedArtifacts, updates)
 	c.drainPendingFiles()
 
 	return nil
@@ -157,6 +246,7 @@ func (c *LodeClient) WriteChunks(ctx context.Context, _, _ string, chunks []*typ
 	for _, chunk := range chunks {
 		offset := localOffsets[chunk.ArtifactID]
 		record := toChunkRecordMap(chunk, offset, c.config)
+		c.addPartitionColumns(record)
 
 		// Add checksum if enabled
 		if checksumEnabled {
@@ -172,16 +262,20 @@ func (c *LodeClient) WriteChunks(ctx context.Context, _, _ string, chunks []*typ
 	// Write to storage.
 	// Chunk writes use empty metadata — sidecar file refs are only flushed
 	// on event and metrics writes, which are the consumer-facing boundaries.
-	_, err := c.dataset.Write(ctx, records, lode.Metadata{})
+	snap, err := c.dataset.Write(ctx, records, lode.Metadata{})
 	if err != nil {
-		return WrapWriteError(err, buildPartitionPath(c.config, "artifact"))
+		return WrapWriteError(err, c.buildPartitionPath("artifact"))
 	}
 
 	// Only update state after successful write
+	batchArtifacts := make(map[string]struct{})
 	for _, chunk := range chunks {
 		c.offsets[chunk.ArtifactID] = localOffsets[chunk.ArtifactID]
 		c.chunksSeen[chunk.ArtifactID] = struct{}{}
+		batchArtifacts[chunk.ArtifactID] = struct{}{}
 	}
+	c.trackChunkFiles(batchArtifacts, snap.Manifest.Files)
+	c.recordDataFiles(snap.Manifest.Files)
 
 	return nil
 }
@@ -194,10 +288,12 @@ func (c *LodeClient) WriteMetrics(ctx context.Context, snap metrics.Snapshot, co
 	defer c.mu.Unlock()
 
 	record := toMetricsRecordMap(snap, c.config, completedAt)
-	_, err := c.dataset.Write(ctx, []any{record}, c.snapshotMetadata())
+	c.addPartitionColumns(record)
+	written, err := c.dataset.Write(ctx, []any{record}, c.snapshotMetadata())
 	if err != nil {
-		return WrapWriteError(err, buildPartitionPath(c.config, "metrics"))
+		return WrapWriteError(err, c.buildPartitionPath("metrics"))
 	}
+	c.recordDataFiles(written.Manifest.Files)
 	c.drainPendingFiles()
 	return nil
 }
@@ -212,6 +308,20 @@ func (c *LodeClient) Close() error {
 // Downstream consumers read this to enumerate files without prefix-scanning.
 const MetadataKeySidecarFiles = "sidecar_files"
 
+// MetadataKeyFlushSeq is the Metadata key for the streaming flush sequence
+// of an event snapshot. Consumers tailing a run list snapshots and process
+// them in flush_seq order; sequences are gap-free.
+const MetadataKeyFlushSeq = "flush_seq"
+
+// withFlushSeq sets MetadataKeyFlushSeq on meta if ctx carries a streaming
+// flush sequence.
+func withFlushSeq(ctx context.Context, meta lode.Metadata) lode.Metadata {
+	if info, ok := policy.FlushInfoFromContext(ctx); ok {
+		meta[MetadataKeyFlushSeq] = info.Seq
+	}
+	return meta
+}
+
 // snapshotMetadata returns Metadata containing any pending sidecar file refs.
 // Returns empty metadata if no files are pending.
 // Only called from WriteEvents and WriteMetrics — chunk writes do not flush
@@ -233,6 +343,13 @@ func (c *LodeClient) drainPendingFiles() {
 	c.pendingFiles = c.pendingFiles[:0]
 }
 
+// addPartitionColumns sets template-only partition keys on a record.
+func (c *LodeClient) addPartitionColumns(record map[string]any) {
+	for key, value := range c.partitionColumns {
+		record[key] = value
+	}
+}
+
 // computeMD5 returns the hex-encoded MD5 digest of data.
 func computeMD5(data []byte) string {
 	hash := md5.Sum(data)
@@ -252,8 +369,8 @@ func extractArtifactID(payload map[string]any) string {
 }
 
 // buildPartitionPath constructs a human-readable partition path for error messages.
-func buildPartitionPath(cfg Config, eventType string) string {
-	return cfg.Dataset + "/" + cfg.Source + "/" + cfg.Category + "/" + cfg.Day + "/" + cfg.RunID + "/" + eventType
+func (c *LodeClient) buildPartitionPath(eventType string) string {
+	return c.config.Dataset + "/" + c.partitionPath + "/event_type=" + eventType
 }
 
 // Verify LodeClient implements Client.
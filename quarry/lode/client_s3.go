@@ -2,12 +2,22 @@ package lode
 
 import (
 	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
 	"strings"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/pithecene-io/lode/lode"
 	lodes3 "github.com/pithecene-io/lode/lode/s3"
 )
@@ -26,16 +36,204 @@ type S3Config struct {
 	// UsePathStyle forces path-style addressing (bucket in path, not subdomain).
 	// Required by most S3-compatible providers (R2, MinIO, etc.).
 	UsePathStyle bool
+	// SSE is the server-side encryption applied to every object write
+	// ("AES256", "aws:kms", "aws:kms:dsse"). Empty uses the bucket default.
+	SSE string
+	// KMSKeyID is the KMS key ID or ARN for SSE "aws:kms" / "aws:kms:dsse".
+	// Empty uses the AWS-managed key.
+	KMSKeyID string
+	// StorageClass is applied to every object write (e.g. "GLACIER_IR").
+	// Empty uses the bucket default (STANDARD).
+	StorageClass string
+	// EgressProxy routes S3 requests through this HTTP(S) proxy, overriding
+	// HTTPS_PROXY / HTTP_PROXY / NO_PROXY. Nil uses the environment.
+	EgressProxy *url.URL
+	// SkipExisting HEADs the key before each create-only sidecar object
+	// write (files, their .meta.json, partition manifests) and skips the PUT
+	// when an object with the same size and content digest already exists.
+	// Dataset segments are never checked: their snapshot keys are unique.
+	// Retries do not benefit, as each attempt writes its own run_id partition.
+	SkipExisting bool
+	// OnWriteSkipped is called for each write skipped by SkipExisting.
+	// May be nil.
+	OnWriteSkipped func()
 }
 
-// Validate checks that required S3 configuration is present.
+// sseValues are the server-side encryption modes accepted for writes.
+var sseValues = []s3types.ServerSideEncryption{
+	s3types.ServerSideEncryptionAes256,
+	s3types.ServerSideEncryptionAwsKms,
+	s3types.ServerSideEncryptionAwsKmsDsse,
+}
+
+// Validate checks that required S3 configuration is present and that the
+// write options form a valid combination.
 func (c *S3Config) Validate() error {
 	if c.Bucket == "" {
 		return errors.New("S3 bucket is required")
 	}
+	return c.ValidateWriteOptions()
+}
+
+// ValidateWriteOptions checks SSE, KMS key, and storage class. It does not
+// require a bucket, so callers can validate options before the path is parsed.
+func (c *S3Config) ValidateWriteOptions() error {
+	if c.SSE != "" && !slices.Contains(sseValues, s3types.ServerSideEncryption(c.SSE)) {
+		return fmt.Errorf("invalid S3 server-side encryption %q (valid: AES256, aws:kms, aws:kms:dsse)", c.SSE)
+	}
+	if c.KMSKeyID != "" {
+		sse := s3types.ServerSideEncryption(c.SSE)
+		if sse != s3types.ServerSideEncryptionAwsKms && sse != s3types.ServerSideEncryptionAwsKmsDsse {
+			return errors.New("S3 KMS key requires server-side encryption aws:kms or aws:kms:dsse")
+		}
+	}
+	if c.StorageClass != "" && !slices.Contains(s3types.StorageClass("").Values(), s3types.StorageClass(c.StorageClass)) {
+		return fmt.Errorf("invalid S3 storage class %q", c.StorageClass)
+	}
 	return nil
 }
 
+// loadOptions returns the AWS config load options for region and egress proxy.
+func (c *S3Config) loadOptions() []func(*config.LoadOptions) error {
+	var opts []func(*config.LoadOptions) error
+	if c.Region != "" {
+		opts = append(opts, config.WithRegion(c.Region))
+	}
+	if c.EgressProxy != nil {
+		proxy := http.ProxyURL(c.EgressProxy)
+		client := awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+			tr.Proxy = proxy
+		})
+		opts = append(opts, config.WithHTTPClient(client))
+	}
+	return opts
+}
+
+// clientOptions returns the S3 client options for a custom endpoint and
+// path-style addressing (MinIO, R2, and other S3-compatible stores). Read
+// and write clients must share them to reach the same bucket.
+func (c *S3Config) clientOptions() []func(*s3.Options) {
+	var opts []func(*s3.Options)
+	if c.Endpoint != "" {
+		endpoint := c.Endpoint
+		opts = append(opts, func(o *s3.Options) {
+			o.BaseEndpoint = &endpoint
+		})
+	}
+	if c.UsePathStyle {
+		opts = append(opts, func(o *s3.Options) {
+			o.UsePathStyle = true
+		})
+	}
+	return opts
+}
+
+// hasWriteOptions reports whether any per-object write option is set.
+func (c *S3Config) hasWriteOptions() bool {
+	return c.SSE != "" || c.KMSKeyID != "" || c.StorageClass != ""
+}
+
+// writeOptionsAPI applies server-side encryption and storage class to every
+// object write: single PutObject calls and multipart uploads. Reads and
+// listings pass through unchanged.
+type writeOptionsAPI struct {
+	lodes3.API
+	cfg S3Config
+}
+
+// PutObject applies the write options to a copy of params.
+func (w *writeOptionsAPI) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	in := *params
+	in.ServerSideEncryption = s3types.ServerSideEncryption(w.cfg.SSE)
+	if w.cfg.KMSKeyID != "" {
+		in.SSEKMSKeyId = &w.cfg.KMSKeyID
+	}
+	in.StorageClass = s3types.StorageClass(w.cfg.StorageClass)
+	return w.API.PutObject(ctx, &in, optFns...)
+}
+
+// CreateMultipartUpload applies the write options to a copy of params.
+// Parts inherit encryption and storage class from the upload.
+func (w *writeOptionsAPI) CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	in := *params
+	in.ServerSideEncryption = s3types.ServerSideEncryption(w.cfg.SSE)
+	if w.cfg.KMSKeyID != "" {
+		in.SSEKMSKeyId = &w.cfg.KMSKeyID
+	}
+	in.StorageClass = s3types.StorageClass(w.cfg.StorageClass)
+	return w.API.CreateMultipartUpload(ctx, &in, optFns...)
+}
+
+// skipContentMD5Key is the user metadata key holding the hex MD5 of an
+// object written with SkipExisting. Unlike the ETag, it is the content
+// digest under every encryption mode.
+const skipContentMD5Key = "quarry-content-md5"
+
+// skipExistingAPI skips create-only PutObject calls (If-None-Match: *) whose
+// object already exists with the same content. Any other write, and any
+// write whose HEAD fails, passes through; a differing object still fails
+// the conditional PUT as before. Multipart uploads (objects over 5 GB) are
+// never skipped.
+type skipExistingAPI struct {
+	lodes3.API
+	onSkip func()
+}
+
+// PutObject HEADs the key and returns without writing when the existing
+// object matches params.Body. Objects written through it carry their
+// content MD5 as user metadata.
+func (w *skipExistingAPI) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	body, ok := params.Body.(io.ReadSeeker)
+	if aws.ToString(params.IfNoneMatch) != "*" || !ok {
+		return w.API.PutObject(ctx, params, optFns...)
+	}
+	h := md5.New()
+	size, err := io.Copy(h, body)
+	if err != nil {
+		return nil, fmt.Errorf("s3: hashing object body: %w", err)
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("s3: seeking object body: %w", err)
+	}
+	digest := hex.EncodeToString(h.Sum(nil))
+
+	head, err := w.API.HeadObject(ctx, &s3.HeadObjectInput{Bucket: params.Bucket, Key: params.Key}, optFns...)
+	if err == nil && sameObject(head, size, digest) {
+		if w.onSkip != nil {
+			w.onSkip()
+		}
+		return &s3.PutObjectOutput{ETag: head.ETag}, nil
+	}
+
+	in := *params
+	in.Metadata = maps.Clone(params.Metadata)
+	if in.Metadata == nil {
+		in.Metadata = make(map[string]string, 1)
+	}
+	in.Metadata[skipContentMD5Key] = digest
+	return w.API.PutObject(ctx, &in, optFns...)
+}
+
+// sameObject reports whether head describes an object of size bytes with
+// the hex MD5 digest. The digest is taken from the object's metadata, or
+// else from its ETag when that is a plain MD5: not a multipart ETag and not
+// SSE-KMS encrypted.
+func sameObject(head *s3.HeadObjectOutput, size int64, digest string) bool {
+	if aws.ToInt64(head.ContentLength) != size {
+		return false
+	}
+	if stored, ok := head.Metadata[skipContentMD5Key]; ok {
+		return stored == digest
+	}
+	etag := strings.Trim(aws.ToString(head.ETag), `"`)
+	if strings.Contains(etag, "-") ||
+		head.ServerSideEncryption == s3types.ServerSideEncryptionAwsKms ||
+		head.ServerSideEncryption == s3types.ServerSideEncryptionAwsKmsDsse {
+		return false
+	}
+	return etag == digest
+}
+
 // ParseS3Path parses a path in format "bucket/prefix" or "bucket".
 func ParseS3Path(path string) (bucket, prefix string) {
 	parts := strings.SplitN(path, "/", 2)
@@ -53,53 +251,60 @@ func NewLodeS3Client(cfg Config, s3cfg S3Config) (*LodeClient, error) {
 		return nil, err
 	}
 
-	// Load AWS config with optional region
+	// Load AWS config with optional region and egress proxy
 	ctx := context.Background()
-	var opts []func(*config.LoadOptions) error
-	if s3cfg.Region != "" {
-		opts = append(opts, config.WithRegion(s3cfg.Region))
-	}
-
-	awsConfig, err := config.LoadDefaultConfig(ctx, opts...)
+	awsConfig, err := config.LoadDefaultConfig(ctx, s3cfg.loadOptions()...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
 	// Create S3 client with optional endpoint and path-style overrides
-	var s3Opts []func(*s3.Options)
-	if s3cfg.Endpoint != "" {
-		endpoint := s3cfg.Endpoint
-		s3Opts = append(s3Opts, func(o *s3.Options) {
-			o.BaseEndpoint = &endpoint
-		})
-	}
-	if s3cfg.UsePathStyle {
-		s3Opts = append(s3Opts, func(o *s3.Options) {
-			o.UsePathStyle = true
-		})
-	}
-	s3Client := s3.NewFromConfig(awsConfig, s3Opts...)
-
-	// Create Lode S3 store factory
-	// StoreFactory is func() (Store, error)
-	s3Factory := func() (lode.Store, error) {
-		return lodes3.New(s3Client, lodes3.Config{
-			Bucket: s3cfg.Bucket,
-			Prefix: s3cfg.Prefix,
-		})
-	}
+	rawClient := s3.NewFromConfig(awsConfig, s3cfg.clientOptions()...)
+	datasetFactory, fileFactory := newS3StoreFactories(rawClient, s3cfg)
 
 	// Create dataset with Hive layout
-	ds, err := lode.NewDataset(
-		lode.DatasetID(cfg.Dataset),
-		s3Factory,
-		lode.WithHiveLayout("source", "category", "day", "run_id", "event_type"),
-		lode.WithCodec(lode.NewJSONLCodec()),
-		lode.WithRetryCount(3),
-	)
+	ds, err := lode.NewDataset(lode.DatasetID(cfg.Dataset), datasetFactory, cfg.datasetOptions(lode.NewJSONLCodec())...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Lode dataset: %w", err)
 	}
 
-	return newClient(ds, cfg, s3Factory), nil
+	c, err := newClient(ds, cfg, fileFactory)
+	if err != nil {
+		return nil, err
+	}
+	c.retention = newS3TagRetention(rawClient, s3cfg)
+	return c, nil
+}
+
+// newS3StoreFactories returns the store factory for the Dataset and the one
+// for sidecar writes (files, partition manifests). Both apply the write
+// options; only the sidecar store skips existing objects, since Dataset
+// segments are written under fresh snapshot keys a HEAD can never match.
+func newS3StoreFactories(api lodes3.API, s3cfg S3Config) (dataset, files lode.StoreFactory) {
+	if s3cfg.hasWriteOptions() {
+		api = &writeOptionsAPI{API: api, cfg: s3cfg}
+	}
+	fileAPI := api
+	if s3cfg.SkipExisting {
+		fileAPI = &skipExistingAPI{API: api, onSkip: s3cfg.OnWriteSkipped}
+	}
+	factory := func(api lodes3.API) lode.StoreFactory {
+		return func() (lode.Store, error) {
+			return lodes3.New(api, lodes3.Config{
+				Bucket: s3cfg.Bucket,
+				Prefix: s3cfg.Prefix,
+			})
+		}
+	}
+	return factory(api), factory(fileAPI)
+}
+
+// newS3TagRetention tags chunk objects under the same key prefix the
+// Lode S3 store writes to.
+func newS3TagRetention(api s3TaggingAPI, s3cfg S3Config) s3TagRetention {
+	prefix := s3cfg.Prefix
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return s3TagRetention{api: api, bucket: s3cfg.Bucket, prefix: prefix}
 }
@@ -1,13 +1,29 @@
 package lode
 
 import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
 	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/pithecene-io/lode/lode"
+	lodes3 "github.com/pithecene-io/lode/lode/s3"
 
 	"github.com/pithecene-io/quarry/metrics"
+	"github.com/pithecene-io/quarry/policy"
 	"github.com/pithecene-io/quarry/types"
 )
 
@@ -503,6 +519,36 @@ func TestS3Config_Validate(t *testing.T) {
 			cfg:     S3Config{Bucket: "my-bucket", Region: "us-west-2"},
 			wantErr: false,
 		},
+		{
+			name:    "sse-kms with key and storage class",
+			cfg:     S3Config{Bucket: "my-bucket", SSE: "aws:kms", KMSKeyID: "arn:aws:kms:us-east-1:111122223333:key/abc", StorageClass: "GLACIER_IR"},
+			wantErr: false,
+		},
+		{
+			name:    "sse AES256 without key",
+			cfg:     S3Config{Bucket: "my-bucket", SSE: "AES256"},
+			wantErr: false,
+		},
+		{
+			name:    "kms key without sse fails",
+			cfg:     S3Config{Bucket: "my-bucket", KMSKeyID: "alias/quarry"},
+			wantErr: true,
+		},
+		{
+			name:    "kms key with AES256 fails",
+			cfg:     S3Config{Bucket: "my-bucket", SSE: "AES256", KMSKeyID: "alias/quarry"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown sse fails",
+			cfg:     S3Config{Bucket: "my-bucket", SSE: "kms"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown storage class fails",
+			cfg:     S3Config{Bucket: "my-bucket", StorageClass: "glacier-ir"},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -515,6 +561,224 @@ func TestS3Config_Validate(t *testing.T) {
 	}
 }
 
+func TestS3Config_EgressProxy(t *testing.T) {
+	proxyURL, _ := url.Parse("http://corp:3128")
+	s3cfg := S3Config{Bucket: "my-bucket", Region: "us-east-1", EgressProxy: proxyURL}
+
+	awsConfig, err := config.LoadDefaultConfig(t.Context(), s3cfg.loadOptions()...)
+	if err != nil {
+		t.Fatalf("LoadDefaultConfig: %v", err)
+	}
+	client, ok := awsConfig.HTTPClient.(*awshttp.BuildableClient)
+	if !ok {
+		t.Fatalf("HTTPClient = %T, want *awshttp.BuildableClient", awsConfig.HTTPClient)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://my-bucket.s3.amazonaws.com/", nil)
+	got, err := client.GetTransport().Proxy(req)
+	if err != nil || got.String() != "http://corp:3128" {
+		t.Errorf("proxy = %v, %v, want http://corp:3128", got, err)
+	}
+}
+
+func TestNewReadStoreS3_CustomEndpoint(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+
+	var gotPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_, _ = w.Write([]byte("sidecar"))
+	}))
+	defer srv.Close()
+
+	store, err := NewReadStoreS3(S3Config{
+		Bucket:       "my-bucket",
+		Region:       "us-east-1",
+		Endpoint:     srv.URL,
+		UsePathStyle: true,
+	})
+	if err != nil {
+		t.Fatalf("NewReadStoreS3: %v", err)
+	}
+	rc, err := store.Get(t.Context(), "files/a.txt")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	defer rc.Close()
+	data, _ := io.ReadAll(rc)
+
+	// Path-style against the custom endpoint, not my-bucket.s3.amazonaws.com
+	if gotPath != "/my-bucket/files/a.txt" || string(data) != "sidecar" {
+		t.Errorf("request path = %q, body = %q; want /my-bucket/files/a.txt, sidecar", gotPath, data)
+	}
+}
+
+// captureS3API records write inputs reaching the underlying S3 client.
+type captureS3API struct {
+	lodes3.API
+	put *s3.PutObjectInput
+	mpu *s3.CreateMultipartUploadInput
+}
+
+func (c *captureS3API) PutObject(_ context.Context, params *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	c.put = params
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (c *captureS3API) CreateMultipartUpload(_ context.Context, params *s3.CreateMultipartUploadInput, _ ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	c.mpu = params
+	return &s3.CreateMultipartUploadOutput{}, nil
+}
+
+func TestWriteOptionsAPI_AppliesToEveryWrite(t *testing.T) {
+	inner := &captureS3API{}
+	api := &writeOptionsAPI{API: inner, cfg: S3Config{
+		Bucket:       "my-bucket",
+		SSE:          "aws:kms",
+		KMSKeyID:     "alias/quarry",
+		StorageClass: "GLACIER_IR",
+	}}
+
+	key := "events.jsonl"
+	orig := &s3.PutObjectInput{Key: &key}
+	if _, err := api.PutObject(t.Context(), orig); err != nil {
+		t.Fatalf("PutObject: %v", err)
+	}
+	if _, err := api.CreateMultipartUpload(t.Context(), &s3.CreateMultipartUploadInput{Key: &key}); err != nil {
+		t.Fatalf("CreateMultipartUpload: %v", err)
+	}
+
+	if inner.put.ServerSideEncryption != s3types.ServerSideEncryptionAwsKms ||
+		inner.put.SSEKMSKeyId == nil || *inner.put.SSEKMSKeyId != "alias/quarry" ||
+		inner.put.StorageClass != s3types.StorageClassGlacierIr {
+		t.Errorf("PutObject options not applied: %+v", inner.put)
+	}
+	if *inner.put.Key != key {
+		t.Errorf("PutObject key = %q, want %q", *inner.put.Key, key)
+	}
+	if inner.mpu.ServerSideEncryption != s3types.ServerSideEncryptionAwsKms ||
+		inner.mpu.SSEKMSKeyId == nil || inner.mpu.StorageClass != s3types.StorageClassGlacierIr {
+		t.Errorf("CreateMultipartUpload options not applied: %+v", inner.mpu)
+	}
+	if orig.ServerSideEncryption != "" {
+		t.Error("caller's PutObjectInput must not be mutated")
+	}
+}
+
+// headS3API extends the Lode mock client with HEAD results carrying size,
+// MD5 ETag, and user metadata.
+type headS3API struct {
+	*lodes3.MockS3Client
+	objects map[string]*s3.HeadObjectOutput
+	puts    int
+}
+
+func (h *headS3API) PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	data, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	in := *params
+	in.Body = bytes.NewReader(data)
+	if _, err := h.MockS3Client.PutObject(ctx, &in, optFns...); err != nil {
+		return nil, err
+	}
+	h.puts++
+	sum := md5.Sum(data)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	h.objects[aws.ToString(params.Key)] = &s3.HeadObjectOutput{ContentLength: aws.Int64(int64(len(data))), ETag: &etag, Metadata: params.Metadata}
+	return &s3.PutObjectOutput{ETag: &etag}, nil
+}
+
+func (h *headS3API) HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	if head, ok := h.objects[aws.ToString(params.Key)]; ok {
+		return head, nil
+	}
+	return h.MockS3Client.HeadObject(ctx, params, optFns...)
+}
+
+func TestSkipExistingAPI(t *testing.T) {
+	inner := &headS3API{MockS3Client: lodes3.NewMockS3Client(), objects: make(map[string]*s3.HeadObjectOutput)}
+	skipped := 0
+	store, err := lodes3.New(&skipExistingAPI{API: inner, onSkip: func() { skipped++ }}, lodes3.Config{Bucket: "my-bucket"})
+	if err != nil {
+		t.Fatalf("lodes3.New: %v", err)
+	}
+	ctx := t.Context()
+
+	if err := store.Put(ctx, "files/report.csv", strings.NewReader("a,b\n")); err != nil {
+		t.Fatalf("first Put: %v", err)
+	}
+	if got := inner.objects["files/report.csv"].Metadata[skipContentMD5Key]; got == "" {
+		t.Error("written object must carry its content MD5 as metadata")
+	}
+	// A repeated write of the same object is skipped
+	if err := store.Put(ctx, "files/report.csv", strings.NewReader("a,b\n")); err != nil {
+		t.Fatalf("repeated Put: %v", err)
+	}
+	if inner.puts != 1 || skipped != 1 {
+		t.Errorf("puts = %d, skipped = %d; want 1, 1", inner.puts, skipped)
+	}
+	// Different content is not skipped and keeps no-overwrite semantics
+	if err := store.Put(ctx, "files/report.csv", strings.NewReader("a,c\n")); !errors.Is(err, lode.ErrPathExists) {
+		t.Errorf("Put of changed content: err = %v, want ErrPathExists", err)
+	}
+	if skipped != 1 {
+		t.Errorf("skipped = %d after a changed write, want 1", skipped)
+	}
+}
+
+func TestNewS3StoreFactories_SkipExistingOnlyForSidecars(t *testing.T) {
+	inner := &headS3API{MockS3Client: lodes3.NewMockS3Client(), objects: make(map[string]*s3.HeadObjectOutput)}
+	datasetFactory, fileFactory := newS3StoreFactories(inner, S3Config{Bucket: "my-bucket", SkipExisting: true})
+	ctx := t.Context()
+
+	datasetStore, err := datasetFactory()
+	if err != nil {
+		t.Fatalf("dataset store: %v", err)
+	}
+	if err := datasetStore.Put(ctx, "data/segment.jsonl", strings.NewReader("{}\n")); err != nil {
+		t.Fatalf("dataset Put: %v", err)
+	}
+	fileStore, err := fileFactory()
+	if err != nil {
+		t.Fatalf("file store: %v", err)
+	}
+	if err := fileStore.Put(ctx, "files/report.csv", strings.NewReader("a,b\n")); err != nil {
+		t.Fatalf("file Put: %v", err)
+	}
+
+	// Only objects written through the skip-existing wrapper carry the digest
+	if _, ok := inner.objects["data/segment.jsonl"].Metadata[skipContentMD5Key]; ok {
+		t.Error("dataset segment write must not go through skip-existing")
+	}
+	if _, ok := inner.objects["files/report.csv"].Metadata[skipContentMD5Key]; !ok {
+		t.Error("sidecar write must go through skip-existing")
+	}
+}
+
+func TestSameObject(t *testing.T) {
+	const digest = "900150983cd24fb0d6963f7d28e17f72" // md5("abc")
+	etag := func(s string) *string { return &s }
+	tests := []struct {
+		name string
+		head *s3.HeadObjectOutput
+		want bool
+	}{
+		{"metadata digest", &s3.HeadObjectOutput{ContentLength: aws.Int64(3), Metadata: map[string]string{skipContentMD5Key: digest}}, true},
+		{"metadata digest differs", &s3.HeadObjectOutput{ContentLength: aws.Int64(3), ETag: etag(`"` + digest + `"`), Metadata: map[string]string{skipContentMD5Key: "0"}}, false},
+		{"size differs", &s3.HeadObjectOutput{ContentLength: aws.Int64(4), ETag: etag(`"` + digest + `"`)}, false},
+		{"plain etag", &s3.HeadObjectOutput{ContentLength: aws.Int64(3), ETag: etag(`"` + digest + `"`)}, true},
+		{"multipart etag", &s3.HeadObjectOutput{ContentLength: aws.Int64(3), ETag: etag(`"` + digest + `-2"`)}, false},
+		{"kms etag", &s3.HeadObjectOutput{ContentLength: aws.Int64(3), ETag: etag(`"` + digest + `"`), ServerSideEncryption: s3types.ServerSideEncryptionAwsKms}, false},
+	}
+	for _, tt := range tests {
+		if got := sameObject(tt.head, 3, digest); got != tt.want {
+			t.Errorf("%s: sameObject = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
 func TestParseS3Path(t *testing.T) {
 	tests := []struct {
 		path       string
@@ -640,6 +904,19 @@ func TestToMetricsRecordMap(t *testing.T) {
 		t.Error("job_id should be omitted when empty")
 	}
 
+	// Verify version tags: contract_version always, executor_version when reported
+	if record["contract_version"] != types.ContractVersion {
+		t.Errorf("contract_version = %v, want %q", record["contract_version"], types.ContractVersion)
+	}
+	if _, exists := record["executor_version"]; exists {
+		t.Error("executor_version should be omitted when not reported")
+	}
+	snapVersioned := snap
+	snapVersioned.ExecutorVersion = "0.0.1"
+	if v := toMetricsRecordMap(snapVersioned, cfg, completedAt)["executor_version"]; v != "0.0.1" {
+		t.Errorf("executor_version = %v, want %q", v, "0.0.1")
+	}
+
 	// Verify dropped_by_type is a deep copy
 	dropped, ok := record["dropped_by_type"].(map[string]int64)
 	if !ok {
@@ -673,7 +950,10 @@ func TestNewClient_InitializesMaps(t *testing.T) {
 		RunID:    "run-1",
 	}
 
-	client := newClient(ds, cfg, lode.NewMemoryFactory())
+	client, err := newClient(ds, cfg, lode.NewMemoryFactory())
+	if err != nil {
+		t.Fatalf("newClient failed: %v", err)
+	}
 
 	if client.offsets == nil {
 		t.Fatal("offsets map is nil, must be initialized")
@@ -735,3 +1015,51 @@ func TestToArtifactCommitRecord(t *testing.T) {
 		t.Errorf("SizeBytes = %d, want %d", record.SizeBytes, 2048)
 	}
 }
+
+func TestLodeClient_WriteEvents_FlushSeqMetadata(t *testing.T) {
+	store := lode.NewMemory()
+	factory := sharedFactory(store)
+	cfg := Config{
+		Dataset:  "quarry",
+		Source:   "test-source",
+		Category: "test-category",
+		Day:      "2026-02-03",
+		RunID:    "run-001",
+		Policy:   "streaming",
+	}
+	client, err := NewLodeClientWithFactory(cfg, factory)
+	if err != nil {
+		t.Fatalf("NewLodeClientWithFactory failed: %v", err)
+	}
+
+	events := []*types.EventEnvelope{
+		{Type: types.EventTypeItem, Seq: 1, Payload: map[string]any{"n": 1}},
+	}
+	// Untagged write: no flush_seq
+	if err := client.WriteEvents(t.Context(), cfg.Dataset, cfg.RunID, events); err != nil {
+		t.Fatalf("WriteEvents failed: %v", err)
+	}
+	ctx := policy.WithFlushInfo(t.Context(), policy.FlushInfo{Seq: 7, Trigger: policy.FlushTriggerCount})
+	if err := client.WriteEvents(ctx, cfg.Dataset, cfg.RunID, events); err != nil {
+		t.Fatalf("WriteEvents failed: %v", err)
+	}
+
+	ds, err := NewReadDataset("quarry", factory)
+	if err != nil {
+		t.Fatalf("NewReadDataset failed: %v", err)
+	}
+	snapshots, err := ds.Snapshots(t.Context())
+	if err != nil {
+		t.Fatalf("Snapshots failed: %v", err)
+	}
+	var seqs []any
+	for _, snap := range snapshots {
+		if raw, ok := snap.Manifest.Metadata[MetadataKeyFlushSeq]; ok {
+			seqs = append(seqs, raw)
+		}
+	}
+	// Metadata values are deserialized from JSON
+	if len(seqs) != 1 || seqs[0] != float64(7) {
+		t.Errorf("flush_seq values = %v, want [7]", seqs)
+	}
+}
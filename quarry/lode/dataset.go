@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
@@ -13,15 +14,57 @@ import (
 
 // NewReadDataset creates a Lode Dataset for reading.
 // Uses the same codec and layout as the write path to ensure compatibility.
+//
+// The returned Dataset is compression-aware: Read discovers each snapshot's
+// compressor (manifest compressor field, falling back to the object key
+// suffix) and decompresses transparently, so read tooling works regardless
+// of the compression a run was written with. It is codec-aware the same
+// way: Parquet item snapshots (--storage-format parquet) decode to the
+// same record shape as JSON Lines, with the payload column parsed back
+// into a map.
 func NewReadDataset(dataset string, factory lode.StoreFactory) (lode.Dataset, error) {
+	noop := lode.NewNoOpCompressor()
+	base, err := newReadDatasetFor(dataset, factory, string(FormatJSONL), noop)
+	if err != nil {
+		return nil, err
+	}
+	return &compressionAwareDataset{
+		Dataset: base,
+		byName:  map[string]lode.Dataset{datasetKey(string(FormatJSONL), noop): base},
+		open: func(codec string, c lode.Compressor) (lode.Dataset, error) {
+			return newReadDatasetFor(dataset, factory, codec, c)
+		},
+	}, nil
+}
+
+// newReadDatasetFor creates a read Dataset bound to one codec and compressor.
+func newReadDatasetFor(dataset string, factory lode.StoreFactory, codec string, c lode.Compressor) (lode.Dataset, error) {
+	var dc lode.Codec
+	switch StorageFormat(codec) {
+	case FormatJSONL:
+		dc = lode.NewJSONLCodec()
+	case FormatParquet:
+		var err error
+		if dc, err = newItemParquetCodec(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported codec %q", codec)
+	}
 	return lode.NewDataset(
 		lode.DatasetID(dataset),
 		factory,
 		lode.WithHiveLayout("source", "category", "day", "run_id", "event_type"),
-		lode.WithCodec(lode.NewJSONLCodec()),
+		lode.WithCodec(dc),
+		lode.WithCompressor(c),
 	)
 }
 
+// datasetKey identifies a read Dataset by codec and compressor.
+func datasetKey(codec string, c lode.Compressor) string {
+	return codec + "/" + c.Name()
+}
+
 // NewReadDatasetFS creates a read Dataset with filesystem storage.
 func NewReadDatasetFS(dataset, rootPath string) (lode.Dataset, error) {
 	return NewReadDataset(dataset, lode.NewFSFactory(rootPath))
@@ -30,31 +73,145 @@ func NewReadDatasetFS(dataset, rootPath string) (lode.Dataset, error) {
 // NewReadDatasetS3 creates a read Dataset with S3 storage.
 // Uses AWS SDK default credential chain (env vars, shared config, IAM role).
 func NewReadDatasetS3(dataset string, s3cfg S3Config) (lode.Dataset, error) {
-	if err := s3cfg.Validate(); err != nil {
+	s3Factory, err := newReadS3Factory(s3cfg)
+	if err != nil {
 		return nil, err
 	}
+	return NewReadDataset(dataset, s3Factory)
+}
 
-	ctx := context.Background()
-	var opts []func(*config.LoadOptions) error
-	if s3cfg.Region != "" {
-		opts = append(opts, config.WithRegion(s3cfg.Region))
+// NewReadStoreS3 creates a raw S3 Store for reading objects outside the
+// Dataset layout (sidecar files, partition manifests).
+func NewReadStoreS3(s3cfg S3Config) (lode.Store, error) {
+	s3Factory, err := newReadS3Factory(s3cfg)
+	if err != nil {
+		return nil, err
+	}
+	return s3Factory()
+}
+
+// newReadS3Factory creates an S3 store factory using the AWS SDK default
+// credential chain, with the same endpoint overrides as the write client.
+func newReadS3Factory(s3cfg S3Config) (lode.StoreFactory, error) {
+	if err := s3cfg.Validate(); err != nil {
+		return nil, err
 	}
 
-	awsConfig, err := config.LoadDefaultConfig(ctx, opts...)
+	ctx := context.Background()
+	awsConfig, err := config.LoadDefaultConfig(ctx, s3cfg.loadOptions()...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
-	s3Client := s3.NewFromConfig(awsConfig)
+	s3Client := s3.NewFromConfig(awsConfig, s3cfg.clientOptions()...)
 
-	s3Factory := func() (lode.Store, error) {
+	return func() (lode.Store, error) {
 		return lodes3.New(s3Client, lodes3.Config{
 			Bucket: s3cfg.Bucket,
 			Prefix: s3cfg.Prefix,
 		})
+	}, nil
+}
+
+// compressionAwareDataset wraps a read Dataset and routes Read to a Dataset
+// configured with the codec and compressor the snapshot was written with.
+// Listing (Snapshots, Snapshot, Latest) is codec- and compressor-independent
+// and delegates to the embedded base Dataset.
+type compressionAwareDataset struct {
+	lode.Dataset
+
+	mu     sync.Mutex
+	byName map[string]lode.Dataset
+	open   func(codec string, c lode.Compressor) (lode.Dataset, error)
+}
+
+// Read retrieves all data units from a snapshot, decompressing and
+// decoding as needed.
+func (d *compressionAwareDataset) Read(ctx context.Context, id lode.DatasetSnapshotID) ([]any, error) {
+	snap, err := d.Snapshot(ctx, id)
+	if err != nil {
+		return nil, err
 	}
 
-	return NewReadDataset(dataset, s3Factory)
+	c, err := SnapshotCompressor(snap)
+	if err != nil {
+		return nil, err
+	}
+
+	codec := string(FormatJSONL)
+	if snap.Manifest != nil && snap.Manifest.Codec != "" {
+		codec = snap.Manifest.Codec
+	}
+
+	ds, err := d.datasetFor(codec, c)
+	if err != nil {
+		return nil, err
+	}
+	records, err := ds.Read(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if codec == string(FormatParquet) {
+		if err := decodeParquetPayloads(records); err != nil {
+			return nil, fmt.Errorf("snapshot %s: %w", id, err)
+		}
+	}
+	return records, nil
+}
+
+// datasetFor returns (creating on first use) the Dataset for a codec and
+// compressor.
+func (d *compressionAwareDataset) datasetFor(codec string, c lode.Compressor) (lode.Dataset, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := datasetKey(codec, c)
+	if ds, ok := d.byName[key]; ok {
+		return ds, nil
+	}
+	ds, err := d.open(codec, c)
+	if err != nil {
+		return nil, fmt.Errorf("open %s read dataset: %w", key, err)
+	}
+	d.byName[key] = ds
+	return ds, nil
+}
+
+// SnapshotCompressor discovers the compressor a snapshot was written with.
+// The manifest compressor field is authoritative; when it is empty (older
+// manifests), the data file key suffix is used (.gz, .zst, or none).
+// Returns an error for an unrecognized compressor.
+func SnapshotCompressor(snap *lode.DatasetSnapshot) (lode.Compressor, error) {
+	name := ""
+	if snap.Manifest != nil {
+		name = snap.Manifest.Compressor
+		if name == "" && len(snap.Manifest.Files) > 0 {
+			name = compressorNameFromPath(snap.Manifest.Files[0].Path)
+		}
+	}
+
+	switch name {
+	case "", "noop":
+		return lode.NewNoOpCompressor(), nil
+	case "gzip":
+		return lode.NewGzipCompressor(), nil
+	case "zstd":
+		return lode.NewZstdCompressor(), nil
+	default:
+		return nil, fmt.Errorf("snapshot %s: unsupported compressor %q", snap.ID, name)
+	}
+}
+
+// compressorNameFromPath maps an object key suffix to a compressor name.
+func compressorNameFromPath(path string) string {
+	switch {
+	case strings.HasSuffix(path, ".gz"):
+		return "gzip"
+	case strings.HasSuffix(path, ".zst"):
+		return "zstd"
+	default:
+		return "noop"
+	}
 }
 
 // isMetricsSnapshot checks if a snapshot contains metrics data
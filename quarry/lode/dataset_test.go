@@ -7,6 +7,7 @@ import (
 	"github.com/pithecene-io/lode/lode"
 
 	"github.com/pithecene-io/quarry/metrics"
+	"github.com/pithecene-io/quarry/types"
 )
 
 func TestNewReadDatasetFS(t *testing.T) {
@@ -82,3 +83,176 @@ func TestNewReadDataset_WriteReadRoundTrip(t *testing.T) {
 		t.Errorf("record_kind = %v, want %q", record["record_kind"], RecordKindMetrics)
 	}
 }
+
+func TestNewReadDataset_ParquetItems(t *testing.T) {
+	factory := sharedFactory(lode.NewMemory())
+	cfg := Config{
+		Dataset:  "quarry",
+		Source:   "pq-source",
+		Category: "pq-category",
+		Day:      "2026-02-04",
+		RunID:    "run-pq",
+		Policy:   "strict",
+		Format:   FormatParquet,
+	}
+	client, err := NewLodeClientWithFactory(cfg, factory)
+	if err != nil {
+		t.Fatalf("NewLodeClientWithFactory failed: %v", err)
+	}
+
+	events := []*types.EventEnvelope{
+		{
+			ContractVersion: "1.0.0", EventID: "evt-1", RunID: "run-pq", Seq: 1,
+			Type: types.EventTypeItem, Ts: "2026-02-04T12:00:00Z", Attempt: 1,
+			Payload: map[string]any{"item_type": "product", "data": map[string]any{"price": 9.5}},
+		},
+		{
+			ContractVersion: "1.0.0", EventID: "evt-2", RunID: "run-pq", Seq: 2,
+			Type: types.EventTypeLog, Ts: "2026-02-04T12:00:01Z", Attempt: 1,
+			Payload: map[string]any{"message": "hello"},
+		},
+	}
+	if err := client.WriteEvents(t.Context(), cfg.Dataset, cfg.RunID, events); err != nil {
+		t.Fatalf("WriteEvents failed: %v", err)
+	}
+
+	ds, err := NewReadDataset("quarry", factory)
+	if err != nil {
+		t.Fatalf("NewReadDataset failed: %v", err)
+	}
+	snaps, err := ds.Snapshots(t.Context())
+	if err != nil {
+		t.Fatalf("Snapshots failed: %v", err)
+	}
+	if len(snaps) != 2 {
+		t.Fatalf("got %d snapshots, want 2 (parquet items + jsonl rest)", len(snaps))
+	}
+
+	codecs := make(map[string]map[string]any)
+	for _, snap := range snaps {
+		data, err := ds.Read(t.Context(), snap.ID)
+		if err != nil {
+			t.Fatalf("Read %s failed: %v", snap.Manifest.Codec, err)
+		}
+		if len(data) != 1 {
+			t.Fatalf("%s snapshot has %d records, want 1", snap.Manifest.Codec, len(data))
+		}
+		record, ok := data[0].(map[string]any)
+		if !ok {
+			t.Fatalf("record type = %T, want map[string]any", data[0])
+		}
+		codecs[snap.Manifest.Codec] = record
+	}
+
+	item := codecs["parquet"]
+	if item == nil || item["event_type"] != "item" {
+		t.Fatalf("parquet record = %#v, want the item event", item)
+	}
+	if item["seq"] != int64(1) || item["run_id"] != "run-pq" {
+		t.Errorf("envelope columns = seq %#v run_id %#v", item["seq"], item["run_id"])
+	}
+	payload, ok := item["payload"].(map[string]any)
+	if !ok || payload["item_type"] != "product" {
+		t.Errorf("payload = %#v, want decoded JSON object", item["payload"])
+	}
+	if log := codecs["jsonl"]; log == nil || log["event_type"] != "log" {
+		t.Errorf("jsonl record = %#v, want the log event", log)
+	}
+}
+
+func TestParseStorageFormat(t *testing.T) {
+	for in, want := range map[string]StorageFormat{"": FormatJSONL, "jsonl": FormatJSONL, "parquet": FormatParquet} {
+		got, err := ParseStorageFormat(in)
+		if err != nil || got != want {
+			t.Errorf("ParseStorageFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseStorageFormat("csv"); err == nil {
+		t.Error("ParseStorageFormat(\"csv\") succeeded, want error")
+	}
+}
+
+func TestNewReadDataset_CompressedSnapshots(t *testing.T) {
+	tests := []struct {
+		name       string
+		compressor lode.Compressor
+	}{
+		{"gzip", lode.NewGzipCompressor()},
+		{"zstd", lode.NewZstdCompressor()},
+		{"noop", lode.NewNoOpCompressor()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := lode.NewMemory()
+			factory := sharedFactory(store)
+
+			// Write directly with a compressed dataset (simulates a compressed run)
+			writer, err := lode.NewDataset(
+				"quarry",
+				factory,
+				lode.WithHiveLayout("source", "category", "day", "run_id", "event_type"),
+				lode.WithCodec(lode.NewJSONLCodec()),
+				lode.WithCompressor(tt.compressor),
+			)
+			if err != nil {
+				t.Fatalf("NewDataset failed: %v", err)
+			}
+			record := map[string]any{
+				"source": "s", "category": "c", "day": "2026-02-04",
+				"run_id": "run-gz", "event_type": "item", "n": "1",
+			}
+			snap, err := writer.Write(t.Context(), []any{record}, lode.Metadata{})
+			if err != nil {
+				t.Fatalf("Write failed: %v", err)
+			}
+
+			ds, err := NewReadDataset("quarry", factory)
+			if err != nil {
+				t.Fatalf("NewReadDataset failed: %v", err)
+			}
+
+			data, err := ds.Read(t.Context(), snap.ID)
+			if err != nil {
+				t.Fatalf("Read failed: %v", err)
+			}
+			if len(data) != 1 {
+				t.Fatalf("expected 1 record, got %d", len(data))
+			}
+			got, ok := data[0].(map[string]any)
+			if !ok || got["n"] != "1" {
+				t.Errorf("unexpected record: %#v", data[0])
+			}
+		})
+	}
+}
+
+func TestSnapshotCompressor(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest *lode.Manifest
+		want     string
+		wantErr  bool
+	}{
+		{"manifest gzip", &lode.Manifest{Compressor: "gzip"}, "gzip", false},
+		{"manifest zstd", &lode.Manifest{Compressor: "zstd"}, "zstd", false},
+		{"manifest noop", &lode.Manifest{Compressor: "noop"}, "noop", false},
+		{"suffix .gz", &lode.Manifest{Files: []lode.FileRef{{Path: "a/data.jsonl.gz"}}}, "gzip", false},
+		{"suffix .zst", &lode.Manifest{Files: []lode.FileRef{{Path: "a/data.jsonl.zst"}}}, "zstd", false},
+		{"no suffix", &lode.Manifest{Files: []lode.FileRef{{Path: "a/data.jsonl"}}}, "noop", false},
+		{"nil manifest", nil, "noop", false},
+		{"unknown", &lode.Manifest{Compressor: "lz4"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := SnapshotCompressor(&lode.DatasetSnapshot{ID: "snap-1", Manifest: tt.manifest})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && c.Name() != tt.want {
+				t.Errorf("compressor = %q, want %q", c.Name(), tt.want)
+			}
+		})
+	}
+}
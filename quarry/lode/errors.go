@@ -42,6 +42,21 @@ var (
 	ErrPathExists = errors.New("path already exists")
 )
 
+// IsRetryable reports whether a storage error is transient: a timeout,
+// throttling, or network failure. Unclassified errors are classified by
+// message first.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var se *StorageError
+	kind := err
+	if !errors.As(err, &se) {
+		kind = classifyError(err)
+	}
+	return errors.Is(kind, ErrTimeout) || errors.Is(kind, ErrThrottled) || errors.Is(kind, ErrNetwork)
+}
+
 // StorageError wraps an underlying error with storage classification.
 // It preserves the original error in the chain for inspection via errors.As.
 type StorageError struct {
@@ -204,3 +204,24 @@ func TestClassifyError_Nil(t *testing.T) {
 		t.Errorf("classifyError(nil) = %v, want nil", got)
 	}
 }
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"wrapped throttling", WrapWriteError(errors.New("SlowDown: reduce your request rate"), "p"), true},
+		{"wrapped timeout", WrapWriteError(errors.New("context deadline exceeded"), "p"), true},
+		{"raw network error", errors.New("dial tcp 10.0.0.1:443: connect: connection refused"), true},
+		{"wrapped access denied", WrapWriteError(errors.New("AccessDenied"), "p"), false},
+		{"disk full", WrapWriteError(errors.New("no space left on device"), "p"), false},
+		{"unclassified", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: IsRetryable = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -66,6 +66,8 @@ func (c *LodeClient) PutFile(ctx context.Context, filename, contentType string,
 		ContentType: contentType,
 		Size:        int64(len(data)),
 	})
+	c.recordObject(path, data)
+	c.recordObject(metaPath, meta)
 	c.mu.Unlock()
 
 	return nil
@@ -85,14 +87,12 @@ func (c *LodeClient) getOrCreateStore() (lode.Store, error) {
 }
 
 // buildFilePath computes the Hive-partitioned path for a sidecar file.
-// Format: datasets/<dataset>/partitions/source=<s>/category=<c>/day=<d>/run_id=<r>/files/<filename>
+// Format: datasets/<dataset>/partitions/<partition template>/files/<filename>
+// (default template: source=<s>/category=<c>/day=<d>/run_id=<r>).
 func (c *LodeClient) buildFilePath(filename string) string {
-	return fmt.Sprintf("datasets/%s/partitions/source=%s/category=%s/day=%s/run_id=%s/files/%s",
+	return fmt.Sprintf("datasets/%s/partitions/%s/files/%s",
 		c.config.Dataset,
-		c.config.Source,
-		c.config.Category,
-		c.config.Day,
-		c.config.RunID,
+		c.partitionPath,
 		filename,
 	)
 }
@@ -0,0 +1,102 @@
+package lode
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pithecene-io/lode/lode"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+// StorageFormat selects how item event records are encoded.
+type StorageFormat string
+
+const (
+	// FormatJSONL writes every record as JSON Lines (default).
+	FormatJSONL StorageFormat = "jsonl"
+	// FormatParquet writes item event records as Parquet, one row group per
+	// write. All other records (non-item events, artifact chunks, metrics)
+	// stay JSON Lines in their usual partitions.
+	FormatParquet StorageFormat = "parquet"
+)
+
+// ParseStorageFormat validates a --storage-format value. Empty selects
+// FormatJSONL.
+func ParseStorageFormat(s string) (StorageFormat, error) {
+	switch StorageFormat(s) {
+	case "", FormatJSONL:
+		return FormatJSONL, nil
+	case FormatParquet:
+		return FormatParquet, nil
+	default:
+		return "", fmt.Errorf("invalid storage format %q (supported: jsonl, parquet)", s)
+	}
+}
+
+// itemParquetSchema is the fixed Parquet schema for item event records: the
+// flattened envelope columns plus the payload as a JSON string column.
+// Template-only partition keys (e.g. tenant) are not columns; their values
+// live in the Hive path. The schema must stay fixed so any reader can decode
+// any run's files.
+var itemParquetSchema = lode.ParquetSchema{Fields: []lode.ParquetField{
+	{Name: "record_kind", Type: lode.ParquetString},
+	{Name: "contract_version", Type: lode.ParquetString},
+	{Name: "event_id", Type: lode.ParquetString},
+	{Name: "run_id", Type: lode.ParquetString},
+	{Name: "seq", Type: lode.ParquetInt64},
+	{Name: "type", Type: lode.ParquetString},
+	{Name: "event_type", Type: lode.ParquetString},
+	{Name: "ts", Type: lode.ParquetString},
+	{Name: "payload", Type: lode.ParquetString},
+	{Name: "attempt", Type: lode.ParquetInt64},
+	{Name: "source", Type: lode.ParquetString},
+	{Name: "category", Type: lode.ParquetString},
+	{Name: "day", Type: lode.ParquetString},
+	{Name: "policy", Type: lode.ParquetString},
+	{Name: "job_id", Type: lode.ParquetString, Nullable: true},
+	{Name: "parent_run_id", Type: lode.ParquetString, Nullable: true},
+}}
+
+// newItemParquetCodec creates the Parquet codec for item event records.
+func newItemParquetCodec() (lode.Codec, error) {
+	return lode.NewParquetCodec(itemParquetSchema)
+}
+
+// isParquetItem reports whether an event is written to the Parquet item
+// dataset under FormatParquet.
+func isParquetItem(e *types.EventEnvelope) bool {
+	return e.Type == types.EventTypeItem
+}
+
+// encodeParquetPayload replaces an event record's payload map with its JSON
+// encoding, the representation of the Parquet payload column.
+func encodeParquetPayload(record map[string]any) error {
+	payload, err := json.Marshal(record["payload"])
+	if err != nil {
+		return fmt.Errorf("encoding item payload as JSON: %w", err)
+	}
+	record["payload"] = string(payload)
+	return nil
+}
+
+// decodeParquetPayloads reverses encodeParquetPayload on records read from
+// a Parquet snapshot, so readers see the same shape as JSON Lines records.
+func decodeParquetPayloads(records []any) error {
+	for _, r := range records {
+		record, ok := r.(map[string]any)
+		if !ok {
+			continue
+		}
+		raw, ok := record["payload"].(string)
+		if !ok {
+			continue
+		}
+		var payload map[string]any
+		if err := json.Unmarshal([]byte(raw), &payload); err != nil {
+			return fmt.Errorf("decoding item payload JSON: %w", err)
+		}
+		record["payload"] = payload
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package lode
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pithecene-io/lode/lode"
+)
+
+// NewLodeMemoryClient creates a Lode client backed by a process-local
+// in-memory store. Writes go through the same encoding and invariant checks
+// as the fs and s3 backends, but everything is discarded when the process
+// exits. Intended for script smoke tests and ephemeral runs.
+func NewLodeMemoryClient(cfg Config) (*LodeClient, error) {
+	// One store shared by the dataset and the sidecar FileWriter;
+	// lode.NewMemoryFactory would hand each caller a separate store.
+	store := lode.NewMemory()
+	return NewLodeClientWithFactory(cfg, func() (lode.Store, error) { return store, nil })
+}
+
+// PartitionSummary counts the objects a client's run partition holds.
+type PartitionSummary struct {
+	Objects int
+	Bytes   int64
+	// Kinds groups objects by the first path segment below the partition:
+	// event_type=<type> for dataset data files, files for sidecar files.
+	Kinds []PartitionKindSummary
+}
+
+// PartitionKindSummary is one group in a PartitionSummary.
+type PartitionKindSummary struct {
+	Kind    string
+	Objects int
+	Bytes   int64
+}
+
+// SummarizePartition lists the run partition and totals object counts and
+// sizes. It reads every object to size it, so it is meant for the memory
+// backend's end-of-run summary, not for large remote partitions.
+func (c *LodeClient) SummarizePartition(ctx context.Context) (*PartitionSummary, error) {
+	store, err := c.getOrCreateStore()
+	if err != nil {
+		return nil, fmt.Errorf("partition summary store init failed: %w", err)
+	}
+	prefix := fmt.Sprintf("datasets/%s/partitions/%s/", c.config.Dataset, c.partitionPath)
+	paths, err := store.List(ctx, prefix)
+	if err != nil {
+		return nil, WrapReadError(err, prefix)
+	}
+
+	summary := &PartitionSummary{}
+	kinds := make(map[string]*PartitionKindSummary)
+	for _, p := range paths {
+		data, err := readAll(ctx, store, p)
+		if err != nil {
+			return nil, err
+		}
+		kind, _, _ := strings.Cut(strings.TrimPrefix(p, prefix), "/")
+		k, ok := kinds[kind]
+		if !ok {
+			k = &PartitionKindSummary{Kind: kind}
+			kinds[kind] = k
+		}
+		k.Objects++
+		k.Bytes += int64(len(data))
+		summary.Objects++
+		summary.Bytes += int64(len(data))
+	}
+	for _, k := range kinds {
+		summary.Kinds = append(summary.Kinds, *k)
+	}
+	sort.Slice(summary.Kinds, func(i, j int) bool { return summary.Kinds[i].Kind < summary.Kinds[j].Kind })
+	return summary, nil
+}
@@ -0,0 +1,64 @@
+package lode
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pithecene-io/quarry/metrics"
+	"github.com/pithecene-io/quarry/types"
+)
+
+func TestLodeMemoryClient_SummarizePartition(t *testing.T) {
+	cfg := Config{
+		Dataset:  "quarry",
+		Source:   "src",
+		Category: "cat",
+		Day:      "2026-02-03",
+		RunID:    "run-mem",
+		Policy:   "strict",
+	}
+	client, err := NewLodeMemoryClient(cfg)
+	if err != nil {
+		t.Fatalf("NewLodeMemoryClient: %v", err)
+	}
+
+	events := []*types.EventEnvelope{{
+		ContractVersion: "1.0.0", EventID: "evt-1", RunID: "run-mem", Seq: 1,
+		Type: types.EventTypeItem, Ts: "2026-02-03T12:00:00Z", Payload: map[string]any{"k": "v"}, Attempt: 1,
+	}}
+	if err := client.WriteEvents(t.Context(), cfg.Dataset, cfg.RunID, events); err != nil {
+		t.Fatalf("WriteEvents: %v", err)
+	}
+	// The sidecar file must land in the same store as the dataset writes.
+	if err := client.PutFile(t.Context(), "page.html", "text/html", []byte("<html></html>")); err != nil {
+		t.Fatalf("PutFile: %v", err)
+	}
+	if err := client.WriteMetrics(t.Context(), metrics.Snapshot{RunID: "run-mem", RunsCompleted: 1}, time.Now()); err != nil {
+		t.Fatalf("WriteMetrics: %v", err)
+	}
+
+	summary, err := client.SummarizePartition(t.Context())
+	if err != nil {
+		t.Fatalf("SummarizePartition: %v", err)
+	}
+
+	got := make(map[string]int)
+	var total int64
+	for _, k := range summary.Kinds {
+		got[k.Kind] = k.Objects
+		total += k.Bytes
+	}
+	// Each dataset write is a data object plus its segment manifest.
+	want := map[string]int{"event_type=item": 2, "event_type=metrics": 2, "files": 2}
+	for kind, n := range want {
+		if got[kind] != n {
+			t.Errorf("kind %s: %d objects, want %d (summary %+v)", kind, got[kind], n, summary.Kinds)
+		}
+	}
+	if summary.Objects != 6 {
+		t.Errorf("Objects = %d, want 6", summary.Objects)
+	}
+	if summary.Bytes != total || total == 0 {
+		t.Errorf("Bytes = %d, kinds total %d", summary.Bytes, total)
+	}
+}
@@ -0,0 +1,42 @@
+package lode
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+// PartitionGuard reports whether a run partition already holds a finished run.
+// Used to refuse accidental double-writes when a run_id is reused.
+type PartitionGuard interface {
+	// HasTerminalEvent reports whether the run partition contains a persisted
+	// terminal event (run_complete or run_error).
+	HasTerminalEvent(ctx context.Context) (bool, error)
+}
+
+// Verify LodeClient implements PartitionGuard.
+var _ PartitionGuard = (*LodeClient)(nil)
+
+// HasTerminalEvent lists the run's terminal event_type partitions.
+// Any stored object under event_type=run_complete or event_type=run_error
+// means a previous run with this run_id reached a terminal state.
+func (c *LodeClient) HasTerminalEvent(ctx context.Context) (bool, error) {
+	store, err := c.getOrCreateStore()
+	if err != nil {
+		return false, fmt.Errorf("partition check store init failed: %w", err)
+	}
+
+	for _, eventType := range []types.EventType{types.EventTypeRunComplete, types.EventTypeRunError} {
+		prefix := fmt.Sprintf("datasets/%s/partitions/%s/event_type=%s/",
+			c.config.Dataset, c.partitionPath, eventType)
+		paths, err := store.List(ctx, prefix)
+		if err != nil {
+			return false, fmt.Errorf("partition check failed for %s: %w", prefix, err)
+		}
+		if len(paths) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
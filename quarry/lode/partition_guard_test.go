@@ -0,0 +1,84 @@
+package lode
+
+import (
+	"testing"
+
+	"github.com/pithecene-io/lode/lode"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+func TestLodeClient_HasTerminalEvent(t *testing.T) {
+	cfg := Config{
+		Dataset:  "quarry",
+		Source:   "src",
+		Category: "cat",
+		Day:      "2026-02-08",
+		RunID:    "run-1",
+		Policy:   "strict",
+	}
+	factory := lode.NewMemoryFactory()
+	store, err := factory()
+	if err != nil {
+		t.Fatalf("factory failed: %v", err)
+	}
+	shared := func() (lode.Store, error) { return store, nil }
+
+	client, err := NewLodeClientWithFactory(cfg, shared)
+	if err != nil {
+		t.Fatalf("NewLodeClientWithFactory failed: %v", err)
+	}
+	ctx := t.Context()
+
+	exists, err := client.HasTerminalEvent(ctx)
+	if err != nil {
+		t.Fatalf("HasTerminalEvent failed: %v", err)
+	}
+	if exists {
+		t.Fatal("empty partition should not report a terminal event")
+	}
+
+	// Non-terminal events alone do not mark the partition as finished
+	item := &types.EventEnvelope{
+		ContractVersion: "1.0.0", EventID: "evt-1", RunID: "run-1", Seq: 1,
+		Type: types.EventTypeItem, Ts: "2026-02-08T12:00:00Z", Attempt: 1,
+	}
+	if err := client.WriteEvents(ctx, cfg.Dataset, cfg.RunID, []*types.EventEnvelope{item}); err != nil {
+		t.Fatalf("WriteEvents failed: %v", err)
+	}
+	if exists, _ := client.HasTerminalEvent(ctx); exists {
+		t.Fatal("item-only partition should not report a terminal event")
+	}
+
+	complete := &types.EventEnvelope{
+		ContractVersion: "1.0.0", EventID: "evt-2", RunID: "run-1", Seq: 2,
+		Type: types.EventTypeRunComplete, Ts: "2026-02-08T12:00:01Z", Attempt: 1,
+	}
+	if err := client.WriteEvents(ctx, cfg.Dataset, cfg.RunID, []*types.EventEnvelope{complete}); err != nil {
+		t.Fatalf("WriteEvents failed: %v", err)
+	}
+
+	// A fresh client for the same run sees the previous run's terminal event
+	again, err := NewLodeClientWithFactory(cfg, shared)
+	if err != nil {
+		t.Fatalf("NewLodeClientWithFactory failed: %v", err)
+	}
+	exists, err = again.HasTerminalEvent(ctx)
+	if err != nil {
+		t.Fatalf("HasTerminalEvent failed: %v", err)
+	}
+	if !exists {
+		t.Fatal("expected terminal event after run_complete was written")
+	}
+
+	// A different run_id is unaffected
+	other := cfg
+	other.RunID = "run-2"
+	otherClient, err := NewLodeClientWithFactory(other, shared)
+	if err != nil {
+		t.Fatalf("NewLodeClientWithFactory failed: %v", err)
+	}
+	if exists, _ := otherClient.HasTerminalEvent(ctx); exists {
+		t.Fatal("different run_id should not report a terminal event")
+	}
+}
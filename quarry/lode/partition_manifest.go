@@ -0,0 +1,277 @@
+package lode
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pithecene-io/lode/lode"
+
+	"github.com/pithecene-io/quarry/iox"
+	"github.com/pithecene-io/quarry/types"
+)
+
+// PartitionManifestFilename is the integrity manifest written to the run
+// partition's files/ prefix when Config.PartitionManifest is set.
+const PartitionManifestFilename = "_manifest.json"
+
+// PartitionManifestVersion is the current _manifest.json schema version.
+const PartitionManifestVersion = 1
+
+// PartitionManifest lists every object a run wrote to its partition with
+// its size and sha256, plus the run outcome, so the partition can be
+// audited with VerifyPartition.
+type PartitionManifest struct {
+	Version     int              `json:"version"`
+	Dataset     string           `json:"dataset"`
+	RunID       string           `json:"run_id"`
+	Partition   string           `json:"partition"`
+	Outcome     string           `json:"outcome"`
+	Reason      string           `json:"reason,omitempty"`
+	CompletedAt string           `json:"completed_at"`
+	Objects     []ManifestObject `json:"objects"`
+	// Checksum is the sha256 of the manifest JSON with Checksum empty.
+	Checksum string `json:"checksum"`
+}
+
+// ManifestObject is one object in a PartitionManifest.
+type ManifestObject struct {
+	// Name is the object's store path.
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// PartitionManifestWriter is implemented by clients that keep a write log
+// for the partition manifest.
+type PartitionManifestWriter interface {
+	// WritePartitionManifest writes _manifest.json from the write log.
+	// A no-op unless Config.PartitionManifest is set.
+	WritePartitionManifest(ctx context.Context, outcome *types.RunOutcome, completedAt time.Time) error
+}
+
+// Verify LodeClient implements PartitionManifestWriter.
+var _ PartitionManifestWriter = (*LodeClient)(nil)
+
+// WritePartitionManifest implements PartitionManifestWriter. Call it after
+// the last write of the run (including metrics); objects written afterwards
+// are not listed.
+func (c *LodeClient) WritePartitionManifest(ctx context.Context, outcome *types.RunOutcome, completedAt time.Time) error {
+	if !c.config.PartitionManifest {
+		return nil
+	}
+	store, err := c.getOrCreateStore()
+	if err != nil {
+		return fmt.Errorf("partition manifest store init failed: %w", err)
+	}
+
+	c.mu.Lock()
+	objects := make([]ManifestObject, len(c.written))
+	copy(objects, c.written)
+	c.mu.Unlock()
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Name < objects[j].Name })
+
+	manifest := PartitionManifest{
+		Version:     PartitionManifestVersion,
+		Dataset:     c.config.Dataset,
+		RunID:       c.config.RunID,
+		Partition:   c.partitionPath,
+		CompletedAt: completedAt.UTC().Format(time.RFC3339Nano),
+		Objects:     objects,
+	}
+	if outcome != nil {
+		manifest.Outcome = string(outcome.Status)
+		manifest.Reason = string(outcome.Reason)
+	}
+	data, err := manifest.seal()
+	if err != nil {
+		return err
+	}
+
+	path := c.buildFilePath(PartitionManifestFilename)
+	if err := store.Put(ctx, path, bytes.NewReader(data)); err != nil {
+		return WrapWriteError(err, path)
+	}
+	return nil
+}
+
+// recordObject adds an object to the partition manifest write log.
+// Must be called under c.mu.
+func (c *LodeClient) recordObject(path string, data []byte) {
+	if !c.config.PartitionManifest {
+		return
+	}
+	sum := sha256.Sum256(data)
+	c.written = append(c.written, ManifestObject{Name: path, Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])})
+}
+
+// recordDataFiles adds dataset data files to the write log. Their sha256 is
+// computed by Lode while streaming (see sha256Checksum).
+// Must be called under c.mu.
+func (c *LodeClient) recordDataFiles(files []lode.FileRef) {
+	if !c.config.PartitionManifest {
+		return
+	}
+	for _, f := range files {
+		c.written = append(c.written, ManifestObject{Name: f.Path, Size: f.SizeBytes, SHA256: f.Checksum})
+	}
+}
+
+// seal sets Checksum and returns the manifest JSON.
+func (m *PartitionManifest) seal() ([]byte, error) {
+	sum, err := m.computeChecksum()
+	if err != nil {
+		return nil, err
+	}
+	m.Checksum = sum
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("partition manifest marshal failed: %w", err)
+	}
+	return data, nil
+}
+
+// computeChecksum returns the sha256 of the manifest with Checksum empty.
+func (m PartitionManifest) computeChecksum() (string, error) {
+	m.Checksum = ""
+	data, err := json.Marshal(m)
+	if err != nil {
+		return "", fmt.Errorf("partition manifest marshal failed: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// sha256Checksum is the Lode checksum used for data files when the
+// partition manifest is enabled.
+type sha256Checksum struct{}
+
+func (sha256Checksum) Name() string { return "sha256" }
+
+func (sha256Checksum) NewHasher() lode.HashWriter { return &sha256Hasher{h: sha256.New()} }
+
+type sha256Hasher struct {
+	h hash.Hash
+}
+
+func (s *sha256Hasher) Write(p []byte) (int, error) { return s.h.Write(p) }
+
+func (s *sha256Hasher) Sum() string { return hex.EncodeToString(s.h.Sum(nil)) }
+
+// ErrPartitionManifestNotFound is returned by FindPartitionManifest when the
+// run has no _manifest.json.
+var ErrPartitionManifestNotFound = errors.New("partition manifest not found")
+
+// FindPartitionManifest returns the store path of the run's _manifest.json
+// under the dataset's partitions. It matches on the run_id= segment, so it
+// works for any partition template or tenant.
+func FindPartitionManifest(ctx context.Context, store lode.Store, dataset, runID string) (string, error) {
+	paths, err := store.List(ctx, fmt.Sprintf("datasets/%s/partitions/", dataset))
+	if err != nil {
+		return "", WrapReadError(err, dataset)
+	}
+	suffix := "/files/" + PartitionManifestFilename
+	segment := "/run_id=" + runID + "/"
+	var found []string
+	for _, p := range paths {
+		if strings.HasSuffix(p, suffix) && strings.Contains(p, segment) {
+			found = append(found, p)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("%w for run %s in dataset %s", ErrPartitionManifestNotFound, runID, dataset)
+	case 1:
+		return found[0], nil
+	default:
+		sort.Strings(found)
+		return "", fmt.Errorf("run %s has %d partition manifests: %s", runID, len(found), strings.Join(found, ", "))
+	}
+}
+
+// ObjectCheck is the verification result for one manifest object.
+type ObjectCheck struct {
+	ManifestObject
+	// Problem is empty when the object matches the manifest.
+	Problem string
+}
+
+// VerifyResult is the outcome of VerifyPartition.
+type VerifyResult struct {
+	Path     string
+	Manifest *PartitionManifest
+	Objects  []ObjectCheck
+}
+
+// OK reports whether every object matched.
+func (r *VerifyResult) OK() bool {
+	for _, o := range r.Objects {
+		if o.Problem != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifyPartition reads the manifest at path, checks its own checksum, then
+// re-reads every listed object and compares size and sha256. A tampered or
+// unreadable manifest is an error; object mismatches are reported in the
+// result.
+func VerifyPartition(ctx context.Context, store lode.Store, path string) (*VerifyResult, error) {
+	data, err := readAll(ctx, store, path)
+	if err != nil {
+		return nil, err
+	}
+	var manifest PartitionManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid partition manifest %s: %w", path, err)
+	}
+	want, err := manifest.computeChecksum()
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Checksum != want {
+		return nil, fmt.Errorf("partition manifest %s checksum mismatch: recorded %s, computed %s", path, manifest.Checksum, want)
+	}
+
+	result := &VerifyResult{Path: path, Manifest: &manifest}
+	for _, obj := range manifest.Objects {
+		check := ObjectCheck{ManifestObject: obj}
+		content, err := readAll(ctx, store, obj.Name)
+		switch {
+		case err != nil:
+			check.Problem = fmt.Sprintf("unreadable: %v", err)
+		case int64(len(content)) != obj.Size:
+			check.Problem = fmt.Sprintf("size %d, manifest says %d", len(content), obj.Size)
+		default:
+			sum := sha256.Sum256(content)
+			if got := hex.EncodeToString(sum[:]); got != obj.SHA256 {
+				check.Problem = fmt.Sprintf("sha256 %s, manifest says %s", got, obj.SHA256)
+			}
+		}
+		result.Objects = append(result.Objects, check)
+	}
+	return result, nil
+}
+
+func readAll(ctx context.Context, store lode.Store, path string) ([]byte, error) {
+	rc, err := store.Get(ctx, path)
+	if err != nil {
+		return nil, WrapReadError(err, path)
+	}
+	defer iox.DiscardClose(rc)
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, WrapReadError(err, path)
+	}
+	return data, nil
+}
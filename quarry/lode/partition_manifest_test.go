@@ -0,0 +1,167 @@
+package lode
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pithecene-io/lode/lode"
+
+	"github.com/pithecene-io/quarry/metrics"
+	"github.com/pithecene-io/quarry/types"
+)
+
+// writeManifestedRun writes events, a sidecar file, metrics, and the
+// partition manifest for run-123 into store.
+func writeManifestedRun(t *testing.T, store lode.Store) {
+	t.Helper()
+	cfg := Config{
+		Dataset:           "quarry",
+		Source:            "src",
+		Category:          "cat",
+		Day:               "2026-02-03",
+		RunID:             "run-123",
+		Policy:            "strict",
+		PartitionManifest: true,
+	}
+	client, err := NewLodeClientWithFactory(cfg, func() (lode.Store, error) { return store, nil })
+	if err != nil {
+		t.Fatalf("NewLodeClientWithFactory: %v", err)
+	}
+
+	events := []*types.EventEnvelope{{
+		ContractVersion: "1.0.0", EventID: "evt-1", RunID: "run-123", Seq: 1,
+		Type: types.EventTypeItem, Ts: "2026-02-03T12:00:00Z", Payload: map[string]any{"k": "v"}, Attempt: 1,
+	}}
+	if err := client.WriteEvents(t.Context(), cfg.Dataset, cfg.RunID, events); err != nil {
+		t.Fatalf("WriteEvents: %v", err)
+	}
+	if err := client.PutFile(t.Context(), "page.html", "text/html", []byte("<html></html>")); err != nil {
+		t.Fatalf("PutFile: %v", err)
+	}
+	if err := client.WriteMetrics(t.Context(), metrics.Snapshot{RunsCompleted: 1}, time.Now()); err != nil {
+		t.Fatalf("WriteMetrics: %v", err)
+	}
+	outcome := &types.RunOutcome{Status: types.OutcomeSuccess, Reason: types.ReasonCompleted}
+	if err := client.WritePartitionManifest(t.Context(), outcome, time.Now()); err != nil {
+		t.Fatalf("WritePartitionManifest: %v", err)
+	}
+}
+
+func TestPartitionManifest_WriteAndVerify(t *testing.T) {
+	store := lode.NewMemory()
+	writeManifestedRun(t, store)
+
+	path, err := FindPartitionManifest(t.Context(), store, "quarry", "run-123")
+	if err != nil {
+		t.Fatalf("FindPartitionManifest: %v", err)
+	}
+	want := "datasets/quarry/partitions/source=src/category=cat/day=2026-02-03/run_id=run-123/files/_manifest.json"
+	if path != want {
+		t.Errorf("manifest path = %q, want %q", path, want)
+	}
+
+	result, err := VerifyPartition(t.Context(), store, path)
+	if err != nil {
+		t.Fatalf("VerifyPartition: %v", err)
+	}
+	if !result.OK() {
+		t.Fatalf("verification failed: %+v", result.Objects)
+	}
+	if result.Manifest.Outcome != "success" || result.Manifest.RunID != "run-123" {
+		t.Errorf("manifest outcome/run = %s/%s", result.Manifest.Outcome, result.Manifest.RunID)
+	}
+
+	// events data file, metrics data file, page.html, page.html.meta.json
+	var names []string
+	for _, o := range result.Objects {
+		names = append(names, o.Name)
+		if len(o.SHA256) != 64 {
+			t.Errorf("object %s sha256 = %q, want 64 hex chars", o.Name, o.SHA256)
+		}
+	}
+	if len(names) != 4 {
+		t.Errorf("manifest objects = %v, want 4", names)
+	}
+}
+
+func TestPartitionManifest_DetectsTamperedObject(t *testing.T) {
+	store := lode.NewMemory()
+	writeManifestedRun(t, store)
+	path, err := FindPartitionManifest(t.Context(), store, "quarry", "run-123")
+	if err != nil {
+		t.Fatalf("FindPartitionManifest: %v", err)
+	}
+
+	target := strings.TrimSuffix(path, PartitionManifestFilename) + "page.html"
+	if err := store.Delete(t.Context(), target); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(t.Context(), target, bytes.NewReader([]byte("<html>altered</html>"))); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := VerifyPartition(t.Context(), store, path)
+	if err != nil {
+		t.Fatalf("VerifyPartition: %v", err)
+	}
+	if result.OK() {
+		t.Fatal("expected verification to fail for an altered object")
+	}
+	for _, o := range result.Objects {
+		if (o.Problem != "") != (o.Name == target) {
+			t.Errorf("object %s problem = %q", o.Name, o.Problem)
+		}
+	}
+}
+
+func TestPartitionManifest_DetectsTamperedManifest(t *testing.T) {
+	store := lode.NewMemory()
+	writeManifestedRun(t, store)
+	path, err := FindPartitionManifest(t.Context(), store, "quarry", "run-123")
+	if err != nil {
+		t.Fatalf("FindPartitionManifest: %v", err)
+	}
+
+	rc, err := store.Get(t.Context(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(rc)
+	_ = rc.Close()
+	var m PartitionManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	m.Outcome = "executor_crash"
+	altered, _ := json.Marshal(m)
+	if err := store.Delete(t.Context(), path); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Put(t.Context(), path, bytes.NewReader(altered)); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := VerifyPartition(t.Context(), store, path); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expected manifest checksum mismatch, got %v", err)
+	}
+}
+
+func TestPartitionManifest_DisabledWritesNothing(t *testing.T) {
+	store := lode.NewMemory()
+	cfg := Config{Dataset: "quarry", Source: "src", Category: "cat", Day: "2026-02-03", RunID: "run-1", Policy: "strict"}
+	client, err := NewLodeClientWithFactory(cfg, func() (lode.Store, error) { return store, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.WritePartitionManifest(t.Context(), nil, time.Now()); err != nil {
+		t.Fatalf("WritePartitionManifest: %v", err)
+	}
+	if _, err := FindPartitionManifest(t.Context(), store, "quarry", "run-1"); !errors.Is(err, ErrPartitionManifestNotFound) {
+		t.Errorf("expected ErrPartitionManifestNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+package lode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// DefaultPartitionTemplate is the partition layout used when no template is
+// configured. Matches the recommended ordering in CONTRACT_LODE.md.
+const DefaultPartitionTemplate = "source={{.Source}}/category={{.Category}}/day={{.Day}}/run_id={{.RunID}}"
+
+// ErrTemplateMissingRunID is returned when a partition template does not
+// reference {{.RunID}}. Without run_id, runs would share partitions.
+var ErrTemplateMissingRunID = errors.New("partition template must reference {{.RunID}}")
+
+// partitionKeyPattern restricts partition keys to lowercase identifiers.
+var partitionKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// nativePartitionKeys maps record columns that may double as partition keys
+// to their template field. Each must render its own field so the column and
+// the path never disagree.
+var nativePartitionKeys = map[string]string{
+	"source":   "Source",
+	"category": "Category",
+	"day":      "Day",
+	"run_id":   "RunID",
+}
+
+// reservedRecordFields are record columns that cannot be used as partition
+// keys. event_type is always appended as the final partition key.
+var reservedRecordFields = map[string]struct{}{
+	"event_type": {}, "record_kind": {}, "contract_version": {}, "event_id": {},
+	"seq": {}, "type": {}, "ts": {}, "payload": {}, "attempt": {}, "policy": {},
+	"job_id": {}, "parent_run_id": {}, "artifact_id": {}, "name": {},
+	"content_type": {}, "size_bytes": {}, "is_last": {}, "offset": {},
+	"length": {}, "data": {}, "checksum": {}, "checksum_algo": {},
+}
+
+// defaultPartitionTemplate is the parsed DefaultPartitionTemplate.
+var defaultPartitionTemplate = mustParsePartitionTemplate(DefaultPartitionTemplate)
+
+// mustParsePartitionTemplate parses a known-good template or panics.
+func mustParsePartitionTemplate(s string) *PartitionTemplate {
+	pt, err := ParsePartitionTemplate(s)
+	if err != nil {
+		panic(err)
+	}
+	return pt
+}
+
+// PartitionValues are the fields available to a partition template.
+type PartitionValues struct {
+	Tenant   string // empty unless Config.Tenant is set
+	Source   string
+	Category string
+	Day      string // YYYY-MM-DD
+	Hour     string // HH, empty unless Config.Hour is set
+	RunID    string
+	Year     string // YYYY, derived from Day
+	Month    string // MM, derived from Day
+}
+
+// partitionValuesFromConfig derives template values from partition keys.
+func partitionValuesFromConfig(cfg Config) PartitionValues {
+	v := PartitionValues{
+		Tenant:   cfg.Tenant,
+		Source:   cfg.Source,
+		Category: cfg.Category,
+		Day:      cfg.Day,
+		Hour:     cfg.Hour,
+		RunID:    cfg.RunID,
+	}
+	if parts := strings.SplitN(cfg.Day, "-", 3); len(parts) == 3 {
+		v.Year, v.Month = parts[0], parts[1]
+	}
+	return v
+}
+
+// PartitionTemplate is a parsed Hive partition layout.
+//
+// A template is a "/"-separated list of key=value segments, where each key is
+// a literal identifier and each value is a Go text/template over
+// PartitionValues, e.g.:
+//
+//	year={{.Year}}/month={{.Month}}/day={{.Day}}/source={{.Source}}/run_id={{.RunID}}
+//
+// The event_type partition is always appended after the template segments.
+type PartitionTemplate struct {
+	raw      string
+	keys     []string
+	segments []*template.Template
+}
+
+// ParsePartitionTemplate parses and validates a partition template.
+// An empty string yields the default template.
+func ParsePartitionTemplate(s string) (*PartitionTemplate, error) {
+	if strings.TrimSpace(s) == "" {
+		s = DefaultPartitionTemplate
+	}
+
+	pt := &PartitionTemplate{raw: s}
+	seen := make(map[string]struct{})
+	for _, seg := range strings.Split(s, "/") {
+		key, value, ok := strings.Cut(seg, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid partition segment %q: expected key={{.Field}}", seg)
+		}
+		if !partitionKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid partition key %q: must match %s", key, partitionKeyPattern)
+		}
+		if _, reserved := reservedRecordFields[key]; reserved {
+			return nil, fmt.Errorf("invalid partition key %q: reserved record field", key)
+		}
+		if key == TenantPartitionKey {
+			return nil, fmt.Errorf("invalid partition key %q: set via Config.Tenant, not the template", key)
+		}
+		if _, dup := seen[key]; dup {
+			return nil, fmt.Errorf("duplicate partition key %q", key)
+		}
+		seen[key] = struct{}{}
+
+		tmpl, err := template.New(key).Option("missingkey=error").Parse(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid partition segment %q: %w", seg, err)
+		}
+		pt.keys = append(pt.keys, key)
+		pt.segments = append(pt.segments, tmpl)
+	}
+
+	if err := pt.validate(); err != nil {
+		return nil, err
+	}
+	return pt, nil
+}
+
+// validate renders the template with sample values to catch execution errors,
+// empty segments, native keys bound to the wrong field, and a missing RunID.
+func (t *PartitionTemplate) validate() error {
+	sample := Config{
+		Source:   "sample-source",
+		Category: "sample-category",
+		Day:      "2006-01-02",
+		RunID:    "sample-run-id",
+	}
+	expected := map[string]string{
+		"source":   sample.Source,
+		"category": sample.Category,
+		"day":      sample.Day,
+		"run_id":   sample.RunID,
+	}
+
+	values, err := t.render(partitionValuesFromConfig(sample))
+	if err != nil {
+		return err
+	}
+
+	hasRunID := false
+	for i, key := range t.keys {
+		if values[i] == "" {
+			return fmt.Errorf("partition key %q renders empty", key)
+		}
+		if want, native := expected[key]; native && values[i] != want {
+			return fmt.Errorf("partition key %q must render its own field (%s={{.%s}})", key, key, nativePartitionKeys[key])
+		}
+		if strings.Contains(values[i], sample.RunID) {
+			hasRunID = true
+		}
+	}
+	if !hasRunID {
+		return ErrTemplateMissingRunID
+	}
+	return nil
+}
+
+// render executes each segment template against v.
+func (t *PartitionTemplate) render(v PartitionValues) ([]string, error) {
+	values := make([]string, len(t.segments))
+	for i, tmpl := range t.segments {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, v); err != nil {
+			return nil, fmt.Errorf("partition template %q: %w", t.raw, err)
+		}
+		values[i] = buf.String()
+	}
+	return values, nil
+}
+
+// ResolvePartitionTemplate returns the configured template, or the default
+// template when none is set. When Tenant is set, the tenant segment is
+// prepended so every path starts with tenant=<id>.
+func (c Config) ResolvePartitionTemplate() *PartitionTemplate {
+	pt := defaultPartitionTemplate
+	if c.PartitionTemplate != nil {
+		pt = c.PartitionTemplate
+	}
+	if c.Tenant != "" {
+		return pt.withTenant()
+	}
+	return pt
+}
+
+// HourPartitionKey is the partition key WithHour inserts after day=.
+const HourPartitionKey = "hour"
+
+// hourSegment renders the hour partition value.
+var hourSegment = template.Must(template.New(HourPartitionKey).Option("missingkey=error").Parse("{{.Hour}}"))
+
+// WithHour returns a copy of t with an hour={{.Hour}} segment inserted
+// directly after the day segment, for hourly partition granularity.
+// Returns an error if t has no day key to anchor the hour to, or already
+// has an hour key.
+func (t *PartitionTemplate) WithHour() (*PartitionTemplate, error) {
+	if slices.Contains(t.keys, HourPartitionKey) {
+		return nil, fmt.Errorf("partition template %q already has an %s key", t.raw, HourPartitionKey)
+	}
+	i := slices.Index(t.keys, "day")
+	if i < 0 {
+		return nil, fmt.Errorf("hourly partitions require a day key in partition template %q", t.raw)
+	}
+	segs := strings.Split(t.raw, "/")
+	return &PartitionTemplate{
+		raw:      strings.Join(slices.Insert(segs, i+1, HourPartitionKey+"={{.Hour}}"), "/"),
+		keys:     slices.Insert(slices.Clone(t.keys), i+1, HourPartitionKey),
+		segments: slices.Insert(slices.Clone(t.segments), i+1, hourSegment),
+	}, nil
+}
+
+// PartitionPath renders the configured partition path (without event_type).
+func (c Config) PartitionPath() (string, error) {
+	return c.ResolvePartitionTemplate().Path(c)
+}
+
+// partitionColumns returns rendered values for template keys that are not
+// native record columns. These are added to every record so Lode's Hive
+// partitioner can resolve them.
+func (c Config) partitionColumns() (map[string]string, error) {
+	values, err := c.ResolvePartitionTemplate().Values(c)
+	if err != nil {
+		return nil, err
+	}
+	extra := make(map[string]string)
+	for key, value := range values {
+		if _, native := nativePartitionKeys[key]; !native {
+			extra[key] = value
+		}
+	}
+	return extra, nil
+}
+
+// hiveKeys returns the Lode Hive partition keys for the configured template.
+func (c Config) hiveKeys() []string {
+	return append(c.ResolvePartitionTemplate().Keys(), "event_type")
+}
+
+// String returns the raw template text.
+func (t *PartitionTemplate) String() string {
+	return t.raw
+}
+
+// Keys returns the Hive partition keys in layout order, excluding event_type.
+func (t *PartitionTemplate) Keys() []string {
+	keys := make([]string, len(t.keys))
+	copy(keys, t.keys)
+	return keys
+}
+
+// Values returns the rendered partition values for cfg, keyed by partition key.
+func (t *PartitionTemplate) Values(cfg Config) (map[string]string, error) {
+	rendered, err := t.render(partitionValuesFromConfig(cfg))
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(t.keys))
+	for i, key := range t.keys {
+		values[key] = rendered[i]
+	}
+	return values, nil
+}
+
+// Path renders the partition path for cfg (e.g. "source=a/category=b/...").
+// Values are escaped the same way Lode's Hive partitioner escapes them, so the
+// path matches the on-disk location.
+func (t *PartitionTemplate) Path(cfg Config) (string, error) {
+	rendered, err := t.render(partitionValuesFromConfig(cfg))
+	if err != nil {
+		return "", err
+	}
+	parts := make([]string, len(t.keys))
+	for i, key := range t.keys {
+		parts[i] = key + "=" + url.PathEscape(rendered[i])
+	}
+	return strings.Join(parts, "/"), nil
+}
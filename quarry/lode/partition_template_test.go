@@ -0,0 +1,223 @@
+package lode
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pithecene-io/lode/lode"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+func TestParsePartitionTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    string
+		wantErr string
+	}{
+		{name: "empty uses default", tmpl: ""},
+		{name: "default", tmpl: DefaultPartitionTemplate},
+		{name: "year month layout", tmpl: "year={{.Year}}/month={{.Month}}/day={{.Day}}/source={{.Source}}/run_id={{.RunID}}"},
+		{name: "run id in custom key", tmpl: "source={{.Source}}/run={{.RunID}}"},
+		{name: "missing run id", tmpl: "year={{.Year}}/month={{.Month}}/day={{.Day}}/source={{.Source}}", wantErr: "must reference {{.RunID}}"},
+		{name: "segment without equals", tmpl: "{{.Source}}/run_id={{.RunID}}", wantErr: "expected key={{.Field}}"},
+		{name: "templated key", tmpl: "{{.Source}}=x/run_id={{.RunID}}", wantErr: "invalid partition key"},
+		{name: "reserved key", tmpl: "event_type={{.Source}}/run_id={{.RunID}}", wantErr: "reserved record field"},
+		{name: "duplicate key", tmpl: "run_id={{.RunID}}/run_id={{.RunID}}", wantErr: "duplicate partition key"},
+		{name: "native key bound to other field", tmpl: "source={{.Category}}/run_id={{.RunID}}", wantErr: `"source" must render its own field`},
+		{name: "unknown field", tmpl: "region={{.Region}}/run_id={{.RunID}}", wantErr: "Region"},
+		{name: "empty value", tmpl: "tag=/run_id={{.RunID}}", wantErr: "renders empty"},
+		{name: "parse error", tmpl: "run_id={{.RunID", wantErr: "invalid partition segment"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pt, err := ParsePartitionTemplate(tt.tmpl)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if pt == nil {
+					t.Fatal("expected template, got nil")
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected error containing %q, got nil", tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want substring %q", err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParsePartitionTemplate_MissingRunIDSentinel(t *testing.T) {
+	_, err := ParsePartitionTemplate("source={{.Source}}")
+	if !errors.Is(err, ErrTemplateMissingRunID) {
+		t.Errorf("expected ErrTemplateMissingRunID, got %v", err)
+	}
+}
+
+func TestPartitionTemplate_Path(t *testing.T) {
+	cfg := Config{Source: "src", Category: "cat", Day: "2026-02-08", RunID: "run-1"}
+
+	got, err := cfg.PartitionPath()
+	if err != nil {
+		t.Fatalf("PartitionPath failed: %v", err)
+	}
+	if want := "source=src/category=cat/day=2026-02-08/run_id=run-1"; got != want {
+		t.Errorf("default path = %q, want %q", got, want)
+	}
+
+	pt, err := ParsePartitionTemplate("year={{.Year}}/month={{.Month}}/day={{.Day}}/source={{.Source}}/run_id={{.RunID}}")
+	if err != nil {
+		t.Fatalf("ParsePartitionTemplate failed: %v", err)
+	}
+	cfg.PartitionTemplate = pt
+	got, err = cfg.PartitionPath()
+	if err != nil {
+		t.Fatalf("PartitionPath failed: %v", err)
+	}
+	if want := "year=2026/month=02/day=2026-02-08/source=src/run_id=run-1"; got != want {
+		t.Errorf("custom path = %q, want %q", got, want)
+	}
+}
+
+func TestPartitionTemplate_WithHour(t *testing.T) {
+	cfg := Config{Tenant: "acme", Source: "src", Category: "cat", Day: "2026-02-08", Hour: "07", RunID: "run-1"}
+
+	pt, err := defaultPartitionTemplate.WithHour()
+	if err != nil {
+		t.Fatalf("WithHour failed: %v", err)
+	}
+	cfg.PartitionTemplate = pt
+	got, err := cfg.PartitionPath()
+	if err != nil {
+		t.Fatalf("PartitionPath failed: %v", err)
+	}
+	if want := "tenant=acme/source=src/category=cat/day=2026-02-08/hour=07/run_id=run-1"; got != want {
+		t.Errorf("hourly path = %q, want %q", got, want)
+	}
+	if want := "source={{.Source}}/category={{.Category}}/day={{.Day}}/hour={{.Hour}}/run_id={{.RunID}}"; pt.String() != want {
+		t.Errorf("hourly template = %q, want %q", pt.String(), want)
+	}
+	if defaultPartitionTemplate.String() != DefaultPartitionTemplate {
+		t.Error("WithHour must not mutate the source template")
+	}
+	if _, err := pt.WithHour(); err == nil {
+		t.Error("expected error adding a second hour key")
+	}
+
+	noDay, err := ParsePartitionTemplate("year={{.Year}}/source={{.Source}}/run_id={{.RunID}}")
+	if err != nil {
+		t.Fatalf("ParsePartitionTemplate failed: %v", err)
+	}
+	if _, err := noDay.WithHour(); err == nil || !strings.Contains(err.Error(), "require a day key") {
+		t.Errorf("expected missing day key error, got %v", err)
+	}
+}
+
+func TestLodeClient_HourlyPartitions(t *testing.T) {
+	root := t.TempDir()
+	pt, err := defaultPartitionTemplate.WithHour()
+	if err != nil {
+		t.Fatalf("WithHour failed: %v", err)
+	}
+	cfg := Config{
+		Dataset:           "quarry",
+		Source:            "src",
+		Category:          "cat",
+		Day:               "2026-02-08",
+		Hour:              "23",
+		RunID:             "run-1",
+		Policy:            "strict",
+		PartitionTemplate: pt,
+	}
+
+	client, err := NewLodeClient(cfg, root)
+	if err != nil {
+		t.Fatalf("NewLodeClient failed: %v", err)
+	}
+	events := []*types.EventEnvelope{{
+		ContractVersion: "1.0.0",
+		EventID:         "evt-1",
+		RunID:           "run-1",
+		Seq:             1,
+		Type:            types.EventTypeItem,
+		Ts:              "2026-02-08T23:10:00Z",
+		Payload:         map[string]any{"k": "v"},
+		Attempt:         1,
+	}}
+	if err := client.WriteEvents(t.Context(), cfg.Dataset, cfg.RunID, events); err != nil {
+		t.Fatalf("WriteEvents failed: %v", err)
+	}
+
+	partition := filepath.Join(root, "datasets", "quarry", "partitions", "source=src", "category=cat", "day=2026-02-08", "hour=23", "run_id=run-1")
+	if _, err := os.Stat(filepath.Join(partition, "event_type=item")); err != nil {
+		t.Errorf("expected event partition under hour segment: %v", err)
+	}
+}
+
+func TestLodeClient_CustomPartitionTemplate(t *testing.T) {
+	root := t.TempDir()
+	pt, err := ParsePartitionTemplate("year={{.Year}}/month={{.Month}}/source={{.Source}}/run_id={{.RunID}}")
+	if err != nil {
+		t.Fatalf("ParsePartitionTemplate failed: %v", err)
+	}
+	cfg := Config{
+		Dataset:           "quarry",
+		Source:            "src",
+		Category:          "cat",
+		Day:               "2026-02-08",
+		RunID:             "run-1",
+		Policy:            "strict",
+		PartitionTemplate: pt,
+	}
+
+	client, err := NewLodeClient(cfg, root)
+	if err != nil {
+		t.Fatalf("NewLodeClient failed: %v", err)
+	}
+
+	events := []*types.EventEnvelope{{
+		ContractVersion: "1.0.0",
+		EventID:         "evt-1",
+		RunID:           "run-1",
+		Seq:             1,
+		Type:            types.EventTypeItem,
+		Ts:              "2026-02-08T12:00:00Z",
+		Payload:         map[string]any{"k": "v"},
+		Attempt:         1,
+	}}
+	if err := client.WriteEvents(t.Context(), cfg.Dataset, cfg.RunID, events); err != nil {
+		t.Fatalf("WriteEvents failed: %v", err)
+	}
+	if err := client.PutFile(t.Context(), "page.html", "text/html", []byte("<html/>")); err != nil {
+		t.Fatalf("PutFile failed: %v", err)
+	}
+
+	partition := filepath.Join(root, "datasets", "quarry", "partitions", "year=2026", "month=02", "source=src", "run_id=run-1")
+	if _, err := os.Stat(filepath.Join(partition, "event_type=item")); err != nil {
+		t.Errorf("expected event partition under template layout: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(partition, "files", "page.html")); err != nil {
+		t.Errorf("expected sidecar file under template layout: %v", err)
+	}
+}
+
+func TestNewLodeClient_TemplateRenderFailure(t *testing.T) {
+	// slice fails at render time for a short source, after parse-time validation
+	pt, err := ParsePartitionTemplate("prefix={{slice .Source 0 8}}/run_id={{.RunID}}")
+	if err != nil {
+		t.Fatalf("ParsePartitionTemplate failed: %v", err)
+	}
+	cfg := Config{Dataset: "quarry", Source: "abc", Category: "c", Day: "2026-02-08", RunID: "r", PartitionTemplate: pt}
+
+	if _, err := NewLodeClientWithFactory(cfg, lode.NewMemoryFactory()); err == nil {
+		t.Fatal("expected init error for unrenderable template")
+	}
+}
@@ -51,6 +51,15 @@ type ArtifactCommitRecord struct {
 	ContentType string `json:"content_type"`
 	SizeBytes   int64  `json:"size_bytes"`
 
+	// DeclaredContentType is the executor's content type when ContentType
+	// was sniffed from the artifact bytes (may be empty); nil otherwise.
+	DeclaredContentType *string `json:"declared_content_type,omitempty"`
+
+	// Optional retention hint; ExpiresAt is ts + ttl_seconds
+	RetentionClass string `json:"retention_class,omitempty"`
+	TTLSeconds     int64  `json:"ttl_seconds,omitempty"`
+	ExpiresAt      string `json:"expires_at,omitempty"`
+
 	// Event metadata (from envelope)
 	ContractVersion string  `json:"contract_version"`
 	EventID         string  `json:"event_id"`
@@ -98,6 +107,9 @@ type artifactPayload struct {
 	name        string
 	contentType string
 	sizeBytes   int64
+	retention   RetentionHint
+	// declaredContentType is set only when contentType was sniffed
+	declaredContentType *string
 }
 
 // extractArtifactPayload extracts typed artifact fields from a payload map.
@@ -115,9 +127,14 @@ func extractArtifactPayload(payload map[string]any) artifactPayload {
 	if v, ok := payload["content_type"].(string); ok {
 		p.contentType = v
 	}
+	if v, ok := payload["declared_content_type"].(string); ok {
+		p.declaredContentType = &v
+	}
 	if v, ok := payload["size_bytes"].(float64); ok {
 		p.sizeBytes = int64(v)
 	}
+	// Malformed hints are rejected before records are built
+	p.retention, _ = ParseRetentionHint(payload)
 	return p
 }
 
@@ -176,6 +193,16 @@ func toArtifactCommitRecordMap(e *types.EventEnvelope, cfg Config) map[string]an
 		"policy":           cfg.Policy,
 	}
 	addOptionalEnvelopeFields(m, e)
+	if ap.declaredContentType != nil {
+		m["declared_content_type"] = *ap.declaredContentType
+	}
+	if ap.retention.Class != "" {
+		m["retention_class"] = ap.retention.Class
+	}
+	if ap.retention.TTLSeconds > 0 {
+		m["ttl_seconds"] = ap.retention.TTLSeconds
+		m["expires_at"] = ap.retention.ExpiresAt(e.Ts).Format(time.RFC3339)
+	}
 	return m
 }
 
@@ -213,26 +240,40 @@ func toMetricsRecordMap(snap metrics.Snapshot, cfg Config, completedAt time.Time
 		"runs_crashed_total":   snap.RunsCrashed,
 
 		// Ingestion
-		"events_received_total":  snap.EventsReceived,
-		"events_persisted_total": snap.EventsPersisted,
-		"events_dropped_total":   snap.EventsDropped,
+		"events_received_total":     snap.EventsReceived,
+		"events_persisted_total":    snap.EventsPersisted,
+		"events_dropped_total":      snap.EventsDropped,
+		"policy_degradations_total": snap.Degradations,
 
 		// Executor
 		"executor_launch_success_total": snap.ExecutorLaunchSuccess,
 		"executor_launch_failure_total": snap.ExecutorLaunchFailure,
 		"executor_crash_total":          snap.ExecutorCrash,
 		"ipc_decode_errors_total":       snap.IPCDecodeErrors,
+		"seq_gaps_total":                snap.SeqGaps,
+		"artifacts_discarded_total":     snap.ArtifactsDiscarded,
+		"events_discarded_total":        snap.EventsDiscarded,
+		"events_sampled_out_total":      snap.EventsSampledOut,
+		"events_filtered_total":         snap.EventsFiltered,
+
+		// Fan-out
+		"enqueues_deduplicated_total": snap.EnqueuesDeduplicated,
+
+		// Proxy
+		"proxy_rotations_total": snap.ProxyRotations,
 
 		// Lode / Storage
-		"lode_write_success_total": snap.LodeWriteSuccess,
-		"lode_write_failure_total": snap.LodeWriteFailure,
-		"lode_write_retry_total":   snap.LodeWriteRetry,
+		"lode_write_success_total":  snap.LodeWriteSuccess,
+		"lode_write_failure_total":  snap.LodeWriteFailure,
+		"lode_write_retry_total":    snap.LodeWriteRetry,
+		"lode_writes_skipped_total": snap.LodeWritesSkipped,
 
 		// Dimensions
-		"policy":          snap.Policy,
-		"executor":        snap.Executor,
-		"storage_backend": snap.StorageBackend,
-		"run_id":          snap.RunID,
+		"policy":           snap.Policy,
+		"executor":         snap.Executor,
+		"storage_backend":  snap.StorageBackend,
+		"run_id":           snap.RunID,
+		"contract_version": types.ContractVersion,
 
 		// Partition keys
 		"source":   cfg.Source,
@@ -244,6 +285,9 @@ func toMetricsRecordMap(snap metrics.Snapshot, cfg Config, completedAt time.Time
 	if snap.JobID != "" {
 		m["job_id"] = snap.JobID
 	}
+	if snap.ExecutorVersion != "" {
+		m["executor_version"] = snap.ExecutorVersion
+	}
 
 	// Copy dropped_by_type if non-empty
 	if len(snap.DroppedByType) > 0 {
@@ -254,6 +298,33 @@ func toMetricsRecordMap(snap metrics.Snapshot, cfg Config, completedAt time.Time
 		m["dropped_by_type"] = dropped
 	}
 
+	// Copy log_filtered_by_level if non-empty
+	if len(snap.LogFilteredByLevel) > 0 {
+		filtered := make(map[string]int64, len(snap.LogFilteredByLevel))
+		for k, v := range snap.LogFilteredByLevel {
+			filtered[k] = v
+		}
+		m["log_filtered_by_level"] = filtered
+	}
+
+	// Copy runs_by_reason if non-empty
+	if len(snap.RunsByReason) > 0 {
+		reasons := make(map[string]int64, len(snap.RunsByReason))
+		for k, v := range snap.RunsByReason {
+			reasons[k] = v
+		}
+		m["runs_by_reason"] = reasons
+	}
+
+	// Copy user run labels if non-empty
+	if len(snap.Labels) > 0 {
+		labels := make(map[string]string, len(snap.Labels))
+		for k, v := range snap.Labels {
+			labels[k] = v
+		}
+		m["labels"] = labels
+	}
+
 	return m
 }
 
@@ -279,23 +350,31 @@ func toEventRecord(e *types.EventEnvelope, cfg Config) EventRecord {
 
 func toArtifactCommitRecord(e *types.EventEnvelope, cfg Config) ArtifactCommitRecord {
 	ap := extractArtifactPayload(e.Payload)
+	var expiresAt string
+	if ap.retention.TTLSeconds > 0 {
+		expiresAt = ap.retention.ExpiresAt(e.Ts).Format(time.RFC3339)
+	}
 	return ArtifactCommitRecord{
-		RecordKind:      RecordKindArtifactEvent,
-		ArtifactID:      ap.artifactID,
-		Name:            ap.name,
-		ContentType:     ap.contentType,
-		SizeBytes:       ap.sizeBytes,
-		ContractVersion: e.ContractVersion,
-		EventID:         e.EventID,
-		RunID:           e.RunID,
-		Seq:             e.Seq,
-		Ts:              e.Ts,
-		JobID:           e.JobID,
-		ParentRunID:     e.ParentRunID,
-		Attempt:         e.Attempt,
-		Source:          cfg.Source,
-		Category:        cfg.Category,
-		Day:             cfg.Day,
+		RecordKind:          RecordKindArtifactEvent,
+		ArtifactID:          ap.artifactID,
+		Name:                ap.name,
+		ContentType:         ap.contentType,
+		SizeBytes:           ap.sizeBytes,
+		DeclaredContentType: ap.declaredContentType,
+		RetentionClass:      ap.retention.Class,
+		TTLSeconds:          ap.retention.TTLSeconds,
+		ExpiresAt:           expiresAt,
+		ContractVersion:     e.ContractVersion,
+		EventID:             e.EventID,
+		RunID:               e.RunID,
+		Seq:                 e.Seq,
+		Ts:                  e.Ts,
+		JobID:               e.JobID,
+		ParentRunID:         e.ParentRunID,
+		Attempt:             e.Attempt,
+		Source:              cfg.Source,
+		Category:            cfg.Category,
+		Day:                 cfg.Day,
 	}
 }
 
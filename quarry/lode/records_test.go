@@ -74,3 +74,24 @@ func TestToArtifactCommitRecordMap_IncludesPolicy(t *testing.T) {
 		t.Errorf("policy = %q, want %q", got, "buffered")
 	}
 }
+
+func TestToArtifactCommitRecordMap_DeclaredContentType(t *testing.T) {
+	envelope := &types.EventEnvelope{
+		Type: types.EventTypeArtifact,
+		Payload: map[string]any{
+			"artifact_id":           "art-001",
+			"content_type":          "image/png",
+			"declared_content_type": "",
+			"size_bytes":            float64(1024),
+		},
+	}
+	record := toArtifactCommitRecordMap(envelope, Config{})
+	if got, ok := record["declared_content_type"]; !ok || got != "" {
+		t.Errorf("declared_content_type = %v (present %v), want an empty declared type kept", got, ok)
+	}
+
+	delete(envelope.Payload, "declared_content_type")
+	if _, ok := toArtifactCommitRecordMap(envelope, Config{})["declared_content_type"]; ok {
+		t.Error("declared_content_type must be omitted when the type was not sniffed")
+	}
+}
@@ -0,0 +1,331 @@
+package lode
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/pithecene-io/lode/lode"
+)
+
+// Object tag keys applied to S3 chunk files holding artifacts with a
+// retention hint. Lifecycle rules can filter on these tags.
+const (
+	TagRetentionClass = "quarry:retention-class"
+	TagTTLSeconds     = "quarry:ttl-seconds"
+	TagExpiresAt      = "quarry:expires-at"
+)
+
+// RetentionSidecarSuffix is appended to a chunk file path to name the
+// retention sidecar written by non-S3 backends.
+const RetentionSidecarSuffix = ".retention.json"
+
+// ErrInvalidRetention indicates an artifact event carried a malformed
+// retention_class or ttl_seconds.
+var ErrInvalidRetention = errors.New("invalid artifact retention")
+
+// retentionClassPattern allows values that are valid S3 tag values and safe
+// in file names.
+var retentionClassPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+
+// RetentionHint is the optional expiry metadata of an artifact commit.
+// The zero value means the artifact is retained indefinitely.
+type RetentionHint struct {
+	// Class is the retention_class label (e.g. "debug").
+	Class string
+	// TTLSeconds is the artifact lifetime after commit (0 = no TTL).
+	TTLSeconds int64
+}
+
+// IsZero reports whether the hint carries no retention metadata.
+func (h RetentionHint) IsZero() bool {
+	return h == RetentionHint{}
+}
+
+// ParseRetentionHint extracts retention_class and ttl_seconds from an
+// artifact event payload. Both are optional; ttl_seconds must be a positive
+// integer. Returns ErrInvalidRetention for malformed values.
+func ParseRetentionHint(payload map[string]any) (RetentionHint, error) {
+	var h RetentionHint
+	if v, ok := payload["retention_class"]; ok && v != nil {
+		class, isString := v.(string)
+		if !isString || !retentionClassPattern.MatchString(class) {
+			return h, fmt.Errorf("%w: retention_class %v must match %s", ErrInvalidRetention, v, retentionClassPattern)
+		}
+		h.Class = class
+	}
+	if v, ok := payload["ttl_seconds"]; ok && v != nil {
+		ttl, isInt := retentionInt(v)
+		if !isInt || ttl <= 0 {
+			return h, fmt.Errorf("%w: ttl_seconds must be a positive integer, got %v", ErrInvalidRetention, v)
+		}
+		h.TTLSeconds = ttl
+	}
+	return h, nil
+}
+
+// retentionInt converts a decoded integer (msgpack picks the smallest
+// encoding; JSON round-trips produce float64) to int64.
+func retentionInt(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint8:
+		return int64(n), true
+	case uint16:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	case uint64:
+		if n > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(n), true
+	case float64:
+		if n != math.Trunc(n) || n > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(n), true
+	default:
+		return 0, false
+	}
+}
+
+// ExpiresAt returns the expiry time for an artifact committed at ts (the
+// event timestamp, ISO 8601), or the zero time if the hint has no TTL.
+// An unparseable ts counts from now.
+func (h RetentionHint) ExpiresAt(ts string) time.Time {
+	if h.TTLSeconds <= 0 {
+		return time.Time{}
+	}
+	commit, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		commit = time.Now()
+	}
+	return commit.Add(time.Duration(h.TTLSeconds) * time.Second).UTC()
+}
+
+// artifactRetention is a committed artifact's hint and expiry.
+type artifactRetention struct {
+	hint    RetentionHint
+	expires time.Time
+}
+
+// retentionFile tracks the artifacts whose chunks landed in one data file.
+// The file's hint is applied once every artifact in it has committed, and
+// only if they all share the same non-zero hint.
+type retentionFile struct {
+	pending map[string]struct{} // artifacts not yet committed
+	hint    RetentionHint       // hint shared by committed artifacts
+	expires time.Time           // latest expiry among committed artifacts
+	mixed   bool                // committed artifacts disagree on the hint
+	ids     []string            // committed artifact IDs, in commit order
+}
+
+// retentionApplier records a retention hint against a chunk data file.
+// Implementations must be idempotent: a failed commit write is retried.
+type retentionApplier interface {
+	applyRetention(ctx context.Context, path string, hint RetentionHint, expiresAt time.Time, artifactIDs []string) error
+}
+
+// retentionSidecar is the JSON written next to a chunk file by
+// sidecarRetention.
+type retentionSidecar struct {
+	RetentionClass string   `json:"retention_class,omitempty"`
+	TTLSeconds     int64    `json:"ttl_seconds,omitempty"`
+	ExpiresAt      string   `json:"expires_at,omitempty"`
+	ArtifactIDs    []string `json:"artifact_ids"`
+}
+
+// sidecarRetention writes <path>.retention.json through the client's store,
+// for an external GC to act on.
+type sidecarRetention struct {
+	client *LodeClient
+}
+
+func (s sidecarRetention) applyRetention(ctx context.Context, path string, hint RetentionHint, expiresAt time.Time, artifactIDs []string) error {
+	store, err := s.client.getOrCreateStore()
+	if err != nil {
+		return fmt.Errorf("retention store init failed: %w", err)
+	}
+	sidecar := retentionSidecar{
+		RetentionClass: hint.Class,
+		TTLSeconds:     hint.TTLSeconds,
+		ArtifactIDs:    artifactIDs,
+	}
+	if !expiresAt.IsZero() {
+		sidecar.ExpiresAt = expiresAt.Format(time.RFC3339)
+	}
+	data, err := json.Marshal(sidecar)
+	if err != nil {
+		return fmt.Errorf("retention sidecar marshal failed: %w", err)
+	}
+	sidecarPath := path + RetentionSidecarSuffix
+	// A sidecar from an earlier attempt of the same commit is already correct
+	if err := store.Put(ctx, sidecarPath, bytes.NewReader(data)); err != nil && !errors.Is(err, lode.ErrPathExists) {
+		return WrapWriteError(err, sidecarPath)
+	}
+	s.client.recordObject(sidecarPath, data)
+	return nil
+}
+
+// s3TaggingAPI is the subset of the S3 client used to tag chunk objects.
+type s3TaggingAPI interface {
+	PutObjectTagging(ctx context.Context, params *s3.PutObjectTaggingInput, optFns ...func(*s3.Options)) (*s3.PutObjectTaggingOutput, error)
+}
+
+// s3TagRetention tags chunk objects in place so bucket lifecycle rules can
+// expire them.
+type s3TagRetention struct {
+	api    s3TaggingAPI
+	bucket string
+	prefix string // store key prefix, with trailing slash if non-empty
+}
+
+func (s s3TagRetention) applyRetention(ctx context.Context, path string, hint RetentionHint, expiresAt time.Time, _ []string) error {
+	var tags []s3types.Tag
+	if hint.Class != "" {
+		tags = append(tags, s3Tag(TagRetentionClass, hint.Class))
+	}
+	if hint.TTLSeconds > 0 {
+		tags = append(tags,
+			s3Tag(TagTTLSeconds, strconv.FormatInt(hint.TTLSeconds, 10)),
+			s3Tag(TagExpiresAt, expiresAt.Format(time.RFC3339)),
+		)
+	}
+	key := s.prefix + path
+	_, err := s.api.PutObjectTagging(ctx, &s3.PutObjectTaggingInput{
+		Bucket:  &s.bucket,
+		Key:     &key,
+		Tagging: &s3types.Tagging{TagSet: tags},
+	})
+	if err != nil {
+		return WrapWriteError(fmt.Errorf("tag object: %w", err), key)
+	}
+	return nil
+}
+
+func s3Tag(key, value string) s3types.Tag {
+	return s3types.Tag{Key: &key, Value: &value}
+}
+
+// trackChunkFiles records that the given artifacts have chunks in files.
+// Must be called under c.mu.
+func (c *LodeClient) trackChunkFiles(artifactIDs map[string]struct{}, files []lode.FileRef) {
+	for _, f := range files {
+		rf := c.retentionFiles[f.Path]
+		if rf == nil {
+			rf = &retentionFile{pending: make(map[string]struct{})}
+			c.retentionFiles[f.Path] = rf
+		}
+		for id := range artifactIDs {
+			rf.pending[id] = struct{}{}
+			c.chunkFiles[id] = append(c.chunkFiles[id], f.Path)
+		}
+	}
+}
+
+// retentionCommit is the effect of committing a batch of artifacts on one
+// chunk file, computed before the commit write and applied after it.
+type retentionCommit struct {
+	path    string
+	updated retentionFile
+}
+
+// planRetention computes how committing the given artifacts updates the
+// tracked chunk files, without mutating client state. Files whose artifacts
+// have all committed with the same non-zero hint are returned in ready.
+// Must be called under c.mu.
+func (c *LodeClient) planRetention(commits []string, retention map[string]artifactRetention) (updates []retentionCommit, ready []retentionCommit) {
+	staged := make(map[string]*retentionFile)
+	var order []string
+	for _, id := range commits {
+		for _, path := range c.chunkFiles[id] {
+			rf, ok := staged[path]
+			if !ok {
+				orig := c.retentionFiles[path]
+				if orig == nil {
+					continue
+				}
+				rf = &retentionFile{
+					pending: make(map[string]struct{}, len(orig.pending)),
+					hint:    orig.hint,
+					expires: orig.expires,
+					mixed:   orig.mixed,
+					ids:     append([]string(nil), orig.ids...),
+				}
+				for p := range orig.pending {
+					rf.pending[p] = struct{}{}
+				}
+				staged[path] = rf
+				order = append(order, path)
+			}
+			if _, isPending := rf.pending[id]; !isPending {
+				continue
+			}
+			delete(rf.pending, id)
+			ar := retention[id]
+			if len(rf.ids) > 0 && rf.hint != ar.hint {
+				rf.mixed = true
+			}
+			rf.hint = ar.hint
+			if ar.expires.After(rf.expires) {
+				rf.expires = ar.expires
+			}
+			rf.ids = append(rf.ids, id)
+		}
+	}
+	for _, path := range order {
+		rc := retentionCommit{path: path, updated: *staged[path]}
+		updates = append(updates, rc)
+		if len(rc.updated.pending) == 0 && !rc.updated.mixed && !rc.updated.hint.IsZero() {
+			ready = append(ready, rc)
+		}
+	}
+	return updates, ready
+}
+
+// applyRetentionPlan records the hints of ready files via the client's
+// retention applier. Called before the commit write, so a committed
+// artifact's chunk files always carry their hint.
+func (c *LodeClient) applyRetentionPlan(ctx context.Context, ready []retentionCommit) error {
+	for _, rc := range ready {
+		if err := c.retention.applyRetention(ctx, rc.path, rc.updated.hint, rc.updated.expires, rc.updated.ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commitRetentionPlan stores the updated file state after a successful
+// commit write, dropping files whose artifacts have all committed.
+// Must be called under c.mu.
+func (c *LodeClient) commitRetentionPlan(commits []string, updates []retentionCommit) {
+	for _, rc := range updates {
+		if len(rc.updated.pending) == 0 {
+			delete(c.retentionFiles, rc.path)
+			continue
+		}
+		updated := rc.updated
+		c.retentionFiles[rc.path] = &updated
+	}
+	for _, id := range commits {
+		delete(c.chunkFiles, id)
+	}
+}
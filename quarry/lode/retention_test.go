@@ -0,0 +1,258 @@
+package lode
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/pithecene-io/lode/lode"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+func TestParseRetentionHint(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload map[string]any
+		want    RetentionHint
+		wantErr bool
+	}{
+		{name: "absent", payload: map[string]any{"artifact_id": "a"}},
+		{name: "class only", payload: map[string]any{"retention_class": "debug"}, want: RetentionHint{Class: "debug"}},
+		{name: "msgpack int ttl", payload: map[string]any{"ttl_seconds": int8(60)}, want: RetentionHint{TTLSeconds: 60}},
+		{name: "json float ttl", payload: map[string]any{"retention_class": "debug", "ttl_seconds": float64(86400)}, want: RetentionHint{Class: "debug", TTLSeconds: 86400}},
+		{name: "zero ttl", payload: map[string]any{"ttl_seconds": int64(0)}, wantErr: true},
+		{name: "negative ttl", payload: map[string]any{"ttl_seconds": int64(-5)}, wantErr: true},
+		{name: "fractional ttl", payload: map[string]any{"ttl_seconds": 1.5}, wantErr: true},
+		{name: "string ttl", payload: map[string]any{"ttl_seconds": "60"}, wantErr: true},
+		{name: "class with slash", payload: map[string]any{"retention_class": "a/b"}, wantErr: true},
+		{name: "empty class", payload: map[string]any{"retention_class": ""}, wantErr: true},
+		{name: "non-string class", payload: map[string]any{"retention_class": 7}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseRetentionHint(tt.payload)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRetention) {
+					t.Fatalf("expected ErrInvalidRetention, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("hint = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// retentionTestClient returns a client over a shared memory store.
+func retentionTestClient(t *testing.T) (*LodeClient, lode.Store) {
+	t.Helper()
+	store := lode.NewMemory()
+	cfg := Config{
+		Dataset:  "quarry",
+		Source:   "test-source",
+		Category: "test-category",
+		Day:      "2026-02-03",
+		RunID:    "run-123",
+		Policy:   "strict",
+	}
+	client, err := NewLodeClientWithFactory(cfg, sharedFactory(store))
+	if err != nil {
+		t.Fatalf("NewLodeClientWithFactory failed: %v", err)
+	}
+	return client, store
+}
+
+func artifactCommit(id string, seq int64, extra map[string]any) *types.EventEnvelope {
+	payload := map[string]any{
+		"artifact_id":  id,
+		"name":         id + ".png",
+		"content_type": "image/png",
+		"size_bytes":   float64(5),
+	}
+	for k, v := range extra {
+		payload[k] = v
+	}
+	return &types.EventEnvelope{
+		EventID: "evt-" + id,
+		Type:    types.EventTypeArtifact,
+		Seq:     seq,
+		Ts:      "2026-02-03T12:00:00Z",
+		Payload: payload,
+	}
+}
+
+// retentionSidecars returns the decoded retention sidecars in store.
+func retentionSidecars(t *testing.T, store lode.Store) []retentionSidecar {
+	t.Helper()
+	paths, err := store.List(t.Context(), "")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	var sidecars []retentionSidecar
+	for _, p := range paths {
+		if !strings.HasSuffix(p, RetentionSidecarSuffix) {
+			continue
+		}
+		if !strings.Contains(p, "event_type=artifact") {
+			t.Errorf("sidecar %s is not next to a chunk file", p)
+		}
+		rc, err := store.Get(t.Context(), p)
+		if err != nil {
+			t.Fatalf("Get %s failed: %v", p, err)
+		}
+		data, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		var s retentionSidecar
+		if err := json.Unmarshal(data, &s); err != nil {
+			t.Fatalf("sidecar %s is not valid JSON: %v", p, err)
+		}
+		sidecars = append(sidecars, s)
+	}
+	return sidecars
+}
+
+func TestLodeClient_RetentionSidecar(t *testing.T) {
+	client, store := retentionTestClient(t)
+	ctx := t.Context()
+
+	chunks := []*types.ArtifactChunk{{ArtifactID: "shot", Seq: 1, IsLast: true, Data: []byte("hello")}}
+	if err := client.WriteChunks(ctx, "quarry", "run-123", chunks); err != nil {
+		t.Fatalf("WriteChunks failed: %v", err)
+	}
+	commit := artifactCommit("shot", 1, map[string]any{"retention_class": "debug", "ttl_seconds": float64(3600)})
+	if err := client.WriteEvents(ctx, "quarry", "run-123", []*types.EventEnvelope{commit}); err != nil {
+		t.Fatalf("WriteEvents failed: %v", err)
+	}
+
+	sidecars := retentionSidecars(t, store)
+	if len(sidecars) != 1 {
+		t.Fatalf("expected 1 retention sidecar, got %d", len(sidecars))
+	}
+	s := sidecars[0]
+	if s.RetentionClass != "debug" || s.TTLSeconds != 3600 || s.ExpiresAt != "2026-02-03T13:00:00Z" {
+		t.Errorf("sidecar = %+v", s)
+	}
+	if len(s.ArtifactIDs) != 1 || s.ArtifactIDs[0] != "shot" {
+		t.Errorf("ArtifactIDs = %v, want [shot]", s.ArtifactIDs)
+	}
+	if len(client.retentionFiles) != 0 || len(client.chunkFiles) != 0 {
+		t.Error("retention state should be cleared after commit")
+	}
+}
+
+func TestLodeClient_RetentionSharedChunkFile(t *testing.T) {
+	hint := map[string]any{"retention_class": "debug"}
+	tests := []struct {
+		name         string
+		secondHint   map[string]any
+		wantSidecars int
+	}{
+		{name: "same hint", secondHint: hint, wantSidecars: 1},
+		{name: "permanent neighbour", secondHint: nil, wantSidecars: 0},
+		{name: "different class", secondHint: map[string]any{"retention_class": "audit"}, wantSidecars: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, store := retentionTestClient(t)
+			ctx := t.Context()
+
+			// Both artifacts land in one chunk file
+			chunks := []*types.ArtifactChunk{
+				{ArtifactID: "a", Seq: 1, IsLast: true, Data: []byte("aaaaa")},
+				{ArtifactID: "b", Seq: 1, IsLast: true, Data: []byte("bbbbb")},
+			}
+			if err := client.WriteChunks(ctx, "quarry", "run-123", chunks); err != nil {
+				t.Fatalf("WriteChunks failed: %v", err)
+			}
+
+			if err := client.WriteEvents(ctx, "quarry", "run-123", []*types.EventEnvelope{artifactCommit("a", 1, hint)}); err != nil {
+				t.Fatalf("WriteEvents a failed: %v", err)
+			}
+			if n := len(retentionSidecars(t, store)); n != 0 {
+				t.Fatalf("file must not be marked while b is uncommitted, got %d sidecars", n)
+			}
+
+			if err := client.WriteEvents(ctx, "quarry", "run-123", []*types.EventEnvelope{artifactCommit("b", 2, tt.secondHint)}); err != nil {
+				t.Fatalf("WriteEvents b failed: %v", err)
+			}
+			if n := len(retentionSidecars(t, store)); n != tt.wantSidecars {
+				t.Errorf("sidecars = %d, want %d", n, tt.wantSidecars)
+			}
+		})
+	}
+}
+
+func TestLodeClient_RetentionRejectsInvalidHint(t *testing.T) {
+	client, _ := retentionTestClient(t)
+	ctx := t.Context()
+
+	chunks := []*types.ArtifactChunk{{ArtifactID: "shot", Seq: 1, IsLast: true, Data: []byte("hello")}}
+	if err := client.WriteChunks(ctx, "quarry", "run-123", chunks); err != nil {
+		t.Fatalf("WriteChunks failed: %v", err)
+	}
+	commit := artifactCommit("shot", 1, map[string]any{"ttl_seconds": float64(-1)})
+	err := client.WriteEvents(ctx, "quarry", "run-123", []*types.EventEnvelope{commit})
+	if !errors.Is(err, ErrInvalidRetention) {
+		t.Fatalf("expected ErrInvalidRetention, got %v", err)
+	}
+}
+
+func TestToArtifactCommitRecordMap_Retention(t *testing.T) {
+	record := toArtifactCommitRecordMap(artifactCommit("shot", 1, map[string]any{"retention_class": "debug", "ttl_seconds": int64(60)}), Config{})
+	if record["retention_class"] != "debug" || record["ttl_seconds"] != int64(60) || record["expires_at"] != "2026-02-03T12:01:00Z" {
+		t.Errorf("retention fields = %v, %v, %v", record["retention_class"], record["ttl_seconds"], record["expires_at"])
+	}
+
+	plain := toArtifactCommitRecordMap(artifactCommit("item", 2, nil), Config{})
+	for _, key := range []string{"retention_class", "ttl_seconds", "expires_at"} {
+		if _, ok := plain[key]; ok {
+			t.Errorf("%s should be omitted without a hint", key)
+		}
+	}
+}
+
+// captureTaggingAPI records PutObjectTagging calls.
+type captureTaggingAPI struct {
+	inputs []*s3.PutObjectTaggingInput
+}
+
+func (c *captureTaggingAPI) PutObjectTagging(_ context.Context, params *s3.PutObjectTaggingInput, _ ...func(*s3.Options)) (*s3.PutObjectTaggingOutput, error) {
+	c.inputs = append(c.inputs, params)
+	return &s3.PutObjectTaggingOutput{}, nil
+}
+
+func TestS3TagRetention_TagsChunkObject(t *testing.T) {
+	api := &captureTaggingAPI{}
+	tagger := newS3TagRetention(api, S3Config{Bucket: "my-bucket", Prefix: "quarry"})
+
+	expires, _ := time.Parse(time.RFC3339, "2026-02-04T12:00:00Z")
+	hint := RetentionHint{Class: "debug", TTLSeconds: 86400}
+	if err := tagger.applyRetention(t.Context(), "datasets/quarry/chunk.jsonl", hint, expires, []string{"shot"}); err != nil {
+		t.Fatalf("applyRetention: %v", err)
+	}
+
+	if len(api.inputs) != 1 {
+		t.Fatalf("expected 1 tagging call, got %d", len(api.inputs))
+	}
+	in := api.inputs[0]
+	if *in.Bucket != "my-bucket" || *in.Key != "quarry/datasets/quarry/chunk.jsonl" {
+		t.Errorf("tagged %s/%s", *in.Bucket, *in.Key)
+	}
+	tags := make(map[string]string)
+	for _, tag := range in.Tagging.TagSet {
+		tags[*tag.Key] = *tag.Value
+	}
+	if tags[TagRetentionClass] != "debug" || tags[TagTTLSeconds] != "86400" || tags[TagExpiresAt] != "2026-02-04T12:00:00Z" {
+		t.Errorf("tags = %v", tags)
+	}
+}
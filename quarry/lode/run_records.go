@@ -0,0 +1,169 @@
+package lode
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/pithecene-io/lode/lode"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+// ErrNoRunRecords is returned when a dataset holds no events or chunks for a run.
+var ErrNoRunRecords = errors.New("no event or chunk records found")
+
+// RunRecords is a persisted run's events and artifact chunks, rebuilt from
+// storage records.
+type RunRecords struct {
+	// Events holds the run's events in ascending seq order, one per seq.
+	// Artifact events carry the commit fields in their payload.
+	Events []*types.EventEnvelope
+	// Chunks holds artifact chunks, ordered by artifact_id then chunk seq,
+	// one per (artifact_id, seq).
+	Chunks []*types.ArtifactChunk
+	// Source and Category are the partition keys of the first record read.
+	Source   string
+	Category string
+}
+
+// RunPartitionReader reads one run's records from a read Dataset.
+type RunPartitionReader struct {
+	ds    lode.Dataset
+	runID string
+}
+
+// NewRunPartitionReader creates a reader for runID's records in ds.
+func NewRunPartitionReader(ds lode.Dataset, runID string) *RunPartitionReader {
+	return &RunPartitionReader{ds: ds, runID: runID}
+}
+
+// ReadRunRecords reads every event and chunk record of the run. Metrics
+// records are skipped. Records duplicated by at-least-once retries are
+// collapsed, keeping the first copy. Returns ErrNoRunRecords if none exist.
+func (r *RunPartitionReader) ReadRunRecords(ctx context.Context) (*RunRecords, error) {
+	snapshots, err := r.ds.Snapshots(ctx)
+	if err != nil {
+		return nil, WrapReadError(err, "quarry/snapshots")
+	}
+
+	out := &RunRecords{}
+	seenEvents := make(map[int64]bool)
+	seenChunks := make(map[string]bool)
+	for _, snap := range snapshots {
+		if isMetricsSnapshot(snap) || !snapshotMatchesFilter(snap, "run_id", r.runID) {
+			continue
+		}
+		data, err := r.ds.Read(ctx, snap.ID)
+		if err != nil {
+			return nil, WrapReadError(err, fmt.Sprintf("quarry/snapshot/%s", snap.ID))
+		}
+		for _, item := range data {
+			record, ok := item.(map[string]any)
+			if !ok || toString(record["run_id"]) != r.runID {
+				continue
+			}
+			if out.Source == "" {
+				out.Source = toString(record["source"])
+				out.Category = toString(record["category"])
+			}
+			switch toString(record["record_kind"]) {
+			case RecordKindEvent, RecordKindArtifactEvent:
+				e := eventFromRecord(record)
+				if seenEvents[e.Seq] {
+					continue
+				}
+				seenEvents[e.Seq] = true
+				out.Events = append(out.Events, e)
+			case RecordKindArtifactChunk:
+				c, err := chunkFromRecord(record)
+				if err != nil {
+					return nil, fmt.Errorf("snapshot %s: %w", snap.ID, err)
+				}
+				key := fmt.Sprintf("%s/%d", c.ArtifactID, c.Seq)
+				if seenChunks[key] {
+					continue
+				}
+				seenChunks[key] = true
+				out.Chunks = append(out.Chunks, c)
+			}
+		}
+	}
+	if len(out.Events) == 0 && len(out.Chunks) == 0 {
+		return nil, fmt.Errorf("run %s: %w", r.runID, ErrNoRunRecords)
+	}
+
+	sort.Slice(out.Events, func(i, j int) bool { return out.Events[i].Seq < out.Events[j].Seq })
+	sort.Slice(out.Chunks, func(i, j int) bool {
+		if out.Chunks[i].ArtifactID != out.Chunks[j].ArtifactID {
+			return out.Chunks[i].ArtifactID < out.Chunks[j].ArtifactID
+		}
+		return out.Chunks[i].Seq < out.Chunks[j].Seq
+	})
+	return out, nil
+}
+
+// eventFromRecord rebuilds an event envelope from an event or artifact
+// commit record. Artifact commit fields are restored into the payload.
+func eventFromRecord(record map[string]any) *types.EventEnvelope {
+	e := &types.EventEnvelope{
+		ContractVersion: toString(record["contract_version"]),
+		EventID:         toString(record["event_id"]),
+		RunID:           toString(record["run_id"]),
+		Seq:             toInt64Any(record["seq"]),
+		Type:            types.EventType(toString(record["type"])),
+		Ts:              toString(record["ts"]),
+		Attempt:         int(toInt64Any(record["attempt"])),
+	}
+	if v, ok := record["job_id"].(string); ok {
+		e.JobID = &v
+	}
+	if v, ok := record["parent_run_id"].(string); ok {
+		e.ParentRunID = &v
+	}
+
+	if toString(record["record_kind"]) != RecordKindArtifactEvent {
+		e.Payload, _ = record["payload"].(map[string]any)
+		return e
+	}
+	e.Type = types.EventTypeArtifact
+	e.Payload = map[string]any{
+		"artifact_id":  toString(record["artifact_id"]),
+		"name":         toString(record["name"]),
+		"content_type": toString(record["content_type"]),
+		"size_bytes":   float64(toInt64Any(record["size_bytes"])),
+	}
+	if v, ok := record["declared_content_type"].(string); ok {
+		e.Payload["declared_content_type"] = v
+	}
+	if v := toString(record["retention_class"]); v != "" {
+		e.Payload["retention_class"] = v
+	}
+	if v := toInt64Any(record["ttl_seconds"]); v > 0 {
+		e.Payload["ttl_seconds"] = v
+	}
+	return e
+}
+
+// chunkFromRecord rebuilds an artifact chunk from a chunk record. Data is
+// base64 text after a JSON round-trip.
+func chunkFromRecord(record map[string]any) (*types.ArtifactChunk, error) {
+	c := &types.ArtifactChunk{
+		ArtifactID: toString(record["artifact_id"]),
+		Seq:        toInt64Any(record["seq"]),
+	}
+	c.IsLast, _ = record["is_last"].(bool)
+	switch data := record["data"].(type) {
+	case []byte:
+		c.Data = data
+	case string:
+		decoded, err := base64.StdEncoding.DecodeString(data)
+		if err != nil {
+			return nil, fmt.Errorf("artifact %s chunk %d: invalid data: %w", c.ArtifactID, c.Seq, err)
+		}
+		c.Data = decoded
+	}
+	return c, nil
+}
@@ -0,0 +1,105 @@
+package lode
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pithecene-io/lode/lode"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+func TestRunPartitionReader_ReadRunRecords(t *testing.T) {
+	store := lode.NewMemory()
+	cfg := Config{
+		Dataset:  "quarry",
+		Source:   "src",
+		Category: "cat",
+		Day:      "2026-02-03",
+		RunID:    "run-replay",
+		Policy:   "strict",
+	}
+	client, err := NewLodeClientWithFactory(cfg, sharedFactory(store))
+	if err != nil {
+		t.Fatalf("NewLodeClientWithFactory: %v", err)
+	}
+	ctx := t.Context()
+
+	event := func(seq int64, typ types.EventType, payload map[string]any) *types.EventEnvelope {
+		return &types.EventEnvelope{
+			ContractVersion: "1.0.0", EventID: "evt", RunID: cfg.RunID, Seq: seq,
+			Type: typ, Ts: "2026-02-03T12:00:00Z", Payload: payload, Attempt: 1,
+		}
+	}
+	// Written out of order, in separate snapshots, with a duplicated batch
+	if err := client.WriteEvents(ctx, cfg.Dataset, cfg.RunID, []*types.EventEnvelope{
+		event(2, types.EventTypeItem, map[string]any{"n": float64(2)}),
+	}); err != nil {
+		t.Fatalf("WriteEvents: %v", err)
+	}
+	if err := client.WriteEvents(ctx, cfg.Dataset, cfg.RunID, []*types.EventEnvelope{
+		event(1, types.EventTypeItem, map[string]any{"n": float64(1)}),
+		event(2, types.EventTypeItem, map[string]any{"n": float64(2)}),
+	}); err != nil {
+		t.Fatalf("WriteEvents: %v", err)
+	}
+	if err := client.WriteChunks(ctx, cfg.Dataset, cfg.RunID, []*types.ArtifactChunk{
+		{ArtifactID: "art-1", Seq: 2, IsLast: true, Data: []byte("world")},
+		{ArtifactID: "art-1", Seq: 1, Data: []byte("hello ")},
+	}); err != nil {
+		t.Fatalf("WriteChunks: %v", err)
+	}
+	if err := client.WriteEvents(ctx, cfg.Dataset, cfg.RunID, []*types.EventEnvelope{
+		event(3, types.EventTypeArtifact, map[string]any{
+			"artifact_id": "art-1", "name": "page.txt", "content_type": "text/plain", "size_bytes": float64(11),
+		}),
+	}); err != nil {
+		t.Fatalf("WriteEvents: %v", err)
+	}
+
+	ds, err := NewReadDataset("quarry", sharedFactory(store))
+	if err != nil {
+		t.Fatalf("NewReadDataset: %v", err)
+	}
+	records, err := NewRunPartitionReader(ds, cfg.RunID).ReadRunRecords(ctx)
+	if err != nil {
+		t.Fatalf("ReadRunRecords: %v", err)
+	}
+
+	if records.Source != "src" || records.Category != "cat" {
+		t.Errorf("partition keys = %s/%s, want src/cat", records.Source, records.Category)
+	}
+	if len(records.Events) != 3 {
+		t.Fatalf("got %d events, want 3 (duplicate collapsed)", len(records.Events))
+	}
+	for i, e := range records.Events {
+		if e.Seq != int64(i+1) {
+			t.Errorf("event %d seq = %d, want %d", i, e.Seq, i+1)
+		}
+	}
+	if records.Events[0].Payload["n"] != float64(1) {
+		t.Errorf("event payload = %v", records.Events[0].Payload)
+	}
+	art := records.Events[2]
+	if art.Type != types.EventTypeArtifact || art.Payload["artifact_id"] != "art-1" || art.Payload["size_bytes"] != float64(11) {
+		t.Errorf("artifact event = %s %v", art.Type, art.Payload)
+	}
+
+	if len(records.Chunks) != 2 {
+		t.Fatalf("got %d chunks, want 2", len(records.Chunks))
+	}
+	if string(records.Chunks[0].Data)+string(records.Chunks[1].Data) != "hello world" || !records.Chunks[1].IsLast {
+		t.Errorf("chunks not restored in seq order: %q %q", records.Chunks[0].Data, records.Chunks[1].Data)
+	}
+}
+
+func TestRunPartitionReader_NoRecords(t *testing.T) {
+	ds, err := NewReadDataset("quarry", sharedFactory(lode.NewMemory()))
+	if err != nil {
+		t.Fatalf("NewReadDataset: %v", err)
+	}
+	_, err = NewRunPartitionReader(ds, "run-missing").ReadRunRecords(t.Context())
+	if !errors.Is(err, ErrNoRunRecords) {
+		t.Errorf("err = %v, want ErrNoRunRecords", err)
+	}
+}
@@ -6,6 +6,7 @@ package lode
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/pithecene-io/quarry/metrics"
@@ -15,10 +16,28 @@ import (
 
 // DeriveDay computes the partition day from run start time.
 // Format: YYYY-MM-DD in UTC per CONTRACT_LODE.md.
+// Callers read startTime once from their clock.Clock and reuse it, so every
+// consumer of the day agrees even when a run starts at UTC midnight.
 func DeriveDay(startTime time.Time) string {
 	return startTime.UTC().Format("2006-01-02")
 }
 
+// DeriveHour computes the partition hour from run start time.
+// Format: HH (00-23) in UTC, from the same instant as DeriveDay so the
+// hour always falls within the partition day.
+func DeriveHour(startTime time.Time) string {
+	return startTime.UTC().Format("15")
+}
+
+// ValidateDay checks that day is a calendar date in the partition day
+// format (YYYY-MM-DD), for explicit day overrides.
+func ValidateDay(day string) error {
+	if _, err := time.Parse("2006-01-02", day); err != nil {
+		return fmt.Errorf("day must be a YYYY-MM-DD date, got %q", day)
+	}
+	return nil
+}
+
 // DefaultDataset is the default Lode dataset name.
 const DefaultDataset = "quarry"
 
@@ -27,16 +46,31 @@ const DefaultDataset = "quarry"
 type Config struct {
 	// Dataset is the Lode dataset ID (default: "quarry", overridable via --storage-dataset).
 	Dataset string
+	// Tenant, when set, is prepended to the partition layout as tenant=<id>
+	// and recorded on every record. Must pass ValidateTenant.
+	Tenant string
 	// Source is the partition key for origin system/provider.
 	Source string
 	// Category is the partition key for logical data type.
 	Category string
 	// Day is the partition key derived from run start time (YYYY-MM-DD UTC).
 	Day string
+	// Hour is the partition hour derived from the same start time as Day
+	// (HH UTC). Empty unless hourly partitions are enabled, in which case
+	// PartitionTemplate must carry an hour segment (see WithHour).
+	Hour string
 	// RunID is the partition key for run identifier.
 	RunID string
 	// Policy is the ingestion policy name (e.g. "strict", "buffered").
 	Policy string
+	// PartitionTemplate overrides the Hive partition layout
+	// (nil: DefaultPartitionTemplate). event_type is always the last key.
+	PartitionTemplate *PartitionTemplate
+	// PartitionManifest enables the write log and sha256 data file checksums
+	// behind WritePartitionManifest (_manifest.json).
+	PartitionManifest bool
+	// Format selects the item event encoding (empty: FormatJSONL).
+	Format StorageFormat
 }
 
 // Sink is a Lode-backed implementation of policy.Sink.
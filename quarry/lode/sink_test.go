@@ -10,6 +10,19 @@ import (
 	"github.com/pithecene-io/quarry/types"
 )
 
+func TestValidateDay(t *testing.T) {
+	for _, day := range []string{"2026-02-03", "2024-02-29"} {
+		if err := ValidateDay(day); err != nil {
+			t.Errorf("ValidateDay(%q) = %v, want nil", day, err)
+		}
+	}
+	for _, day := range []string{"", "2026-2-3", "2026-02-30", "2026/02/03", "2026-02-03T00:00:00Z"} {
+		if err := ValidateDay(day); err == nil {
+			t.Errorf("ValidateDay(%q) = nil, want error", day)
+		}
+	}
+}
+
 func TestDeriveDay(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -43,6 +56,17 @@ func TestDeriveDay(t *testing.T) {
 	}
 }
 
+func TestDeriveHour(t *testing.T) {
+	// 22:30 EST is 03:30 UTC the next day: hour and day agree on the instant
+	start := time.Date(2026, 2, 3, 22, 30, 0, 0, time.FixedZone("EST", -5*3600))
+	if got := DeriveHour(start); got != "03" {
+		t.Errorf("DeriveHour() = %q, want 03", got)
+	}
+	if got := DeriveDay(start); got != "2026-02-04" {
+		t.Errorf("DeriveDay() = %q, want 2026-02-04", got)
+	}
+}
+
 func TestSink_WriteEvents(t *testing.T) {
 	client := NewStubClient()
 	sink := NewSink(Config{
@@ -0,0 +1,226 @@
+package lode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// DirLister lists the immediate child directories of a key prefix without
+// enumerating the objects below them. Used by ListSources to walk the
+// partition tree.
+type DirLister interface {
+	// ListDirs returns the names of the child directories of prefix
+	// (a slash-terminated store key, e.g. "datasets/quarry/partitions/").
+	// A missing prefix has no children.
+	ListDirs(ctx context.Context, prefix string) ([]string, error)
+}
+
+// NewFSDirLister creates a DirLister over a filesystem store root.
+func NewFSDirLister(rootPath string) DirLister {
+	return fsDirLister{root: rootPath}
+}
+
+// fsDirLister reads one directory per ListDirs call.
+type fsDirLister struct {
+	root string
+}
+
+func (l fsDirLister) ListDirs(_ context.Context, prefix string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(l.root, filepath.FromSlash(prefix)))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var dirs []string
+	for _, e := range entries {
+		if e.IsDir() {
+			dirs = append(dirs, e.Name())
+		}
+	}
+	return dirs, nil
+}
+
+// NewS3DirLister creates a DirLister over an S3 bucket/prefix.
+// Uses AWS SDK default credential chain (env vars, shared config, IAM role).
+func NewS3DirLister(s3cfg S3Config) (DirLister, error) {
+	if err := s3cfg.Validate(); err != nil {
+		return nil, err
+	}
+	awsConfig, err := config.LoadDefaultConfig(context.Background(), s3cfg.loadOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return newS3DirLister(s3.NewFromConfig(awsConfig, s3cfg.clientOptions()...), s3cfg), nil
+}
+
+// s3ListAPI is the subset of the S3 client used to list directories.
+type s3ListAPI interface {
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+}
+
+// s3DirLister lists common prefixes with a "/" delimiter, so each call
+// costs one request per 1000 child directories regardless of how many
+// objects sit below them.
+type s3DirLister struct {
+	api    s3ListAPI
+	bucket string
+	prefix string // store key prefix, with trailing slash if non-empty
+}
+
+// newS3DirLister lists under the same key prefix the Lode S3 store
+// writes to.
+func newS3DirLister(api s3ListAPI, s3cfg S3Config) s3DirLister {
+	prefix := s3cfg.Prefix
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return s3DirLister{api: api, bucket: s3cfg.Bucket, prefix: prefix}
+}
+
+func (l s3DirLister) ListDirs(ctx context.Context, prefix string) ([]string, error) {
+	full := l.prefix + prefix
+	input := &s3.ListObjectsV2Input{
+		Bucket:    aws.String(l.bucket),
+		Prefix:    aws.String(full),
+		Delimiter: aws.String("/"),
+	}
+	var dirs []string
+	for {
+		out, err := l.api.ListObjectsV2(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, cp := range out.CommonPrefixes {
+			name := strings.TrimSuffix(strings.TrimPrefix(aws.ToString(cp.Prefix), full), "/")
+			if name != "" {
+				dirs = append(dirs, name)
+			}
+		}
+		if !aws.ToBool(out.IsTruncated) || out.NextContinuationToken == nil {
+			return dirs, nil
+		}
+		input.ContinuationToken = out.NextContinuationToken
+	}
+}
+
+// ListSourcesOptions selects how deep ListSources resolves each source.
+type ListSourcesOptions struct {
+	// Categories also resolves the category= values under each source.
+	Categories bool
+	// Days also resolves the day= values; implies Categories.
+	Days bool
+}
+
+// SourceListing is one distinct partition prefix found by ListSources.
+// Category and Day are empty unless requested, or when the partition
+// layout has no such key.
+type SourceListing struct {
+	Source   string `json:"source"`
+	Category string `json:"category,omitempty"`
+	Day      string `json:"day,omitempty"`
+}
+
+// ListSources enumerates the distinct source= values (and, per opts,
+// category= and day= values) of a dataset by walking its partition
+// directories. No event data is read.
+//
+// The walk follows Hive key=value directories from the partition root and
+// stops once every requested key has been seen on the path, or at run_id=,
+// so it works for custom partition templates and tenant prefixes as well
+// as the default source/category/day/run_id layout. Results are sorted and
+// deduplicated across tenants.
+func ListSources(ctx context.Context, lister DirLister, dataset string, opts ListSourcesOptions) ([]SourceListing, error) {
+	want := []string{"source"}
+	if opts.Categories || opts.Days {
+		want = append(want, "category")
+	}
+	if opts.Days {
+		want = append(want, "day")
+	}
+
+	root := fmt.Sprintf("datasets/%s/partitions/", dataset)
+	seen := make(map[SourceListing]struct{})
+	if err := walkPartitionDirs(ctx, lister, root, want, map[string]string{}, seen); err != nil {
+		return nil, WrapReadError(err, root)
+	}
+
+	listings := make([]SourceListing, 0, len(seen))
+	for l := range seen {
+		listings = append(listings, l)
+	}
+	sort.Slice(listings, func(i, j int) bool {
+		a, b := listings[i], listings[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Category != b.Category {
+			return a.Category < b.Category
+		}
+		return a.Day < b.Day
+	})
+	return listings, nil
+}
+
+// walkPartitionDirs descends prefix until found holds every wanted key,
+// then records the listing. Paths that end without a source are skipped.
+func walkPartitionDirs(ctx context.Context, lister DirLister, prefix string, want []string, found map[string]string, seen map[SourceListing]struct{}) error {
+	if hasAllKeys(found, want) {
+		seen[SourceListing{Source: found["source"], Category: found["category"], Day: found["day"]}] = struct{}{}
+		return nil
+	}
+	dirs, err := lister.ListDirs(ctx, prefix)
+	if err != nil {
+		return err
+	}
+	for _, dir := range dirs {
+		key, raw, ok := strings.Cut(dir, "=")
+		if !ok || key == "event_type" {
+			continue
+		}
+		value, err := url.PathUnescape(raw)
+		if err != nil {
+			value = raw
+		}
+		if key == "run_id" {
+			// Leaf partition: the layout lacks the remaining keys
+			if _, ok := found["source"]; ok {
+				seen[SourceListing{Source: found["source"], Category: found["category"], Day: found["day"]}] = struct{}{}
+			}
+			continue
+		}
+		next := found
+		if slices.Contains(want, key) {
+			next = maps.Clone(found)
+			next[key] = value
+		}
+		if err := walkPartitionDirs(ctx, lister, prefix+dir+"/", want, next, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasAllKeys reports whether found has a value for every key in want.
+func hasAllKeys(found map[string]string, want []string) bool {
+	for _, k := range want {
+		if _, ok := found[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
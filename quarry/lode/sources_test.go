@@ -0,0 +1,190 @@
+package lode
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// sourceTreeDirs are partition directories (relative to the dataset
+// partition root) covering the default layout, a tenant prefix, an escaped
+// value, and non-partition directories that must be ignored.
+var sourceTreeDirs = []string{
+	"source=shop/category=products/day=2026-03-01/run_id=r1/event_type=item",
+	"source=shop/category=products/day=2026-03-02/run_id=r2/event_type=item",
+	"source=shop/category=reviews/day=2026-03-01/run_id=r3/event_type=item",
+	"source=news/category=articles/day=2026-03-01/run_id=r4/event_type=log",
+	"tenant=acme/source=shop/category=products/day=2026-03-03/run_id=r5/event_type=item",
+	"source=my%20site/category=pages/day=2026-03-01/run_id=r6/files",
+	"_tmp",
+}
+
+func writeSourceTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, d := range sourceTreeDirs {
+		if err := os.MkdirAll(filepath.Join(root, "datasets", "quarry", "partitions", filepath.FromSlash(d)), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+	}
+	return root
+}
+
+func TestListSources_FS(t *testing.T) {
+	lister := NewFSDirLister(writeSourceTree(t))
+
+	tests := []struct {
+		name string
+		opts ListSourcesOptions
+		want []SourceListing
+	}{
+		{
+			name: "sources",
+			want: []SourceListing{{Source: "my site"}, {Source: "news"}, {Source: "shop"}},
+		},
+		{
+			name: "categories",
+			opts: ListSourcesOptions{Categories: true},
+			want: []SourceListing{
+				{Source: "my site", Category: "pages"},
+				{Source: "news", Category: "articles"},
+				{Source: "shop", Category: "products"},
+				{Source: "shop", Category: "reviews"},
+			},
+		},
+		{
+			name: "days",
+			opts: ListSourcesOptions{Days: true},
+			want: []SourceListing{
+				{Source: "my site", Category: "pages", Day: "2026-03-01"},
+				{Source: "news", Category: "articles", Day: "2026-03-01"},
+				{Source: "shop", Category: "products", Day: "2026-03-01"},
+				{Source: "shop", Category: "products", Day: "2026-03-02"},
+				{Source: "shop", Category: "products", Day: "2026-03-03"},
+				{Source: "shop", Category: "reviews", Day: "2026-03-01"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ListSources(t.Context(), lister, "quarry", tt.opts)
+			if err != nil {
+				t.Fatalf("ListSources: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListSources_CustomTemplate(t *testing.T) {
+	root := t.TempDir()
+	for _, d := range []string{
+		"year=2026/month=03/day=01/source=shop/run_id=r1/event_type=item",
+		"year=2026/month=03/day=02/source=news/run_id=r2/event_type=item",
+	} {
+		if err := os.MkdirAll(filepath.Join(root, "datasets", "quarry", "partitions", filepath.FromSlash(d)), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+	}
+
+	got, err := ListSources(t.Context(), NewFSDirLister(root), "quarry", ListSourcesOptions{Categories: true})
+	if err != nil {
+		t.Fatalf("ListSources: %v", err)
+	}
+	want := []SourceListing{{Source: "news"}, {Source: "shop"}} // layout has no category
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestListSources_EmptyDataset(t *testing.T) {
+	got, err := ListSources(t.Context(), NewFSDirLister(t.TempDir()), "quarry", ListSourcesOptions{})
+	if err != nil || len(got) != 0 {
+		t.Errorf("ListSources(empty) = %+v, %v; want no sources", got, err)
+	}
+}
+
+// fakeS3ListAPI answers delimiter listings from a flat key set, paging
+// after pageSize common prefixes.
+type fakeS3ListAPI struct {
+	keys     []string
+	pageSize int
+	calls    []string // requested prefixes
+}
+
+func (f *fakeS3ListAPI) ListObjectsV2(_ context.Context, in *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	prefix := aws.ToString(in.Prefix)
+	f.calls = append(f.calls, prefix)
+	if aws.ToString(in.Delimiter) != "/" {
+		panic("expected a delimiter listing")
+	}
+	set := map[string]struct{}{}
+	for _, k := range f.keys {
+		rest, ok := strings.CutPrefix(k, prefix)
+		if !ok {
+			continue
+		}
+		if dir, _, ok := strings.Cut(rest, "/"); ok {
+			set[prefix+dir+"/"] = struct{}{}
+		}
+	}
+	var prefixes []string
+	for p := range set {
+		prefixes = append(prefixes, p)
+	}
+	sort.Strings(prefixes)
+
+	start := 0
+	if in.ContinuationToken != nil {
+		for i, p := range prefixes {
+			if p == *in.ContinuationToken {
+				start = i
+			}
+		}
+	}
+	out := &s3.ListObjectsV2Output{}
+	end := min(start+f.pageSize, len(prefixes))
+	for _, p := range prefixes[start:end] {
+		out.CommonPrefixes = append(out.CommonPrefixes, s3types.CommonPrefix{Prefix: aws.String(p)})
+	}
+	if end < len(prefixes) {
+		out.IsTruncated = aws.Bool(true)
+		out.NextContinuationToken = aws.String(prefixes[end])
+	}
+	return out, nil
+}
+
+func TestListSources_S3(t *testing.T) {
+	api := &fakeS3ListAPI{pageSize: 1}
+	for _, d := range sourceTreeDirs {
+		api.keys = append(api.keys, "crawl/datasets/quarry/partitions/"+d+"/data.jsonl")
+	}
+	lister := newS3DirLister(api, S3Config{Bucket: "bucket", Prefix: "crawl"})
+
+	got, err := ListSources(t.Context(), lister, "quarry", ListSourcesOptions{})
+	if err != nil {
+		t.Fatalf("ListSources: %v", err)
+	}
+	want := []SourceListing{{Source: "my site"}, {Source: "news"}, {Source: "shop"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	for _, p := range api.calls {
+		if strings.Contains(p, "category=") {
+			t.Errorf("listed below the source level: %s", p)
+		}
+	}
+	if api.calls[0] != "crawl/datasets/quarry/partitions/" {
+		t.Errorf("first listing prefix = %q", api.calls[0])
+	}
+}
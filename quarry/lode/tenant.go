@@ -0,0 +1,61 @@
+package lode
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"text/template"
+)
+
+// TenantPartitionKey is the partition key prepended to the layout when
+// Config.Tenant is set: datasets/<dataset>/partitions/tenant=<id>/...
+const TenantPartitionKey = "tenant"
+
+// MaxTenantLength bounds a tenant ID.
+const MaxTenantLength = 64
+
+// ErrInvalidTenant is returned when a tenant ID is not path-safe or does not
+// match the configured allowlist pattern.
+var ErrInvalidTenant = errors.New("invalid tenant")
+
+// tenantPattern is the built-in safety check every tenant ID must pass,
+// independent of any allowlist: path-safe, no escaping needed.
+var tenantPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// tenantSegment renders the tenant partition value.
+var tenantSegment = template.Must(template.New(TenantPartitionKey).Option("missingkey=error").Parse("{{.Tenant}}"))
+
+// CompileTenantPattern compiles a tenant allowlist pattern anchored to
+// match the whole ID, for ValidateTenant.
+func CompileTenantPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
+
+// ValidateTenant checks a tenant ID against the built-in safety pattern and,
+// if allow is non-nil, against the allowlist (see CompileTenantPattern).
+func ValidateTenant(tenant string, allow *regexp.Regexp) error {
+	if tenant == "" {
+		return fmt.Errorf("%w: tenant must not be empty", ErrInvalidTenant)
+	}
+	if len(tenant) > MaxTenantLength {
+		return fmt.Errorf("%w: %q exceeds %d characters", ErrInvalidTenant, tenant, MaxTenantLength)
+	}
+	if !tenantPattern.MatchString(tenant) {
+		return fmt.Errorf("%w: %q must match %s", ErrInvalidTenant, tenant, tenantPattern)
+	}
+	if allow != nil {
+		if !allow.MatchString(tenant) {
+			return fmt.Errorf("%w: %q does not match allowlist %s", ErrInvalidTenant, tenant, allow)
+		}
+	}
+	return nil
+}
+
+// withTenant returns a copy of t with the tenant segment prepended.
+func (t *PartitionTemplate) withTenant() *PartitionTemplate {
+	return &PartitionTemplate{
+		raw:      TenantPartitionKey + "={{.Tenant}}/" + t.raw,
+		keys:     append([]string{TenantPartitionKey}, t.keys...),
+		segments: append([]*template.Template{tenantSegment}, t.segments...),
+	}
+}
@@ -0,0 +1,131 @@
+package lode
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+func TestValidateTenant(t *testing.T) {
+	allow, err := CompileTenantPattern(`acme|globex-[0-9]+`)
+	if err != nil {
+		t.Fatalf("CompileTenantPattern: %v", err)
+	}
+	// Leftmost-first alternation: an unanchored a|ab matches only "a" of "ab"
+	prefixAlt, err := CompileTenantPattern(`a|ab`)
+	if err != nil {
+		t.Fatalf("CompileTenantPattern: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		tenant string
+		allow  *regexp.Regexp
+		ok     bool
+	}{
+		{name: "simple", tenant: "acme", ok: true},
+		{name: "underscore and dash", tenant: "acme_eu-1", ok: true},
+		{name: "empty", tenant: ""},
+		{name: "path separator", tenant: "acme/../globex"},
+		{name: "leading dash", tenant: "-acme"},
+		{name: "equals sign", tenant: "tenant=acme"},
+		{name: "too long", tenant: strings.Repeat("a", MaxTenantLength+1)},
+		{name: "allowlisted", tenant: "globex-42", allow: allow, ok: true},
+		{name: "allowlist partial match rejected", tenant: "acme-evil", allow: allow},
+		{name: "not allowlisted", tenant: "initech", allow: allow},
+		{name: "alternation matches whole ID", tenant: "ab", allow: prefixAlt, ok: true},
+		{name: "alternation rejects longer ID", tenant: "abc", allow: prefixAlt},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTenant(tt.tenant, tt.allow)
+			if tt.ok && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrInvalidTenant) {
+				t.Errorf("expected ErrInvalidTenant, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPartitionTemplate_TenantPrefix(t *testing.T) {
+	cfg := Config{Tenant: "acme", Source: "src", Category: "cat", Day: "2026-02-08", RunID: "run-1"}
+
+	got, err := cfg.PartitionPath()
+	if err != nil {
+		t.Fatalf("PartitionPath failed: %v", err)
+	}
+	if want := "tenant=acme/source=src/category=cat/day=2026-02-08/run_id=run-1"; got != want {
+		t.Errorf("tenant path = %q, want %q", got, want)
+	}
+
+	pt, err := ParsePartitionTemplate("year={{.Year}}/source={{.Source}}/run_id={{.RunID}}")
+	if err != nil {
+		t.Fatalf("ParsePartitionTemplate failed: %v", err)
+	}
+	cfg.PartitionTemplate = pt
+	if got, _ = cfg.PartitionPath(); got != "tenant=acme/year=2026/source=src/run_id=run-1" {
+		t.Errorf("tenant custom path = %q", got)
+	}
+	if keys := cfg.hiveKeys(); keys[0] != TenantPartitionKey || keys[len(keys)-1] != "event_type" {
+		t.Errorf("hive keys = %v, want tenant first and event_type last", keys)
+	}
+	if pt.Keys()[0] == TenantPartitionKey {
+		t.Error("resolving with a tenant must not mutate the configured template")
+	}
+
+	columns, err := cfg.partitionColumns()
+	if err != nil {
+		t.Fatalf("partitionColumns failed: %v", err)
+	}
+	if columns[TenantPartitionKey] != "acme" {
+		t.Errorf("tenant column = %q, want acme", columns[TenantPartitionKey])
+	}
+}
+
+func TestParsePartitionTemplate_TenantKeyReserved(t *testing.T) {
+	_, err := ParsePartitionTemplate("tenant={{.Source}}/run_id={{.RunID}}")
+	if err == nil || !strings.Contains(err.Error(), "Config.Tenant") {
+		t.Errorf("expected reserved tenant key error, got %v", err)
+	}
+}
+
+func TestLodeClient_TenantLayout(t *testing.T) {
+	root := t.TempDir()
+	cfg := Config{Dataset: "quarry", Tenant: "acme", Source: "src", Category: "cat", Day: "2026-02-08", RunID: "run-1", Policy: "strict"}
+
+	client, err := NewLodeClient(cfg, root)
+	if err != nil {
+		t.Fatalf("NewLodeClient failed: %v", err)
+	}
+	events := []*types.EventEnvelope{{
+		ContractVersion: "1.0.0",
+		EventID:         "evt-1",
+		RunID:           "run-1",
+		Seq:             1,
+		Type:            types.EventTypeItem,
+		Ts:              "2026-02-08T12:00:00Z",
+		Payload:         map[string]any{"k": "v"},
+		Attempt:         1,
+	}}
+	if err := client.WriteEvents(t.Context(), cfg.Dataset, cfg.RunID, events); err != nil {
+		t.Fatalf("WriteEvents failed: %v", err)
+	}
+	if err := client.PutFile(t.Context(), "page.html", "text/html", []byte("<html/>")); err != nil {
+		t.Fatalf("PutFile failed: %v", err)
+	}
+
+	partition := filepath.Join(root, "datasets", "quarry", "partitions", "tenant=acme", "source=src", "category=cat", "day=2026-02-08", "run_id=run-1")
+	if _, err := os.Stat(filepath.Join(partition, "event_type=item")); err != nil {
+		t.Errorf("expected event partition under tenant prefix: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(partition, "files", "page.html")); err != nil {
+		t.Errorf("expected sidecar file under tenant prefix: %v", err)
+	}
+}
@@ -5,7 +5,10 @@
 // policy.Stats at run completion rather than recorded live, avoiding double-counting.
 package metrics
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
 
 // Snapshot is an immutable point-in-time view of all contract-required metrics.
 // Returned by Collector.Snapshot(). Safe to read concurrently after creation.
@@ -15,6 +18,7 @@ type Snapshot struct {
 	RunsCompleted int64
 	RunsFailed    int64
 	RunsCrashed   int64
+	RunsByReason  map[string]int64 // run outcomes by types.OutcomeReason
 
 	// Ingestion (absorbed from policy.Stats at run completion)
 	EventsReceived  int64
@@ -22,17 +26,38 @@ type Snapshot struct {
 	EventsDropped   int64
 	DroppedByType   map[string]int64
 	FlushTriggers   map[string]int64 // streaming policy per-trigger flush counts; nil for non-streaming
+	FlushLatency    Histogram        // per-flush wall time of the ingestion policy
+	Degradations    int64            // strict policy degradations to buffering (--strict-degrade-buffer)
 
 	// Executor
 	ExecutorLaunchSuccess int64
 	ExecutorLaunchFailure int64
 	ExecutorCrash         int64
 	IPCDecodeErrors       int64
+	SeqGaps               int64            // forward seq jumps tolerated under --allow-seq-gaps
+	ArtifactsDiscarded    int64            // artifacts skipped under --events-only
+	EventsDiscarded       int64            // events skipped under --artifacts-only
+	EventsSampledOut      int64            // item events skipped by --sample-rate
+	EventsFiltered        int64            // events dropped by --events-filter
+	LogFilteredByLevel    map[string]int64 // log events filtered by --log-min-level, by payload.level
+	TimeToFirstEvent      Histogram        // executor launch to first IPC frame, one observation per run
+
+	// Fan-out
+	EnqueuesDeduplicated int64 // enqueue events skipped as duplicates
+
+	// Proxy
+	ProxyRotations int64 // mid-run endpoint rotations requested by rotate_proxy
 
 	// Lode / Storage
-	LodeWriteSuccess int64
-	LodeWriteFailure int64
-	LodeWriteRetry   int64 // reserved for future use, always 0 in v0.3.0
+	LodeWriteSuccess  int64
+	LodeWriteFailure  int64
+	LodeWriteRetry    int64 // reserved for future use, always 0 in v0.3.0
+	LodeWritesSkipped int64 // S3 object writes skipped by --storage-s3-skip-existing
+
+	// Tee storage sinks (--storage-sink), keyed by sink label; nil without a tee
+	StorageSinkWrites    map[string]int64 // per-sink write calls
+	StorageSinkFailures  map[string]int64 // per-sink failed write calls
+	StorageSinkLatencyUs map[string]int64 // per-sink total write latency, microseconds
 
 	// Dimensions (informational, set at construction)
 	Policy         string
@@ -40,6 +65,10 @@ type Snapshot struct {
 	StorageBackend string
 	RunID          string
 	JobID          string
+	Labels         map[string]string // user run labels; nil if none
+	// ExecutorVersion is the version reported in the executor's run_result;
+	// empty if it reported none
+	ExecutorVersion string
 }
 
 // Collector accumulates metrics during a single run.
@@ -52,16 +81,36 @@ type Collector struct {
 	runsCompleted int64
 	runsFailed    int64
 	runsCrashed   int64
+	runsByReason  map[string]int64
 
 	// Executor
 	executorLaunchSuccess int64
 	executorLaunchFailure int64
 	executorCrash         int64
 	ipcDecodeErrors       int64
+	seqGaps               int64
+	artifactsDiscarded    int64
+	eventsDiscarded       int64
+	eventsSampledOut      int64
+	eventsFiltered        int64
+	logFiltered           map[string]int64
+	timeToFirstEvent      Histogram
+
+	// Fan-out
+	enqueuesDeduplicated int64
+
+	// Proxy
+	proxyRotations int64
 
 	// Lode / Storage
-	lodeWriteSuccess int64
-	lodeWriteFailure int64
+	lodeWriteSuccess  int64
+	lodeWriteFailure  int64
+	lodeWritesSkipped int64
+
+	// Tee storage sinks (allocated on first observation)
+	storageSinkWrites   map[string]int64
+	storageSinkFailures map[string]int64
+	storageSinkLatency  map[string]time.Duration
 
 	// Ingestion (set once via AbsorbPolicyStats)
 	eventsReceived  int64
@@ -69,6 +118,8 @@ type Collector struct {
 	eventsDropped   int64
 	droppedByType   map[string]int64
 	flushTriggers   map[string]int64
+	flushLatency    Histogram
+	degradations    int64
 
 	// Dimensions
 	policy         string
@@ -76,6 +127,9 @@ type Collector struct {
 	storageBackend string
 	runID          string
 	jobID          string
+	labels         map[string]string
+
+	executorVersion string
 }
 
 // NewCollector creates a Collector with dimension labels.
@@ -84,6 +138,8 @@ type Collector struct {
 func NewCollector(policy, executor, storageBackend, runID, jobID string) *Collector {
 	return &Collector{
 		droppedByType:  make(map[string]int64),
+		runsByReason:   make(map[string]int64),
+		logFiltered:    make(map[string]int64),
 		policy:         policy,
 		executor:       executor,
 		storageBackend: storageBackend,
@@ -92,6 +148,29 @@ func NewCollector(policy, executor, storageBackend, runID, jobID string) *Collec
 	}
 }
 
+// SetExecutorVersion records the version the executor reported.
+func (c *Collector) SetExecutorVersion(version string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.executorVersion = version
+	c.mu.Unlock()
+}
+
+// SetLabels attaches user run labels as additional dimensions.
+func (c *Collector) SetLabels(labels map[string]string) {
+	if c == nil || len(labels) == 0 {
+		return
+	}
+	c.mu.Lock()
+	c.labels = make(map[string]string, len(labels))
+	for k, v := range labels {
+		c.labels[k] = v
+	}
+	c.mu.Unlock()
+}
+
 // --- Run lifecycle ---
 
 // IncRunStarted records a run start.
@@ -134,6 +213,18 @@ func (c *Collector) IncRunCrashed() {
 	c.mu.Unlock()
 }
 
+// IncRunReason records a run outcome reason. Empty reasons are ignored.
+// The reason is string-typed to keep this package free of dependencies on
+// the types package.
+func (c *Collector) IncRunReason(reason string) {
+	if c == nil || reason == "" {
+		return
+	}
+	c.mu.Lock()
+	c.runsByReason[reason]++
+	c.mu.Unlock()
+}
+
 // --- Executor ---
 
 // IncExecutorLaunchSuccess records a successful executor launch.
@@ -176,6 +267,100 @@ func (c *Collector) IncIPCDecodeErrors() {
 	c.mu.Unlock()
 }
 
+// IncSeqGaps records a tolerated forward jump in event sequence numbers.
+func (c *Collector) IncSeqGaps() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.seqGaps++
+	c.mu.Unlock()
+}
+
+// IncArtifactsDiscarded records an artifact skipped by selective ingestion.
+func (c *Collector) IncArtifactsDiscarded() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.artifactsDiscarded++
+	c.mu.Unlock()
+}
+
+// IncEventsDiscarded records an event skipped by selective ingestion.
+func (c *Collector) IncEventsDiscarded() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.eventsDiscarded++
+	c.mu.Unlock()
+}
+
+// IncEventsSampledOut records an item event skipped by sampling.
+func (c *Collector) IncEventsSampledOut() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.eventsSampledOut++
+	c.mu.Unlock()
+}
+
+// IncEventsFiltered records an event dropped by the events filter.
+func (c *Collector) IncEventsFiltered() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.eventsFiltered++
+	c.mu.Unlock()
+}
+
+// IncLogFiltered records a log event filtered by --log-min-level.
+func (c *Collector) IncLogFiltered(level string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.logFiltered[level]++
+	c.mu.Unlock()
+}
+
+// ObserveTimeToFirstEvent records the time from executor launch to its
+// first IPC frame, a measure of cold-start cost.
+func (c *Collector) ObserveTimeToFirstEvent(d time.Duration) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.timeToFirstEvent.Observe(d)
+	c.mu.Unlock()
+}
+
+// IncProxyRotations records a proxy endpoint sent to the executor in
+// response to a rotate_proxy event.
+func (c *Collector) IncProxyRotations() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.proxyRotations++
+	c.mu.Unlock()
+}
+
+// --- Fan-out ---
+
+// IncEnqueuesDeduplicated records an enqueue event skipped as a duplicate.
+func (c *Collector) IncEnqueuesDeduplicated() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.enqueuesDeduplicated++
+	c.mu.Unlock()
+}
+
 // --- Lode / Storage ---
 // Lode counters are per-call, not per-record. A single WriteEvents call
 // with N events counts as 1 success. Per-event granularity is tracked
@@ -201,6 +386,37 @@ func (c *Collector) IncLodeWriteFailure() {
 	c.mu.Unlock()
 }
 
+// IncLodeWriteSkipped records an S3 object write skipped because the
+// object already exists with the same content (per-object, not per-call).
+func (c *Collector) IncLodeWriteSkipped() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.lodeWritesSkipped++
+	c.mu.Unlock()
+}
+
+// ObserveStorageSinkWrite records one write call of a tee storage sink:
+// its latency and whether it failed. Implements policy.TeeObserver.
+func (c *Collector) ObserveStorageSinkWrite(label string, latency time.Duration, err error) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	if c.storageSinkWrites == nil {
+		c.storageSinkWrites = make(map[string]int64)
+		c.storageSinkFailures = make(map[string]int64)
+		c.storageSinkLatency = make(map[string]time.Duration)
+	}
+	c.storageSinkWrites[label]++
+	if err != nil {
+		c.storageSinkFailures[label]++
+	}
+	c.storageSinkLatency[label] += latency
+	c.mu.Unlock()
+}
+
 // --- Ingestion (absorbed from policy.Stats) ---
 
 // AbsorbPolicyStats copies ingestion counters from policy.Stats into the collector.
@@ -231,6 +447,30 @@ func (c *Collector) AbsorbPolicyStats(totalEvents, persisted, dropped int64, dro
 	c.mu.Unlock()
 }
 
+// AbsorbFlushLatency copies the policy's flush latency histogram
+// (policy.Stats.FlushLatencyHistogram) into the collector. Called once after
+// run completion, alongside AbsorbPolicyStats.
+func (c *Collector) AbsorbFlushLatency(h Histogram) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.flushLatency = h
+	c.mu.Unlock()
+}
+
+// AbsorbPolicyDegradations copies the strict policy's degradation count
+// (policy.Stats.Degradations) into the collector. Called once after run
+// completion, alongside AbsorbPolicyStats.
+func (c *Collector) AbsorbPolicyDegradations(n int64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	c.degradations = n
+	c.mu.Unlock()
+}
+
 // --- Snapshot ---
 
 // Snapshot returns an immutable point-in-time view of all metrics.
@@ -248,6 +488,24 @@ func (c *Collector) Snapshot() Snapshot {
 		dropped[k] = v
 	}
 
+	logFiltered := make(map[string]int64, len(c.logFiltered))
+	for k, v := range c.logFiltered {
+		logFiltered[k] = v
+	}
+
+	reasons := make(map[string]int64, len(c.runsByReason))
+	for k, v := range c.runsByReason {
+		reasons[k] = v
+	}
+
+	var labels map[string]string
+	if c.labels != nil {
+		labels = make(map[string]string, len(c.labels))
+		for k, v := range c.labels {
+			labels[k] = v
+		}
+	}
+
 	var triggers map[string]int64
 	if c.flushTriggers != nil {
 		triggers = make(map[string]int64, len(c.flushTriggers))
@@ -256,31 +514,65 @@ func (c *Collector) Snapshot() Snapshot {
 		}
 	}
 
+	var sinkWrites, sinkFailures, sinkLatency map[string]int64
+	if c.storageSinkWrites != nil {
+		sinkWrites = make(map[string]int64, len(c.storageSinkWrites))
+		sinkFailures = make(map[string]int64, len(c.storageSinkWrites))
+		sinkLatency = make(map[string]int64, len(c.storageSinkWrites))
+		for k, v := range c.storageSinkWrites {
+			sinkWrites[k] = v
+			sinkFailures[k] = c.storageSinkFailures[k]
+			sinkLatency[k] = c.storageSinkLatency[k].Microseconds()
+		}
+	}
+
 	return Snapshot{
 		RunsStarted:   c.runsStarted,
 		RunsCompleted: c.runsCompleted,
 		RunsFailed:    c.runsFailed,
 		RunsCrashed:   c.runsCrashed,
+		RunsByReason:  reasons,
 
 		EventsReceived:  c.eventsReceived,
 		EventsPersisted: c.eventsPersisted,
 		EventsDropped:   c.eventsDropped,
 		DroppedByType:   dropped,
 		FlushTriggers:   triggers,
+		FlushLatency:    c.flushLatency,
+		Degradations:    c.degradations,
 
 		ExecutorLaunchSuccess: c.executorLaunchSuccess,
 		ExecutorLaunchFailure: c.executorLaunchFailure,
 		ExecutorCrash:         c.executorCrash,
 		IPCDecodeErrors:       c.ipcDecodeErrors,
+		SeqGaps:               c.seqGaps,
+		ArtifactsDiscarded:    c.artifactsDiscarded,
+		EventsDiscarded:       c.eventsDiscarded,
+		EventsSampledOut:      c.eventsSampledOut,
+		EventsFiltered:        c.eventsFiltered,
+		LogFilteredByLevel:    logFiltered,
+		TimeToFirstEvent:      c.timeToFirstEvent,
 
-		LodeWriteSuccess: c.lodeWriteSuccess,
-		LodeWriteFailure: c.lodeWriteFailure,
-		LodeWriteRetry:   0, // reserved for future use
+		EnqueuesDeduplicated: c.enqueuesDeduplicated,
+
+		ProxyRotations: c.proxyRotations,
+
+		LodeWriteSuccess:  c.lodeWriteSuccess,
+		LodeWriteFailure:  c.lodeWriteFailure,
+		LodeWriteRetry:    0, // reserved for future use
+		LodeWritesSkipped: c.lodeWritesSkipped,
+
+		StorageSinkWrites:    sinkWrites,
+		StorageSinkFailures:  sinkFailures,
+		StorageSinkLatencyUs: sinkLatency,
 
 		Policy:         c.policy,
 		Executor:       c.executor,
 		StorageBackend: c.storageBackend,
 		RunID:          c.runID,
 		JobID:          c.jobID,
+		Labels:         labels,
+
+		ExecutorVersion: c.executorVersion,
 	}
 }
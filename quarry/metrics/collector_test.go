@@ -1,8 +1,10 @@
 package metrics
 
 import (
+	"errors"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestCollector_IncrementMethods(t *testing.T) {
@@ -20,6 +22,7 @@ func TestCollector_IncrementMethods(t *testing.T) {
 	c.IncIPCDecodeErrors()
 	c.IncIPCDecodeErrors()
 	c.IncIPCDecodeErrors()
+	c.IncSeqGaps()
 	c.IncLodeWriteSuccess()
 	c.IncLodeWriteSuccess()
 	c.IncLodeWriteFailure()
@@ -50,6 +53,9 @@ func TestCollector_IncrementMethods(t *testing.T) {
 	if s.IPCDecodeErrors != 3 {
 		t.Errorf("IPCDecodeErrors = %d, want 3", s.IPCDecodeErrors)
 	}
+	if s.SeqGaps != 1 {
+		t.Errorf("SeqGaps = %d, want 1", s.SeqGaps)
+	}
 	if s.LodeWriteSuccess != 2 {
 		t.Errorf("LodeWriteSuccess = %d, want 2", s.LodeWriteSuccess)
 	}
@@ -217,6 +223,96 @@ func TestCollector_SnapshotDroppedByTypeIsolation(t *testing.T) {
 	}
 }
 
+func TestCollector_IncRunReason(t *testing.T) {
+	c := NewCollector("strict", "node", "fs", "run-001", "")
+	c.IncRunReason("stream_truncated")
+	c.IncRunReason("stream_truncated")
+	c.IncRunReason("timeout")
+	c.IncRunReason("") // ignored
+
+	s := c.Snapshot()
+	if s.RunsByReason["stream_truncated"] != 2 || s.RunsByReason["timeout"] != 1 {
+		t.Errorf("RunsByReason = %v, want stream_truncated=2 timeout=1", s.RunsByReason)
+	}
+	if _, ok := s.RunsByReason[""]; ok {
+		t.Error("empty reason should not be recorded")
+	}
+
+	// Snapshot map is isolated from the collector
+	s.RunsByReason["timeout"] = 99
+	if got := c.Snapshot().RunsByReason["timeout"]; got != 1 {
+		t.Errorf("RunsByReason[timeout] = %d after snapshot mutation, want 1", got)
+	}
+}
+
+func TestCollector_ObserveStorageSinkWrite(t *testing.T) {
+	c := NewCollector("strict", "node", "s3", "run-001", "")
+	if s := c.Snapshot(); s.StorageSinkWrites != nil {
+		t.Errorf("StorageSinkWrites = %v, want nil without a tee", s.StorageSinkWrites)
+	}
+
+	c.ObserveStorageSinkWrite("s3:bucket", 3*time.Millisecond, nil)
+	c.ObserveStorageSinkWrite("s3:bucket", 2*time.Millisecond, nil)
+	c.ObserveStorageSinkWrite("fs:/cache", time.Millisecond, errors.New("disk full"))
+
+	s := c.Snapshot()
+	if s.StorageSinkWrites["s3:bucket"] != 2 || s.StorageSinkWrites["fs:/cache"] != 1 {
+		t.Errorf("StorageSinkWrites = %v", s.StorageSinkWrites)
+	}
+	if s.StorageSinkFailures["s3:bucket"] != 0 || s.StorageSinkFailures["fs:/cache"] != 1 {
+		t.Errorf("StorageSinkFailures = %v", s.StorageSinkFailures)
+	}
+	if s.StorageSinkLatencyUs["s3:bucket"] != 5000 || s.StorageSinkLatencyUs["fs:/cache"] != 1000 {
+		t.Errorf("StorageSinkLatencyUs = %v", s.StorageSinkLatencyUs)
+	}
+}
+
+func TestHistogram_FixedBuckets(t *testing.T) {
+	var h Histogram
+	h.Observe(0)
+	h.Observe(time.Millisecond) // inclusive upper bound
+	h.Observe(3 * time.Millisecond)
+	h.Observe(90 * time.Second) // overflow
+
+	if h.Counts[0] != 2 || h.Counts[1] != 1 || h.Counts[len(LatencyBuckets)] != 1 {
+		t.Errorf("Counts = %v", h.Counts)
+	}
+	if h.Count != 4 || h.Sum != 90*time.Second+4*time.Millisecond {
+		t.Errorf("Count = %d, Sum = %s", h.Count, h.Sum)
+	}
+	if got := (Histogram{}).Mean(); got != 0 {
+		t.Errorf("empty Mean = %s, want 0", got)
+	}
+
+	var merged Histogram
+	merged.Add(h)
+	merged.Add(h)
+	if merged.Count != 8 || merged.Counts[0] != 4 {
+		t.Errorf("merged = %+v", merged)
+	}
+}
+
+func TestCollector_LatencyHistograms(t *testing.T) {
+	c := NewCollector("streaming", "node", "fs", "run-001", "")
+	c.ObserveTimeToFirstEvent(400 * time.Millisecond)
+
+	var flush Histogram
+	flush.Observe(20 * time.Millisecond)
+	flush.Observe(30 * time.Millisecond)
+	c.AbsorbFlushLatency(flush)
+
+	s := c.Snapshot()
+	if s.TimeToFirstEvent.Count != 1 || s.TimeToFirstEvent.Counts[LatencyBucket(400*time.Millisecond)] != 1 {
+		t.Errorf("TimeToFirstEvent = %+v", s.TimeToFirstEvent)
+	}
+	if s.FlushLatency != flush {
+		t.Errorf("FlushLatency = %+v, want %+v", s.FlushLatency, flush)
+	}
+	if got := s.FlushLatency.Mean(); got != 25*time.Millisecond {
+		t.Errorf("FlushLatency mean = %s, want 25ms", got)
+	}
+}
+
 func TestCollector_NilReceiverSafety(t *testing.T) {
 	var c *Collector
 
@@ -225,6 +321,7 @@ func TestCollector_NilReceiverSafety(t *testing.T) {
 	c.IncRunCompleted()
 	c.IncRunFailed()
 	c.IncRunCrashed()
+	c.IncRunReason("timeout")
 	c.IncExecutorLaunchSuccess()
 	c.IncExecutorLaunchFailure()
 	c.IncExecutorCrash()
@@ -232,6 +329,7 @@ func TestCollector_NilReceiverSafety(t *testing.T) {
 	c.IncLodeWriteSuccess()
 	c.IncLodeWriteFailure()
 	c.AbsorbPolicyStats(10, 8, 2, map[string]int64{"log": 2}, nil)
+	c.ObserveStorageSinkWrite("fs:/cache", time.Millisecond, nil)
 
 	s := c.Snapshot()
 	if s.RunsStarted != 0 {
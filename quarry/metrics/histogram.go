@@ -0,0 +1,69 @@
+package metrics
+
+import "time"
+
+// LatencyBuckets are the upper bounds (inclusive) shared by every latency
+// histogram. They are fixed so distributions compare across runs and
+// releases; changing them is a breaking change per CONTRACT_METRICS.md.
+// Observations above the last bound land in an overflow bucket.
+var LatencyBuckets = [...]time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	60 * time.Second,
+}
+
+// Histogram is a latency distribution over LatencyBuckets. Counts are per
+// bucket, not cumulative: Counts[i] holds observations in
+// (LatencyBuckets[i-1], LatencyBuckets[i]], and the final element holds
+// observations above the last bound. The zero value is an empty histogram.
+// Histogram is a plain value and is not safe for concurrent mutation.
+type Histogram struct {
+	Counts [len(LatencyBuckets) + 1]int64
+	Count  int64
+	Sum    time.Duration
+}
+
+// LatencyBucket returns the Counts index that d falls in.
+func LatencyBucket(d time.Duration) int {
+	for i, bound := range LatencyBuckets {
+		if d <= bound {
+			return i
+		}
+	}
+	return len(LatencyBuckets)
+}
+
+// Observe records one observation.
+func (h *Histogram) Observe(d time.Duration) {
+	h.Counts[LatencyBucket(d)]++
+	h.Count++
+	h.Sum += d
+}
+
+// Add merges o into h.
+func (h *Histogram) Add(o Histogram) {
+	for i, n := range o.Counts {
+		h.Counts[i] += n
+	}
+	h.Count += o.Count
+	h.Sum += o.Sum
+}
+
+// Mean returns the average observation, or 0 for an empty histogram.
+func (h Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
@@ -0,0 +1,216 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Merge sums the counters of several snapshots into one, e.g. a fan-out
+// root run and its children. Dimensions are taken from the first snapshot.
+func Merge(snaps ...Snapshot) Snapshot {
+	if len(snaps) == 0 {
+		return Snapshot{}
+	}
+	out := Snapshot{
+		DroppedByType:      make(map[string]int64),
+		RunsByReason:       make(map[string]int64),
+		LogFilteredByLevel: make(map[string]int64),
+		Policy:             snaps[0].Policy,
+		Executor:           snaps[0].Executor,
+		StorageBackend:     snaps[0].StorageBackend,
+		RunID:              snaps[0].RunID,
+		JobID:              snaps[0].JobID,
+		Labels:             snaps[0].Labels,
+	}
+	for _, s := range snaps {
+		out.RunsStarted += s.RunsStarted
+		out.RunsCompleted += s.RunsCompleted
+		out.RunsFailed += s.RunsFailed
+		out.RunsCrashed += s.RunsCrashed
+		for k, v := range s.RunsByReason {
+			out.RunsByReason[k] += v
+		}
+
+		out.EventsReceived += s.EventsReceived
+		out.EventsPersisted += s.EventsPersisted
+		out.EventsDropped += s.EventsDropped
+		out.Degradations += s.Degradations
+		for k, v := range s.DroppedByType {
+			out.DroppedByType[k] += v
+		}
+		if s.FlushTriggers != nil {
+			if out.FlushTriggers == nil {
+				out.FlushTriggers = make(map[string]int64, len(s.FlushTriggers))
+			}
+			for k, v := range s.FlushTriggers {
+				out.FlushTriggers[k] += v
+			}
+		}
+
+		out.FlushLatency.Add(s.FlushLatency)
+
+		out.ExecutorLaunchSuccess += s.ExecutorLaunchSuccess
+		out.ExecutorLaunchFailure += s.ExecutorLaunchFailure
+		out.ExecutorCrash += s.ExecutorCrash
+		out.IPCDecodeErrors += s.IPCDecodeErrors
+		out.SeqGaps += s.SeqGaps
+		out.ArtifactsDiscarded += s.ArtifactsDiscarded
+		out.EventsDiscarded += s.EventsDiscarded
+		out.EventsSampledOut += s.EventsSampledOut
+		out.EventsFiltered += s.EventsFiltered
+		for k, v := range s.LogFilteredByLevel {
+			out.LogFilteredByLevel[k] += v
+		}
+		out.TimeToFirstEvent.Add(s.TimeToFirstEvent)
+		out.EnqueuesDeduplicated += s.EnqueuesDeduplicated
+		out.ProxyRotations += s.ProxyRotations
+
+		out.LodeWriteSuccess += s.LodeWriteSuccess
+		out.LodeWriteFailure += s.LodeWriteFailure
+		out.LodeWriteRetry += s.LodeWriteRetry
+		out.LodeWritesSkipped += s.LodeWritesSkipped
+		out.StorageSinkWrites = mergeCounts(out.StorageSinkWrites, s.StorageSinkWrites)
+		out.StorageSinkFailures = mergeCounts(out.StorageSinkFailures, s.StorageSinkFailures)
+		out.StorageSinkLatencyUs = mergeCounts(out.StorageSinkLatencyUs, s.StorageSinkLatencyUs)
+	}
+	return out
+}
+
+// mergeCounts adds src into dst, allocating dst on first use. A nil src
+// leaves dst unchanged, so families absent from every snapshot stay nil.
+func mergeCounts(dst, src map[string]int64) map[string]int64 {
+	if src == nil {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]int64, len(src))
+	}
+	for k, v := range src {
+		dst[k] += v
+	}
+	return dst
+}
+
+// prometheusPrefix namespaces every exported metric.
+const prometheusPrefix = "quarry_"
+
+// WritePrometheus writes s in the Prometheus text exposition format.
+// Metric names follow CONTRACT_METRICS.md with a quarry_ prefix. Only the
+// bounded dimensions (policy, executor, storage_backend) and user run labels
+// (as label_<key>, fixed per invocation) become labels.
+func WritePrometheus(w io.Writer, s Snapshot) error {
+	bw := bufio.NewWriter(w)
+	dims := fmt.Sprintf(`policy="%s",executor="%s",storage_backend="%s"`,
+		escapeLabel(s.Policy), escapeLabel(s.Executor), escapeLabel(s.StorageBackend))
+	dims += runLabelDims(s.Labels)
+
+	counters := []struct {
+		name  string
+		help  string
+		value int64
+	}{
+		{"runs_started_total", "Runs started.", s.RunsStarted},
+		{"runs_completed_total", "Runs completed successfully.", s.RunsCompleted},
+		{"runs_failed_total", "Runs ended by script error, policy failure, or version mismatch.", s.RunsFailed},
+		{"runs_crashed_total", "Runs ended by executor crash.", s.RunsCrashed},
+		{"events_received_total", "Events received by the ingestion policy (absorbed at run completion).", s.EventsReceived},
+		{"events_persisted_total", "Events persisted by the ingestion policy (absorbed at run completion).", s.EventsPersisted},
+		{"events_dropped_total", "Events dropped by the ingestion policy (absorbed at run completion).", s.EventsDropped},
+		{"policy_degradations_total", "Strict policy degradations to buffering on a retryable sink error (absorbed at run completion).", s.Degradations},
+		{"executor_launch_success_total", "Executor launches that succeeded.", s.ExecutorLaunchSuccess},
+		{"executor_launch_failure_total", "Executor launches that failed.", s.ExecutorLaunchFailure},
+		{"executor_crash_total", "Executor crashes.", s.ExecutorCrash},
+		{"ipc_decode_errors_total", "IPC frame decode errors.", s.IPCDecodeErrors},
+		{"seq_gaps_total", "Forward seq jumps tolerated under --allow-seq-gaps.", s.SeqGaps},
+		{"artifacts_discarded_total", "Artifacts discarded under --events-only.", s.ArtifactsDiscarded},
+		{"events_discarded_total", "Events discarded under --artifacts-only.", s.EventsDiscarded},
+		{"events_sampled_out_total", "Item events skipped by --sample-rate.", s.EventsSampledOut},
+		{"events_filtered_total", "Events dropped by --events-filter.", s.EventsFiltered},
+		{"enqueues_deduplicated_total", "Fan-out enqueue events skipped as duplicates.", s.EnqueuesDeduplicated},
+		{"proxy_rotations_total", "Proxy endpoints rotated mid-run on rotate_proxy.", s.ProxyRotations},
+		{"lode_write_success_total", "Successful storage writes.", s.LodeWriteSuccess},
+		{"lode_write_failure_total", "Failed storage writes.", s.LodeWriteFailure},
+		{"lode_write_retry_total", "Storage write retries (reserved).", s.LodeWriteRetry},
+		{"lode_writes_skipped_total", "S3 object writes skipped by --storage-s3-skip-existing.", s.LodeWritesSkipped},
+	}
+	for _, c := range counters {
+		name := prometheusPrefix + c.name
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s counter\n%s{%s} %d\n", name, c.help, name, name, dims, c.value)
+	}
+
+	writeLabeled(bw, "runs_by_reason_total", "Run outcomes, by reason.", "reason", dims, s.RunsByReason)
+	writeLabeled(bw, "events_dropped_by_type_total", "Events dropped by the ingestion policy, by event type.", "type", dims, s.DroppedByType)
+	writeLabeled(bw, "log_filtered_total", "Log events filtered below --log-min-level, by level.", "level", dims, s.LogFilteredByLevel)
+	if s.FlushTriggers != nil {
+		writeLabeled(bw, "flush_triggers_total", "Streaming policy flushes, by trigger.", "trigger", dims, s.FlushTriggers)
+	}
+	if s.StorageSinkWrites != nil {
+		writeLabeled(bw, "storage_sink_writes_total", "Tee storage sink write calls, by sink.", "sink", dims, s.StorageSinkWrites)
+		writeLabeled(bw, "storage_sink_write_failures_total", "Tee storage sink failed write calls, by sink.", "sink", dims, s.StorageSinkFailures)
+		writeLabeled(bw, "storage_sink_write_latency_microseconds_total", "Tee storage sink total write latency in microseconds, by sink.", "sink", dims, s.StorageSinkLatencyUs)
+	}
+	writeHistogram(bw, "time_to_first_event_seconds", "Time from executor launch to its first IPC frame.", dims, s.TimeToFirstEvent)
+	writeHistogram(bw, "flush_latency_seconds", "Ingestion policy flush wall time (absorbed at run completion).", dims, s.FlushLatency)
+	return bw.Flush()
+}
+
+// writeLabeled writes one counter family with an extra label per map key,
+// in sorted key order for stable output.
+func writeLabeled(w io.Writer, metric, help, label, dims string, values map[string]int64) {
+	name := prometheusPrefix + metric
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s{%s,%s=\"%s\"} %d\n", name, dims, label, escapeLabel(k), values[k])
+	}
+}
+
+// writeHistogram writes one histogram family over LatencyBuckets, converting
+// the per-bucket counts to Prometheus's cumulative le buckets.
+func writeHistogram(w io.Writer, metric, help, dims string, h Histogram) {
+	name := prometheusPrefix + metric
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	var cumulative int64
+	for i, bound := range LatencyBuckets {
+		cumulative += h.Counts[i]
+		fmt.Fprintf(w, "%s_bucket{%s,le=\"%s\"} %d\n", name, dims, strconv.FormatFloat(bound.Seconds(), 'g', -1, 64), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, dims, h.Count)
+	fmt.Fprintf(w, "%s_sum{%s} %s\n", name, dims, strconv.FormatFloat(h.Sum.Seconds(), 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count{%s} %d\n", name, dims, h.Count)
+}
+
+// runLabelDims renders user run labels as ,label_<key>="value" pairs in
+// sorted key order. Characters invalid in Prometheus label names become '_'.
+func runLabelDims(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, `,label_%s="%s"`, labelNameReplacer.Replace(k), escapeLabel(labels[k]))
+	}
+	return b.String()
+}
+
+// labelNameReplacer maps run label key characters that are not valid in
+// Prometheus label names to '_'.
+var labelNameReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+// labelEscaper escapes label values per the Prometheus text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a label value for the Prometheus text format.
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
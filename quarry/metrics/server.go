@@ -0,0 +1,141 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// shutdownTimeout bounds Server.Close so serving metrics never delays run
+// completion by more than this.
+const shutdownTimeout = time.Second
+
+// Server serves live metrics over HTTP for the duration of a run:
+//   - /metrics: merged Snapshot of all registered collectors (Prometheus text)
+//   - /healthz: "ok" while the server is up
+//
+// All methods are nil-receiver safe so callers can pass a nil *Server when
+// the endpoint is disabled.
+type Server struct {
+	mu         sync.Mutex
+	collectors []*Collector
+	retired    *Snapshot // merged snapshots of retired collectors
+
+	listener net.Listener
+	srv      *http.Server
+	done     chan struct{}
+}
+
+// NewServer binds addr (e.g. ":9900") and starts serving in the background.
+// Binding happens synchronously so address errors surface before the run.
+func NewServer(addr string) (*Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("metrics server: %w", err)
+	}
+
+	s := &Server{listener: ln, done: make(chan struct{})}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/healthz", handleHealthz)
+	s.srv = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		defer close(s.done)
+		_ = s.srv.Serve(ln)
+	}()
+	return s, nil
+}
+
+// Addr returns the bound listen address.
+func (s *Server) Addr() string {
+	if s == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// Register adds a collector to the aggregated /metrics view.
+func (s *Server) Register(c *Collector) {
+	if s == nil || c == nil {
+		return
+	}
+	s.mu.Lock()
+	s.collectors = append(s.collectors, c)
+	s.mu.Unlock()
+}
+
+// Retire removes a collector whose run has finished, folding its final
+// snapshot into the /metrics view so long fan-outs do not hold one
+// collector per child. Retiring an unregistered collector is a no-op.
+func (s *Server) Retire(c *Collector) {
+	if s == nil || c == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, registered := range s.collectors {
+		if registered != c {
+			continue
+		}
+		s.collectors = append(s.collectors[:i], s.collectors[i+1:]...)
+		snap := c.Snapshot()
+		if s.retired != nil {
+			snap = Merge(*s.retired, snap)
+		}
+		s.retired = &snap
+		return
+	}
+}
+
+// Snapshot returns the merged snapshot of all registered collectors and
+// those already retired.
+func (s *Server) Snapshot() Snapshot {
+	if s == nil {
+		return Snapshot{}
+	}
+	s.mu.Lock()
+	collectors := make([]*Collector, len(s.collectors))
+	copy(collectors, s.collectors)
+	retired := s.retired
+	s.mu.Unlock()
+
+	snaps := make([]Snapshot, 0, len(collectors)+1)
+	for _, c := range collectors {
+		snaps = append(snaps, c.Snapshot())
+	}
+	if retired != nil {
+		snaps = append(snaps, *retired)
+	}
+	return Merge(snaps...)
+}
+
+// Close shuts the server down, waiting at most shutdownTimeout for
+// in-flight scrapes before closing remaining connections.
+func (s *Server) Close() error {
+	if s == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := s.srv.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = s.srv.Close()
+	}
+	<-s.done
+	return err
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = WritePrometheus(w, s.Snapshot())
+}
+
+func handleHealthz(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("ok\n"))
+}
@@ -0,0 +1,233 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMerge_SumsCountersAndMaps(t *testing.T) {
+	root := NewCollector("buffered", "executor.mjs", "fs", "root", "")
+	root.IncRunStarted()
+	root.IncLodeWriteSuccess()
+	root.AbsorbPolicyStats(10, 8, 2, map[string]int64{"log": 2}, nil)
+
+	child := NewCollector("buffered", "executor.mjs", "fs", "child", "")
+	child.IncRunStarted()
+	child.IncRunCrashed()
+	child.AbsorbPolicyStats(5, 4, 1, map[string]int64{"log": 1}, map[string]int64{"count": 3})
+	root.IncLogFiltered("debug")
+	child.IncLogFiltered("debug")
+
+	got := Merge(root.Snapshot(), child.Snapshot())
+	if got.RunsStarted != 2 || got.RunsCrashed != 1 || got.LodeWriteSuccess != 1 {
+		t.Errorf("lifecycle counters not summed: %+v", got)
+	}
+	if got.EventsReceived != 15 || got.EventsPersisted != 12 || got.EventsDropped != 3 {
+		t.Errorf("ingestion counters not summed: %+v", got)
+	}
+	if got.DroppedByType["log"] != 3 {
+		t.Errorf("DroppedByType[log] = %d, want 3", got.DroppedByType["log"])
+	}
+	if got.LogFilteredByLevel["debug"] != 2 {
+		t.Errorf("LogFilteredByLevel[debug] = %d, want 2", got.LogFilteredByLevel["debug"])
+	}
+	if got.FlushTriggers["count"] != 3 {
+		t.Errorf("FlushTriggers[count] = %d, want 3", got.FlushTriggers["count"])
+	}
+	if got.RunID != "root" {
+		t.Errorf("dimensions should come from the first snapshot, got run_id %q", got.RunID)
+	}
+}
+
+func TestWritePrometheus(t *testing.T) {
+	c := NewCollector("strict", `exec"utor`, "fs", "run-001", "")
+	c.IncRunStarted()
+	c.AbsorbPolicyStats(3, 3, 0, map[string]int64{"enqueue": 1}, nil)
+	c.IncLogFiltered("info")
+
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf, c.Snapshot()); err != nil {
+		t.Fatalf("WritePrometheus: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"# TYPE quarry_runs_started_total counter\n",
+		`quarry_runs_started_total{policy="strict",executor="exec\"utor",storage_backend="fs"} 1`,
+		`quarry_events_received_total{policy="strict",executor="exec\"utor",storage_backend="fs"} 3`,
+		`quarry_events_dropped_by_type_total{policy="strict",executor="exec\"utor",storage_backend="fs",type="enqueue"} 1`,
+		`quarry_log_filtered_total{policy="strict",executor="exec\"utor",storage_backend="fs",level="info"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "run_id") {
+		t.Error("run_id must not be exported as a label (unbounded cardinality)")
+	}
+	if strings.Contains(out, "flush_triggers_total") {
+		t.Error("flush triggers should be omitted for non-streaming policies")
+	}
+	if strings.Contains(out, "storage_sink_") {
+		t.Error("storage sink families should be omitted without a tee")
+	}
+}
+
+func TestWritePrometheus_StorageSinks(t *testing.T) {
+	root := NewCollector("strict", "node", "s3", "root", "")
+	root.ObserveStorageSinkWrite("fs:/cache", 1500*time.Microsecond, nil)
+	child := NewCollector("strict", "node", "s3", "child", "")
+	child.ObserveStorageSinkWrite("fs:/cache", 500*time.Microsecond, errors.New("disk full"))
+
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf, Merge(root.Snapshot(), child.Snapshot())); err != nil {
+		t.Fatalf("WritePrometheus: %v", err)
+	}
+	for _, want := range []string{
+		`quarry_storage_sink_writes_total{policy="strict",executor="node",storage_backend="s3",sink="fs:/cache"} 2`,
+		`quarry_storage_sink_write_failures_total{policy="strict",executor="node",storage_backend="s3",sink="fs:/cache"} 1`,
+		`quarry_storage_sink_write_latency_microseconds_total{policy="strict",executor="node",storage_backend="s3",sink="fs:/cache"} 2000`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestWritePrometheus_Histograms(t *testing.T) {
+	root := NewCollector("strict", "node", "fs", "root", "")
+	root.ObserveTimeToFirstEvent(3 * time.Millisecond)
+	child := NewCollector("strict", "node", "fs", "child", "")
+	child.ObserveTimeToFirstEvent(2 * time.Minute)
+
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf, Merge(root.Snapshot(), child.Snapshot())); err != nil {
+		t.Fatalf("WritePrometheus: %v", err)
+	}
+	dims := `policy="strict",executor="node",storage_backend="fs"`
+	for _, want := range []string{
+		"# TYPE quarry_time_to_first_event_seconds histogram\n",
+		`quarry_time_to_first_event_seconds_bucket{` + dims + `,le="0.001"} 0`,
+		`quarry_time_to_first_event_seconds_bucket{` + dims + `,le="0.005"} 1`,
+		`quarry_time_to_first_event_seconds_bucket{` + dims + `,le="60"} 1`,
+		`quarry_time_to_first_event_seconds_bucket{` + dims + `,le="+Inf"} 2`,
+		`quarry_time_to_first_event_seconds_sum{` + dims + `} 120.003`,
+		`quarry_time_to_first_event_seconds_count{` + dims + `} 2`,
+		`quarry_flush_latency_seconds_count{` + dims + `} 0`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestWritePrometheus_RunLabels(t *testing.T) {
+	c := NewCollector("strict", "node", "fs", "run-001", "")
+	c.SetLabels(map[string]string{"team": "growth", "cost-center": "cc\"42"})
+	c.IncRunStarted()
+
+	var buf bytes.Buffer
+	if err := WritePrometheus(&buf, c.Snapshot()); err != nil {
+		t.Fatalf("WritePrometheus: %v", err)
+	}
+	want := `quarry_runs_started_total{policy="strict",executor="node",storage_backend="fs",label_cost_center="cc\"42",label_team="growth"} 1`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("output missing %q:\n%s", want, buf.String())
+	}
+}
+
+func TestServer_ServesMetricsAndHealth(t *testing.T) {
+	srv, err := NewServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+
+	root := NewCollector("strict", "node", "fs", "root", "")
+	root.IncRunStarted()
+	srv.Register(root)
+	child := NewCollector("strict", "node", "fs", "child", "")
+	child.IncRunStarted()
+	srv.Register(child)
+
+	get := func(path string) string {
+		t.Helper()
+		resp, err := http.Get("http://" + srv.Addr() + path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", path, err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: status %d", path, resp.StatusCode)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	if body := get("/healthz"); body != "ok\n" {
+		t.Errorf("/healthz = %q, want ok", body)
+	}
+	if body := get("/metrics"); !strings.Contains(body, `quarry_runs_started_total{policy="strict",executor="node",storage_backend="fs"} 2`) {
+		t.Errorf("/metrics should aggregate root and child:\n%s", body)
+	}
+
+	if err := srv.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := http.Get("http://" + srv.Addr() + "/healthz"); err == nil {
+		t.Error("server should not accept requests after Close")
+	}
+}
+
+func TestServer_RetireKeepsFinalCounts(t *testing.T) {
+	srv, err := NewServer("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	defer func() { _ = srv.Close() }()
+
+	root := NewCollector("strict", "node", "fs", "root", "")
+	root.IncRunStarted()
+	srv.Register(root)
+	for _, id := range []string{"child-1", "child-2"} {
+		child := NewCollector("strict", "node", "fs", id, "")
+		child.IncRunStarted()
+		child.IncRunCompleted()
+		srv.Register(child)
+		srv.Retire(child)
+	}
+	srv.Retire(NewCollector("strict", "node", "fs", "unregistered", ""))
+
+	if n := len(srv.collectors); n != 1 {
+		t.Errorf("registered collectors = %d, want only the root", n)
+	}
+	got := srv.Snapshot()
+	if got.RunsStarted != 3 || got.RunsCompleted != 2 {
+		t.Errorf("snapshot = %+v, want retired children's counts kept", got)
+	}
+	if got.RunID != "root" {
+		t.Errorf("run_id = %q, want dimensions from the live root", got.RunID)
+	}
+}
+
+func TestServer_NilSafe(t *testing.T) {
+	var srv *Server
+	srv.Register(NewCollector("strict", "node", "fs", "r", ""))
+	srv.Retire(NewCollector("strict", "node", "fs", "r", ""))
+	if got := srv.Snapshot(); got.RunsStarted != 0 {
+		t.Errorf("nil server snapshot = %+v", got)
+	}
+	if err := srv.Close(); err != nil {
+		t.Errorf("nil Close: %v", err)
+	}
+}
+
+func TestNewServer_BadAddr(t *testing.T) {
+	if _, err := NewServer("not-an-address"); err == nil {
+		t.Error("expected error for invalid address")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/pithecene-io/quarry/log"
 	"github.com/pithecene-io/quarry/types"
@@ -45,6 +46,13 @@ type BufferedConfig struct {
 	// Default is FlushAtLeastOnce (safest, may duplicate on retry).
 	FlushMode FlushMode
 
+	// ParallelFlush writes chunks and independent events concurrently
+	// during a FlushTwoPhase flush. Artifact commit events are still
+	// written only after their chunks succeed. Requires FlushTwoPhase and
+	// a sink that is safe for concurrent WriteChunks/WriteEvents calls.
+	// Default is false (serial two-phase flush).
+	ParallelFlush bool
+
 	// Logger is an optional logger for policy observability.
 	// If nil, no logging is emitted.
 	Logger *log.Logger
@@ -68,6 +76,9 @@ var ErrInvalidConfig = errors.New("invalid config: at least one of MaxBufferEven
 // ErrInvalidFlushMode is returned when FlushMode is unknown.
 var ErrInvalidFlushMode = errors.New("invalid flush mode")
 
+// ErrParallelFlushMode is returned when ParallelFlush is set without FlushTwoPhase.
+var ErrParallelFlushMode = errors.New("invalid config: parallel flush requires two_phase flush mode")
+
 // BufferedPolicy implements buffered persistence with drop rules.
 //
 // Per CONTRACT_POLICY.md:
@@ -92,7 +103,9 @@ type BufferedPolicy struct {
 	bufferBytes     int64
 	chunksFlushed   bool // TwoPhase: chunkBuffer written, awaiting events success
 	eventsFlushed   bool // TwoPhase: eventBuffer written, awaiting full success
-	stats           *statsRecorder
+	// ParallelFlush: non-artifact events of eventBuffer written, artifact commits pending
+	independentFlushed bool
+	stats              *statsRecorder
 }
 
 // NewBufferedPolicy creates a new buffered policy.
@@ -114,6 +127,9 @@ func NewBufferedPolicy(sink Sink, config BufferedConfig) (*BufferedPolicy, error
 	default:
 		return nil, fmt.Errorf("%w: %s", ErrInvalidFlushMode, config.FlushMode)
 	}
+	if config.ParallelFlush && config.FlushMode != FlushTwoPhase {
+		return nil, fmt.Errorf("%w (got %s)", ErrParallelFlushMode, config.FlushMode)
+	}
 
 	return &BufferedPolicy{
 		sink:            sink,
@@ -170,9 +186,10 @@ func (p *BufferedPolicy) IngestEvent(_ context.Context, envelope *types.EventEnv
 }
 
 // appendEvent adds an event to the appropriate buffer. Caller must hold mu.
-// In TwoPhase mode with eventsFlushed=true, appends to eventBufferNext.
+// In TwoPhase mode with eventsFlushed=true (or independentFlushed=true under
+// ParallelFlush), appends to eventBufferNext.
 func (p *BufferedPolicy) appendEvent(envelope *types.EventEnvelope, eventSize int64) {
-	if p.config.FlushMode == FlushTwoPhase && p.eventsFlushed {
+	if p.config.FlushMode == FlushTwoPhase && (p.eventsFlushed || p.independentFlushed) {
 		// Events added after partial flush go to next buffer
 		p.eventBufferNext = append(p.eventBufferNext, envelope)
 	} else {
@@ -221,11 +238,18 @@ func (p *BufferedPolicy) IngestArtifactChunk(_ context.Context, chunk *types.Art
 
 // Flush writes all buffered events and chunks to the sink.
 // Behavior depends on FlushMode configuration.
+// Flush latency (including failed attempts) is recorded in Stats.
 func (p *BufferedPolicy) Flush(ctx context.Context) error {
+	start := time.Now()
+	defer func() { p.stats.observeFlushLatency(time.Since(start)) }()
+
 	switch p.config.FlushMode {
 	case FlushChunksFirst:
 		return p.flushChunksFirst(ctx)
 	case FlushTwoPhase:
+		if p.config.ParallelFlush {
+			return p.flushTwoPhaseParallel(ctx)
+		}
 		return p.flushTwoPhase(ctx)
 	default:
 		return p.flushAtLeastOnce(ctx)
@@ -414,6 +438,151 @@ func (p *BufferedPolicy) flushTwoPhase(ctx context.Context) error {
 	return nil
 }
 
+// flushTwoPhaseParallel is flushTwoPhase with chunk and event writes overlapped.
+// Chunk writes (chunks → chunksNext) run concurrently with the non-artifact
+// events of eventBuffer, which have no ordering dependency on chunks.
+// Artifact commit events are written only after all chunks succeed, then
+// eventsNext. Per-buffer success is tracked as in flushTwoPhase, so nothing
+// that was written is re-written on retry.
+func (p *BufferedPolicy) flushTwoPhaseParallel(ctx context.Context) error {
+	p.mu.Lock()
+	p.stats.incFlushLocked()
+	events := p.eventBuffer
+	eventsNext := p.eventBufferNext
+	chunks := p.chunkBuffer
+	chunksNext := p.chunkBufferNext
+	chunksFlushed := p.chunksFlushed
+	eventsFlushed := p.eventsFlushed
+	independentFlushed := p.independentFlushed
+	p.mu.Unlock()
+
+	var independent, commits []*types.EventEnvelope
+	if !eventsFlushed {
+		independent, commits = splitArtifactCommits(events)
+	}
+
+	var wg sync.WaitGroup
+	var chunkErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		chunkErr = p.writeTwoPhaseChunks(ctx, chunks, chunksNext, chunksFlushed)
+	}()
+
+	var eventErr error
+	if len(independent) > 0 && !independentFlushed {
+		if err := p.sink.WriteEvents(ctx, independent); err != nil {
+			p.mu.Lock()
+			p.stats.incErrorsLocked()
+			p.mu.Unlock()
+			p.logFlushFailure("events", err)
+			eventErr = err
+		} else {
+			p.mu.Lock()
+			p.stats.incEventsPersistedLocked(int64(len(independent)))
+			p.independentFlushed = true
+			p.mu.Unlock()
+		}
+	}
+	wg.Wait()
+
+	if chunkErr != nil || eventErr != nil {
+		return errors.Join(chunkErr, eventErr)
+	}
+
+	// Artifact commits only after their chunks have landed
+	if !eventsFlushed {
+		if len(commits) > 0 {
+			if err := p.sink.WriteEvents(ctx, commits); err != nil {
+				p.mu.Lock()
+				p.stats.incErrorsLocked()
+				p.mu.Unlock()
+				p.logFlushFailure("events", err)
+				return err
+			}
+		}
+		p.mu.Lock()
+		p.stats.incEventsPersistedLocked(int64(len(commits)))
+		p.eventsFlushed = true
+		p.mu.Unlock()
+	}
+
+	// Write new events added after partial flush
+	if len(eventsNext) > 0 {
+		if err := p.sink.WriteEvents(ctx, eventsNext); err != nil {
+			p.mu.Lock()
+			p.stats.incErrorsLocked()
+			p.mu.Unlock()
+			p.logFlushFailure("events", err)
+			return err
+		}
+		p.mu.Lock()
+		p.stats.incEventsPersistedLocked(int64(len(eventsNext)))
+		p.mu.Unlock()
+	}
+
+	// Clear all buffers and reset state after full success
+	p.mu.Lock()
+	p.clearEventBuffer()
+	p.clearEventBufferNext()
+	p.clearChunkBuffer()
+	p.clearChunkBufferNext()
+	p.recalculateBufferBytes()
+	p.chunksFlushed = false
+	p.eventsFlushed = false
+	p.independentFlushed = false
+	p.mu.Unlock()
+
+	return nil
+}
+
+// writeTwoPhaseChunks writes chunks (unless already flushed), then chunksNext.
+// Updates chunksFlushed and clears chunkBufferNext on success.
+func (p *BufferedPolicy) writeTwoPhaseChunks(ctx context.Context, chunks, chunksNext []*types.ArtifactChunk, chunksFlushed bool) error {
+	if len(chunks) > 0 && !chunksFlushed {
+		if err := p.sink.WriteChunks(ctx, chunks); err != nil {
+			p.mu.Lock()
+			p.stats.incErrorsLocked()
+			p.mu.Unlock()
+			p.logFlushFailure("chunks", err)
+			return err
+		}
+		p.mu.Lock()
+		p.stats.incChunksPersistedLocked(int64(len(chunks)))
+		p.chunksFlushed = true
+		p.mu.Unlock()
+	}
+
+	if len(chunksNext) > 0 {
+		if err := p.sink.WriteChunks(ctx, chunksNext); err != nil {
+			p.mu.Lock()
+			p.stats.incErrorsLocked()
+			p.mu.Unlock()
+			p.logFlushFailure("chunks", err)
+			return err
+		}
+		p.mu.Lock()
+		p.stats.incChunksPersistedLocked(int64(len(chunksNext)))
+		p.clearChunkBufferNext()
+		p.recalculateBufferBytes()
+		p.mu.Unlock()
+	}
+	return nil
+}
+
+// splitArtifactCommits partitions events into non-artifact events and artifact
+// commit events, preserving seq order within each group.
+func splitArtifactCommits(events []*types.EventEnvelope) (independent, commits []*types.EventEnvelope) {
+	for _, e := range events {
+		if e.Type == types.EventTypeArtifact {
+			commits = append(commits, e)
+		} else {
+			independent = append(independent, e)
+		}
+	}
+	return independent, commits
+}
+
 // clearEventBuffer resets the event buffer. Caller must hold mu.
 // Call recalculateBufferBytes after all buffer clears are complete.
 func (p *BufferedPolicy) clearEventBuffer() {
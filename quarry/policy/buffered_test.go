@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/pithecene-io/quarry/policy"
 	"github.com/pithecene-io/quarry/types"
@@ -1444,3 +1445,183 @@ func TestBufferedPolicy_AllEventsWrittenTogether(t *testing.T) {
 		}
 	}
 }
+
+// gatedChunkSink blocks WriteChunks until released and signals each write.
+type gatedChunkSink struct {
+	*policy.StubSink
+	chunksStarted chan struct{}
+	releaseChunks chan struct{}
+	eventsWritten chan []*types.EventEnvelope
+	failOnChunks  bool
+}
+
+func newGatedChunkSink() *gatedChunkSink {
+	return &gatedChunkSink{
+		StubSink:      policy.NewStubSink(),
+		chunksStarted: make(chan struct{}, 10),
+		releaseChunks: make(chan struct{}),
+		eventsWritten: make(chan []*types.EventEnvelope, 10),
+	}
+}
+
+func (s *gatedChunkSink) WriteChunks(ctx context.Context, chunks []*types.ArtifactChunk) error {
+	s.chunksStarted <- struct{}{}
+	<-s.releaseChunks
+	if s.failOnChunks {
+		return errors.New("chunk write failed")
+	}
+	return s.StubSink.WriteChunks(ctx, chunks)
+}
+
+func (s *gatedChunkSink) WriteEvents(ctx context.Context, events []*types.EventEnvelope) error {
+	if err := s.StubSink.WriteEvents(ctx, events); err != nil {
+		return err
+	}
+	s.eventsWritten <- events
+	return nil
+}
+
+func TestBufferedPolicy_ParallelFlush_RequiresTwoPhase(t *testing.T) {
+	_, err := policy.NewBufferedPolicy(policy.NewStubSink(), policy.BufferedConfig{
+		MaxBufferBytes: 1000,
+		FlushMode:      policy.FlushChunksFirst,
+		ParallelFlush:  true,
+	})
+	if !errors.Is(err, policy.ErrParallelFlushMode) {
+		t.Errorf("expected ErrParallelFlushMode, got %v", err)
+	}
+}
+
+func TestBufferedPolicy_ParallelFlush_EventsOverlapChunks(t *testing.T) {
+	sink := newGatedChunkSink()
+	pol := mustNewBufferedPolicy(t, sink, policy.BufferedConfig{
+		MaxBufferBytes: 10000,
+		FlushMode:      policy.FlushTwoPhase,
+		ParallelFlush:  true,
+	})
+
+	_ = pol.IngestEvent(t.Context(), &types.EventEnvelope{EventID: "e1", Seq: 1, Type: types.EventTypeItem})
+	_ = pol.IngestArtifactChunk(t.Context(), &types.ArtifactChunk{ArtifactID: "a1", Seq: 1, Data: []byte("data")})
+	_ = pol.IngestEvent(t.Context(), &types.EventEnvelope{
+		EventID: "e2", Seq: 2, Type: types.EventTypeArtifact,
+		Payload: map[string]any{"artifact_id": "a1"},
+	})
+	_ = pol.IngestEvent(t.Context(), &types.EventEnvelope{EventID: "e3", Seq: 3, Type: types.EventTypeLog})
+
+	done := make(chan error, 1)
+	go func() { done <- pol.Flush(t.Context()) }()
+
+	<-sink.chunksStarted
+	// Independent events land while the chunk write is still in flight
+	select {
+	case batch := <-sink.eventsWritten:
+		if len(batch) != 2 || batch[0].EventID != "e1" || batch[1].EventID != "e3" {
+			t.Errorf("expected independent batch [e1 e3], got %d events", len(batch))
+		}
+		for _, ev := range batch {
+			if ev.Type == types.EventTypeArtifact {
+				t.Error("artifact commit written before chunks")
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("independent events were not written while chunks were in flight")
+	}
+
+	close(sink.releaseChunks)
+	if err := <-done; err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+
+	// Commit written last, after chunks
+	order := sink.WriteOrder
+	if len(order) != 3 {
+		t.Fatalf("expected 3 write ops, got %d", len(order))
+	}
+	if order[1].Type != "chunks" || order[2].Type != "events" || order[2].Events[0].EventID != "e2" {
+		t.Errorf("expected chunks then artifact commit, got %s then %s", order[1].Type, order[2].Type)
+	}
+
+	stats := pol.Stats()
+	if stats.EventsPersisted != 3 || stats.ChunksPersisted != 1 || stats.BufferSize != 0 {
+		t.Errorf("unexpected stats: persisted=%d chunks=%d buffer=%d",
+			stats.EventsPersisted, stats.ChunksPersisted, stats.BufferSize)
+	}
+}
+
+func TestBufferedPolicy_ParallelFlush_NoDuplicatesOnChunkFailure(t *testing.T) {
+	sink := newGatedChunkSink()
+	sink.failOnChunks = true
+	close(sink.releaseChunks)
+
+	pol := mustNewBufferedPolicy(t, sink, policy.BufferedConfig{
+		MaxBufferBytes: 10000,
+		FlushMode:      policy.FlushTwoPhase,
+		ParallelFlush:  true,
+	})
+
+	_ = pol.IngestEvent(t.Context(), &types.EventEnvelope{EventID: "e1", Seq: 1, Type: types.EventTypeItem})
+	_ = pol.IngestArtifactChunk(t.Context(), &types.ArtifactChunk{ArtifactID: "a1", Seq: 1, Data: []byte("data")})
+	_ = pol.IngestEvent(t.Context(), &types.EventEnvelope{
+		EventID: "e2", Seq: 2, Type: types.EventTypeArtifact,
+		Payload: map[string]any{"artifact_id": "a1"},
+	})
+
+	// Chunks fail; independent event succeeds, commit is held back
+	if err := pol.Flush(t.Context()); err == nil {
+		t.Fatal("expected flush to fail on chunks")
+	}
+	if got := sink.Stats().EventsWritten; got != 1 {
+		t.Fatalf("expected only the independent event written, got %d", got)
+	}
+
+	// Event ingested after the partial flush must not be lost
+	_ = pol.IngestEvent(t.Context(), &types.EventEnvelope{EventID: "e3", Seq: 3, Type: types.EventTypeItem})
+
+	sink.failOnChunks = false
+	if err := pol.Flush(t.Context()); err != nil {
+		t.Fatalf("retry should succeed: %v", err)
+	}
+
+	eventIDs := make(map[string]int)
+	for _, ev := range sink.WrittenEvents {
+		eventIDs[ev.EventID]++
+	}
+	for _, id := range []string{"e1", "e2", "e3"} {
+		if eventIDs[id] != 1 {
+			t.Errorf("%s should be written exactly once, got %d", id, eventIDs[id])
+		}
+	}
+	if sink.Stats().ChunksWritten != 1 {
+		t.Errorf("expected 1 chunk written, got %d", sink.Stats().ChunksWritten)
+	}
+}
+
+func TestBufferedPolicy_FlushLatencyRecorded(t *testing.T) {
+	sink := newGatedChunkSink()
+	pol := mustNewBufferedPolicy(t, sink, policy.BufferedConfig{
+		MaxBufferBytes: 10000,
+		FlushMode:      policy.FlushTwoPhase,
+	})
+
+	_ = pol.IngestArtifactChunk(t.Context(), &types.ArtifactChunk{ArtifactID: "a1", Seq: 1, Data: []byte("data")})
+
+	go func() {
+		<-sink.chunksStarted
+		time.Sleep(10 * time.Millisecond)
+		close(sink.releaseChunks)
+	}()
+	if err := pol.Flush(t.Context()); err != nil {
+		t.Fatalf("flush failed: %v", err)
+	}
+	if err := pol.Flush(t.Context()); err != nil {
+		t.Fatalf("empty flush failed: %v", err)
+	}
+
+	stats := pol.Stats()
+	if stats.FlushLatencyMax < 10*time.Millisecond {
+		t.Errorf("expected max flush latency >= 10ms, got %s", stats.FlushLatencyMax)
+	}
+	if stats.FlushLatencyTotal < stats.FlushLatencyMax {
+		t.Errorf("total latency %s should be >= max %s", stats.FlushLatencyTotal, stats.FlushLatencyMax)
+	}
+}
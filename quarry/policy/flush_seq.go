@@ -0,0 +1,48 @@
+package policy
+
+import (
+	"context"
+)
+
+// FlushCompleteName is the marker file written by the termination flush
+// when streaming flush sequencing is enabled.
+const FlushCompleteName = "_complete"
+
+// flushCompleteContentType is the content type of the _complete marker.
+const flushCompleteContentType = "application/json"
+
+// FileSink persists discrete, immutable files alongside the event stream.
+// Satisfied by lode.FileWriter; declared here so policy does not import lode.
+type FileSink interface {
+	PutFile(ctx context.Context, filename, contentType string, data []byte) error
+}
+
+// FlushInfo identifies the streaming flush a Sink.WriteEvents call persists.
+type FlushInfo struct {
+	// Seq is the flush sequence: it starts at 1 and is gap-free.
+	Seq int64
+	// Trigger is the trigger that caused the flush.
+	Trigger FlushTrigger
+}
+
+// flushInfoKey is the context key for FlushInfo.
+type flushInfoKey struct{}
+
+// WithFlushInfo returns a child context carrying info for a sink write.
+func WithFlushInfo(ctx context.Context, info FlushInfo) context.Context {
+	return context.WithValue(ctx, flushInfoKey{}, info)
+}
+
+// FlushInfoFromContext returns the FlushInfo carried by ctx, if any.
+// Sinks that persist each event batch as a discrete object record Seq on it.
+func FlushInfoFromContext(ctx context.Context) (FlushInfo, bool) {
+	info, ok := ctx.Value(flushInfoKey{}).(FlushInfo)
+	return info, ok
+}
+
+// FlushComplete is the JSON document written to the _complete marker.
+// LastFlushSeq is the highest flush sequence written (0 if none).
+type FlushComplete struct {
+	LastFlushSeq int64 `json:"last_flush_seq"`
+	EventCount   int64 `json:"event_count"`
+}
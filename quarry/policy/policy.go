@@ -4,7 +4,9 @@ package policy
 import (
 	"context"
 	"sync/atomic"
+	"time"
 
+	"github.com/pithecene-io/quarry/metrics"
 	"github.com/pithecene-io/quarry/types"
 )
 
@@ -62,8 +64,20 @@ type Stats struct {
 	Errors int64
 	// FlushTriggers is a per-trigger-type flush counter.
 	// Only populated by streaming policy; nil for strict/buffered.
-	// Keys are trigger names: "count", "interval", "termination", "capacity".
+	// Keys are trigger names: "count", "interval", "termination", "capacity", "idle".
 	FlushTriggers map[string]int64
+	// FlushLatencyTotal is the cumulative wall time spent flushing.
+	// Populated by buffered and streaming policies, and by strict policy
+	// with batching; zero otherwise.
+	FlushLatencyTotal time.Duration
+	// FlushLatencyMax is the longest single flush.
+	FlushLatencyMax time.Duration
+	// FlushLatencyHistogram is the distribution of flush wall times over
+	// metrics.LatencyBuckets, from the same observations as FlushLatencyTotal.
+	FlushLatencyHistogram metrics.Histogram
+	// Degradations is how many times a strict policy degraded to buffering
+	// on a retryable sink error (StrictConfig.DegradeBuffer); zero otherwise.
+	Degradations int64
 }
 
 // droppableTypes defines which event types may be dropped per CONTRACT_POLICY.md.
@@ -118,6 +132,10 @@ type statsRecorder struct {
 	bufferSize      atomic.Int64
 	flushCount      atomic.Int64
 	errors          atomic.Int64
+	flushNanos      atomic.Int64
+	flushMaxNanos   atomic.Int64
+	flushBuckets    [len(metrics.LatencyBuckets) + 1]atomic.Int64
+	degradations    atomic.Int64
 
 	// droppedByType is a map requiring external synchronization.
 	// StrictPolicy never writes to it (snapshot is safe without locking).
@@ -133,24 +151,53 @@ func newStatsRecorder() *statsRecorder {
 }
 
 func (r *statsRecorder) incTotalEvents()            { r.totalEvents.Add(1) }
-func (r *statsRecorder) incEventsPersisted(n int64)  { r.eventsPersisted.Add(n) }
-func (r *statsRecorder) incTotalChunks()             { r.totalChunks.Add(1) }
-func (r *statsRecorder) incChunksPersisted(n int64)  { r.chunksPersisted.Add(n) }
-func (r *statsRecorder) incErrors()                  { r.errors.Add(1) }
-func (r *statsRecorder) incFlush()                   { r.flushCount.Add(1) }
+func (r *statsRecorder) incEventsPersisted(n int64) { r.eventsPersisted.Add(n) }
+func (r *statsRecorder) incTotalChunks()            { r.totalChunks.Add(1) }
+func (r *statsRecorder) incChunksPersisted(n int64) { r.chunksPersisted.Add(n) }
+func (r *statsRecorder) incErrors()                 { r.errors.Add(1) }
+func (r *statsRecorder) incFlush()                  { r.flushCount.Add(1) }
+func (r *statsRecorder) incDegradations()           { r.degradations.Add(1) }
+
+// observeFlushLatency records the duration of one flush call (lock-free).
+func (r *statsRecorder) observeFlushLatency(d time.Duration) {
+	n := int64(d)
+	r.flushNanos.Add(n)
+	r.flushBuckets[metrics.LatencyBucket(d)].Add(1)
+	for {
+		cur := r.flushMaxNanos.Load()
+		if n <= cur || r.flushMaxNanos.CompareAndSwap(cur, n) {
+			return
+		}
+	}
+}
+
+// flushHistogram reads the flush latency histogram. Like the other counters
+// it is read field by field (relaxed consistency).
+func (r *statsRecorder) flushHistogram() metrics.Histogram {
+	h := metrics.Histogram{Sum: time.Duration(r.flushNanos.Load())}
+	for i := range r.flushBuckets {
+		h.Counts[i] = r.flushBuckets[i].Load()
+		h.Count += h.Counts[i]
+	}
+	return h
+}
 
 func (r *statsRecorder) snapshot() Stats {
 	s := Stats{
-		TotalEvents:     r.totalEvents.Load(),
-		EventsPersisted: r.eventsPersisted.Load(),
-		EventsDropped:   r.eventsDropped.Load(),
-		TotalChunks:     r.totalChunks.Load(),
-		ChunksPersisted: r.chunksPersisted.Load(),
-		BufferSize:      r.bufferSize.Load(),
-		FlushCount:      r.flushCount.Load(),
-		Errors:          r.errors.Load(),
-		DroppedByType:   make(map[types.EventType]int64, len(r.droppedByType)),
+		TotalEvents:       r.totalEvents.Load(),
+		EventsPersisted:   r.eventsPersisted.Load(),
+		EventsDropped:     r.eventsDropped.Load(),
+		TotalChunks:       r.totalChunks.Load(),
+		ChunksPersisted:   r.chunksPersisted.Load(),
+		BufferSize:        r.bufferSize.Load(),
+		FlushCount:        r.flushCount.Load(),
+		Errors:            r.errors.Load(),
+		FlushLatencyTotal: time.Duration(r.flushNanos.Load()),
+		FlushLatencyMax:   time.Duration(r.flushMaxNanos.Load()),
+		DroppedByType:     make(map[types.EventType]int64, len(r.droppedByType)),
+		Degradations:      r.degradations.Load(),
 	}
+	s.FlushLatencyHistogram = r.flushHistogram()
 	for k, v := range r.droppedByType {
 		s.DroppedByType[k] = v
 	}
@@ -179,16 +226,20 @@ func (r *statsRecorder) setBufferSizeLocked(bytes int64)  { r.bufferSize.Store(b
 // Caller must hold its policy mu (required for droppedByType map access).
 func (r *statsRecorder) snapshotLocked(bufferSize int64) Stats {
 	s := Stats{
-		TotalEvents:     r.totalEvents.Load(),
-		EventsPersisted: r.eventsPersisted.Load(),
-		EventsDropped:   r.eventsDropped.Load(),
-		TotalChunks:     r.totalChunks.Load(),
-		ChunksPersisted: r.chunksPersisted.Load(),
-		BufferSize:      bufferSize,
-		FlushCount:      r.flushCount.Load(),
-		Errors:          r.errors.Load(),
-		DroppedByType:   make(map[types.EventType]int64, len(r.droppedByType)),
+		TotalEvents:       r.totalEvents.Load(),
+		EventsPersisted:   r.eventsPersisted.Load(),
+		EventsDropped:     r.eventsDropped.Load(),
+		TotalChunks:       r.totalChunks.Load(),
+		ChunksPersisted:   r.chunksPersisted.Load(),
+		BufferSize:        bufferSize,
+		FlushCount:        r.flushCount.Load(),
+		Errors:            r.errors.Load(),
+		FlushLatencyTotal: time.Duration(r.flushNanos.Load()),
+		FlushLatencyMax:   time.Duration(r.flushMaxNanos.Load()),
+		DroppedByType:     make(map[types.EventType]int64, len(r.droppedByType)),
+		Degradations:      r.degradations.Load(),
 	}
+	s.FlushLatencyHistogram = r.flushHistogram()
 	for k, v := range r.droppedByType {
 		s.DroppedByType[k] = v
 	}
@@ -2,10 +2,13 @@ package policy
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
+	"github.com/pithecene-io/quarry/clock"
 	"github.com/pithecene-io/quarry/log"
 	"github.com/pithecene-io/quarry/types"
 )
@@ -31,8 +34,24 @@ type StreamingConfig struct {
 	// Zero means interval-based flush is disabled.
 	FlushInterval time.Duration
 
+	// FlushIdle triggers a flush once no event or chunk has been ingested
+	// for this long. Each ingest restarts the idle timer.
+	// Zero means idle-based flush is disabled.
+	FlushIdle time.Duration
+
+	// FlushSequence, if non-nil, numbers each flush that persists events.
+	// The flush's single Sink.WriteEvents call carries its FlushInfo in the
+	// context (see FlushInfoFromContext). Sequences start at 1 and are
+	// gap-free: a sequence is consumed only once its write succeeds. The
+	// termination flush writes the _complete marker to FlushSequence.
+	FlushSequence FileSink
+
 	// Logger is an optional logger for policy observability.
 	Logger *log.Logger
+
+	// Clock drives the interval and idle timers and flush latency.
+	// Nil means clock.Real.
+	Clock clock.Clock
 }
 
 // FlushTrigger identifies which trigger caused a flush.
@@ -49,10 +68,12 @@ const (
 	// Fired when buffer is at internal capacity after an append, regardless
 	// of whether the event count threshold was reached.
 	FlushTriggerCapacity FlushTrigger = "capacity"
+	// FlushTriggerIdle indicates a flush after FlushIdle without ingestion.
+	FlushTriggerIdle FlushTrigger = "idle"
 )
 
 // ErrStreamingInvalidConfig is returned when StreamingConfig is invalid.
-var ErrStreamingInvalidConfig = errors.New("invalid streaming config: at least one of FlushCount or FlushInterval must be set")
+var ErrStreamingInvalidConfig = errors.New("invalid streaming config: at least one of FlushCount, FlushInterval or FlushIdle must be set")
 
 // StreamingPolicy implements continuous persistence with batched writes.
 //
@@ -65,6 +86,8 @@ var ErrStreamingInvalidConfig = errors.New("invalid streaming config: at least o
 //
 // Flush semantics: chunks first, then events (equivalent to chunks_first).
 // On flush failure, buffer is preserved and retried on next trigger.
+// With FlushSequence set, each flush's event write is tagged with its flush
+// sequence, and the termination flush writes _complete.
 //
 // Thread safety:
 //   - mu guards buffer state (append, size tracking, stats)
@@ -76,6 +99,7 @@ type StreamingPolicy struct {
 	sink   Sink
 	config StreamingConfig
 	logger *log.Logger
+	clock  clock.Clock
 
 	mu          sync.Mutex // guards buffer state and stats
 	bufferCond  *sync.Cond // signaled after flush drains buffer; uses mu as locker
@@ -94,8 +118,18 @@ type StreamingPolicy struct {
 	flushByInterval    int64
 	flushByTermination int64
 	flushByCapacity    int64
+	flushByIdle        int64
+
+	// Flush sequence state. Guarded by flushMu.
+	flushSeq        int64
+	flushSeqEvents  int64
+	completeWritten bool
+
+	// activity wakes the idle goroutine after an ingest. Buffered (1) so
+	// ingestion never blocks on it; nil when FlushIdle is disabled.
+	activity chan struct{}
 
-	// stopCh signals the interval goroutine to stop.
+	// stopCh signals the interval and idle goroutines to stop.
 	stopCh chan struct{}
 	// stopped indicates Close has been called. Guarded by mu.
 	stopped bool
@@ -104,7 +138,7 @@ type StreamingPolicy struct {
 // NewStreamingPolicy creates a new streaming policy.
 // Returns error if config is invalid.
 func NewStreamingPolicy(sink Sink, config StreamingConfig) (*StreamingPolicy, error) {
-	if config.FlushCount <= 0 && config.FlushInterval <= 0 {
+	if config.FlushCount <= 0 && config.FlushInterval <= 0 && config.FlushIdle <= 0 {
 		return nil, ErrStreamingInvalidConfig
 	}
 
@@ -112,6 +146,7 @@ func NewStreamingPolicy(sink Sink, config StreamingConfig) (*StreamingPolicy, er
 		sink:        sink,
 		config:      config,
 		logger:      config.Logger,
+		clock:       clock.Or(config.Clock),
 		eventBuffer: make([]*types.EventEnvelope, 0, 128),
 		chunkBuffer: make([]*types.ArtifactChunk, 0),
 		stats:       newStatsRecorder(),
@@ -119,9 +154,16 @@ func NewStreamingPolicy(sink Sink, config StreamingConfig) (*StreamingPolicy, er
 	}
 	p.bufferCond = sync.NewCond(&p.mu)
 
-	// Start interval flush goroutine if configured
+	// Timers are created before the goroutines start so that time
+	// advanced after construction is never missed
 	if config.FlushInterval > 0 {
-		go p.intervalLoop()
+		go p.intervalLoop(p.clock.NewTicker(config.FlushInterval))
+	}
+	if config.FlushIdle > 0 {
+		p.activity = make(chan struct{}, 1)
+		timer := p.clock.NewTimer(config.FlushIdle)
+		timer.Stop()
+		go p.idleLoop(timer)
 	}
 
 	return p, nil
@@ -150,6 +192,7 @@ func (p *StreamingPolicy) IngestEvent(ctx context.Context, envelope *types.Event
 	countTriggered := p.config.FlushCount > 0 && len(p.eventBuffer) >= p.config.FlushCount
 	capacityTriggered := !countTriggered && p.isBufferFullLocked()
 	p.mu.Unlock()
+	p.signalActivity()
 
 	if countTriggered {
 		return p.triggerFlush(ctx, FlushTriggerCount)
@@ -185,6 +228,7 @@ func (p *StreamingPolicy) IngestArtifactChunk(ctx context.Context, chunk *types.
 	// contribute to the count threshold.
 	shouldFlush := p.isBufferFullLocked()
 	p.mu.Unlock()
+	p.signalActivity()
 
 	if shouldFlush {
 		return p.triggerFlush(ctx, FlushTriggerCapacity)
@@ -205,9 +249,12 @@ func (p *StreamingPolicy) Flush(ctx context.Context) error {
 // Strategy: swap buffers under mu, write outside mu, restore on failure.
 // This allows IngestEvent/IngestArtifactChunk to continue appending to
 // fresh buffers during a write, without blocking on the sink.
+// Flush latency (including failed attempts) is recorded in Stats.
 func (p *StreamingPolicy) triggerFlush(ctx context.Context, trigger FlushTrigger) error {
 	p.flushMu.Lock()
 	defer p.flushMu.Unlock()
+	start := p.clock.Now()
+	defer func() { p.stats.observeFlushLatency(p.clock.Now().Sub(start)) }()
 
 	// Swap buffers under mu
 	p.mu.Lock()
@@ -222,6 +269,8 @@ func (p *StreamingPolicy) triggerFlush(ctx context.Context, trigger FlushTrigger
 		p.flushByTermination++
 	case FlushTriggerCapacity:
 		p.flushByCapacity++
+	case FlushTriggerIdle:
+		p.flushByIdle++
 	}
 
 	p.stats.incFlushLocked()
@@ -229,10 +278,10 @@ func (p *StreamingPolicy) triggerFlush(ctx context.Context, trigger FlushTrigger
 	events := p.eventBuffer
 	chunks := p.chunkBuffer
 
-	// Nothing to flush
+	// Nothing to flush; still write the _complete marker on termination
 	if len(events) == 0 && len(chunks) == 0 {
 		p.mu.Unlock()
-		return nil
+		return p.writeFlushComplete(ctx, trigger)
 	}
 
 	// Install fresh buffers so ingestion can continue during write
@@ -265,7 +314,11 @@ func (p *StreamingPolicy) triggerFlush(ctx context.Context, trigger FlushTrigger
 
 	// Write events
 	if len(events) > 0 {
-		if err := p.sink.WriteEvents(ctx, events); err != nil {
+		writeCtx := ctx
+		if p.config.FlushSequence != nil {
+			writeCtx = WithFlushInfo(ctx, FlushInfo{Seq: p.flushSeq + 1, Trigger: trigger})
+		}
+		if err := p.sink.WriteEvents(writeCtx, events); err != nil {
 			// Chunks succeeded; restore only events
 			p.mu.Lock()
 			p.stats.incErrorsLocked()
@@ -278,14 +331,40 @@ func (p *StreamingPolicy) triggerFlush(ctx context.Context, trigger FlushTrigger
 		p.mu.Lock()
 		p.stats.incEventsPersistedLocked(int64(len(events)))
 		p.mu.Unlock()
+		if p.config.FlushSequence != nil {
+			p.flushSeq++
+			p.flushSeqEvents += int64(len(events))
+		}
 	}
 
 	p.logFlush(trigger, len(events), len(chunks))
 
+	return p.writeFlushComplete(ctx, trigger)
+}
+
+// writeFlushComplete writes the _complete marker on the termination flush
+// when flush sequencing is enabled. A failed write is retried by the next
+// termination flush. Caller must hold flushMu.
+func (p *StreamingPolicy) writeFlushComplete(ctx context.Context, trigger FlushTrigger) error {
+	if p.config.FlushSequence == nil || trigger != FlushTriggerTermination || p.completeWritten {
+		return nil
+	}
+	data, err := json.Marshal(FlushComplete{LastFlushSeq: p.flushSeq, EventCount: p.flushSeqEvents})
+	if err == nil {
+		err = p.config.FlushSequence.PutFile(ctx, FlushCompleteName, flushCompleteContentType, data)
+	}
+	if err != nil {
+		p.mu.Lock()
+		p.stats.incErrorsLocked()
+		p.mu.Unlock()
+		p.logFlushFailure("flush_complete", trigger, err)
+		return fmt.Errorf("flush marker %s: %w", FlushCompleteName, err)
+	}
+	p.completeWritten = true
 	return nil
 }
 
-// Close stops the interval goroutine and closes the sink.
+// Close stops the interval and idle goroutines and closes the sink.
 func (p *StreamingPolicy) Close() error {
 	p.mu.Lock()
 	if !p.stopped {
@@ -313,6 +392,7 @@ func (p *StreamingPolicy) Stats() Stats {
 		string(FlushTriggerInterval):    p.flushByInterval,
 		string(FlushTriggerTermination): p.flushByTermination,
 		string(FlushTriggerCapacity):    p.flushByCapacity,
+		string(FlushTriggerIdle):        p.flushByIdle,
 	}
 	return s
 }
@@ -328,17 +408,17 @@ func (p *StreamingPolicy) FlushTriggerStats() map[FlushTrigger]int64 {
 		FlushTriggerInterval:    p.flushByInterval,
 		FlushTriggerTermination: p.flushByTermination,
 		FlushTriggerCapacity:    p.flushByCapacity,
+		FlushTriggerIdle:        p.flushByIdle,
 	}
 }
 
-// intervalLoop runs in a goroutine and triggers flushes on the configured interval.
-func (p *StreamingPolicy) intervalLoop() {
-	ticker := time.NewTicker(p.config.FlushInterval)
+// intervalLoop runs in a goroutine and triggers flushes on each tick.
+func (p *StreamingPolicy) intervalLoop(ticker clock.Ticker) {
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
+		case <-ticker.C():
 			p.mu.Lock()
 			hasData := len(p.eventBuffer) > 0 || len(p.chunkBuffer) > 0
 			p.mu.Unlock()
@@ -353,6 +433,43 @@ func (p *StreamingPolicy) intervalLoop() {
 	}
 }
 
+// signalActivity restarts the idle timer. Non-blocking: a pending signal
+// already covers this ingest.
+func (p *StreamingPolicy) signalActivity() {
+	if p.activity == nil {
+		return
+	}
+	select {
+	case p.activity <- struct{}{}:
+	default:
+	}
+}
+
+// idleLoop runs in a goroutine and triggers a flush once FlushIdle elapses
+// with no ingest. The timer is armed by the first ingest after a flush, so
+// an idle policy does not flush repeatedly. timer starts stopped.
+func (p *StreamingPolicy) idleLoop(timer clock.Timer) {
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-p.activity:
+			timer.Reset(p.config.FlushIdle)
+		case <-timer.C():
+			p.mu.Lock()
+			hasData := len(p.eventBuffer) > 0 || len(p.chunkBuffer) > 0
+			p.mu.Unlock()
+
+			if hasData {
+				// Best-effort idle flush — errors logged but not fatal
+				_ = p.triggerFlush(context.Background(), FlushTriggerIdle)
+			}
+		case <-p.stopCh:
+			return
+		}
+	}
+}
+
 // estimateEventSize delegates to the package-level estimateEventSize.
 func (p *StreamingPolicy) estimateEventSize(envelope *types.EventEnvelope) int64 {
 	return estimateEventSize(envelope)
@@ -2,10 +2,13 @@ package policy_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/pithecene-io/quarry/clock"
 	"github.com/pithecene-io/quarry/iox"
 	"github.com/pithecene-io/quarry/policy"
 	"github.com/pithecene-io/quarry/types"
@@ -490,28 +493,60 @@ func TestStreamingPolicy_FlushTriggerStats(t *testing.T) {
 	}
 }
 
+// streamingNotifySink signals written after each successful WriteEvents,
+// so fake-clock tests can wait for a timer-driven flush without sleeping.
+type streamingNotifySink struct {
+	*policy.StubSink
+	written chan struct{}
+}
+
+func newStreamingNotifySink() *streamingNotifySink {
+	return &streamingNotifySink{StubSink: policy.NewStubSink(), written: make(chan struct{}, 16)}
+}
+
+func (s *streamingNotifySink) WriteEvents(ctx context.Context, events []*types.EventEnvelope) error {
+	err := s.StubSink.WriteEvents(ctx, events)
+	if err == nil {
+		s.written <- struct{}{}
+	}
+	return err
+}
+
+func (s *streamingNotifySink) waitWritten(t *testing.T) {
+	t.Helper()
+	select {
+	case <-s.written:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a timer-driven flush")
+	}
+}
+
 func TestStreamingPolicy_IntervalTrigger(t *testing.T) {
-	sink := policy.NewStubSink()
+	sink := newStreamingNotifySink()
+	clk := clock.NewFake(time.Date(2026, 2, 23, 23, 59, 0, 0, time.UTC))
 	pol := mustNewStreamingPolicy(t, sink, policy.StreamingConfig{
-		FlushInterval: 50 * time.Millisecond,
+		FlushInterval: time.Minute,
+		Clock:         clk,
 	})
 
-	// Ingest an event
 	_ = pol.IngestEvent(t.Context(), &types.EventEnvelope{
 		EventID: "e1", Type: types.EventTypeItem, Seq: 1,
 	})
+	clk.Advance(time.Minute)
+	sink.waitWritten(t)
 
-	// Wait for interval to fire
-	time.Sleep(150 * time.Millisecond)
+	// Each interval flushes what accumulated since the previous one
+	_ = pol.IngestEvent(t.Context(), &types.EventEnvelope{
+		EventID: "e2", Type: types.EventTypeItem, Seq: 2,
+	})
+	clk.Advance(time.Minute)
+	sink.waitWritten(t)
 
-	// Event should have been flushed by interval
-	if sink.Stats().EventsWritten != 1 {
-		t.Errorf("expected 1 event written by interval flush, got %d", sink.Stats().EventsWritten)
+	if got := sink.Stats().EventBatches; got != 2 {
+		t.Errorf("expected 2 event batches, got %d", got)
 	}
-
-	triggerStats := pol.FlushTriggerStats()
-	if triggerStats[policy.FlushTriggerInterval] < 1 {
-		t.Errorf("expected at least 1 interval trigger, got %d", triggerStats[policy.FlushTriggerInterval])
+	if got := pol.FlushTriggerStats()[policy.FlushTriggerInterval]; got != 2 {
+		t.Errorf("expected 2 interval triggers, got %d", got)
 	}
 }
 
@@ -530,6 +565,68 @@ func TestStreamingPolicy_IntervalSkipsEmptyBuffer(t *testing.T) {
 	}
 }
 
+func TestStreamingPolicy_ValidConfig_OnlyIdle(t *testing.T) {
+	sink := policy.NewStubSink()
+	pol, err := policy.NewStreamingPolicy(sink, policy.StreamingConfig{FlushIdle: time.Second})
+	if err != nil {
+		t.Fatalf("expected success, got %v", err)
+	}
+	iox.DiscardClose(pol)
+}
+
+func TestStreamingPolicy_IdleTrigger(t *testing.T) {
+	sink := newStreamingNotifySink()
+	clk := clock.NewFake(time.Date(2026, 2, 23, 23, 59, 0, 0, time.UTC))
+	pol := mustNewStreamingPolicy(t, sink, policy.StreamingConfig{
+		FlushIdle: time.Minute,
+		Clock:     clk,
+	})
+
+	_ = pol.IngestEvent(t.Context(), &types.EventEnvelope{
+		EventID: "e1", Type: types.EventTypeItem, Seq: 1,
+	})
+
+	// The ingest arms the idle timer; an hour of silence fires it once
+	clk.BlockUntil(1)
+	clk.Advance(time.Hour)
+	sink.waitWritten(t)
+
+	if sink.Stats().EventsWritten != 1 {
+		t.Errorf("expected 1 event written by idle flush, got %d", sink.Stats().EventsWritten)
+	}
+	// The timer is not re-armed until the next ingest
+	if got := pol.FlushTriggerStats()[policy.FlushTriggerIdle]; got != 1 {
+		t.Errorf("expected 1 idle trigger, got %d", got)
+	}
+}
+
+func TestStreamingPolicy_IdleResetOnIngest(t *testing.T) {
+	sink := policy.NewStubSink()
+	pol := mustNewStreamingPolicy(t, sink, policy.StreamingConfig{
+		FlushIdle: 150 * time.Millisecond,
+	})
+
+	// Keep ingesting faster than the idle window; no idle flush may fire
+	for i := 1; i <= 5; i++ {
+		_ = pol.IngestEvent(t.Context(), &types.EventEnvelope{
+			EventID: fmt.Sprintf("e%d", i), Type: types.EventTypeItem, Seq: int64(i),
+		})
+		time.Sleep(50 * time.Millisecond)
+	}
+	if sink.Stats().EventBatches != 0 {
+		t.Fatalf("expected no flush while events keep arriving, got %d batches", sink.Stats().EventBatches)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+
+	if sink.Stats().EventsWritten != 5 {
+		t.Errorf("expected 5 events written by idle flush, got %d", sink.Stats().EventsWritten)
+	}
+	if got := pol.FlushTriggerStats()[policy.FlushTriggerIdle]; got != 1 {
+		t.Errorf("expected 1 idle trigger, got %d", got)
+	}
+}
+
 func TestStreamingPolicy_Close_FlushesAndStops(t *testing.T) {
 	sink := policy.NewStubSink()
 	pol, err := policy.NewStreamingPolicy(sink, policy.StreamingConfig{
@@ -782,6 +879,28 @@ func TestStreamingPolicy_Stats_FlushTriggers_InStats(t *testing.T) {
 	}
 }
 
+func TestStreamingPolicy_Stats_FlushLatencyHistogram(t *testing.T) {
+	sink := policy.NewStubSink()
+	pol := mustNewStreamingPolicy(t, sink, policy.StreamingConfig{FlushCount: 2})
+
+	_ = pol.IngestEvent(t.Context(), &types.EventEnvelope{
+		EventID: "e1", Type: types.EventTypeItem, Seq: 1,
+	})
+	_ = pol.IngestEvent(t.Context(), &types.EventEnvelope{
+		EventID: "e2", Type: types.EventTypeItem, Seq: 2,
+	})
+	_ = pol.Flush(t.Context())
+
+	stats := pol.Stats()
+	hist := stats.FlushLatencyHistogram
+	if hist.Count != stats.FlushCount {
+		t.Errorf("histogram count = %d, want one observation per flush (%d)", hist.Count, stats.FlushCount)
+	}
+	if hist.Sum != stats.FlushLatencyTotal {
+		t.Errorf("histogram sum = %s, want FlushLatencyTotal %s", hist.Sum, stats.FlushLatencyTotal)
+	}
+}
+
 func TestStreamingPolicy_Stats_FlushTriggers_NilForOtherPolicies(t *testing.T) {
 	sink := policy.NewStubSink()
 	pol := policy.NewStrictPolicy(sink)
@@ -979,3 +1098,115 @@ func (s *streamingSlowSink) WriteChunks(ctx context.Context, chunks []*types.Art
 	time.Sleep(s.writeDelay)
 	return s.StubSink.WriteChunks(ctx, chunks)
 }
+
+// streamingSeqSink records the FlushInfo of each event write.
+type streamingSeqSink struct {
+	*policy.StubSink
+	flushes []policy.FlushInfo
+	sizes   []int
+}
+
+func (s *streamingSeqSink) WriteEvents(ctx context.Context, events []*types.EventEnvelope) error {
+	if err := s.StubSink.WriteEvents(ctx, events); err != nil {
+		return err
+	}
+	info, _ := policy.FlushInfoFromContext(ctx)
+	s.flushes = append(s.flushes, info)
+	s.sizes = append(s.sizes, len(events))
+	return nil
+}
+
+// streamingFileSink records files for FlushSequence tests.
+type streamingFileSink struct {
+	names []string
+	files map[string][]byte
+	err   error
+}
+
+func (s *streamingFileSink) PutFile(_ context.Context, filename, _ string, data []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.files == nil {
+		s.files = make(map[string][]byte)
+	}
+	s.names = append(s.names, filename)
+	s.files[filename] = data
+	return nil
+}
+
+func TestStreamingPolicy_FlushSequence_SequenceAndComplete(t *testing.T) {
+	sink := &streamingSeqSink{StubSink: policy.NewStubSink()}
+	files := &streamingFileSink{}
+	pol := mustNewStreamingPolicy(t, sink, policy.StreamingConfig{FlushCount: 2, FlushSequence: files})
+
+	for i := 1; i <= 5; i++ {
+		if err := pol.IngestEvent(t.Context(), &types.EventEnvelope{EventID: fmt.Sprintf("e%d", i), Type: types.EventTypeItem, Seq: int64(i)}); err != nil {
+			t.Fatalf("IngestEvent: %v", err)
+		}
+	}
+	// Chunk-only flushes persist no events and must not consume a sequence
+	_ = pol.IngestArtifactChunk(t.Context(), &types.ArtifactChunk{ArtifactID: "a", Seq: 1, Data: []byte("x")})
+	if err := pol.Flush(t.Context()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if err := pol.Flush(t.Context()); err != nil {
+		t.Fatalf("second Flush: %v", err)
+	}
+
+	// One event write per flush, each tagged with the next sequence
+	want := []policy.FlushInfo{
+		{Seq: 1, Trigger: policy.FlushTriggerCount},
+		{Seq: 2, Trigger: policy.FlushTriggerCount},
+		{Seq: 3, Trigger: policy.FlushTriggerTermination},
+	}
+	if fmt.Sprint(sink.flushes) != fmt.Sprint(want) {
+		t.Fatalf("flushes = %v, want %v", sink.flushes, want)
+	}
+	if fmt.Sprint(sink.sizes) != fmt.Sprint([]int{2, 2, 1}) {
+		t.Errorf("write sizes = %v, want [2 2 1]", sink.sizes)
+	}
+
+	// Only the marker is written as a file; events are not duplicated
+	if fmt.Sprint(files.names) != fmt.Sprint([]string{policy.FlushCompleteName}) {
+		t.Fatalf("files = %v, want only %s", files.names, policy.FlushCompleteName)
+	}
+	var complete policy.FlushComplete
+	if err := json.Unmarshal(files.files[policy.FlushCompleteName], &complete); err != nil {
+		t.Fatalf("unmarshal _complete: %v", err)
+	}
+	if complete.LastFlushSeq != 3 || complete.EventCount != 5 {
+		t.Errorf("_complete = %+v, want last_flush_seq=3 event_count=5", complete)
+	}
+}
+
+func TestStreamingPolicy_FlushSequence_FailureRetriedWithoutGap(t *testing.T) {
+	sink := &streamingSeqSink{StubSink: policy.NewStubSink()}
+	sink.ErrorOnWrite = errors.New("write failed")
+	pol := mustNewStreamingPolicy(t, sink, policy.StreamingConfig{FlushCount: 100, FlushSequence: &streamingFileSink{}})
+
+	_ = pol.IngestEvent(t.Context(), &types.EventEnvelope{EventID: "e1", Type: types.EventTypeItem, Seq: 1})
+	if err := pol.Flush(t.Context()); err == nil {
+		t.Fatal("expected write failure")
+	}
+
+	// The retried batch keeps sequence 1
+	sink.ErrorOnWrite = nil
+	_ = pol.IngestEvent(t.Context(), &types.EventEnvelope{EventID: "e2", Type: types.EventTypeItem, Seq: 2})
+	if err := pol.Flush(t.Context()); err != nil {
+		t.Fatalf("retry Flush: %v", err)
+	}
+	if len(sink.flushes) != 1 || sink.flushes[0].Seq != 1 || sink.sizes[0] != 2 {
+		t.Errorf("flushes = %v sizes = %v, want one write of 2 events at seq 1", sink.flushes, sink.sizes)
+	}
+}
+
+func TestStreamingPolicy_FlushSequence_DisabledLeavesContextUntagged(t *testing.T) {
+	sink := &streamingSeqSink{StubSink: policy.NewStubSink()}
+	pol := mustNewStreamingPolicy(t, sink, policy.StreamingConfig{FlushCount: 1})
+
+	_ = pol.IngestEvent(t.Context(), &types.EventEnvelope{EventID: "e1", Type: types.EventTypeItem, Seq: 1})
+	if len(sink.flushes) != 1 || sink.flushes[0] != (policy.FlushInfo{}) {
+		t.Errorf("flushes = %v, want one untagged write", sink.flushes)
+	}
+}
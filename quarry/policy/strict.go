@@ -2,10 +2,97 @@ package policy
 
 import (
 	"context"
+	"fmt"
+	"sync"
+	"time"
 
+	"github.com/pithecene-io/quarry/clock"
 	"github.com/pithecene-io/quarry/types"
 )
 
+// StrictConfig configures micro-batching for a StrictPolicy.
+// The zero value disables batching (each event is written immediately).
+type StrictConfig struct {
+	// BatchSize writes a batch once N events accumulate.
+	// Zero or one means no size-based batching.
+	BatchSize int
+
+	// BatchWindow writes a batch once the oldest pending event is this old.
+	// Zero means no time-based batching.
+	BatchWindow time.Duration
+
+	// DegradeBuffer enables degraded mode: on a retryable sink error, up to
+	// N unwritten events are held in memory while the write is retried with
+	// backoff. Zero fails the run on the first sink error.
+	DegradeBuffer int
+
+	// DegradeRetries is how many failed retries end degraded mode with a
+	// run failure. Zero means DefaultDegradeRetries.
+	DegradeRetries int
+
+	// DegradeBackoff is the delay before the first retry, doubled per
+	// retry up to maxDegradeBackoff. Zero means DefaultDegradeBackoff.
+	DegradeBackoff time.Duration
+
+	// Retryable reports whether a sink error is transient. Only retryable
+	// errors degrade; nil treats every error as fatal.
+	Retryable func(error) bool
+
+	// Clock drives the degraded-mode retry backoff. Nil means clock.Real.
+	Clock clock.Clock
+}
+
+// Degraded mode defaults.
+const (
+	DefaultDegradeRetries = 5
+	DefaultDegradeBackoff = 250 * time.Millisecond
+	maxDegradeBackoff     = 10 * time.Second
+)
+
+// batching reports whether events are micro-batched.
+func (c StrictConfig) batching() bool {
+	return c.BatchSize > 1 || c.BatchWindow > 0
+}
+
+// degrading reports whether retryable sink errors degrade to buffering.
+func (c StrictConfig) degrading() bool {
+	return c.DegradeBuffer > 0 && c.Retryable != nil
+}
+
+// locked reports whether ingest calls serialize on mu: batching or
+// degraded mode keep state between calls.
+func (c StrictConfig) locked() bool {
+	return c.batching() || c.degrading()
+}
+
+// retryable reports whether err may degrade instead of failing the run.
+func (c StrictConfig) retryable(err error) bool {
+	return c.degrading() && c.Retryable(err)
+}
+
+// maxRetries returns DegradeRetries or its default.
+func (c StrictConfig) maxRetries() int {
+	if c.DegradeRetries > 0 {
+		return c.DegradeRetries
+	}
+	return DefaultDegradeRetries
+}
+
+// backoff returns the delay before retry n (0-based).
+func (c StrictConfig) backoff(n int) time.Duration {
+	d := c.DegradeBackoff
+	if d <= 0 {
+		d = DefaultDegradeBackoff
+	}
+	for range n {
+		d *= 2
+		if d >= maxDegradeBackoff {
+			return maxDegradeBackoff
+		}
+	}
+	return d
+}
+
 // StrictPolicy implements synchronous, unbuffered persistence.
 //
 // Per CONTRACT_POLICY.md:
@@ -13,33 +100,99 @@ import (
 //   - No drops: all events are persisted
 //   - Backpressure: caller blocks on sink latency
 //   - Sink errors fail the run
+//
+// With StrictConfig batching enabled, events are held in a micro-batch that
+// is written when it reaches BatchSize, when BatchWindow elapses, or on a
+// terminal event (run_complete, run_error) or Flush. Chunks are still written
+// immediately, so they always precede the artifact event that commits them.
+// A failed batch write fails the run: the error is returned by the ingest
+// call that triggered the write, or, for a window-expiry write, by the next
+// ingest or Flush call.
+//
+// With StrictConfig.DegradeBuffer set, a retryable event write failure
+// degrades the policy instead: the unwritten events are held in a backlog,
+// later events join it, and the whole backlog is retried with backoff on
+// ingest calls once the delay has passed. A terminal event, Flush, or Close
+// waits out the backoff until the backlog is written, releasing mu while it
+// waits so concurrent calls are not stalled. The run fails only
+// when the backlog exceeds DegradeBuffer, the retries are exhausted, or a
+// retry fails with a non-retryable error. A retryable chunk write failure
+// is retried in place with the same backoff; chunks are never held.
 type StrictPolicy struct {
-	sink Sink
+	sink   Sink
+	config StrictConfig
+	clock  clock.Clock
+
+	// mu serializes sink writes and guards the pending batch.
+	mu      sync.Mutex
+	pending []*types.EventEnvelope
+	timer   *time.Timer
+	// err is a sticky batch write failure from a window-expiry write or
+	// degraded mode.
+	err    error
+	closed bool
+
+	// Degraded mode state, nil backlog when healthy.
+	backlog   []*types.EventEnvelope
+	cause     error     // sink error that started degraded mode
+	retries   int       // failed backlog retries so far
+	nextRetry time.Time // earliest time for the next backlog retry
 
 	stats *statsRecorder
 }
 
 // NewStrictPolicy creates a new strict policy writing to the given sink.
 func NewStrictPolicy(sink Sink) *StrictPolicy {
+	return NewStrictPolicyWithConfig(sink, StrictConfig{})
+}
+
+// NewStrictPolicyWithConfig creates a strict policy with optional
+// micro-batching of events.
+func NewStrictPolicyWithConfig(sink Sink, config StrictConfig) *StrictPolicy {
 	return &StrictPolicy{
-		sink:  sink,
-		stats: newStatsRecorder(),
+		sink:   sink,
+		config: config,
+		clock:  clock.Or(config.Clock),
+		stats:  newStatsRecorder(),
 	}
 }
 
-// IngestEvent writes the event immediately to the sink.
+// IngestEvent writes the event immediately to the sink, or adds it to the
+// pending batch when batching is enabled.
 // Returns error on sink failure (terminates run).
 func (p *StrictPolicy) IngestEvent(ctx context.Context, envelope *types.EventEnvelope) error {
 	p.stats.incTotalEvents()
 
-	// Write immediately (batch of 1)
-	if err := p.sink.WriteEvents(ctx, []*types.EventEnvelope{envelope}); err != nil {
-		p.stats.incErrors()
-		return err
+	if !p.config.locked() {
+		// Write immediately (batch of 1)
+		if err := p.sink.WriteEvents(ctx, []*types.EventEnvelope{envelope}); err != nil {
+			p.stats.incErrors()
+			return err
+		}
+		p.stats.incEventsPersisted(1)
+		return nil
 	}
 
-	p.stats.incEventsPersisted(1)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
 
+	p.pending = append(p.pending, envelope)
+	terminal := envelope.Type == types.EventTypeRunComplete || envelope.Type == types.EventTypeRunError
+	if terminal {
+		if err := p.writePendingLocked(ctx); err != nil {
+			return err
+		}
+		return p.drainLocked(ctx)
+	}
+	if !p.config.batching() || (p.config.BatchSize > 1 && len(p.pending) >= p.config.BatchSize) {
+		return p.writePendingLocked(ctx)
+	}
+	if len(p.pending) == 1 && p.config.BatchWindow > 0 {
+		p.timer = time.AfterFunc(p.config.BatchWindow, p.windowExpired)
+	}
 	return nil
 }
 
@@ -48,10 +201,38 @@ func (p *StrictPolicy) IngestEvent(ctx context.Context, envelope *types.EventEnv
 func (p *StrictPolicy) IngestArtifactChunk(ctx context.Context, chunk *types.ArtifactChunk) error {
 	p.stats.incTotalChunks()
 
-	// Write immediately (batch of 1)
-	if err := p.sink.WriteChunks(ctx, []*types.ArtifactChunk{chunk}); err != nil {
+	if p.config.locked() {
+		// Serialize with event writes; surface a failed window write.
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.err != nil {
+			return p.err
+		}
+	}
+
+	// Write immediately (batch of 1), retrying in place when degrading
+	for attempt := 0; ; attempt++ {
+		err := p.sink.WriteChunks(ctx, []*types.ArtifactChunk{chunk})
+		if err == nil {
+			break
+		}
 		p.stats.incErrors()
-		return err
+		if !p.config.retryable(err) {
+			return err
+		}
+		if attempt == p.config.maxRetries() {
+			p.err = fmt.Errorf("strict degrade: chunk write still failing after %d retries: %w", attempt, err)
+			return p.err
+		}
+		if attempt == 0 && p.backlog == nil {
+			p.stats.incDegradations()
+		}
+		if err := p.backoffLocked(ctx, p.config.backoff(attempt)); err != nil {
+			return err
+		}
+		if p.err != nil {
+			return p.err
+		}
 	}
 
 	p.stats.incChunksPersisted(1)
@@ -59,14 +240,171 @@ func (p *StrictPolicy) IngestArtifactChunk(ctx context.Context, chunk *types.Art
 	return nil
 }
 
-// Flush is a no-op for strict policy (nothing is buffered).
-func (p *StrictPolicy) Flush(_ context.Context) error {
+// Flush writes the pending batch, if any. Without batching nothing is
+// buffered and Flush only counts the call. With batching, flush latency
+// is recorded in Stats.
+//
+// In degraded mode, Flush also retries the backlog until it is written or
+// degraded mode fails.
+func (p *StrictPolicy) Flush(ctx context.Context) error {
 	p.stats.incFlush()
+	if !p.config.locked() {
+		return nil
+	}
+	if p.config.batching() {
+		start := time.Now()
+		defer func() { p.stats.observeFlushLatency(time.Since(start)) }()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	if err := p.writePendingLocked(ctx); err != nil {
+		return err
+	}
+	return p.drainLocked(ctx)
+}
+
+// windowExpired writes the pending batch when BatchWindow elapses.
+// A failure is kept and returned by the next ingest or Flush call.
+func (p *StrictPolicy) windowExpired() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || p.err != nil {
+		return
+	}
+	p.err = p.writePendingLocked(context.Background())
+}
+
+// writePendingLocked writes the pending batch as one sink call.
+// Caller must hold mu.
+func (p *StrictPolicy) writePendingLocked(ctx context.Context) error {
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	if len(p.pending) == 0 {
+		return nil
+	}
+
+	batch := p.pending
+	p.pending = nil
+	return p.writeEventsLocked(ctx, batch)
+}
+
+// writeEventsLocked writes batch as one sink call. In degraded mode the
+// batch joins the backlog, which is retried once its backoff has passed.
+// Caller must hold mu.
+func (p *StrictPolicy) writeEventsLocked(ctx context.Context, batch []*types.EventEnvelope) error {
+	if p.backlog != nil {
+		p.backlog = append(p.backlog, batch...)
+		if len(p.backlog) > p.config.DegradeBuffer {
+			p.err = fmt.Errorf("strict degrade: buffer overflow (%d events > %d) while sink is failing: %w",
+				len(p.backlog), p.config.DegradeBuffer, p.cause)
+			return p.err
+		}
+		if p.clock.Now().Before(p.nextRetry) {
+			return nil
+		}
+		return p.retryBacklogLocked(ctx)
+	}
+
+	err := p.sink.WriteEvents(ctx, batch)
+	if err == nil {
+		p.stats.incEventsPersisted(int64(len(batch)))
+		return nil
+	}
+	p.stats.incErrors()
+	if !p.config.retryable(err) || len(batch) > p.config.DegradeBuffer {
+		return err
+	}
+
+	// Degrade: hold the batch and retry it later
+	p.backlog = append([]*types.EventEnvelope(nil), batch...)
+	p.cause = err
+	p.retries = 0
+	p.nextRetry = p.clock.Now().Add(p.config.backoff(0))
+	p.stats.incDegradations()
 	return nil
 }
 
-// Close closes the underlying sink.
+// retryBacklogLocked writes the backlog as one sink call, leaving degraded
+// mode on success. Caller must hold mu and be in degraded mode.
+func (p *StrictPolicy) retryBacklogLocked(ctx context.Context) error {
+	err := p.sink.WriteEvents(ctx, p.backlog)
+	if err == nil {
+		p.stats.incEventsPersisted(int64(len(p.backlog)))
+		p.backlog, p.cause = nil, nil
+		return nil
+	}
+	p.stats.incErrors()
+	p.retries++
+	switch {
+	case !p.config.retryable(err):
+		p.err = fmt.Errorf("strict degrade: retry of %d buffered events failed: %w", len(p.backlog), err)
+	case p.retries >= p.config.maxRetries():
+		p.err = fmt.Errorf("strict degrade: %d buffered events still failing after %d retries: %w", len(p.backlog), p.retries, err)
+	default:
+		p.nextRetry = p.clock.Now().Add(p.config.backoff(p.retries))
+		return nil
+	}
+	return p.err
+}
+
+// drainLocked waits out the backoff and retries the backlog until it is
+// written or degraded mode fails. No-op when healthy. Caller must hold mu.
+func (p *StrictPolicy) drainLocked(ctx context.Context) error {
+	for p.backlog != nil {
+		if err := p.backoffLocked(ctx, p.nextRetry.Sub(p.clock.Now())); err != nil {
+			return err
+		}
+		// A concurrent call may have retried the backlog while mu was free
+		if p.err != nil {
+			return p.err
+		}
+		if p.backlog == nil || p.clock.Now().Before(p.nextRetry) {
+			continue
+		}
+		if err := p.retryBacklogLocked(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backoffLocked waits d on the policy clock, or until ctx is done, with mu
+// released. Caller must hold mu; it is held again on return.
+func (p *StrictPolicy) backoffLocked(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	p.mu.Unlock()
+	defer p.mu.Lock()
+	t := p.clock.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close writes any pending batch and degraded-mode backlog (best effort)
+// and closes the underlying sink.
 func (p *StrictPolicy) Close() error {
+	if p.config.locked() {
+		p.mu.Lock()
+		if p.err == nil {
+			if p.writePendingLocked(context.Background()) == nil {
+				_ = p.drainLocked(context.Background())
+			}
+		}
+		p.closed = true
+		p.mu.Unlock()
+	}
 	return p.sink.Close()
 }
 
@@ -1,9 +1,13 @@
 package policy_test
 
 import (
+	"context"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/pithecene-io/quarry/clock"
 	"github.com/pithecene-io/quarry/policy"
 	"github.com/pithecene-io/quarry/types"
 )
@@ -204,3 +208,319 @@ func TestStrictPolicy_Close(t *testing.T) {
 		t.Error("sink should be closed after policy Close()")
 	}
 }
+
+func strictEvent(seq int64, et types.EventType) *types.EventEnvelope {
+	return &types.EventEnvelope{EventID: "e", Type: et, RunID: "run-1", Seq: seq}
+}
+
+func TestStrictPolicy_BatchSize(t *testing.T) {
+	sink := policy.NewStubSink()
+	pol := policy.NewStrictPolicyWithConfig(sink, policy.StrictConfig{BatchSize: 3})
+
+	for i := int64(1); i <= 4; i++ {
+		if err := pol.IngestEvent(t.Context(), strictEvent(i, types.EventTypeItem)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if s := sink.Stats(); s.EventBatches != 1 || s.EventsWritten != 3 {
+		t.Errorf("expected one batch of 3, got %d batches / %d events", s.EventBatches, s.EventsWritten)
+	}
+
+	// Terminal event writes the partial batch immediately
+	if err := pol.IngestEvent(t.Context(), strictEvent(5, types.EventTypeRunComplete)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s := sink.Stats(); s.EventBatches != 2 || s.EventsWritten != 5 {
+		t.Errorf("expected terminal event to flush, got %d batches / %d events", s.EventBatches, s.EventsWritten)
+	}
+	if stats := pol.Stats(); stats.EventsPersisted != 5 {
+		t.Errorf("expected EventsPersisted=5, got %d", stats.EventsPersisted)
+	}
+}
+
+func TestStrictPolicy_BatchWindow(t *testing.T) {
+	sink := policy.NewStubSink()
+	pol := policy.NewStrictPolicyWithConfig(sink, policy.StrictConfig{BatchWindow: 20 * time.Millisecond})
+	defer func() { _ = pol.Close() }()
+
+	for i := int64(1); i <= 2; i++ {
+		if err := pol.IngestEvent(t.Context(), strictEvent(i, types.EventTypeItem)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if s := sink.Stats(); s.EventsWritten != 0 {
+		t.Fatalf("events should be pending before the window expires, got %d written", s.EventsWritten)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for sink.Stats().EventsWritten < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if s := sink.Stats(); s.EventBatches != 1 || s.EventsWritten != 2 {
+		t.Errorf("expected window expiry to write one batch of 2, got %d batches / %d events", s.EventBatches, s.EventsWritten)
+	}
+}
+
+func TestStrictPolicy_BatchFlushWritesPending(t *testing.T) {
+	sink := policy.NewStubSink()
+	pol := policy.NewStrictPolicyWithConfig(sink, policy.StrictConfig{BatchSize: 100})
+
+	if err := pol.IngestEvent(t.Context(), strictEvent(1, types.EventTypeItem)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := pol.Flush(t.Context()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if s := sink.Stats(); s.EventsWritten != 1 {
+		t.Errorf("Flush should write the pending batch, got %d written", s.EventsWritten)
+	}
+}
+
+func TestStrictPolicy_BatchSinkErrorFailsRun(t *testing.T) {
+	sink := policy.NewStubSink()
+	expectedErr := errors.New("sink failure")
+	sink.ErrorOnWrite = expectedErr
+	pol := policy.NewStrictPolicyWithConfig(sink, policy.StrictConfig{BatchSize: 2})
+
+	if err := pol.IngestEvent(t.Context(), strictEvent(1, types.EventTypeItem)); err != nil {
+		t.Fatalf("first event should be pending, got %v", err)
+	}
+	if err := pol.IngestEvent(t.Context(), strictEvent(2, types.EventTypeItem)); !errors.Is(err, expectedErr) {
+		t.Errorf("expected batch write error %v, got %v", expectedErr, err)
+	}
+	if stats := pol.Stats(); stats.EventsPersisted != 0 || stats.Errors != 1 {
+		t.Errorf("expected 0 persisted / 1 error, got %d / %d", stats.EventsPersisted, stats.Errors)
+	}
+}
+
+func TestStrictPolicy_BatchWindowErrorIsSticky(t *testing.T) {
+	sink := policy.NewStubSink()
+	expectedErr := errors.New("sink failure")
+	sink.ErrorOnWrite = expectedErr
+	pol := policy.NewStrictPolicyWithConfig(sink, policy.StrictConfig{BatchWindow: 10 * time.Millisecond})
+
+	if err := pol.IngestEvent(t.Context(), strictEvent(1, types.EventTypeItem)); err != nil {
+		t.Fatalf("first event should be pending, got %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for pol.Stats().Errors == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := pol.Flush(t.Context()); !errors.Is(err, expectedErr) {
+		t.Errorf("expected window write failure from Flush, got %v", err)
+	}
+	if err := pol.IngestEvent(t.Context(), strictEvent(2, types.EventTypeItem)); !errors.Is(err, expectedErr) {
+		t.Errorf("expected window write failure from IngestEvent, got %v", err)
+	}
+}
+
+// errTransient marks a retryable sink error in degraded-mode tests.
+var errTransient = errors.New("transient sink failure")
+
+func isTransient(err error) bool { return errors.Is(err, errTransient) }
+
+// flakySink fails the first failEvents event writes and the first
+// failChunks chunk writes with failErr (errTransient when nil).
+type flakySink struct {
+	*policy.StubSink
+	failEvents int
+	failChunks int
+	failErr    error
+}
+
+func (s *flakySink) fail() error {
+	if s.failErr != nil {
+		return s.failErr
+	}
+	return errTransient
+}
+
+func (s *flakySink) WriteEvents(ctx context.Context, events []*types.EventEnvelope) error {
+	if s.failEvents > 0 {
+		s.failEvents--
+		return s.fail()
+	}
+	return s.StubSink.WriteEvents(ctx, events)
+}
+
+func (s *flakySink) WriteChunks(ctx context.Context, chunks []*types.ArtifactChunk) error {
+	if s.failChunks > 0 {
+		s.failChunks--
+		return s.fail()
+	}
+	return s.StubSink.WriteChunks(ctx, chunks)
+}
+
+func degradeConfig(clk clock.Clock, buffer int) policy.StrictConfig {
+	return policy.StrictConfig{
+		DegradeBuffer:  buffer,
+		DegradeRetries: 3,
+		DegradeBackoff: time.Second,
+		Retryable:      isTransient,
+		Clock:          clk,
+	}
+}
+
+func newDegradeClock() *clock.Fake {
+	return clock.NewFake(time.Date(2026, 2, 23, 12, 0, 0, 0, time.UTC))
+}
+
+// withBackoffs runs call in a goroutine and fires n of its backoff timers,
+// advancing clk past the longest backoff each time, then returns its error.
+func withBackoffs(clk *clock.Fake, n int, call func() error) error {
+	done := make(chan error, 1)
+	go func() { done <- call() }()
+	for range n {
+		clk.BlockUntil(1)
+		clk.Advance(time.Minute)
+	}
+	return <-done
+}
+
+func TestStrictPolicy_DegradeRecovers(t *testing.T) {
+	sink := &flakySink{StubSink: policy.NewStubSink(), failEvents: 2}
+	clk := newDegradeClock()
+	pol := policy.NewStrictPolicyWithConfig(sink, degradeConfig(clk, 10))
+
+	for seq := int64(1); seq <= 3; seq++ {
+		if err := pol.IngestEvent(t.Context(), strictEvent(seq, types.EventTypeItem)); err != nil {
+			t.Fatalf("event %d should be buffered, got %v", seq, err)
+		}
+	}
+	// The first drain retry fails again, the second succeeds
+	err := withBackoffs(clk, 2, func() error {
+		return pol.IngestEvent(t.Context(), strictEvent(4, types.EventTypeRunComplete))
+	})
+	if err != nil {
+		t.Fatalf("terminal event should drain the backlog, got %v", err)
+	}
+
+	s := sink.Stats()
+	if s.EventsWritten != 4 {
+		t.Fatalf("expected 4 events written, got %d", s.EventsWritten)
+	}
+	for i, e := range sink.WrittenEvents {
+		if e.Seq != int64(i+1) {
+			t.Errorf("event %d has seq %d, ordering not preserved", i, e.Seq)
+		}
+	}
+	stats := pol.Stats()
+	if stats.Degradations != 1 || stats.EventsPersisted != 4 || stats.Errors != 2 {
+		t.Errorf("expected 1 degradation / 4 persisted / 2 errors, got %d / %d / %d",
+			stats.Degradations, stats.EventsPersisted, stats.Errors)
+	}
+}
+
+func TestStrictPolicy_DegradeFlushDrains(t *testing.T) {
+	sink := &flakySink{StubSink: policy.NewStubSink(), failEvents: 1}
+	clk := newDegradeClock()
+	pol := policy.NewStrictPolicyWithConfig(sink, degradeConfig(clk, 10))
+
+	if err := pol.IngestEvent(t.Context(), strictEvent(1, types.EventTypeItem)); err != nil {
+		t.Fatalf("event should be buffered, got %v", err)
+	}
+	if err := withBackoffs(clk, 1, func() error { return pol.Flush(t.Context()) }); err != nil {
+		t.Fatalf("Flush should drain the backlog, got %v", err)
+	}
+	if s := sink.Stats(); s.EventsWritten != 1 {
+		t.Errorf("expected 1 event written, got %d", s.EventsWritten)
+	}
+}
+
+func TestStrictPolicy_DegradeBackoffReleasesLock(t *testing.T) {
+	sink := &flakySink{StubSink: policy.NewStubSink(), failEvents: 1}
+	clk := newDegradeClock()
+	pol := policy.NewStrictPolicyWithConfig(sink, degradeConfig(clk, 10))
+
+	if err := pol.IngestEvent(t.Context(), strictEvent(1, types.EventTypeItem)); err != nil {
+		t.Fatalf("event should be buffered, got %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- pol.Flush(t.Context()) }()
+
+	// While Flush waits out the backoff, ingest must not block on it
+	clk.BlockUntil(1)
+	if err := pol.IngestEvent(t.Context(), strictEvent(2, types.EventTypeItem)); err != nil {
+		t.Fatalf("event during backoff should be buffered, got %v", err)
+	}
+	clk.Advance(time.Minute)
+	if err := <-done; err != nil {
+		t.Fatalf("Flush should drain the backlog, got %v", err)
+	}
+	if s := sink.Stats(); s.EventsWritten != 2 {
+		t.Errorf("expected 2 events written, got %d", s.EventsWritten)
+	}
+}
+
+func TestStrictPolicy_DegradeBufferOverflow(t *testing.T) {
+	sink := &flakySink{StubSink: policy.NewStubSink(), failEvents: 100}
+	pol := policy.NewStrictPolicyWithConfig(sink, degradeConfig(newDegradeClock(), 2)) // clock never advances: no retry
+
+	for seq := int64(1); seq <= 2; seq++ {
+		if err := pol.IngestEvent(t.Context(), strictEvent(seq, types.EventTypeItem)); err != nil {
+			t.Fatalf("event %d should be buffered, got %v", seq, err)
+		}
+	}
+	err := pol.IngestEvent(t.Context(), strictEvent(3, types.EventTypeItem))
+	if !errors.Is(err, errTransient) || !strings.Contains(err.Error(), "buffer overflow (3 events > 2)") {
+		t.Fatalf("expected buffer overflow, got %v", err)
+	}
+	if err := pol.Flush(t.Context()); !errors.Is(err, errTransient) {
+		t.Errorf("overflow should be sticky, got %v", err)
+	}
+}
+
+func TestStrictPolicy_DegradeRetriesExhausted(t *testing.T) {
+	sink := &flakySink{StubSink: policy.NewStubSink(), failEvents: 100}
+	clk := newDegradeClock()
+	pol := policy.NewStrictPolicyWithConfig(sink, degradeConfig(clk, 10))
+
+	if err := pol.IngestEvent(t.Context(), strictEvent(1, types.EventTypeItem)); err != nil {
+		t.Fatalf("event should be buffered, got %v", err)
+	}
+	err := withBackoffs(clk, 3, func() error { return pol.Flush(t.Context()) })
+	if !errors.Is(err, errTransient) || !strings.Contains(err.Error(), "still failing after 3 retries") {
+		t.Fatalf("expected exhausted retries, got %v", err)
+	}
+	if stats := pol.Stats(); stats.EventsPersisted != 0 || stats.Errors != 4 {
+		t.Errorf("expected 0 persisted / 4 errors, got %d / %d", stats.EventsPersisted, stats.Errors)
+	}
+}
+
+func TestStrictPolicy_DegradeNonRetryableFailsFast(t *testing.T) {
+	fatal := errors.New("access denied")
+	sink := &flakySink{StubSink: policy.NewStubSink(), failEvents: 1, failErr: fatal}
+	pol := policy.NewStrictPolicyWithConfig(sink, degradeConfig(newDegradeClock(), 10))
+
+	if err := pol.IngestEvent(t.Context(), strictEvent(1, types.EventTypeItem)); !errors.Is(err, fatal) {
+		t.Errorf("expected non-retryable error %v, got %v", fatal, err)
+	}
+	if stats := pol.Stats(); stats.Degradations != 0 {
+		t.Errorf("non-retryable error must not degrade, got %d degradations", stats.Degradations)
+	}
+}
+
+func TestStrictPolicy_DegradeChunkRetry(t *testing.T) {
+	sink := &flakySink{StubSink: policy.NewStubSink(), failChunks: 2}
+	clk := newDegradeClock()
+	pol := policy.NewStrictPolicyWithConfig(sink, degradeConfig(clk, 10))
+
+	chunk := &types.ArtifactChunk{ArtifactID: "a1", Seq: 1, IsLast: true, Data: []byte("x")}
+	ingest := func() error { return pol.IngestArtifactChunk(t.Context(), chunk) }
+	if err := withBackoffs(clk, 2, ingest); err != nil {
+		t.Fatalf("chunk should be retried in place, got %v", err)
+	}
+	if s := sink.Stats(); s.ChunksWritten != 1 {
+		t.Errorf("expected 1 chunk written, got %d", s.ChunksWritten)
+	}
+	if stats := pol.Stats(); stats.Degradations != 1 || stats.ChunksPersisted != 1 {
+		t.Errorf("expected 1 degradation / 1 chunk persisted, got %d / %d", stats.Degradations, stats.ChunksPersisted)
+	}
+
+	sink.failChunks = 100
+	err := withBackoffs(clk, 3, ingest)
+	if !errors.Is(err, errTransient) || !strings.Contains(err.Error(), "chunk write still failing after 3 retries") {
+		t.Errorf("expected exhausted chunk retries, got %v", err)
+	}
+}
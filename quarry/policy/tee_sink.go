@@ -0,0 +1,118 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+// TeeEntry is one destination of a [TeeSink].
+type TeeEntry struct {
+	Sink Sink
+	// Label identifies this sink in errors, logs, and metrics (e.g. "s3:bucket/prefix").
+	Label string
+}
+
+// TeeObserver receives the latency and result of every per-sink write.
+// Implemented by metrics.Collector.
+type TeeObserver interface {
+	ObserveStorageSinkWrite(label string, latency time.Duration, err error)
+}
+
+// TeeSink implements [Sink] by writing every batch of events and chunks to
+// several sinks concurrently. A write succeeds once at least quorum sinks
+// have accepted it; quorum equal to the number of sinks is all-or-nothing,
+// quorum 1 is best-effort. Failures tolerated by the quorum are logged.
+//
+// The tee always waits for every sink, so a slow sink bounds write latency
+// even when the quorum is already met.
+type TeeSink struct {
+	entries  []TeeEntry
+	quorum   int
+	observer TeeObserver
+}
+
+// NewTeeSink creates a tee over entries. quorum 0 requires every sink to
+// succeed. observer may be nil.
+func NewTeeSink(entries []TeeEntry, quorum int, observer TeeObserver) (*TeeSink, error) {
+	if len(entries) == 0 {
+		return nil, errors.New("tee sink requires at least one sink")
+	}
+	if quorum < 0 || quorum > len(entries) {
+		return nil, fmt.Errorf("tee sink quorum must be between 0 and %d, got %d", len(entries), quorum)
+	}
+	if quorum == 0 {
+		quorum = len(entries)
+	}
+	return &TeeSink{
+		entries:  append([]TeeEntry(nil), entries...),
+		quorum:   quorum,
+		observer: observer,
+	}, nil
+}
+
+// WriteEvents writes events to every sink.
+func (t *TeeSink) WriteEvents(ctx context.Context, events []*types.EventEnvelope) error {
+	return t.write("events", func(s Sink) error { return s.WriteEvents(ctx, events) })
+}
+
+// WriteChunks writes chunks to every sink.
+func (t *TeeSink) WriteChunks(ctx context.Context, chunks []*types.ArtifactChunk) error {
+	return t.write("chunks", func(s Sink) error { return s.WriteChunks(ctx, chunks) })
+}
+
+// write runs fn against every sink concurrently and applies the quorum.
+func (t *TeeSink) write(kind string, fn func(Sink) error) error {
+	errs := make([]error, len(t.entries))
+	var wg sync.WaitGroup
+	for i, entry := range t.entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			errs[i] = fn(entry.Sink)
+			if t.observer != nil {
+				t.observer.ObserveStorageSinkWrite(entry.Label, time.Since(start), errs[i])
+			}
+		}()
+	}
+	wg.Wait()
+
+	var failed []string
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", t.entries[i].Label, err))
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	if len(t.entries)-len(failed) >= t.quorum {
+		for _, f := range failed {
+			log.Printf("Warning: tee storage sink %s write failed (quorum %d/%d met): %s", kind, t.quorum, len(t.entries), f)
+		}
+		return nil
+	}
+	return fmt.Errorf("tee sink %s write failed (quorum %d/%d not met): %s", kind, t.quorum, len(t.entries), strings.Join(failed, "; "))
+}
+
+// Close closes every sink. Returns the first error encountered.
+func (t *TeeSink) Close() error {
+	var firstErr error
+	for _, entry := range t.entries {
+		if err := entry.Sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Verify TeeSink implements Sink and EventSink.
+var _ Sink = (*TeeSink)(nil)
+var _ EventSink = (*TeeSink)(nil)
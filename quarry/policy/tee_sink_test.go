@@ -0,0 +1,101 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+// recordingObserver is a test double for TeeObserver.
+type recordingObserver struct {
+	mu       sync.Mutex
+	writes   map[string]int
+	failures map[string]int
+}
+
+func (o *recordingObserver) ObserveStorageSinkWrite(label string, _ time.Duration, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.writes[label]++
+	if err != nil {
+		o.failures[label]++
+	}
+}
+
+func TestTeeSink_WritesToAll(t *testing.T) {
+	primary, cache := NewStubSink(), NewStubSink()
+	obs := &recordingObserver{writes: map[string]int{}, failures: map[string]int{}}
+	tee, err := NewTeeSink([]TeeEntry{{Sink: primary, Label: "s3"}, {Sink: cache, Label: "fs"}}, 0, obs)
+	if err != nil {
+		t.Fatalf("NewTeeSink: %v", err)
+	}
+
+	if err := tee.WriteEvents(t.Context(), testEnvelopes(3)); err != nil {
+		t.Fatalf("WriteEvents: %v", err)
+	}
+	if err := tee.WriteChunks(t.Context(), []*types.ArtifactChunk{{ArtifactID: "a", Seq: 1}}); err != nil {
+		t.Fatalf("WriteChunks: %v", err)
+	}
+	for name, s := range map[string]*StubSink{"primary": primary, "cache": cache} {
+		if s.EventsWritten != 3 || s.ChunksWritten != 1 {
+			t.Errorf("%s: events=%d chunks=%d, want 3 and 1", name, s.EventsWritten, s.ChunksWritten)
+		}
+	}
+	if obs.writes["s3"] != 2 || obs.writes["fs"] != 2 {
+		t.Errorf("observed writes = %v, want 2 per sink", obs.writes)
+	}
+
+	if err := tee.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if !primary.Closed || !cache.Closed {
+		t.Error("expected every sink closed")
+	}
+}
+
+func TestTeeSink_AllRequired(t *testing.T) {
+	failing := NewStubSink()
+	failing.ErrorOnWrite = errors.New("disk full")
+	tee, err := NewTeeSink([]TeeEntry{{Sink: NewStubSink(), Label: "s3"}, {Sink: failing, Label: "fs"}}, 0, nil)
+	if err != nil {
+		t.Fatalf("NewTeeSink: %v", err)
+	}
+
+	err = tee.WriteEvents(t.Context(), testEnvelopes(1))
+	if err == nil || !strings.Contains(err.Error(), "quorum 2/2 not met") || !strings.Contains(err.Error(), "fs: disk full") {
+		t.Errorf("err = %v, want quorum failure naming fs", err)
+	}
+}
+
+func TestTeeSink_QuorumToleratesFailure(t *testing.T) {
+	failing := NewStubSink()
+	failing.ErrorOnWrite = errors.New("disk full")
+	obs := &recordingObserver{writes: map[string]int{}, failures: map[string]int{}}
+	tee, err := NewTeeSink([]TeeEntry{{Sink: NewStubSink(), Label: "s3"}, {Sink: failing, Label: "fs"}}, 1, obs)
+	if err != nil {
+		t.Fatalf("NewTeeSink: %v", err)
+	}
+
+	if err := tee.WriteEvents(t.Context(), testEnvelopes(1)); err != nil {
+		t.Errorf("WriteEvents with quorum 1: %v", err)
+	}
+	if obs.failures["fs"] != 1 || obs.failures["s3"] != 0 {
+		t.Errorf("observed failures = %v, want one for fs", obs.failures)
+	}
+}
+
+func TestNewTeeSink_InvalidQuorum(t *testing.T) {
+	entries := []TeeEntry{{Sink: NewStubSink(), Label: "a"}, {Sink: NewStubSink(), Label: "b"}}
+	for _, q := range []int{-1, 3} {
+		if _, err := NewTeeSink(entries, q, nil); err == nil {
+			t.Errorf("quorum %d: expected error", q)
+		}
+	}
+	if _, err := NewTeeSink(nil, 0, nil); err == nil {
+		t.Error("expected error for no sinks")
+	}
+}
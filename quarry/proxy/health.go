@@ -0,0 +1,94 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+// DefaultHealthTimeout is the default per-endpoint probe timeout.
+const DefaultHealthTimeout = 2 * time.Second
+
+// Prober checks whether a proxy endpoint is reachable.
+// Returns nil if the endpoint is healthy.
+type Prober func(ctx context.Context, ep *types.ProxyEndpoint) error
+
+// TCPProber returns a Prober that opens (and immediately closes) a TCP
+// connection to the endpoint's host:port within timeout.
+func TCPProber(timeout time.Duration) Prober {
+	return func(ctx context.Context, ep *types.ProxyEndpoint) error {
+		dialer := &net.Dialer{Timeout: timeout}
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ep.Host, strconv.Itoa(ep.Port)))
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+}
+
+// SkippedEndpoint records an endpoint that failed its health probe.
+type SkippedEndpoint struct {
+	// Endpoint is the redacted endpoint (no password).
+	Endpoint types.ProxyEndpointRedacted
+	// Err is the probe failure.
+	Err error
+}
+
+// SelectHealthy selects an endpoint per the pool strategy, then probes it.
+// If the probe fails, the next endpoint in pool order is tried, wrapping
+// around, until one passes or every endpoint has been tried.
+//
+// Selection state advances exactly as for Select (one rotation step per
+// call). For sticky pools, a failed sticky assignment is rebound to the
+// healthy endpoint when req.Commit is true.
+//
+// Returns the healthy endpoint and the skipped endpoints in probe order.
+// Returns an error (with the skipped list) if every endpoint fails.
+func (s *Selector) SelectHealthy(ctx context.Context, req SelectRequest, probe Prober) (*types.ProxyEndpoint, []SkippedEndpoint, error) {
+	s.mu.Lock()
+	state, start, err := s.selectIndex(req)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Endpoints are immutable after registration; probing happens unlocked
+	// so slow probes do not block concurrent selection.
+	endpoints := state.pool.Endpoints
+	var skipped []SkippedEndpoint
+	for i := range len(endpoints) {
+		idx := (start + i) % len(endpoints)
+		ep := endpoints[idx]
+
+		if probeErr := probe(ctx, &ep); probeErr != nil {
+			skipped = append(skipped, SkippedEndpoint{Endpoint: ep.Redact(), Err: probeErr})
+			if ctx.Err() != nil {
+				return nil, skipped, ctx.Err()
+			}
+			continue
+		}
+
+		if idx != start && req.Commit {
+			s.rebindSticky(state, req, start, idx)
+		}
+		return &ep, skipped, nil
+	}
+
+	return nil, skipped, fmt.Errorf("all %d endpoints in pool %q failed health check", len(endpoints), req.Pool)
+}
+
+// rebindSticky moves a sticky assignment from a failed endpoint to a healthy
+// one. No-op when the key is not bound to the failed endpoint.
+func (s *Selector) rebindSticky(state *poolState, req SelectRequest, failedIdx, healthyIdx int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := s.deriveStickyKey(state, req)
+	if entry, ok := state.stickyMap[key]; ok && entry.endpointIdx == failedIdx {
+		entry.endpointIdx = healthyIdx
+	}
+}
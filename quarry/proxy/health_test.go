@@ -0,0 +1,153 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+// hostProber fails for any endpoint whose host is in dead.
+func hostProber(dead ...string) Prober {
+	deadSet := make(map[string]bool, len(dead))
+	for _, h := range dead {
+		deadSet[h] = true
+	}
+	return func(_ context.Context, ep *types.ProxyEndpoint) error {
+		if deadSet[ep.Host] {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+}
+
+func newHealthTestSelector(t *testing.T, strategy types.ProxyStrategy) *Selector {
+	t.Helper()
+	s := NewSelector()
+	pool := &types.ProxyPool{
+		Name:     "test",
+		Strategy: strategy,
+		Endpoints: []types.ProxyEndpoint{
+			{Protocol: types.ProxyProtocolHTTP, Host: "p1.example.com", Port: 8080},
+			{Protocol: types.ProxyProtocolHTTP, Host: "p2.example.com", Port: 8080},
+			{Protocol: types.ProxyProtocolHTTP, Host: "p3.example.com", Port: 8080},
+		},
+	}
+	if strategy == types.ProxyStrategySticky {
+		pool.Sticky = &types.ProxySticky{Scope: types.ProxyStickyJob}
+	}
+	if err := s.RegisterPool(pool); err != nil {
+		t.Fatalf("RegisterPool failed: %v", err)
+	}
+	return s
+}
+
+func TestSelectHealthy_SkipsDeadInPoolOrder(t *testing.T) {
+	s := newHealthTestSelector(t, types.ProxyStrategyRoundRobin)
+
+	ep, skipped, err := s.SelectHealthy(t.Context(), SelectRequest{Pool: "test", Commit: true}, hostProber("p1.example.com", "p2.example.com"))
+	if err != nil {
+		t.Fatalf("SelectHealthy failed: %v", err)
+	}
+	if ep.Host != "p3.example.com" {
+		t.Errorf("selected %q, want p3.example.com", ep.Host)
+	}
+	if len(skipped) != 2 || skipped[0].Endpoint.Host != "p1.example.com" || skipped[1].Endpoint.Host != "p2.example.com" {
+		t.Errorf("unexpected skipped list: %+v", skipped)
+	}
+
+	// Rotation advanced by exactly one step
+	next, err := s.Select(SelectRequest{Pool: "test", Commit: true})
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if next.Host != "p2.example.com" {
+		t.Errorf("next round-robin = %q, want p2.example.com", next.Host)
+	}
+}
+
+func TestSelectHealthy_WrapsAround(t *testing.T) {
+	s := newHealthTestSelector(t, types.ProxyStrategyRoundRobin)
+
+	// Advance to p3
+	for range 2 {
+		if _, err := s.Select(SelectRequest{Pool: "test", Commit: true}); err != nil {
+			t.Fatalf("Select failed: %v", err)
+		}
+	}
+
+	ep, skipped, err := s.SelectHealthy(t.Context(), SelectRequest{Pool: "test", Commit: true}, hostProber("p3.example.com"))
+	if err != nil {
+		t.Fatalf("SelectHealthy failed: %v", err)
+	}
+	if ep.Host != "p1.example.com" {
+		t.Errorf("selected %q, want p1.example.com", ep.Host)
+	}
+	if len(skipped) != 1 {
+		t.Errorf("expected 1 skipped, got %d", len(skipped))
+	}
+}
+
+func TestSelectHealthy_AllDead(t *testing.T) {
+	s := newHealthTestSelector(t, types.ProxyStrategyRoundRobin)
+
+	ep, skipped, err := s.SelectHealthy(t.Context(), SelectRequest{Pool: "test", Commit: true},
+		hostProber("p1.example.com", "p2.example.com", "p3.example.com"))
+	if err == nil {
+		t.Fatalf("expected error, got endpoint %+v", ep)
+	}
+	if len(skipped) != 3 {
+		t.Errorf("expected 3 skipped, got %d", len(skipped))
+	}
+}
+
+func TestSelectHealthy_StickyRebind(t *testing.T) {
+	s := newHealthTestSelector(t, types.ProxyStrategySticky)
+	req := SelectRequest{Pool: "test", JobID: "job-1", Commit: true}
+
+	first, err := s.Select(req)
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+
+	healthy, _, err := s.SelectHealthy(t.Context(), req, hostProber(first.Host))
+	if err != nil {
+		t.Fatalf("SelectHealthy failed: %v", err)
+	}
+	if healthy.Host == first.Host {
+		t.Fatalf("expected a different endpoint than dead %q", first.Host)
+	}
+
+	// Sticky key now bound to the healthy endpoint
+	again, err := s.Select(req)
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if again.Host != healthy.Host {
+		t.Errorf("sticky rebind: got %q, want %q", again.Host, healthy.Host)
+	}
+}
+
+func TestTCPProber(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().(*net.TCPAddr)
+
+	probe := TCPProber(time.Second)
+	ep := &types.ProxyEndpoint{Protocol: types.ProxyProtocolHTTP, Host: "127.0.0.1", Port: addr.Port}
+	if err := probe(t.Context(), ep); err != nil {
+		t.Errorf("expected healthy listener, got %v", err)
+	}
+
+	if err := ln.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := probe(t.Context(), ep); err == nil {
+		t.Error("expected probe failure after listener closed")
+	}
+}
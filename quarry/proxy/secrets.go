@@ -0,0 +1,77 @@
+package proxy
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+// SecretFilePrefix marks an endpoint field whose value is read from a file
+// (e.g. a mounted secret): "@file:/run/secrets/proxy_password".
+const SecretFilePrefix = "@file:"
+
+// secretEnvPattern matches ${VAR} references in endpoint fields.
+var secretEnvPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ResolveSecrets replaces ${VAR} and @file:/path references in the host,
+// username, and password of every endpoint in pool, so credentials can stay
+// out of config files. Unlike config-file expansion, an unset or empty
+// variable is an error, as is an unreadable file. Errors name the reference,
+// never the resolved value.
+func ResolveSecrets(pool *types.ProxyPool) error {
+	for i := range pool.Endpoints {
+		ep := &pool.Endpoints[i]
+		host, err := resolveSecret(ep.Host)
+		if err != nil {
+			return fmt.Errorf("pool %q endpoint %d host: %w", pool.Name, i, err)
+		}
+		ep.Host = host
+		if ep.Username, err = resolveSecretPtr(ep.Username); err != nil {
+			return fmt.Errorf("pool %q endpoint %d username: %w", pool.Name, i, err)
+		}
+		if ep.Password, err = resolveSecretPtr(ep.Password); err != nil {
+			return fmt.Errorf("pool %q endpoint %d password: %w", pool.Name, i, err)
+		}
+	}
+	return nil
+}
+
+func resolveSecretPtr(value *string) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	resolved, err := resolveSecret(*value)
+	if err != nil {
+		return nil, err
+	}
+	return &resolved, nil
+}
+
+// resolveSecret resolves one field. @file: must be the whole value; the
+// file's trailing newline is dropped. ${VAR} may appear anywhere.
+func resolveSecret(value string) (string, error) {
+	if path, ok := strings.CutPrefix(value, SecretFilePrefix); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("unresolved reference %s%s: %w", SecretFilePrefix, path, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	var missing []string
+	resolved := secretEnvPattern.ReplaceAllStringFunc(value, func(match string) string {
+		name := secretEnvPattern.FindStringSubmatch(match)[1]
+		v, ok := os.LookupEnv(name)
+		if !ok || v == "" {
+			missing = append(missing, name)
+		}
+		return v
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("unresolved reference ${%s}: environment variable is not set", strings.Join(missing, "}, ${"))
+	}
+	return resolved, nil
+}
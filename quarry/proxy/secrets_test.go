@@ -0,0 +1,78 @@
+package proxy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	t.Setenv("QUARRY_TEST_PROXY_USER", "alice")
+	secretPath := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(secretPath, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	user := "${QUARRY_TEST_PROXY_USER}"
+	pass := SecretFilePrefix + secretPath
+	pool := &types.ProxyPool{
+		Name:     "residential",
+		Strategy: types.ProxyStrategyRoundRobin,
+		Endpoints: []types.ProxyEndpoint{
+			{Protocol: types.ProxyProtocolHTTP, Host: "proxy.example.com", Port: 8080, Username: &user, Password: &pass},
+			{Protocol: types.ProxyProtocolHTTP, Host: "plain.example.com", Port: 8080},
+		},
+	}
+
+	if err := ResolveSecrets(pool); err != nil {
+		t.Fatalf("ResolveSecrets: %v", err)
+	}
+	ep := pool.Endpoints[0]
+	if *ep.Username != "alice" || *ep.Password != "s3cret" {
+		t.Errorf("credentials = %q/%q, want alice/s3cret", *ep.Username, *ep.Password)
+	}
+	if pool.Endpoints[1].Username != nil || pool.Endpoints[1].Password != nil {
+		t.Error("endpoint without credentials gained credentials")
+	}
+
+	// The resolved password never reaches the redacted form
+	redacted := ep.Redact()
+	if *redacted.Username != "alice" {
+		t.Errorf("redacted username = %q, want alice", *redacted.Username)
+	}
+	if strings.Contains(fmt.Sprintf("%+v", redacted), "s3cret") {
+		t.Error("redacted endpoint contains the resolved password")
+	}
+}
+
+func TestResolveSecrets_Unresolved(t *testing.T) {
+	tests := []struct {
+		name    string
+		pass    string
+		wantErr string
+	}{
+		{"unset env", "${QUARRY_TEST_UNSET_PROXY_PASS}", "${QUARRY_TEST_UNSET_PROXY_PASS}"},
+		{"missing file", SecretFilePrefix + "/nonexistent/proxy_pass", "@file:/nonexistent/proxy_pass"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := "bob"
+			pass := tt.pass
+			pool := &types.ProxyPool{
+				Name:      "dc",
+				Endpoints: []types.ProxyEndpoint{{Host: "proxy.example.com", Port: 8080, Username: &user, Password: &pass}},
+			}
+			err := ResolveSecrets(pool)
+			if err == nil {
+				t.Fatal("expected error for unresolved reference")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) || !strings.Contains(err.Error(), `pool "dc" endpoint 0 password`) {
+				t.Errorf("error = %q, want pool/endpoint/field and %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math/big"
 	"os"
+	"slices"
 	"sync"
 	"time"
 
@@ -26,6 +27,11 @@ type poolState struct {
 	rrIndex   int64                   // round-robin counter
 	stickyMap map[string]*stickyEntry // sticky key -> entry
 
+	// wrrCurrent holds the smooth weighted round-robin current weight per
+	// endpoint. Allocated on first weighted selection, so a strategy
+	// override works on any pool.
+	wrrCurrent []int64
+
 	// recencyRing is a fixed-size ring buffer of recently-used endpoint indices.
 	// Only populated when pool.RecencyWindow is set.
 	recencyRing []int // ring buffer (capacity = recency window size)
@@ -108,9 +114,22 @@ func (s *Selector) Select(req SelectRequest) (*types.ProxyEndpoint, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	state, idx, err := s.selectIndex(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Return a copy of the endpoint
+	ep := state.pool.Endpoints[idx]
+	return &ep, nil
+}
+
+// selectIndex resolves the pool and selects an endpoint index per the
+// effective strategy. Caller must hold s.mu.
+func (s *Selector) selectIndex(req SelectRequest) (*poolState, int, error) {
 	state, ok := s.pools[req.Pool]
 	if !ok {
-		return nil, fmt.Errorf("pool %q not found", req.Pool)
+		return nil, 0, fmt.Errorf("pool %q not found", req.Pool)
 	}
 
 	// Determine effective strategy
@@ -125,23 +144,23 @@ func (s *Selector) Select(req SelectRequest) (*types.ProxyEndpoint, error) {
 	switch strategy {
 	case types.ProxyStrategyRoundRobin:
 		idx = s.selectRoundRobin(state, req.Commit)
+	case types.ProxyStrategyWeightedRoundRobin:
+		idx = s.selectWeightedRoundRobin(state, req.Commit)
 	case types.ProxyStrategyRandom:
 		idx, err = s.selectRandom(state, req.Commit)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 	case types.ProxyStrategySticky:
 		idx, err = s.selectSticky(state, req, req.Commit)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 	default:
-		return nil, fmt.Errorf("unknown strategy %q", strategy)
+		return nil, 0, fmt.Errorf("unknown strategy %q", strategy)
 	}
 
-	// Return a copy of the endpoint
-	ep := state.pool.Endpoints[idx]
-	return &ep, nil
+	return state, idx, nil
 }
 
 // selectRoundRobin selects using round-robin.
@@ -154,6 +173,37 @@ func (s *Selector) selectRoundRobin(state *poolState, commit bool) int {
 	return idx
 }
 
+// selectWeightedRoundRobin selects using smooth weighted round-robin: each
+// endpoint's current weight grows by its weight, the largest is picked
+// (lowest index on ties) and reduced by the total. Over any window of
+// total-weight selections each endpoint is picked exactly weight times,
+// interleaved rather than in bursts. The result depends only on the
+// current weights, so a given cursor state always picks the same endpoint.
+// Current weights are updated only when commit is true.
+func (s *Selector) selectWeightedRoundRobin(state *poolState, commit bool) int {
+	endpoints := state.pool.Endpoints
+	if state.wrrCurrent == nil {
+		state.wrrCurrent = make([]int64, len(endpoints))
+	}
+	current := state.wrrCurrent
+	if !commit {
+		current = slices.Clone(current)
+	}
+
+	var total int64
+	best := 0
+	for i := range endpoints {
+		w := int64(endpoints[i].EffectiveWeight())
+		current[i] += w
+		total += w
+		if current[i] > current[best] {
+			best = i
+		}
+	}
+	current[best] -= total
+	return best
+}
+
 // selectRandom selects uniformly at random, excluding recently-used indices
 // when a recency window is configured.
 //
@@ -263,17 +313,27 @@ func (s *Selector) selectSticky(state *poolState, req SelectRequest, commit bool
 	now := time.Now()
 
 	// Check existing entry
+	expiredIdx := -1
 	if entry, ok := state.stickyMap[stickyKey]; ok {
 		// Check TTL expiration
 		if entry.expiresAt == nil || entry.expiresAt.After(now) {
 			return entry.endpointIdx, nil
 		}
-		// Entry expired, remove it
-		delete(state.stickyMap, stickyKey)
+		// Entry expired: rotate away from it, removing it only on commit
+		expiredIdx = entry.endpointIdx
+		if commit {
+			delete(state.stickyMap, stickyKey)
+		}
 	}
 
 	// Select new endpoint (use random for new assignments)
-	idx, err := s.selectRandom(state, commit)
+	var idx int
+	var err error
+	if expiredIdx >= 0 && len(state.pool.Endpoints) > 1 {
+		idx, err = s.selectRotated(expiredIdx, len(state.pool.Endpoints))
+	} else {
+		idx, err = s.selectRandom(state, commit)
+	}
 	if err != nil {
 		return 0, err
 	}
@@ -292,6 +352,19 @@ func (s *Selector) selectSticky(state *poolState, req SelectRequest, commit bool
 	return idx, nil
 }
 
+// selectRotated selects uniformly at random among the n endpoints other than
+// the expired sticky assignment, so a TTL expiry always rotates the endpoint.
+func (s *Selector) selectRotated(expiredIdx, n int) (int, error) {
+	idx, err := s.randomIndex(n - 1)
+	if err != nil {
+		return 0, err
+	}
+	if idx >= expiredIdx {
+		idx++
+	}
+	return idx, nil
+}
+
 // deriveStickyKey derives the sticky key per CONTRACT_PROXY.md precedence:
 // 1. req.StickyKey if provided
 // 2. if scope = job: req.JobID
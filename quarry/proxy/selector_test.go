@@ -51,6 +51,89 @@ func TestSelector_RoundRobin(t *testing.T) {
 	}
 }
 
+func TestSelector_WeightedRoundRobin(t *testing.T) {
+	s := NewSelector()
+
+	pool := &types.ProxyPool{
+		Name:     "test",
+		Strategy: types.ProxyStrategyWeightedRoundRobin,
+		Endpoints: []types.ProxyEndpoint{
+			{Protocol: types.ProxyProtocolHTTP, Host: "big.example.com", Port: 8080, Weight: 5},
+			{Protocol: types.ProxyProtocolHTTP, Host: "small.example.com", Port: 8080},
+			{Protocol: types.ProxyProtocolHTTP, Host: "tiny.example.com", Port: 8080, Weight: 1},
+		},
+	}
+
+	if err := s.RegisterPool(pool); err != nil {
+		t.Fatalf("RegisterPool failed: %v", err)
+	}
+
+	// Smooth WRR interleaves the heavy endpoint; absent weight counts as 1.
+	expected := []string{
+		"big.example.com",
+		"big.example.com",
+		"small.example.com",
+		"big.example.com",
+		"tiny.example.com",
+		"big.example.com",
+		"big.example.com",
+	}
+
+	// Two full cycles: the sequence repeats once current weights return to zero.
+	for cycle := range 2 {
+		for i, exp := range expected {
+			ep, err := s.Select(SelectRequest{Pool: "test", Commit: true})
+			if err != nil {
+				t.Fatalf("Select failed: %v", err)
+			}
+			if ep.Host != exp {
+				t.Errorf("cycle %d selection %d = %q, want %q", cycle, i, ep.Host, exp)
+			}
+		}
+	}
+}
+
+func TestSelector_WeightedRoundRobin_PeekDoesNotAdvance(t *testing.T) {
+	s := NewSelector()
+
+	pool := &types.ProxyPool{
+		Name:     "test",
+		Strategy: types.ProxyStrategyRoundRobin,
+		Endpoints: []types.ProxyEndpoint{
+			{Protocol: types.ProxyProtocolHTTP, Host: "p1.example.com", Port: 8080, Weight: 2},
+			{Protocol: types.ProxyProtocolHTTP, Host: "p2.example.com", Port: 8080, Weight: 3},
+		},
+	}
+
+	if err := s.RegisterPool(pool); err != nil {
+		t.Fatalf("RegisterPool failed: %v", err)
+	}
+
+	// Strategy override on a round_robin pool uses the endpoint weights.
+	override := types.ProxyStrategyWeightedRoundRobin
+	for i := range 3 {
+		ep, err := s.Select(SelectRequest{Pool: "test", StrategyOverride: &override, Commit: false})
+		if err != nil {
+			t.Fatalf("Select failed: %v", err)
+		}
+		if ep.Host != "p2.example.com" {
+			t.Errorf("peek %d = %q, want p2.example.com", i, ep.Host)
+		}
+	}
+
+	counts := make(map[string]int)
+	for range 10 {
+		ep, err := s.Select(SelectRequest{Pool: "test", StrategyOverride: &override, Commit: true})
+		if err != nil {
+			t.Fatalf("Select failed: %v", err)
+		}
+		counts[ep.Host]++
+	}
+	if counts["p1.example.com"] != 4 || counts["p2.example.com"] != 6 {
+		t.Errorf("counts = %v, want p1=4 p2=6", counts)
+	}
+}
+
 func TestSelector_Random(t *testing.T) {
 	s := NewSelector()
 
@@ -153,19 +236,76 @@ func TestSelector_Sticky_WithTTL(t *testing.T) {
 		t.Fatalf("Select failed: %v", err)
 	}
 
+	// Within the TTL the assignment holds
+	same, err := s.Select(SelectRequest{Pool: "test", JobID: "job-123", Commit: true})
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if same.Host != ep1.Host {
+		t.Errorf("expected sticky %s within TTL, got %s", ep1.Host, same.Host)
+	}
+
 	// Wait for TTL to expire
 	time.Sleep(60 * time.Millisecond)
 
-	// After TTL, may get different endpoint
+	// A peek after expiry rotates but does not replace the entry
+	peek, err := s.Select(SelectRequest{Pool: "test", JobID: "job-123", Commit: false})
+	if err != nil {
+		t.Fatalf("peek failed after TTL: %v", err)
+	}
+	if peek.Host == ep1.Host {
+		t.Errorf("expected peek after TTL to rotate away from %s", ep1.Host)
+	}
+	if stats, _ := s.Stats("test"); stats.StickyEntries != 1 {
+		t.Errorf("StickyEntries = %d after peek, want 1", stats.StickyEntries)
+	}
+
+	// After TTL the key is reassigned to a different endpoint
 	ep2, err := s.Select(SelectRequest{Pool: "test", JobID: "job-123", Commit: true})
 	if err != nil {
 		t.Fatalf("Select failed after TTL: %v", err)
 	}
+	if ep2.Host == ep1.Host {
+		t.Errorf("expected rotation away from %s after TTL, got the same endpoint", ep1.Host)
+	}
 
-	// Note: ep2 may or may not equal ep1 (random selection after TTL)
-	// We just verify it doesn't error
-	_ = ep1
-	_ = ep2
+	// The new assignment is sticky again
+	ep3, err := s.Select(SelectRequest{Pool: "test", JobID: "job-123", Commit: true})
+	if err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	if ep3.Host != ep2.Host {
+		t.Errorf("expected new assignment %s to stick, got %s", ep2.Host, ep3.Host)
+	}
+}
+
+func TestSelector_Sticky_TTLSingleEndpoint(t *testing.T) {
+	s := NewSelector()
+
+	ttl := int64(10)
+	pool := &types.ProxyPool{
+		Name:      "test",
+		Strategy:  types.ProxyStrategySticky,
+		Endpoints: []types.ProxyEndpoint{{Protocol: types.ProxyProtocolHTTP, Host: "p1.example.com", Port: 8080}},
+		Sticky:    &types.ProxySticky{Scope: types.ProxyStickyJob, TTLMs: &ttl},
+	}
+	if err := s.RegisterPool(pool); err != nil {
+		t.Fatalf("RegisterPool failed: %v", err)
+	}
+
+	if _, err := s.Select(SelectRequest{Pool: "test", JobID: "job-123", Commit: true}); err != nil {
+		t.Fatalf("Select failed: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// With nowhere to rotate, the only endpoint is reassigned
+	ep, err := s.Select(SelectRequest{Pool: "test", JobID: "job-123", Commit: true})
+	if err != nil {
+		t.Fatalf("Select failed after TTL: %v", err)
+	}
+	if ep.Host != "p1.example.com" {
+		t.Errorf("expected p1.example.com, got %s", ep.Host)
+	}
 }
 
 func TestSelector_Sticky_ExplicitKey(t *testing.T) {
@@ -0,0 +1,71 @@
+package runtime
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"testing"
+	"time"
+
+	"github.com/pithecene-io/quarry/policy"
+	"github.com/pithecene-io/quarry/types"
+)
+
+// TestE2E_VerifyArtifacts runs the real Node executor with artifact
+// verification enabled. The executor sends a CRC32C on every chunk and the
+// SDK a sha256 on the commit, so the fixture's artifact must verify and the
+// run must succeed.
+//
+// Gating: requires QUARRY_E2E=1 (slow, requires Node + Puppeteer).
+func TestE2E_VerifyArtifacts(t *testing.T) {
+	if os.Getenv("QUARRY_E2E") != "1" {
+		t.Skip("QUARRY_E2E=1 not set, skipping live E2E test")
+	}
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node not available")
+	}
+
+	// Resolve repo root from this file's location (quarry/runtime/)
+	_, thisFile, _, ok := goruntime.Caller(0)
+	if !ok {
+		t.Fatal("failed to get caller info")
+	}
+	repoRoot := filepath.Dir(filepath.Dir(filepath.Dir(thisFile)))
+	executorBin := filepath.Join(repoRoot, "executor-node", "dist", "bin", "executor.js")
+	if _, err := os.Stat(executorBin); os.IsNotExist(err) {
+		t.Skipf("executor not built at %s", executorBin)
+	}
+	scriptPath := filepath.Join(repoRoot, "executor-node", "testdata", "e2e-fixture-script.js")
+	if _, err := os.Stat(scriptPath); err != nil {
+		t.Fatalf("fixture script not found: %s", scriptPath)
+	}
+	t.Setenv("QUARRY_NO_SANDBOX", "1")
+
+	ctx, cancel := context.WithTimeout(t.Context(), 60*time.Second)
+	defer cancel()
+
+	orchestrator, err := NewRunOrchestrator(&RunConfig{
+		ExecutorPath:    executorBin,
+		ScriptPath:      scriptPath,
+		Job:             map[string]any{},
+		RunMeta:         &types.RunMeta{RunID: "run-e2e-verify", Attempt: 1},
+		Policy:          policy.NewNoopPolicy(),
+		VerifyArtifacts: true,
+	})
+	if err != nil {
+		t.Fatalf("NewRunOrchestrator: %v", err)
+	}
+
+	result, err := orchestrator.Execute(ctx)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if result.Outcome.Status != types.OutcomeSuccess {
+		t.Fatalf("outcome = %s (%s), want success", result.Outcome.Status, result.Outcome.Message)
+	}
+	if result.ArtifactStats.CommittedArtifacts != 1 {
+		t.Errorf("CommittedArtifacts = %d, want 1", result.ArtifactStats.CommittedArtifacts)
+	}
+}
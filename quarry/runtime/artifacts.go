@@ -2,9 +2,20 @@
 package runtime
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"mime"
+	"net/http"
+	"os"
 	"sync"
 
+	"github.com/pithecene-io/quarry/iox"
 	"github.com/pithecene-io/quarry/ipc"
 	"github.com/pithecene-io/quarry/types"
 )
@@ -13,6 +24,28 @@ import (
 // Per CONTRACT_IPC.md, this is implementation-defined (recommended: 1 GiB).
 const MaxArtifactSize = 1 * 1024 * 1024 * 1024
 
+// ErrArtifactBudgetExceeded indicates a run wrote more artifact bytes or
+// artifacts than its ArtifactBudget allows. Ingestion surfaces it as a
+// policy failure (the run is failed, not the executor stream).
+var ErrArtifactBudgetExceeded = errors.New("artifact budget exceeded")
+
+// ErrArtifactChecksumMismatch indicates an artifact chunk CRC32C or the
+// full-artifact SHA-256 did not match the assembled data. Only returned when
+// verification is enabled (see ArtifactManager.SetVerify).
+var ErrArtifactChecksumMismatch = errors.New("artifact checksum mismatch")
+
+// crc32cTable is the Castagnoli table used for chunk checksums.
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ArtifactBudget bounds the artifacts a single run may write.
+// Zero fields are unlimited.
+type ArtifactBudget struct {
+	// MaxBytes is the maximum total artifact chunk bytes across the run.
+	MaxBytes int64
+	// MaxArtifacts is the maximum number of committed artifacts.
+	MaxArtifacts int
+}
+
 // ArtifactManager manages artifact chunk accumulation and orphan tracking.
 // Per CONTRACT_IPC.md, chunks may arrive before the artifact event.
 // Thread-safe for concurrent access.
@@ -22,6 +55,48 @@ type ArtifactManager struct {
 	// pendingCommits tracks artifacts where commit arrived before all chunks.
 	// Maps artifact_id -> declared size_bytes for reconciliation.
 	pendingCommits map[string]int64
+
+	budget         ArtifactBudget
+	budgetBytes    int64 // chunk bytes accepted across all artifacts
+	budgetCommits  int   // artifact commits accepted
+	budgetExceeded bool
+
+	// Checksum verification (opt-in). digests holds running SHA-256 state
+	// until is_last; sums holds the final hex digest; declaredSums holds
+	// commit-declared digests awaiting chunk completion.
+	verify       bool
+	digests      map[string]hash.Hash
+	sums         map[string]string
+	declaredSums map[string]string
+
+	// Spill (opt-in). Once an artifact's bytes exceed spillThreshold, its
+	// data moves to a temp file in spillDir and retained chunks keep only
+	// metadata. A temp file is removed when its artifact commits or fails,
+	// and any left (orphans) by Close.
+	spillThreshold int64
+	spillDir       string
+	spills         map[string]*artifactSpill
+
+	// onCommit is notified once per artifact when it becomes committed
+	// (commit received and chunks complete). Nil disables it.
+	onCommit func(artifactID string, sizeBytes int64)
+
+	// Content-type sniffing (opt-in). sniffed holds the type detected from
+	// each artifact's first chunk; substituted holds it for the artifacts
+	// whose declared type it replaced.
+	sniff       bool
+	sniffed     map[string]string
+	substituted map[string]string
+}
+
+// artifactSpill is the temp file holding a spilled artifact's data.
+// file is nil once the artifact is finalized (is_last seen); released is
+// set once the file is deleted at commit or failure. The entry is kept so
+// Stats still counts the artifact as spilled.
+type artifactSpill struct {
+	path     string
+	file     *os.File
+	released bool
 }
 
 // NewArtifactManager creates a new artifact manager.
@@ -29,9 +104,76 @@ func NewArtifactManager() *ArtifactManager {
 	return &ArtifactManager{
 		accumulators:   make(map[string]*types.ArtifactAccumulator),
 		pendingCommits: make(map[string]int64),
+		digests:        make(map[string]hash.Hash),
+		sums:           make(map[string]string),
+		declaredSums:   make(map[string]string),
+		spills:         make(map[string]*artifactSpill),
+		sniffed:        make(map[string]string),
+		substituted:    make(map[string]string),
 	}
 }
 
+// SetSpill enables spilling: an artifact whose accumulated bytes exceed
+// threshold is written to a temp file in dir (empty = os.TempDir) instead
+// of being held in memory. Zero disables spilling. Must be called before
+// ingestion; call Close to remove temp files.
+func (m *ArtifactManager) SetSpill(threshold int64, dir string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.spillThreshold = threshold
+	m.spillDir = dir
+}
+
+// SetVerify enables checksum verification. When enabled, a chunk CRC32C or
+// commit sha256 that is present must match, else ErrArtifactChecksumMismatch;
+// missing checksums are not checked. Must be called before ingestion.
+func (m *ArtifactManager) SetVerify(verify bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.verify = verify
+}
+
+// SetSniffContentType enables content-type sniffing: the first chunk of
+// each artifact is passed to http.DetectContentType, and ResolveContentType
+// substitutes the result for a missing or generic declared type. Must be
+// called before ingestion.
+func (m *ArtifactManager) SetSniffContentType(sniff bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sniff = sniff
+}
+
+// SetOnCommit registers fn to be called once per artifact when it becomes
+// committed: its commit event and its last chunk have both arrived, in
+// either order. fn runs on the caller's goroutine after the manager lock is
+// released. Must be called before ingestion.
+func (m *ArtifactManager) SetOnCommit(fn func(artifactID string, sizeBytes int64)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onCommit = fn
+}
+
+// notifyCommit invokes the commit hook, if any. Caller must not hold m.mu.
+func (m *ArtifactManager) notifyCommit(artifactID string, sizeBytes int64) {
+	if m.onCommit != nil {
+		m.onCommit(artifactID, sizeBytes)
+	}
+}
+
+// SetBudget sets the per-run artifact budget. Must be called before ingestion.
+func (m *ArtifactManager) SetBudget(budget ArtifactBudget) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.budget = budget
+}
+
+// BudgetExceeded returns true if a chunk or commit was rejected by the budget.
+func (m *ArtifactManager) BudgetExceeded() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.budgetExceeded
+}
+
 // AddChunk adds a chunk to an artifact.
 // Per CONTRACT_IPC.md:
 //   - seq must start at 1 and be strictly increasing
@@ -42,8 +184,17 @@ func NewArtifactManager() *ArtifactManager {
 //   - chunk arrives after is_last=true was seen
 //   - chunk data exceeds max chunk size
 //   - accumulated size exceeds MaxArtifactSize
+//   - run-wide chunk bytes exceed the budget (ErrArtifactBudgetExceeded)
 //   - size mismatch when commit arrived before chunks and is_last is seen
+//   - verification is enabled and the chunk CRC32C is wrong, or the
+//     assembled SHA-256 differs from a pending commit's declared sha256
 func (m *ArtifactManager) AddChunk(chunk *types.ArtifactChunk) error {
+	var committedSize int64 = -1
+	defer func() {
+		if committedSize >= 0 {
+			m.notifyCommit(chunk.ArtifactID, committedSize)
+		}
+	}()
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -86,30 +237,235 @@ func (m *ArtifactManager) AddChunk(chunk *types.ArtifactChunk) error {
 			chunk.ArtifactID, newTotal, MaxArtifactSize)
 	}
 
+	// Check run-wide byte budget
+	if m.budget.MaxBytes > 0 && m.budgetBytes+int64(len(chunk.Data)) > m.budget.MaxBytes {
+		m.budgetExceeded = true
+		return fmt.Errorf("%w: artifact %s: %d bytes exceeds max %d",
+			ErrArtifactBudgetExceeded, chunk.ArtifactID, m.budgetBytes+int64(len(chunk.Data)), m.budget.MaxBytes)
+	}
+
+	if m.verify {
+		if err := m.verifyChunk(acc, chunk); err != nil {
+			return err
+		}
+	}
+
 	// Add chunk
-	acc.Chunks = append(acc.Chunks, chunk)
+	if err := m.retainChunkLocked(acc, chunk, newTotal); err != nil {
+		acc.ErrorState = true
+		_ = m.removeSpillLocked(chunk.ArtifactID)
+		return fmt.Errorf("artifact %s: spill to temp file failed: %w", chunk.ArtifactID, err)
+	}
 	acc.TotalBytes = newTotal
 	acc.NextSeq++
+	m.budgetBytes += int64(len(chunk.Data))
+	if m.sniff && chunk.Seq == 1 {
+		m.sniffed[chunk.ArtifactID] = http.DetectContentType(chunk.Data)
+	}
 
 	if chunk.IsLast {
 		acc.Complete = true
+		if err := m.finalizeSpillLocked(chunk.ArtifactID); err != nil {
+			acc.ErrorState = true
+			_ = m.removeSpillLocked(chunk.ArtifactID)
+			return fmt.Errorf("artifact %s: spill finalization failed: %w", chunk.ArtifactID, err)
+		}
+		if m.verify {
+			m.sums[chunk.ArtifactID] = hex.EncodeToString(m.digests[chunk.ArtifactID].Sum(nil))
+			delete(m.digests, chunk.ArtifactID)
+		}
 
 		// If commit arrived before chunks, reconcile size now
 		if declaredSize, pending := m.pendingCommits[chunk.ArtifactID]; pending {
 			// Always clean up pending commit to avoid inconsistent state
 			delete(m.pendingCommits, chunk.ArtifactID)
 
+			// Committed or failed either way, so the spilled data is done
+			defer m.releaseSpillLocked(chunk.ArtifactID)
 			if acc.TotalBytes != declaredSize {
 				// Mark accumulator in error state to prevent further operations
 				acc.ErrorState = true
 				return fmt.Errorf("artifact %s: size mismatch (chunks=%d, declared=%d)",
 					chunk.ArtifactID, acc.TotalBytes, declaredSize)
 			}
+			if m.verify {
+				declared := m.declaredSums[chunk.ArtifactID]
+				delete(m.declaredSums, chunk.ArtifactID)
+				if err := m.verifySum(acc, declared); err != nil {
+					return err
+				}
+			}
 			// Size matches, mark as committed
 			acc.Committed = true
+			committedSize = acc.TotalBytes
+		}
+	}
+
+	return nil
+}
+
+// retainChunkLocked records chunk on acc. Below the spill threshold the
+// chunk (with data) is kept in memory; once newTotal exceeds it, buffered
+// data is moved to a temp file and only chunk metadata is retained.
+// The caller's chunk is never mutated: the policy may still hold it.
+// Caller must hold m.mu.
+func (m *ArtifactManager) retainChunkLocked(acc *types.ArtifactAccumulator, chunk *types.ArtifactChunk, newTotal int64) error {
+	spill, spilled := m.spills[acc.ArtifactID]
+	if !spilled && (m.spillThreshold <= 0 || newTotal <= m.spillThreshold) {
+		acc.Chunks = append(acc.Chunks, chunk)
+		return nil
+	}
+
+	if !spilled {
+		file, err := os.CreateTemp(m.spillDir, "quarry-artifact-*")
+		if err != nil {
+			return err
+		}
+		spill = &artifactSpill{path: file.Name(), file: file}
+		m.spills[acc.ArtifactID] = spill
+		for i, buffered := range acc.Chunks {
+			if _, err := file.Write(buffered.Data); err != nil {
+				return err
+			}
+			acc.Chunks[i] = chunkMetadata(buffered)
+		}
+	}
+
+	if _, err := spill.file.Write(chunk.Data); err != nil {
+		return err
+	}
+	acc.Chunks = append(acc.Chunks, chunkMetadata(chunk))
+	return nil
+}
+
+// chunkMetadata returns a copy of chunk without its data.
+func chunkMetadata(chunk *types.ArtifactChunk) *types.ArtifactChunk {
+	return &types.ArtifactChunk{
+		ArtifactID: chunk.ArtifactID,
+		Seq:        chunk.Seq,
+		IsLast:     chunk.IsLast,
+		CRC32C:     chunk.CRC32C,
+	}
+}
+
+// finalizeSpillLocked closes a spilled artifact's temp file once is_last is
+// seen; the file stays readable via OpenArtifact until the artifact commits.
+// Caller must hold m.mu.
+func (m *ArtifactManager) finalizeSpillLocked(artifactID string) error {
+	spill, ok := m.spills[artifactID]
+	if !ok || spill.file == nil {
+		return nil
+	}
+	err := spill.file.Close()
+	spill.file = nil
+	return err
+}
+
+// releaseSpillLocked deletes a committed or failed artifact's temp file,
+// keeping its entry for Stats. A failed delete is retried by Close.
+// Caller must hold m.mu.
+func (m *ArtifactManager) releaseSpillLocked(artifactID string) {
+	spill, ok := m.spills[artifactID]
+	if !ok || spill.released {
+		return
+	}
+	if spill.file != nil {
+		iox.DiscardClose(spill.file)
+		spill.file = nil
+	}
+	if err := os.Remove(spill.path); err == nil || errors.Is(err, os.ErrNotExist) {
+		spill.released = true
+	}
+}
+
+// removeSpillLocked deletes an artifact's temp file, if any.
+// Caller must hold m.mu.
+func (m *ArtifactManager) removeSpillLocked(artifactID string) error {
+	spill, ok := m.spills[artifactID]
+	if !ok {
+		return nil
+	}
+	delete(m.spills, artifactID)
+	var closeErr error
+	if spill.file != nil {
+		closeErr = spill.file.Close()
+	}
+	if err := os.Remove(spill.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return closeErr
+}
+
+// OpenArtifact returns a reader over the reassembled data of a complete
+// artifact, from memory or from its spill file. A spilled artifact is
+// readable only until it commits, when its spill file is deleted.
+func (m *ArtifactManager) OpenArtifact(artifactID string) (io.ReadCloser, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	acc, exists := m.accumulators[artifactID]
+	if !exists || !acc.Complete || acc.ErrorState {
+		return nil, fmt.Errorf("artifact %s: not complete", artifactID)
+	}
+	if spill, ok := m.spills[artifactID]; ok {
+		if spill.released {
+			return nil, fmt.Errorf("artifact %s: spilled data released at commit", artifactID)
+		}
+		return os.Open(spill.path)
+	}
+	readers := make([]io.Reader, 0, len(acc.Chunks))
+	for _, chunk := range acc.Chunks {
+		readers = append(readers, bytes.NewReader(chunk.Data))
+	}
+	return io.NopCloser(io.MultiReader(readers...)), nil
+}
+
+// Close removes all spill temp files. Call on every run exit path.
+// Safe to call more than once.
+func (m *ArtifactManager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var errs []error
+	for id := range m.spills {
+		if err := m.removeSpillLocked(id); err != nil {
+			errs = append(errs, err)
 		}
 	}
+	return errors.Join(errs...)
+}
+
+// verifyChunk checks the chunk CRC32C, when the executor sent one, and feeds
+// the artifact's running SHA-256. Caller must hold m.mu.
+func (m *ArtifactManager) verifyChunk(acc *types.ArtifactAccumulator, chunk *types.ArtifactChunk) error {
+	if chunk.CRC32C != nil {
+		if got := crc32.Checksum(chunk.Data, crc32cTable); got != *chunk.CRC32C {
+			acc.ErrorState = true
+			return fmt.Errorf("artifact %s: chunk seq %d: %w (crc32c=%08x, declared=%08x)",
+				chunk.ArtifactID, chunk.Seq, ErrArtifactChecksumMismatch, got, *chunk.CRC32C)
+		}
+	}
+	digest, ok := m.digests[chunk.ArtifactID]
+	if !ok {
+		digest = sha256.New()
+		m.digests[chunk.ArtifactID] = digest
+	}
+	digest.Write(chunk.Data)
+	return nil
+}
 
+// verifySum compares the assembled SHA-256 with the declared digest. An
+// empty declared digest (an SDK predating checksums) is not checked.
+// Caller must hold m.mu and chunks must be complete.
+func (m *ArtifactManager) verifySum(acc *types.ArtifactAccumulator, declared string) error {
+	if declared == "" {
+		return nil
+	}
+	if got := m.sums[acc.ArtifactID]; got != declared {
+		acc.ErrorState = true
+		return fmt.Errorf("artifact %s: %w (sha256=%s, declared=%s)",
+			acc.ArtifactID, ErrArtifactChecksumMismatch, got, declared)
+	}
 	return nil
 }
 
@@ -119,8 +475,22 @@ func (m *ArtifactManager) AddChunk(chunk *types.ArtifactChunk) error {
 //
 // Returns error if:
 //   - size_bytes exceeds MaxArtifactSize
+//   - the commit exceeds the artifact count budget (ErrArtifactBudgetExceeded)
 //   - size_bytes doesn't match accumulated bytes (when chunks are complete)
 func (m *ArtifactManager) CommitArtifact(artifactID string, sizeBytes int64) error {
+	return m.CommitArtifactWithSum(artifactID, sizeBytes, "")
+}
+
+// CommitArtifactWithSum is CommitArtifact with the commit's declared SHA-256
+// (lowercase hex, or "" when the commit carries none). The digest is only
+// checked when verification is enabled.
+func (m *ArtifactManager) CommitArtifactWithSum(artifactID string, sizeBytes int64, sha256Hex string) error {
+	committed := false
+	defer func() {
+		if committed {
+			m.notifyCommit(artifactID, sizeBytes)
+		}
+	}()
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -130,11 +500,19 @@ func (m *ArtifactManager) CommitArtifact(artifactID string, sizeBytes int64) err
 			artifactID, sizeBytes, MaxArtifactSize)
 	}
 
+	// Check artifact count budget
+	if m.budget.MaxArtifacts > 0 && m.budgetCommits >= m.budget.MaxArtifacts {
+		m.budgetExceeded = true
+		return fmt.Errorf("%w: artifact %s: count %d exceeds max %d",
+			ErrArtifactBudgetExceeded, artifactID, m.budgetCommits+1, m.budget.MaxArtifacts)
+	}
+
 	acc, exists := m.accumulators[artifactID]
 	if !exists {
 		// Artifact event arrived before any chunks - this is valid per contract.
 		// Track the declared size for reconciliation when chunks complete.
 		m.pendingCommits[artifactID] = sizeBytes
+		m.declaredSums[artifactID] = sha256Hex
 		acc = &types.ArtifactAccumulator{
 			ArtifactID: artifactID,
 			Chunks:     make([]*types.ArtifactChunk, 0),
@@ -142,6 +520,7 @@ func (m *ArtifactManager) CommitArtifact(artifactID string, sizeBytes int64) err
 			// Note: NOT marked Committed yet - will be marked when chunks complete
 		}
 		m.accumulators[artifactID] = acc
+		m.budgetCommits++
 		return nil
 	}
 
@@ -152,21 +531,62 @@ func (m *ArtifactManager) CommitArtifact(artifactID string, sizeBytes int64) err
 
 	// If chunks are complete, verify size matches
 	if acc.Complete {
+		// Committed or failed either way, so the spilled data is done
+		defer m.releaseSpillLocked(artifactID)
 		if acc.TotalBytes != sizeBytes {
 			// Mark as error state - size mismatch is a terminal contract violation
 			acc.ErrorState = true
 			return fmt.Errorf("artifact %s: size mismatch (chunks=%d, declared=%d)",
 				artifactID, acc.TotalBytes, sizeBytes)
 		}
+		if m.verify {
+			if err := m.verifySum(acc, sha256Hex); err != nil {
+				return err
+			}
+		}
 		acc.Committed = true
+		committed = true
 	} else {
 		// Chunks not complete yet - track for reconciliation
 		m.pendingCommits[artifactID] = sizeBytes
+		m.declaredSums[artifactID] = sha256Hex
 	}
+	m.budgetCommits++
 
 	return nil
 }
 
+// ResolveContentType returns the content type to record for an artifact
+// whose event declared declared. With sniffing enabled, an empty or
+// application/octet-stream declaration is replaced by the type sniffed from
+// the first chunk, when that is more specific; sniffed reports whether it
+// was. Without a first chunk yet (the event arrived before its chunks) the
+// declared type is kept.
+func (m *ArtifactManager) ResolveContentType(artifactID, declared string) (contentType string, sniffed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.sniff || !isGenericContentType(declared) {
+		return declared, false
+	}
+	detected, ok := m.sniffed[artifactID]
+	if !ok || isGenericContentType(detected) {
+		return declared, false
+	}
+	m.substituted[artifactID] = detected
+	return detected, true
+}
+
+// isGenericContentType reports whether contentType carries no type
+// information: empty, or application/octet-stream (parameters ignored).
+func isGenericContentType(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == "application/octet-stream"
+}
+
 // GetOrphanIDs returns the list of artifact IDs with chunks but no commit.
 // These are eligible for GC per CONTRACT_IPC.md.
 //
@@ -222,10 +642,20 @@ func (m *ArtifactManager) Stats() ArtifactStats {
 	defer m.mu.RUnlock()
 
 	stats := ArtifactStats{}
+	for _, contentType := range m.substituted {
+		if stats.SniffedContentTypes == nil {
+			stats.SniffedContentTypes = make(map[string]int64)
+		}
+		stats.SniffedContentTypes[contentType]++
+		stats.SniffedArtifacts++
+	}
 	for id, acc := range m.accumulators {
 		stats.TotalArtifacts++
 		stats.TotalChunks += int64(len(acc.Chunks))
 		stats.TotalBytes += acc.TotalBytes
+		if _, spilled := m.spills[id]; spilled {
+			stats.SpilledArtifacts++
+		}
 
 		switch {
 		case acc.Committed:
@@ -249,4 +679,10 @@ type ArtifactStats struct {
 	OrphanedArtifacts  int64
 	TotalChunks        int64
 	TotalBytes         int64
+	SpilledArtifacts   int64
+	// SniffedArtifacts is the number of artifacts whose declared content
+	// type was replaced by sniffing; SniffedContentTypes breaks it down by
+	// sniffed type (nil when none).
+	SniffedArtifacts    int64
+	SniffedContentTypes map[string]int64
 }
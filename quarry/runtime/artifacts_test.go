@@ -1,6 +1,14 @@
 package runtime
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash/crc32"
+	"io"
+	"maps"
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/pithecene-io/quarry/types"
@@ -293,3 +301,389 @@ func TestArtifactManager_ErrorState_RejectsOperations(t *testing.T) {
 		t.Errorf("expected 1 chunk (no mutation), got %d", len(accAfter.Chunks))
 	}
 }
+
+func TestArtifactManager_BudgetMaxBytes(t *testing.T) {
+	m := NewArtifactManager()
+	m.SetBudget(ArtifactBudget{MaxBytes: 10})
+
+	// 6 bytes in one artifact, then 6 more in another: second crosses the budget
+	if err := m.AddChunk(&types.ArtifactChunk{ArtifactID: "a", Seq: 1, IsLast: true, Data: make([]byte, 6)}); err != nil {
+		t.Fatalf("first chunk within budget: %v", err)
+	}
+	err := m.AddChunk(&types.ArtifactChunk{ArtifactID: "b", Seq: 1, IsLast: true, Data: make([]byte, 6)})
+	if !errors.Is(err, ErrArtifactBudgetExceeded) {
+		t.Fatalf("expected ErrArtifactBudgetExceeded, got %v", err)
+	}
+	if !m.BudgetExceeded() {
+		t.Error("BudgetExceeded() = false, want true")
+	}
+	if got := m.Stats().TotalBytes; got != 6 {
+		t.Errorf("TotalBytes = %d, want 6 (rejected chunk not accumulated)", got)
+	}
+}
+
+func TestArtifactManager_BudgetMaxArtifacts(t *testing.T) {
+	m := NewArtifactManager()
+	m.SetBudget(ArtifactBudget{MaxArtifacts: 2})
+
+	for _, id := range []string{"a", "b"} {
+		if err := m.CommitArtifact(id, 0); err != nil {
+			t.Fatalf("commit %s within budget: %v", id, err)
+		}
+	}
+	err := m.CommitArtifact("c", 0)
+	if !errors.Is(err, ErrArtifactBudgetExceeded) {
+		t.Fatalf("expected ErrArtifactBudgetExceeded, got %v", err)
+	}
+	if !m.BudgetExceeded() {
+		t.Error("BudgetExceeded() = false, want true")
+	}
+}
+
+func TestArtifactManager_ZeroBudgetUnlimited(t *testing.T) {
+	m := NewArtifactManager()
+
+	for i, id := range []string{"a", "b", "c"} {
+		if err := m.AddChunk(&types.ArtifactChunk{ArtifactID: id, Seq: 1, IsLast: true, Data: make([]byte, 1024)}); err != nil {
+			t.Fatalf("chunk %d: %v", i, err)
+		}
+		if err := m.CommitArtifact(id, 1024); err != nil {
+			t.Fatalf("commit %d: %v", i, err)
+		}
+	}
+	if m.BudgetExceeded() {
+		t.Error("zero budget should never be exceeded")
+	}
+}
+
+// checkedChunk builds a chunk carrying the CRC32C of data.
+func checkedChunk(id string, seq int64, isLast bool, data []byte) *types.ArtifactChunk {
+	crc := crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli))
+	return &types.ArtifactChunk{ArtifactID: id, Seq: seq, IsLast: isLast, Data: data, CRC32C: &crc}
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestArtifactManager_Verify_ChunksThenCommit(t *testing.T) {
+	m := NewArtifactManager()
+	m.SetVerify(true)
+
+	if err := m.AddChunk(checkedChunk("a", 1, false, []byte("hello "))); err != nil {
+		t.Fatalf("chunk 1: %v", err)
+	}
+	if err := m.AddChunk(checkedChunk("a", 2, true, []byte("world"))); err != nil {
+		t.Fatalf("chunk 2: %v", err)
+	}
+	if err := m.CommitArtifactWithSum("a", 11, sha256Hex([]byte("hello world"))); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	if !m.IsCommitted("a") {
+		t.Error("artifact should be committed")
+	}
+}
+
+func TestArtifactManager_Verify_CommitBeforeChunks(t *testing.T) {
+	m := NewArtifactManager()
+	m.SetVerify(true)
+
+	if err := m.CommitArtifactWithSum("a", 3, sha256Hex([]byte("abd"))); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+	err := m.AddChunk(checkedChunk("a", 1, true, []byte("abc")))
+	if !errors.Is(err, ErrArtifactChecksumMismatch) {
+		t.Fatalf("expected ErrArtifactChecksumMismatch at is_last, got %v", err)
+	}
+	if acc, _ := m.GetArtifact("a"); !acc.ErrorState {
+		t.Error("artifact should be in error state after sha256 mismatch")
+	}
+}
+
+func TestArtifactManager_Verify_ChunkCRCMismatch(t *testing.T) {
+	m := NewArtifactManager()
+	m.SetVerify(true)
+
+	chunk := checkedChunk("a", 1, true, []byte("abc"))
+	chunk.Data = []byte("abx") // corrupted in transit
+	err := m.AddChunk(chunk)
+	if !errors.Is(err, ErrArtifactChecksumMismatch) {
+		t.Fatalf("expected ErrArtifactChecksumMismatch, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "chunk seq 1") {
+		t.Errorf("error should name the chunk, got %v", err)
+	}
+}
+
+func TestArtifactManager_Verify_MissingChecksumsSkipped(t *testing.T) {
+	m := NewArtifactManager()
+	m.SetVerify(true)
+
+	// Executors and SDKs predating checksums send neither; nothing is checked
+	if err := m.AddChunk(&types.ArtifactChunk{ArtifactID: "a", Seq: 1, IsLast: true, Data: []byte("abc")}); err != nil {
+		t.Fatalf("chunk without crc32c: %v", err)
+	}
+	if err := m.CommitArtifact("a", 3); err != nil {
+		t.Fatalf("commit without sha256: %v", err)
+	}
+	if !m.IsCommitted("a") {
+		t.Error("artifact should be committed")
+	}
+
+	// A declared sha256 is still checked when the chunks carried no crc32c
+	if err := m.AddChunk(&types.ArtifactChunk{ArtifactID: "b", Seq: 1, IsLast: true, Data: []byte("abc")}); err != nil {
+		t.Fatalf("chunk without crc32c: %v", err)
+	}
+	if err := m.CommitArtifactWithSum("b", 3, strings.Repeat("0", 64)); !errors.Is(err, ErrArtifactChecksumMismatch) {
+		t.Errorf("expected checksum mismatch, got %v", err)
+	}
+}
+
+func TestArtifactManager_NoVerify_IgnoresChecksums(t *testing.T) {
+	m := NewArtifactManager()
+
+	bad := uint32(0)
+	if err := m.AddChunk(&types.ArtifactChunk{ArtifactID: "a", Seq: 1, IsLast: true, Data: []byte("abc"), CRC32C: &bad}); err != nil {
+		t.Fatalf("chunk: %v", err)
+	}
+	if err := m.CommitArtifactWithSum("a", 3, "not-a-digest"); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+}
+
+func spillFiles(t *testing.T, dir string) []os.DirEntry {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("read spill dir: %v", err)
+	}
+	return entries
+}
+
+func TestArtifactManager_Spill_AboveThreshold(t *testing.T) {
+	dir := t.TempDir()
+	m := NewArtifactManager()
+	m.SetSpill(5, dir)
+
+	chunks := []*types.ArtifactChunk{
+		{ArtifactID: "big", Seq: 1, Data: []byte("abc")},
+		{ArtifactID: "big", Seq: 2, Data: []byte("def")},
+		{ArtifactID: "big", Seq: 3, Data: []byte("gh"), IsLast: true},
+	}
+	for _, c := range chunks {
+		if err := m.AddChunk(c); err != nil {
+			t.Fatalf("AddChunk seq %d: %v", c.Seq, err)
+		}
+	}
+
+	if n := len(spillFiles(t, dir)); n != 1 {
+		t.Fatalf("expected 1 spill file, got %d", n)
+	}
+	acc, _ := m.GetArtifact("big")
+	for _, c := range acc.Chunks {
+		if c.Data != nil {
+			t.Errorf("retained chunk seq %d should not hold data after spill", c.Seq)
+		}
+	}
+	if string(chunks[0].Data) != "abc" {
+		t.Error("caller's chunk must not be mutated by spilling")
+	}
+	if got := m.Stats().SpilledArtifacts; got != 1 {
+		t.Errorf("SpilledArtifacts = %d, want 1", got)
+	}
+
+	r, err := m.OpenArtifact("big")
+	if err != nil {
+		t.Fatalf("OpenArtifact: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	_ = r.Close()
+	if string(data) != "abcdefgh" {
+		t.Errorf("reassembled data = %q, want abcdefgh", data)
+	}
+
+	// Committing deletes the spill file, not waiting for Close
+	if err := m.CommitArtifact("big", 8); err != nil {
+		t.Fatalf("CommitArtifact: %v", err)
+	}
+	if n := len(spillFiles(t, dir)); n != 0 {
+		t.Errorf("expected spill file removed on commit, %d remain", n)
+	}
+	if _, err := m.OpenArtifact("big"); err == nil {
+		t.Error("expected error opening a committed spilled artifact")
+	}
+	if got := m.Stats().SpilledArtifacts; got != 1 {
+		t.Errorf("SpilledArtifacts after commit = %d, want 1", got)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func TestArtifactManager_Spill_RemovedOnCommitBeforeChunks(t *testing.T) {
+	dir := t.TempDir()
+	m := NewArtifactManager()
+	m.SetSpill(2, dir)
+
+	if err := m.CommitArtifact("big", 4); err != nil {
+		t.Fatalf("CommitArtifact: %v", err)
+	}
+	if err := m.AddChunk(&types.ArtifactChunk{ArtifactID: "big", Seq: 1, Data: []byte("abc")}); err != nil {
+		t.Fatalf("AddChunk: %v", err)
+	}
+	if n := len(spillFiles(t, dir)); n != 1 {
+		t.Fatalf("expected 1 spill file, got %d", n)
+	}
+	if err := m.AddChunk(&types.ArtifactChunk{ArtifactID: "big", Seq: 2, Data: []byte("d"), IsLast: true}); err != nil {
+		t.Fatalf("AddChunk is_last: %v", err)
+	}
+	if !m.IsCommitted("big") {
+		t.Fatal("artifact should be committed")
+	}
+	if n := len(spillFiles(t, dir)); n != 0 {
+		t.Errorf("expected spill file removed on commit, %d remain", n)
+	}
+}
+
+func TestArtifactManager_Spill_SmallArtifactStaysInMemory(t *testing.T) {
+	dir := t.TempDir()
+	m := NewArtifactManager()
+	m.SetSpill(1024, dir)
+
+	if err := m.AddChunk(&types.ArtifactChunk{ArtifactID: "small", Seq: 1, Data: []byte("hello"), IsLast: true}); err != nil {
+		t.Fatalf("AddChunk: %v", err)
+	}
+	if n := len(spillFiles(t, dir)); n != 0 {
+		t.Errorf("small artifact should not spill, got %d files", n)
+	}
+	r, err := m.OpenArtifact("small")
+	if err != nil {
+		t.Fatalf("OpenArtifact: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	if string(data) != "hello" {
+		t.Errorf("data = %q, want hello", data)
+	}
+	if _, err := m.OpenArtifact("missing"); err == nil {
+		t.Error("expected error opening unknown artifact")
+	}
+}
+
+func TestArtifactManager_Spill_RemovedOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	m := NewArtifactManager()
+	m.SetSpill(2, dir)
+
+	if err := m.CommitArtifact("bad", 100); err != nil {
+		t.Fatalf("CommitArtifact: %v", err)
+	}
+	if err := m.AddChunk(&types.ArtifactChunk{ArtifactID: "bad", Seq: 1, Data: []byte("abc")}); err != nil {
+		t.Fatalf("AddChunk: %v", err)
+	}
+	// Size mismatch on is_last puts the artifact in error state
+	if err := m.AddChunk(&types.ArtifactChunk{ArtifactID: "bad", Seq: 2, Data: []byte("d"), IsLast: true}); err == nil {
+		t.Fatal("expected size mismatch error")
+	}
+	if _, err := m.OpenArtifact("bad"); err == nil {
+		t.Error("failed artifact should not be readable")
+	}
+	if n := len(spillFiles(t, dir)); n != 0 {
+		t.Errorf("expected spill files removed after failure, %d remain", n)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+}
+
+func TestArtifactManager_OnCommit(t *testing.T) {
+	m := NewArtifactManager()
+	commits := map[string]int64{}
+	m.SetOnCommit(func(artifactID string, sizeBytes int64) {
+		// The hook runs without the manager lock held
+		if !m.IsCommitted(artifactID) {
+			t.Errorf("%s: hook fired before the artifact was committed", artifactID)
+		}
+		commits[artifactID] = sizeBytes
+	})
+
+	// Chunks then commit
+	if err := m.AddChunk(&types.ArtifactChunk{ArtifactID: "after", Seq: 1, IsLast: true, Data: make([]byte, 4)}); err != nil {
+		t.Fatalf("AddChunk: %v", err)
+	}
+	if len(commits) != 0 {
+		t.Fatalf("hook fired before commit: %v", commits)
+	}
+	if err := m.CommitArtifact("after", 4); err != nil {
+		t.Fatalf("CommitArtifact: %v", err)
+	}
+
+	// Commit then chunks
+	if err := m.CommitArtifact("before", 3); err != nil {
+		t.Fatalf("CommitArtifact: %v", err)
+	}
+	if _, ok := commits["before"]; ok {
+		t.Fatal("hook fired before chunks completed")
+	}
+	if err := m.AddChunk(&types.ArtifactChunk{ArtifactID: "before", Seq: 1, IsLast: true, Data: make([]byte, 3)}); err != nil {
+		t.Fatalf("AddChunk: %v", err)
+	}
+
+	if len(commits) != 2 || commits["after"] != 4 || commits["before"] != 3 {
+		t.Errorf("commits = %v, want after=4 before=3", commits)
+	}
+}
+
+func TestArtifactManager_ResolveContentType(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	m := NewArtifactManager()
+	m.SetSniffContentType(true)
+	for id, data := range map[string][]byte{"png": png, "gif": []byte("GIF89a"), "blob": {0x00, 0x01, 0xfe}} {
+		if err := m.AddChunk(&types.ArtifactChunk{ArtifactID: id, Seq: 1, IsLast: true, Data: data}); err != nil {
+			t.Fatalf("AddChunk(%s): %v", id, err)
+		}
+	}
+
+	tests := []struct {
+		name, id, declared, want string
+		sniffed                  bool
+	}{
+		{"empty declared", "png", "", "image/png", true},
+		{"octet-stream declared", "png", "application/octet-stream", "image/png", true},
+		{"octet-stream with params", "png", "application/octet-stream; name=x", "image/png", true},
+		{"specific declared kept", "png", "image/webp", "image/webp", false},
+		{"another artifact", "gif", "", "image/gif", true},
+		{"undetectable bytes", "blob", "", "", false},
+		{"no chunk yet", "missing", "application/octet-stream", "application/octet-stream", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, sniffed := m.ResolveContentType(tt.id, tt.declared)
+			if got != tt.want || sniffed != tt.sniffed {
+				t.Errorf("ResolveContentType(%s, %q) = %q, %v; want %q, %v", tt.id, tt.declared, got, sniffed, tt.want, tt.sniffed)
+			}
+		})
+	}
+
+	stats := m.Stats()
+	want := map[string]int64{"image/png": 1, "image/gif": 1}
+	if stats.SniffedArtifacts != 2 || !maps.Equal(stats.SniffedContentTypes, want) {
+		t.Errorf("stats = %d sniffed %v, want %v", stats.SniffedArtifacts, stats.SniffedContentTypes, want)
+	}
+}
+
+func TestArtifactManager_ResolveContentType_Disabled(t *testing.T) {
+	m := NewArtifactManager()
+	if err := m.AddChunk(&types.ArtifactChunk{ArtifactID: "a", Seq: 1, IsLast: true, Data: []byte("%PDF-1.7")}); err != nil {
+		t.Fatalf("AddChunk: %v", err)
+	}
+	if got, sniffed := m.ResolveContentType("a", ""); got != "" || sniffed {
+		t.Errorf("ResolveContentType = %q, %v; want the declared type unchanged", got, sniffed)
+	}
+	if stats := m.Stats(); stats.SniffedArtifacts != 0 || stats.SniffedContentTypes != nil {
+		t.Errorf("stats = %+v, want no sniffed artifacts", stats)
+	}
+}
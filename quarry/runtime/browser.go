@@ -2,17 +2,69 @@ package runtime
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"os/exec"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pithecene-io/quarry/iox"
+	"github.com/pithecene-io/quarry/types"
 )
 
+// DefaultBrowserLaunchTimeout bounds how long a browser server may take to
+// print its WS endpoint after launch.
+const DefaultBrowserLaunchTimeout = 30 * time.Second
+
+// browserLaunchStderrMax caps the browser stderr kept for a launch failure.
+const browserLaunchStderrMax = 64 << 10
+
+// BrowserLaunchError reports a shared browser that failed to start or did
+// not print its WS endpoint within the launch timeout. Stderr holds the tail
+// of the browser server's stderr, where Chromium reports why it failed.
+type BrowserLaunchError struct {
+	Err    error
+	Stderr string
+}
+
+func (e *BrowserLaunchError) Error() string {
+	return fmt.Sprintf("browser launch failed: %v", e.Err)
+}
+
+func (e *BrowserLaunchError) Unwrap() error {
+	return e.Err
+}
+
+// BrowserLaunchResult builds the run result for a run that never started
+// because its shared browser failed to launch: executor_crash with reason
+// browser_launch, which --retry-on and external schedulers treat as
+// retryable. Records run outcome metrics on config.Collector like an
+// executed run.
+func BrowserLaunchResult(config *RunConfig, err error) *RunResult {
+	result := &RunResult{
+		RunMeta: config.RunMeta,
+		Outcome: &types.RunOutcome{
+			Status:  types.OutcomeExecutorCrash,
+			Reason:  types.ReasonBrowserLaunch,
+			Message: err.Error(),
+		},
+		PolicyStats: config.Policy.Stats(),
+	}
+	var launchErr *BrowserLaunchError
+	if errors.As(err, &launchErr) {
+		result.StderrOutput = launchErr.Stderr
+	}
+	config.Collector.IncRunStarted()
+	config.Collector.IncRunCrashed()
+	config.Collector.IncRunReason(string(types.ReasonBrowserLaunch))
+	return result
+}
+
 // ManagedBrowser represents a Quarry-managed browser process.
 // Used by fan-out to share a single browser across all child executor runs.
 type ManagedBrowser struct {
@@ -25,8 +77,17 @@ type ManagedBrowser struct {
 // The executor resolves puppeteer from the script's directory and launches Chrome.
 // The WS endpoint is read from the executor's stdout (first line).
 // The browser stays alive until Close() is called.
-func LaunchManagedBrowser(ctx context.Context, executorPath, scriptPath string) (*ManagedBrowser, error) {
+//
+// timeout bounds the wait for the WS endpoint; zero means
+// DefaultBrowserLaunchTimeout. Failures are returned as *BrowserLaunchError
+// carrying the tail of the browser's stderr.
+func LaunchManagedBrowser(ctx context.Context, executorPath, scriptPath string, timeout time.Duration) (*ManagedBrowser, error) {
+	if timeout <= 0 {
+		timeout = DefaultBrowserLaunchTimeout
+	}
 	cmd := exec.CommandContext(ctx, executorPath, "--launch-browser", scriptPath)
+	stderr := &tailBuffer{max: browserLaunchStderrMax}
+	cmd.Stderr = stderr
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -40,13 +101,29 @@ func LaunchManagedBrowser(ctx context.Context, executorPath, scriptPath string)
 	}
 
 	if err := cmd.Start(); err != nil {
-		return nil, fmt.Errorf("failed to start browser server: %w", err)
+		return nil, &BrowserLaunchError{Err: fmt.Errorf("failed to start browser server: %w", err)}
 	}
 
-	// Read the WS endpoint URL from stdout (first line)
-	scanner := bufio.NewScanner(stdout)
+	wsURL, err := readWSEndpoint(ctx, stdout, timeout)
+	if err != nil {
+		iox.DiscardClose(stdin)
+		_ = cmd.Process.Kill()
+		waitErr := cmd.Wait() // stderr is complete once Wait returns
+		return nil, &BrowserLaunchError{Err: withExitStatus(err, waitErr), Stderr: stderr.String()}
+	}
+
+	return &ManagedBrowser{
+		cmd:        cmd,
+		stdin:      stdin,
+		WSEndpoint: wsURL,
+	}, nil
+}
 
-	// Use a timeout channel — if the browser doesn't print the WS URL within 30s, bail
+// readWSEndpoint reads the browser WS endpoint from the first line of a
+// browser server's stdout. Shared by the managed (fan-out) and reusable
+// (daemon) launch paths. The caller owns process cleanup on error.
+func readWSEndpoint(ctx context.Context, stdout io.Reader, timeout time.Duration) (string, error) {
+	scanner := bufio.NewScanner(stdout)
 	wsURLCh := make(chan string, 1)
 	errCh := make(chan error, 1)
 	go func() {
@@ -68,27 +145,66 @@ func LaunchManagedBrowser(ctx context.Context, executorPath, scriptPath string)
 
 	select {
 	case wsURL := <-wsURLCh:
-		return &ManagedBrowser{
-			cmd:        cmd,
-			stdin:      stdin,
-			WSEndpoint: wsURL,
-		}, nil
+		return wsURL, nil
 	case err := <-errCh:
-		_ = stdin.Close()
-		_ = cmd.Process.Kill()
-		_ = cmd.Wait()
-		return nil, err
-	case <-time.After(30 * time.Second):
-		_ = stdin.Close()
-		_ = cmd.Process.Kill()
-		_ = cmd.Wait()
-		return nil, errors.New("timed out waiting for browser server WS endpoint")
+		return "", err
+	case <-time.After(timeout):
+		return "", fmt.Errorf("timed out after %s waiting for browser server WS endpoint", timeout)
 	case <-ctx.Done():
-		_ = stdin.Close()
-		_ = cmd.Process.Kill()
-		_ = cmd.Wait()
-		return nil, ctx.Err()
+		return "", ctx.Err()
+	}
+}
+
+// withExitStatus appends the browser server's exit status, as reported by
+// cmd.Wait, to a launch error.
+func withExitStatus(err, waitErr error) error {
+	var exitErr *exec.ExitError
+	if errors.As(waitErr, &exitErr) {
+		return fmt.Errorf("%w (%s)", err, exitErr)
 	}
+	return err
+}
+
+// readFileTail returns the last max bytes of the file at path, or "" if it
+// cannot be read.
+func readFileTail(path string, max int) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	if len(data) > max {
+		data = data[len(data)-max:]
+	}
+	return string(data)
+}
+
+// tailBuffer is an io.Writer that keeps the last max bytes written, and
+// counts the bytes and newlines of the whole stream.
+// Safe for the concurrent writes exec.Cmd makes while copying stderr.
+type tailBuffer struct {
+	mu       sync.Mutex
+	max      int
+	buf      []byte
+	total    int64
+	newlines int64
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.total += int64(len(p))
+	b.newlines += int64(bytes.Count(p, []byte{'\n'}))
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - b.max; over > 0 {
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return string(b.buf)
 }
 
 // Close shuts down the managed browser by closing stdin (signaling the
@@ -1,7 +1,6 @@
 package runtime
 
 import (
-	"bufio"
 	"context"
 	"crypto/sha256"
 	"encoding/json"
@@ -21,6 +20,10 @@ import (
 	"github.com/pithecene-io/quarry/types"
 )
 
+// browserServerLogName is the reusable browser server's stderr log, kept
+// beside browser.json and truncated on each launch.
+const browserServerLogName = "browser-server.log"
+
 // BrowserDiscovery is the on-disk schema for a reusable browser server.
 // Written to $QUARRY_RUNTIME_DIR/browser.json.
 type BrowserDiscovery struct {
@@ -28,6 +31,9 @@ type BrowserDiscovery struct {
 	PID        int    `json:"pid"`
 	ProxyHash  string `json:"proxy_hash"`
 	StartedAt  string `json:"started_at"`
+	// Persistent is true for servers started via `quarry browser start`
+	// (no idle shutdown).
+	Persistent bool `json:"persistent,omitempty"`
 }
 
 // ReusableBrowserConfig holds inputs for AcquireReusableBrowser.
@@ -36,11 +42,25 @@ type ReusableBrowserConfig struct {
 	ScriptPath   string
 	Proxy        *types.ProxyEndpoint
 	IdleTimeout  time.Duration // 0 means default (60s)
+	// LaunchTimeout bounds the wait for a new server's WS endpoint;
+	// 0 means DefaultBrowserLaunchTimeout.
+	LaunchTimeout time.Duration
+	// Persistent disables idle shutdown for a newly launched server.
+	// Used by `quarry browser start`; the server runs until StopBrowserServer.
+	Persistent bool
 }
 
+// ErrNoBrowserServer is returned by StopBrowserServer when no discovery
+// file exists.
+var ErrNoBrowserServer = errors.New("no browser server running")
+
 // defaultIdleTimeout is used when ReusableBrowserConfig.IdleTimeout is zero.
 const defaultIdleTimeout = 60 * time.Second
 
+// stopGracePeriod bounds how long StopBrowserServer waits after SIGTERM
+// before force-killing the process group.
+const stopGracePeriod = 5 * time.Second
+
 // AcquireReusableBrowser returns a WS endpoint for a reusable browser server.
 //
 // Flow:
@@ -50,27 +70,100 @@ const defaultIdleTimeout = 60 * time.Second
 //  4. If stale/missing → launch --browser-server, read endpoint, write browser.json
 //  5. Release lock
 func AcquireReusableBrowser(ctx context.Context, cfg ReusableBrowserConfig) (string, error) {
-	dir, err := discoveryDir()
+	disc, _, err := acquireBrowserServer(ctx, cfg)
 	if err != nil {
-		return "", fmt.Errorf("browser reuse: %w", err)
+		return "", err
 	}
+	return disc.WSEndpoint, nil
+}
+
+// StartBrowserServer starts a warm browser server for sequential runs to
+// share, or returns the existing one if it is healthy. The returned bool
+// reports whether an existing server was reused.
+//
+// Runs discover the server through the same discovery file used by
+// AcquireReusableBrowser, so no --browser-ws-endpoint is needed.
+func StartBrowserServer(ctx context.Context, cfg ReusableBrowserConfig) (*BrowserDiscovery, bool, error) {
+	return acquireBrowserServer(ctx, cfg)
+}
 
-	lockPath := filepath.Join(dir, "browser.lock")
+// StopBrowserServer stops the browser server recorded in the discovery file.
+// Sends SIGTERM (graceful browser close), force-kills the process group after
+// a grace period, and removes the discovery file.
+// Returns ErrNoBrowserServer if no discovery file exists.
+func StopBrowserServer() (*BrowserDiscovery, error) {
+	dir, err := discoveryDir()
+	if err != nil {
+		return nil, fmt.Errorf("browser stop: %w", err)
+	}
 	discoveryPath := filepath.Join(dir, "browser.json")
 
-	// Acquire exclusive file lock
-	lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_RDWR, 0600)
+	unlock, err := lockDiscovery(dir)
 	if err != nil {
-		return "", fmt.Errorf("browser reuse: open lock: %w", err)
+		return nil, fmt.Errorf("browser stop: %w", err)
 	}
-	defer func() {
-		_ = syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
-		_ = lockFile.Close()
-	}()
+	defer unlock()
 
+	disc, err := readDiscovery(discoveryPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNoBrowserServer
+		}
+		return nil, fmt.Errorf("browser stop: %w", err)
+	}
+
+	// Only signal processes verified to be browser servers (guards PID reuse)
+	if processStatus(disc.PID) != processGone && isBrowserServerProcess(disc.PID) {
+		_ = syscall.Kill(disc.PID, syscall.SIGTERM)
+		deadline := time.Now().Add(stopGracePeriod)
+		for processStatus(disc.PID) == processHealthy && time.Now().Before(deadline) {
+			time.Sleep(100 * time.Millisecond)
+		}
+		if processStatus(disc.PID) == processHealthy {
+			killProcessGroup(disc.PID)
+		}
+	}
+
+	if err := os.Remove(discoveryPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return disc, fmt.Errorf("browser stop: remove discovery: %w", err)
+	}
+	return disc, nil
+}
+
+// lockDiscovery acquires the exclusive browser.lock in dir.
+// The returned function releases the lock.
+func lockDiscovery(dir string) (func(), error) {
+	lockFile, err := os.OpenFile(filepath.Join(dir, "browser.lock"), os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("open lock: %w", err)
+	}
 	if err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX); err != nil {
-		return "", fmt.Errorf("browser reuse: flock: %w", err)
+		iox.DiscardClose(lockFile)
+		return nil, fmt.Errorf("flock: %w", err)
 	}
+	return func() {
+		_ = syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
+		iox.DiscardClose(lockFile)
+	}, nil
+}
+
+// acquireBrowserServer implements the shared discover-or-launch flow for
+// AcquireReusableBrowser and StartBrowserServer. Returns the discovery
+// record and whether an existing healthy server was reused.
+func acquireBrowserServer(ctx context.Context, cfg ReusableBrowserConfig) (*BrowserDiscovery, bool, error) {
+	dir, err := discoveryDir()
+	if err != nil {
+		return nil, false, fmt.Errorf("browser reuse: %w", err)
+	}
+
+	discoveryPath := filepath.Join(dir, "browser.json")
+
+	// Acquire exclusive file lock
+	unlock, err := lockDiscovery(dir)
+	if err != nil {
+		return nil, false, fmt.Errorf("browser reuse: %w", err)
+	}
+	defer unlock()
 
 	wantHash := proxyHash(cfg.Proxy)
 
@@ -80,7 +173,7 @@ func AcquireReusableBrowser(ctx context.Context, cfg ReusableBrowserConfig) (str
 		// Validate proxy hash
 		if disc.ProxyHash != wantHash {
 			fmt.Fprintf(os.Stderr, "Proxy mismatch, skipping browser reuse for this run\n")
-			return "", errors.New("browser reuse: proxy mismatch")
+			return nil, false, errors.New("browser reuse: proxy mismatch")
 		}
 
 		// Fast-path: check process liveness before the 2s HTTP health check
@@ -96,7 +189,7 @@ func AcquireReusableBrowser(ctx context.Context, cfg ReusableBrowserConfig) (str
 			if err := HealthCheckBrowser(disc.WSEndpoint); err == nil {
 				age := time.Since(parseTimeOrZero(disc.StartedAt))
 				fmt.Fprintf(os.Stderr, "Reusing browser server (pid=%d, age=%s)\n", disc.PID, age.Round(time.Second))
-				return disc.WSEndpoint, nil
+				return disc, true, nil
 			}
 			fmt.Fprintf(os.Stderr, "Stale browser server detected (pid=%d), cleaning up and relaunching\n", disc.PID)
 			cleanupStaleProcess(disc.PID)
@@ -104,15 +197,19 @@ func AcquireReusableBrowser(ctx context.Context, cfg ReusableBrowserConfig) (str
 		}
 	}
 
-	// Launch new browser server
+	// Launch new browser server. Persistent servers pass an idle timeout of
+	// 0, which disables idle shutdown in the executor.
 	idleTimeout := cfg.IdleTimeout
 	if idleTimeout == 0 {
 		idleTimeout = defaultIdleTimeout
 	}
+	if cfg.Persistent {
+		idleTimeout = 0
+	}
 
-	wsEndpoint, pid, err := launchBrowserServerProcess(ctx, cfg.ExecutorPath, cfg.ScriptPath, discoveryPath, idleTimeout, cfg.Proxy)
+	wsEndpoint, pid, err := launchBrowserServerProcess(ctx, cfg.ExecutorPath, cfg.ScriptPath, discoveryPath, idleTimeout, cfg.LaunchTimeout, cfg.Proxy)
 	if err != nil {
-		return "", fmt.Errorf("browser reuse: launch: %w", err)
+		return nil, false, fmt.Errorf("browser reuse: launch: %w", err)
 	}
 
 	// Write discovery file
@@ -121,14 +218,15 @@ func AcquireReusableBrowser(ctx context.Context, cfg ReusableBrowserConfig) (str
 		PID:        pid,
 		ProxyHash:  wantHash,
 		StartedAt:  time.Now().UTC().Format(time.RFC3339),
+		Persistent: cfg.Persistent,
 	}
 	if err := writeDiscovery(discoveryPath, disc); err != nil {
 		killProcessGroup(pid)
-		return "", fmt.Errorf("browser reuse: write discovery: %w", err)
+		return nil, false, fmt.Errorf("browser reuse: write discovery: %w", err)
 	}
 
 	fmt.Fprintf(os.Stderr, "Starting reusable browser server (pid=%d)\n", pid)
-	return wsEndpoint, nil
+	return disc, false, nil
 }
 
 // discoveryDir returns the directory for browser discovery files.
@@ -264,9 +362,12 @@ func proxyHash(proxy *types.ProxyEndpoint) string {
 func launchBrowserServerProcess(
 	ctx context.Context,
 	executorPath, scriptPath, discoveryPath string,
-	idleTimeout time.Duration,
+	idleTimeout, launchTimeout time.Duration,
 	proxy *types.ProxyEndpoint,
 ) (wsEndpoint string, pid int, err error) {
+	if launchTimeout <= 0 {
+		launchTimeout = DefaultBrowserLaunchTimeout
+	}
 	cmd := exec.Command(executorPath, "--browser-server", scriptPath)
 
 	// Detach: new session so the browser server outlives the parent
@@ -292,51 +393,33 @@ func launchBrowserServerProcess(
 		return "", 0, fmt.Errorf("stdout pipe: %w", err)
 	}
 
-	// Inherit stderr for diagnostics
-	cmd.Stderr = os.Stderr
+	// Stderr goes to a log file beside the discovery file rather than a
+	// pipe, which would break once this run exits. A failed launch reads
+	// its tail back for the BrowserLaunchError.
+	logPath := filepath.Join(filepath.Dir(discoveryPath), browserServerLogName)
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return "", 0, fmt.Errorf("open browser server log: %w", err)
+	}
+	defer iox.DiscardClose(logFile) // the server holds its own descriptor
+	cmd.Stderr = logFile
 
 	if err := cmd.Start(); err != nil {
-		return "", 0, fmt.Errorf("start browser server: %w", err)
-	}
-
-	// Read WS endpoint from first line of stdout
-	scanner := bufio.NewScanner(stdout)
-	wsURLCh := make(chan string, 1)
-	errCh := make(chan error, 1)
-	go func() {
-		if scanner.Scan() {
-			line := strings.TrimSpace(scanner.Text())
-			if strings.HasPrefix(line, "ws://") || strings.HasPrefix(line, "wss://") {
-				wsURLCh <- line
-				return
-			}
-			errCh <- fmt.Errorf("unexpected browser server output: %q", line)
-			return
-		}
-		if scanErr := scanner.Err(); scanErr != nil {
-			errCh <- fmt.Errorf("reading browser server stdout: %w", scanErr)
-			return
-		}
-		errCh <- errors.New("browser server exited without printing WS endpoint")
-	}()
-
-	select {
-	case ws := <-wsURLCh:
-		// Detached process — do NOT call cmd.Wait() (we don't own the lifecycle)
-		return ws, cmd.Process.Pid, nil
-	case readErr := <-errCh:
-		killProcessGroup(cmd.Process.Pid)
-		_ = cmd.Wait()
-		return "", 0, readErr
-	case <-time.After(30 * time.Second):
-		killProcessGroup(cmd.Process.Pid)
-		_ = cmd.Wait()
-		return "", 0, errors.New("timed out waiting for browser server WS endpoint")
-	case <-ctx.Done():
+		return "", 0, &BrowserLaunchError{Err: fmt.Errorf("failed to start browser server: %w", err)}
+	}
+
+	ws, err := readWSEndpoint(ctx, stdout, launchTimeout)
+	if err != nil {
 		killProcessGroup(cmd.Process.Pid)
-		_ = cmd.Wait()
-		return "", 0, ctx.Err()
+		waitErr := cmd.Wait()
+		return "", 0, &BrowserLaunchError{
+			Err:    withExitStatus(err, waitErr),
+			Stderr: readFileTail(logPath, browserLaunchStderrMax),
+		}
 	}
+
+	// Detached process — do NOT call cmd.Wait() (we don't own the lifecycle)
+	return ws, cmd.Process.Pid, nil
 }
 
 // killProcessGroup kills an entire process group by negated PID.
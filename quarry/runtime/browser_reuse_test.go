@@ -2,9 +2,11 @@ package runtime
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"testing"
 	"time"
@@ -361,3 +363,88 @@ func TestIdleTimeoutFromEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestStopBrowserServer_NoDiscovery(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+
+	_, err := StopBrowserServer()
+	if !errors.Is(err, ErrNoBrowserServer) {
+		t.Fatalf("expected ErrNoBrowserServer, got %v", err)
+	}
+}
+
+func TestStopBrowserServer_RemovesStaleDiscovery(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", tmpDir)
+
+	dir, err := discoveryDir()
+	if err != nil {
+		t.Fatalf("discoveryDir failed: %v", err)
+	}
+	path := filepath.Join(dir, "browser.json")
+
+	// Use our own PID: alive but not a browser server, so it must not be signalled.
+	disc := &BrowserDiscovery{
+		WSEndpoint: "ws://127.0.0.1:1/devtools/browser/stale",
+		PID:        os.Getpid(),
+		StartedAt:  time.Now().UTC().Format(time.RFC3339),
+		Persistent: true,
+	}
+	if err := writeDiscovery(path, disc); err != nil {
+		t.Fatalf("writeDiscovery: %v", err)
+	}
+
+	got, err := StopBrowserServer()
+	if err != nil {
+		t.Fatalf("StopBrowserServer: %v", err)
+	}
+	if got.PID != disc.PID {
+		t.Errorf("PID: got %d, want %d", got.PID, disc.PID)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected discovery file removed, stat err = %v", err)
+	}
+}
+
+func TestReadWSEndpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"ws endpoint", "ws://127.0.0.1:9222/devtools/browser/abc\n", "ws://127.0.0.1:9222/devtools/browser/abc", false},
+		{"wss endpoint trimmed", "  wss://host/devtools/browser/abc  \n", "wss://host/devtools/browser/abc", false},
+		{"unexpected output", "error: puppeteer not found\n", "", true},
+		{"empty output", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readWSEndpoint(t.Context(), strings.NewReader(tt.input), time.Second)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLaunchBrowserServerProcess_FailureCapturesStderr(t *testing.T) {
+	executor := writeFakeExecutor(t, `echo "chrome: missing libnss3.so" >&2; exit 3`)
+	discoveryPath := filepath.Join(t.TempDir(), "browser.json")
+
+	_, _, err := launchBrowserServerProcess(t.Context(), executor, "script.ts", discoveryPath, time.Minute, 5*time.Second, nil)
+	var launchErr *BrowserLaunchError
+	if !errors.As(err, &launchErr) {
+		t.Fatalf("err = %v, want *BrowserLaunchError", err)
+	}
+	if !strings.Contains(launchErr.Stderr, "missing libnss3.so") {
+		t.Errorf("Stderr = %q, want browser stderr", launchErr.Stderr)
+	}
+	if !strings.Contains(err.Error(), "exit status 3") {
+		t.Errorf("err = %v, want exit status", err)
+	}
+}
@@ -0,0 +1,95 @@
+package runtime
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pithecene-io/quarry/metrics"
+	"github.com/pithecene-io/quarry/policy"
+	"github.com/pithecene-io/quarry/types"
+)
+
+// writeFakeExecutor writes a shell script standing in for the executor's
+// --launch-browser mode.
+func writeFakeExecutor(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "executor.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLaunchManagedBrowser_FailureCapturesStderr(t *testing.T) {
+	executor := writeFakeExecutor(t, `echo "chrome: missing libnss3.so" >&2; exit 1`)
+
+	_, err := LaunchManagedBrowser(t.Context(), executor, "script.ts", 5*time.Second)
+	var launchErr *BrowserLaunchError
+	if !errors.As(err, &launchErr) {
+		t.Fatalf("err = %v, want *BrowserLaunchError", err)
+	}
+	if !strings.Contains(launchErr.Stderr, "missing libnss3.so") {
+		t.Errorf("Stderr = %q, want browser stderr", launchErr.Stderr)
+	}
+	if !strings.Contains(err.Error(), "without printing WS endpoint") {
+		t.Errorf("err = %v, want exit-without-endpoint cause", err)
+	}
+	if !strings.Contains(err.Error(), "exit status 1") {
+		t.Errorf("err = %v, want exit status", err)
+	}
+}
+
+func TestLaunchManagedBrowser_Timeout(t *testing.T) {
+	executor := writeFakeExecutor(t, `echo "starting" >&2; exec sleep 10`)
+
+	start := time.Now()
+	_, err := LaunchManagedBrowser(t.Context(), executor, "script.ts", 200*time.Millisecond)
+	var launchErr *BrowserLaunchError
+	if !errors.As(err, &launchErr) {
+		t.Fatalf("err = %v, want *BrowserLaunchError", err)
+	}
+	if !strings.Contains(err.Error(), "timed out after 200ms") {
+		t.Errorf("err = %v, want timeout", err)
+	}
+	if launchErr.Stderr != "starting\n" {
+		t.Errorf("Stderr = %q, want %q", launchErr.Stderr, "starting\n")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("launch took %s, want the timeout to bound it", elapsed)
+	}
+}
+
+func TestTailBuffer(t *testing.T) {
+	b := &tailBuffer{max: 8}
+	_, _ = b.Write([]byte("0123"))
+	_, _ = b.Write([]byte("456789ab"))
+	if got := b.String(); got != "456789ab" {
+		t.Errorf("String() = %q, want last 8 bytes", got)
+	}
+}
+
+func TestBrowserLaunchResult(t *testing.T) {
+	collector := metrics.NewCollector("strict", "node", "fs", "run-1", "")
+	config := &RunConfig{
+		RunMeta:   &types.RunMeta{RunID: "run-1", Attempt: 1},
+		Policy:    policy.NewNoopPolicy(),
+		Collector: collector,
+	}
+	err := &BrowserLaunchError{Err: errors.New("boom"), Stderr: "chrome died"}
+
+	result := BrowserLaunchResult(config, err)
+	if result.Outcome.Status != types.OutcomeExecutorCrash || result.Outcome.Reason != types.ReasonBrowserLaunch {
+		t.Errorf("outcome = %s/%s, want executor_crash/browser_launch", result.Outcome.Status, result.Outcome.Reason)
+	}
+	if result.StderrOutput != "chrome died" {
+		t.Errorf("StderrOutput = %q, want browser stderr", result.StderrOutput)
+	}
+	snap := collector.Snapshot()
+	if snap.RunsStarted != 1 || snap.RunsCrashed != 1 || snap.RunsByReason["browser_launch"] != 1 {
+		t.Errorf("metrics = started %d crashed %d by_reason %v", snap.RunsStarted, snap.RunsCrashed, snap.RunsByReason)
+	}
+}
@@ -0,0 +1,116 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ErrDomainDenied indicates a job or enqueue target URL was blocked by the
+// domain policy. The run fails with a policy_failure outcome.
+var ErrDomainDenied = errors.New("domain denied by domain policy")
+
+// DomainPolicy is a runtime-enforced allowlist/denylist of target domains,
+// independent of script behavior. It checks the job payload's "url" field
+// before the executor launches, and every enqueue event's params.url (see
+// OriginParam) before the fan-out operator sees it.
+//
+// An entry matches the domain itself and all of its subdomains
+// ("example.com" matches "www.example.com"). Matching is case-insensitive
+// and ignores scheme and port. Deny entries win over allow entries. With a
+// non-empty allowlist, any host not listed is blocked, including a url
+// field that is not an absolute URL; targets without a url field are not
+// checked.
+type DomainPolicy struct {
+	allow []string
+	deny  []string
+}
+
+// NewDomainPolicy builds a DomainPolicy from allow and deny entries.
+// Returns an error for empty entries or entries that are not bare domains
+// (containing a scheme, path, port, or wildcard). Returns nil (no
+// enforcement) when both lists are empty.
+func NewDomainPolicy(allow, deny []string) (*DomainPolicy, error) {
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil, nil
+	}
+	p := &DomainPolicy{}
+	var err error
+	if p.allow, err = normalizeDomains(allow); err != nil {
+		return nil, err
+	}
+	if p.deny, err = normalizeDomains(deny); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// normalizeDomains lowercases entries and strips a trailing dot.
+func normalizeDomains(entries []string) ([]string, error) {
+	out := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		domain := strings.TrimSuffix(strings.ToLower(strings.TrimSpace(entry)), ".")
+		if domain == "" {
+			return nil, errors.New("domain policy entry must not be empty")
+		}
+		if strings.ContainsAny(domain, ":/*@ ") || strings.HasPrefix(domain, ".") {
+			return nil, fmt.Errorf("invalid domain policy entry %q: want a bare domain such as example.com", entry)
+		}
+		out = append(out, domain)
+	}
+	return out, nil
+}
+
+// Check returns an error wrapping ErrDomainDenied if rawURL targets a
+// blocked domain. Nil-receiver safe (allows everything).
+func (p *DomainPolicy) Check(rawURL string) error {
+	if p == nil {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		if len(p.allow) > 0 {
+			return fmt.Errorf("%w: %q is not an absolute URL and an allowlist is set", ErrDomainDenied, rawURL)
+		}
+		return nil
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if domain, ok := matchDomain(host, p.deny); ok {
+		return fmt.Errorf("%w: %s matches deny entry %s", ErrDomainDenied, host, domain)
+	}
+	if len(p.allow) > 0 {
+		if _, ok := matchDomain(host, p.allow); !ok {
+			return fmt.Errorf("%w: %s is not on the allowlist", ErrDomainDenied, host)
+		}
+	}
+	return nil
+}
+
+// CheckTarget checks the "url" field of a job payload or enqueue params.
+// Targets that are not objects or carry no string url are not checked.
+// Nil-receiver safe.
+func (p *DomainPolicy) CheckTarget(target any) error {
+	if p == nil {
+		return nil
+	}
+	fields, ok := target.(map[string]any)
+	if !ok {
+		return nil
+	}
+	raw, ok := fields[OriginParam].(string)
+	if !ok {
+		return nil
+	}
+	return p.Check(raw)
+}
+
+// matchDomain returns the first entry that host equals or is a subdomain of.
+func matchDomain(host string, entries []string) (string, bool) {
+	for _, domain := range entries {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return domain, true
+		}
+	}
+	return "", false
+}
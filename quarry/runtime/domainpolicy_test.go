@@ -0,0 +1,140 @@
+package runtime
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/pithecene-io/quarry/log"
+	"github.com/pithecene-io/quarry/policy"
+	"github.com/pithecene-io/quarry/types"
+)
+
+func TestDomainPolicy_Check(t *testing.T) {
+	denyOnly, err := NewDomainPolicy(nil, []string{"Blocked.example."})
+	if err != nil {
+		t.Fatalf("NewDomainPolicy: %v", err)
+	}
+	allowList, err := NewDomainPolicy([]string{"shop.example"}, []string{"private.shop.example"})
+	if err != nil {
+		t.Fatalf("NewDomainPolicy: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		policy  *DomainPolicy
+		url     string
+		blocked bool
+	}{
+		{"denied domain", denyOnly, "https://blocked.example/page", true},
+		{"denied subdomain", denyOnly, "http://www.BLOCKED.example:8080/", true},
+		{"suffix is not a subdomain", denyOnly, "https://notblocked.example/", false},
+		{"other domain", denyOnly, "https://open.example/", false},
+		{"relative url without allowlist", denyOnly, "/path", false},
+		{"allowed domain", allowList, "https://shop.example/item", false},
+		{"allowed subdomain", allowList, "https://cdn.shop.example/img.png", false},
+		{"deny wins over allow", allowList, "https://private.shop.example/", true},
+		{"unlisted domain", allowList, "https://other.example/", true},
+		{"relative url with allowlist", allowList, "shop.example/item", true},
+		{"nil policy", nil, "https://blocked.example/", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.url)
+			if blocked := errors.Is(err, ErrDomainDenied); blocked != tt.blocked {
+				t.Errorf("Check(%q) = %v, want blocked=%v", tt.url, err, tt.blocked)
+			}
+		})
+	}
+}
+
+func TestDomainPolicy_CheckTarget(t *testing.T) {
+	p, _ := NewDomainPolicy(nil, []string{"blocked.example"})
+
+	if err := p.CheckTarget(map[string]any{"url": "https://blocked.example/"}); !errors.Is(err, ErrDomainDenied) {
+		t.Errorf("expected url field to be checked, got %v", err)
+	}
+	for _, target := range []any{nil, "https://blocked.example/", map[string]any{"page": 1}, map[string]any{"url": 42}} {
+		if err := p.CheckTarget(target); err != nil {
+			t.Errorf("CheckTarget(%v) = %v, want unchecked", target, err)
+		}
+	}
+}
+
+func TestNewDomainPolicy(t *testing.T) {
+	if p, err := NewDomainPolicy(nil, nil); p != nil || err != nil {
+		t.Errorf("empty lists = %v, %v; want nil, nil", p, err)
+	}
+	for _, entry := range []string{"", "https://example.com", "example.com/path", "example.com:443", "*.example.com", ".example.com"} {
+		if _, err := NewDomainPolicy([]string{entry}, nil); err == nil {
+			t.Errorf("entry %q: expected error", entry)
+		}
+	}
+}
+
+func TestRunOrchestrator_DomainPolicyBlocksJob(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-domain", Attempt: 1}
+	mockExec := newMockExecutor(makeValidEventStream(runMeta), 0)
+	domains, _ := NewDomainPolicy(nil, []string{"blocked.example"})
+
+	orchestrator, err := NewRunOrchestrator(&RunConfig{
+		ExecutorPath: "/fake/executor",
+		ScriptPath:   "/fake/script.js",
+		Job:          map[string]any{"url": "https://www.blocked.example/a"},
+		RunMeta:      runMeta,
+		Policy:       newFlushTrackingPolicy(),
+		DomainPolicy: domains,
+		ExecutorFactory: func(_ *ExecutorConfig) Executor {
+			return mockExec
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	result, err := orchestrator.Execute(t.Context())
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if result.Outcome.Status != types.OutcomePolicyFailure || result.Outcome.Reason != types.ReasonDomainDenied {
+		t.Errorf("outcome = %s/%s, want policy_failure/domain_denied", result.Outcome.Status, result.Outcome.Reason)
+	}
+	if mockExec.started {
+		t.Error("executor must not start for a blocked job")
+	}
+}
+
+func TestIngestionEngine_DomainPolicyBlocksEnqueue(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+	enqueue := func(seq int64, url string) *types.EventEnvelope {
+		e := seqLogEnvelope(seq)
+		e.Type = types.EventTypeEnqueue
+		e.Payload = map[string]any{"target": "detail.ts", "params": map[string]any{"url": url}}
+		return e
+	}
+
+	var buf bytes.Buffer
+	buf.Write(encodeEventFrame(enqueue(1, "https://open.example/a")))
+	buf.Write(encodeEventFrame(enqueue(2, "https://blocked.example/b")))
+	buf.Write(encodeEventFrame(enqueue(3, "https://open.example/c")))
+
+	var observed []string
+	observer := func(e *types.EventEnvelope) {
+		params, _ := e.Payload["params"].(map[string]any)
+		url, _ := params["url"].(string)
+		observed = append(observed, url)
+	}
+	domains, _ := NewDomainPolicy(nil, []string{"blocked.example"})
+	engine := NewIngestionEngine(&buf, policy.NewNoopPolicy(), NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, nil, observer, nil)
+	engine.SetDomainPolicy(domains)
+
+	err := engine.Run(t.Context())
+	if !IsPolicyError(err) || !errors.Is(err, ErrDomainDenied) {
+		t.Fatalf("Run = %v, want a policy error wrapping ErrDomainDenied", err)
+	}
+	if got := ReasonFromIngestionError(err); got != types.ReasonDomainDenied {
+		t.Errorf("reason = %s, want %s", got, types.ReasonDomainDenied)
+	}
+	if len(observed) != 1 || observed[0] != "https://open.example/a" {
+		t.Errorf("observed = %v, want only the enqueue before the blocked one", observed)
+	}
+}
@@ -0,0 +1,525 @@
+package runtime
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+// EventFilter is a predicate over event envelopes (--events-filter). Events
+// it does not match are dropped before the policy, except artifact,
+// checkpoint, and terminal events, which are always kept.
+//
+// The expression language is deliberately small and side-effect free:
+//
+//	expr       = or
+//	or         = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | "(" expr ")" | "has(" path ")" | comparison
+//	comparison = operand ( "==" | "!=" | "<" | "<=" | ">" | ">=" ) operand
+//	           | operand "=~" string
+//	operand    = path | string | number | "true" | "false" | "null"
+//	path       = field { "." name }
+//
+// field is an envelope field: type, event_id, run_id, seq, ts, attempt,
+// contract_version, job_id, parent_run_id, or payload. Strings are quoted
+// with " or '. Missing paths are null. Comparisons across types are false;
+// numbers compare by value whatever their msgpack encoding; =~ is an RE2
+// match on a string.
+type EventFilter struct {
+	expr string
+	root filterNode
+}
+
+// envelopeFields are the envelope fields a filter path may start with.
+var envelopeFields = map[string]bool{
+	"type": true, "event_id": true, "run_id": true, "seq": true, "ts": true,
+	"attempt": true, "contract_version": true, "job_id": true,
+	"parent_run_id": true, "payload": true,
+}
+
+// NewEventFilter parses a filter expression. Returns nil (keep all) for an
+// empty expression.
+func NewEventFilter(expr string) (*EventFilter, error) {
+	if strings.TrimSpace(expr) == "" {
+		return nil, nil
+	}
+	tokens, err := lexFilter(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid events filter: %w", err)
+	}
+	p := &filterParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.peek().kind != tokEOF {
+		err = fmt.Errorf("unexpected %s at offset %d", p.peek(), p.peek().pos)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid events filter: %w", err)
+	}
+	return &EventFilter{expr: expr, root: root}, nil
+}
+
+// String returns the source expression.
+func (f *EventFilter) String() string {
+	if f == nil {
+		return ""
+	}
+	return f.expr
+}
+
+// Match reports whether envelope satisfies the filter. A nil filter
+// matches everything.
+func (f *EventFilter) Match(envelope *types.EventEnvelope) bool {
+	if f == nil {
+		return true
+	}
+	return f.root.eval(envelope)
+}
+
+// filterExempt reports whether an event type bypasses the filter: artifact
+// commits (their chunks are already buffered), checkpoints, and terminals.
+func filterExempt(t types.EventType) bool {
+	return t == types.EventTypeArtifact || t == types.EventTypeCheckpoint || t.IsTerminal()
+}
+
+// filterNode is a boolean expression node.
+type filterNode interface {
+	eval(e *types.EventEnvelope) bool
+}
+
+type orNode struct{ left, right filterNode }
+
+func (n orNode) eval(e *types.EventEnvelope) bool { return n.left.eval(e) || n.right.eval(e) }
+
+type andNode struct{ left, right filterNode }
+
+func (n andNode) eval(e *types.EventEnvelope) bool { return n.left.eval(e) && n.right.eval(e) }
+
+type notNode struct{ inner filterNode }
+
+func (n notNode) eval(e *types.EventEnvelope) bool { return !n.inner.eval(e) }
+
+type hasNode struct{ path []string }
+
+func (n hasNode) eval(e *types.EventEnvelope) bool { return lookupPath(e, n.path) != nil }
+
+type matchNode struct {
+	operand filterOperand
+	re      *regexp.Regexp
+}
+
+func (n matchNode) eval(e *types.EventEnvelope) bool {
+	s, ok := n.operand.resolve(e).(string)
+	return ok && n.re.MatchString(s)
+}
+
+type compareNode struct {
+	op          string
+	left, right filterOperand
+}
+
+func (n compareNode) eval(e *types.EventEnvelope) bool {
+	l, r := n.left.resolve(e), n.right.resolve(e)
+	switch n.op {
+	case "==":
+		return filterEqual(l, r)
+	case "!=":
+		return !filterEqual(l, r)
+	}
+	c, ok := filterCompare(l, r)
+	if !ok {
+		return false
+	}
+	switch n.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	default: // ">="
+		return c >= 0
+	}
+}
+
+// filterOperand is a path into the envelope or a literal.
+type filterOperand struct {
+	path    []string
+	literal any
+}
+
+func (o filterOperand) resolve(e *types.EventEnvelope) any {
+	if o.path != nil {
+		return lookupPath(e, o.path)
+	}
+	return o.literal
+}
+
+// lookupPath resolves a validated path; missing fields resolve to nil.
+func lookupPath(e *types.EventEnvelope, path []string) any {
+	var v any
+	switch path[0] {
+	case "type":
+		v = string(e.Type)
+	case "event_id":
+		v = e.EventID
+	case "run_id":
+		v = e.RunID
+	case "seq":
+		v = e.Seq
+	case "ts":
+		v = e.Ts
+	case "attempt":
+		v = e.Attempt
+	case "contract_version":
+		v = e.ContractVersion
+	case "job_id":
+		if e.JobID != nil {
+			v = *e.JobID
+		}
+	case "parent_run_id":
+		if e.ParentRunID != nil {
+			v = *e.ParentRunID
+		}
+	case "payload":
+		if e.Payload != nil {
+			v = e.Payload
+		}
+	}
+	for _, name := range path[1:] {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[name]
+	}
+	return v
+}
+
+// filterEqual compares two values; numbers compare by value.
+func filterEqual(l, r any) bool {
+	if ln, ok := filterNumber(l); ok {
+		rn, ok := filterNumber(r)
+		return ok && ln == rn
+	}
+	switch lv := l.(type) {
+	case nil:
+		return r == nil
+	case string:
+		rv, ok := r.(string)
+		return ok && lv == rv
+	case bool:
+		rv, ok := r.(bool)
+		return ok && lv == rv
+	default:
+		return false
+	}
+}
+
+// filterCompare orders two numbers or two strings.
+func filterCompare(l, r any) (int, bool) {
+	if ln, ok := filterNumber(l); ok {
+		rn, ok := filterNumber(r)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case ln < rn:
+			return -1, true
+		case ln > rn:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+	ls, lok := l.(string)
+	rs, rok := r.(string)
+	if !lok || !rok {
+		return 0, false
+	}
+	return strings.Compare(ls, rs), true
+}
+
+// filterNumber converts any msgpack-decoded number to float64.
+func filterNumber(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
+
+// Filter expression tokens.
+type filterTokenKind int
+
+const (
+	tokEOF filterTokenKind = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+	pos  int
+}
+
+func (t filterToken) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+// compareOps are the binary comparison operators.
+var compareOps = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true}
+
+// filterOps are the operator tokens, longest first.
+var filterOps = []string{"==", "!=", "<=", ">=", "=~", "&&", "||", "<", ">", "!", "(", ")", "."}
+
+// lexFilter splits an expression into tokens. String tokens hold the
+// unquoted value.
+func lexFilter(expr string) ([]filterToken, error) {
+	var tokens []filterToken
+	for i := 0; i < len(expr); {
+		c := rune(expr[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(expr) && rune(expr[end]) != c {
+				if expr[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expr) {
+				return nil, fmt.Errorf("unterminated string at offset %d", i)
+			}
+			raw := expr[i : end+1]
+			if c == '\'' {
+				raw = `"` + strings.ReplaceAll(raw[1:len(raw)-1], `"`, `\"`) + `"`
+			}
+			s, err := strconv.Unquote(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at offset %d: %w", i, err)
+			}
+			tokens = append(tokens, filterToken{kind: tokString, text: s, pos: i})
+			i = end + 1
+		case c == '-' || unicode.IsDigit(c):
+			end := i + 1
+			for end < len(expr) && (unicode.IsDigit(rune(expr[end])) || strings.ContainsRune(".eE+-", rune(expr[end]))) {
+				end++
+			}
+			tokens = append(tokens, filterToken{kind: tokNumber, text: expr[i:end], pos: i})
+			i = end
+		case c == '_' || unicode.IsLetter(c):
+			end := i + 1
+			for end < len(expr) && (expr[end] == '_' || unicode.IsLetter(rune(expr[end])) || unicode.IsDigit(rune(expr[end]))) {
+				end++
+			}
+			tokens = append(tokens, filterToken{kind: tokIdent, text: expr[i:end], pos: i})
+			i = end
+		default:
+			op := ""
+			for _, candidate := range filterOps {
+				if strings.HasPrefix(expr[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+			tokens = append(tokens, filterToken{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, filterToken{kind: tokEOF, pos: len(expr)}), nil
+}
+
+// filterParser is a recursive-descent parser over filter tokens.
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) peek() filterToken { return p.tokens[p.pos] }
+
+func (p *filterParser) next() filterToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the operator op if it is next.
+func (p *filterParser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) expect(op string) error {
+	if !p.accept(op) {
+		return fmt.Errorf("expected %q, got %s at offset %d", op, p.peek(), p.peek().pos)
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	if p.accept("!") {
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner}, nil
+	}
+	if p.accept("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(")")
+	}
+	if t := p.peek(); t.kind == tokIdent && t.text == "has" && p.tokens[p.pos+1].text == "(" {
+		p.pos += 2
+		operand, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if operand.path == nil {
+			return nil, fmt.Errorf("has() takes a field path, at offset %d", t.pos)
+		}
+		return hasNode{operand.path}, p.expect(")")
+	}
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (filterNode, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	op := p.next()
+	switch {
+	case op.kind == tokOp && op.text == "=~":
+		pattern := p.next()
+		if pattern.kind != tokString {
+			return nil, fmt.Errorf("=~ takes a string pattern, got %s at offset %d", pattern, pattern.pos)
+		}
+		re, err := regexp.Compile(pattern.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern at offset %d: %w", pattern.pos, err)
+		}
+		return matchNode{left, re}, nil
+	case op.kind == tokOp && compareOps[op.text]:
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return compareNode{op: op.text, left: left, right: right}, nil
+	default:
+		return nil, fmt.Errorf("expected a comparison operator, got %s at offset %d", op, op.pos)
+	}
+}
+
+func (p *filterParser) parseOperand() (filterOperand, error) {
+	t := p.next()
+	switch t.kind {
+	case tokString:
+		return filterOperand{literal: t.text}, nil
+	case tokNumber:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return filterOperand{}, fmt.Errorf("invalid number %q at offset %d", t.text, t.pos)
+		}
+		return filterOperand{literal: n}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return filterOperand{literal: true}, nil
+		case "false":
+			return filterOperand{literal: false}, nil
+		case "null":
+			return filterOperand{}, nil
+		}
+		if !envelopeFields[t.text] {
+			return filterOperand{}, fmt.Errorf("unknown field %q at offset %d (want type, event_id, run_id, seq, ts, attempt, contract_version, job_id, parent_run_id, or payload)", t.text, t.pos)
+		}
+		path := []string{t.text}
+		for p.accept(".") {
+			name := p.next()
+			if name.kind != tokIdent {
+				return filterOperand{}, fmt.Errorf("expected a field name after \".\", got %s at offset %d", name, name.pos)
+			}
+			path = append(path, name.text)
+		}
+		return filterOperand{path: path}, nil
+	default:
+		return filterOperand{}, fmt.Errorf("expected a field or value, got %s at offset %d", t, t.pos)
+	}
+}
@@ -0,0 +1,99 @@
+package runtime
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+func TestEventFilter_Match(t *testing.T) {
+	jobID := "job-7"
+	item := &types.EventEnvelope{
+		EventID: "evt-1",
+		RunID:   "run-123",
+		Seq:     42,
+		Type:    types.EventTypeItem,
+		Attempt: 2,
+		JobID:   &jobID,
+		Payload: map[string]any{
+			"item_type": "product",
+			"data": map[string]any{
+				"price": int8(12),
+				"stock": uint32(0),
+				"url":   "https://shop.example/p/1",
+				"sale":  true,
+			},
+		},
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`type == "item" && payload.item_type == "product"`, true},
+		{`type == 'item' && payload.item_type == 'listing'`, false},
+		{`type == "log" || payload.item_type == "product"`, true},
+		{`!(type == "item")`, false},
+		{`has(payload.data.url)`, true},
+		{`has(payload.data.missing)`, false},
+		{`!has(parent_run_id) && job_id == "job-7"`, true},
+		{`payload.data.price == 12`, true},
+		{`payload.data.price >= 12.0 && payload.data.price < 13`, true},
+		{`payload.data.stock > 0`, false},
+		{`seq <= 42 && attempt != 1`, true},
+		{`payload.data.sale == true`, true},
+		{`payload.data.missing == null`, true},
+		{`payload.data.url =~ "^https://shop\\.example/"`, true},
+		{`payload.data.price =~ "12"`, false}, // =~ needs a string
+		{`payload.data.price == "12"`, false}, // no coercion across types
+		{`payload.data.url > 5`, false},
+		{`payload.item_type.nested == "x"`, false},
+		{`run_id < "run-200"`, true},
+	}
+	for _, tt := range tests {
+		f, err := NewEventFilter(tt.expr)
+		if err != nil {
+			t.Fatalf("NewEventFilter(%q): %v", tt.expr, err)
+		}
+		if got := f.Match(item); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestEventFilter_Empty(t *testing.T) {
+	f, err := NewEventFilter("  ")
+	if f != nil || err != nil {
+		t.Fatalf("NewEventFilter(blank) = %v, %v; want nil, nil", f, err)
+	}
+	if !f.Match(&types.EventEnvelope{Type: types.EventTypeLog}) {
+		t.Error("nil filter must match every event")
+	}
+}
+
+func TestEventFilter_ParseErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{`type == "item`, "unterminated string"},
+		{`type "item"`, "expected a comparison operator"},
+		{`kind == "item"`, `unknown field "kind"`},
+		{`type == "item" &&`, "end of expression"},
+		{`(type == "item"`, `expected ")"`},
+		{`type == "item")`, `unexpected ")"`},
+		{`has("item")`, "has() takes a field path"},
+		{`payload.url =~ 5`, "=~ takes a string pattern"},
+		{`payload.url =~ "("`, "invalid pattern"},
+		{`payload. == 1`, `expected a field name after "."`},
+		{`seq == 1.2.3`, "invalid number"},
+		{`type == "item" # x`, "unexpected character"},
+	}
+	for _, tt := range tests {
+		_, err := NewEventFilter(tt.expr)
+		if err == nil || !strings.Contains(err.Error(), tt.want) || !strings.HasPrefix(err.Error(), "invalid events filter: ") {
+			t.Errorf("NewEventFilter(%q) err = %v, want %q", tt.expr, err, tt.want)
+		}
+	}
+}
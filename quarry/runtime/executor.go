@@ -8,9 +8,11 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"syscall"
 
+	"github.com/pithecene-io/quarry/ipc"
 	"github.com/pithecene-io/quarry/types"
 )
 
@@ -22,6 +24,9 @@ type ExecutorConfig struct {
 	ScriptPath string
 	// Job is the job payload.
 	Job any
+	// SeedState is optional initial script state, sent as seed_state
+	// alongside (not inside) the job payload.
+	SeedState map[string]any
 	// RunMeta is the run metadata.
 	RunMeta *types.RunMeta
 	// Proxy is the optional resolved proxy endpoint per CONTRACT_PROXY.md.
@@ -30,22 +35,36 @@ type ExecutorConfig struct {
 	// BrowserWSEndpoint is the optional WebSocket URL of an externally managed browser.
 	// When set, the executor connects instead of launching a new Chromium instance.
 	BrowserWSEndpoint string
-	// ResolveFrom is the optional path to a node_modules directory used for
+	// ResolveFrom lists optional directories used, in order, for
 	// bare-specifier ESM resolution fallback. When set, the executor registers
 	// a custom resolve hook via module.register().
-	ResolveFrom string
+	ResolveFrom []string
 	// Storage is the optional Hive partition metadata for SDK-side key computation.
 	// When set, the executor passes this to the SDK so storage.put() can return
 	// the resolved storage key without a bidirectional IPC round-trip.
 	Storage *StoragePartition
+	// ExtraArgs are user-supplied executor arguments appended after quarry's
+	// own argv (--executor-arg). Quarry does not interpret them.
+	ExtraArgs []string
+	// MaxFrameBytes, when non-zero, overrides the executor's IPC frame size
+	// limit (QUARRY_MAX_FRAME_BYTES) to match the runtime decoder.
+	MaxFrameBytes int64
+	// StderrMaxBytes bounds the stderr kept in memory; only the tail is
+	// captured (0 = DefaultStderrMaxBytes).
+	StderrMaxBytes int64
 }
 
 // ExecutorResult represents the result of executor execution.
 type ExecutorResult struct {
 	// ExitCode is the process exit code.
 	ExitCode int
-	// StderrBytes is the captured stderr output.
+	// StderrBytes is the captured stderr output: the tail of the stream
+	// when it exceeded ExecutorConfig.StderrMaxBytes.
 	StderrBytes []byte
+	// StderrTotalBytes and StderrTotalLines measure the whole stderr
+	// stream. Zero means StderrBytes is the whole stream.
+	StderrTotalBytes int64
+	StderrTotalLines int64
 }
 
 // ExecutorManager manages executor process lifecycle.
@@ -72,6 +91,7 @@ type StoragePartition struct {
 	Source   string `json:"source"`
 	Category string `json:"category"`
 	Day      string `json:"day"`
+	Hour     string `json:"hour,omitempty"` // set for hourly partitions
 	RunID    string `json:"run_id"`
 }
 
@@ -82,9 +102,30 @@ type executorInput struct {
 	JobID       *string              `json:"job_id,omitempty"`
 	ParentRunID *string              `json:"parent_run_id,omitempty"`
 	Job               any                  `json:"job"`
+	SeedState         map[string]any       `json:"seed_state,omitempty"`
 	Proxy             *types.ProxyEndpoint `json:"proxy,omitempty"`
 	BrowserWSEndpoint string               `json:"browser_ws_endpoint,omitempty"`
 	Storage           *StoragePartition    `json:"storage,omitempty"`
+	IPC               *executorIPC         `json:"ipc,omitempty"`
+}
+
+// executorIPC advertises the runtime's optional IPC capabilities. Executors
+// that do not recognize it ignore it, and the runtime decodes frames from
+// executors that do not use it (CONTRACT_IPC.md, Frame Compression).
+type executorIPC struct {
+	// Compression lists the frame compression codecs the decoder accepts.
+	Compression []string `json:"compression,omitempty"`
+}
+
+// runtimeIPC is the capability set this runtime advertises.
+var runtimeIPC = &executorIPC{Compression: []string{ipc.CompressionZstd}}
+
+// executorArgs builds the executor argv (after the binary): the script path
+// followed by any user-supplied extra args, which always come last.
+func executorArgs(config *ExecutorConfig) []string {
+	args := make([]string, 0, 1+len(config.ExtraArgs))
+	args = append(args, config.ScriptPath)
+	return append(args, config.ExtraArgs...)
 }
 
 // Start starts the executor process.
@@ -92,26 +133,21 @@ type executorInput struct {
 // Stdout is used for IPC frames.
 // Stderr is captured for diagnostics.
 func (m *ExecutorManager) Start(ctx context.Context) error {
-	// Build command: quarry-executor <script-path>
-	m.cmd = exec.CommandContext(ctx, m.config.ExecutorPath, m.config.ScriptPath)
-
-	// Set module resolution env vars when --resolve-from is configured.
-	// QUARRY_RESOLVE_FROM tells the executor's ESM hook where to look.
-	// NODE_PATH provides CJS require() compat (ESM ignores NODE_PATH).
-	if m.config.ResolveFrom != "" {
-		m.cmd.Env = os.Environ()
-		m.cmd.Env = append(m.cmd.Env, "QUARRY_RESOLVE_FROM="+m.config.ResolveFrom)
-
-		// Prepend to NODE_PATH for CJS fallback
-		existing := os.Getenv("NODE_PATH")
-		if existing != "" {
-			m.cmd.Env = append(m.cmd.Env, "NODE_PATH="+m.config.ResolveFrom+string(os.PathListSeparator)+existing)
-		} else {
-			m.cmd.Env = append(m.cmd.Env, "NODE_PATH="+m.config.ResolveFrom)
-		}
+	// Build command: quarry-executor <script-path> [extra-args...]
+	m.cmd = exec.CommandContext(ctx, m.config.ExecutorPath, executorArgs(m.config)...)
+
+	// Set module resolution env vars when --resolve-from is configured
+	if len(m.config.ResolveFrom) > 0 {
+		m.cmd.Env = resolveFromEnv(m.config.ResolveFrom)
+	}
 
-		// Remove duplicate NODE_PATH entries from inherited env
-		m.cmd.Env = deduplicateEnv(m.cmd.Env)
+	// QUARRY_MAX_FRAME_BYTES raises or lowers the executor's encode limit
+	// to match --max-frame-bytes.
+	if m.config.MaxFrameBytes != 0 {
+		if m.cmd.Env == nil {
+			m.cmd.Env = os.Environ()
+		}
+		m.cmd.Env = append(m.cmd.Env, "QUARRY_MAX_FRAME_BYTES="+strconv.FormatInt(m.config.MaxFrameBytes, 10))
 	}
 
 	// Set up pipes
@@ -145,9 +181,11 @@ func (m *ExecutorManager) Start(ctx context.Context) error {
 		JobID:             m.config.RunMeta.JobID,
 		ParentRunID:       m.config.RunMeta.ParentRunID,
 		Job:               m.config.Job,
+		SeedState:         m.config.SeedState,
 		Proxy:             m.config.Proxy,
 		BrowserWSEndpoint: m.config.BrowserWSEndpoint,
 		Storage:           m.config.Storage,
+		IPC:               runtimeIPC,
 	}
 
 	if err := json.NewEncoder(stdin).Encode(input); err != nil {
@@ -184,14 +222,18 @@ func (m *ExecutorManager) Wait() (*ExecutorResult, error) {
 		return nil, errors.New("executor not started")
 	}
 
-	// Read stderr (non-blocking capture)
-	stderrBytes, _ := io.ReadAll(m.stderr)
+	// Read stderr (non-blocking capture), keeping a bounded tail
+	stderr := &tailBuffer{max: int(stderrMaxBytes(m.config.StderrMaxBytes))}
+	_, _ = io.Copy(stderr, m.stderr)
 
 	// Wait for exit
 	err := m.cmd.Wait()
 
+	captured := []byte(stderr.String())
 	result := &ExecutorResult{
-		StderrBytes: stderrBytes,
+		StderrBytes:      captured,
+		StderrTotalBytes: stderr.total,
+		StderrTotalLines: stderr.newlines + countPartialLine(captured),
 	}
 
 	// Determine exit code
@@ -237,21 +279,12 @@ type ScriptValidation struct {
 // ValidateScript spawns the executor in --validate mode and returns the
 // script validation result. This loads the script module and checks its
 // shape without launching a browser or setting up IPC.
-func ValidateScript(ctx context.Context, executorPath, scriptPath, resolveFrom string) (*ScriptValidation, error) {
+func ValidateScript(ctx context.Context, executorPath, scriptPath string, resolveFrom []string) (*ScriptValidation, error) {
 	cmd := exec.CommandContext(ctx, executorPath, "--validate", scriptPath)
 
 	// Set module resolution env vars when --resolve-from is configured
-	if resolveFrom != "" {
-		cmd.Env = os.Environ()
-		cmd.Env = append(cmd.Env, "QUARRY_RESOLVE_FROM="+resolveFrom)
-
-		existing := os.Getenv("NODE_PATH")
-		if existing != "" {
-			cmd.Env = append(cmd.Env, "NODE_PATH="+resolveFrom+string(os.PathListSeparator)+existing)
-		} else {
-			cmd.Env = append(cmd.Env, "NODE_PATH="+resolveFrom)
-		}
-		cmd.Env = deduplicateEnv(cmd.Env)
+	if len(resolveFrom) > 0 {
+		cmd.Env = resolveFromEnv(resolveFrom)
 	}
 
 	stdout, err := cmd.Output()
@@ -279,6 +312,23 @@ func ValidateScript(ctx context.Context, executorPath, scriptPath, resolveFrom s
 	return &result, nil
 }
 
+// resolveFromEnv returns the process environment with module resolution
+// vars for the --resolve-from directories. QUARRY_RESOLVE_FROM tells the
+// executor's ESM hook where to look, in order; NODE_PATH provides CJS
+// require() compat (ESM ignores NODE_PATH). Both are os.PathListSeparator
+// lists, and the directories are prepended to any inherited NODE_PATH.
+func resolveFromEnv(dirs []string) []string {
+	list := strings.Join(dirs, string(os.PathListSeparator))
+	env := append(os.Environ(), "QUARRY_RESOLVE_FROM="+list)
+	if existing := os.Getenv("NODE_PATH"); existing != "" {
+		env = append(env, "NODE_PATH="+list+string(os.PathListSeparator)+existing)
+	} else {
+		env = append(env, "NODE_PATH="+list)
+	}
+	// Remove duplicate NODE_PATH entries from inherited env
+	return deduplicateEnv(env)
+}
+
 // deduplicateEnv keeps the last occurrence of each env var key.
 // This ensures our appended values (NODE_PATH, QUARRY_RESOLVE_FROM) win
 // over inherited duplicates from os.Environ().
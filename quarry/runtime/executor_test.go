@@ -2,7 +2,9 @@ package runtime
 
 import (
 	"encoding/json"
+	"os"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -37,6 +39,39 @@ func TestExecutorInputJSON_IncludesBrowserWSEndpoint(t *testing.T) {
 	}
 }
 
+func TestExecutorInputJSON_AdvertisesCompression(t *testing.T) {
+	data, err := json.Marshal(executorInput{RunID: "run-001", Attempt: 1, Job: map[string]any{}, IPC: runtimeIPC})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(data), `"ipc":{"compression":["zstd"]}`) {
+		t.Errorf("input = %s, want ipc.compression [zstd]", data)
+	}
+}
+
+func TestExecutorInputJSON_SeedStateBesideJob(t *testing.T) {
+	data, err := json.Marshal(executorInput{
+		RunID:     "run-001",
+		Attempt:   1,
+		Job:       map[string]any{"url": "https://example.com"},
+		SeedState: map[string]any{"cursor": "abc"},
+	})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(data), `"job":{"url":"https://example.com"},"seed_state":{"cursor":"abc"}`) {
+		t.Errorf("input = %s, want seed_state as a sibling of job", data)
+	}
+
+	data, err = json.Marshal(executorInput{RunID: "run-001", Attempt: 1, Job: map[string]any{}})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if strings.Contains(string(data), "seed_state") {
+		t.Errorf("input = %s, seed_state should be omitted when unset", data)
+	}
+}
+
 func TestExecutorInputJSON_OmitsBrowserWSEndpointWhenEmpty(t *testing.T) {
 	input := executorInput{
 		RunID:   "run-001",
@@ -132,19 +167,59 @@ func TestDeduplicateEnv_Empty(t *testing.T) {
 }
 
 func TestExecutorConfig_ResolveFromEnvSetup(t *testing.T) {
-	// Verify that the config struct accepts ResolveFrom
+	// Every resolve-from directory reaches the executor env, in order
 	config := &ExecutorConfig{
 		ExecutorPath: "/usr/bin/node",
 		ScriptPath:   "/app/script.ts",
-		ResolveFrom:  "/app/node_modules",
+		ResolveFrom:  []string{"/app/node_modules", "/app/packages"},
 		RunMeta: &types.RunMeta{
 			RunID:   "run-001",
 			Attempt: 1,
 		},
 	}
 
-	if config.ResolveFrom != "/app/node_modules" {
-		t.Errorf("ResolveFrom = %q, want %q", config.ResolveFrom, "/app/node_modules")
+	t.Setenv("NODE_PATH", "/opt/lib")
+	env := resolveFromEnv(config.ResolveFrom)
+	sep := string(os.PathListSeparator)
+	want := map[string]string{
+		"QUARRY_RESOLVE_FROM": "/app/node_modules" + sep + "/app/packages",
+		"NODE_PATH":           "/app/node_modules" + sep + "/app/packages" + sep + "/opt/lib",
+	}
+	for _, entry := range env {
+		key, value, _ := strings.Cut(entry, "=")
+		if w, ok := want[key]; ok {
+			if value != w {
+				t.Errorf("%s = %q, want %q", key, value, w)
+			}
+			delete(want, key)
+		}
+	}
+	for key := range want {
+		t.Errorf("%s missing from env", key)
+	}
+}
+
+func TestExecutorArgs_ExtraArgsLast(t *testing.T) {
+	config := &ExecutorConfig{
+		ExecutorPath: "/usr/bin/quarry-executor",
+		ScriptPath:   "/app/script.ts",
+		ExtraArgs:    []string{"--experimental-foo", "--bar=1"},
+	}
+
+	got := executorArgs(config)
+	want := []string{"/app/script.ts", "--experimental-foo", "--bar=1"}
+	if len(got) != len(want) {
+		t.Fatalf("executorArgs = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("executorArgs[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	config.ExtraArgs = nil
+	if got := executorArgs(config); len(got) != 1 || got[0] != "/app/script.ts" {
+		t.Errorf("executorArgs without extras = %v, want [/app/script.ts]", got)
 	}
 }
 
@@ -192,6 +267,19 @@ func TestExecutorInputJSON_IncludesStoragePartition(t *testing.T) {
 			t.Errorf("storage.%s = %q, want %q", key, got, want)
 		}
 	}
+	if _, exists := storage["hour"]; exists {
+		t.Error("storage.hour should be omitted for daily partitions")
+	}
+}
+
+func TestExecutorInputJSON_StorageHour(t *testing.T) {
+	data, err := json.Marshal(StoragePartition{Dataset: "quarry", Source: "s", Category: "c", Day: "2026-02-23", Hour: "09", RunID: "r"})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if !strings.Contains(string(data), `"day":"2026-02-23","hour":"09"`) {
+		t.Errorf("storage partition JSON = %s, want hour after day", data)
+	}
 }
 
 func TestExecutorInputJSON_OmitsStorageWhenNil(t *testing.T) {
@@ -1,20 +1,76 @@
 package runtime
 
 import (
+	"container/list"
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"math"
+	"slices"
 	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 
+	"github.com/pithecene-io/quarry/metrics"
 	"github.com/pithecene-io/quarry/types"
 )
 
+// Enqueue dedup identities for FanOutConfig.DedupeBy.
+const (
+	// DedupeExact dedupes identical (target, params) pairs. This is the default.
+	DedupeExact = ""
+	// DedupeTarget dedupes by target alone, ignoring params.
+	DedupeTarget = "target"
+	// DedupeParamPrefix selects a single params field as the identity, e.g.
+	// "param:url" dedupes by (target, params["url"]). Enqueues without the
+	// field fall back to exact dedup.
+	DedupeParamPrefix = "param:"
+)
+
+// ParseDedupeBy validates a dedup identity ("", "exact", "target", or
+// "param:<field>") and returns its canonical form.
+func ParseDedupeBy(s string) (string, error) {
+	switch {
+	case s == "" || s == "exact":
+		return DedupeExact, nil
+	case s == DedupeTarget:
+		return DedupeTarget, nil
+	case strings.HasPrefix(s, DedupeParamPrefix) && len(s) > len(DedupeParamPrefix):
+		return s, nil
+	default:
+		return "", fmt.Errorf("invalid dedup identity %q (want exact, target, or param:<field>)", s)
+	}
+}
+
+// Enqueue priorities (CONTRACT_EMIT.md, enqueue). Queued children with a
+// higher priority are dispatched first.
+const (
+	// MinPriority is the lowest enqueue priority.
+	MinPriority = 0
+	// MaxPriority is the highest enqueue priority.
+	MaxPriority = 9
+	// DefaultPriority applies to enqueues without a priority, and to seeds.
+	DefaultPriority = 5
+)
+
+// enqueuePriority reads the optional priority of an enqueue payload, clamped
+// to [MinPriority, MaxPriority]. A missing or non-integer value yields
+// DefaultPriority.
+func enqueuePriority(payload map[string]any) int {
+	n, ok := filterNumber(payload["priority"])
+	if !ok || n != math.Trunc(n) {
+		return DefaultPriority
+	}
+	return int(min(max(n, MinPriority), MaxPriority))
+}
+
 // FanOutConfig configures the fan-out operator.
 type FanOutConfig struct {
 	// MaxDepth is the maximum recursion depth for fan-out (root = depth 0).
@@ -23,6 +79,34 @@ type FanOutConfig struct {
 	MaxRuns int
 	// Parallel is the maximum concurrent child runs.
 	Parallel int
+	// MaxBytesPerChild is the artifact byte budget for each child run (0 = unlimited).
+	MaxBytesPerChild int64
+	// MaxArtifactsPerChild is the artifact count budget for each child run (0 = unlimited).
+	MaxArtifactsPerChild int
+	// DedupeBy selects the enqueue dedup identity (DedupeExact, DedupeTarget,
+	// or DedupeParamPrefix+field). Must be canonical (see ParseDedupeBy).
+	DedupeBy string
+	// DedupeCapacity bounds the dedup set (0 = unbounded). When full, the
+	// oldest key is evicted, so a long-evicted duplicate may run again;
+	// a fresh item is never wrongly skipped.
+	DedupeCapacity int
+	// Collector receives enqueues_deduplicated_total increments (nil = off).
+	Collector *metrics.Collector
+	// RetryPerItem is how many times a child that ends in a retryable
+	// outcome is re-dispatched (0 = no retries). See IsRetryableOutcome.
+	// Retries do not count against MaxRuns.
+	RetryPerItem int
+	// PerOriginConcurrency caps in-flight children per origin (scheme+host+port
+	// of params.url, see ItemOrigin), independently of Parallel (0 = unlimited).
+	// Items over the cap wait without holding a Parallel slot.
+	PerOriginConcurrency int
+	// OriginStagger spaces successive child starts against the same origin
+	// by a random gap in [OriginStagger/2, OriginStagger] (0 = no stagger).
+	OriginStagger time.Duration
+	// AbortOnError stops the fan-out at the first child failure: in-flight
+	// children are canceled and queued children never start. A failure that
+	// will be retried under RetryPerItem does not abort.
+	AbortOnError bool
 }
 
 // FanOutResult aggregates fan-out execution statistics.
@@ -41,6 +125,26 @@ type FanOutResult struct {
 	EnqueueSkipped int64
 	// ChildResults holds the result of each child run, keyed by run_id.
 	ChildResults map[string]*RunResult
+	// BudgetExceeded lists run_ids of children failed by their artifact budget, sorted.
+	BudgetExceeded []string
+	// RunsRetried is the number of retry attempts dispatched (included in RunsTotal).
+	RunsRetried int64
+	// Retried lists run_ids of retry attempts, sorted.
+	Retried []string
+	// OriginMaxInFlight is the max concurrent children observed per origin.
+	// Nil unless PerOriginConcurrency or OriginStagger is set.
+	OriginMaxInFlight map[string]int
+	// Aborted is true if AbortOnError stopped the fan-out.
+	Aborted bool
+	// FirstFailure is the run_id of the child failure that aborted the
+	// fan-out. Empty unless Aborted.
+	FirstFailure string
+	// AbortedPending is the number of queued children that never started
+	// because of the abort.
+	AbortedPending int64
+	// Priorities counts queued children per priority. Nil when every child
+	// ran at DefaultPriority.
+	Priorities map[int]int64
 }
 
 // WorkItem represents a unit of derived work to execute.
@@ -61,6 +165,47 @@ type WorkItem struct {
 	// Category is an optional partition override for the child run's category.
 	// Empty string means inherit from parent.
 	Category string
+	// ArtifactBudget is the per-child artifact budget from FanOutConfig.
+	ArtifactBudget ArtifactBudget
+	// Attempt is the attempt number (1 = first dispatch, >1 = retry).
+	Attempt int
+	// ParentRunID is the run_id of the failed attempt this item retries.
+	// Empty on the first attempt.
+	ParentRunID string
+	// PreviousProxy is the endpoint used by the failed attempt, if any, so the
+	// factory can select a different one. Nil on the first attempt.
+	PreviousProxy *types.ProxyEndpointRedacted
+	// Priority orders dispatch from the queue: higher first, FIFO within a
+	// level. DefaultPriority unless the enqueue set one.
+	Priority int
+
+	// queueSeq is the item's FIFO position in the operator queue (0: never queued).
+	queueSeq uint64
+}
+
+// IsRetryableOutcome reports whether a child outcome may be retried under
+// FanOutConfig.RetryPerItem. Only executor crashes are retried (e.g. a
+// browser killed by a dead proxy); script errors, policy failures, invalid
+// input, and cancellation, interruption or drain are not.
+func IsRetryableOutcome(outcome *types.RunOutcome) bool {
+	if outcome == nil || outcome.Status != types.OutcomeExecutorCrash {
+		return false
+	}
+	switch outcome.Reason {
+	case types.ReasonInvalidInput, types.ReasonCanceled, types.ReasonInterrupted, types.ReasonDrained:
+		return false
+	}
+	return true
+}
+
+// retryItem derives the next attempt of item after it failed with result.
+func retryItem(item WorkItem, result *RunResult) WorkItem {
+	next := item
+	next.RunID = uuid.New().String()
+	next.Attempt = item.Attempt + 1
+	next.ParentRunID = item.RunID
+	next.PreviousProxy = result.ProxyUsed
+	return next
 }
 
 // ChildRunFactory creates and executes a child run, returning the result.
@@ -73,12 +218,15 @@ type Operator struct {
 	config  FanOutConfig
 	factory ChildRunFactory
 
-	queue chan WorkItem
-	seen  map[string]struct{}
-	mu    sync.Mutex
+	queue      *workQueue
+	seen       *dedupSet
+	origins    *originLimiter // nil when no per-origin limits are configured
+	priorities map[int]int64  // queued items per priority, guarded by mu
+	mu         sync.Mutex
 
 	runsStarted  atomic.Int64
 	runsFinished atomic.Int64
+	retried      atomic.Int64
 	succeeded    atomic.Int64
 	failed       atomic.Int64
 	received     atomic.Int64
@@ -87,6 +235,12 @@ type Operator struct {
 
 	resultsMu    sync.Mutex
 	childResults map[string]*RunResult
+	retriedIDs   []string
+
+	// Abort state (FanOutConfig.AbortOnError); cancel is set by Run
+	cancel       context.CancelCauseFunc
+	abortOnce    sync.Once
+	firstFailure string
 }
 
 // NewOperator creates a new fan-out operator.
@@ -94,8 +248,10 @@ func NewOperator(config FanOutConfig, factory ChildRunFactory) *Operator {
 	return &Operator{
 		config:       config,
 		factory:      factory,
-		queue:        make(chan WorkItem, config.MaxRuns),
-		seen:         make(map[string]struct{}),
+		queue:        newWorkQueue(),
+		seen:         newDedupSet(config.DedupeCapacity),
+		origins:      newOriginLimiter(config.PerOriginConcurrency, config.OriginStagger),
+		priorities:   make(map[int]int64),
 		childResults: make(map[string]*RunResult),
 	}
 }
@@ -114,59 +270,92 @@ func (s *Operator) NewObserver(depth int) EnqueueObserver {
 		}
 
 		params, _ := envelope.Payload["params"].(map[string]any)
-		if params == nil {
-			params = map[string]any{}
-		}
-
-		childDepth := depth + 1
-		if childDepth > s.config.MaxDepth {
-			s.skipped.Add(1)
-			return
-		}
+		source, _ := envelope.Payload["source"].(string)
+		category, _ := envelope.Payload["category"].(string)
+		s.submit(target, params, source, category, enqueuePriority(envelope.Payload), depth+1)
+	}
+}
 
-		dedupKey := computeDedupKey(target, params)
+// Seed submits a root-level work item (depth 1) without an enqueue event,
+// for callers that supply the work list up front instead of running a
+// discovery script. Seeds go through the same dedup and max-runs checks
+// as enqueues and are counted as received, and run at DefaultPriority.
+// Seed must be called before Run; it returns false if the item was deduped
+// or skipped.
+func (s *Operator) Seed(target string, params map[string]any) bool {
+	s.received.Add(1)
+	return s.submit(target, params, "", "", DefaultPriority, 1)
+}
 
-		s.mu.Lock()
-		if _, exists := s.seen[dedupKey]; exists {
-			s.mu.Unlock()
-			s.deduped.Add(1)
-			return
-		}
+// submit dedups an item, reserves a max-runs slot, and queues it.
+// Reports whether the item was queued.
+func (s *Operator) submit(target string, params map[string]any, source, category string, priority, childDepth int) bool {
+	if params == nil {
+		params = map[string]any{}
+	}
+	if childDepth > s.config.MaxDepth {
+		s.skipped.Add(1)
+		return false
+	}
 
-		// Check max-runs before committing the slot.
-		// Both dedup and slot reservation are under the same mutex,
-		// so a single check is sufficient.
-		if s.runsStarted.Load() >= int64(s.config.MaxRuns) {
-			s.mu.Unlock()
-			s.skipped.Add(1)
-			return
-		}
+	dedupKey := s.dedupKey(target, params)
 
-		s.seen[dedupKey] = struct{}{}
-		s.runsStarted.Add(1)
+	s.mu.Lock()
+	if s.seen.contains(dedupKey) {
 		s.mu.Unlock()
+		s.deduped.Add(1)
+		s.config.Collector.IncEnqueuesDeduplicated()
+		return false
+	}
 
-		source, _ := envelope.Payload["source"].(string)
-		category, _ := envelope.Payload["category"].(string)
+	// Check max-runs before committing the slot.
+	// Both dedup and slot reservation are under the same mutex,
+	// so a single check is sufficient.
+	if s.runsStarted.Load() >= int64(s.config.MaxRuns) {
+		s.mu.Unlock()
+		s.skipped.Add(1)
+		return false
+	}
 
-		item := WorkItem{
-			Target:   target,
-			Params:   params,
-			Depth:    childDepth,
-			DedupKey: dedupKey,
-			RunID:    uuid.New().String(),
-			Source:   source,
-			Category: category,
-		}
+	s.seen.add(dedupKey)
+	s.runsStarted.Add(1)
+	s.priorities[priority]++
+	s.mu.Unlock()
+
+	item := WorkItem{
+		Target:   target,
+		Params:   params,
+		Depth:    childDepth,
+		DedupKey: dedupKey,
+		RunID:    uuid.New().String(),
+		Source:   source,
+		Category: category,
+		ArtifactBudget: ArtifactBudget{
+			MaxBytes:     s.config.MaxBytesPerChild,
+			MaxArtifacts: s.config.MaxArtifactsPerChild,
+		},
+		Attempt:  1,
+		Priority: priority,
+	}
+	s.queue.push(item)
+	return true
+}
 
-		// Non-blocking send; queue is sized to MaxRuns.
-		select {
-		case s.queue <- item:
-		default:
-			// Queue full — should not happen since queue capacity == MaxRuns
-			s.skipped.Add(1)
-			s.runsStarted.Add(-1)
-		}
+// SeededRootResult builds the root result for a fan-out seeded from an input
+// list (see Operator.Seed): no root script runs, so the root succeeds once
+// the seeds are queued. Children report their own outcomes.
+func SeededRootResult(config *RunConfig, seeded int) *RunResult {
+	config.Collector.IncRunStarted()
+	config.Collector.IncRunCompleted()
+	config.Collector.IncRunReason(string(types.ReasonCompleted))
+	return &RunResult{
+		RunMeta: config.RunMeta,
+		Outcome: &types.RunOutcome{
+			Status:  types.OutcomeSuccess,
+			Reason:  types.ReasonCompleted,
+			Message: fmt.Sprintf("seeded %d work items from input list", seeded),
+		},
+		PolicyStats: config.Policy.Stats(),
 	}
 }
 
@@ -174,6 +363,10 @@ func (s *Operator) NewObserver(depth int) EnqueueObserver {
 // It reads from the work queue and spawns child runs up to the concurrency limit.
 // Terminates when rootDone is closed AND the queue is drained AND all workers are idle.
 func (s *Operator) Run(ctx context.Context, rootDone <-chan struct{}) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	s.cancel = cancel
+
 	sem := make(chan struct{}, s.config.Parallel)
 	var wg sync.WaitGroup
 
@@ -193,47 +386,58 @@ func (s *Operator) Run(ctx context.Context, rootDone <-chan struct{}) {
 				default:
 				}
 			}()
-
-			childObserver := s.NewObserver(wi.Depth)
-			result, err := s.factory(ctx, wi, childObserver)
-			s.runsFinished.Add(1)
-
-			s.resultsMu.Lock()
-			if err != nil || result == nil {
-				s.failed.Add(1)
-				if result != nil {
-					s.childResults[wi.RunID] = result
-				}
-			} else {
-				s.childResults[wi.RunID] = result
-				if result.Outcome.Status == types.OutcomeSuccess {
-					s.succeeded.Add(1)
-				} else {
-					s.failed.Add(1)
+			// Runs before the slot is released, so a parked item is back in
+			// the queue before the main loop can observe an idle pool.
+			defer s.releaseOrigin(wi)
+
+			// Retries run inline on the same worker slot, so they neither
+			// consume queue capacity nor race operator termination.
+			for {
+				s.origins.wait(ctx, wi)
+				childObserver := s.NewObserver(wi.Depth)
+				result, err := s.factory(ctx, wi, childObserver)
+				s.recordResult(wi, result, err)
+
+				if err != nil || result == nil || ctx.Err() != nil ||
+					wi.Attempt > s.config.RetryPerItem || !IsRetryableOutcome(result.Outcome) {
+					// A child stopped by an earlier cancel is not a failure of its own
+					if s.config.AbortOnError && ctx.Err() == nil && childFailed(result, err) {
+						s.abort(wi.RunID)
+					}
+					return
 				}
+				wi = retryItem(wi, result)
+				s.retried.Add(1)
 			}
-			s.resultsMu.Unlock()
 		}(item)
 	}
 
 	rootFinished := false
 	for {
-		// Try to drain the queue non-blocking first.
-		drained := false
-		for !drained {
-			select {
-			case item := <-s.queue:
-				// Acquire semaphore (bounded concurrency).
-				select {
-				case sem <- struct{}{}:
-				case <-ctx.Done():
-					wg.Wait()
-					return
-				}
-				dispatch(item)
-			default:
-				drained = true
+		if ctx.Err() != nil {
+			wg.Wait()
+			return
+		}
+
+		// Dispatch queued items, highest priority first. The worker slot is
+		// taken before popping, so items queued while every worker is busy
+		// still compete on priority for the next free slot.
+		for s.queue.len() > 0 {
+			if !s.acquireSlot(ctx, sem) {
+				wg.Wait()
+				return
+			}
+			item, ok := s.queue.pop()
+			if !ok {
+				<-sem
+				break
+			}
+			// Items over their origin cap are parked until a slot frees.
+			if !s.origins.acquire(item) {
+				<-sem
+				continue
 			}
+			dispatch(item)
 		}
 
 		// Queue is empty. Check termination conditions.
@@ -248,7 +452,7 @@ func (s *Operator) Run(ctx context.Context, rootDone <-chan struct{}) {
 		if rootFinished {
 			// Root is done. Wait for all workers, then check if they enqueued more.
 			wg.Wait()
-			if len(s.queue) == 0 {
+			if s.queue.len() == 0 {
 				return
 			}
 			// Workers enqueued more items — continue draining.
@@ -257,14 +461,8 @@ func (s *Operator) Run(ctx context.Context, rootDone <-chan struct{}) {
 
 		// Root still running — block until new work, root completion, worker completion, or cancel.
 		select {
-		case item := <-s.queue:
-			select {
-			case sem <- struct{}{}:
-			case <-ctx.Done():
-				wg.Wait()
-				return
-			}
-			dispatch(item)
+		case <-s.queue.signal():
+			// New work — dispatched at the top of the loop.
 		case <-rootDone:
 			rootFinished = true
 		case <-workerDone:
@@ -276,6 +474,80 @@ func (s *Operator) Run(ctx context.Context, rootDone <-chan struct{}) {
 	}
 }
 
+// acquireSlot takes a worker slot, reporting false if ctx is done first.
+// Slots are taken before an item leaves the queue, so after an abort every
+// item not yet started still counts as pending.
+func (s *Operator) acquireSlot(ctx context.Context, sem chan struct{}) bool {
+	select {
+	case sem <- struct{}{}:
+		if ctx.Err() == nil {
+			return true
+		}
+		<-sem
+	case <-ctx.Done():
+	}
+	return false
+}
+
+// childFailed reports whether a child attempt ended in failure.
+func childFailed(result *RunResult, err error) bool {
+	return err != nil || result == nil || result.Outcome == nil || result.Outcome.Status != types.OutcomeSuccess
+}
+
+// abort cancels the fan-out for the first failed child (AbortOnError).
+// In-flight children see a CancelCause naming it; later calls are no-ops.
+func (s *Operator) abort(runID string) {
+	s.abortOnce.Do(func() {
+		s.resultsMu.Lock()
+		s.firstFailure = runID
+		s.resultsMu.Unlock()
+		s.cancel(&CancelCause{
+			Reason:  types.ReasonCanceled,
+			Message: fmt.Sprintf("fan-out aborted: child %s failed", runID),
+		})
+	})
+}
+
+// FirstFailure returns the run_id of the child failure that aborted the
+// fan-out (FanOutConfig.AbortOnError), or "" if it was not aborted.
+func (s *Operator) FirstFailure() string {
+	s.resultsMu.Lock()
+	defer s.resultsMu.Unlock()
+	return s.firstFailure
+}
+
+// releaseOrigin frees wi's origin slot and requeues the next item parked on
+// that origin, at its original queue position.
+func (s *Operator) releaseOrigin(wi WorkItem) {
+	if next, ok := s.origins.release(wi); ok {
+		s.queue.push(next)
+	}
+}
+
+// recordResult records the result of one child attempt.
+func (s *Operator) recordResult(wi WorkItem, result *RunResult, err error) {
+	s.runsFinished.Add(1)
+
+	s.resultsMu.Lock()
+	defer s.resultsMu.Unlock()
+	if wi.Attempt > 1 {
+		s.retriedIDs = append(s.retriedIDs, wi.RunID)
+	}
+	if err != nil || result == nil {
+		s.failed.Add(1)
+		if result != nil {
+			s.childResults[wi.RunID] = result
+		}
+		return
+	}
+	s.childResults[wi.RunID] = result
+	if result.Outcome.Status == types.OutcomeSuccess {
+		s.succeeded.Add(1)
+	} else {
+		s.failed.Add(1)
+	}
+}
+
 // Results returns the aggregate fan-out statistics.
 func (s *Operator) Results() FanOutResult {
 	s.resultsMu.Lock()
@@ -283,8 +555,20 @@ func (s *Operator) Results() FanOutResult {
 
 	// Copy the map to avoid external mutation
 	results := make(map[string]*RunResult, len(s.childResults))
+	var budgetExceeded []string
 	for k, v := range s.childResults {
 		results[k] = v
+		if v.BudgetExceeded {
+			budgetExceeded = append(budgetExceeded, k)
+		}
+	}
+	sort.Strings(budgetExceeded)
+	retried := append([]string(nil), s.retriedIDs...)
+	sort.Strings(retried)
+	// After an abort nothing drains the queue, so what is left never started
+	var pending int64
+	if s.firstFailure != "" {
+		pending = int64(s.queue.len())
 	}
 
 	return FanOutResult{
@@ -295,7 +579,29 @@ func (s *Operator) Results() FanOutResult {
 		EnqueueDeduped:  s.deduped.Load(),
 		EnqueueSkipped:  s.skipped.Load(),
 		ChildResults:    results,
+		BudgetExceeded:  budgetExceeded,
+		RunsRetried:     s.retried.Load(),
+		Retried:         retried,
+
+		OriginMaxInFlight: s.origins.observed(),
+
+		Aborted:        s.firstFailure != "",
+		FirstFailure:   s.firstFailure,
+		AbortedPending: pending,
+
+		Priorities: s.priorityCounts(),
+	}
+}
+
+// priorityCounts copies the per-priority queued counts, or returns nil when
+// every item was queued at DefaultPriority.
+func (s *Operator) priorityCounts() map[int]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.priorities) == 0 || (len(s.priorities) == 1 && s.priorities[DefaultPriority] > 0) {
+		return nil
 	}
+	return maps.Clone(s.priorities)
 }
 
 // computeDedupKey produces a deterministic key from target + params.
@@ -316,6 +622,65 @@ func computeDedupKey(target string, params map[string]any) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
+// dedupKey computes the dedup key for an enqueue per config.DedupeBy.
+func (s *Operator) dedupKey(target string, params map[string]any) string {
+	switch {
+	case s.config.DedupeBy == DedupeTarget:
+		return computeDedupKey(target, nil)
+	case strings.HasPrefix(s.config.DedupeBy, DedupeParamPrefix):
+		field := strings.TrimPrefix(s.config.DedupeBy, DedupeParamPrefix)
+		if v, ok := params[field]; ok {
+			return computeDedupKey(target, map[string]any{field: v})
+		}
+	}
+	return computeDedupKey(target, params)
+}
+
+// dedupSet is a set of dedup keys, optionally bounded with FIFO eviction.
+// Not safe for concurrent use; the Operator guards it with its mutex.
+type dedupSet struct {
+	capacity int // 0 = unbounded
+	keys     map[string]*list.Element
+	order    *list.List // insertion order, oldest at front
+}
+
+func newDedupSet(capacity int) *dedupSet {
+	return &dedupSet{
+		capacity: capacity,
+		keys:     make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func (d *dedupSet) contains(key string) bool {
+	_, ok := d.keys[key]
+	return ok
+}
+
+// add inserts key, evicting the oldest key when the set is at capacity.
+func (d *dedupSet) add(key string) {
+	if d.contains(key) {
+		return
+	}
+	if d.capacity > 0 && len(d.keys) >= d.capacity {
+		oldest := d.order.Front()
+		d.order.Remove(oldest)
+		delete(d.keys, oldest.Value.(string))
+	}
+	d.keys[key] = d.order.PushBack(key)
+}
+
+// formatPriorities renders per-priority counts highest first, e.g. "9=3, 5=10".
+func formatPriorities(counts map[int]int64) string {
+	levels := slices.Sorted(maps.Keys(counts))
+	slices.Reverse(levels)
+	parts := make([]string, len(levels))
+	for i, p := range levels {
+		parts[i] = fmt.Sprintf("%d=%d", p, counts[p])
+	}
+	return strings.Join(parts, ", ")
+}
+
 // PrintFanOutSummary prints a human-readable fan-out summary to stdout.
 func PrintFanOutSummary(result FanOutResult) {
 	fmt.Printf("\n=== Fan-Out Summary ===\n")
@@ -323,6 +688,36 @@ func PrintFanOutSummary(result FanOutResult) {
 		result.RunsTotal, result.RunsSucceeded, result.RunsFailed)
 	fmt.Printf("Enqueue Events:   %d received, %d deduped, %d skipped\n",
 		result.EnqueueReceived, result.EnqueueDeduped, result.EnqueueSkipped)
+	if len(result.BudgetExceeded) > 0 {
+		fmt.Printf("Budget Exceeded:  %d child runs\n", len(result.BudgetExceeded))
+		for _, runID := range result.BudgetExceeded {
+			fmt.Printf("  %s\n", runID)
+		}
+	}
+	if result.RunsRetried > 0 {
+		fmt.Printf("Retries:          %d retry attempts\n", result.RunsRetried)
+	}
+	if result.Aborted {
+		fmt.Printf("Aborted:          first failure %s", result.FirstFailure)
+		if res := result.ChildResults[result.FirstFailure]; res != nil && res.Outcome != nil {
+			fmt.Printf(" (outcome=%s: %s)", res.Outcome.Status, res.Outcome.Message)
+		}
+		fmt.Printf(", %d pending child runs not started\n", result.AbortedPending)
+	}
+	if len(result.Priorities) > 0 {
+		fmt.Printf("Priorities:       %s (child runs per priority)\n", formatPriorities(result.Priorities))
+	}
+	if len(result.OriginMaxInFlight) > 0 {
+		fmt.Printf("Origins:          %d (max in-flight per origin)\n", len(result.OriginMaxInFlight))
+		origins := make([]string, 0, len(result.OriginMaxInFlight))
+		for origin := range result.OriginMaxInFlight {
+			origins = append(origins, origin)
+		}
+		sort.Strings(origins)
+		for _, origin := range origins {
+			fmt.Printf("  %s: %d\n", origin, result.OriginMaxInFlight[origin])
+		}
+	}
 
 	if len(result.ChildResults) > 0 {
 		fmt.Printf("\n--- Child Run Results ---\n")
@@ -333,8 +728,12 @@ func PrintFanOutSummary(result FanOutResult) {
 		sort.Strings(runIDs)
 		for _, runID := range runIDs {
 			res := result.ChildResults[runID]
-			fmt.Printf("  %s: outcome=%s, events=%d, duration=%s\n",
-				runID, res.Outcome.Status, res.EventCount, res.Duration)
+			retry := ""
+			if res.RunMeta != nil && res.RunMeta.Attempt > 1 && res.RunMeta.ParentRunID != nil {
+				retry = fmt.Sprintf(", attempt=%d, retry_of=%s", res.RunMeta.Attempt, *res.RunMeta.ParentRunID)
+			}
+			fmt.Printf("  %s: outcome=%s, events=%d, duration=%s%s\n",
+				runID, res.Outcome.Status, res.EventCount, res.Duration, retry)
 		}
 	}
 }
@@ -0,0 +1,158 @@
+package runtime
+
+import (
+	"context"
+	"math/rand/v2"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OriginParam is the enqueue params field whose URL identifies a child's
+// origin for FanOutConfig.PerOriginConcurrency and OriginStagger.
+const OriginParam = "url"
+
+// ItemOrigin returns the origin (scheme://host:port) of item's params.url,
+// with the default port filled in for http and https. Returns "" if the
+// field is missing or not an absolute URL; such items are not origin-limited.
+func ItemOrigin(item WorkItem) string {
+	raw, _ := item.Params[OriginParam].(string)
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme == "" || u.Hostname() == "" {
+		return ""
+	}
+	scheme := strings.ToLower(u.Scheme)
+	port := u.Port()
+	if port == "" {
+		switch scheme {
+		case "http":
+			port = "80"
+		case "https":
+			port = "443"
+		}
+	}
+	host := strings.ToLower(u.Hostname())
+	if port == "" {
+		return scheme + "://" + host
+	}
+	return scheme + "://" + net.JoinHostPort(host, port)
+}
+
+// originLimiter enforces per-origin concurrency and start stagger for the
+// fan-out operator. Items that cannot start are parked per origin and
+// handed back on release. A nil limiter admits everything.
+type originLimiter struct {
+	limit   int           // max in-flight per origin (0 = unlimited)
+	stagger time.Duration // max randomized gap between starts (0 = none)
+	jitter  func(time.Duration) time.Duration
+
+	mu          sync.Mutex
+	inFlight    map[string]int
+	maxInFlight map[string]int
+	parked      map[string][]WorkItem
+	nextStart   map[string]time.Time
+}
+
+// newOriginLimiter returns nil when neither limit nor stagger is set.
+func newOriginLimiter(limit int, stagger time.Duration) *originLimiter {
+	if limit <= 0 && stagger <= 0 {
+		return nil
+	}
+	return &originLimiter{
+		limit:       limit,
+		stagger:     stagger,
+		jitter:      staggerJitter,
+		inFlight:    make(map[string]int),
+		maxInFlight: make(map[string]int),
+		parked:      make(map[string][]WorkItem),
+		nextStart:   make(map[string]time.Time),
+	}
+}
+
+// staggerJitter returns a random gap in [stagger/2, stagger].
+func staggerJitter(stagger time.Duration) time.Duration {
+	half := stagger / 2
+	return half + rand.N(stagger-half+1)
+}
+
+// acquire takes an origin slot for item. Returns false if the origin is at
+// its limit; the item is then parked until a slot is released.
+func (l *originLimiter) acquire(item WorkItem) bool {
+	origin := ItemOrigin(item)
+	if l == nil || origin == "" {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limit > 0 && l.inFlight[origin] >= l.limit {
+		l.parked[origin] = append(l.parked[origin], item)
+		return false
+	}
+	l.inFlight[origin]++
+	l.maxInFlight[origin] = max(l.maxInFlight[origin], l.inFlight[origin])
+	return true
+}
+
+// release frees item's origin slot and returns the oldest parked item for
+// that origin, if any, for the caller to requeue.
+func (l *originLimiter) release(item WorkItem) (WorkItem, bool) {
+	origin := ItemOrigin(item)
+	if l == nil || origin == "" {
+		return WorkItem{}, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight[origin]--
+	queue := l.parked[origin]
+	if len(queue) == 0 {
+		return WorkItem{}, false
+	}
+	next := queue[0]
+	l.parked[origin] = queue[1:]
+	return next, true
+}
+
+// wait blocks until item's origin may start another child, spacing starts
+// against the same origin by a randomized gap of up to the stagger.
+// Returns early if ctx is canceled.
+func (l *originLimiter) wait(ctx context.Context, item WorkItem) {
+	origin := ItemOrigin(item)
+	if l == nil || l.stagger <= 0 || origin == "" {
+		return
+	}
+	l.mu.Lock()
+	now := time.Now()
+	start := l.nextStart[origin]
+	if start.Before(now) {
+		start = now
+	}
+	l.nextStart[origin] = start.Add(l.jitter(l.stagger))
+	l.mu.Unlock()
+
+	delay := time.Until(start)
+	if delay <= 0 {
+		return
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// observed returns a copy of the max in-flight children seen per origin.
+func (l *originLimiter) observed() map[string]int {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	out := make(map[string]int, len(l.maxInFlight))
+	for origin, n := range l.maxInFlight {
+		out[origin] = n
+	}
+	return out
+}
@@ -0,0 +1,88 @@
+package runtime
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// workQueue is the fan-out operator's pending work: a priority queue that
+// pops the highest WorkItem.Priority first and, within a priority level,
+// the item queued first. Safe for concurrent use.
+//
+// It is unbounded; the operator bounds it by reserving a max-runs slot
+// before every push.
+type workQueue struct {
+	mu      sync.Mutex
+	items   workHeap
+	nextSeq uint64
+	ready   chan struct{} // signaled (non-blocking) on every push
+}
+
+func newWorkQueue() *workQueue {
+	return &workQueue{ready: make(chan struct{}, 1)}
+}
+
+// push queues item. An item that was queued before (e.g. taken out and put
+// back) keeps its original FIFO position within its priority level.
+func (q *workQueue) push(item WorkItem) {
+	q.mu.Lock()
+	if item.queueSeq == 0 {
+		q.nextSeq++
+		item.queueSeq = q.nextSeq
+	}
+	heap.Push(&q.items, item)
+	q.mu.Unlock()
+
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// pop removes and returns the next item, or false if the queue is empty.
+func (q *workQueue) pop() (WorkItem, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 {
+		return WorkItem{}, false
+	}
+	return heap.Pop(&q.items).(WorkItem), true
+}
+
+// len returns the number of queued items.
+func (q *workQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// signal returns a channel that receives after a push. A receive only means
+// the queue may be non-empty; callers re-check with pop.
+func (q *workQueue) signal() <-chan struct{} {
+	return q.ready
+}
+
+// workHeap implements heap.Interface ordered by (Priority desc, queueSeq asc).
+type workHeap []WorkItem
+
+func (h workHeap) Len() int { return len(h) }
+
+func (h workHeap) Less(i, j int) bool {
+	if h[i].Priority != h[j].Priority {
+		return h[i].Priority > h[j].Priority
+	}
+	return h[i].queueSeq < h[j].queueSeq
+}
+
+func (h workHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *workHeap) Push(x any) { *h = append(*h, x.(WorkItem)) }
+
+func (h *workHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = WorkItem{}
+	*h = old[:n-1]
+	return item
+}
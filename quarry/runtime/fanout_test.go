@@ -2,10 +2,14 @@ package runtime
 
 import (
 	"context"
+	"fmt"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/pithecene-io/quarry/metrics"
+	"github.com/pithecene-io/quarry/policy"
 	"github.com/pithecene-io/quarry/types"
 )
 
@@ -403,6 +407,66 @@ func TestOperator_NoEnqueueEvents(t *testing.T) {
 	}
 }
 
+func TestOperator_Seed(t *testing.T) {
+	var mu sync.Mutex
+	var items []WorkItem
+	operator := NewOperator(FanOutConfig{
+		MaxDepth: 1,
+		MaxRuns:  2,
+		Parallel: 1,
+	}, func(ctx context.Context, item WorkItem, observer EnqueueObserver) (*RunResult, error) {
+		mu.Lock()
+		items = append(items, item)
+		mu.Unlock()
+		return &RunResult{
+			RunMeta: &types.RunMeta{RunID: item.RunID, Attempt: 1},
+			Outcome: &types.RunOutcome{Status: types.OutcomeSuccess},
+		}, nil
+	})
+
+	queued := []bool{
+		operator.Seed("script.ts", map[string]any{"url": "https://a.example"}),
+		operator.Seed("script.ts", map[string]any{"url": "https://a.example"}), // duplicate
+		operator.Seed("script.ts", map[string]any{"url": "https://b.example"}),
+		operator.Seed("script.ts", map[string]any{"url": "https://c.example"}), // over max-runs
+	}
+	if want := []bool{true, false, true, false}; fmt.Sprint(queued) != fmt.Sprint(want) {
+		t.Errorf("Seed results = %v, want %v", queued, want)
+	}
+
+	rootDone := make(chan struct{})
+	close(rootDone)
+	operator.Run(t.Context(), rootDone)
+
+	result := operator.Results()
+	if result.RunsTotal != 2 || result.EnqueueReceived != 4 || result.EnqueueDeduped != 1 || result.EnqueueSkipped != 1 {
+		t.Errorf("result = total %d received %d deduped %d skipped %d, want 2/4/1/1",
+			result.RunsTotal, result.EnqueueReceived, result.EnqueueDeduped, result.EnqueueSkipped)
+	}
+	for _, item := range items {
+		if item.Depth != 1 || item.Target != "script.ts" {
+			t.Errorf("seeded item depth %d target %q, want depth 1 target script.ts", item.Depth, item.Target)
+		}
+	}
+}
+
+func TestSeededRootResult(t *testing.T) {
+	collector := metrics.NewCollector("strict", "node", "fs", "run-1", "")
+	config := &RunConfig{
+		RunMeta:   &types.RunMeta{RunID: "run-1", Attempt: 1},
+		Policy:    policy.NewNoopPolicy(),
+		Collector: collector,
+	}
+
+	result := SeededRootResult(config, 3)
+	if result.Outcome.Status != types.OutcomeSuccess || result.Outcome.Message != "seeded 3 work items from input list" {
+		t.Errorf("outcome = %s %q", result.Outcome.Status, result.Outcome.Message)
+	}
+	if snap := collector.Snapshot(); snap.RunsStarted != 1 || snap.RunsCompleted != 1 {
+		t.Errorf("metrics = started %d completed %d, want 1/1", snap.RunsStarted, snap.RunsCompleted)
+	}
+}
+
 func TestOperator_EnqueueSourceCategoryOverride(t *testing.T) {
 	var capturedItems []WorkItem
 
@@ -502,3 +566,553 @@ func TestOperator_FailedChildRun(t *testing.T) {
 		t.Errorf("expected 0 succeeded, got %d", result.RunsSucceeded)
 	}
 }
+
+func TestOperator_AbortOnError(t *testing.T) {
+	slowStarted := make(chan struct{})
+	var mu sync.Mutex
+	runIDs := make(map[string]string) // target -> run_id
+	factory := func(ctx context.Context, item WorkItem, observer EnqueueObserver) (*RunResult, error) {
+		mu.Lock()
+		runIDs[item.Target] = item.RunID
+		mu.Unlock()
+
+		meta := &types.RunMeta{RunID: item.RunID, Attempt: item.Attempt}
+		switch item.Target {
+		case "fail.ts":
+			<-slowStarted
+			return &RunResult{RunMeta: meta, Outcome: &types.RunOutcome{Status: types.OutcomeScriptError, Message: "boom"}}, nil
+		case "slow.ts":
+			close(slowStarted)
+			<-ctx.Done()
+			return &RunResult{RunMeta: meta, Outcome: &types.RunOutcome{
+				Status:  types.OutcomeExecutorCrash,
+				Reason:  ReasonFromIngestionError(context.Cause(ctx)),
+				Message: context.Cause(ctx).Error(),
+			}}, nil
+		default:
+			t.Errorf("child %s started after the abort", item.Target)
+			return &RunResult{RunMeta: meta, Outcome: &types.RunOutcome{Status: types.OutcomeSuccess}}, nil
+		}
+	}
+
+	operator := NewOperator(FanOutConfig{
+		MaxDepth:     1,
+		MaxRuns:      5,
+		Parallel:     2,
+		AbortOnError: true,
+	}, factory)
+	for _, target := range []string{"fail.ts", "slow.ts", "a.ts", "b.ts", "c.ts"} {
+		operator.Seed(target, nil)
+	}
+
+	rootDone := make(chan struct{})
+	close(rootDone)
+	operator.Run(t.Context(), rootDone)
+
+	result := operator.Results()
+	if !result.Aborted || result.FirstFailure != runIDs["fail.ts"] {
+		t.Fatalf("Aborted = %v, FirstFailure = %q; want true, %q", result.Aborted, result.FirstFailure, runIDs["fail.ts"])
+	}
+	if operator.FirstFailure() != result.FirstFailure {
+		t.Errorf("FirstFailure() = %q, want %q", operator.FirstFailure(), result.FirstFailure)
+	}
+	if result.RunsTotal != 2 || result.RunsFailed != 2 || result.AbortedPending != 3 {
+		t.Errorf("total=%d failed=%d pending=%d, want 2, 2, 3", result.RunsTotal, result.RunsFailed, result.AbortedPending)
+	}
+	slow := result.ChildResults[runIDs["slow.ts"]]
+	if slow == nil || slow.Outcome.Reason != types.ReasonCanceled || slow.Outcome.Message != "fan-out aborted: child "+runIDs["fail.ts"]+" failed" {
+		t.Errorf("in-flight child outcome = %+v, want canceled by the abort", slow)
+	}
+}
+
+func TestOperator_ContinueOnError(t *testing.T) {
+	factory := func(ctx context.Context, item WorkItem, observer EnqueueObserver) (*RunResult, error) {
+		return &RunResult{Outcome: &types.RunOutcome{Status: types.OutcomeScriptError}}, nil
+	}
+	operator := NewOperator(FanOutConfig{MaxDepth: 1, MaxRuns: 3, Parallel: 1}, factory)
+	for _, target := range []string{"a.ts", "b.ts", "c.ts"} {
+		operator.Seed(target, nil)
+	}
+
+	rootDone := make(chan struct{})
+	close(rootDone)
+	operator.Run(t.Context(), rootDone)
+
+	result := operator.Results()
+	if result.Aborted || result.RunsTotal != 3 {
+		t.Errorf("Aborted = %v, total = %d; want every child to run", result.Aborted, result.RunsTotal)
+	}
+}
+
+func TestOperator_RetryPerItem(t *testing.T) {
+	var mu sync.Mutex
+	var attempts []WorkItem
+	// Crashing targets crash on every attempt; each attempt reports its proxy.
+	factory := func(ctx context.Context, item WorkItem, observer EnqueueObserver) (*RunResult, error) {
+		mu.Lock()
+		attempts = append(attempts, item)
+		n := len(attempts)
+		mu.Unlock()
+
+		status := types.OutcomeExecutorCrash
+		if item.Target == "script-error.ts" {
+			status = types.OutcomeScriptError
+		}
+		meta := &types.RunMeta{RunID: item.RunID, Attempt: item.Attempt}
+		if item.ParentRunID != "" {
+			meta.ParentRunID = &item.ParentRunID
+		}
+		return &RunResult{
+			RunMeta:   meta,
+			Outcome:   &types.RunOutcome{Status: status, Message: "failed"},
+			ProxyUsed: &types.ProxyEndpointRedacted{Host: fmt.Sprintf("proxy-%d", n), Port: 8080},
+		}, nil
+	}
+
+	operator := NewOperator(FanOutConfig{
+		MaxDepth:     1,
+		MaxRuns:      5,
+		Parallel:     1,
+		RetryPerItem: 2,
+	}, factory)
+
+	observer := operator.NewObserver(0)
+	for _, target := range []string{"crash.ts", "script-error.ts"} {
+		observer(&types.EventEnvelope{
+			Type:    types.EventTypeEnqueue,
+			Payload: map[string]any{"target": target, "params": map[string]any{}},
+		})
+	}
+
+	rootDone := make(chan struct{})
+	close(rootDone)
+	operator.Run(t.Context(), rootDone)
+
+	result := operator.Results()
+	// crash.ts: 1 + 2 retries; script-error.ts: not retried
+	if result.RunsTotal != 4 || result.RunsFailed != 4 {
+		t.Errorf("expected 4 failed runs, got total=%d failed=%d", result.RunsTotal, result.RunsFailed)
+	}
+	if result.RunsRetried != 2 || len(result.Retried) != 2 {
+		t.Errorf("expected 2 retries, got %d (%v)", result.RunsRetried, result.Retried)
+	}
+
+	var crashes []WorkItem
+	for _, item := range attempts {
+		if item.Target == "crash.ts" {
+			crashes = append(crashes, item)
+		}
+	}
+	if len(crashes) != 3 {
+		t.Fatalf("expected 3 crash.ts attempts, got %d", len(crashes))
+	}
+	for i := 1; i < len(crashes); i++ {
+		prev, cur := crashes[i-1], crashes[i]
+		if cur.Attempt != i+1 || cur.ParentRunID != prev.RunID || cur.RunID == prev.RunID {
+			t.Errorf("attempt %d lineage wrong: %+v (previous run %s)", i+1, cur, prev.RunID)
+		}
+		if cur.PreviousProxy == nil || cur.PreviousProxy != result.ChildResults[prev.RunID].ProxyUsed {
+			t.Errorf("attempt %d should carry the failed attempt's proxy, got %+v", i+1, cur.PreviousProxy)
+		}
+	}
+	if crashes[0].Attempt != 1 || crashes[0].PreviousProxy != nil {
+		t.Errorf("first attempt should be attempt 1 without a previous proxy: %+v", crashes[0])
+	}
+}
+
+func TestIsRetryableOutcome(t *testing.T) {
+	tests := []struct {
+		outcome *types.RunOutcome
+		want    bool
+	}{
+		{&types.RunOutcome{Status: types.OutcomeExecutorCrash, Reason: types.ReasonExecutorCrash}, true},
+		{&types.RunOutcome{Status: types.OutcomeExecutorCrash, Reason: types.ReasonTimeout}, true},
+		{&types.RunOutcome{Status: types.OutcomeExecutorCrash, Reason: types.ReasonInvalidInput}, false},
+		{&types.RunOutcome{Status: types.OutcomeExecutorCrash, Reason: types.ReasonCanceled}, false},
+		{&types.RunOutcome{Status: types.OutcomeExecutorCrash, Reason: types.ReasonDrained}, false},
+		{&types.RunOutcome{Status: types.OutcomeExecutorCrash, Reason: types.ReasonInterrupted}, false},
+		{&types.RunOutcome{Status: types.OutcomeScriptError, Reason: types.ReasonScriptError}, false},
+		{&types.RunOutcome{Status: types.OutcomePolicyFailure}, false},
+		{&types.RunOutcome{Status: types.OutcomeSuccess}, false},
+		{nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsRetryableOutcome(tt.outcome); got != tt.want {
+			t.Errorf("IsRetryableOutcome(%+v) = %v, want %v", tt.outcome, got, tt.want)
+		}
+	}
+}
+
+func TestOperator_ArtifactBudgetPropagatesAndReports(t *testing.T) {
+	var mu sync.Mutex
+	budgets := map[string]ArtifactBudget{}
+
+	// The "big" child reports a blown budget; the other succeeds
+	factory := func(ctx context.Context, item WorkItem, observer EnqueueObserver) (*RunResult, error) {
+		mu.Lock()
+		budgets[item.Target] = item.ArtifactBudget
+		mu.Unlock()
+
+		result := &RunResult{
+			RunMeta: &types.RunMeta{RunID: item.RunID, Attempt: 1},
+			Outcome: &types.RunOutcome{Status: types.OutcomeSuccess, Message: "ok"},
+		}
+		if item.Target == "big.ts" {
+			result.Outcome = &types.RunOutcome{Status: types.OutcomePolicyFailure, Message: "artifact budget exceeded"}
+			result.BudgetExceeded = true
+		}
+		return result, nil
+	}
+
+	operator := NewOperator(FanOutConfig{
+		MaxDepth:             1,
+		MaxRuns:              5,
+		Parallel:             2,
+		MaxBytesPerChild:     1024,
+		MaxArtifactsPerChild: 3,
+	}, factory)
+
+	observer := operator.NewObserver(0)
+	for _, target := range []string{"big.ts", "small.ts"} {
+		observer(&types.EventEnvelope{
+			Type:    types.EventTypeEnqueue,
+			Payload: map[string]any{"target": target, "params": map[string]any{}},
+		})
+	}
+
+	rootDone := make(chan struct{})
+	close(rootDone)
+	operator.Run(t.Context(), rootDone)
+
+	want := ArtifactBudget{MaxBytes: 1024, MaxArtifacts: 3}
+	for target, got := range budgets {
+		if got != want {
+			t.Errorf("%s: ArtifactBudget = %+v, want %+v", target, got, want)
+		}
+	}
+
+	result := operator.Results()
+	if result.RunsFailed != 1 || result.RunsSucceeded != 1 {
+		t.Errorf("expected 1 failed and 1 succeeded, got failed=%d succeeded=%d", result.RunsFailed, result.RunsSucceeded)
+	}
+	if len(result.BudgetExceeded) != 1 {
+		t.Fatalf("BudgetExceeded = %v, want 1 entry", result.BudgetExceeded)
+	}
+	if res := result.ChildResults[result.BudgetExceeded[0]]; res == nil || !res.BudgetExceeded {
+		t.Errorf("BudgetExceeded run_id %s does not map to a budget-failed child", result.BudgetExceeded[0])
+	}
+}
+
+func TestParseDedupeBy(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "", want: DedupeExact},
+		{in: "exact", want: DedupeExact},
+		{in: "target", want: DedupeTarget},
+		{in: "param:url", want: "param:url"},
+		{in: "param:", wantErr: true},
+		{in: "url", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDedupeBy(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseDedupeBy(%q): expected error", tt.in)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseDedupeBy(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestOperator_DedupeByParam(t *testing.T) {
+	var calls atomic.Int64
+	collector := metrics.NewCollector("strict", "node", "fs", "root", "")
+	operator := NewOperator(FanOutConfig{
+		MaxDepth:  1,
+		MaxRuns:   10,
+		Parallel:  1,
+		DedupeBy:  "param:url",
+		Collector: collector,
+	}, successFactory(&calls))
+
+	observer := operator.NewObserver(0)
+	enqueue := func(params map[string]any) {
+		observer(&types.EventEnvelope{
+			Type:    types.EventTypeEnqueue,
+			Payload: map[string]any{"target": "detail.ts", "params": params},
+		})
+	}
+	// Same URL discovered from two listing pages: second is a duplicate
+	enqueue(map[string]any{"url": "https://example.com/a", "from": "page-1"})
+	enqueue(map[string]any{"url": "https://example.com/a", "from": "page-2"})
+	enqueue(map[string]any{"url": "https://example.com/b", "from": "page-2"})
+	// Missing field falls back to exact (target, params) dedup
+	enqueue(map[string]any{"from": "page-3"})
+	enqueue(map[string]any{"from": "page-3"})
+
+	rootDone := make(chan struct{})
+	close(rootDone)
+	operator.Run(t.Context(), rootDone)
+
+	result := operator.Results()
+	if result.RunsTotal != 3 {
+		t.Errorf("expected 3 runs, got %d", result.RunsTotal)
+	}
+	if result.EnqueueDeduped != 2 {
+		t.Errorf("expected 2 deduped, got %d", result.EnqueueDeduped)
+	}
+	if got := collector.Snapshot().EnqueuesDeduplicated; got != 2 {
+		t.Errorf("enqueues_deduplicated_total = %d, want 2", got)
+	}
+}
+
+func TestOperator_DedupeByTarget(t *testing.T) {
+	var calls atomic.Int64
+	operator := NewOperator(FanOutConfig{
+		MaxDepth: 1,
+		MaxRuns:  10,
+		Parallel: 1,
+		DedupeBy: DedupeTarget,
+	}, successFactory(&calls))
+
+	observer := operator.NewObserver(0)
+	for i := range 3 {
+		observer(&types.EventEnvelope{
+			Type:    types.EventTypeEnqueue,
+			Payload: map[string]any{"target": "once.ts", "params": map[string]any{"page": float64(i)}},
+		})
+	}
+
+	rootDone := make(chan struct{})
+	close(rootDone)
+	operator.Run(t.Context(), rootDone)
+
+	if got := operator.Results(); got.RunsTotal != 1 || got.EnqueueDeduped != 2 {
+		t.Errorf("expected 1 run and 2 deduped, got %d runs, %d deduped", got.RunsTotal, got.EnqueueDeduped)
+	}
+}
+
+func TestDedupSet_CapacityEvictsOldest(t *testing.T) {
+	d := newDedupSet(2)
+	d.add("a")
+	d.add("b")
+	d.add("a") // already present: no reordering, no eviction
+	d.add("c") // evicts "a"
+
+	if d.contains("a") {
+		t.Error("oldest key should be evicted at capacity")
+	}
+	if !d.contains("b") || !d.contains("c") {
+		t.Error("newer keys should remain")
+	}
+	if len(d.keys) != 2 || d.order.Len() != 2 {
+		t.Errorf("set size = %d/%d, want 2", len(d.keys), d.order.Len())
+	}
+}
+
+func TestItemOrigin(t *testing.T) {
+	tests := []struct {
+		url  any
+		want string
+	}{
+		{"https://Example.com/a?b=1", "https://example.com:443"},
+		{"http://example.com:8080/x", "http://example.com:8080"},
+		{"http://example.com/", "http://example.com:80"},
+		{"ftp://example.com/f", "ftp://example.com"},
+		{"/relative/path", ""},
+		{42, ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		item := WorkItem{Params: map[string]any{OriginParam: tt.url}}
+		if got := ItemOrigin(item); got != tt.want {
+			t.Errorf("ItemOrigin(%v) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestOperator_PerOriginConcurrency(t *testing.T) {
+	var mu sync.Mutex
+	current := map[string]int{}
+	peak := map[string]int{}
+	var globalPeak, global int
+
+	factory := func(ctx context.Context, item WorkItem, observer EnqueueObserver) (*RunResult, error) {
+		origin := ItemOrigin(item)
+		mu.Lock()
+		current[origin]++
+		peak[origin] = max(peak[origin], current[origin])
+		global++
+		globalPeak = max(globalPeak, global)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		current[origin]--
+		global--
+		mu.Unlock()
+		return &RunResult{
+			RunMeta: &types.RunMeta{RunID: item.RunID, Attempt: 1},
+			Outcome: &types.RunOutcome{Status: types.OutcomeSuccess, Message: "ok"},
+		}, nil
+	}
+
+	operator := NewOperator(FanOutConfig{
+		MaxDepth:             1,
+		MaxRuns:              7,
+		Parallel:             4,
+		PerOriginConcurrency: 1,
+	}, factory)
+
+	observer := operator.NewObserver(0)
+	urls := []string{
+		"https://a.example/1", "https://a.example/2", "https://a.example/3",
+		"https://b.example/1", "https://b.example/2", "https://b.example/3",
+		"not-a-url",
+	}
+	for _, u := range urls {
+		observer(&types.EventEnvelope{
+			Type:    types.EventTypeEnqueue,
+			Payload: map[string]any{"target": "script.ts", "params": map[string]any{"url": u}},
+		})
+	}
+
+	rootDone := make(chan struct{})
+	close(rootDone)
+	operator.Run(t.Context(), rootDone)
+
+	result := operator.Results()
+	if result.RunsTotal != 7 || result.RunsSucceeded != 7 {
+		t.Fatalf("expected 7 successful runs, got total=%d succeeded=%d", result.RunsTotal, result.RunsSucceeded)
+	}
+	if peak["https://a.example:443"] != 1 || peak["https://b.example:443"] != 1 {
+		t.Errorf("per-origin concurrency exceeded: %v", peak)
+	}
+	if globalPeak < 2 {
+		t.Errorf("different origins should run concurrently, global peak %d", globalPeak)
+	}
+	want := map[string]int{"https://a.example:443": 1, "https://b.example:443": 1}
+	if fmt.Sprint(result.OriginMaxInFlight) != fmt.Sprint(want) {
+		t.Errorf("OriginMaxInFlight = %v, want %v", result.OriginMaxInFlight, want)
+	}
+}
+
+func TestEnqueuePriority(t *testing.T) {
+	tests := []struct {
+		value any
+		want  int
+	}{
+		{nil, DefaultPriority},
+		{int8(9), 9},
+		{uint16(0), 0},
+		{float64(3), 3},
+		{int64(42), MaxPriority},
+		{int32(-1), MinPriority},
+		{2.5, DefaultPriority},
+		{"9", DefaultPriority},
+	}
+	for _, tt := range tests {
+		payload := map[string]any{"target": "script.ts"}
+		if tt.value != nil {
+			payload["priority"] = tt.value
+		}
+		if got := enqueuePriority(payload); got != tt.want {
+			t.Errorf("enqueuePriority(%#v) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestOperator_PriorityOrder(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	operator := NewOperator(FanOutConfig{
+		MaxDepth: 1,
+		MaxRuns:  10,
+		Parallel: 1,
+	}, func(ctx context.Context, item WorkItem, observer EnqueueObserver) (*RunResult, error) {
+		mu.Lock()
+		order = append(order, item.Params["id"].(string))
+		mu.Unlock()
+		return &RunResult{
+			RunMeta: &types.RunMeta{RunID: item.RunID, Attempt: 1},
+			Outcome: &types.RunOutcome{Status: types.OutcomeSuccess},
+		}, nil
+	})
+
+	observer := operator.NewObserver(0)
+	enqueue := func(id string, priority any) {
+		payload := map[string]any{"target": "script.ts", "params": map[string]any{"id": id}}
+		if priority != nil {
+			payload["priority"] = priority
+		}
+		observer(&types.EventEnvelope{Type: types.EventTypeEnqueue, Payload: payload})
+	}
+	enqueue("low", int8(1))
+	enqueue("default-1", nil)
+	enqueue("high-1", int8(9))
+	operator.Seed("script.ts", map[string]any{"id": "default-2"})
+	enqueue("high-2", int8(9))
+	enqueue("default-3", int8(5))
+
+	rootDone := make(chan struct{})
+	close(rootDone)
+	operator.Run(t.Context(), rootDone)
+
+	want := []string{"high-1", "high-2", "default-1", "default-2", "default-3", "low"}
+	if fmt.Sprint(order) != fmt.Sprint(want) {
+		t.Errorf("dispatch order = %v, want %v", order, want)
+	}
+	result := operator.Results()
+	if got := fmt.Sprint(result.Priorities); got != "map[1:1 5:3 9:2]" {
+		t.Errorf("Priorities = %s, want map[1:1 5:3 9:2]", got)
+	}
+	if got := formatPriorities(result.Priorities); got != "9=2, 5=3, 1=1" {
+		t.Errorf("formatPriorities = %q", got)
+	}
+}
+
+func TestOperator_DefaultPriorityOmitsDistribution(t *testing.T) {
+	operator := NewOperator(FanOutConfig{MaxDepth: 1, MaxRuns: 2, Parallel: 1},
+		func(ctx context.Context, item WorkItem, observer EnqueueObserver) (*RunResult, error) {
+			return &RunResult{
+				RunMeta: &types.RunMeta{RunID: item.RunID, Attempt: 1},
+				Outcome: &types.RunOutcome{Status: types.OutcomeSuccess},
+			}, nil
+		})
+	operator.Seed("script.ts", map[string]any{"url": "https://a.example"})
+	operator.Seed("script.ts", map[string]any{"url": "https://b.example"})
+
+	rootDone := make(chan struct{})
+	close(rootDone)
+	operator.Run(t.Context(), rootDone)
+
+	if p := operator.Results().Priorities; p != nil {
+		t.Errorf("Priorities = %v, want nil when every child ran at the default", p)
+	}
+}
+
+func TestOriginLimiter_StaggerSpacesStarts(t *testing.T) {
+	l := newOriginLimiter(0, 20*time.Millisecond)
+	l.jitter = func(d time.Duration) time.Duration { return d }
+	item := WorkItem{Params: map[string]any{"url": "https://a.example/"}}
+	other := WorkItem{Params: map[string]any{"url": "https://b.example/"}}
+
+	start := time.Now()
+	l.wait(t.Context(), item)
+	l.wait(t.Context(), other) // different origin: not delayed
+	if elapsed := time.Since(start); elapsed >= 20*time.Millisecond {
+		t.Errorf("first starts should not wait, took %s", elapsed)
+	}
+	l.wait(t.Context(), item)
+	l.wait(t.Context(), item)
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("third start against one origin should wait >= 40ms, took %s", elapsed)
+	}
+}
@@ -2,11 +2,17 @@ package runtime
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
+	"github.com/vmihailenco/msgpack/v5"
+
+	"github.com/pithecene-io/quarry/clock"
 	"github.com/pithecene-io/quarry/ipc"
 	"github.com/pithecene-io/quarry/lode"
 	"github.com/pithecene-io/quarry/log"
@@ -81,6 +87,31 @@ func IsStreamError(err error) bool {
 	return false
 }
 
+// ErrStreamStalled indicates no frame was decoded within the stall timeout.
+// Wrapped in an IngestionErrorStream error (executor crash outcome).
+var ErrStreamStalled = errors.New("executor stream stalled")
+
+// ErrEventTooLarge indicates a single event payload exceeded the configured
+// per-event size limit (see SetMaxEventBytes).
+// Wrapped in an IngestionErrorStream error (executor crash outcome).
+var ErrEventTooLarge = errors.New("event payload too large")
+
+// ErrRunQuotaExceeded indicates the bytes a run handed to the policy
+// (event payloads plus artifact chunks) exceeded its cap (see
+// SetMaxRunBytes). Wrapped in an IngestionErrorPolicy error
+// (policy_failure outcome).
+var ErrRunQuotaExceeded = errors.New("run quota exceeded")
+
+// ErrTelemetryViolation indicates a telemetry-mode run sent something other
+// than log or terminal events (see SetTelemetryMode).
+// Wrapped in an IngestionErrorStream error (executor crash outcome).
+var ErrTelemetryViolation = errors.New("telemetry mode accepts only log events")
+
+// ErrDrained indicates ingestion stopped accepting frames after a drain
+// request (graceful shutdown) and before the terminal event arrived.
+// Wrapped in an IngestionErrorCanceled error (executor crash outcome).
+var ErrDrained = errors.New("ingestion drained on shutdown")
+
 // errContractVersionMismatch is a sentinel error for contract version mismatches.
 // Used to distinguish version skew from other envelope validation failures
 // (run_id mismatch, attempt mismatch) which remain stream errors.
@@ -92,16 +123,60 @@ var errContractVersionMismatch = errors.New("contract version mismatch")
 // for dedup bookkeeping is acceptable.
 type EnqueueObserver func(*types.EventEnvelope)
 
+// ArtifactCommit describes an artifact whose commit event and chunks have
+// all arrived. Name and ContentType come from the artifact event payload.
+type ArtifactCommit struct {
+	ArtifactID  string
+	Name        string
+	ContentType string
+	SizeBytes   int64
+}
+
+// ArtifactObserver is a callback invoked once per committed artifact, after
+// the policy reports all of its chunks persisted (buffering policies: after
+// the flush that writes them). An artifact whose chunks the sink never
+// writes is not reported. Called synchronously from the ingestion loop, or
+// from the orchestrator after the final flush. Implementations must not
+// perform blocking I/O.
+type ArtifactObserver func(ArtifactCommit)
+
+// ProxyRotator selects a replacement proxy endpoint when the executor emits
+// rotate_proxy. current is the endpoint in use; implementations should avoid
+// returning it again. Chromium fixes the proxy server at launch, so only an
+// endpoint on the same protocol, host and port as current (i.e. different
+// credentials) is applied. Called synchronously from the ingestion loop.
+type ProxyRotator interface {
+	Rotate(current *types.ProxyEndpoint) (*types.ProxyEndpoint, error)
+}
+
+// IngestMode selects which frame streams the ingestion engine persists.
+type IngestMode string
+
+const (
+	// IngestAll persists events and artifacts (default).
+	IngestAll IngestMode = ""
+	// IngestEventsOnly discards artifact chunks and artifact commit events.
+	// Discarded artifacts are counted in artifacts_discarded_total.
+	IngestEventsOnly IngestMode = "events_only"
+	// IngestArtifactsOnly discards non-artifact events, except terminal
+	// events which still record the run outcome. Discarded events are
+	// counted in events_discarded_total.
+	IngestArtifactsOnly IngestMode = "artifacts_only"
+)
+
 // IngestionEngine handles IPC frame ingestion.
 // Per CONTRACT_IPC.md and CONTRACT_EMIT.md:
 //   - Frames are read in order
 //   - Sequence numbers must be strictly monotonic (1, 2, 3...)
+//     unless seq gaps are allowed, in which case forward jumps are tolerated,
+//     or the run is in telemetry mode, in which case ordering is not checked
 //   - First terminal event wins; subsequent terminals ignored
 //   - Invalid framing is fatal (no resync)
 //   - Policy failure on non-droppable events terminates run
 //   - run_result control frames do not affect seq ordering
 type IngestionEngine struct {
 	decoder          *ipc.FrameDecoder
+	decodeLimits     ipc.DecodeLimits
 	policy           policy.Policy
 	artifacts        *ArtifactManager
 	fileWriter       lode.FileWriter // sidecar file writes, may be nil
@@ -109,17 +184,49 @@ type IngestionEngine struct {
 	runMeta          *types.RunMeta // for envelope validation
 	collector        *metrics.Collector
 	enqueueObserver  EnqueueObserver // optional fan-out observer, may be nil
-	ackWriter        io.Writer       // stdin pipe for file_write_ack frames, may be nil
+	control          ipc.ControlSender // runtime→executor frames (acks, proxy_update), may be nil
+	allowSeqGaps     bool            // tolerate forward seq jumps (see SetAllowSeqGaps)
+	telemetryMode    bool            // log-only run, seq not enforced (see SetTelemetryMode)
+	stallTimeout     time.Duration   // inter-frame watchdog, 0 = disabled
+	maxEventBytes    int64           // per-event payload limit, 0 = disabled
+	maxRunBytes      int64           // cumulative persisted bytes cap, 0 = disabled
+	runBytes         int64           // event payload and chunk bytes handed to the policy
+	stalled          bool            // watchdog fired after the terminal event
+	redactor         *Redactor       // payload scrubber, may be nil
+	redactedFields   int64
+	ingestMode       IngestMode          // selective ingestion (see SetIngestMode)
+	discarded        map[string]struct{} // artifact IDs discarded under IngestEventsOnly
+	eventsDiscarded  int64
+	logMinLevel      types.LogLevel // drop log events below this level, "" = keep all
+	logsFiltered     int64
+	sampleRate       float64 // fraction of item events kept, 0 = keep all
+	eventsSampledOut int64
+	eventFilter      *EventFilter // drop non-matching events, nil = keep all
+	eventsFiltered   int64
+	drain            <-chan struct{} // closed to stop accepting frames, may be nil
+	proxyRotator     ProxyRotator         // rotate_proxy handler, may be nil
+	currentProxy     *types.ProxyEndpoint // endpoint the executor is using
+	drained          bool            // ingestion stopped on drain
 	currentSeq       int64
 	terminalSeen     bool
 	terminalEvent    *types.EventEnvelope
+	lastCheckpoint   *types.EventEnvelope // most recent checkpoint event, may be nil
 	runResult        *types.RunResultFrame // control frame, not counted in seq
+	artifactObserver ArtifactObserver      // per-artifact commit callback, may be nil
+	artifactMeta     map[string]ArtifactCommit // commit payload metadata by artifact ID
+	committed        []pendingArtifactCommit   // commits awaiting persisted chunks, in commit order
+	chunksIngested   int64                     // chunks accepted by the policy
+	launchedAt       time.Time                 // executor launch, zero = time-to-first-event not recorded
+	clock            clock.Clock               // stall watchdog and time-to-first-event
+	domainPolicy     *DomainPolicy             // enqueue target check, may be nil
+	firstFrameSeen   bool
 }
 
 // NewIngestionEngine creates a new ingestion engine.
 // The fileWriter parameter may be nil if sidecar file writes are not supported.
 // The observer parameter may be nil if fan-out is not enabled.
-// The ackWriter parameter may be nil for backward compatibility (no ack frames sent).
+// The control parameter may be nil for backward compatibility (no ack or
+// proxy_update frames sent).
 func NewIngestionEngine(
 	reader io.Reader,
 	pol policy.Policy,
@@ -129,7 +236,7 @@ func NewIngestionEngine(
 	runMeta *types.RunMeta,
 	collector *metrics.Collector,
 	observer EnqueueObserver,
-	ackWriter io.Writer,
+	control ipc.ControlSender,
 ) *IngestionEngine {
 	return &IngestionEngine{
 		decoder:         ipc.NewFrameDecoder(reader),
@@ -140,11 +247,370 @@ func NewIngestionEngine(
 		runMeta:         runMeta,
 		collector:       collector,
 		enqueueObserver: observer,
-		ackWriter:       ackWriter,
+		control:         control,
+		clock:           clock.Real,
 		currentSeq:      0,
 	}
 }
 
+// SetAllowSeqGaps controls whether forward jumps in event seq are tolerated.
+// When enabled, a seq greater than expected is logged and counted in
+// seq_gaps_total instead of aborting the stream. A backward or duplicate
+// seq remains a fatal stream error. Must be called before Run.
+func (e *IngestionEngine) SetAllowSeqGaps(allow bool) {
+	e.allowSeqGaps = allow
+}
+
+// SetTelemetryMode declares the run log-only. Sequence ordering is not
+// enforced, and any event other than log or a terminal event, any artifact
+// chunk, and any file write fails Run with a stream error wrapping
+// ErrTelemetryViolation, so the mode cannot carry real data.
+// Must be called before Run.
+func (e *IngestionEngine) SetTelemetryMode(on bool) {
+	e.telemetryMode = on
+}
+
+// SetStallTimeout enables the inter-frame watchdog. If no frame is decoded
+// within d, Run fails with a stream error wrapping ErrStreamStalled. The
+// deadline resets on every decoded frame. Zero disables the watchdog.
+// Must be called before Run.
+func (e *IngestionEngine) SetStallTimeout(d time.Duration) {
+	e.stallTimeout = d
+}
+
+// SetMaxEventBytes bounds the encoded size of a single event payload. An
+// event whose payload exceeds n bytes fails Run with a stream error wrapping
+// ErrEventTooLarge. This is stricter than the IPC frame cap. Zero disables
+// the check. Must be called before Run.
+func (e *IngestionEngine) SetMaxEventBytes(n int64) {
+	e.maxEventBytes = n
+}
+
+// SetMaxRunBytes caps the bytes a run may hand to the policy: event
+// payloads (msgpack-encoded, as for SetMaxEventBytes) plus artifact chunk
+// data. The frame that crosses the cap is still persisted; Run then fails
+// with a policy error wrapping ErrRunQuotaExceeded, so no further events or
+// artifacts are ingested. Zero disables the cap. Must be called before Run.
+func (e *IngestionEngine) SetMaxRunBytes(n int64) {
+	e.maxRunBytes = n
+}
+
+// RunBytes returns the event payload and artifact chunk bytes handed to
+// the policy. Only counted when SetMaxRunBytes is set.
+func (e *IngestionEngine) RunBytes() int64 {
+	return e.runBytes
+}
+
+// chargeRunBytes adds n persisted bytes and enforces the run cap.
+func (e *IngestionEngine) chargeRunBytes(n int64) error {
+	e.runBytes += n
+	if e.runBytes <= e.maxRunBytes {
+		return nil
+	}
+	e.logger.Error("run byte quota exceeded", map[string]any{
+		"bytes": e.runBytes,
+		"limit": e.maxRunBytes,
+	})
+	return &IngestionError{
+		Kind: IngestionErrorPolicy,
+		Err:  fmt.Errorf("%w: %d bytes persisted, limit %d", ErrRunQuotaExceeded, e.runBytes, e.maxRunBytes),
+	}
+}
+
+// SetRedactor installs a payload redactor applied to every event before the
+// fan-out observer and policy see it. Nil disables redaction.
+// Must be called before Run.
+func (e *IngestionEngine) SetRedactor(r *Redactor) {
+	e.redactor = r
+}
+
+// SetIngestMode selects which frame streams are persisted. Discarded frames
+// are still validated (framing, seq ordering) but never reach the artifact
+// manager or the policy. Must be called before Run.
+func (e *IngestionEngine) SetIngestMode(m IngestMode) {
+	e.ingestMode = m
+}
+
+// SetLogMinLevel drops log events whose payload.level ranks below level,
+// before they reach the policy. Filtered events are counted per level in
+// log_filtered_total, not as policy drops. Log events with a missing or
+// unknown level are kept. Empty level disables the filter.
+// Must be called before Run.
+func (e *IngestionEngine) SetLogMinLevel(level types.LogLevel) {
+	e.logMinLevel = level
+}
+
+// SetSampleRate keeps only a deterministic fraction of item events: an
+// item is kept when the hash of its event_id falls below rate, so the same
+// stream always yields the same sample. Sampled-out items are counted in
+// events_sampled_out_total, not as policy drops. All other event types are
+// kept, and seq is validated over the full stream. A rate of 0 or >= 1
+// disables sampling. Must be called before Run.
+func (e *IngestionEngine) SetSampleRate(rate float64) {
+	e.sampleRate = rate
+}
+
+// SetEventFilter drops events that do not match filter before the policy.
+// Filtered events are counted in events_filtered_total, not as policy
+// drops. Artifact, checkpoint, and terminal events are always kept, enqueue
+// events are still scheduled, and seq is validated over the full stream.
+// The filter sees redacted payloads. Nil disables filtering. Must be called
+// before Run.
+func (e *IngestionEngine) SetEventFilter(filter *EventFilter) {
+	e.eventFilter = filter
+}
+
+// SetDrain installs a graceful-shutdown channel. Once ch is closed, the
+// engine finishes the frame in flight and stops reading; Run returns an
+// IngestionErrorCanceled wrapping ErrDrained. If the terminal event has
+// already been received, draining is ignored and ingestion continues to EOF
+// so the executor's exit decides the outcome. Must be called before Run.
+func (e *IngestionEngine) SetDrain(ch <-chan struct{}) {
+	e.drain = ch
+}
+
+// SetProxyRotator enables mid-run proxy rotation. On each rotate_proxy event
+// the engine asks r for a replacement for current and sends it to the
+// executor as a proxy_update frame on the ack writer. Rotation is advisory:
+// selection or write failures are logged and the event is still ingested.
+// Requires an ack writer; nil r disables rotation. Must be called before Run.
+func (e *IngestionEngine) SetProxyRotator(r ProxyRotator, current *types.ProxyEndpoint) {
+	e.proxyRotator = r
+	e.currentProxy = current
+}
+
+// SetMaxFrameBytes overrides the decoder's frame size limit, including the
+// length prefix (0 = ipc.MaxFrameSize, at most ipc.MaxFrameSizeCeiling).
+// Oversize frames remain fatal stream errors. Must be called before Run.
+func (e *IngestionEngine) SetMaxFrameBytes(n int64) error {
+	return e.decoder.SetMaxFrameSize(n)
+}
+
+// SetDecodeLimits overrides the msgpack structure limits applied to each
+// frame payload (zero fields = ipc defaults). A payload beyond them is a
+// decode error, like any malformed frame. Must be called before Run.
+func (e *IngestionEngine) SetDecodeLimits(limits ipc.DecodeLimits) {
+	e.decodeLimits = limits
+}
+
+// SetLaunchTime records when the executor was launched. The first frame
+// read is then observed in the collector's time-to-first-event histogram.
+// Must be called before Run.
+func (e *IngestionEngine) SetLaunchTime(t time.Time) {
+	e.launchedAt = t
+}
+
+// SetClock replaces the clock behind the stall watchdog and the
+// time-to-first-event measurement (default clock.Real; nil keeps it).
+// Must be called before Run.
+func (e *IngestionEngine) SetClock(c clock.Clock) {
+	if c != nil {
+		e.clock = c
+	}
+}
+
+// SetDomainPolicy enforces p on every enqueue event's params.url. A blocked
+// enqueue fails Run with a policy error wrapping ErrDomainDenied before the
+// fan-out observer or policy sees it. Must be called before Run.
+func (e *IngestionEngine) SetDomainPolicy(p *DomainPolicy) {
+	e.domainPolicy = p
+}
+
+// SetArtifactObserver registers obs to be called once per artifact when it
+// becomes committed (via the artifact manager's commit hook) and its chunks
+// are persisted. Artifacts discarded by IngestEventsOnly are never reported.
+// Must be called before Run.
+func (e *IngestionEngine) SetArtifactObserver(obs ArtifactObserver) {
+	if obs == nil {
+		return
+	}
+	e.artifactObserver = obs
+	e.artifactMeta = make(map[string]ArtifactCommit)
+	e.artifacts.SetOnCommit(e.notifyArtifactCommit)
+}
+
+// pendingArtifactCommit is a committed artifact awaiting its chunks'
+// persistence. chunks is the policy chunk count that covers the artifact's
+// last chunk, or -1 until the frame that committed it has been ingested.
+type pendingArtifactCommit struct {
+	commit ArtifactCommit
+	chunks int64
+}
+
+// notifyArtifactCommit is the artifact manager commit hook. It fires before
+// the policy has seen the committing frame, so the commit is queued and
+// reported by releaseArtifactCommits once the chunks are persisted.
+func (e *IngestionEngine) notifyArtifactCommit(artifactID string, sizeBytes int64) {
+	commit, ok := e.artifactMeta[artifactID]
+	if !ok {
+		commit = ArtifactCommit{ArtifactID: artifactID}
+	}
+	delete(e.artifactMeta, artifactID)
+	commit.SizeBytes = sizeBytes
+	e.committed = append(e.committed, pendingArtifactCommit{commit: commit, chunks: -1})
+}
+
+// releaseArtifactCommits reports, in commit order, the queued commits whose
+// chunks the policy reports persisted. Called after each successfully
+// ingested frame and after the final policy flush.
+func (e *IngestionEngine) releaseArtifactCommits() {
+	if len(e.committed) == 0 {
+		return
+	}
+	for i := range e.committed {
+		if e.committed[i].chunks < 0 {
+			e.committed[i].chunks = e.chunksIngested
+		}
+	}
+	persisted := e.policy.Stats().ChunksPersisted
+	n := 0
+	for n < len(e.committed) && e.committed[n].chunks <= persisted {
+		e.artifactObserver(e.committed[n].commit)
+		n++
+	}
+	e.committed = e.committed[n:]
+}
+
+// CurrentProxy returns the endpoint most recently sent to the executor, or
+// the initial endpoint if no rotation has happened.
+func (e *IngestionEngine) CurrentProxy() *types.ProxyEndpoint {
+	return e.currentProxy
+}
+
+// Drained reports whether ingestion stopped because of a drain request.
+func (e *IngestionEngine) Drained() bool {
+	return e.drained
+}
+
+// drainRequested reports whether a drain was requested and still applies
+// (no terminal event yet).
+func (e *IngestionEngine) drainRequested() bool {
+	if e.drain == nil || e.terminalSeen {
+		return false
+	}
+	select {
+	case <-e.drain:
+		return true
+	default:
+		return false
+	}
+}
+
+// stopForDrain records the drain and returns the drain error.
+func (e *IngestionEngine) stopForDrain() error {
+	e.drained = true
+	e.logger.Warn("ingestion drained on shutdown", map[string]any{
+		"last_seq": e.currentSeq,
+	})
+	return &IngestionError{
+		Kind: IngestionErrorCanceled,
+		Err:  fmt.Errorf("%w after seq %d", ErrDrained, e.currentSeq),
+	}
+}
+
+// ArtifactsDiscarded returns the number of distinct artifacts discarded
+// under IngestEventsOnly.
+func (e *IngestionEngine) ArtifactsDiscarded() int64 {
+	return int64(len(e.discarded))
+}
+
+// EventsDiscarded returns the number of events discarded under
+// IngestArtifactsOnly.
+func (e *IngestionEngine) EventsDiscarded() int64 {
+	return e.eventsDiscarded
+}
+
+// LogsFiltered returns the number of log events dropped by SetLogMinLevel.
+func (e *IngestionEngine) LogsFiltered() int64 {
+	return e.logsFiltered
+}
+
+// EventsSampledOut returns the number of item events skipped by
+// SetSampleRate.
+func (e *IngestionEngine) EventsSampledOut() int64 {
+	return e.eventsSampledOut
+}
+
+// EventsFiltered returns the number of events dropped by SetEventFilter.
+func (e *IngestionEngine) EventsFiltered() int64 {
+	return e.eventsFiltered
+}
+
+// filterOut reports whether envelope fails the event filter, counting it
+// if so.
+func (e *IngestionEngine) filterOut(envelope *types.EventEnvelope) bool {
+	if e.eventFilter == nil || filterExempt(envelope.Type) || e.eventFilter.Match(envelope) {
+		return false
+	}
+	e.eventsFiltered++
+	e.collector.IncEventsFiltered()
+	return true
+}
+
+// sampleOut reports whether envelope is an item event outside the sample,
+// counting it if so.
+func (e *IngestionEngine) sampleOut(envelope *types.EventEnvelope) bool {
+	if e.sampleRate <= 0 || e.sampleRate >= 1 || envelope.Type != types.EventTypeItem {
+		return false
+	}
+	if sampleFraction(envelope.EventID) < e.sampleRate {
+		return false
+	}
+	e.eventsSampledOut++
+	e.collector.IncEventsSampledOut()
+	return true
+}
+
+// sampleFraction maps an event ID to a stable value in [0, 1).
+func sampleFraction(eventID string) float64 {
+	sum := sha256.Sum256([]byte(eventID))
+	return float64(binary.BigEndian.Uint64(sum[:8])>>11) / (1 << 53)
+}
+
+// filterLog reports whether envelope is a log event below the minimum
+// level, counting it if so.
+func (e *IngestionEngine) filterLog(envelope *types.EventEnvelope) bool {
+	if e.logMinLevel == "" || envelope.Type != types.EventTypeLog {
+		return false
+	}
+	level, _ := envelope.Payload["level"].(string)
+	severity, known := types.LogLevel(level).Severity()
+	if !known {
+		return false
+	}
+	minSeverity, _ := e.logMinLevel.Severity()
+	if severity >= minSeverity {
+		return false
+	}
+	e.logsFiltered++
+	e.collector.IncLogFiltered(level)
+	return true
+}
+
+// discardArtifact records artifactID as discarded, counting each artifact once.
+func (e *IngestionEngine) discardArtifact(artifactID string) {
+	if _, seen := e.discarded[artifactID]; seen {
+		return
+	}
+	if e.discarded == nil {
+		e.discarded = make(map[string]struct{})
+	}
+	e.discarded[artifactID] = struct{}{}
+	e.collector.IncArtifactsDiscarded()
+}
+
+// RedactedFields returns the number of payload fields redacted so far.
+func (e *IngestionEngine) RedactedFields() int64 {
+	return e.redactedFields
+}
+
+// Stalled reports whether the watchdog fired after the terminal event.
+// Run returns nil in that case (the terminal event decides the outcome),
+// but the executor is still holding stdout open and must be killed.
+func (e *IngestionEngine) Stalled() bool {
+	return e.stalled
+}
+
 // Run runs the ingestion loop until EOF or fatal error.
 // Returns:
 //   - nil: stream ended cleanly (EOF)
@@ -157,14 +623,51 @@ func (e *IngestionEngine) Run(ctx context.Context) error {
 		case <-ctx.Done():
 			return &IngestionError{
 				Kind: IngestionErrorCanceled,
-				Err:  ctx.Err(),
+				Err:  context.Cause(ctx),
 			}
 		default:
 		}
+		if e.drainRequested() {
+			return e.stopForDrain()
+		}
 
 		// Read frame
-		payload, err := e.decoder.ReadFrame()
+		payload, err := e.readFrame(ctx)
 		if err != nil {
+			if errors.Is(err, ErrDrained) {
+				return e.stopForDrain()
+			}
+
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				// Prefer the structured cause (see CancelCause)
+				if cause := context.Cause(ctx); cause != nil {
+					err = cause
+				}
+				return &IngestionError{
+					Kind: IngestionErrorCanceled,
+					Err:  err,
+				}
+			}
+
+			if errors.Is(err, ErrStreamStalled) {
+				if e.terminalSeen {
+					e.logger.Warn("executor stalled after terminal event", map[string]any{
+						"stall_timeout": e.stallTimeout.String(),
+					})
+					e.stalled = true
+					return nil
+				}
+				e.logger.Error("executor stalled", map[string]any{
+					"stall_timeout": e.stallTimeout.String(),
+					"last_seq":      e.currentSeq,
+				})
+				e.collector.IncExecutorCrash()
+				return &IngestionError{
+					Kind: IngestionErrorStream,
+					Err:  err,
+				}
+			}
+
 			if errors.Is(err, io.EOF) {
 				// Stream ended cleanly
 				return nil
@@ -192,6 +695,13 @@ func (e *IngestionEngine) Run(ctx context.Context) error {
 			}
 		}
 
+		if !e.firstFrameSeen {
+			e.firstFrameSeen = true
+			if !e.launchedAt.IsZero() {
+				e.collector.ObserveTimeToFirstEvent(e.clock.Now().Sub(e.launchedAt))
+			}
+		}
+
 		// Decode and process frame
 		if err := e.processFrame(ctx, payload); err != nil {
 			// Count stream errors as executor crashes — decode failures,
@@ -206,10 +716,58 @@ func (e *IngestionEngine) Run(ctx context.Context) error {
 	}
 }
 
+// readFrame reads the next frame, bounded by the stall timeout when enabled
+// and interrupted by a drain request. The read runs in a goroutine because
+// pipe reads cannot be interrupted; on stall, drain, or cancellation the
+// goroutine finishes once the executor is killed and its stdout closes.
+func (e *IngestionEngine) readFrame(ctx context.Context) ([]byte, error) {
+	var drain <-chan struct{}
+	if !e.terminalSeen {
+		drain = e.drain
+	}
+	if e.stallTimeout <= 0 && drain == nil {
+		return e.decoder.ReadFrame()
+	}
+
+	type readResult struct {
+		payload []byte
+		err     error
+	}
+	done := make(chan readResult, 1)
+	go func() {
+		payload, err := e.decoder.ReadFrame()
+		done <- readResult{payload: payload, err: err}
+	}()
+
+	var stall <-chan time.Time
+	if e.stallTimeout > 0 {
+		timer := e.clock.NewTimer(e.stallTimeout)
+		defer timer.Stop()
+		stall = timer.C()
+	}
+
+	select {
+	case res := <-done:
+		return res.payload, res.err
+	case <-stall:
+		return nil, fmt.Errorf("%w: no frame received within %s", ErrStreamStalled, e.stallTimeout)
+	case <-drain:
+		// A frame that finished decoding is in flight: process it first
+		select {
+		case res := <-done:
+			return res.payload, res.err
+		default:
+			return nil, ErrDrained
+		}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
 // processFrame decodes and processes a single frame.
 func (e *IngestionEngine) processFrame(ctx context.Context, payload []byte) error {
 	// Decode frame - discriminates by type field
-	decoded, err := ipc.DecodeFrame(payload)
+	decoded, err := ipc.DecodeFrameWithLimits(payload, e.decodeLimits)
 	if err != nil {
 		e.logger.Error("frame decode error", map[string]any{
 			"error": err.Error(),
@@ -228,19 +786,25 @@ func (e *IngestionEngine) processFrame(ctx context.Context, payload []byte) erro
 	// Handle based on frame type
 	switch frame := decoded.(type) {
 	case *types.ArtifactChunkFrame:
-		return e.processArtifactChunk(ctx, frame)
+		err = e.processArtifactChunk(ctx, frame)
 	case *types.EventEnvelope:
-		return e.processEvent(ctx, frame)
+		err = e.processEvent(ctx, frame)
 	case *types.RunResultFrame:
-		return e.processRunResult(frame)
+		err = e.processRunResult(frame)
 	case *types.FileWriteFrame:
-		return e.processFileWrite(ctx, frame)
+		err = e.processFileWrite(ctx, frame)
 	default:
 		return &IngestionError{
 			Kind: IngestionErrorStream,
 			Err:  fmt.Errorf("unexpected frame type: %T", decoded),
 		}
 	}
+	if err != nil {
+		return err
+	}
+
+	e.releaseArtifactCommits()
+	return nil
 }
 
 // processEvent processes an event envelope.
@@ -266,9 +830,44 @@ func (e *IngestionEngine) processEvent(ctx context.Context, envelope *types.Even
 		}
 	}
 
+	if err := e.checkEventSize(envelope); err != nil {
+		e.logger.Error("event too large", map[string]any{
+			"error": err.Error(),
+			"type":  envelope.Type,
+			"seq":   envelope.Seq,
+		})
+		return &IngestionError{
+			Kind: IngestionErrorStream,
+			Err:  err,
+		}
+	}
+
+	if e.telemetryMode && envelope.Type != types.EventTypeLog && !envelope.Type.IsTerminal() {
+		e.logger.Error("telemetry mode violation", map[string]any{
+			"type": envelope.Type,
+			"seq":  envelope.Seq,
+		})
+		return &IngestionError{
+			Kind: IngestionErrorStream,
+			Err:  fmt.Errorf("%w: got %s event (seq %d)", ErrTelemetryViolation, envelope.Type, envelope.Seq),
+		}
+	}
+
 	// Validate sequence ordering per CONTRACT_EMIT.md
 	expectedSeq := e.currentSeq + 1
-	if envelope.Seq != expectedSeq {
+	switch {
+	case e.telemetryMode:
+		// Log-only runs do not enforce ordering; currentSeq tracks the highest seen
+	case envelope.Seq > expectedSeq && e.allowSeqGaps:
+		// Forward jump tolerated: events were lost upstream but ordering holds
+		e.logger.Warn("sequence gap", map[string]any{
+			"expected": expectedSeq,
+			"got":      envelope.Seq,
+			"missing":  envelope.Seq - expectedSeq,
+			"type":     envelope.Type,
+		})
+		e.collector.IncSeqGaps()
+	case envelope.Seq != expectedSeq:
 		e.logger.Error("sequence violation", map[string]any{
 			"expected": expectedSeq,
 			"got":      envelope.Seq,
@@ -280,7 +879,7 @@ func (e *IngestionEngine) processEvent(ctx context.Context, envelope *types.Even
 			Err:  fmt.Errorf("sequence violation: expected %d, got %d", expectedSeq, envelope.Seq),
 		}
 	}
-	e.currentSeq = envelope.Seq
+	e.currentSeq = max(e.currentSeq, envelope.Seq)
 
 	// Check for terminal events
 	if envelope.Type.IsTerminal() {
@@ -302,23 +901,86 @@ func (e *IngestionEngine) processEvent(ctx context.Context, envelope *types.Even
 		})
 	}
 
+	// Events-only mode: drop the commit with its (already discarded) chunks
+	if envelope.Type == types.EventTypeArtifact && e.ingestMode == IngestEventsOnly {
+		artifactID, _ := envelope.Payload["artifact_id"].(string)
+		e.discardArtifact(artifactID)
+		return nil
+	}
+
 	// Handle artifact commit
 	if envelope.Type == types.EventTypeArtifact {
 		if err := e.handleArtifactCommit(envelope); err != nil {
-			// Artifact errors are stream errors (executor/data misbehavior)
+			// Artifact errors are stream errors (executor/data misbehavior),
+			// except budget violations which fail the run by policy
 			return &IngestionError{
-				Kind: IngestionErrorStream,
+				Kind: artifactErrorKind(err),
 				Err:  err,
 			}
 		}
 	}
 
+	// Domain policy: reject a blocked enqueue before the operator dispatches it.
+	// Checked before redaction so a redacted url cannot bypass it.
+	if envelope.Type == types.EventTypeEnqueue {
+		if err := e.domainPolicy.CheckTarget(envelope.Payload["params"]); err != nil {
+			e.logger.Error("enqueue blocked by domain policy", map[string]any{
+				"seq":   envelope.Seq,
+				"error": err.Error(),
+			})
+			return &IngestionError{
+				Kind: IngestionErrorPolicy,
+				Err:  err,
+			}
+		}
+	}
+
+	// Redact after artifact bookkeeping (which reads raw payload fields) and
+	// before any consumer: observer, policy, sinks, and terminal summary.
+	if n := e.redactor.Redact(envelope.Payload); n > 0 {
+		e.redactedFields += int64(n)
+		e.logger.Debug("payload fields redacted", map[string]any{
+			"type":   envelope.Type,
+			"seq":    envelope.Seq,
+			"fields": n,
+		})
+	}
+
 	// Notify fan-out observer before policy dispatch.
 	// Scheduling is independent of whether the policy drops the enqueue event.
 	if envelope.Type == types.EventTypeEnqueue && e.enqueueObserver != nil {
 		e.enqueueObserver(envelope)
 	}
 
+	if envelope.Type == types.EventTypeRotateProxy {
+		e.rotateProxy(envelope)
+	}
+
+	if envelope.Type == types.EventTypeCheckpoint {
+		e.lastCheckpoint = envelope
+	}
+
+	// Log level filter: a deliberate pre-policy drop, not buffer pressure
+	if e.filterLog(envelope) {
+		return nil
+	}
+
+	// Artifacts-only mode: skip non-artifact events after fan-out scheduling.
+	// Terminal events are kept so the run outcome is persisted.
+	if e.ingestMode == IngestArtifactsOnly && envelope.Type != types.EventTypeArtifact && !envelope.Type.IsTerminal() {
+		e.eventsDiscarded++
+		e.collector.IncEventsDiscarded()
+		return nil
+	}
+
+	// Event filter, then sampling: deliberate skips, not buffer pressure
+	if e.filterOut(envelope) {
+		return nil
+	}
+	if e.sampleOut(envelope) {
+		return nil
+	}
+
 	// Delegate to policy
 	if err := e.policy.IngestEvent(ctx, envelope); err != nil {
 		// Policy failure terminates run per CONTRACT_POLICY.md
@@ -333,6 +995,34 @@ func (e *IngestionEngine) processEvent(ctx context.Context, envelope *types.Even
 		}
 	}
 
+	if e.maxRunBytes > 0 {
+		encoded, err := msgpack.Marshal(envelope.Payload)
+		if err != nil {
+			return &IngestionError{
+				Kind: IngestionErrorStream,
+				Err:  fmt.Errorf("measuring %s event payload: %w", envelope.Type, err),
+			}
+		}
+		return e.chargeRunBytes(int64(len(encoded)))
+	}
+
+	return nil
+}
+
+// checkEventSize enforces the per-event payload limit. The payload is
+// measured as its msgpack encoding, the same representation it arrived in.
+func (e *IngestionEngine) checkEventSize(envelope *types.EventEnvelope) error {
+	if e.maxEventBytes <= 0 {
+		return nil
+	}
+	encoded, err := msgpack.Marshal(envelope.Payload)
+	if err != nil {
+		return fmt.Errorf("measuring %s event payload: %w", envelope.Type, err)
+	}
+	if size := int64(len(encoded)); size > e.maxEventBytes {
+		return fmt.Errorf("%w: %s event (seq %d) payload is %d bytes, limit %d",
+			ErrEventTooLarge, envelope.Type, envelope.Seq, size, e.maxEventBytes)
+	}
 	return nil
 }
 
@@ -397,7 +1087,35 @@ func (e *IngestionEngine) handleArtifactCommit(envelope *types.EventEnvelope) er
 		return fmt.Errorf("artifact event has invalid size_bytes type: %T", envelope.Payload["size_bytes"])
 	}
 
-	if err := e.artifacts.CommitArtifact(artifactID, sizeBytes); err != nil {
+	// sha256 is optional; the artifact manager checks it only when verifying
+	sha256Hex, _ := envelope.Payload["sha256"].(string)
+
+	// retention_class and ttl_seconds are optional storage hints
+	if _, err := lode.ParseRetentionHint(envelope.Payload); err != nil {
+		return fmt.Errorf("artifact %s: %w", artifactID, err)
+	}
+
+	// A sniffed content type replaces a missing or generic declared one; the
+	// declared value is kept as declared_content_type
+	declared, _ := envelope.Payload["content_type"].(string)
+	if contentType, sniffed := e.artifacts.ResolveContentType(artifactID, declared); sniffed {
+		envelope.Payload["content_type"] = contentType
+		envelope.Payload["declared_content_type"] = declared
+		e.logger.Debug("artifact content type sniffed", map[string]any{
+			"artifact_id":           artifactID,
+			"content_type":          contentType,
+			"declared_content_type": declared,
+		})
+	}
+
+	// Recorded before the commit, which fires the hook when chunks are complete
+	if e.artifactObserver != nil {
+		name, _ := envelope.Payload["name"].(string)
+		contentType, _ := envelope.Payload["content_type"].(string)
+		e.artifactMeta[artifactID] = ArtifactCommit{ArtifactID: artifactID, Name: name, ContentType: contentType}
+	}
+
+	if err := e.artifacts.CommitArtifactWithSum(artifactID, sizeBytes, strings.ToLower(sha256Hex)); err != nil {
 		e.logger.Error("artifact commit failed", map[string]any{
 			"artifact_id": artifactID,
 			"size_bytes":  sizeBytes,
@@ -416,6 +1134,13 @@ func (e *IngestionEngine) handleArtifactCommit(envelope *types.EventEnvelope) er
 
 // processArtifactChunk processes an artifact chunk frame.
 func (e *IngestionEngine) processArtifactChunk(ctx context.Context, frame *types.ArtifactChunkFrame) error {
+	if e.telemetryMode {
+		return &IngestionError{
+			Kind: IngestionErrorStream,
+			Err:  fmt.Errorf("%w: got artifact chunk for %s", ErrTelemetryViolation, frame.ArtifactID),
+		}
+	}
+
 	// Validate chunk per CONTRACT_IPC.md
 	if frame.Seq < 1 {
 		return &IngestionError{
@@ -431,12 +1156,19 @@ func (e *IngestionEngine) processArtifactChunk(ctx context.Context, frame *types
 		}
 	}
 
+	// Events-only mode: validated, then discarded
+	if e.ingestMode == IngestEventsOnly {
+		e.discardArtifact(frame.ArtifactID)
+		return nil
+	}
+
 	// Convert to internal chunk type
 	chunk := &types.ArtifactChunk{
 		ArtifactID: frame.ArtifactID,
 		Seq:        frame.Seq,
 		IsLast:     frame.IsLast,
 		Data:       frame.Data,
+		CRC32C:     frame.CRC32C,
 	}
 
 	// Add to artifact manager
@@ -447,9 +1179,10 @@ func (e *IngestionEngine) processArtifactChunk(ctx context.Context, frame *types
 			"is_last":     chunk.IsLast,
 			"error":       err.Error(),
 		})
-		// Artifact errors are stream errors (executor/data misbehavior)
+		// Artifact errors are stream errors (executor/data misbehavior),
+		// except budget violations which fail the run by policy
 		return &IngestionError{
-			Kind: IngestionErrorStream,
+			Kind: artifactErrorKind(err),
 			Err:  fmt.Errorf("artifact chunk failed: %w", err),
 		}
 	}
@@ -466,15 +1199,34 @@ func (e *IngestionEngine) processArtifactChunk(ctx context.Context, frame *types
 			Err:  fmt.Errorf("policy chunk failure: %w", err),
 		}
 	}
+	e.chunksIngested++
+
+	if e.maxRunBytes > 0 {
+		return e.chargeRunBytes(int64(len(chunk.Data)))
+	}
 
 	return nil
 }
 
+// artifactErrorKind classifies an ArtifactManager error.
+func artifactErrorKind(err error) IngestionErrorKind {
+	if errors.Is(err, ErrArtifactBudgetExceeded) {
+		return IngestionErrorPolicy
+	}
+	return IngestionErrorStream
+}
+
 // GetTerminalEvent returns the terminal event if seen.
 func (e *IngestionEngine) GetTerminalEvent() (*types.EventEnvelope, bool) {
 	return e.terminalEvent, e.terminalSeen
 }
 
+// LastCheckpoint returns the most recent checkpoint event (after redaction),
+// or nil if the run emitted none.
+func (e *IngestionEngine) LastCheckpoint() *types.EventEnvelope {
+	return e.lastCheckpoint
+}
+
 // HasTerminal returns true if a terminal event has been seen.
 func (e *IngestionEngine) HasTerminal() bool {
 	return e.terminalSeen
@@ -499,10 +1251,20 @@ func (e *IngestionEngine) processRunResult(frame *types.RunResultFrame) error {
 
 	e.runResult = frame
 	e.logger.Debug("run_result frame received", map[string]any{
-		"status":    frame.Outcome.Status,
-		"has_proxy": frame.ProxyUsed != nil,
+		"status":           frame.Outcome.Status,
+		"has_proxy":        frame.ProxyUsed != nil,
+		"executor_version": frame.ExecutorVersion,
 	})
 
+	// Envelopes must match the contract version exactly; this surfaces a
+	// skewed executor even when it emitted no event to fail that check
+	if frame.ExecutorVersion != "" && frame.ExecutorVersion != types.ContractVersion {
+		e.logger.Warn("executor version differs from runtime contract version", map[string]any{
+			"executor_version": frame.ExecutorVersion,
+			"contract_version": types.ContractVersion,
+		})
+	}
+
 	return nil
 }
 
@@ -514,6 +1276,14 @@ func (e *IngestionEngine) processRunResult(frame *types.RunResultFrame) error {
 // script decides how to handle it. Validation errors (empty filename, path
 // traversal) remain fatal stream errors.
 func (e *IngestionEngine) processFileWrite(ctx context.Context, frame *types.FileWriteFrame) error {
+	if e.telemetryMode {
+		e.sendFileWriteAck(frame.WriteID, false, "file writes are not allowed in telemetry mode")
+		return &IngestionError{
+			Kind: IngestionErrorStream,
+			Err:  fmt.Errorf("%w: got file_write for %s", ErrTelemetryViolation, frame.Filename),
+		}
+	}
+
 	// Reject file writes after terminal event — send error ack to guarantee
 	// promise settlement on the executor side, then discard.
 	if e.terminalSeen {
@@ -585,39 +1355,86 @@ func (e *IngestionEngine) processFileWrite(ctx context.Context, frame *types.Fil
 	return nil
 }
 
-// sendFileWriteAck writes a file_write_ack frame to the executor's stdin.
-// No-op if ackWriter is nil (backward compat) or writeId is 0 (legacy frame).
-// Ack write failures are logged but non-fatal (executor may have exited).
+// sendFileWriteAck sends a file_write_ack frame to the executor's stdin.
+// No-op if control is nil (backward compat) or writeId is 0 (legacy frame).
+// Ack send failures are logged but non-fatal (executor may have exited).
 func (e *IngestionEngine) sendFileWriteAck(writeID uint32, ok bool, errMsg string) {
-	if e.ackWriter == nil || writeID == 0 {
+	if e.control == nil || writeID == 0 {
 		return
 	}
 
-	ack := &types.FileWriteAckFrame{
-		Type:    ipc.FileWriteAckType,
-		WriteID: writeID,
-		OK:      ok,
+	if err := e.control.FileWriteAck(writeID, ok, errMsg); err != nil {
+		// EPIPE or similar — executor may have exited. Non-fatal.
+		e.logger.Warn("failed to write file_write_ack (executor may have exited)", map[string]any{
+			"write_id": writeID,
+			"error":    err.Error(),
+		})
 	}
-	if errMsg != "" {
-		ack.Error = &errMsg
+}
+
+// rotateProxy handles a rotate_proxy event by selecting a fresh endpoint and
+// sending it to the executor. No-op without a rotator or control channel. If the
+// rotator returns the endpoint already in use, or one on another proxy server
+// (which the launched browser cannot switch to), nothing is sent.
+func (e *IngestionEngine) rotateProxy(envelope *types.EventEnvelope) {
+	if e.proxyRotator == nil || e.control == nil {
+		return
 	}
 
-	frame, err := ipc.EncodeFileWriteAck(ack)
+	next, err := e.proxyRotator.Rotate(e.currentProxy)
 	if err != nil {
-		e.logger.Warn("failed to encode file_write_ack", map[string]any{
-			"write_id": writeID,
-			"error":    err.Error(),
+		e.logger.Warn("proxy rotation failed", map[string]any{
+			"seq":   envelope.Seq,
+			"error": err.Error(),
+		})
+		return
+	}
+	if next == nil || (e.currentProxy != nil && sameProxyEndpoint(next, e.currentProxy)) {
+		e.logger.Warn("proxy rotation found no alternative endpoint", map[string]any{
+			"seq": envelope.Seq,
+		})
+		return
+	}
+	if e.currentProxy != nil && !sameProxyServer(next, e.currentProxy) {
+		e.logger.Warn("proxy rotation skipped: endpoint is on another proxy server, fixed at browser launch", map[string]any{
+			"seq":  envelope.Seq,
+			"host": next.Host,
+			"port": next.Port,
 		})
 		return
 	}
 
-	if _, err := e.ackWriter.Write(frame); err != nil {
+	reason, _ := envelope.Payload["reason"].(string)
+	if err := e.control.ProxyUpdate(*next, reason); err != nil {
 		// EPIPE or similar — executor may have exited. Non-fatal.
-		e.logger.Warn("failed to write file_write_ack (executor may have exited)", map[string]any{
-			"write_id": writeID,
-			"error":    err.Error(),
+		e.logger.Warn("failed to write proxy_update (executor may have exited)", map[string]any{
+			"seq":   envelope.Seq,
+			"error": err.Error(),
 		})
+		return
 	}
+
+	e.currentProxy = next
+	e.collector.IncProxyRotations()
+	e.logger.Info("proxy rotated", map[string]any{
+		"seq":  envelope.Seq,
+		"host": next.Host,
+		"port": next.Port,
+	})
+}
+
+// sameProxyEndpoint reports whether a and b dial the same proxy as the
+// same user.
+func sameProxyEndpoint(a, b *types.ProxyEndpoint) bool {
+	return sameProxyServer(a, b) &&
+		(a.Username == nil) == (b.Username == nil) &&
+		(a.Username == nil || *a.Username == *b.Username)
+}
+
+// sameProxyServer reports whether a and b dial the same proxy server,
+// regardless of credentials.
+func sameProxyServer(a, b *types.ProxyEndpoint) bool {
+	return a.Protocol == b.Protocol && a.Host == b.Host && a.Port == b.Port
 }
 
 // GetRunResult returns the run result frame if received.
@@ -5,13 +5,21 @@ import (
 	"context"
 	"encoding/binary"
 	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	lodepkg "github.com/pithecene-io/lode/lode"
 	"github.com/vmihailenco/msgpack/v5"
 
+	"github.com/pithecene-io/quarry/clock"
+	"github.com/pithecene-io/quarry/ipc"
 	"github.com/pithecene-io/quarry/lode"
 	"github.com/pithecene-io/quarry/log"
+	"github.com/pithecene-io/quarry/metrics"
 	"github.com/pithecene-io/quarry/policy"
 	"github.com/pithecene-io/quarry/types"
 )
@@ -176,6 +184,947 @@ func TestIngestionEngine_SequenceViolation(t *testing.T) {
 	}
 }
 
+// seqLogEnvelope builds a valid log envelope for run-123 with the given seq.
+func seqLogEnvelope(seq int64) *types.EventEnvelope {
+	return &types.EventEnvelope{
+		ContractVersion: types.ContractVersion,
+		EventID:         fmt.Sprintf("evt-%d", seq),
+		RunID:           "run-123",
+		Seq:             seq,
+		Type:            types.EventTypeLog,
+		Ts:              "2024-01-01T00:00:00Z",
+		Payload:         map[string]any{"level": "info", "message": "test"},
+		Attempt:         1,
+	}
+}
+
+func TestIngestionEngine_AllowSeqGaps(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	tests := []struct {
+		name     string
+		seqs     []int64
+		wantErr  bool
+		wantGaps int64
+		wantSeq  int64
+	}{
+		{name: "contiguous", seqs: []int64{1, 2, 3}, wantGaps: 0, wantSeq: 3},
+		{name: "single forward gap", seqs: []int64{1, 4, 5}, wantGaps: 1, wantSeq: 5},
+		{name: "gap before first event", seqs: []int64{3, 4}, wantGaps: 1, wantSeq: 4},
+		{name: "multiple gaps", seqs: []int64{1, 3, 7}, wantGaps: 2, wantSeq: 7},
+		{name: "duplicate seq stays fatal", seqs: []int64{1, 2, 2}, wantErr: true, wantGaps: 0, wantSeq: 2},
+		{name: "backward seq stays fatal", seqs: []int64{1, 5, 3}, wantErr: true, wantGaps: 1, wantSeq: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			for _, seq := range tt.seqs {
+				buf.Write(encodeEventFrame(seqLogEnvelope(seq)))
+			}
+
+			collector := metrics.NewCollector("buffered", "test", "fs", "run-123", "")
+			engine := NewIngestionEngine(&buf, policy.NewNoopPolicy(), NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, collector, nil, nil)
+			engine.SetAllowSeqGaps(true)
+
+			err := engine.Run(t.Context())
+			if tt.wantErr {
+				var ingErr *IngestionError
+				if !errors.As(err, &ingErr) || ingErr.Kind != IngestionErrorStream {
+					t.Fatalf("expected stream error, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := collector.Snapshot().SeqGaps; got != tt.wantGaps {
+				t.Errorf("SeqGaps = %d, want %d", got, tt.wantGaps)
+			}
+			if got := engine.CurrentSeq(); got != tt.wantSeq {
+				t.Errorf("CurrentSeq = %d, want %d", got, tt.wantSeq)
+			}
+		})
+	}
+}
+
+func TestIngestionEngine_SeqGapFatalByDefault(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	var buf bytes.Buffer
+	buf.Write(encodeEventFrame(seqLogEnvelope(1)))
+	buf.Write(encodeEventFrame(seqLogEnvelope(3)))
+
+	collector := metrics.NewCollector("strict", "test", "fs", "run-123", "")
+	engine := NewIngestionEngine(&buf, policy.NewNoopPolicy(), NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, collector, nil, nil)
+
+	if err := engine.Run(t.Context()); err == nil {
+		t.Fatal("expected sequence violation without AllowSeqGaps")
+	}
+	if got := collector.Snapshot().SeqGaps; got != 0 {
+		t.Errorf("SeqGaps = %d, want 0", got)
+	}
+}
+
+func TestIngestionEngine_TimeToFirstEvent(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	var buf bytes.Buffer
+	buf.Write(encodeEventFrame(seqLogEnvelope(1)))
+	buf.Write(encodeEventFrame(seqLogEnvelope(2)))
+
+	collector := metrics.NewCollector("strict", "test", "fs", "run-123", "")
+	engine := NewIngestionEngine(&buf, policy.NewNoopPolicy(), NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, collector, nil, nil)
+	engine.SetLaunchTime(time.Now().Add(-200 * time.Millisecond))
+
+	if err := engine.Run(t.Context()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	hist := collector.Snapshot().TimeToFirstEvent
+	if hist.Count != 1 {
+		t.Fatalf("TimeToFirstEvent count = %d, want 1 (first frame only)", hist.Count)
+	}
+	if hist.Sum < 200*time.Millisecond {
+		t.Errorf("TimeToFirstEvent = %s, want >= 200ms", hist.Sum)
+	}
+}
+
+func TestIngestionEngine_MaxEventBytes(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	huge := seqLogEnvelope(2)
+	huge.Type = types.EventTypeItem
+	huge.Payload = map[string]any{"item_type": "product", "data": strings.Repeat("x", 4096)}
+
+	var buf bytes.Buffer
+	buf.Write(encodeEventFrame(seqLogEnvelope(1)))
+	buf.Write(encodeEventFrame(huge))
+
+	engine := NewIngestionEngine(&buf, policy.NewNoopPolicy(), NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, nil, nil, nil)
+	engine.SetMaxEventBytes(1024)
+
+	err := engine.Run(t.Context())
+	if !IsStreamError(err) || !errors.Is(err, ErrEventTooLarge) {
+		t.Fatalf("expected stream error wrapping ErrEventTooLarge, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "item event") || !strings.Contains(err.Error(), "limit 1024") {
+		t.Errorf("error should name the event type and sizes, got %q", err.Error())
+	}
+	if got := engine.CurrentSeq(); got != 1 {
+		t.Errorf("CurrentSeq = %d, want 1 (oversized event not accepted)", got)
+	}
+
+	// Disabled by default: the same stream is accepted
+	buf.Reset()
+	buf.Write(encodeEventFrame(seqLogEnvelope(1)))
+	buf.Write(encodeEventFrame(huge))
+	engine = NewIngestionEngine(&buf, policy.NewNoopPolicy(), NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, nil, nil, nil)
+	if err := engine.Run(t.Context()); err != nil {
+		t.Fatalf("unexpected error without a limit: %v", err)
+	}
+}
+
+func TestIngestionEngine_MaxRunBytes(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	var buf bytes.Buffer
+	buf.Write(encodeEventFrame(seqLogEnvelope(1)))
+	for seq := int64(1); seq <= 3; seq++ {
+		chunk, _ := msgpack.Marshal(&types.ArtifactChunkFrame{
+			Type: "artifact_chunk", ArtifactID: "art-1", Seq: seq, Data: bytes.Repeat([]byte("x"), 600), IsLast: seq == 3,
+		})
+		buf.Write(encodeFrame(chunk))
+	}
+	buf.Write(encodeEventFrame(seqLogEnvelope(2)))
+
+	pol := &streamRecordingPolicy{NoopPolicy: policy.NewNoopPolicy()}
+	engine := NewIngestionEngine(&buf, pol, NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, nil, nil, nil)
+	engine.SetMaxRunBytes(1024)
+
+	err := engine.Run(t.Context())
+	if !IsPolicyError(err) || !errors.Is(err, ErrRunQuotaExceeded) {
+		t.Fatalf("expected policy error wrapping ErrRunQuotaExceeded, got %v", err)
+	}
+	if got := ReasonFromIngestionError(err); got != types.ReasonQuotaExceeded {
+		t.Errorf("reason = %s, want quota_exceeded", got)
+	}
+	// The crossing chunk is persisted; nothing after it is ingested.
+	if pol.chunks != 2 || len(pol.eventTypes) != 1 {
+		t.Errorf("policy saw %d chunks and %d events, want 2 and 1", pol.chunks, len(pol.eventTypes))
+	}
+	if engine.RunBytes() <= 1024 {
+		t.Errorf("RunBytes = %d, want > 1024", engine.RunBytes())
+	}
+}
+
+func TestIngestionEngine_TelemetryMode_RelaxesSeq(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	// Out of order, duplicated, and no terminal event
+	var buf bytes.Buffer
+	for _, seq := range []int64{2, 1, 5, 5, 3} {
+		buf.Write(encodeEventFrame(seqLogEnvelope(seq)))
+	}
+
+	engine := NewIngestionEngine(&buf, policy.NewNoopPolicy(), NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, nil, nil, nil)
+	engine.SetTelemetryMode(true)
+	if err := engine.Run(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := engine.CurrentSeq(); got != 5 {
+		t.Errorf("CurrentSeq = %d, want 5", got)
+	}
+	if engine.HasTerminal() {
+		t.Error("no terminal event was sent")
+	}
+}
+
+func TestIngestionEngine_TelemetryMode_RejectsData(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	item := seqLogEnvelope(2)
+	item.Type = types.EventTypeItem
+	item.Payload = map[string]any{"item_type": "product", "data": map[string]any{"id": 1}}
+	chunk, _ := msgpack.Marshal(&types.ArtifactChunkFrame{
+		Type: "artifact_chunk", ArtifactID: "art-1", Seq: 1, Data: []byte("x"), IsLast: true,
+	})
+	file := encodeFileWriteFrame(&types.FileWriteFrame{
+		Type: "file_write", WriteID: 1, Filename: "a.txt", ContentType: "text/plain", Data: []byte("x"),
+	})
+
+	tests := []struct {
+		name  string
+		frame []byte
+	}{
+		{name: "item event", frame: encodeEventFrame(item)},
+		{name: "artifact chunk", frame: encodeFrame(chunk)},
+		{name: "file write", frame: file},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			buf.Write(encodeEventFrame(seqLogEnvelope(1)))
+			buf.Write(tt.frame)
+
+			engine := NewIngestionEngine(&buf, policy.NewNoopPolicy(), NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, nil, nil, nil)
+			engine.SetTelemetryMode(true)
+			err := engine.Run(t.Context())
+			if !errors.Is(err, ErrTelemetryViolation) || !IsStreamError(err) {
+				t.Fatalf("expected telemetry violation stream error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestIngestionEngine_InvalidArtifactRetention(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	chunk, _ := msgpack.Marshal(&types.ArtifactChunkFrame{
+		Type: "artifact_chunk", ArtifactID: "art-1", Seq: 1, Data: []byte("x"), IsLast: true,
+	})
+	commit := seqLogEnvelope(1)
+	commit.Type = types.EventTypeArtifact
+	commit.Payload = map[string]any{
+		"artifact_id": "art-1", "name": "a.png", "content_type": "image/png", "size_bytes": int64(1),
+		"ttl_seconds": int64(-1),
+	}
+
+	var buf bytes.Buffer
+	buf.Write(encodeFrame(chunk))
+	buf.Write(encodeEventFrame(commit))
+
+	engine := NewIngestionEngine(&buf, policy.NewNoopPolicy(), NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, nil, nil, nil)
+	err := engine.Run(t.Context())
+	if !errors.Is(err, lode.ErrInvalidRetention) || !IsStreamError(err) {
+		t.Fatalf("expected invalid retention stream error, got %v", err)
+	}
+}
+
+func TestIngestionEngine_ArtifactObserver(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	chunk, _ := msgpack.Marshal(&types.ArtifactChunkFrame{
+		Type: "artifact_chunk", ArtifactID: "art-1", Seq: 1, Data: []byte("png!"), IsLast: true,
+	})
+	commit := seqLogEnvelope(1)
+	commit.Type = types.EventTypeArtifact
+	commit.Payload = map[string]any{
+		"artifact_id": "art-1", "name": "shot.png", "content_type": "image/png", "size_bytes": int64(4),
+	}
+	complete := seqLogEnvelope(2)
+	complete.Type = types.EventTypeRunComplete
+	complete.Payload = map[string]any{}
+
+	var buf bytes.Buffer
+	buf.Write(encodeFrame(chunk))
+	buf.Write(encodeEventFrame(commit))
+	buf.Write(encodeEventFrame(complete))
+
+	var commits []ArtifactCommit
+	sink := policy.NewStubSink()
+	engine := NewIngestionEngine(&buf, policy.NewStrictPolicy(sink), NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, nil, nil, nil)
+	engine.SetArtifactObserver(func(c ArtifactCommit) {
+		if sink.ChunksWritten != 1 {
+			t.Errorf("observer called with %d chunks written, want 1", sink.ChunksWritten)
+		}
+		commits = append(commits, c)
+	})
+	if err := engine.Run(t.Context()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	want := ArtifactCommit{ArtifactID: "art-1", Name: "shot.png", ContentType: "image/png", SizeBytes: 4}
+	if len(commits) != 1 || commits[0] != want {
+		t.Errorf("commits = %+v, want [%+v]", commits, want)
+	}
+}
+
+// artifactCommitStream is a commit event followed by its only chunk, so the
+// commit hook fires on the chunk, before the policy has written it.
+func artifactCommitStream(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	commit := seqLogEnvelope(1)
+	commit.Type = types.EventTypeArtifact
+	commit.Payload = map[string]any{
+		"artifact_id": "art-1", "name": "shot.png", "content_type": "image/png", "size_bytes": int64(4),
+	}
+	chunk, _ := msgpack.Marshal(&types.ArtifactChunkFrame{
+		Type: "artifact_chunk", ArtifactID: "art-1", Seq: 1, Data: []byte("png!"), IsLast: true,
+	})
+	complete := seqLogEnvelope(2)
+	complete.Type = types.EventTypeRunComplete
+	complete.Payload = map[string]any{}
+
+	var buf bytes.Buffer
+	buf.Write(encodeEventFrame(commit))
+	buf.Write(encodeFrame(chunk))
+	buf.Write(encodeEventFrame(complete))
+	return &buf
+}
+
+func TestIngestionEngine_ArtifactObserver_NotCalledWhenSinkFails(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	sink := policy.NewStubSink()
+	sink.ErrorOnWrite = errors.New("bucket unavailable")
+	var commits []ArtifactCommit
+	engine := NewIngestionEngine(artifactCommitStream(t), policy.NewStrictPolicy(sink), NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, nil, nil, nil)
+	engine.SetArtifactObserver(func(c ArtifactCommit) { commits = append(commits, c) })
+
+	if err := engine.Run(t.Context()); err == nil {
+		t.Fatal("expected policy error from failing sink")
+	}
+	engine.releaseArtifactCommits()
+	if len(commits) != 0 {
+		t.Errorf("commits = %+v, want none: the chunk was never written", commits)
+	}
+}
+
+func TestIngestionEngine_ArtifactObserver_WaitsForFlush(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	sink := policy.NewStubSink()
+	pol, err := policy.NewBufferedPolicy(sink, policy.BufferedConfig{MaxBufferEvents: 100, MaxBufferBytes: 1 << 20})
+	if err != nil {
+		t.Fatalf("NewBufferedPolicy: %v", err)
+	}
+	var commits []ArtifactCommit
+	engine := NewIngestionEngine(artifactCommitStream(t), pol, NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, nil, nil, nil)
+	engine.SetArtifactObserver(func(c ArtifactCommit) { commits = append(commits, c) })
+
+	if err := engine.Run(t.Context()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(commits) != 0 {
+		t.Fatalf("commits = %+v before flush, want none", commits)
+	}
+
+	if err := pol.Flush(t.Context()); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	engine.releaseArtifactCommits()
+	if len(commits) != 1 || commits[0].ArtifactID != "art-1" {
+		t.Errorf("commits = %+v after flush, want art-1", commits)
+	}
+}
+
+func TestIngestionEngine_SniffContentType(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	chunk, _ := msgpack.Marshal(&types.ArtifactChunkFrame{
+		Type: "artifact_chunk", ArtifactID: "art-1", Seq: 1, Data: []byte("%PDF-1.7\n"), IsLast: true,
+	})
+	commit := seqLogEnvelope(1)
+	commit.Type = types.EventTypeArtifact
+	commit.Payload = map[string]any{
+		"artifact_id": "art-1", "name": "report", "content_type": "application/octet-stream", "size_bytes": int64(9),
+	}
+	complete := seqLogEnvelope(2)
+	complete.Type = types.EventTypeRunComplete
+	complete.Payload = map[string]any{}
+
+	var buf bytes.Buffer
+	buf.Write(encodeFrame(chunk))
+	buf.Write(encodeEventFrame(commit))
+	buf.Write(encodeEventFrame(complete))
+
+	artifacts := NewArtifactManager()
+	artifacts.SetSniffContentType(true)
+	sink := policy.NewStubSink()
+	var commits []ArtifactCommit
+	engine := NewIngestionEngine(&buf, policy.NewStrictPolicy(sink), artifacts, nil, log.NewLogger(runMeta), runMeta, nil, nil, nil)
+	engine.SetArtifactObserver(func(c ArtifactCommit) { commits = append(commits, c) })
+	if err := engine.Run(t.Context()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// The persisted event carries the sniffed type and the declared one
+	payload := sink.WrittenEvents[0].Payload
+	if payload["content_type"] != "application/pdf" || payload["declared_content_type"] != "application/octet-stream" {
+		t.Errorf("payload = %v, want sniffed application/pdf beside the declared type", payload)
+	}
+	if len(commits) != 1 || commits[0].ContentType != "application/pdf" {
+		t.Errorf("commits = %+v, want the sniffed type", commits)
+	}
+}
+
+func TestIngestionEngine_StallTimeout(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	// One frame, then the writer goes silent without closing the pipe
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	go func() {
+		_, _ = pw.Write(encodeEventFrame(seqLogEnvelope(1)))
+	}()
+
+	collector := metrics.NewCollector("strict", "test", "fs", "run-123", "")
+	engine := NewIngestionEngine(pr, policy.NewNoopPolicy(), NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, collector, nil, nil)
+	engine.SetStallTimeout(50 * time.Millisecond)
+
+	err := engine.Run(t.Context())
+	if !errors.Is(err, ErrStreamStalled) {
+		t.Fatalf("expected ErrStreamStalled, got %v", err)
+	}
+	if !IsStreamError(err) {
+		t.Error("stall should be a stream error")
+	}
+	if engine.CurrentSeq() != 1 {
+		t.Errorf("CurrentSeq = %d, want 1", engine.CurrentSeq())
+	}
+	if got := collector.Snapshot().ExecutorCrash; got != 1 {
+		t.Errorf("ExecutorCrash = %d, want 1", got)
+	}
+}
+
+func TestIngestionEngine_StallTimeoutFakeClock(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+	pr, pw := io.Pipe()
+	defer pw.Close()
+
+	clk := clock.NewFake(time.Date(2026, 2, 23, 23, 59, 0, 0, time.UTC))
+	engine := NewIngestionEngine(pr, policy.NewNoopPolicy(), NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, nil, nil, nil)
+	engine.SetClock(clk)
+	engine.SetStallTimeout(time.Hour)
+
+	done := make(chan error, 1)
+	go func() { done <- engine.Run(t.Context()) }()
+
+	// The watchdog fires on the clock, not after an hour of real time
+	clk.BlockUntil(1)
+	clk.Advance(time.Hour)
+	if err := <-done; !errors.Is(err, ErrStreamStalled) {
+		t.Fatalf("expected ErrStreamStalled, got %v", err)
+	}
+}
+
+func TestIngestionEngine_StallTimeoutResetsPerFrame(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	// Total stream time exceeds the stall timeout, but no single gap does
+	pr, pw := io.Pipe()
+	go func() {
+		for seq := int64(1); seq <= 5; seq++ {
+			time.Sleep(30 * time.Millisecond)
+			_, _ = pw.Write(encodeEventFrame(seqLogEnvelope(seq)))
+		}
+		_ = pw.Close()
+	}()
+
+	engine := NewIngestionEngine(pr, policy.NewNoopPolicy(), NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, nil, nil, nil)
+	engine.SetStallTimeout(100 * time.Millisecond)
+
+	if err := engine.Run(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if engine.CurrentSeq() != 5 {
+		t.Errorf("CurrentSeq = %d, want 5", engine.CurrentSeq())
+	}
+}
+
+func TestIngestionEngine_StallAfterTerminal(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	terminal := seqLogEnvelope(1)
+	terminal.EventID = "evt-complete"
+	terminal.Type = types.EventTypeRunComplete
+	terminal.Payload = map[string]any{}
+
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	go func() {
+		_, _ = pw.Write(encodeEventFrame(terminal))
+	}()
+
+	engine := NewIngestionEngine(pr, policy.NewNoopPolicy(), NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, nil, nil, nil)
+	engine.SetStallTimeout(50 * time.Millisecond)
+
+	if err := engine.Run(t.Context()); err != nil {
+		t.Fatalf("stall after terminal should not be an error, got %v", err)
+	}
+	if !engine.Stalled() {
+		t.Error("Stalled() = false, want true")
+	}
+	if !engine.HasTerminal() {
+		t.Error("terminal event should be recorded")
+	}
+}
+
+// signalingPolicy signals on every ingested event.
+type signalingPolicy struct {
+	*policy.NoopPolicy
+	ingested chan struct{}
+}
+
+func (p *signalingPolicy) IngestEvent(ctx context.Context, envelope *types.EventEnvelope) error {
+	p.ingested <- struct{}{}
+	return p.NoopPolicy.IngestEvent(ctx, envelope)
+}
+
+func TestIngestionEngine_DrainBeforeTerminal(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	// One frame, then the writer goes silent without closing the pipe
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	go func() {
+		_, _ = pw.Write(encodeEventFrame(seqLogEnvelope(1)))
+	}()
+
+	pol := &signalingPolicy{NoopPolicy: policy.NewNoopPolicy(), ingested: make(chan struct{}, 1)}
+	drain := make(chan struct{})
+	engine := NewIngestionEngine(pr, pol, NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, nil, nil, nil)
+	engine.SetDrain(drain)
+
+	done := make(chan error, 1)
+	go func() { done <- engine.Run(t.Context()) }()
+
+	<-pol.ingested
+	close(drain)
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrDrained) {
+			t.Fatalf("expected ErrDrained, got %v", err)
+		}
+		if !IsCanceledError(err) {
+			t.Error("drain should be a canceled error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after drain")
+	}
+	if !engine.Drained() {
+		t.Error("Drained() = false, want true")
+	}
+	if engine.CurrentSeq() != 1 {
+		t.Errorf("CurrentSeq = %d, want 1 (in-flight frame processed)", engine.CurrentSeq())
+	}
+}
+
+func TestIngestionEngine_DrainAfterTerminalReadsToEOF(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	terminal := seqLogEnvelope(1)
+	terminal.Type = types.EventTypeRunComplete
+	terminal.Payload = map[string]any{}
+
+	pr, pw := io.Pipe()
+	go func() {
+		_, _ = pw.Write(encodeEventFrame(terminal))
+	}()
+
+	pol := &signalingPolicy{NoopPolicy: policy.NewNoopPolicy(), ingested: make(chan struct{}, 1)}
+	drain := make(chan struct{})
+	engine := NewIngestionEngine(pr, pol, NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, nil, nil, nil)
+	engine.SetDrain(drain)
+
+	done := make(chan error, 1)
+	go func() { done <- engine.Run(t.Context()) }()
+
+	<-pol.ingested
+	close(drain)
+	_ = pw.Close()
+
+	if err := <-done; err != nil {
+		t.Fatalf("drain after terminal should read to EOF, got %v", err)
+	}
+	if engine.Drained() {
+		t.Error("Drained() = true, want false after terminal")
+	}
+	if !engine.HasTerminal() {
+		t.Error("terminal event should be recorded")
+	}
+}
+
+// capturingPolicy records the payload of every event it ingests.
+type capturingPolicy struct {
+	*policy.NoopPolicy
+	payloads []map[string]any
+}
+
+func (p *capturingPolicy) IngestEvent(ctx context.Context, envelope *types.EventEnvelope) error {
+	p.payloads = append(p.payloads, envelope.Payload)
+	return p.NoopPolicy.IngestEvent(ctx, envelope)
+}
+
+func TestIngestionEngine_Redaction(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	item := seqLogEnvelope(1)
+	item.Type = types.EventTypeItem
+	item.Payload = map[string]any{
+		"item_type": "user",
+		"data":      map[string]any{"email": "a@example.com", "name": "A"},
+	}
+	enqueue := seqLogEnvelope(2)
+	enqueue.Type = types.EventTypeEnqueue
+	enqueue.Payload = map[string]any{
+		"target": "child.ts",
+		"params": map[string]any{"email": "b@example.com"},
+	}
+
+	var buf bytes.Buffer
+	buf.Write(encodeEventFrame(item))
+	buf.Write(encodeEventFrame(enqueue))
+
+	redactor, err := NewRedactor([]string{"email"})
+	if err != nil {
+		t.Fatalf("NewRedactor: %v", err)
+	}
+
+	// Observer must see the redacted payload too
+	var observed map[string]any
+	observer := func(env *types.EventEnvelope) {
+		observed = env.Payload
+	}
+
+	pol := &capturingPolicy{NoopPolicy: policy.NewNoopPolicy()}
+	engine := NewIngestionEngine(&buf, pol, NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, nil, observer, nil)
+	engine.SetRedactor(redactor)
+
+	if err := engine.Run(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(pol.payloads) != 2 {
+		t.Fatalf("policy saw %d events, want 2", len(pol.payloads))
+	}
+	data, _ := pol.payloads[0]["data"].(map[string]any)
+	if data["email"] != RedactedValue {
+		t.Errorf("item email = %v, want %q", data["email"], RedactedValue)
+	}
+	if data["name"] != "A" {
+		t.Errorf("item name = %v, want unchanged", data["name"])
+	}
+	params, _ := observed["params"].(map[string]any)
+	if params["email"] != RedactedValue {
+		t.Errorf("observer saw email = %v, want %q", params["email"], RedactedValue)
+	}
+	if got := engine.RedactedFields(); got != 2 {
+		t.Errorf("RedactedFields = %d, want 2", got)
+	}
+}
+
+// streamRecordingPolicy records ingested event types and chunk count.
+type streamRecordingPolicy struct {
+	*policy.NoopPolicy
+	eventTypes []types.EventType
+	chunks     int
+}
+
+func (p *streamRecordingPolicy) IngestEvent(ctx context.Context, envelope *types.EventEnvelope) error {
+	p.eventTypes = append(p.eventTypes, envelope.Type)
+	return p.NoopPolicy.IngestEvent(ctx, envelope)
+}
+
+func (p *streamRecordingPolicy) IngestArtifactChunk(ctx context.Context, chunk *types.ArtifactChunk) error {
+	p.chunks++
+	return p.NoopPolicy.IngestArtifactChunk(ctx, chunk)
+}
+
+// mixedStream returns item, artifact chunk + commit, enqueue, and run_complete frames.
+func mixedStream() []byte {
+	var buf bytes.Buffer
+	item := seqLogEnvelope(1)
+	item.Type = types.EventTypeItem
+	buf.Write(encodeEventFrame(item))
+
+	chunk, _ := msgpack.Marshal(&types.ArtifactChunkFrame{
+		Type: "artifact_chunk", ArtifactID: "art-1", Seq: 1, Data: []byte("test data"), IsLast: true,
+	})
+	buf.Write(encodeFrame(chunk))
+
+	commit := seqLogEnvelope(2)
+	commit.Type = types.EventTypeArtifact
+	commit.Payload = map[string]any{"artifact_id": "art-1", "name": "a.txt", "content_type": "text/plain", "size_bytes": int64(9)}
+	buf.Write(encodeEventFrame(commit))
+
+	enqueue := seqLogEnvelope(3)
+	enqueue.Type = types.EventTypeEnqueue
+	enqueue.Payload = map[string]any{"target": "child.ts", "params": map[string]any{}}
+	buf.Write(encodeEventFrame(enqueue))
+
+	complete := seqLogEnvelope(4)
+	complete.Type = types.EventTypeRunComplete
+	complete.Payload = map[string]any{}
+	buf.Write(encodeEventFrame(complete))
+	return buf.Bytes()
+}
+
+func TestIngestionEngine_IngestMode(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	tests := []struct {
+		name               string
+		mode               IngestMode
+		wantTypes          []types.EventType
+		wantChunks         int
+		wantArtifactsDisc  int64
+		wantEventsDisc     int64
+		wantCommittedStats int
+	}{
+		{
+			name:               "all",
+			mode:               IngestAll,
+			wantTypes:          []types.EventType{types.EventTypeItem, types.EventTypeArtifact, types.EventTypeEnqueue, types.EventTypeRunComplete},
+			wantChunks:         1,
+			wantCommittedStats: 1,
+		},
+		{
+			name:              "events only",
+			mode:              IngestEventsOnly,
+			wantTypes:         []types.EventType{types.EventTypeItem, types.EventTypeEnqueue, types.EventTypeRunComplete},
+			wantArtifactsDisc: 1,
+		},
+		{
+			name:               "artifacts only",
+			mode:               IngestArtifactsOnly,
+			wantTypes:          []types.EventType{types.EventTypeArtifact, types.EventTypeRunComplete},
+			wantChunks:         1,
+			wantEventsDisc:     2,
+			wantCommittedStats: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var enqueues int
+			observer := func(*types.EventEnvelope) { enqueues++ }
+
+			pol := &streamRecordingPolicy{NoopPolicy: policy.NewNoopPolicy()}
+			artifacts := NewArtifactManager()
+			collector := metrics.NewCollector("strict", "test", "fs", "run-123", "")
+			engine := NewIngestionEngine(bytes.NewReader(mixedStream()), pol, artifacts, nil, log.NewLogger(runMeta), runMeta, collector, observer, nil)
+			engine.SetIngestMode(tt.mode)
+
+			if err := engine.Run(t.Context()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if fmt.Sprint(pol.eventTypes) != fmt.Sprint(tt.wantTypes) {
+				t.Errorf("policy saw %v, want %v", pol.eventTypes, tt.wantTypes)
+			}
+			if pol.chunks != tt.wantChunks {
+				t.Errorf("policy saw %d chunks, want %d", pol.chunks, tt.wantChunks)
+			}
+			if got := int(artifacts.Stats().CommittedArtifacts); got != tt.wantCommittedStats {
+				t.Errorf("committed artifacts = %d, want %d", got, tt.wantCommittedStats)
+			}
+			if enqueues != 1 {
+				t.Errorf("observer saw %d enqueues, want 1 (scheduling is unaffected by mode)", enqueues)
+			}
+			if !engine.HasTerminal() {
+				t.Error("terminal event should be recorded in every mode")
+			}
+
+			snap := collector.Snapshot()
+			if engine.ArtifactsDiscarded() != tt.wantArtifactsDisc || snap.ArtifactsDiscarded != tt.wantArtifactsDisc {
+				t.Errorf("artifacts discarded = %d (metric %d), want %d", engine.ArtifactsDiscarded(), snap.ArtifactsDiscarded, tt.wantArtifactsDisc)
+			}
+			if engine.EventsDiscarded() != tt.wantEventsDisc || snap.EventsDiscarded != tt.wantEventsDisc {
+				t.Errorf("events discarded = %d (metric %d), want %d", engine.EventsDiscarded(), snap.EventsDiscarded, tt.wantEventsDisc)
+			}
+		})
+	}
+}
+
+func TestIngestionEngine_LogMinLevel(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	var buf bytes.Buffer
+	levels := []string{"debug", "info", "warn", "error", "debug", "verbose"}
+	for i, level := range levels {
+		env := seqLogEnvelope(int64(i + 1))
+		env.Payload = map[string]any{"level": level, "message": "m"}
+		buf.Write(encodeEventFrame(env))
+	}
+	item := seqLogEnvelope(int64(len(levels) + 1))
+	item.Type = types.EventTypeItem
+	item.Payload = map[string]any{"level": "debug"}
+	buf.Write(encodeEventFrame(item))
+
+	pol := &streamRecordingPolicy{NoopPolicy: policy.NewNoopPolicy()}
+	collector := metrics.NewCollector("strict", "test", "fs", "run-123", "")
+	engine := NewIngestionEngine(&buf, pol, NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, collector, nil, nil)
+	engine.SetLogMinLevel(types.LogLevelWarn)
+
+	if err := engine.Run(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// warn, error, the unknown level, and the non-log item pass through
+	want := []types.EventType{types.EventTypeLog, types.EventTypeLog, types.EventTypeLog, types.EventTypeItem}
+	if fmt.Sprint(pol.eventTypes) != fmt.Sprint(want) {
+		t.Errorf("policy saw %v, want %v", pol.eventTypes, want)
+	}
+	if engine.LogsFiltered() != 3 {
+		t.Errorf("LogsFiltered = %d, want 3", engine.LogsFiltered())
+	}
+	snap := collector.Snapshot()
+	if snap.LogFilteredByLevel["debug"] != 2 || snap.LogFilteredByLevel["info"] != 1 || len(snap.LogFilteredByLevel) != 2 {
+		t.Errorf("log_filtered_total = %v, want debug=2 info=1", snap.LogFilteredByLevel)
+	}
+	if snap.EventsDropped != 0 {
+		t.Errorf("filtered logs must not count as policy drops, got %d", snap.EventsDropped)
+	}
+}
+
+func TestIngestionEngine_SampleRate(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+	const items, rate = 200, 0.25
+
+	var buf bytes.Buffer
+	wantKept := 0
+	for seq := int64(1); seq <= items; seq++ {
+		env := seqLogEnvelope(seq)
+		env.Type = types.EventTypeItem
+		env.Payload = map[string]any{"item_type": "product"}
+		buf.Write(encodeEventFrame(env))
+		if sampleFraction(env.EventID) < rate {
+			wantKept++
+		}
+	}
+	checkpoint := seqLogEnvelope(items + 1)
+	checkpoint.Type = types.EventTypeCheckpoint
+	checkpoint.Payload = map[string]any{"checkpoint_id": "cp-1"}
+	buf.Write(encodeEventFrame(checkpoint))
+	terminal := seqLogEnvelope(items + 2)
+	terminal.Type = types.EventTypeRunComplete
+	terminal.Payload = map[string]any{}
+	buf.Write(encodeEventFrame(terminal))
+
+	// The hash spreads event IDs roughly evenly
+	if wantKept < items/8 || wantKept > items/2 {
+		t.Fatalf("sample kept %d of %d items at rate %v", wantKept, items, rate)
+	}
+
+	pol := &streamRecordingPolicy{NoopPolicy: policy.NewNoopPolicy()}
+	collector := metrics.NewCollector("strict", "test", "fs", "run-123", "")
+	engine := NewIngestionEngine(&buf, pol, NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, collector, nil, nil)
+	engine.SetSampleRate(rate)
+
+	if err := engine.Run(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	counts := make(map[types.EventType]int)
+	for _, typ := range pol.eventTypes {
+		counts[typ]++
+	}
+	if counts[types.EventTypeItem] != wantKept || counts[types.EventTypeCheckpoint] != 1 || counts[types.EventTypeRunComplete] != 1 {
+		t.Errorf("policy saw %v, want %d items plus the checkpoint and terminal", counts, wantKept)
+	}
+	wantOut := int64(items - wantKept)
+	if snap := collector.Snapshot(); engine.EventsSampledOut() != wantOut || snap.EventsSampledOut != wantOut {
+		t.Errorf("sampled out = %d (metric %d), want %d", engine.EventsSampledOut(), snap.EventsSampledOut, wantOut)
+	}
+	if snap := collector.Snapshot(); snap.EventsDropped != 0 || snap.EventsDiscarded != 0 {
+		t.Errorf("sampled-out items must not count as drops or discards, got %d/%d", snap.EventsDropped, snap.EventsDiscarded)
+	}
+	// Seq is validated over the full stream, sampled or not
+	if engine.CurrentSeq() != items+2 {
+		t.Errorf("CurrentSeq = %d, want %d", engine.CurrentSeq(), items+2)
+	}
+}
+
+func TestIngestionEngine_EventFilter(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	var buf bytes.Buffer
+	seq := int64(0)
+	emit := func(typ types.EventType, payload map[string]any) {
+		seq++
+		env := seqLogEnvelope(seq)
+		env.Type = typ
+		env.Payload = payload
+		buf.Write(encodeEventFrame(env))
+	}
+	emit(types.EventTypeItem, map[string]any{"item_type": "product", "data": map[string]any{}})
+	emit(types.EventTypeItem, map[string]any{"item_type": "review", "data": map[string]any{}})
+	emit(types.EventTypeLog, map[string]any{"level": "info", "message": "hi"})
+	emit(types.EventTypeItem, map[string]any{"item_type": "product", "data": map[string]any{}})
+	emit(types.EventTypeCheckpoint, map[string]any{"checkpoint_id": "cp-1"})
+	emit(types.EventTypeRunComplete, map[string]any{})
+
+	filter, err := NewEventFilter(`type == "item" && payload.item_type == "product"`)
+	if err != nil {
+		t.Fatalf("NewEventFilter: %v", err)
+	}
+	pol := &streamRecordingPolicy{NoopPolicy: policy.NewNoopPolicy()}
+	collector := metrics.NewCollector("strict", "test", "fs", "run-123", "")
+	engine := NewIngestionEngine(&buf, pol, NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, collector, nil, nil)
+	engine.SetEventFilter(filter)
+
+	if err := engine.Run(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []types.EventType{types.EventTypeItem, types.EventTypeItem, types.EventTypeCheckpoint, types.EventTypeRunComplete}
+	if !slices.Equal(pol.eventTypes, want) {
+		t.Errorf("policy saw %v, want %v", pol.eventTypes, want)
+	}
+	if snap := collector.Snapshot(); engine.EventsFiltered() != 2 || snap.EventsFiltered != 2 {
+		t.Errorf("filtered = %d (metric %d), want 2", engine.EventsFiltered(), snap.EventsFiltered)
+	}
+	if snap := collector.Snapshot(); snap.EventsDropped != 0 {
+		t.Errorf("filtered events must not count as drops, got %d", snap.EventsDropped)
+	}
+	if engine.CurrentSeq() != seq {
+		t.Errorf("CurrentSeq = %d, want %d", engine.CurrentSeq(), seq)
+	}
+}
+
+func TestSampleFraction_Deterministic(t *testing.T) {
+	for _, id := range []string{"", "evt-1", "evt-2", "0190f0c2-7d1e-7c4a-9f1b-3a2c5d6e7f80"} {
+		f := sampleFraction(id)
+		if f < 0 || f >= 1 {
+			t.Errorf("sampleFraction(%q) = %v, want [0, 1)", id, f)
+		}
+		if again := sampleFraction(id); again != f {
+			t.Errorf("sampleFraction(%q) not stable: %v then %v", id, f, again)
+		}
+	}
+}
+
 func TestIngestionEngine_FrameDecodeError(t *testing.T) {
 	runMeta := &types.RunMeta{
 		RunID:   "run-123",
@@ -443,6 +1392,31 @@ func TestIngestionEngine_RunResult_WithProxy(t *testing.T) {
 	}
 }
 
+func TestIngestionEngine_RunResult_ExecutorVersionSkew(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	var buf bytes.Buffer
+	buf.Write(encodeRunResultFrame(&types.RunResultFrame{
+		Type:            "run_result",
+		Outcome:         types.RunResultOutcome{Status: types.RunResultStatusCompleted},
+		ExecutorVersion: "0.0.1",
+	}))
+
+	engine := NewIngestionEngine(&buf, policy.NewNoopPolicy(), NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, nil, nil, nil)
+
+	// A differing executor version is a warning, not a failure
+	if err := engine.Run(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result := engine.GetRunResult()
+	if result == nil {
+		t.Fatal("expected run_result to be captured")
+	}
+	if result.ExecutorVersion != "0.0.1" {
+		t.Errorf("ExecutorVersion = %q, want %q", result.ExecutorVersion, "0.0.1")
+	}
+}
+
 func TestIngestionEngine_RunResult_DuplicateIgnored(t *testing.T) {
 	runMeta := &types.RunMeta{
 		RunID:   "run-123",
@@ -749,7 +1723,7 @@ func TestIngestionEngine_FileWrite_SuccessAck(t *testing.T) {
 	var ackBuf bytes.Buffer
 	logger := log.NewLogger(runMeta)
 	fw := lode.NewStubFileWriter()
-	engine := NewIngestionEngine(&buf, policy.NewNoopPolicy(), NewArtifactManager(), fw, logger, runMeta, nil, nil, &ackBuf)
+	engine := NewIngestionEngine(&buf, policy.NewNoopPolicy(), NewArtifactManager(), fw, logger, runMeta, nil, nil, ipc.NewControlWriter(&ackBuf))
 
 	err := engine.Run(t.Context())
 	if err != nil {
@@ -832,7 +1806,7 @@ func TestIngestionEngine_FileWrite_ErrorAck_NoStreamError(t *testing.T) {
 	var ackBuf bytes.Buffer
 	logger := log.NewLogger(runMeta)
 	fw := &failingFileWriter{err: errors.New("S3 PutObject failed: 500")}
-	engine := NewIngestionEngine(&buf, policy.NewNoopPolicy(), NewArtifactManager(), fw, logger, runMeta, nil, nil, &ackBuf)
+	engine := NewIngestionEngine(&buf, policy.NewNoopPolicy(), NewArtifactManager(), fw, logger, runMeta, nil, nil, ipc.NewControlWriter(&ackBuf))
 
 	err := engine.Run(t.Context())
 	// PutFile failure is recoverable — no stream error
@@ -976,7 +1950,7 @@ func TestIngestionEngine_FileWrite_AckWriteEPIPE_NonFatal(t *testing.T) {
 	logger := log.NewLogger(runMeta)
 	fw := lode.NewStubFileWriter()
 	// EPIPE ack writer — should not cause stream error
-	engine := NewIngestionEngine(&buf, policy.NewNoopPolicy(), NewArtifactManager(), fw, logger, runMeta, nil, nil, &epipeWriter{})
+	engine := NewIngestionEngine(&buf, policy.NewNoopPolicy(), NewArtifactManager(), fw, logger, runMeta, nil, nil, ipc.NewControlWriter(&epipeWriter{}))
 
 	err := engine.Run(t.Context())
 	if err != nil {
@@ -1021,7 +1995,7 @@ func TestIngestionEngine_FileWrite_PostTerminal_ErrorAck(t *testing.T) {
 	var ackBuf bytes.Buffer
 	logger := log.NewLogger(runMeta)
 	fw := lode.NewStubFileWriter()
-	engine := NewIngestionEngine(&buf, policy.NewNoopPolicy(), NewArtifactManager(), fw, logger, runMeta, nil, nil, &ackBuf)
+	engine := NewIngestionEngine(&buf, policy.NewNoopPolicy(), NewArtifactManager(), fw, logger, runMeta, nil, nil, ipc.NewControlWriter(&ackBuf))
 
 	err := engine.Run(t.Context())
 	if err != nil {
@@ -1215,7 +2189,7 @@ func TestIngestionEngine_FileWrite_MetadataInventory_EndToEnd(t *testing.T) {
 	// Run ingestion
 	var ackBuf bytes.Buffer
 	logger := log.NewLogger(runMeta)
-	engine := NewIngestionEngine(&buf, pol, NewArtifactManager(), client, logger, runMeta, nil, nil, &ackBuf)
+	engine := NewIngestionEngine(&buf, pol, NewArtifactManager(), client, logger, runMeta, nil, nil, ipc.NewControlWriter(&ackBuf))
 
 	if err := engine.Run(t.Context()); err != nil {
 		t.Fatalf("ingestion failed: %v", err)
@@ -1301,3 +2275,155 @@ func TestIngestionEngine_FileWrite_MetadataInventory_EndToEnd(t *testing.T) {
 		t.Errorf("sidecar_files metadata found on %d snapshots, want exactly 1 (should only appear on item event flush)", metadataCount)
 	}
 }
+
+// stubProxyRotator returns endpoints in order and records what it was asked
+// to replace.
+type stubProxyRotator struct {
+	next     []*types.ProxyEndpoint
+	replaced []string
+}
+
+func (s *stubProxyRotator) Rotate(current *types.ProxyEndpoint) (*types.ProxyEndpoint, error) {
+	s.replaced = append(s.replaced, current.Host)
+	if len(s.next) == 0 {
+		return nil, errors.New("pool exhausted")
+	}
+	ep := s.next[0]
+	s.next = s.next[1:]
+	return ep, nil
+}
+
+func rotateProxyStream(t *testing.T, reasons ...string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	seq := int64(0)
+	for _, reason := range reasons {
+		seq++
+		buf.Write(encodeEventFrame(&types.EventEnvelope{
+			ContractVersion: types.ContractVersion,
+			EventID:         fmt.Sprintf("evt-%d", seq),
+			RunID:           "run-123",
+			Seq:             seq,
+			Type:            types.EventTypeRotateProxy,
+			Ts:              "2024-01-01T00:00:00Z",
+			Payload:         map[string]any{"reason": reason},
+			Attempt:         1,
+		}))
+	}
+	seq++
+	buf.Write(encodeEventFrame(&types.EventEnvelope{
+		ContractVersion: types.ContractVersion,
+		EventID:         fmt.Sprintf("evt-%d", seq),
+		RunID:           "run-123",
+		Seq:             seq,
+		Type:            types.EventTypeRunComplete,
+		Ts:              "2024-01-01T00:00:01Z",
+		Payload:         map[string]any{},
+		Attempt:         1,
+	}))
+	return &buf
+}
+
+func TestIngestionEngine_RotateProxy_SendsProxyUpdate(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+	user1, user2 := "session-1", "session-2"
+	initial := &types.ProxyEndpoint{Protocol: types.ProxyProtocolHTTP, Host: "gw.example.com", Port: 8080, Username: &user1}
+	b := &types.ProxyEndpoint{Protocol: types.ProxyProtocolHTTP, Host: "gw.example.com", Port: 8080, Username: &user2}
+	other := &types.ProxyEndpoint{Protocol: types.ProxyProtocolHTTP, Host: "other.example.com", Port: 8080}
+	rotator := &stubProxyRotator{next: []*types.ProxyEndpoint{b, b, other}}
+
+	var ackBuf bytes.Buffer
+	collector := metrics.NewCollector("strict", "test", "fs", "run-123", "")
+	engine := NewIngestionEngine(rotateProxyStream(t, "captcha", "blocked", "blocked", "blocked"), policy.NewNoopPolicy(), NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, collector, nil, ipc.NewControlWriter(&ackBuf))
+	engine.SetProxyRotator(rotator, initial)
+
+	if err := engine.Run(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Second rotation returned the endpoint in use; third another proxy
+	// server, which the launched browser cannot switch to; fourth found none
+	if got := len(rotator.replaced); got != 4 {
+		t.Errorf("rotator asked %d times, want 4", got)
+	}
+	if got := collector.Snapshot().ProxyRotations; got != 1 {
+		t.Errorf("ProxyRotations = %d, want 1", got)
+	}
+	if engine.CurrentProxy() != b {
+		t.Errorf("CurrentProxy = %+v, want b", engine.CurrentProxy())
+	}
+
+	data := ackBuf.Bytes()
+	if len(data) < 4 {
+		t.Fatalf("expected one proxy_update frame, got %d bytes", len(data))
+	}
+	payloadLen := binary.BigEndian.Uint32(data[:4])
+	if int(payloadLen)+4 != len(data) {
+		t.Fatalf("expected exactly one frame, got %d bytes for payload of %d", len(data), payloadLen)
+	}
+	var update types.ProxyUpdateFrame
+	if err := msgpack.Unmarshal(data[4:], &update); err != nil {
+		t.Fatalf("failed to decode proxy_update: %v", err)
+	}
+	if update.Type != "proxy_update" || update.Endpoint.Username == nil || *update.Endpoint.Username != user2 {
+		t.Errorf("update = %+v", update)
+	}
+	if update.Reason == nil || *update.Reason != "captcha" {
+		t.Errorf("Reason = %v, want captcha", update.Reason)
+	}
+}
+
+func TestIngestionEngine_RotateProxy_DisabledWithoutRotator(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	var ackBuf bytes.Buffer
+	engine := NewIngestionEngine(rotateProxyStream(t, "blocked"), policy.NewNoopPolicy(), NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, nil, nil, ipc.NewControlWriter(&ackBuf))
+
+	if err := engine.Run(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ackBuf.Len() != 0 {
+		t.Errorf("expected no frames without a rotator, got %d bytes", ackBuf.Len())
+	}
+}
+
+func TestIngestionEngine_LastCheckpoint(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-123", Attempt: 1}
+
+	var buf bytes.Buffer
+	for seq, id := range []string{"login", "session"} {
+		buf.Write(encodeEventFrame(&types.EventEnvelope{
+			ContractVersion: types.ContractVersion,
+			EventID:         fmt.Sprintf("evt-%d", seq+1),
+			RunID:           "run-123",
+			Seq:             int64(seq + 1),
+			Type:            types.EventTypeCheckpoint,
+			Ts:              "2024-01-01T00:00:00Z",
+			Payload:         map[string]any{"checkpoint_id": id},
+			Attempt:         1,
+		}))
+	}
+	buf.Write(encodeEventFrame(&types.EventEnvelope{
+		ContractVersion: types.ContractVersion,
+		EventID:         "evt-3",
+		RunID:           "run-123",
+		Seq:             3,
+		Type:            types.EventTypeRunComplete,
+		Ts:              "2024-01-01T00:00:01Z",
+		Payload:         map[string]any{},
+		Attempt:         1,
+	}))
+
+	engine := NewIngestionEngine(&buf, policy.NewNoopPolicy(), NewArtifactManager(), nil, log.NewLogger(runMeta), runMeta, nil, nil, nil)
+	if err := engine.Run(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	checkpoint := engine.LastCheckpoint()
+	if checkpoint == nil {
+		t.Fatal("expected a checkpoint to be recorded")
+	}
+	if got := checkpoint.Payload["checkpoint_id"]; got != "session" {
+		t.Errorf("checkpoint_id = %v, want session (the last one)", got)
+	}
+}
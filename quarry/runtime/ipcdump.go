@@ -0,0 +1,114 @@
+package runtime
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pithecene-io/quarry/log"
+)
+
+// DefaultIPCDumpMaxBytes caps the --dump-ipc capture file.
+const DefaultIPCDumpMaxBytes = 256 << 20
+
+// ipcDumpBufferSize is the capture's write buffer; ingestion only touches
+// the file once per buffer fill.
+const ipcDumpBufferSize = 256 << 10
+
+// ipcDump captures the raw executor stdout (the length-prefixed frame
+// stream) to a file as ingestion reads it.
+// Best effort: Write never fails, so the tee cannot break ingestion. A
+// write error stops the capture; bytes past maxBytes are dropped.
+type ipcDump struct {
+	path      string
+	file      *os.File
+	buf       *bufio.Writer
+	maxBytes  int64
+	written   int64
+	total     int64
+	failed    bool
+	truncated bool
+	logger    *log.Logger
+}
+
+// ipcDumpPath returns the capture path for a run attempt. Attempts after the
+// first get an ".attempt-N" suffix before the extension so a --max-attempts
+// retry does not truncate the earlier attempt's capture.
+func ipcDumpPath(path string, attempt int) string {
+	if attempt <= 1 {
+		return path
+	}
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s.attempt-%d%s", strings.TrimSuffix(path, ext), attempt, ext)
+}
+
+// openIPCDump creates (or truncates) the capture file at path.
+func openIPCDump(path string, maxBytes int64, logger *log.Logger) (*ipcDump, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultIPCDumpMaxBytes
+	}
+	return &ipcDump{
+		path:     path,
+		file:     f,
+		buf:      bufio.NewWriterSize(f, ipcDumpBufferSize),
+		maxBytes: maxBytes,
+		logger:   logger,
+	}, nil
+}
+
+// tee returns a reader that copies everything read from r into the capture.
+func (d *ipcDump) tee(r io.Reader) io.Reader {
+	return io.TeeReader(r, d)
+}
+
+// Write implements io.Writer. It always reports success.
+func (d *ipcDump) Write(p []byte) (int, error) {
+	d.total += int64(len(p))
+	if d.failed {
+		return len(p), nil
+	}
+	chunk := p
+	if remaining := d.maxBytes - d.written; int64(len(chunk)) > remaining {
+		chunk = chunk[:remaining]
+		d.truncated = true
+	}
+	if len(chunk) == 0 {
+		return len(p), nil
+	}
+	if _, err := d.buf.Write(chunk); err != nil {
+		d.failed = true
+		d.logger.Warn("ipc dump write failed; capture stopped", map[string]any{
+			"path":  d.path,
+			"error": err.Error(),
+		})
+		return len(p), nil
+	}
+	d.written += int64(len(chunk))
+	return len(p), nil
+}
+
+// Close flushes and closes the capture, warning when it was truncated.
+func (d *ipcDump) Close() error {
+	var err error
+	if !d.failed {
+		err = d.buf.Flush()
+	}
+	if closeErr := d.file.Close(); err == nil {
+		err = closeErr
+	}
+	if d.truncated {
+		d.logger.Warn("ipc dump truncated at size cap; the capture ends mid-stream", map[string]any{
+			"path":        d.path,
+			"kept_bytes":  d.written,
+			"total_bytes": d.total,
+		})
+	}
+	return err
+}
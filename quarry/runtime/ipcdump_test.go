@@ -0,0 +1,172 @@
+package runtime
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pithecene-io/quarry/ipc"
+	"github.com/pithecene-io/quarry/log"
+	"github.com/pithecene-io/quarry/types"
+)
+
+func TestRunOrchestrator_DumpIPC(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-dump", Attempt: 1}
+	stream := makeValidEventStream(runMeta)
+	path := filepath.Join(t.TempDir(), "capture.bin")
+
+	orchestrator, err := NewRunOrchestrator(&RunConfig{
+		ExecutorPath: "/fake/executor",
+		ScriptPath:   "/fake/script.js",
+		RunMeta:      runMeta,
+		Policy:       newFlushTrackingPolicy(),
+		DumpIPCPath:  path,
+		ExecutorFactory: func(_ *ExecutorConfig) Executor {
+			return newMockExecutor(stream, 0)
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	result, err := orchestrator.Execute(t.Context())
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if result.Outcome.Status != types.OutcomeSuccess {
+		t.Fatalf("expected OutcomeSuccess, got %s: %s", result.Outcome.Status, result.Outcome.Message)
+	}
+
+	captured, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read capture: %v", err)
+	}
+	if !bytes.Equal(captured, stream) {
+		t.Fatalf("capture = %d bytes, want the %d-byte executor stream", len(captured), len(stream))
+	}
+
+	// The capture decodes with the IPC frame decoder
+	decoder := ipc.NewFrameDecoder(bytes.NewReader(captured))
+	frames := 0
+	for {
+		if _, err := decoder.ReadFrame(); err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Fatalf("ReadFrame: %v", err)
+			}
+			break
+		}
+		frames++
+	}
+	if frames == 0 {
+		t.Error("expected frames in the capture")
+	}
+}
+
+func TestRunOrchestrator_DumpIPC_RetryKeepsEarlierAttempt(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "capture.bin")
+	if err := os.WriteFile(path, []byte("attempt 1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	parent := "run-dump"
+	runMeta := &types.RunMeta{RunID: "run-dump-retry", Attempt: 2, ParentRunID: &parent}
+	stream := makeValidEventStream(runMeta)
+	orchestrator, err := NewRunOrchestrator(&RunConfig{
+		ExecutorPath: "/fake/executor",
+		ScriptPath:   "/fake/script.js",
+		RunMeta:      runMeta,
+		Policy:       newFlushTrackingPolicy(),
+		DumpIPCPath:  path,
+		ExecutorFactory: func(_ *ExecutorConfig) Executor {
+			return newMockExecutor(stream, 0)
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	if _, err := orchestrator.Execute(t.Context()); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	first, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read first capture: %v", err)
+	}
+	if string(first) != "attempt 1" {
+		t.Errorf("first attempt's capture was overwritten: %q", first)
+	}
+	captured, err := os.ReadFile(filepath.Join(dir, "capture.attempt-2.bin"))
+	if err != nil {
+		t.Fatalf("read retry capture: %v", err)
+	}
+	if !bytes.Equal(captured, stream) {
+		t.Errorf("retry capture = %d bytes, want the %d-byte executor stream", len(captured), len(stream))
+	}
+}
+
+func TestIPCDumpPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		attempt int
+		want    string
+	}{
+		{"capture.bin", 1, "capture.bin"},
+		{"capture.bin", 0, "capture.bin"},
+		{"/tmp/capture.bin", 2, "/tmp/capture.attempt-2.bin"},
+		{"/tmp/capture", 3, "/tmp/capture.attempt-3"},
+	}
+	for _, tt := range tests {
+		if got := ipcDumpPath(tt.path, tt.attempt); got != tt.want {
+			t.Errorf("ipcDumpPath(%q, %d) = %q, want %q", tt.path, tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestIPCDump_TruncatesAtCap(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.bin")
+	var logs bytes.Buffer
+	dump, err := openIPCDump(path, 10, log.NewLogger(&types.RunMeta{RunID: "run-dump", Attempt: 1}).WithOutput(&logs))
+	if err != nil {
+		t.Fatalf("openIPCDump: %v", err)
+	}
+
+	// The tee still yields the full stream to the reader
+	read, err := io.ReadAll(dump.tee(strings.NewReader("0123456789abcdef")))
+	if err != nil || string(read) != "0123456789abcdef" {
+		t.Fatalf("tee read = %q, %v; want the full input", read, err)
+	}
+	if err := dump.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	captured, _ := os.ReadFile(path)
+	if string(captured) != "0123456789" {
+		t.Errorf("capture = %q, want the first 10 bytes", captured)
+	}
+	if !strings.Contains(logs.String(), "ipc dump truncated") {
+		t.Errorf("logs = %q, want a truncation warning", logs.String())
+	}
+}
+
+func TestIPCDump_WriteErrorIsBestEffort(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.bin")
+	var logs bytes.Buffer
+	dump, err := openIPCDump(path, 0, log.NewLogger(&types.RunMeta{RunID: "run-dump", Attempt: 1}).WithOutput(&logs))
+	if err != nil {
+		t.Fatalf("openIPCDump: %v", err)
+	}
+	// Closing the file underneath makes the next buffer flush fail
+	_ = dump.file.Close()
+
+	payload := bytes.Repeat([]byte("x"), ipcDumpBufferSize+1)
+	if n, err := dump.Write(payload); err != nil || n != len(payload) {
+		t.Errorf("Write = %d, %v; want %d, nil", n, err, len(payload))
+	}
+	if !strings.Contains(logs.String(), "ipc dump write failed") {
+		t.Errorf("logs = %q, want a write failure warning", logs.String())
+	}
+}
@@ -0,0 +1,39 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// LabelsFilename is the sidecar file holding a run's labels, written to the
+// run partition's files/ prefix.
+const LabelsFilename = "_labels.json"
+
+// labelsWriteTimeout bounds the labels sidecar write.
+const labelsWriteTimeout = 30 * time.Second
+
+// writeLabels persists RunMeta.Labels as the _labels.json sidecar.
+// Best effort: a failed write is logged and does not fail the run.
+func (r *RunOrchestrator) writeLabels(ctx context.Context) {
+	labels := r.config.RunMeta.Labels
+	if len(labels) == 0 || r.config.FileWriter == nil {
+		return
+	}
+
+	data, err := json.Marshal(labels)
+	if err != nil {
+		r.logger.Warn("failed to encode run labels", map[string]any{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), labelsWriteTimeout)
+	defer cancel()
+	if err := r.config.FileWriter.PutFile(writeCtx, LabelsFilename, "application/json", data); err != nil {
+		r.logger.Warn("failed to write run labels", map[string]any{
+			"error": err.Error(),
+		})
+	}
+}
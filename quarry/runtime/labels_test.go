@@ -0,0 +1,68 @@
+package runtime
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pithecene-io/quarry/lode"
+	"github.com/pithecene-io/quarry/types"
+)
+
+func TestRunOrchestrator_WritesLabelsSidecar(t *testing.T) {
+	labels := map[string]string{"team": "growth", "campaign": "spring"}
+	runMeta := &types.RunMeta{RunID: "run-labels", Attempt: 1, Labels: labels}
+	fw := lode.NewStubFileWriter()
+
+	orchestrator, err := NewRunOrchestrator(&RunConfig{
+		ExecutorPath: "/fake/executor",
+		ScriptPath:   "/fake/script.js",
+		RunMeta:      runMeta,
+		Policy:       newFlushTrackingPolicy(),
+		FileWriter:   fw,
+		ExecutorFactory: func(_ *ExecutorConfig) Executor {
+			return newMockExecutor(makeValidEventStream(runMeta), 0)
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	if _, err := orchestrator.Execute(t.Context()); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	if len(fw.Files) != 1 || fw.Files[0].Filename != LabelsFilename {
+		t.Fatalf("expected one %s write, got %+v", LabelsFilename, fw.Files)
+	}
+	var got map[string]string
+	if err := json.Unmarshal(fw.Files[0].Data, &got); err != nil {
+		t.Fatalf("labels sidecar is not JSON: %v", err)
+	}
+	if got["team"] != "growth" || got["campaign"] != "spring" {
+		t.Errorf("labels sidecar = %v, want %v", got, labels)
+	}
+}
+
+func TestRunOrchestrator_NoLabelsNoSidecar(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-nolabels", Attempt: 1}
+	fw := lode.NewStubFileWriter()
+
+	orchestrator, err := NewRunOrchestrator(&RunConfig{
+		ExecutorPath: "/fake/executor",
+		ScriptPath:   "/fake/script.js",
+		RunMeta:      runMeta,
+		Policy:       newFlushTrackingPolicy(),
+		FileWriter:   fw,
+		ExecutorFactory: func(_ *ExecutorConfig) Executor {
+			return newMockExecutor(makeValidEventStream(runMeta), 0)
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	if _, err := orchestrator.Execute(t.Context()); err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if len(fw.Files) != 0 {
+		t.Errorf("expected no sidecar writes without labels, got %+v", fw.Files)
+	}
+}
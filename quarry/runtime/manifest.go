@@ -0,0 +1,197 @@
+package runtime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+// RunManifest is the machine-readable run summary written by --output-manifest.
+// It carries every RunReport field (flattened) plus the run lineage, the
+// storage location, and, for fan-out, the per-child summary.
+type RunManifest struct {
+	*RunReport
+
+	ParentRunID string            `json:"parent_run_id,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+
+	Storage *ManifestStorage `json:"storage,omitempty"`
+	FanOut  *ManifestFanOut  `json:"fan_out,omitempty"`
+	Inputs  *ManifestInputs  `json:"inputs,omitempty"`
+}
+
+// ManifestInputs records what the run was started with, so that
+// quarry run --replay can reconstruct it.
+type ManifestInputs struct {
+	Script     string            `json:"script"`
+	ConfigFile string            `json:"config_file,omitempty"`
+	Job        map[string]any    `json:"job,omitempty"`
+	Settings   []ManifestSetting `json:"settings"`
+}
+
+// ManifestSetting is one resolved run setting and where its value came from
+// ("flag", "config", or "default"). Slice-valued settings use Values.
+// Redacted values had credentials stripped and cannot be replayed verbatim.
+type ManifestSetting struct {
+	Name     string   `json:"name"`
+	Value    string   `json:"value,omitempty"`
+	Values   []string `json:"values,omitempty"`
+	Source   string   `json:"source"`
+	Redacted bool     `json:"redacted,omitempty"`
+}
+
+// ManifestStorage describes where the run was persisted.
+type ManifestStorage struct {
+	Tenant   string `json:"tenant,omitempty"`
+	Backend  string `json:"backend"`
+	Dataset  string `json:"dataset"`
+	Source   string `json:"source"`
+	Category string `json:"category"`
+	Day      string `json:"day"`
+	Hour     string `json:"hour,omitempty"`
+	Path     string `json:"path"`
+}
+
+// ManifestFanOut is the fan-out summary in the manifest.
+type ManifestFanOut struct {
+	RunsTotal       int64           `json:"runs_total"`
+	RunsSucceeded   int64           `json:"runs_succeeded"`
+	RunsFailed      int64           `json:"runs_failed"`
+	RunsRetried     int64           `json:"runs_retried"`
+	EnqueueReceived int64           `json:"enqueue_received"`
+	EnqueueDeduped  int64           `json:"enqueue_deduped"`
+	EnqueueSkipped  int64           `json:"enqueue_skipped"`
+	Children        []ManifestChild `json:"children"`
+
+	OriginMaxInFlight map[string]int `json:"origin_max_in_flight,omitempty"`
+	Priorities        map[int]int64  `json:"priorities,omitempty"`
+
+	Aborted        bool   `json:"aborted,omitempty"`
+	FirstFailure   string `json:"first_failure,omitempty"`
+	AbortedPending int64  `json:"aborted_pending,omitempty"`
+}
+
+// ManifestChild summarizes one fan-out child run.
+type ManifestChild struct {
+	RunID          string                       `json:"run_id"`
+	ParentRunID    string                       `json:"parent_run_id,omitempty"`
+	Attempt        int                          `json:"attempt"`
+	Outcome        types.OutcomeStatus          `json:"outcome"`
+	Reason         types.OutcomeReason          `json:"reason,omitempty"`
+	Message        string                       `json:"message"`
+	EventCount     int64                        `json:"event_count"`
+	DurationMs     int64                        `json:"duration_ms"`
+	ProxyUsed      *types.ProxyEndpointRedacted `json:"proxy_used,omitempty"`
+	BudgetExceeded bool                         `json:"budget_exceeded,omitempty"`
+}
+
+// BuildRunManifest composes a RunManifest from the run's report and metadata.
+// storage and fanOut may be nil (no storage location, or not a fan-out run).
+func BuildRunManifest(report *RunReport, meta *types.RunMeta, storage *ManifestStorage, fanOut *FanOutResult) *RunManifest {
+	manifest := &RunManifest{
+		RunReport: report,
+		Labels:    meta.Labels,
+		Storage:   storage,
+	}
+	if meta.ParentRunID != nil {
+		manifest.ParentRunID = *meta.ParentRunID
+	}
+	if fanOut != nil {
+		manifest.FanOut = buildManifestFanOut(fanOut)
+	}
+	return manifest
+}
+
+// buildManifestFanOut converts a FanOutResult, with children sorted by run_id.
+func buildManifestFanOut(result *FanOutResult) *ManifestFanOut {
+	out := &ManifestFanOut{
+		RunsTotal:       result.RunsTotal,
+		RunsSucceeded:   result.RunsSucceeded,
+		RunsFailed:      result.RunsFailed,
+		RunsRetried:     result.RunsRetried,
+		EnqueueReceived: result.EnqueueReceived,
+		EnqueueDeduped:  result.EnqueueDeduped,
+		EnqueueSkipped:  result.EnqueueSkipped,
+		Children:        make([]ManifestChild, 0, len(result.ChildResults)),
+
+		OriginMaxInFlight: result.OriginMaxInFlight,
+		Priorities:        result.Priorities,
+
+		Aborted:        result.Aborted,
+		FirstFailure:   result.FirstFailure,
+		AbortedPending: result.AbortedPending,
+	}
+
+	runIDs := make([]string, 0, len(result.ChildResults))
+	for id := range result.ChildResults {
+		runIDs = append(runIDs, id)
+	}
+	sort.Strings(runIDs)
+	for _, runID := range runIDs {
+		res := result.ChildResults[runID]
+		child := ManifestChild{
+			RunID:          runID,
+			EventCount:     res.EventCount,
+			DurationMs:     res.Duration.Milliseconds(),
+			ProxyUsed:      res.ProxyUsed,
+			BudgetExceeded: res.BudgetExceeded,
+		}
+		if res.RunMeta != nil {
+			child.Attempt = res.RunMeta.Attempt
+			if res.RunMeta.ParentRunID != nil {
+				child.ParentRunID = *res.RunMeta.ParentRunID
+			}
+		}
+		if res.Outcome != nil {
+			child.Outcome = res.Outcome.Status
+			child.Reason = res.Outcome.Reason
+			child.Message = res.Outcome.Message
+		}
+		out.Children = append(out.Children, child)
+	}
+	return out
+}
+
+// WriteRunManifest writes the manifest as JSON to path. The file is written
+// to a temporary sibling and renamed, so readers never see a partial manifest.
+func WriteRunManifest(manifest *RunManifest, path string) error {
+	if path == "" {
+		return errors.New("manifest path must not be empty")
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	data = append(data, '\n')
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest to %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to write manifest to %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadRunManifest reads a manifest written by WriteRunManifest.
+func ReadRunManifest(path string) (*RunManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", path, err)
+	}
+	var manifest RunManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	if manifest.RunReport == nil {
+		manifest.RunReport = &RunReport{}
+	}
+	return &manifest, nil
+}
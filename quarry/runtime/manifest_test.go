@@ -0,0 +1,156 @@
+package runtime
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+func TestBuildRunManifest_FlattensReportAndAddsLineage(t *testing.T) {
+	result := newTestRunResult()
+	parent := "run-000"
+	result.RunMeta.ParentRunID = &parent
+	result.RunMeta.Attempt = 2
+	result.RunMeta.Labels = map[string]string{"team": "growth"}
+
+	report := BuildRunReport(result, newTestSnapshot(), "streaming", 0)
+	storage := &ManifestStorage{Backend: "fs", Dataset: "quarry", Source: "shop", Category: "default", Day: "2026-01-02", Path: "/data/quarry/source=shop"}
+	manifest := BuildRunManifest(report, result.RunMeta, storage, nil)
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	// Report fields are top-level, not nested
+	if got["run_id"] != "run-001" || got["outcome"] != "success" || got["attempt"] != float64(2) {
+		t.Errorf("report fields not flattened: %v", got)
+	}
+	if got["parent_run_id"] != "run-000" {
+		t.Errorf("parent_run_id = %v, want run-000", got["parent_run_id"])
+	}
+	if labels, _ := got["labels"].(map[string]any); labels["team"] != "growth" {
+		t.Errorf("labels = %v, want team=growth", got["labels"])
+	}
+	if s, _ := got["storage"].(map[string]any); s["path"] != "/data/quarry/source=shop" {
+		t.Errorf("storage = %v", got["storage"])
+	}
+	if _, ok := got["fan_out"]; ok {
+		t.Error("fan_out should be omitted for single runs")
+	}
+}
+
+func TestBuildRunManifest_FanOutChildren(t *testing.T) {
+	retryOf := "child-a"
+	fanOut := &FanOutResult{
+		RunsTotal:     2,
+		RunsSucceeded: 1,
+		RunsFailed:    1,
+		RunsRetried:   1,
+		Aborted:       true,
+		FirstFailure:  "child-a",
+		ChildResults: map[string]*RunResult{
+			"child-b": {
+				RunMeta:   &types.RunMeta{RunID: "child-b", Attempt: 2, ParentRunID: &retryOf},
+				Outcome:   &types.RunOutcome{Status: types.OutcomeSuccess, Reason: types.ReasonCompleted, Message: "ok"},
+				Duration:  1500 * time.Millisecond,
+				ProxyUsed: &types.ProxyEndpointRedacted{Protocol: types.ProxyProtocolHTTP, Host: "p2", Port: 8080},
+			},
+			"child-a": {
+				RunMeta:        &types.RunMeta{RunID: "child-a", Attempt: 1},
+				Outcome:        &types.RunOutcome{Status: types.OutcomeExecutorCrash, Reason: types.ReasonExecutorCrash, Message: "crash"},
+				BudgetExceeded: true,
+			},
+		},
+	}
+
+	manifest := BuildRunManifest(BuildRunReport(newTestRunResult(), newTestSnapshot(), "strict", 0), newTestRunResult().RunMeta, nil, fanOut)
+	if manifest.FanOut == nil || len(manifest.FanOut.Children) != 2 {
+		t.Fatalf("expected 2 children, got %+v", manifest.FanOut)
+	}
+	a, b := manifest.FanOut.Children[0], manifest.FanOut.Children[1]
+	if a.RunID != "child-a" || b.RunID != "child-b" {
+		t.Errorf("children should be sorted by run_id, got %s, %s", a.RunID, b.RunID)
+	}
+	if a.Outcome != types.OutcomeExecutorCrash || !a.BudgetExceeded {
+		t.Errorf("child-a = %+v", a)
+	}
+	if b.Attempt != 2 || b.ParentRunID != "child-a" || b.DurationMs != 1500 || b.ProxyUsed.Host != "p2" {
+		t.Errorf("child-b = %+v", b)
+	}
+	if manifest.FanOut.RunsRetried != 1 {
+		t.Errorf("RunsRetried = %d, want 1", manifest.FanOut.RunsRetried)
+	}
+	if !manifest.FanOut.Aborted || manifest.FanOut.FirstFailure != "child-a" {
+		t.Errorf("aborted = %v, first_failure = %q", manifest.FanOut.Aborted, manifest.FanOut.FirstFailure)
+	}
+}
+
+func TestWriteRunManifest_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.json")
+	manifest := BuildRunManifest(BuildRunReport(newTestRunResult(), newTestSnapshot(), "strict", 0), newTestRunResult().RunMeta, nil, nil)
+
+	if err := WriteRunManifest(manifest, path); err != nil {
+		t.Fatalf("WriteRunManifest: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("manifest is not valid JSON: %v", err)
+	}
+	if got["run_id"] != "run-001" {
+		t.Errorf("run_id = %v, want run-001", got["run_id"])
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("temporary manifest file should not remain")
+	}
+
+	if err := WriteRunManifest(manifest, ""); err == nil {
+		t.Error("expected error for empty path")
+	}
+}
+
+func TestReadRunManifest_RoundTripsInputs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.json")
+	manifest := BuildRunManifest(BuildRunReport(newTestRunResult(), newTestSnapshot(), "strict", 0), newTestRunResult().RunMeta, nil, nil)
+	manifest.Inputs = &ManifestInputs{
+		Script: "./scripts/shop.ts",
+		Job:    map[string]any{"url": "https://example.com"},
+		Settings: []ManifestSetting{
+			{Name: "policy", Value: "buffered", Source: "flag"},
+			{Name: "label", Values: []string{"team=growth"}, Source: "config"},
+		},
+	}
+	if err := WriteRunManifest(manifest, path); err != nil {
+		t.Fatalf("WriteRunManifest: %v", err)
+	}
+
+	got, err := ReadRunManifest(path)
+	if err != nil {
+		t.Fatalf("ReadRunManifest: %v", err)
+	}
+	if got.RunID != "run-001" {
+		t.Errorf("RunID = %q, want run-001", got.RunID)
+	}
+	if got.Inputs == nil || got.Inputs.Script != "./scripts/shop.ts" || got.Inputs.Job["url"] != "https://example.com" {
+		t.Fatalf("Inputs = %+v", got.Inputs)
+	}
+	if len(got.Inputs.Settings) != 2 || got.Inputs.Settings[1].Values[0] != "team=growth" {
+		t.Errorf("Settings = %+v", got.Inputs.Settings)
+	}
+
+	if _, err := ReadRunManifest(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing manifest")
+	}
+}
@@ -0,0 +1,67 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"time"
+)
+
+// DefaultOnCompleteHookTimeout bounds an on-complete hook when no timeout is set.
+const DefaultOnCompleteHookTimeout = 5 * time.Minute
+
+// OnCompleteHook is an external command run after a successful run has been
+// finalized, to trigger downstream work. The command runs via "sh -c" with
+// the RunManifest JSON on stdin. The run outcome is already final: a hook
+// failure is reported to the caller, which decides whether it matters.
+type OnCompleteHook struct {
+	// Command is the shell command to run.
+	Command string
+	// Timeout bounds the hook (0 = DefaultOnCompleteHookTimeout).
+	Timeout time.Duration
+	// Required makes a hook failure fail the process (--on-complete-required).
+	// The hook itself does not interpret it.
+	Required bool
+	// Output receives the hook's stdout and stderr. Nil discards them.
+	Output io.Writer
+}
+
+// Run executes the hook with manifest on stdin. A nil error means the hook
+// exited 0; otherwise the error describes the exit status, timeout, or
+// start failure.
+func (h *OnCompleteHook) Run(ctx context.Context, manifest *RunManifest) error {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = DefaultOnCompleteHookTimeout
+	}
+	hookCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stdin, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("on-complete hook: encoding manifest: %w", err)
+	}
+
+	cmd := exec.CommandContext(hookCtx, "sh", "-c", h.Command)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = h.Output
+	cmd.Stderr = h.Output
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(hookCtx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("on-complete hook timed out after %s", timeout)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("on-complete hook exited %d", exitErr.ExitCode())
+	}
+	return fmt.Errorf("on-complete hook failed: %w", err)
+}
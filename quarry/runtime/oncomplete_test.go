@@ -0,0 +1,64 @@
+package runtime
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+func testOnCompleteManifest() *RunManifest {
+	return &RunManifest{
+		RunReport: &RunReport{RunID: "run-done", Outcome: types.OutcomeSuccess},
+		Storage:   &ManifestStorage{Backend: "fs", Path: "/data/run-done"},
+	}
+}
+
+func TestOnCompleteHook_ReceivesManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stdin.json")
+	var output bytes.Buffer
+	hook := &OnCompleteHook{
+		Command: `cat > "` + path + `"; echo triggered`,
+		Timeout: 5 * time.Second,
+		Output:  &output,
+	}
+
+	if err := hook.Run(t.Context(), testOnCompleteManifest()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	got, err := ReadRunManifest(path)
+	if err != nil {
+		t.Fatalf("decode hook stdin: %v", err)
+	}
+	if got.RunID != "run-done" || got.Storage == nil || got.Storage.Path != "/data/run-done" {
+		t.Errorf("hook manifest = %+v", got)
+	}
+	if !strings.Contains(output.String(), "triggered") {
+		t.Errorf("output = %q, want the hook's stdout", output.String())
+	}
+}
+
+func TestOnCompleteHook_NonzeroExit(t *testing.T) {
+	hook := &OnCompleteHook{Command: "exit 4", Timeout: 5 * time.Second}
+
+	err := hook.Run(t.Context(), testOnCompleteManifest())
+	if err == nil || !strings.Contains(err.Error(), "exited 4") {
+		t.Errorf("err = %v, want exit status 4", err)
+	}
+}
+
+func TestOnCompleteHook_Timeout(t *testing.T) {
+	hook := &OnCompleteHook{Command: "sleep 5", Timeout: 50 * time.Millisecond}
+
+	start := time.Now()
+	err := hook.Run(t.Context(), testOnCompleteManifest())
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("hook timeout not enforced: took %s", elapsed)
+	}
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("err = %v, want a timeout", err)
+	}
+}
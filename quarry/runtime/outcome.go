@@ -1,8 +1,14 @@
 package runtime
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/pithecene-io/quarry/ipc"
+	"github.com/pithecene-io/quarry/policy"
 	"github.com/pithecene-io/quarry/types"
 )
 
@@ -29,6 +35,81 @@ func outcomeFromExitCode(exitCode int) types.OutcomeStatus {
 	}
 }
 
+// reasonFromExitCode maps exit code to the outcome reason used when the
+// exit code alone determines the outcome.
+func reasonFromExitCode(exitCode int) types.OutcomeReason {
+	switch exitCode {
+	case ExitCodeCompleted:
+		return types.ReasonCompleted
+	case ExitCodeError:
+		return types.ReasonScriptError
+	case ExitCodeCrash:
+		return types.ReasonExecutorCrash
+	case ExitCodeInvalidInput:
+		return types.ReasonInvalidInput
+	default:
+		return types.ReasonUnexpectedExit
+	}
+}
+
+// CancelCause records why a run context was canceled. Cancel the context
+// passed to RunOrchestrator.Execute with context.WithCancelCause and a
+// *CancelCause, and the outcome takes its Reason and Message instead of the
+// generic canceled. Without one, a canceled context reports canceled and an
+// expired deadline timeout. A CancelCause matches context.Canceled.
+type CancelCause struct {
+	Reason  types.OutcomeReason
+	Message string
+}
+
+func (c *CancelCause) Error() string {
+	return c.Message
+}
+
+func (c *CancelCause) Unwrap() error {
+	return context.Canceled
+}
+
+// ReasonFromIngestionError classifies an error returned by IngestionEngine.Run
+// into an outcome reason.
+func ReasonFromIngestionError(err error) types.OutcomeReason {
+	var frameErr *ipc.FrameError
+	var cause *CancelCause
+	switch {
+	case errors.As(err, &cause) && cause.Reason != "":
+		return cause.Reason
+	case errors.Is(err, ErrStreamStalled), errors.Is(err, context.DeadlineExceeded):
+		return types.ReasonTimeout
+	case errors.Is(err, ErrDrained):
+		return types.ReasonDrained
+	case IsCanceledError(err):
+		return types.ReasonCanceled
+	case IsVersionMismatchError(err):
+		return types.ReasonVersionMismatch
+	case errors.Is(err, ErrArtifactBudgetExceeded):
+		return types.ReasonBudgetExceeded
+	case errors.Is(err, ErrRunQuotaExceeded):
+		return types.ReasonQuotaExceeded
+	case errors.Is(err, ErrDomainDenied):
+		return types.ReasonDomainDenied
+	case IsPolicyError(err):
+		return types.ReasonPolicyFailure
+	case errors.Is(err, ErrArtifactChecksumMismatch):
+		return types.ReasonChecksumMismatch
+	case errors.As(err, &frameErr):
+		switch frameErr.Kind {
+		case ipc.FrameErrorPartial:
+			return types.ReasonStreamTruncated
+		case ipc.FrameErrorTooLarge:
+			return types.ReasonOversizeFrame
+		default:
+			return types.ReasonDecodeError
+		}
+	default:
+		return types.ReasonStreamError
+	}
+}
+
 // DetermineOutcome determines the run outcome based on exit code and terminal event.
 // Per CONTRACT_RUN.md, outcome is determined by:
 //  1. Exit code from executor
@@ -46,12 +127,14 @@ func DetermineOutcome(exitCode int, hasTerminal bool, terminalEvent *types.Event
 		if hasTerminal && terminalEvent.Type == types.EventTypeRunComplete {
 			return &types.RunOutcome{
 				Status:  types.OutcomeSuccess,
+				Reason:  types.ReasonCompleted,
 				Message: "run completed successfully",
 			}
 		}
 		// Exit 0 without terminal = anomaly, treat as crash
 		return &types.RunOutcome{
 			Status:  types.OutcomeExecutorCrash,
+			Reason:  types.ReasonMissingTerminal,
 			Message: "executor exited cleanly without terminal event",
 		}
 
@@ -63,33 +146,92 @@ func DetermineOutcome(exitCode int, hasTerminal bool, terminalEvent *types.Event
 		// Exit 1 without terminal = anomaly, treat as crash
 		return &types.RunOutcome{
 			Status:  types.OutcomeExecutorCrash,
+			Reason:  types.ReasonMissingTerminal,
 			Message: "executor exited with error without terminal event",
 		}
 
 	case ExitCodeCrash:
 		return &types.RunOutcome{
 			Status:  types.OutcomeExecutorCrash,
+			Reason:  types.ReasonExecutorCrash,
 			Message: "executor crashed",
 		}
 
 	case ExitCodeInvalidInput:
 		return &types.RunOutcome{
 			Status:  types.OutcomeExecutorCrash,
+			Reason:  types.ReasonInvalidInput,
 			Message: "executor rejected invalid input",
 		}
 
 	default:
 		return &types.RunOutcome{
 			Status:  types.OutcomeExecutorCrash,
+			Reason:  types.ReasonUnexpectedExit,
 			Message: fmt.Sprintf("executor exited with unexpected code %d", exitCode),
 		}
 	}
 }
 
+// DetermineTelemetryOutcome is DetermineOutcome for telemetry-mode (log-only)
+// runs: a clean exit (code 0) is success whether or not a terminal event
+// arrived. Other exit codes are classified as usual.
+func DetermineTelemetryOutcome(exitCode int, hasTerminal bool, terminalEvent *types.EventEnvelope) *types.RunOutcome {
+	if exitCode != ExitCodeCompleted {
+		return DetermineOutcome(exitCode, hasTerminal, terminalEvent)
+	}
+	message := "telemetry run completed successfully"
+	if !hasTerminal {
+		message = "telemetry run exited cleanly without terminal event"
+	}
+	return &types.RunOutcome{
+		Status:  types.OutcomeSuccess,
+		Reason:  types.ReasonCompleted,
+		Message: message,
+	}
+}
+
+// OutcomeEvaluator refines the outcome of a run that reached the exit-code
+// classification (no stream, policy, or flush failure). terminal is the
+// terminal event (nil if none arrived). Returning nil keeps the default
+// outcome. See applyOutcomeEvaluator for how the result is bounded.
+type OutcomeEvaluator func(terminal *types.EventEnvelope, exitCode int) *types.RunOutcome
+
+// applyOutcomeEvaluator runs eval and merges its result into outcome.
+// Exit codes stay authoritative: the evaluator may downgrade a success or
+// reclassify a failure, but cannot turn a failure into success. An empty
+// Reason becomes ReasonOutcomeEvaluator and an empty Message keeps the
+// default message. The returned bool reports whether outcome changed.
+func applyOutcomeEvaluator(eval OutcomeEvaluator, outcome *types.RunOutcome, terminal *types.EventEnvelope, exitCode int) (*types.RunOutcome, bool) {
+	if eval == nil {
+		return outcome, false
+	}
+	refined := eval(terminal, exitCode)
+	if refined == nil {
+		return outcome, false
+	}
+	if refined.Status == types.OutcomeSuccess && outcome.Status != types.OutcomeSuccess {
+		return outcome, false
+	}
+
+	merged := *refined
+	if merged.Status == "" {
+		merged.Status = outcome.Status
+	}
+	if merged.Reason == "" {
+		merged.Reason = types.ReasonOutcomeEvaluator
+	}
+	if merged.Message == "" {
+		merged.Message = outcome.Message
+	}
+	return &merged, true
+}
+
 // extractRunErrorOutcome extracts outcome details from a run_error event.
 func extractRunErrorOutcome(event *types.EventEnvelope) *types.RunOutcome {
 	outcome := &types.RunOutcome{
 		Status:  types.OutcomeScriptError,
+		Reason:  types.ReasonScriptError,
 		Message: "script error",
 	}
 
@@ -107,3 +249,47 @@ func extractRunErrorOutcome(event *types.EventEnvelope) *types.RunOutcome {
 
 	return outcome
 }
+
+// applyDropGate converts the outcome to policy_failure when any events were
+// dropped by the policy. Used by the --fail-on-drops post-run gate.
+//
+// The gate applies regardless of the executor outcome. When the outcome is
+// not success, the original status and message are preserved in the new
+// message so the underlying failure remains visible.
+// Outcomes that are already policy_failure are returned unchanged.
+func applyDropGate(outcome *types.RunOutcome, stats policy.Stats) *types.RunOutcome {
+	if stats.EventsDropped == 0 || outcome.Status == types.OutcomePolicyFailure {
+		return outcome
+	}
+
+	msg := fmt.Sprintf("fail-on-drops: %d events dropped (%s)", stats.EventsDropped, formatDroppedByType(stats.DroppedByType))
+	if outcome.Status != types.OutcomeSuccess {
+		msg = fmt.Sprintf("%s; original outcome %s: %s", msg, outcome.Status, outcome.Message)
+	}
+
+	return &types.RunOutcome{
+		Status:    types.OutcomePolicyFailure,
+		Reason:    types.ReasonEventsDropped,
+		Message:   msg,
+		ErrorType: outcome.ErrorType,
+		Stack:     outcome.Stack,
+	}
+}
+
+// formatDroppedByType renders per-type drop counts as "type=n" pairs sorted by type.
+func formatDroppedByType(droppedByType map[types.EventType]int64) string {
+	if len(droppedByType) == 0 {
+		return "no per-type breakdown"
+	}
+	keys := make([]string, 0, len(droppedByType))
+	for k := range droppedByType {
+		keys = append(keys, string(k))
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%d", k, droppedByType[types.EventType(k)]))
+	}
+	return strings.Join(parts, ", ")
+}
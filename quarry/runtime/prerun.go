@@ -0,0 +1,114 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+// DefaultPreRunHookTimeout bounds a pre-run hook when no timeout is set.
+const DefaultPreRunHookTimeout = 30 * time.Second
+
+// preRunHookMaxMessage bounds the hook stderr carried into the outcome message.
+const preRunHookMaxMessage = 4096
+
+// PreRunHook is an external command that can veto a run before the executor
+// launches. The command runs via "sh -c" with a PreRunHookInput JSON document
+// on stdin. Exit 0 allows the run; any other exit, a start failure, or a
+// timeout aborts it with policy_failure (fail closed).
+type PreRunHook struct {
+	// Command is the shell command to run.
+	Command string
+	// Timeout bounds the hook independently of the run (0 = DefaultPreRunHookTimeout).
+	Timeout time.Duration
+}
+
+// PreRunHookInput is the JSON document written to the hook's stdin.
+type PreRunHookInput struct {
+	RunID       string `json:"run_id"`
+	JobID       string `json:"job_id,omitempty"`
+	ParentRunID string `json:"parent_run_id,omitempty"`
+	Attempt     int    `json:"attempt"`
+	Script      string `json:"script"`
+	Source      string `json:"source,omitempty"`
+	Category    string `json:"category,omitempty"`
+	Job         any    `json:"job"`
+}
+
+// run executes the hook. A nil error means the run may proceed; otherwise the
+// error message explains the veto.
+func (h *PreRunHook) run(ctx context.Context, input *PreRunHookInput) error {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = DefaultPreRunHookTimeout
+	}
+	hookCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stdin, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Errorf("pre-run hook: encoding input: %w", err)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(hookCtx, "sh", "-c", h.Command)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stderr = &stderr
+	cmd.WaitDelay = time.Second
+
+	err = cmd.Run()
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(hookCtx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("pre-run hook timed out after %s", timeout)
+	}
+
+	msg := strings.TrimSpace(stderr.String())
+	if len(msg) > preRunHookMaxMessage {
+		msg = msg[:preRunHookMaxMessage]
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if msg == "" {
+			return fmt.Errorf("pre-run hook rejected run (exit %d)", exitErr.ExitCode())
+		}
+		return fmt.Errorf("pre-run hook rejected run: %s", msg)
+	}
+	return fmt.Errorf("pre-run hook failed: %w", err)
+}
+
+// preRunHookInput builds the hook input from the run config.
+func preRunHookInput(config *RunConfig) *PreRunHookInput {
+	input := &PreRunHookInput{
+		RunID:    config.RunMeta.RunID,
+		Attempt:  config.RunMeta.Attempt,
+		Script:   config.ScriptPath,
+		Source:   config.Source,
+		Category: config.Category,
+		Job:      config.Job,
+	}
+	if config.RunMeta.JobID != nil {
+		input.JobID = *config.RunMeta.JobID
+	}
+	if config.RunMeta.ParentRunID != nil {
+		input.ParentRunID = *config.RunMeta.ParentRunID
+	}
+	return input
+}
+
+// preRunHookOutcome converts a hook veto into a run outcome.
+func preRunHookOutcome(err error) *types.RunOutcome {
+	return &types.RunOutcome{
+		Status:  types.OutcomePolicyFailure,
+		Reason:  types.ReasonPreRunHook,
+		Message: err.Error(),
+	}
+}
@@ -0,0 +1,90 @@
+package runtime
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+// runWithPreRunHook executes a run with a successful mock executor behind hook.
+func runWithPreRunHook(t *testing.T, hook *PreRunHook) (*RunResult, *mockExecutor) {
+	t.Helper()
+	runMeta := &types.RunMeta{RunID: "run-hook", Attempt: 1}
+	mockExec := newMockExecutor(makeValidEventStream(runMeta), 0)
+
+	orchestrator, err := NewRunOrchestrator(&RunConfig{
+		ExecutorPath: "/fake/executor",
+		ScriptPath:   "/fake/script.js",
+		Job:          map[string]any{"url": "https://example.com/a"},
+		RunMeta:      runMeta,
+		Source:       "shop",
+		Policy:       newFlushTrackingPolicy(),
+		PreRunHook:   hook,
+		ExecutorFactory: func(_ *ExecutorConfig) Executor {
+			return mockExec
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	result, err := orchestrator.Execute(t.Context())
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	return result, mockExec
+}
+
+func TestPreRunHook_AllowsRun(t *testing.T) {
+	// The hook sees the job payload and run metadata on stdin
+	hook := &PreRunHook{
+		Command: `input=$(cat); echo "$input" | grep -q '"run_id":"run-hook"' && echo "$input" | grep -q '"source":"shop"' && echo "$input" | grep -q 'example.com/a'`,
+		Timeout: 5 * time.Second,
+	}
+
+	result, mockExec := runWithPreRunHook(t, hook)
+	if result.Outcome.Status != types.OutcomeSuccess {
+		t.Fatalf("expected success, got %s: %s", result.Outcome.Status, result.Outcome.Message)
+	}
+	if !mockExec.started {
+		t.Error("executor should start when the hook allows the run")
+	}
+}
+
+func TestPreRunHook_VetoesRun(t *testing.T) {
+	hook := &PreRunHook{Command: `echo "domain example.com is not allowlisted" >&2; exit 3`, Timeout: 5 * time.Second}
+
+	result, mockExec := runWithPreRunHook(t, hook)
+	if result.Outcome.Status != types.OutcomePolicyFailure {
+		t.Fatalf("expected policy_failure, got %s", result.Outcome.Status)
+	}
+	if result.Outcome.Reason != types.ReasonPreRunHook {
+		t.Errorf("reason = %s, want %s", result.Outcome.Reason, types.ReasonPreRunHook)
+	}
+	if !strings.Contains(result.Outcome.Message, "domain example.com is not allowlisted") {
+		t.Errorf("message should carry hook stderr, got %q", result.Outcome.Message)
+	}
+	if mockExec.started {
+		t.Error("executor must not start when the hook vetoes the run")
+	}
+}
+
+func TestPreRunHook_TimeoutVetoesRun(t *testing.T) {
+	hook := &PreRunHook{Command: "sleep 5", Timeout: 50 * time.Millisecond}
+
+	start := time.Now()
+	result, mockExec := runWithPreRunHook(t, hook)
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("hook timeout not enforced: took %s", elapsed)
+	}
+	if result.Outcome.Status != types.OutcomePolicyFailure {
+		t.Fatalf("expected policy_failure, got %s", result.Outcome.Status)
+	}
+	if !strings.Contains(result.Outcome.Message, "timed out") {
+		t.Errorf("message should report the timeout, got %q", result.Outcome.Message)
+	}
+	if mockExec.started {
+		t.Error("executor must not start after a hook timeout")
+	}
+}
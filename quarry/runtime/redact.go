@@ -0,0 +1,126 @@
+package runtime
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RedactedValue replaces the value of every redacted payload field.
+const RedactedValue = "[REDACTED]"
+
+// Redactor scrubs configured keys from event payloads before they reach the
+// fan-out observer, the policy, and any sink.
+//
+// Two key forms are supported:
+//   - Plain key ("email"): matches a map key of that name at any depth.
+//   - Dotted path ("user.contact.email"): matches from the payload root only.
+//     Arrays along the path are traversed element-wise.
+//
+// A matched value is replaced wholesale; redacted subtrees are not descended.
+type Redactor struct {
+	keys  map[string]struct{}
+	paths [][]string
+}
+
+// NewRedactor builds a Redactor from key specs. Returns an error for empty
+// keys or malformed dotted paths (leading, trailing, or doubled dots).
+// Returns nil (no redaction) when keys is empty.
+func NewRedactor(keys []string) (*Redactor, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	r := &Redactor{keys: make(map[string]struct{})}
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, errors.New("redact key must not be empty")
+		}
+		if !strings.Contains(key, ".") {
+			r.keys[key] = struct{}{}
+			continue
+		}
+		segments := strings.Split(key, ".")
+		for _, seg := range segments {
+			if seg == "" {
+				return nil, fmt.Errorf("invalid redact path %q: empty segment", key)
+			}
+		}
+		r.paths = append(r.paths, segments)
+	}
+	return r, nil
+}
+
+// Redact replaces matching values in payload in place and returns the number
+// of fields redacted. Nil-receiver safe (returns 0).
+func (r *Redactor) Redact(payload map[string]any) int {
+	if r == nil || payload == nil {
+		return 0
+	}
+
+	count := 0
+	for _, path := range r.paths {
+		count += redactPath(payload, path)
+	}
+	if len(r.keys) > 0 {
+		count += r.redactKeys(payload)
+	}
+	return count
+}
+
+// redactKeys walks v and redacts every map entry whose key is configured.
+func (r *Redactor) redactKeys(v any) int {
+	count := 0
+	switch node := v.(type) {
+	case map[string]any:
+		for k, child := range node {
+			if _, ok := r.keys[k]; ok {
+				if !isRedacted(child) {
+					node[k] = RedactedValue
+					count++
+				}
+				continue
+			}
+			count += r.redactKeys(child)
+		}
+	case []any:
+		for _, child := range node {
+			count += r.redactKeys(child)
+		}
+	}
+	return count
+}
+
+// redactPath redacts the value at path under v, fanning out across arrays.
+func redactPath(v any, path []string) int {
+	switch node := v.(type) {
+	case map[string]any:
+		child, ok := node[path[0]]
+		if !ok {
+			return 0
+		}
+		if len(path) == 1 {
+			if isRedacted(child) {
+				return 0
+			}
+			node[path[0]] = RedactedValue
+			return 1
+		}
+		return redactPath(child, path[1:])
+	case []any:
+		count := 0
+		for _, child := range node {
+			count += redactPath(child, path)
+		}
+		return count
+	}
+	return 0
+}
+
+// isRedacted reports whether v was already replaced, so overlapping key and
+// path specs count each field once.
+func isRedacted(v any) bool {
+	s, ok := v.(string)
+	return ok && s == RedactedValue
+}
@@ -0,0 +1,169 @@
+package runtime
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNewRedactor(t *testing.T) {
+	tests := []struct {
+		name    string
+		keys    []string
+		wantNil bool
+		wantErr bool
+	}{
+		{name: "no keys", keys: nil, wantNil: true},
+		{name: "plain keys", keys: []string{"email", "phone"}},
+		{name: "dotted path", keys: []string{"user.email"}},
+		{name: "whitespace trimmed", keys: []string{" email "}},
+		{name: "empty key", keys: []string{"email", ""}, wantErr: true},
+		{name: "leading dot", keys: []string{".email"}, wantErr: true},
+		{name: "trailing dot", keys: []string{"user."}, wantErr: true},
+		{name: "double dot", keys: []string{"user..email"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRedactor(tt.keys)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (r == nil) != tt.wantNil {
+				t.Errorf("redactor nil = %v, want %v", r == nil, tt.wantNil)
+			}
+		})
+	}
+}
+
+func TestRedactor_Redact(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      []string
+		payload   map[string]any
+		want      map[string]any
+		wantCount int
+	}{
+		{
+			name:      "plain key at top level",
+			keys:      []string{"email"},
+			payload:   map[string]any{"email": "a@example.com", "name": "A"},
+			want:      map[string]any{"email": RedactedValue, "name": "A"},
+			wantCount: 1,
+		},
+		{
+			name: "plain key at any depth, including arrays",
+			keys: []string{"email"},
+			payload: map[string]any{
+				"data": map[string]any{
+					"users": []any{
+						map[string]any{"email": "a@example.com"},
+						map[string]any{"email": "b@example.com"},
+					},
+				},
+			},
+			want: map[string]any{
+				"data": map[string]any{
+					"users": []any{
+						map[string]any{"email": RedactedValue},
+						map[string]any{"email": RedactedValue},
+					},
+				},
+			},
+			wantCount: 2,
+		},
+		{
+			name: "plain key replaces subtree wholesale",
+			keys: []string{"contact"},
+			payload: map[string]any{
+				"contact": map[string]any{"email": "a@example.com", "phone": "555"},
+			},
+			want:      map[string]any{"contact": RedactedValue},
+			wantCount: 1,
+		},
+		{
+			name: "dotted path matches from root only",
+			keys: []string{"user.email"},
+			payload: map[string]any{
+				"user":  map[string]any{"email": "a@example.com"},
+				"email": "top@example.com",
+				"other": map[string]any{"user": map[string]any{"email": "nested@example.com"}},
+			},
+			want: map[string]any{
+				"user":  map[string]any{"email": RedactedValue},
+				"email": "top@example.com",
+				"other": map[string]any{"user": map[string]any{"email": "nested@example.com"}},
+			},
+			wantCount: 1,
+		},
+		{
+			name: "dotted path fans out across arrays",
+			keys: []string{"data.items.ssn"},
+			payload: map[string]any{
+				"data": map[string]any{
+					"items": []any{
+						map[string]any{"ssn": "1"},
+						map[string]any{"id": "no-ssn"},
+						map[string]any{"ssn": "2"},
+					},
+				},
+			},
+			want: map[string]any{
+				"data": map[string]any{
+					"items": []any{
+						map[string]any{"ssn": RedactedValue},
+						map[string]any{"id": "no-ssn"},
+						map[string]any{"ssn": RedactedValue},
+					},
+				},
+			},
+			wantCount: 2,
+		},
+		{
+			name:      "missing path is a no-op",
+			keys:      []string{"user.email"},
+			payload:   map[string]any{"user": "not-a-map"},
+			want:      map[string]any{"user": "not-a-map"},
+			wantCount: 0,
+		},
+		{
+			name:      "overlapping key and path counted once",
+			keys:      []string{"email", "user.email"},
+			payload:   map[string]any{"user": map[string]any{"email": "a@example.com"}},
+			want:      map[string]any{"user": map[string]any{"email": RedactedValue}},
+			wantCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := NewRedactor(tt.keys)
+			if err != nil {
+				t.Fatalf("NewRedactor: %v", err)
+			}
+			got := r.Redact(tt.payload)
+			if got != tt.wantCount {
+				t.Errorf("count = %d, want %d", got, tt.wantCount)
+			}
+			if !reflect.DeepEqual(tt.payload, tt.want) {
+				t.Errorf("payload = %#v, want %#v", tt.payload, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactor_NilSafe(t *testing.T) {
+	var r *Redactor
+	payload := map[string]any{"email": "a@example.com"}
+	if n := r.Redact(payload); n != 0 {
+		t.Errorf("nil redactor count = %d, want 0", n)
+	}
+	if payload["email"] != "a@example.com" {
+		t.Error("nil redactor must not modify payload")
+	}
+}
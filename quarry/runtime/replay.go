@@ -0,0 +1,92 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pithecene-io/quarry/clock"
+	"github.com/pithecene-io/quarry/lode"
+	"github.com/pithecene-io/quarry/policy"
+	"github.com/pithecene-io/quarry/types"
+)
+
+// PartitionReader supplies a persisted run's records for ReplayPartition.
+// Implemented by lode.RunPartitionReader.
+type PartitionReader interface {
+	ReadRunRecords(ctx context.Context) (*lode.RunRecords, error)
+}
+
+// ReplayResult summarizes a ReplayPartition call.
+type ReplayResult struct {
+	// Events is the number of events fed to the policy.
+	Events int64
+	// Chunks is the number of artifact chunks fed to the policy.
+	Chunks int64
+	// Duration covers ingestion and the final flush, not the read.
+	Duration time.Duration
+	// PolicyStats is the policy's statistics after the final flush.
+	PolicyStats policy.Stats
+}
+
+// ReplayPartition feeds a persisted run's events and chunks through pol
+// without an executor, then flushes it. Events are ingested in seq order;
+// an artifact's chunks are ingested just before its commit event, as the
+// executor emits them. Chunks without a commit event are ingested last.
+// The caller owns pol and closes it. clk times the replay.
+func ReplayPartition(ctx context.Context, reader PartitionReader, pol policy.Policy, clk clock.Clock) (*ReplayResult, error) {
+	records, err := reader.ReadRunRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	chunksByArtifact := make(map[string][]*types.ArtifactChunk)
+	var artifactOrder []string
+	for _, c := range records.Chunks {
+		if _, ok := chunksByArtifact[c.ArtifactID]; !ok {
+			artifactOrder = append(artifactOrder, c.ArtifactID)
+		}
+		chunksByArtifact[c.ArtifactID] = append(chunksByArtifact[c.ArtifactID], c)
+	}
+
+	result := &ReplayResult{}
+	start := clk.Now()
+	ingestChunks := func(artifactID string) error {
+		for _, c := range chunksByArtifact[artifactID] {
+			if err := pol.IngestArtifactChunk(ctx, c); err != nil {
+				return fmt.Errorf("replay artifact %s chunk %d: %w", artifactID, c.Seq, err)
+			}
+			result.Chunks++
+		}
+		delete(chunksByArtifact, artifactID)
+		return nil
+	}
+
+	for _, e := range records.Events {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if e.Type == types.EventTypeArtifact {
+			artifactID, _ := e.Payload["artifact_id"].(string)
+			if err := ingestChunks(artifactID); err != nil {
+				return nil, err
+			}
+		}
+		if err := pol.IngestEvent(ctx, e); err != nil {
+			return nil, fmt.Errorf("replay event seq %d: %w", e.Seq, err)
+		}
+		result.Events++
+	}
+	for _, artifactID := range artifactOrder {
+		if err := ingestChunks(artifactID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := pol.Flush(ctx); err != nil {
+		return nil, fmt.Errorf("replay flush: %w", err)
+	}
+	result.Duration = clk.Now().Sub(start)
+	result.PolicyStats = pol.Stats()
+	return result, nil
+}
@@ -0,0 +1,70 @@
+package runtime
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pithecene-io/quarry/clock"
+	"github.com/pithecene-io/quarry/lode"
+	"github.com/pithecene-io/quarry/policy"
+	"github.com/pithecene-io/quarry/types"
+)
+
+type stubPartitionReader struct {
+	records *lode.RunRecords
+}
+
+func (r stubPartitionReader) ReadRunRecords(context.Context) (*lode.RunRecords, error) {
+	return r.records, nil
+}
+
+func TestReplayPartition_PreservesOrder(t *testing.T) {
+	reader := stubPartitionReader{records: &lode.RunRecords{
+		Events: []*types.EventEnvelope{
+			{Seq: 1, Type: types.EventTypeItem},
+			{Seq: 2, Type: types.EventTypeArtifact, Payload: map[string]any{"artifact_id": "art-1"}},
+			{Seq: 3, Type: types.EventTypeRunComplete},
+		},
+		Chunks: []*types.ArtifactChunk{
+			{ArtifactID: "art-1", Seq: 1, Data: []byte("ab")},
+			{ArtifactID: "art-1", Seq: 2, IsLast: true, Data: []byte("c")},
+			{ArtifactID: "orphan", Seq: 1, IsLast: true, Data: []byte("x")},
+		},
+	}}
+	sink := policy.NewStubSink()
+
+	result, err := ReplayPartition(t.Context(), reader, policy.NewStrictPolicy(sink), clock.NewFake(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatalf("ReplayPartition: %v", err)
+	}
+	if result.Events != 3 || result.Chunks != 3 {
+		t.Errorf("result = %d events %d chunks, want 3/3", result.Events, result.Chunks)
+	}
+	if result.Duration != 0 {
+		t.Errorf("Duration = %s, want 0 on a clock that never advanced", result.Duration)
+	}
+	if result.PolicyStats.EventsPersisted != 3 {
+		t.Errorf("EventsPersisted = %d, want 3", result.PolicyStats.EventsPersisted)
+	}
+
+	// Strict writes each item as it arrives, so the write order is the feed order
+	var got []string
+	for _, op := range sink.WriteOrder {
+		for _, e := range op.Events {
+			got = append(got, string(e.Type))
+		}
+		for _, c := range op.Chunks {
+			got = append(got, "chunk:"+c.ArtifactID)
+		}
+	}
+	want := []string{"item", "chunk:art-1", "chunk:art-1", "artifact", "run_complete", "chunk:orphan"}
+	if len(got) != len(want) {
+		t.Fatalf("write order = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("write order = %v, want %v", got, want)
+		}
+	}
+}
@@ -18,11 +18,17 @@ type RunReport struct {
 	JobID      string             `json:"job_id,omitempty"`
 	Attempt    int                `json:"attempt"`
 	Outcome    types.OutcomeStatus `json:"outcome"`
+	Reason     types.OutcomeReason `json:"reason,omitempty"`
 	Message    string             `json:"message"`
 	ExitCode   int                `json:"exit_code"`
 	DurationMs int64              `json:"duration_ms"`
 	EventCount int64              `json:"event_count"`
 
+	// ContractVersion is the runtime's; ExecutorVersion is what the
+	// executor reported (omitted if none)
+	ContractVersion string `json:"contract_version"`
+	ExecutorVersion string `json:"executor_version,omitempty"`
+
 	Policy   *ReportPolicy   `json:"policy"`
 	Artifacts *ReportArtifacts `json:"artifacts"`
 	Metrics  *metrics.Snapshot `json:"metrics"`
@@ -39,6 +45,10 @@ type ReportPolicy struct {
 	EventsPersisted int64           `json:"events_persisted"`
 	EventsDropped   int64           `json:"events_dropped"`
 	FlushTriggers   map[string]int64 `json:"flush_triggers,omitempty"`
+	FieldsRedacted  int64           `json:"fields_redacted,omitempty"`
+	// Degradations counts strict policy degradations to buffering
+	// (--strict-degrade-buffer); omitted when none occurred.
+	Degradations int64 `json:"degradations,omitempty"`
 }
 
 // ReportArtifacts holds artifact stats in the report.
@@ -48,6 +58,9 @@ type ReportArtifacts struct {
 	Orphaned  int64 `json:"orphaned"`
 	Chunks    int64 `json:"chunks"`
 	Bytes     int64 `json:"bytes"`
+	// Sniffed counts artifacts whose content type was sniffed, by type
+	// (omitted unless --sniff-content-type replaced one).
+	Sniffed map[string]int64 `json:"sniffed,omitempty"`
 }
 
 // BuildRunReport composes a RunReport from a RunResult and metrics snapshot.
@@ -58,16 +71,21 @@ func BuildRunReport(result *RunResult, snap metrics.Snapshot, policyName string,
 		RunID:      result.RunMeta.RunID,
 		Attempt:    result.RunMeta.Attempt,
 		Outcome:    result.Outcome.Status,
+		Reason:     result.Outcome.Reason,
 		Message:    result.Outcome.Message,
 		ExitCode:   exitCode,
 		DurationMs: result.Duration.Milliseconds(),
 		EventCount: result.EventCount,
+		ContractVersion: types.ContractVersion,
+		ExecutorVersion: result.ExecutorVersion,
 		Policy: &ReportPolicy{
 			Name:            policyName,
 			EventsReceived:  result.PolicyStats.TotalEvents,
 			EventsPersisted: result.PolicyStats.EventsPersisted,
 			EventsDropped:   result.PolicyStats.EventsDropped,
 			FlushTriggers:   result.PolicyStats.FlushTriggers,
+			FieldsRedacted:  result.RedactedFields,
+			Degradations:    result.PolicyStats.Degradations,
 		},
 		Artifacts: &ReportArtifacts{
 			Total:     result.ArtifactStats.TotalArtifacts,
@@ -75,6 +93,7 @@ func BuildRunReport(result *RunResult, snap metrics.Snapshot, policyName string,
 			Orphaned:  result.ArtifactStats.OrphanedArtifacts,
 			Chunks:    result.ArtifactStats.TotalChunks,
 			Bytes:     result.ArtifactStats.TotalBytes,
+			Sniffed:   result.ArtifactStats.SniffedContentTypes,
 		},
 		Metrics:   &snap,
 		ProxyUsed: result.ProxyUsed,
@@ -186,6 +186,33 @@ func TestBuildRunReport_ProxyUsed(t *testing.T) {
 	}
 }
 
+func TestBuildRunReport_Versions(t *testing.T) {
+	result := newTestRunResult()
+	snap := newTestSnapshot()
+
+	report := BuildRunReport(result, snap, "strict", 0)
+	if report.ContractVersion != types.ContractVersion {
+		t.Errorf("ContractVersion = %q, want %q", report.ContractVersion, types.ContractVersion)
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+	if _, exists := raw["executor_version"]; exists {
+		t.Error("executor_version should be omitted when the executor did not report one")
+	}
+
+	result.ExecutorVersion = "0.0.1"
+	report = BuildRunReport(result, snap, "strict", 0)
+	if report.ExecutorVersion != "0.0.1" {
+		t.Errorf("ExecutorVersion = %q, want %q", report.ExecutorVersion, "0.0.1")
+	}
+}
+
 func TestWriteRunReport_File(t *testing.T) {
 	result := newTestRunResult()
 	snap := newTestSnapshot()
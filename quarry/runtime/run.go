@@ -2,10 +2,13 @@ package runtime
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"time"
 
+	"github.com/pithecene-io/quarry/clock"
+	"github.com/pithecene-io/quarry/ipc"
 	"github.com/pithecene-io/quarry/lode"
 	"github.com/pithecene-io/quarry/log"
 	"github.com/pithecene-io/quarry/metrics"
@@ -33,6 +36,9 @@ type RunConfig struct {
 	ScriptPath string
 	// Job is the job payload.
 	Job any
+	// SeedState is optional initial state for the script (--seed-state),
+	// passed to the executor separately from Job. Nil omits it.
+	SeedState map[string]any
 	// RunMeta is the run identity and lineage metadata.
 	RunMeta *types.RunMeta
 	// Proxy is the optional resolved proxy endpoint per CONTRACT_PROXY.md.
@@ -52,9 +58,11 @@ type RunConfig struct {
 	// BrowserWSEndpoint is the optional WebSocket URL of an externally managed browser.
 	// When set, the executor connects instead of launching a new Chromium instance.
 	BrowserWSEndpoint string
-	// ResolveFrom is the optional path to a node_modules directory used for
+	// ResolveFrom lists optional directories used, in order, for
 	// bare-specifier ESM resolution fallback in workspace/monorepo setups.
-	ResolveFrom string
+	ResolveFrom []string
+	// ExecutorArgs are passed through to the executor after its own args.
+	ExecutorArgs []string
 	// Source is the partition key for origin system/provider.
 	Source string
 	// Category is the partition key for logical data type (default: "default").
@@ -65,9 +73,109 @@ type RunConfig struct {
 	// StorageDay is the partition day (YYYY-MM-DD UTC).
 	// Used to construct StoragePartition for executor input.
 	StorageDay string
+	// StorageHour is the partition hour (HH UTC) for hourly partitions,
+	// derived from the same start time as StorageDay. Empty: daily.
+	StorageHour string
 	// Collector is the metrics collector for this run per CONTRACT_METRICS.md.
 	// If nil, no metrics are recorded (all Collector methods are nil-safe).
 	Collector *metrics.Collector
+	// FailOnDrops converts the outcome to policy_failure when the policy
+	// dropped any events, regardless of the executor outcome.
+	FailOnDrops bool
+	// AllowSeqGaps tolerates forward jumps in event seq (logged and counted
+	// in seq_gaps_total). Backward or duplicate seq remains fatal.
+	AllowSeqGaps bool
+	// StallTimeout is the inter-frame watchdog: if no frame is decoded within
+	// this duration the executor is killed and the run reports executor_crash.
+	// Zero disables the watchdog.
+	StallTimeout time.Duration
+	// MaxEventBytes rejects, as a stream error, any single event whose
+	// payload exceeds this many bytes (0 = disabled).
+	MaxEventBytes int64
+	// MaxRunBytes caps the event payload and artifact chunk bytes this run
+	// persists; exceeding it stops ingestion and fails the run with
+	// policy_failure / quota_exceeded (0 = unlimited).
+	MaxRunBytes int64
+	// ArtifactBudget bounds artifact bytes/count for this run (zero = unlimited).
+	// Exceeding it fails the run with policy_failure. Set per child by fan-out.
+	ArtifactBudget ArtifactBudget
+	// Redactor scrubs configured payload keys before persistence (nil = off).
+	Redactor *Redactor
+	// IngestMode selects which frame streams are persisted (default: all).
+	IngestMode IngestMode
+	// LogMinLevel drops log events below this payload.level before the
+	// policy (counted in log_filtered_total). Empty keeps all levels.
+	LogMinLevel types.LogLevel
+	// SampleRate keeps this fraction of item events, chosen by event_id
+	// hash (counted in events_sampled_out_total). 0 (unset) keeps all; the
+	// CLI only accepts 0 < r <= 1.
+	SampleRate float64
+	// EventFilter drops events it does not match before the policy
+	// (counted in events_filtered_total); artifacts, checkpoints, and
+	// terminals are always kept. Nil keeps all.
+	EventFilter *EventFilter
+	// Drain, when closed, stops ingestion after the in-flight frame, then the
+	// executor is killed and the policy flushed (graceful shutdown).
+	// Nil disables draining; cancel the context for a hard stop.
+	Drain <-chan struct{}
+	// ProxyRotator, when set with Proxy, answers rotate_proxy events with a
+	// fresh endpoint sent to the executor as a proxy_update frame.
+	// Nil ignores rotate_proxy beyond normal ingestion.
+	ProxyRotator ProxyRotator
+	// VerifyArtifacts checks per-chunk CRC32C and per-artifact sha256
+	// checksums when present and fails the run with a stream error on mismatch.
+	VerifyArtifacts bool
+	// SniffContentType replaces an artifact's empty or
+	// application/octet-stream content type with one detected from its
+	// first chunk, keeping the original as declared_content_type.
+	SniffContentType bool
+	// TelemetryMode declares the run log-only: seq ordering and the terminal
+	// event are not required, a clean exit is success, and any non-log event,
+	// artifact chunk, or file write fails the run.
+	TelemetryMode bool
+	// ArtifactSpillThreshold moves an artifact's reassembly buffer to a temp
+	// file once it exceeds this many bytes (0 = always in memory).
+	ArtifactSpillThreshold int64
+	// PreRunHook, when set, runs before the executor launches and can veto
+	// the run (policy_failure). Nil disables the hook.
+	PreRunHook *PreRunHook
+	// DomainPolicy, when set, blocks a job or enqueue targeting a denied
+	// domain (policy_failure, reason domain_denied). Nil allows all domains.
+	DomainPolicy *DomainPolicy
+	// PersistStderr writes captured executor stderr to the _stderr.log
+	// sidecar for every run; by default only failed runs persist it.
+	PersistStderr bool
+	// StderrMaxBytes caps _stderr.log, keeping the tail
+	// (0 = DefaultStderrMaxBytes).
+	StderrMaxBytes int64
+	// StderrTailLines caps RunResult.StderrOutput to the last lines of
+	// executor stderr (0 = DefaultStderrTailLines).
+	StderrTailLines int
+	// OutcomeEvaluator, when set, refines the exit-code outcome (e.g. a
+	// run_complete reporting a partial result). It cannot turn a failure
+	// into success. Nil keeps the default outcome.
+	OutcomeEvaluator OutcomeEvaluator
+	// MaxFrameBytes overrides the IPC frame size limit, including the length
+	// prefix, for both the executor and the decoder (0 = ipc.MaxFrameSize).
+	// Each frame is buffered whole, so this bounds the per-frame allocation.
+	MaxFrameBytes int64
+	// DecodeLimits bounds the msgpack nesting depth and container length of
+	// each frame payload; zero fields take the ipc defaults. A frame beyond
+	// them fails with a stream error instead of being decoded.
+	DecodeLimits ipc.DecodeLimits
+	// ArtifactObserver is notified once per committed artifact (commit event
+	// and all chunks received). Nil disables it.
+	ArtifactObserver ArtifactObserver
+	// DumpIPCPath, when set, captures the raw executor stdout frame stream
+	// to this file as ingestion reads it (best effort). Attempts after the
+	// first write to "<name>.attempt-N<ext>" instead. Empty disables it.
+	DumpIPCPath string
+	// DumpIPCMaxBytes caps the DumpIPCPath capture; later bytes are dropped
+	// with a warning (0 = DefaultIPCDumpMaxBytes).
+	DumpIPCMaxBytes int64
+	// Clock times the run, the executor launch, and the ingestion stall
+	// watchdog. Nil means clock.Real.
+	Clock clock.Clock
 }
 
 // RunResult represents the result of a run.
@@ -84,8 +192,12 @@ type RunResult struct {
 	ArtifactStats ArtifactStats
 	// OrphanIDs is the list of orphaned artifact IDs.
 	OrphanIDs []string
-	// StderrOutput is the captured executor stderr.
+	// StderrOutput is the captured executor stderr, cut to the last
+	// RunConfig.StderrTailLines lines behind a marker line.
 	StderrOutput string
+	// stderrLog is the captured executor stderr for the _stderr.log
+	// sidecar, cut to RunConfig.StderrMaxBytes.
+	stderrLog []byte
 	// EventCount is the total number of events processed.
 	EventCount int64
 	// ProxyUsed is the proxy endpoint used (redacted, no password).
@@ -94,12 +206,33 @@ type RunResult struct {
 	// TerminalSummary is the payload from the terminal event (run_complete or run_error).
 	// Nil if no terminal event was received.
 	TerminalSummary map[string]any
+	// FinalCheckpoint is the payload of the last checkpoint event.
+	// Nil if the run emitted no checkpoint.
+	FinalCheckpoint map[string]any
+	// BudgetExceeded is true if the run was failed by its ArtifactBudget.
+	BudgetExceeded bool
+	// RedactedFields is the number of payload fields replaced by the Redactor.
+	RedactedFields int64
+	// ArtifactsDiscarded is the number of artifacts skipped under IngestEventsOnly.
+	ArtifactsDiscarded int64
+	// EventsDiscarded is the number of events skipped under IngestArtifactsOnly.
+	EventsDiscarded int64
+	// LogsFiltered is the number of log events dropped by LogMinLevel.
+	LogsFiltered int64
+	// EventsSampledOut is the number of item events skipped by SampleRate.
+	EventsSampledOut int64
+	// EventsFiltered is the number of events dropped by EventFilter.
+	EventsFiltered int64
+	// ExecutorVersion is the version the executor reported in its
+	// run_result. Empty if it reported none.
+	ExecutorVersion string
 }
 
 // RunOrchestrator orchestrates a single run.
 type RunOrchestrator struct {
 	config    *RunConfig
 	logger    *log.Logger
+	clock     clock.Clock
 	startTime time.Time
 }
 
@@ -110,6 +243,12 @@ func NewRunOrchestrator(config *RunConfig) (*RunOrchestrator, error) {
 	if err := config.RunMeta.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid run metadata: %w", err)
 	}
+	if err := ipc.ValidateMaxFrameSize(config.MaxFrameBytes); err != nil {
+		return nil, fmt.Errorf("invalid max frame bytes: %w", err)
+	}
+	if err := config.DecodeLimits.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid decode limits: %w", err)
+	}
 
 	// Create logger with run context
 	logger := log.NewLogger(config.RunMeta)
@@ -117,6 +256,7 @@ func NewRunOrchestrator(config *RunConfig) (*RunOrchestrator, error) {
 	return &RunOrchestrator{
 		config: config,
 		logger: logger,
+		clock:  clock.Or(config.Clock),
 	}, nil
 }
 
@@ -124,14 +264,25 @@ func NewRunOrchestrator(config *RunConfig) (*RunOrchestrator, error) {
 // This is the main entry point for run orchestration.
 //
 // Execution flow:
-//  1. Start executor process
-//  2. Run IPC ingestion loop (concurrent)
-//  3. Wait for executor exit
-//  4. Flush policy
-//  5. Determine outcome
-//  6. Return result
+//  1. Run pre-run hook (if configured)
+//  2. Start executor process
+//  3. Run IPC ingestion loop (concurrent)
+//  4. Wait for executor exit
+//  5. Flush policy
+//  6. Determine outcome
+//  7. Persist executor stderr (failed runs, or all with PersistStderr)
+//  8. Return result
 func (r *RunOrchestrator) Execute(ctx context.Context) (*RunResult, error) {
-	r.startTime = time.Now()
+	result, err := r.execute(ctx)
+	if result != nil {
+		r.writeStderr(ctx, result)
+	}
+	return result, err
+}
+
+// execute runs steps 1-6 of Execute.
+func (r *RunOrchestrator) execute(ctx context.Context) (*RunResult, error) {
+	r.startTime = r.clock.Now()
 	r.config.Collector.IncRunStarted()
 
 	r.logger.Info("starting run", map[string]any{
@@ -139,6 +290,31 @@ func (r *RunOrchestrator) Execute(ctx context.Context) (*RunResult, error) {
 		"executor": r.config.ExecutorPath,
 	})
 
+	// Domain policy: the job's target is checked before anything else runs
+	if err := r.config.DomainPolicy.CheckTarget(r.config.Job); err != nil {
+		r.logger.Error("job target blocked by domain policy", map[string]any{
+			"error": err.Error(),
+		})
+		return r.buildResult(&types.RunOutcome{
+			Status:  types.OutcomePolicyFailure,
+			Reason:  types.ReasonDomainDenied,
+			Message: err.Error(),
+		}, nil, nil, nil), nil
+	}
+
+	// Pre-run hook: an external veto before any executor compute is spent
+	if r.config.PreRunHook != nil {
+		if err := r.config.PreRunHook.run(ctx, preRunHookInput(r.config)); err != nil {
+			r.logger.Warn("run vetoed by pre-run hook", map[string]any{
+				"error": err.Error(),
+			})
+			return r.buildResult(preRunHookOutcome(err), nil, nil, nil), nil
+		}
+	}
+
+	// Labels sidecar: written once the run is allowed to proceed
+	r.writeLabels(ctx)
+
 	// Pre-run health gate: verify external browser is reachable before launching
 	// the executor. Best-effort: warns on failure but does not block the run,
 	// because the health probe assumes /json/version at the host root, which is
@@ -161,10 +337,14 @@ func (r *RunOrchestrator) Execute(ctx context.Context) (*RunResult, error) {
 		ExecutorPath:      r.config.ExecutorPath,
 		ScriptPath:        r.config.ScriptPath,
 		Job:               r.config.Job,
+		SeedState:         r.config.SeedState,
 		RunMeta:           r.config.RunMeta,
 		Proxy:             r.config.Proxy,
 		BrowserWSEndpoint: r.config.BrowserWSEndpoint,
 		ResolveFrom:       r.config.ResolveFrom,
+		ExtraArgs:         r.config.ExecutorArgs,
+		MaxFrameBytes:     r.config.MaxFrameBytes,
+		StderrMaxBytes:    r.config.StderrMaxBytes,
 	}
 
 	// Attach storage partition metadata for SDK-side key computation
@@ -174,6 +354,7 @@ func (r *RunOrchestrator) Execute(ctx context.Context) (*RunResult, error) {
 			Source:   r.config.Source,
 			Category: r.config.Category,
 			Day:      r.config.StorageDay,
+			Hour:     r.config.StorageHour,
 			RunID:    r.config.RunMeta.RunID,
 		}
 	}
@@ -186,6 +367,7 @@ func (r *RunOrchestrator) Execute(ctx context.Context) (*RunResult, error) {
 	}
 
 	// Start executor
+	launchedAt := r.clock.Now()
 	if err := executor.Start(ctx); err != nil {
 		r.config.Collector.IncExecutorLaunchFailure()
 		r.logger.Error("failed to start executor", map[string]any{
@@ -201,19 +383,50 @@ func (r *RunOrchestrator) Execute(ctx context.Context) (*RunResult, error) {
 		flushCancel()
 		return r.buildResult(&types.RunOutcome{
 			Status:  types.OutcomeExecutorCrash,
+			Reason:  types.ReasonStartFailure,
 			Message: fmt.Sprintf("failed to start executor: %v", err),
-		}, "", nil, nil), nil
+		}, nil, nil, nil), nil
 	}
 
 	r.config.Collector.IncExecutorLaunchSuccess()
 
 	// Create artifact manager
 	artifacts := NewArtifactManager()
+	artifacts.SetBudget(r.config.ArtifactBudget)
+	artifacts.SetVerify(r.config.VerifyArtifacts)
+	artifacts.SetSniffContentType(r.config.SniffContentType)
+	artifacts.SetSpill(r.config.ArtifactSpillThreshold, "")
+	defer func() {
+		if err := artifacts.Close(); err != nil {
+			r.logger.Warn("failed to remove artifact spill files", map[string]any{"error": err.Error()})
+		}
+	}()
 
-	// Create ingestion engine with ack writer for file_write_ack frames.
-	// executor.Stdin() is kept open after metadata delivery for this purpose.
+	// Optionally tee the raw frame stream to the IPC dump (best effort)
+	stdout := executor.Stdout()
+	if r.config.DumpIPCPath != "" {
+		dumpPath := ipcDumpPath(r.config.DumpIPCPath, r.config.RunMeta.Attempt)
+		dump, err := openIPCDump(dumpPath, r.config.DumpIPCMaxBytes, r.logger)
+		if err != nil {
+			r.logger.Warn("failed to open ipc dump (continuing without capture)", map[string]any{
+				"path":  dumpPath,
+				"error": err.Error(),
+			})
+		} else {
+			stdout = dump.tee(stdout)
+			defer func() {
+				if err := dump.Close(); err != nil {
+					r.logger.Warn("failed to close ipc dump", map[string]any{"error": err.Error()})
+				}
+			}()
+		}
+	}
+
+	// Create ingestion engine with the control channel for runtime→executor
+	// frames. executor.Stdin() is kept open after metadata delivery for this purpose.
+	control := ipc.NewControlWriter(executor.Stdin())
 	ingestion := NewIngestionEngine(
-		executor.Stdout(),
+		stdout,
 		r.config.Policy,
 		artifacts,
 		r.config.FileWriter,
@@ -221,8 +434,28 @@ func (r *RunOrchestrator) Execute(ctx context.Context) (*RunResult, error) {
 		r.config.RunMeta,
 		r.config.Collector,
 		r.config.EnqueueObserver,
-		executor.Stdin(),
+		control,
 	)
+	ingestion.SetAllowSeqGaps(r.config.AllowSeqGaps)
+	ingestion.SetTelemetryMode(r.config.TelemetryMode)
+	ingestion.SetStallTimeout(r.config.StallTimeout)
+	ingestion.SetMaxEventBytes(r.config.MaxEventBytes)
+	ingestion.SetMaxRunBytes(r.config.MaxRunBytes)
+	ingestion.SetRedactor(r.config.Redactor)
+	ingestion.SetIngestMode(r.config.IngestMode)
+	ingestion.SetLogMinLevel(r.config.LogMinLevel)
+	ingestion.SetSampleRate(r.config.SampleRate)
+	ingestion.SetEventFilter(r.config.EventFilter)
+	ingestion.SetDrain(r.config.Drain)
+	ingestion.SetArtifactObserver(r.config.ArtifactObserver)
+	ingestion.SetClock(r.clock)
+	ingestion.SetLaunchTime(launchedAt)
+	ingestion.SetDomainPolicy(r.config.DomainPolicy)
+	_ = ingestion.SetMaxFrameBytes(r.config.MaxFrameBytes) // validated by NewRunOrchestrator
+	ingestion.SetDecodeLimits(r.config.DecodeLimits)
+	if r.config.ProxyRotator != nil && r.config.Proxy != nil {
+		ingestion.SetProxyRotator(r.config.ProxyRotator, r.config.Proxy)
+	}
 
 	// Run ingestion in goroutine
 	ingestionDone := make(chan error, 1)
@@ -236,9 +469,9 @@ func (r *RunOrchestrator) Execute(ctx context.Context) (*RunResult, error) {
 	// fail with "file already closed" even if data is still in the pipe buffer.
 	ingErr := <-ingestionDone
 
-	// Close stdin after ingestion completes — signals EOF to executor's AckReader.
-	// Errors are expected if the executor already exited.
-	if err := executor.Stdin().Close(); err != nil {
+	// Close the control channel after ingestion completes — signals EOF to
+	// executor's AckReader. Errors are expected if the executor already exited.
+	if err := control.Close(); err != nil {
 		r.logger.Debug("stdin close after ingestion (expected if executor exited)", map[string]any{
 			"error": err.Error(),
 		})
@@ -252,6 +485,10 @@ func (r *RunOrchestrator) Execute(ctx context.Context) (*RunResult, error) {
 			"is_policy": IsPolicyError(ingErr),
 		})
 		_ = executor.Kill()
+	} else if ingestion.Stalled() {
+		// Terminal event already received but stdout never closed
+		r.logger.Warn("killing executor stalled after terminal event", nil)
+		_ = executor.Kill()
 	}
 
 	// NOW call Wait() to reap the child process
@@ -269,6 +506,8 @@ func (r *RunOrchestrator) Execute(ctx context.Context) (*RunResult, error) {
 			"error": flushErr.Error(),
 		})
 	}
+	// Report artifacts whose chunks the flush persisted
+	ingestion.releaseArtifactCommits()
 
 	// Handle executor wait error
 	if execErr != nil {
@@ -277,8 +516,9 @@ func (r *RunOrchestrator) Execute(ctx context.Context) (*RunResult, error) {
 		})
 		return r.buildResult(&types.RunOutcome{
 			Status:  types.OutcomeExecutorCrash,
+			Reason:  types.ReasonWaitError,
 			Message: fmt.Sprintf("executor wait failed: %v", execErr),
-		}, "", artifacts, ingestion), nil
+		}, nil, artifacts, ingestion), nil
 	}
 
 	// Handle ingestion errors
@@ -301,10 +541,24 @@ func (r *RunOrchestrator) Execute(ctx context.Context) (*RunResult, error) {
 				Status:  types.OutcomeVersionMismatch,
 				Message: fmt.Sprintf("SDK/CLI version mismatch: %v. Update the quarry CLI to match your SDK version, or pin the SDK to match your CLI.", ingErr),
 			}
+		case errors.Is(ingErr, ErrStreamStalled):
+			outcome = &types.RunOutcome{
+				Status:  types.OutcomeExecutorCrash,
+				Message: fmt.Sprintf("executor stalled: no frame received within %s", r.config.StallTimeout),
+			}
+		case errors.Is(ingErr, ErrDrained):
+			outcome = &types.RunOutcome{
+				Status:  types.OutcomeExecutorCrash,
+				Message: fmt.Sprintf("run drained on shutdown before terminal event: %v", ingErr),
+			}
 		case IsCanceledError(ingErr):
+			verb := "canceled"
+			if ReasonFromIngestionError(ingErr) == types.ReasonTimeout {
+				verb = "timed out"
+			}
 			outcome = &types.RunOutcome{
 				Status:  types.OutcomeExecutorCrash,
-				Message: fmt.Sprintf("run canceled: %v", ingErr),
+				Message: fmt.Sprintf("run %s: %v", verb, ingErr),
 			}
 		default:
 			// Stream/frame errors are executor crash
@@ -313,16 +567,18 @@ func (r *RunOrchestrator) Execute(ctx context.Context) (*RunResult, error) {
 				Message: fmt.Sprintf("stream error: %v", ingErr),
 			}
 		}
+		outcome.Reason = ReasonFromIngestionError(ingErr)
 
-		return r.buildResult(outcome, string(execResult.StderrBytes), artifacts, ingestion), nil
+		return r.buildResult(outcome, execResult, artifacts, ingestion), nil
 	}
 
 	// If flush failed and there were no other errors, report policy failure
 	if flushErr != nil {
 		return r.buildResult(&types.RunOutcome{
 			Status:  types.OutcomePolicyFailure,
+			Reason:  types.ReasonFlushFailure,
 			Message: fmt.Sprintf("policy flush failed: %v", flushErr),
-		}, string(execResult.StderrBytes), artifacts, ingestion), nil
+		}, execResult, artifacts, ingestion), nil
 	}
 
 	// Determine outcome based on exit code and run_result frame.
@@ -359,6 +615,7 @@ func (r *RunOrchestrator) Execute(ctx context.Context) (*RunResult, error) {
 		// run_result provides supplementary context (message, error_type, stack)
 		outcome = &types.RunOutcome{
 			Status:    exitOutcome,
+			Reason:    reasonFromExitCode(execResult.ExitCode),
 			Message:   runResultOutcome.Message,
 			ErrorType: runResultOutcome.ErrorType,
 			Stack:     runResultOutcome.Stack,
@@ -373,22 +630,38 @@ func (r *RunOrchestrator) Execute(ctx context.Context) (*RunResult, error) {
 
 		r.logger.Info("run completed (from run_result)", map[string]any{
 			"outcome":   outcome.Status,
+			"reason":    outcome.Reason,
 			"exit_code": execResult.ExitCode,
-			"duration":  time.Since(r.startTime).String(),
+			"duration":  r.clock.Now().Sub(r.startTime).String(),
 		})
 	} else {
 		// Fall back to exit code + terminal event analysis
 		terminalEvent, hasTerminal := ingestion.GetTerminalEvent()
-		outcome = DetermineOutcome(execResult.ExitCode, hasTerminal, terminalEvent)
+		if r.config.TelemetryMode {
+			outcome = DetermineTelemetryOutcome(execResult.ExitCode, hasTerminal, terminalEvent)
+		} else {
+			outcome = DetermineOutcome(execResult.ExitCode, hasTerminal, terminalEvent)
+		}
 		r.logger.Info("run completed", map[string]any{
 			"outcome":      outcome.Status,
+			"reason":       outcome.Reason,
 			"exit_code":    execResult.ExitCode,
-			"duration":     time.Since(r.startTime).String(),
+			"duration":     r.clock.Now().Sub(r.startTime).String(),
 			"has_terminal": hasTerminal,
 		})
 	}
 
-	return r.buildResult(outcome, string(execResult.StderrBytes), artifacts, ingestion), nil
+	terminalEvent, _ := ingestion.GetTerminalEvent()
+	if refined, changed := applyOutcomeEvaluator(r.config.OutcomeEvaluator, outcome, terminalEvent, execResult.ExitCode); changed {
+		r.logger.Info("outcome refined by evaluator", map[string]any{
+			"outcome":          refined.Status,
+			"reason":           refined.Reason,
+			"original_outcome": outcome.Status,
+		})
+		outcome = refined
+	}
+
+	return r.buildResult(outcome, execResult, artifacts, ingestion), nil
 }
 
 // runResultOutcomeToRunOutcome converts a RunResultFrame to a RunOutcome.
@@ -425,37 +698,72 @@ func runResultOutcomeToRunOutcome(frame *types.RunResultFrame) *types.RunOutcome
 // buildResult constructs the final run result.
 func (r *RunOrchestrator) buildResult(
 	outcome *types.RunOutcome,
-	stderrOutput string,
+	execResult *ExecutorResult,
 	artifacts *ArtifactManager,
 	ingestion *IngestionEngine,
 ) *RunResult {
 	result := &RunResult{
 		RunMeta:      r.config.RunMeta,
 		Outcome:      outcome,
-		Duration:     time.Since(r.startTime),
+		Duration:     r.clock.Now().Sub(r.startTime),
 		PolicyStats:  r.config.Policy.Stats(),
-		StderrOutput: stderrOutput,
+	}
+	if execResult != nil {
+		result.StderrOutput, result.stderrLog = r.executorStderr(execResult)
+	}
+
+	// Post-run drop gate (--fail-on-drops): evaluated before outcome
+	// metrics so counters reflect the final status.
+	if r.config.FailOnDrops {
+		if gated := applyDropGate(outcome, result.PolicyStats); gated != outcome {
+			r.logger.Warn("run failed by drop gate", map[string]any{
+				"events_dropped":   result.PolicyStats.EventsDropped,
+				"original_outcome": outcome.Status,
+			})
+			outcome = gated
+			result.Outcome = gated
+		}
 	}
 
 	// Set redacted proxy (per CONTRACT_PROXY.md: exclude password)
-	// Prefer run_result.proxy_used if available, otherwise use config.Proxy
+	// Prefer run_result.proxy_used if available, otherwise use config.Proxy.
+	// An external browser keeps the proxy it was launched with, so only the
+	// executor's report is truthful there: no report means no known proxy.
 	if ingestion != nil {
 		if runResult := ingestion.GetRunResult(); runResult != nil && runResult.ProxyUsed != nil {
 			result.ProxyUsed = runResult.ProxyUsed
 		}
 	}
-	if result.ProxyUsed == nil && r.config.Proxy != nil {
-		redacted := r.config.Proxy.Redact()
+	// Record the executor's reported version (ingestion warns on skew)
+	if ingestion != nil {
+		if runResult := ingestion.GetRunResult(); runResult != nil {
+			result.ExecutorVersion = runResult.ExecutorVersion
+			r.config.Collector.SetExecutorVersion(runResult.ExecutorVersion)
+		}
+	}
+	if result.ProxyUsed == nil && r.config.Proxy != nil && r.config.BrowserWSEndpoint == "" {
+		proxy := r.config.Proxy
+		if ingestion != nil && ingestion.CurrentProxy() != nil {
+			proxy = ingestion.CurrentProxy() // last endpoint sent on rotation
+		}
+		redacted := proxy.Redact()
 		result.ProxyUsed = &redacted
 	}
 
 	if artifacts != nil {
 		result.ArtifactStats = artifacts.Stats()
 		result.OrphanIDs = artifacts.GetOrphanIDs()
+		result.BudgetExceeded = artifacts.BudgetExceeded()
 	}
 
 	if ingestion != nil {
 		result.EventCount = ingestion.CurrentSeq()
+		result.RedactedFields = ingestion.RedactedFields()
+		result.ArtifactsDiscarded = ingestion.ArtifactsDiscarded()
+		result.EventsDiscarded = ingestion.EventsDiscarded()
+		result.LogsFiltered = ingestion.LogsFiltered()
+		result.EventsSampledOut = ingestion.EventsSampledOut()
+		result.EventsFiltered = ingestion.EventsFiltered()
 		if termEvent, hasTerm := ingestion.GetTerminalEvent(); hasTerm {
 			if termEvent.Payload != nil {
 				result.TerminalSummary = termEvent.Payload
@@ -463,6 +771,9 @@ func (r *RunOrchestrator) buildResult(
 				result.TerminalSummary = map[string]any{}
 			}
 		}
+		if checkpoint := ingestion.LastCheckpoint(); checkpoint != nil {
+			result.FinalCheckpoint = checkpoint.Payload
+		}
 	}
 
 	// Record run outcome metrics per CONTRACT_METRICS.md
@@ -474,6 +785,7 @@ func (r *RunOrchestrator) buildResult(
 	case types.OutcomeExecutorCrash:
 		r.config.Collector.IncRunCrashed()
 	}
+	r.config.Collector.IncRunReason(string(outcome.Reason))
 
 	// Absorb policy stats into the metrics collector
 	ps := result.PolicyStats
@@ -482,6 +794,8 @@ func (r *RunOrchestrator) buildResult(
 		droppedByType[string(k)] = v
 	}
 	r.config.Collector.AbsorbPolicyStats(ps.TotalEvents, ps.EventsPersisted, ps.EventsDropped, droppedByType, ps.FlushTriggers)
+	r.config.Collector.AbsorbFlushLatency(ps.FlushLatencyHistogram)
+	r.config.Collector.AbsorbPolicyDegradations(ps.Degradations)
 
 	return result
 }
@@ -4,13 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/vmihailenco/msgpack/v5"
 
+	"github.com/pithecene-io/quarry/clock"
+	"github.com/pithecene-io/quarry/ipc"
+	"github.com/pithecene-io/quarry/metrics"
 	"github.com/pithecene-io/quarry/policy"
 	"github.com/pithecene-io/quarry/types"
 )
@@ -28,6 +34,7 @@ type mockExecutor struct {
 	killChan    chan struct{} // signals Wait to return when Kill is called
 	releaseChan chan struct{} // signals Wait to return for normal completion
 	blockOnWait bool          // if true, Wait blocks until kill or release
+	stderr      []byte        // returned as ExecutorResult.StderrBytes
 }
 
 func newMockExecutor(stdout []byte, exitCode int) *mockExecutor {
@@ -89,9 +96,13 @@ func (m *mockExecutor) Wait() (*ExecutorResult, error) {
 	if m.waitErr != nil {
 		return nil, m.waitErr
 	}
+	stderr := m.stderr
+	if stderr == nil {
+		stderr = []byte{}
+	}
 	return &ExecutorResult{
 		ExitCode:    m.exitCode,
-		StderrBytes: []byte{},
+		StderrBytes: stderr,
 	}, nil
 }
 
@@ -376,6 +387,38 @@ func TestRunOrchestrator_ExecutorKilledOnPolicyError(t *testing.T) {
 	}
 }
 
+func TestRunOrchestrator_ClockDrivesDuration(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-clock", Attempt: 1}
+	mockExec := newMockExecutor(makeValidEventStream(runMeta), 0)
+
+	// The executor is built after the start time is captured, so the
+	// advance lands inside the run
+	clk := clock.NewFake(time.Date(2026, 2, 23, 23, 59, 0, 0, time.UTC))
+	orchestrator, err := NewRunOrchestrator(&RunConfig{
+		ExecutorPath: "/fake/executor",
+		ScriptPath:   "/fake/script.js",
+		Job:          map[string]any{},
+		RunMeta:      runMeta,
+		Policy:       newFlushTrackingPolicy(),
+		Clock:        clk,
+		ExecutorFactory: func(_ *ExecutorConfig) Executor {
+			clk.Advance(90 * time.Second)
+			return mockExec
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+
+	result, err := orchestrator.Execute(t.Context())
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if result.Duration != 90*time.Second {
+		t.Errorf("Duration = %s, want exactly the 90s the clock advanced", result.Duration)
+	}
+}
+
 func TestRunOrchestrator_SuccessfulRun(t *testing.T) {
 	runMeta := &types.RunMeta{
 		RunID:   "run-success",
@@ -425,6 +468,51 @@ func TestRunOrchestrator_SuccessfulRun(t *testing.T) {
 	}
 }
 
+func TestRunOrchestrator_ProxyUsedFallback(t *testing.T) {
+	tests := []struct {
+		name              string
+		browserWSEndpoint string
+		wantHost          string // "" = no proxy reported
+	}{
+		{"launched browser uses configured proxy", "", "proxy.example.com"},
+		// The executor reported no proxy_used, so the external browser's
+		// proxy is unknown; the configured endpoint is not assumed
+		{"external browser without report", "ws://127.0.0.1:1/devtools/browser/x", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runMeta := &types.RunMeta{RunID: "run-proxy", Attempt: 1}
+			mockExec := newMockExecutor(makeValidEventStream(runMeta), 0)
+			orchestrator, err := NewRunOrchestrator(&RunConfig{
+				ExecutorPath:      "/fake/executor",
+				ScriptPath:        "/fake/script.js",
+				Job:               map[string]any{},
+				RunMeta:           runMeta,
+				Policy:            newFlushTrackingPolicy(),
+				Proxy:             &types.ProxyEndpoint{Protocol: types.ProxyProtocolHTTP, Host: "proxy.example.com", Port: 8080},
+				BrowserWSEndpoint: tt.browserWSEndpoint,
+				ExecutorFactory: func(_ *ExecutorConfig) Executor {
+					return mockExec
+				},
+			})
+			if err != nil {
+				t.Fatalf("failed to create orchestrator: %v", err)
+			}
+
+			result, err := orchestrator.Execute(t.Context())
+			if err != nil {
+				t.Fatalf("Execute returned error: %v", err)
+			}
+			switch {
+			case tt.wantHost == "" && result.ProxyUsed != nil:
+				t.Errorf("ProxyUsed = %+v, want nil", result.ProxyUsed)
+			case tt.wantHost != "" && (result.ProxyUsed == nil || result.ProxyUsed.Host != tt.wantHost):
+				t.Errorf("ProxyUsed = %+v, want host %s", result.ProxyUsed, tt.wantHost)
+			}
+		})
+	}
+}
+
 func TestRunOrchestrator_FlushCalledOnExecutorWaitError(t *testing.T) {
 	runMeta := &types.RunMeta{
 		RunID:   "run-flush-wait-err",
@@ -619,6 +707,9 @@ func TestRunOrchestrator_VersionMismatch_OutcomeMapping(t *testing.T) {
 	if result.Outcome.Status != types.OutcomeVersionMismatch {
 		t.Errorf("expected OutcomeVersionMismatch, got %s: %s", result.Outcome.Status, result.Outcome.Message)
 	}
+	if result.Outcome.Reason != types.ReasonVersionMismatch {
+		t.Errorf("expected reason %s, got %s", types.ReasonVersionMismatch, result.Outcome.Reason)
+	}
 
 	// Verify actionable message
 	if result.Outcome.Message == "" {
@@ -1075,6 +1166,7 @@ func TestOutcomeMapping_DetermineOutcome(t *testing.T) {
 		hasTerminal    bool
 		terminalType   types.EventType
 		expectedStatus types.OutcomeStatus
+		expectedReason types.OutcomeReason
 	}{
 		{
 			name:           "exit 0 with run_complete",
@@ -1082,6 +1174,7 @@ func TestOutcomeMapping_DetermineOutcome(t *testing.T) {
 			hasTerminal:    true,
 			terminalType:   types.EventTypeRunComplete,
 			expectedStatus: types.OutcomeSuccess,
+			expectedReason: types.ReasonCompleted,
 		},
 		{
 			name:           "exit 0 without terminal (anomaly)",
@@ -1089,6 +1182,7 @@ func TestOutcomeMapping_DetermineOutcome(t *testing.T) {
 			hasTerminal:    false,
 			terminalType:   "",
 			expectedStatus: types.OutcomeExecutorCrash,
+			expectedReason: types.ReasonMissingTerminal,
 		},
 		{
 			name:           "exit 1 with run_error",
@@ -1096,6 +1190,7 @@ func TestOutcomeMapping_DetermineOutcome(t *testing.T) {
 			hasTerminal:    true,
 			terminalType:   types.EventTypeRunError,
 			expectedStatus: types.OutcomeScriptError,
+			expectedReason: types.ReasonScriptError,
 		},
 		{
 			name:           "exit 1 without terminal (anomaly)",
@@ -1103,6 +1198,7 @@ func TestOutcomeMapping_DetermineOutcome(t *testing.T) {
 			hasTerminal:    false,
 			terminalType:   "",
 			expectedStatus: types.OutcomeExecutorCrash,
+			expectedReason: types.ReasonMissingTerminal,
 		},
 		{
 			name:           "exit 2 crash",
@@ -1110,6 +1206,7 @@ func TestOutcomeMapping_DetermineOutcome(t *testing.T) {
 			hasTerminal:    false,
 			terminalType:   "",
 			expectedStatus: types.OutcomeExecutorCrash,
+			expectedReason: types.ReasonExecutorCrash,
 		},
 		{
 			name:           "exit 3 invalid input",
@@ -1117,6 +1214,7 @@ func TestOutcomeMapping_DetermineOutcome(t *testing.T) {
 			hasTerminal:    false,
 			terminalType:   "",
 			expectedStatus: types.OutcomeExecutorCrash,
+			expectedReason: types.ReasonInvalidInput,
 		},
 	}
 
@@ -1132,6 +1230,98 @@ func TestOutcomeMapping_DetermineOutcome(t *testing.T) {
 				t.Errorf("DetermineOutcome(%d, %v, %v) = %s, want %s",
 					tt.exitCode, tt.hasTerminal, tt.terminalType, outcome.Status, tt.expectedStatus)
 			}
+			if outcome.Reason != tt.expectedReason {
+				t.Errorf("DetermineOutcome(%d, %v, %v) reason = %s, want %s",
+					tt.exitCode, tt.hasTerminal, tt.terminalType, outcome.Reason, tt.expectedReason)
+			}
+		})
+	}
+}
+
+func TestOutcomeMapping_DetermineTelemetryOutcome(t *testing.T) {
+	if got := DetermineTelemetryOutcome(ExitCodeCompleted, false, nil); got.Status != types.OutcomeSuccess || got.Reason != types.ReasonCompleted {
+		t.Errorf("exit 0 without terminal = %s/%s, want success/completed", got.Status, got.Reason)
+	}
+	complete := &types.EventEnvelope{Type: types.EventTypeRunComplete}
+	if got := DetermineTelemetryOutcome(ExitCodeCompleted, true, complete); got.Status != types.OutcomeSuccess {
+		t.Errorf("exit 0 with run_complete = %s, want success", got.Status)
+	}
+	if got := DetermineTelemetryOutcome(ExitCodeError, false, nil); got.Status != types.OutcomeExecutorCrash || got.Reason != types.ReasonMissingTerminal {
+		t.Errorf("exit 1 without terminal = %s/%s, want executor_crash/missing_terminal", got.Status, got.Reason)
+	}
+	if got := DetermineTelemetryOutcome(ExitCodeCrash, false, nil); got.Status != types.OutcomeExecutorCrash {
+		t.Errorf("exit 2 = %s, want executor_crash", got.Status)
+	}
+}
+
+func TestRunOrchestrator_CancelCause(t *testing.T) {
+	tests := []struct {
+		name        string
+		cause       error
+		wantReason  types.OutcomeReason
+		wantMessage string
+	}{
+		{"interrupted", &CancelCause{Reason: types.ReasonInterrupted, Message: "received terminated"}, types.ReasonInterrupted, "run canceled: received terminated"},
+		{"caller timeout", &CancelCause{Reason: types.ReasonTimeout, Message: "max runtime 1m elapsed"}, types.ReasonTimeout, "run timed out: max runtime 1m elapsed"},
+		{"no cause", nil, types.ReasonCanceled, "run canceled: context canceled"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runMeta := &types.RunMeta{RunID: "run-cancel", Attempt: 1}
+			mockExec := newMockExecutor(makeValidEventStream(runMeta), 0)
+			orchestrator, err := NewRunOrchestrator(&RunConfig{
+				ExecutorPath: "/fake/executor",
+				ScriptPath:   "/fake/script.js",
+				RunMeta:      runMeta,
+				Policy:       newFlushTrackingPolicy(),
+				ExecutorFactory: func(_ *ExecutorConfig) Executor {
+					return mockExec
+				},
+			})
+			if err != nil {
+				t.Fatalf("failed to create orchestrator: %v", err)
+			}
+
+			ctx, cancel := context.WithCancelCause(t.Context())
+			cancel(tt.cause)
+			result, err := orchestrator.Execute(ctx)
+			if err != nil {
+				t.Fatalf("Execute returned error: %v", err)
+			}
+			if result.Outcome.Reason != tt.wantReason || result.Outcome.Message != tt.wantMessage {
+				t.Errorf("outcome = %s %q, want %s %q", result.Outcome.Reason, result.Outcome.Message, tt.wantReason, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestReasonFromIngestionError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want types.OutcomeReason
+	}{
+		{"stall", &IngestionError{Kind: IngestionErrorStream, Err: fmt.Errorf("%w: 5s", ErrStreamStalled)}, types.ReasonTimeout},
+		{"deadline", &IngestionError{Kind: IngestionErrorCanceled, Err: context.DeadlineExceeded}, types.ReasonTimeout},
+		{"canceled", &IngestionError{Kind: IngestionErrorCanceled, Err: context.Canceled}, types.ReasonCanceled},
+		{"cancel cause", &IngestionError{Kind: IngestionErrorCanceled, Err: &CancelCause{Reason: types.ReasonInterrupted, Message: "received terminated"}}, types.ReasonInterrupted},
+		{"cancel cause without reason", &IngestionError{Kind: IngestionErrorCanceled, Err: &CancelCause{Message: "stop"}}, types.ReasonCanceled},
+		{"drained", &IngestionError{Kind: IngestionErrorCanceled, Err: ErrDrained}, types.ReasonDrained},
+		{"version mismatch", &IngestionError{Kind: IngestionErrorVersionMismatch, Err: errors.New("skew")}, types.ReasonVersionMismatch},
+		{"policy", &IngestionError{Kind: IngestionErrorPolicy, Err: errors.New("sink down")}, types.ReasonPolicyFailure},
+		{"budget", &IngestionError{Kind: IngestionErrorPolicy, Err: fmt.Errorf("artifact chunk failed: %w", ErrArtifactBudgetExceeded)}, types.ReasonBudgetExceeded},
+		{"checksum", &IngestionError{Kind: IngestionErrorStream, Err: fmt.Errorf("artifact chunk failed: %w", ErrArtifactChecksumMismatch)}, types.ReasonChecksumMismatch},
+		{"truncated", &IngestionError{Kind: IngestionErrorStream, Err: fmt.Errorf("frame error: %w", &ipc.FrameError{Kind: ipc.FrameErrorPartial, Msg: "short read"})}, types.ReasonStreamTruncated},
+		{"oversize", &IngestionError{Kind: IngestionErrorStream, Err: fmt.Errorf("frame error: %w", &ipc.FrameError{Kind: ipc.FrameErrorTooLarge, Msg: "too large"})}, types.ReasonOversizeFrame},
+		{"decode", &IngestionError{Kind: IngestionErrorStream, Err: fmt.Errorf("frame decode error: %w", &ipc.FrameError{Kind: ipc.FrameErrorDecode, Msg: "bad msgpack"})}, types.ReasonDecodeError},
+		{"sequence", &IngestionError{Kind: IngestionErrorStream, Err: errors.New("sequence violation: expected 2, got 5")}, types.ReasonStreamError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ReasonFromIngestionError(tt.err); got != tt.want {
+				t.Errorf("ReasonFromIngestionError(%v) = %s, want %s", tt.err, got, tt.want)
+			}
 		})
 	}
 }
@@ -1447,3 +1637,348 @@ func TestRunOrchestrator_NoSilentDataLoss_ErrorSurfaced(t *testing.T) {
 		t.Error("outcome message should describe the failure (not empty)")
 	}
 }
+
+// makeEventStreamWithLogDrop creates a stream with a droppable log event
+// followed by run_complete. NoopPolicy counts the log event as dropped.
+func makeEventStreamWithLogDrop(runMeta *types.RunMeta) []byte {
+	var buf []byte
+	buf = append(buf, encodeTestEventFrame(&types.EventEnvelope{
+		ContractVersion: types.ContractVersion,
+		EventID:         "evt-1",
+		RunID:           runMeta.RunID,
+		Seq:             1,
+		Type:            types.EventTypeLog,
+		Ts:              "2024-01-01T00:00:00Z",
+		Payload:         map[string]any{"level": "info", "message": "hello"},
+		Attempt:         runMeta.Attempt,
+	})...)
+	buf = append(buf, encodeTestEventFrame(&types.EventEnvelope{
+		ContractVersion: types.ContractVersion,
+		EventID:         "evt-2",
+		RunID:           runMeta.RunID,
+		Seq:             2,
+		Type:            types.EventTypeRunComplete,
+		Ts:              "2024-01-01T00:00:01Z",
+		Payload:         map[string]any{},
+		Attempt:         runMeta.Attempt,
+	})...)
+	return buf
+}
+
+func TestRunOrchestrator_ArtifactBudgetExceeded(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-budget", Attempt: 1}
+
+	// Stream carries a 9-byte chunk ("test data"); budget allows 4
+	mockExec := newMockExecutor(makeEventStreamWithArtifactChunk(runMeta), 0)
+
+	config := &RunConfig{
+		ExecutorPath:   "/fake/executor",
+		ScriptPath:     "/fake/script.js",
+		Job:            map[string]any{},
+		RunMeta:        runMeta,
+		Policy:         policy.NewNoopPolicy(),
+		ArtifactBudget: ArtifactBudget{MaxBytes: 4},
+		ExecutorFactory: func(_ *ExecutorConfig) Executor {
+			return mockExec
+		},
+	}
+
+	orchestrator, err := NewRunOrchestrator(config)
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+
+	result, err := orchestrator.Execute(t.Context())
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	if result.Outcome.Status != types.OutcomePolicyFailure {
+		t.Fatalf("expected OutcomePolicyFailure, got %s: %s", result.Outcome.Status, result.Outcome.Message)
+	}
+	if !strings.Contains(result.Outcome.Message, "artifact budget exceeded") {
+		t.Errorf("message should mention the budget, got %q", result.Outcome.Message)
+	}
+	if !result.BudgetExceeded {
+		t.Error("BudgetExceeded = false, want true")
+	}
+}
+
+func TestRunOrchestrator_FailOnDrops(t *testing.T) {
+	tests := []struct {
+		name        string
+		failOnDrops bool
+		wantStatus  types.OutcomeStatus
+	}{
+		{"disabled", false, types.OutcomeSuccess},
+		{"enabled", true, types.OutcomePolicyFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runMeta := &types.RunMeta{RunID: "run-drops", Attempt: 1}
+			mockExec := newMockExecutor(makeEventStreamWithLogDrop(runMeta), 0)
+			collector := metrics.NewCollector("noop", "executor", "fs", runMeta.RunID, "")
+
+			config := &RunConfig{
+				ExecutorPath: "/fake/executor",
+				ScriptPath:   "/fake/script.js",
+				Job:          map[string]any{},
+				RunMeta:      runMeta,
+				Policy:       policy.NewNoopPolicy(),
+				Collector:    collector,
+				FailOnDrops:  tt.failOnDrops,
+				ExecutorFactory: func(_ *ExecutorConfig) Executor {
+					return mockExec
+				},
+			}
+
+			orchestrator, err := NewRunOrchestrator(config)
+			if err != nil {
+				t.Fatalf("failed to create orchestrator: %v", err)
+			}
+
+			result, err := orchestrator.Execute(t.Context())
+			if err != nil {
+				t.Fatalf("Execute returned error: %v", err)
+			}
+
+			if result.Outcome.Status != tt.wantStatus {
+				t.Fatalf("expected %s, got %s: %s", tt.wantStatus, result.Outcome.Status, result.Outcome.Message)
+			}
+
+			snap := collector.Snapshot()
+			if tt.failOnDrops {
+				if !strings.Contains(result.Outcome.Message, "log=1") {
+					t.Errorf("expected message to list dropped-by-type counts, got %q", result.Outcome.Message)
+				}
+				if snap.RunsFailed != 1 || snap.RunsCompleted != 0 {
+					t.Errorf("expected runs_failed=1 runs_completed=0, got %d/%d", snap.RunsFailed, snap.RunsCompleted)
+				}
+			} else if snap.RunsCompleted != 1 {
+				t.Errorf("expected runs_completed=1, got %d", snap.RunsCompleted)
+			}
+		})
+	}
+}
+
+func TestApplyDropGate(t *testing.T) {
+	dropped := policy.Stats{
+		EventsDropped: 3,
+		DroppedByType: map[types.EventType]int64{
+			types.EventTypeLog:     2,
+			types.EventTypeEnqueue: 1,
+		},
+	}
+
+	t.Run("no drops leaves outcome unchanged", func(t *testing.T) {
+		outcome := &types.RunOutcome{Status: types.OutcomeSuccess, Message: "ok"}
+		if got := applyDropGate(outcome, policy.Stats{}); got != outcome {
+			t.Errorf("expected unchanged outcome, got %+v", got)
+		}
+	})
+
+	t.Run("success becomes policy_failure", func(t *testing.T) {
+		outcome := &types.RunOutcome{Status: types.OutcomeSuccess, Message: "ok"}
+		got := applyDropGate(outcome, dropped)
+		if got.Status != types.OutcomePolicyFailure {
+			t.Fatalf("expected policy_failure, got %s", got.Status)
+		}
+		want := "fail-on-drops: 3 events dropped (enqueue=1, log=2)"
+		if got.Message != want {
+			t.Errorf("message = %q, want %q", got.Message, want)
+		}
+	})
+
+	t.Run("script_error preserves original outcome", func(t *testing.T) {
+		outcome := &types.RunOutcome{Status: types.OutcomeScriptError, Message: "boom"}
+		got := applyDropGate(outcome, dropped)
+		if got.Status != types.OutcomePolicyFailure {
+			t.Fatalf("expected policy_failure, got %s", got.Status)
+		}
+		if !strings.Contains(got.Message, "original outcome script_error: boom") {
+			t.Errorf("expected original outcome in message, got %q", got.Message)
+		}
+	})
+
+	t.Run("policy_failure unchanged", func(t *testing.T) {
+		outcome := &types.RunOutcome{Status: types.OutcomePolicyFailure, Message: "flush failed"}
+		if got := applyDropGate(outcome, dropped); got != outcome {
+			t.Errorf("expected unchanged outcome, got %+v", got)
+		}
+	})
+}
+
+// partialEvaluator downgrades a run_complete carrying payload.partial=true.
+func partialEvaluator(terminal *types.EventEnvelope, _ int) *types.RunOutcome {
+	if terminal == nil || terminal.Type != types.EventTypeRunComplete {
+		return nil
+	}
+	if partial, _ := terminal.Payload["partial"].(bool); !partial {
+		return nil
+	}
+	return &types.RunOutcome{Status: types.OutcomeScriptError, Message: "partial result"}
+}
+
+func TestApplyOutcomeEvaluator(t *testing.T) {
+	partial := &types.EventEnvelope{Type: types.EventTypeRunComplete, Payload: map[string]any{"partial": true}}
+	success := DetermineOutcome(ExitCodeCompleted, true, partial)
+
+	t.Run("nil evaluator leaves outcome unchanged", func(t *testing.T) {
+		if got, changed := applyOutcomeEvaluator(nil, success, partial, ExitCodeCompleted); changed || got != success {
+			t.Errorf("expected unchanged outcome, got %+v", got)
+		}
+	})
+
+	t.Run("downgrades partial run_complete", func(t *testing.T) {
+		got, changed := applyOutcomeEvaluator(partialEvaluator, success, partial, ExitCodeCompleted)
+		if !changed || got.Status != types.OutcomeScriptError {
+			t.Fatalf("expected script_error, got %+v", got)
+		}
+		if got.Reason != types.ReasonOutcomeEvaluator {
+			t.Errorf("reason = %s, want %s", got.Reason, types.ReasonOutcomeEvaluator)
+		}
+		if got.Message != "partial result" {
+			t.Errorf("message = %q, want evaluator message", got.Message)
+		}
+	})
+
+	t.Run("nil result keeps default", func(t *testing.T) {
+		complete := &types.EventEnvelope{Type: types.EventTypeRunComplete, Payload: map[string]any{}}
+		if got, changed := applyOutcomeEvaluator(partialEvaluator, success, complete, ExitCodeCompleted); changed || got != success {
+			t.Errorf("expected unchanged outcome, got %+v", got)
+		}
+	})
+
+	t.Run("cannot turn crash into success", func(t *testing.T) {
+		crash := DetermineOutcome(ExitCodeCrash, false, nil)
+		alwaysSuccess := func(*types.EventEnvelope, int) *types.RunOutcome {
+			return &types.RunOutcome{Status: types.OutcomeSuccess}
+		}
+		if got, changed := applyOutcomeEvaluator(alwaysSuccess, crash, nil, ExitCodeCrash); changed || got != crash {
+			t.Errorf("expected crash to stand, got %+v", got)
+		}
+	})
+
+	t.Run("keeps status and message when omitted", func(t *testing.T) {
+		scriptErr := &types.RunOutcome{Status: types.OutcomeScriptError, Reason: types.ReasonScriptError, Message: "boom"}
+		relabel := func(*types.EventEnvelope, int) *types.RunOutcome {
+			return &types.RunOutcome{Reason: "retryable"}
+		}
+		got, _ := applyOutcomeEvaluator(relabel, scriptErr, nil, ExitCodeError)
+		if got.Status != types.OutcomeScriptError || got.Message != "boom" || got.Reason != "retryable" {
+			t.Errorf("got %+v, want script_error/retryable/boom", got)
+		}
+	})
+}
+
+func TestRunOrchestrator_OutcomeEvaluator(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-partial", Attempt: 1}
+	stream := encodeTestEventFrame(&types.EventEnvelope{
+		ContractVersion: types.ContractVersion,
+		EventID:         "evt-1",
+		RunID:           runMeta.RunID,
+		Seq:             1,
+		Type:            types.EventTypeRunComplete,
+		Ts:              "2024-01-01T00:00:00Z",
+		Payload:         map[string]any{"partial": true},
+		Attempt:         runMeta.Attempt,
+	})
+	collector := metrics.NewCollector("noop", "executor", "fs", runMeta.RunID, "")
+
+	config := &RunConfig{
+		ExecutorPath:     "/fake/executor",
+		ScriptPath:       "/fake/script.js",
+		Job:              map[string]any{},
+		RunMeta:          runMeta,
+		Policy:           policy.NewNoopPolicy(),
+		Collector:        collector,
+		OutcomeEvaluator: partialEvaluator,
+		ExecutorFactory: func(_ *ExecutorConfig) Executor {
+			return newMockExecutor(stream, 0)
+		},
+	}
+
+	orchestrator, err := NewRunOrchestrator(config)
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+
+	result, err := orchestrator.Execute(t.Context())
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	if result.Outcome.Status != types.OutcomeScriptError || result.Outcome.Reason != types.ReasonOutcomeEvaluator {
+		t.Fatalf("expected script_error/outcome_evaluator, got %s/%s", result.Outcome.Status, result.Outcome.Reason)
+	}
+	if snap := collector.Snapshot(); snap.RunsFailed != 1 || snap.RunsCompleted != 0 {
+		t.Errorf("expected runs_failed=1 runs_completed=0, got %d/%d", snap.RunsFailed, snap.RunsCompleted)
+	}
+}
+
+func TestRunOrchestrator_DecodeLimits(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-limits", Attempt: 1}
+	newConfig := func(limits ipc.DecodeLimits) *RunConfig {
+		return &RunConfig{
+			ExecutorPath: "/fake/executor",
+			ScriptPath:   "/fake/script.js",
+			RunMeta:      runMeta,
+			Policy:       newFlushTrackingPolicy(),
+			DecodeLimits: limits,
+			ExecutorFactory: func(_ *ExecutorConfig) Executor {
+				return newMockExecutor(makeValidEventStream(runMeta), 0)
+			},
+		}
+	}
+
+	if _, err := NewRunOrchestrator(newConfig(ipc.DecodeLimits{MaxDepth: -1})); err == nil || !strings.Contains(err.Error(), "invalid decode limits") {
+		t.Errorf("err = %v, want invalid decode limits", err)
+	}
+
+	// Event payloads nest below the envelope map, so depth 1 rejects them
+	orchestrator, err := NewRunOrchestrator(newConfig(ipc.DecodeLimits{MaxDepth: 1}))
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	result, err := orchestrator.Execute(t.Context())
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if result.Outcome.Status != types.OutcomeExecutorCrash || !strings.Contains(result.Outcome.Message, "decode limit exceeded") {
+		t.Errorf("outcome = %s: %s, want executor_crash on a frame beyond decode limits", result.Outcome.Status, result.Outcome.Message)
+	}
+}
+
+func TestRunOrchestrator_MaxFrameBytes(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-frames", Attempt: 1}
+	newConfig := func(maxFrameBytes int64) *RunConfig {
+		return &RunConfig{
+			ExecutorPath:  "/fake/executor",
+			ScriptPath:    "/fake/script.js",
+			RunMeta:       runMeta,
+			Policy:        newFlushTrackingPolicy(),
+			MaxFrameBytes: maxFrameBytes,
+			ExecutorFactory: func(_ *ExecutorConfig) Executor {
+				return newMockExecutor(makeValidEventStream(runMeta), 0)
+			},
+		}
+	}
+
+	if _, err := NewRunOrchestrator(newConfig(ipc.MaxFrameSizeCeiling + 1)); err == nil || !strings.Contains(err.Error(), "invalid max frame bytes") {
+		t.Errorf("err = %v, want invalid max frame bytes", err)
+	}
+
+	// A limit below the stream's frames fails the run with a stream error
+	orchestrator, err := NewRunOrchestrator(newConfig(16))
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	result, err := orchestrator.Execute(t.Context())
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if result.Outcome.Status != types.OutcomeExecutorCrash || !strings.Contains(result.Outcome.Message, "exceeds maximum 12") {
+		t.Errorf("outcome = %s: %s, want executor_crash on oversize frame", result.Outcome.Status, result.Outcome.Message)
+	}
+}
@@ -0,0 +1,75 @@
+package runtime
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Run ID generation keywords accepted by quarry run --run-id.
+const (
+	// RunIDAuto derives a stable ID from the run inputs (see DeriveRunID).
+	RunIDAuto = "auto"
+	// RunIDRandom generates a fresh ULID (see NewRandomRunID).
+	RunIDRandom = "random"
+)
+
+// runIDDerivationVersion prefixes the derivation input. Changing the
+// derivation requires a new version so existing IDs stay reproducible.
+const runIDDerivationVersion = "quarry.run_id.v1"
+
+// DeriveRunID returns the deterministic run ID for source, category, day
+// (YYYY-MM-DD) and job payload:
+//
+//	job_hash = hex(sha256(json(job)))        // object keys sorted; nil job = {}
+//	digest   = sha256("quarry.run_id.v1" NUL source NUL category NUL day NUL job_hash)
+//	run_id   = "run-" + hex(digest)[:32]
+//
+// The same inputs always yield the same ID, so re-running a job on the same
+// day hits the idempotency guard instead of writing a second run.
+func DeriveRunID(source, category, day string, job map[string]any) (string, error) {
+	if job == nil {
+		job = map[string]any{}
+	}
+	payload, err := json.Marshal(job)
+	if err != nil {
+		return "", fmt.Errorf("derive run_id: encoding job: %w", err)
+	}
+	jobHash := sha256.Sum256(payload)
+
+	input := strings.Join([]string{
+		runIDDerivationVersion, source, category, day, hex.EncodeToString(jobHash[:]),
+	}, "\x00")
+	digest := sha256.Sum256([]byte(input))
+	return "run-" + hex.EncodeToString(digest[:])[:32], nil
+}
+
+// crockford is the ULID base32 alphabet.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// NewRandomRunID returns a ULID: a 48-bit millisecond timestamp followed by
+// 80 random bits, as 26 Crockford base32 characters. IDs sort by creation
+// time to the millisecond.
+func NewRandomRunID(now time.Time) (string, error) {
+	var raw [16]byte
+	binary.BigEndian.PutUint64(raw[:8], uint64(now.UnixMilli())<<16)
+	if _, err := rand.Read(raw[6:]); err != nil {
+		return "", fmt.Errorf("generate run_id: %w", err)
+	}
+
+	// 128 bits as 26 5-bit groups, most significant first (2 leading pad bits)
+	hi := binary.BigEndian.Uint64(raw[:8])
+	lo := binary.BigEndian.Uint64(raw[8:])
+	var out [26]byte
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:]), nil
+}
@@ -0,0 +1,86 @@
+package runtime
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeriveRunID_Stable(t *testing.T) {
+	job := map[string]any{"url": "https://example.com", "page": float64(1)}
+	reordered := map[string]any{"page": float64(1), "url": "https://example.com"}
+
+	a, err := DeriveRunID("shop", "products", "2026-03-01", job)
+	if err != nil {
+		t.Fatalf("DeriveRunID: %v", err)
+	}
+	b, err := DeriveRunID("shop", "products", "2026-03-01", reordered)
+	if err != nil {
+		t.Fatalf("DeriveRunID: %v", err)
+	}
+	if a != b {
+		t.Errorf("same inputs gave %s and %s", a, b)
+	}
+	// Pinned: the derivation is documented and must not drift
+	if want := "run-185b3ce280403007e3f6151f153759eb"; a != want {
+		t.Errorf("run_id = %s, want %s", a, want)
+	}
+}
+
+func TestDeriveRunID_InputsDistinguish(t *testing.T) {
+	base, _ := DeriveRunID("shop", "products", "2026-03-01", map[string]any{"page": float64(1)})
+	variants := map[string][4]any{
+		"source":   {"shop2", "products", "2026-03-01", map[string]any{"page": float64(1)}},
+		"category": {"shop", "reviews", "2026-03-01", map[string]any{"page": float64(1)}},
+		"day":      {"shop", "products", "2026-03-02", map[string]any{"page": float64(1)}},
+		"job":      {"shop", "products", "2026-03-01", map[string]any{"page": float64(2)}},
+		// NUL separators keep field boundaries distinct
+		"boundary": {"shopp", "roducts", "2026-03-01", map[string]any{"page": float64(1)}},
+	}
+	for name, v := range variants {
+		got, err := DeriveRunID(v[0].(string), v[1].(string), v[2].(string), v[3].(map[string]any))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got == base {
+			t.Errorf("changing %s did not change the run_id", name)
+		}
+	}
+}
+
+func TestDeriveRunID_NilJobIsEmptyObject(t *testing.T) {
+	a, _ := DeriveRunID("s", "c", "2026-03-01", nil)
+	b, _ := DeriveRunID("s", "c", "2026-03-01", map[string]any{})
+	if a != b {
+		t.Errorf("nil job %s != empty job %s", a, b)
+	}
+}
+
+func TestNewRandomRunID_ULID(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	a, err := NewRandomRunID(now)
+	if err != nil {
+		t.Fatalf("NewRandomRunID: %v", err)
+	}
+	b, _ := NewRandomRunID(now)
+
+	if len(a) != 26 {
+		t.Fatalf("len = %d, want 26", len(a))
+	}
+	for _, r := range a {
+		if !strings.ContainsRune(crockford, r) {
+			t.Fatalf("%q has non-Crockford character %q", a, r)
+		}
+	}
+	if a == b {
+		t.Error("two random IDs collided")
+	}
+	// First 10 characters encode the timestamp
+	if a[:10] != b[:10] || a[:10] != "01HF7YAT00" {
+		t.Errorf("timestamp prefix = %s / %s, want 01HF7YAT00", a[:10], b[:10])
+	}
+	later, _ := NewRandomRunID(now.Add(time.Millisecond))
+	if later[:10] <= a[:10] {
+		t.Errorf("later ID %s does not sort after %s", later, a)
+	}
+}
@@ -0,0 +1,116 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pithecene-io/quarry/types"
+)
+
+// StderrFilename is the sidecar file holding the captured executor stderr,
+// written to the run partition's files/ prefix.
+const StderrFilename = "_stderr.log"
+
+// DefaultStderrMaxBytes caps the _stderr.log sidecar.
+const DefaultStderrMaxBytes = 1 << 20
+
+// DefaultStderrTailLines caps the executor stderr lines kept in
+// RunResult.StderrOutput.
+const DefaultStderrTailLines = 1000
+
+// stderrWriteTimeout bounds the stderr sidecar write.
+const stderrWriteTimeout = 30 * time.Second
+
+// writeStderr persists the captured executor stderr as the _stderr.log
+// sidecar when the run failed, or for every run with PersistStderr.
+// Best effort: a failed write is logged and does not fail the run.
+func (r *RunOrchestrator) writeStderr(ctx context.Context, result *RunResult) {
+	if r.config.FileWriter == nil || len(result.stderrLog) == 0 {
+		return
+	}
+	if !r.config.PersistStderr && result.Outcome.Status == types.OutcomeSuccess {
+		return
+	}
+
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), stderrWriteTimeout)
+	defer cancel()
+	if err := r.config.FileWriter.PutFile(writeCtx, StderrFilename, "text/plain; charset=utf-8", result.stderrLog); err != nil {
+		r.logger.Warn("failed to write executor stderr", map[string]any{
+			"error": err.Error(),
+		})
+	}
+}
+
+// executorStderr derives both views of the executor's captured stderr: the
+// last StderrTailLines lines for RunResult.StderrOutput, and the last
+// StderrMaxBytes bytes for the _stderr.log sidecar, so --persist-stderr
+// keeps far more than the summary prints.
+func (r *RunOrchestrator) executorStderr(res *ExecutorResult) (string, []byte) {
+	captured := res.StderrBytes
+	total := max(res.StderrTotalBytes, int64(len(captured)))
+	lines := max(res.StderrTotalLines, int64(bytes.Count(captured, []byte{'\n'}))+countPartialLine(captured))
+
+	tailLines := r.config.StderrTailLines
+	if tailLines <= 0 {
+		tailLines = DefaultStderrTailLines
+	}
+	headDropped := total > int64(len(captured))
+	return tailStderrLines(captured, headDropped, lines, tailLines),
+		truncateStderr(captured, total, stderrMaxBytes(r.config.StderrMaxBytes))
+}
+
+// stderrMaxBytes resolves the stderr byte cap (0 = DefaultStderrMaxBytes).
+func stderrMaxBytes(n int64) int64 {
+	if n <= 0 {
+		return DefaultStderrMaxBytes
+	}
+	return n
+}
+
+// countPartialLine is 1 when b ends in an unterminated line, else 0.
+func countPartialLine(b []byte) int64 {
+	if len(b) > 0 && b[len(b)-1] != '\n' {
+		return 1
+	}
+	return 0
+}
+
+// truncateStderr keeps the last maxBytes of the captured stderr, where crash
+// output lands, behind a marker line naming the kept and total sizes. total
+// is the size of the whole stream, which exceeds len(stderr) when the
+// capture already dropped its head.
+func truncateStderr(stderr []byte, total, maxBytes int64) []byte {
+	kept := min(int64(len(stderr)), maxBytes)
+	if kept >= total {
+		return stderr
+	}
+	marker := fmt.Sprintf("[quarry: stderr truncated, last %d of %d bytes kept]\n", kept, total)
+	out := make([]byte, 0, int64(len(marker))+kept)
+	out = append(out, marker...)
+	return append(out, stderr[int64(len(stderr))-kept:]...)
+}
+
+// tailStderrLines keeps the last n lines of the captured stderr behind a
+// marker line naming the kept and total line counts. When headDropped, the
+// capture starts mid-line and that fragment is discarded.
+func tailStderrLines(stderr []byte, headDropped bool, totalLines int64, n int) string {
+	s := string(stderr)
+	if headDropped {
+		_, s, _ = strings.Cut(s, "\n")
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	if int64(len(lines)) >= totalLines {
+		return s
+	}
+	marker := fmt.Sprintf("[quarry: stderr truncated, last %d of %d lines kept]\n", len(lines), totalLines)
+	return marker + strings.Join(lines, "")
+}
@@ -0,0 +1,166 @@
+package runtime
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pithecene-io/quarry/lode"
+	"github.com/pithecene-io/quarry/types"
+)
+
+func runWithStderr(t *testing.T, stdout []byte, exitCode int, persist bool) (*RunResult, *lode.StubFileWriter) {
+	t.Helper()
+	runMeta := &types.RunMeta{RunID: "run-stderr", Attempt: 1}
+	fw := lode.NewStubFileWriter()
+
+	orchestrator, err := NewRunOrchestrator(&RunConfig{
+		ExecutorPath:  "/fake/executor",
+		ScriptPath:    "/fake/script.js",
+		RunMeta:       runMeta,
+		Policy:        newFlushTrackingPolicy(),
+		FileWriter:    fw,
+		PersistStderr: persist,
+		ExecutorFactory: func(_ *ExecutorConfig) Executor {
+			exec := newMockExecutor(stdout, exitCode)
+			exec.stderr = []byte("TypeError: boom\n    at main (script.js:3)\n")
+			return exec
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	result, err := orchestrator.Execute(t.Context())
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	return result, fw
+}
+
+func TestRunOrchestrator_FailedRunWritesStderr(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-stderr", Attempt: 1}
+	result, fw := runWithStderr(t, makePartialEventStream(runMeta, 1), 2, false)
+
+	if result.Outcome.Status == types.OutcomeSuccess {
+		t.Fatalf("expected a failed outcome, got %s", result.Outcome.Status)
+	}
+	if len(fw.Files) != 1 || fw.Files[0].Filename != StderrFilename {
+		t.Fatalf("expected one %s write, got %+v", StderrFilename, fw.Files)
+	}
+	if !strings.Contains(string(fw.Files[0].Data), "TypeError: boom") {
+		t.Errorf("stderr sidecar = %q, want executor stderr", fw.Files[0].Data)
+	}
+}
+
+func TestRunOrchestrator_SuccessSkipsStderrByDefault(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-stderr", Attempt: 1}
+	result, fw := runWithStderr(t, makeValidEventStream(runMeta), 0, false)
+
+	if result.Outcome.Status != types.OutcomeSuccess {
+		t.Fatalf("expected success, got %s", result.Outcome.Status)
+	}
+	if len(fw.Files) != 0 {
+		t.Errorf("expected no sidecar for a successful run, got %+v", fw.Files)
+	}
+}
+
+func TestRunOrchestrator_PersistStderrOnSuccess(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-stderr", Attempt: 1}
+	_, fw := runWithStderr(t, makeValidEventStream(runMeta), 0, true)
+
+	if len(fw.Files) != 1 || fw.Files[0].Filename != StderrFilename {
+		t.Fatalf("expected one %s write with PersistStderr, got %+v", StderrFilename, fw.Files)
+	}
+}
+
+func TestTruncateStderr(t *testing.T) {
+	if got := truncateStderr([]byte("short"), 5, 10); string(got) != "short" {
+		t.Errorf("within bound: got %q", got)
+	}
+
+	got := string(truncateStderr([]byte("0123456789abcdef"), 16, 6))
+	if !strings.HasPrefix(got, "[quarry: stderr truncated, last 6 of 16 bytes kept]\n") {
+		t.Errorf("missing truncation marker: %q", got)
+	}
+	if !strings.HasSuffix(got, "abcdef") {
+		t.Errorf("expected the tail to be kept, got %q", got)
+	}
+
+	// The capture already dropped the head: the marker reports the stream size.
+	got = string(truncateStderr([]byte("abcdef"), 16, 10))
+	if got != "[quarry: stderr truncated, last 6 of 16 bytes kept]\nabcdef" {
+		t.Errorf("dropped head: got %q", got)
+	}
+}
+
+func TestTailStderrLines(t *testing.T) {
+	tests := []struct {
+		name        string
+		stderr      string
+		headDropped bool
+		total       int64
+		n           int
+		want        string
+	}{
+		{"within bound", "a\nb\n", false, 2, 5, "a\nb\n"},
+		{"unterminated last line", "a\nb\nc", false, 3, 2, "[quarry: stderr truncated, last 2 of 3 lines kept]\nb\nc"},
+		{"keeps last lines", "a\nb\nc\nd\n", false, 4, 2, "[quarry: stderr truncated, last 2 of 4 lines kept]\nc\nd\n"},
+		{"drops partial head", "xx\nc\nd\n", true, 9, 5, "[quarry: stderr truncated, last 2 of 9 lines kept]\nc\nd\n"},
+		{"empty", "", false, 0, 5, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tailStderrLines([]byte(tt.stderr), tt.headDropped, tt.total, tt.n); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTailBuffer_CountsWholeStream(t *testing.T) {
+	b := &tailBuffer{max: 4}
+	_, _ = b.Write([]byte("one\ntwo\n"))
+	_, _ = b.Write([]byte("three"))
+	if b.String() != "hree" || b.total != 13 || b.newlines != 2 {
+		t.Errorf("tail %q total %d newlines %d, want \"hree\" 13 2", b.String(), b.total, b.newlines)
+	}
+}
+
+func TestRunOrchestrator_StderrTailLines(t *testing.T) {
+	runMeta := &types.RunMeta{RunID: "run-stderr", Attempt: 1}
+	fw := lode.NewStubFileWriter()
+	var stderr strings.Builder
+	for i := range 50 {
+		fmt.Fprintf(&stderr, "line %d\n", i)
+	}
+
+	orchestrator, err := NewRunOrchestrator(&RunConfig{
+		ExecutorPath:    "/fake/executor",
+		ScriptPath:      "/fake/script.js",
+		RunMeta:         runMeta,
+		Policy:          newFlushTrackingPolicy(),
+		FileWriter:      fw,
+		PersistStderr:   true,
+		StderrTailLines: 3,
+		ExecutorFactory: func(_ *ExecutorConfig) Executor {
+			exec := newMockExecutor(makeValidEventStream(runMeta), 0)
+			exec.stderr = []byte(stderr.String())
+			return exec
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create orchestrator: %v", err)
+	}
+	result, err := orchestrator.Execute(t.Context())
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	want := "[quarry: stderr truncated, last 3 of 50 lines kept]\nline 47\nline 48\nline 49\n"
+	if result.StderrOutput != want {
+		t.Errorf("StderrOutput = %q, want %q", result.StderrOutput, want)
+	}
+	if len(fw.Files) != 1 || string(fw.Files[0].Data) != stderr.String() {
+		t.Errorf("expected the full stream in %s, got %+v", StderrFilename, fw.Files)
+	}
+}
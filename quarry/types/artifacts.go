@@ -15,6 +15,9 @@ type ArtifactChunkFrame struct {
 	IsLast bool `msgpack:"is_last"`
 	// Data is the raw binary data.
 	Data []byte `msgpack:"data"`
+	// CRC32C is the optional CRC-32C (Castagnoli) checksum of Data.
+	// Verified only when artifact verification is enabled.
+	CRC32C *uint32 `msgpack:"crc32c,omitempty"`
 }
 
 // ArtifactChunk is an internal representation of a chunk (after decoding).
@@ -23,6 +26,7 @@ type ArtifactChunk struct {
 	Seq        int64
 	IsLast     bool
 	Data       []byte
+	CRC32C     *uint32
 }
 
 // ArtifactAccumulator tracks chunks for a single artifact.
@@ -0,0 +1,29 @@
+//nolint:revive // types is a common Go package naming convention
+package types
+
+// DrainFrame represents a drain IPC frame.
+// Sent by the runtime to the executor via stdin to ask the script to stop
+// starting new work, finish what is in flight, and emit its terminal event.
+type DrainFrame struct {
+	// Type is always "drain" for drain frames.
+	Type string `msgpack:"type"`
+	// Reason describes why the runtime is draining (e.g. "shutdown"), if any.
+	Reason *string `msgpack:"reason,omitempty"`
+}
+
+// PauseFrame represents a pause IPC frame.
+// Sent by the runtime to the executor via stdin to ask the script to hold
+// further emits until a resume frame arrives.
+type PauseFrame struct {
+	// Type is always "pause" for pause frames.
+	Type string `msgpack:"type"`
+	// Reason describes why the runtime is pausing (e.g. "backpressure"), if any.
+	Reason *string `msgpack:"reason,omitempty"`
+}
+
+// ResumeFrame represents a resume IPC frame.
+// Sent by the runtime to the executor via stdin to lift a previous pause.
+type ResumeFrame struct {
+	// Type is always "resume" for resume frames.
+	Type string `msgpack:"type"`
+}
@@ -1,5 +1,7 @@
 package types
 
+import "fmt"
+
 // ContractVersion is the emit contract version per CONTRACT_EMIT.md.
 // This is an alias for the canonical Version to maintain backward compatibility.
 const ContractVersion = Version
@@ -35,6 +37,30 @@ const (
 	LogLevelError LogLevel = "error"
 )
 
+// logLevelSeverity orders the known log levels, lowest first.
+var logLevelSeverity = map[LogLevel]int{
+	LogLevelDebug: 0,
+	LogLevelInfo:  1,
+	LogLevelWarn:  2,
+	LogLevelError: 3,
+}
+
+// Severity returns the level's rank (debug < info < warn < error) and
+// whether the level is known.
+func (l LogLevel) Severity() (int, bool) {
+	s, ok := logLevelSeverity[l]
+	return s, ok
+}
+
+// ParseLogLevel validates s as one of debug, info, warn, error.
+func ParseLogLevel(s string) (LogLevel, error) {
+	l := LogLevel(s)
+	if _, ok := l.Severity(); !ok {
+		return "", fmt.Errorf("invalid log level %q: must be one of debug, info, warn, error", s)
+	}
+	return l, nil
+}
+
 // EventEnvelope is the envelope for all events per CONTRACT_EMIT.md.
 // All fields use msgpack tags to match the TypeScript SDK wire format.
 type EventEnvelope struct {
@@ -79,6 +105,11 @@ type ArtifactPayload struct {
 	ContentType string `msgpack:"content_type"`
 	// SizeBytes is the total size in bytes.
 	SizeBytes int64 `msgpack:"size_bytes"`
+	// RetentionClass is an optional retention label (e.g. "debug") that
+	// storage translates into object tags or a retention sidecar.
+	RetentionClass string `msgpack:"retention_class,omitempty"`
+	// TTLSeconds is an optional lifetime after commit, in seconds.
+	TTLSeconds int64 `msgpack:"ttl_seconds,omitempty"`
 }
 
 // CheckpointPayload represents a checkpoint event payload per CONTRACT_EMIT.md.
@@ -100,6 +131,8 @@ type EnqueuePayload struct {
 	Source string `msgpack:"source,omitempty"`
 	// Category is an optional partition override for the child run's category.
 	Category string `msgpack:"category,omitempty"`
+	// Priority is an optional fan-out dispatch priority (0-9, default 5).
+	Priority *int `msgpack:"priority,omitempty"`
 }
 
 // RotateProxyPayload represents a rotate_proxy event payload per CONTRACT_EMIT.md.
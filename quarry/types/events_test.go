@@ -4,6 +4,26 @@ import (
 	"testing"
 )
 
+func TestParseLogLevel(t *testing.T) {
+	for _, s := range []string{"debug", "info", "warn", "error"} {
+		if _, err := ParseLogLevel(s); err != nil {
+			t.Errorf("ParseLogLevel(%q) error: %v", s, err)
+		}
+	}
+	for _, s := range []string{"", "WARN", "warning", "fatal"} {
+		if _, err := ParseLogLevel(s); err == nil {
+			t.Errorf("ParseLogLevel(%q) accepted, want error", s)
+		}
+	}
+
+	debug, _ := LogLevelDebug.Severity()
+	warn, _ := LogLevelWarn.Severity()
+	errSev, _ := LogLevelError.Severity()
+	if !(debug < warn && warn < errSev) {
+		t.Errorf("severity order broken: debug=%d warn=%d error=%d", debug, warn, errSev)
+	}
+}
+
 func TestEventType_IsTerminal(t *testing.T) {
 	tests := []struct {
 		eventType EventType
@@ -34,3 +34,15 @@ type FileWriteAckFrame struct {
 	// Error is the error message when OK is false. Nil on success.
 	Error *string `msgpack:"error,omitempty"`
 }
+
+// ProxyUpdateFrame represents a proxy_update IPC frame.
+// Sent by the runtime to the executor via stdin in response to a
+// rotate_proxy event, carrying the endpoint to use for the rest of the run.
+type ProxyUpdateFrame struct {
+	// Type is always "proxy_update" for proxy update frames.
+	Type string `msgpack:"type"`
+	// Endpoint is the newly selected proxy endpoint, including credentials.
+	Endpoint ProxyEndpoint `msgpack:"endpoint"`
+	// Reason echoes the rotate_proxy reason, if any.
+	Reason *string `msgpack:"reason,omitempty"`
+}
@@ -7,6 +7,7 @@ package types
 import (
 	"errors"
 	"fmt"
+	"regexp"
 )
 
 // RunMeta contains run identity and lineage metadata per CONTRACT_RUN.md.
@@ -19,12 +20,36 @@ type RunMeta struct {
 	ParentRunID *string
 	// Attempt is the attempt number. Starts at 1 for initial runs.
 	Attempt int
+	// Labels are user-supplied key/value tags (e.g. team, campaign) carried
+	// to storage, the run_completed event, and metrics. Nil if none.
+	Labels map[string]string
+}
+
+// MaxLabelValueLen bounds the length of a run label value in bytes.
+const MaxLabelValueLen = 256
+
+// labelKeyPattern constrains run label keys: a letter, then up to 62
+// letters, digits, '_', '.', or '-'.
+var labelKeyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]{0,62}$`)
+
+// ValidateLabels checks run label keys and values.
+func ValidateLabels(labels map[string]string) error {
+	for k, v := range labels {
+		if !labelKeyPattern.MatchString(k) {
+			return fmt.Errorf("invalid label key %q: must start with a letter and contain only letters, digits, '_', '.', '-' (max 63 chars)", k)
+		}
+		if len(v) > MaxLabelValueLen {
+			return fmt.Errorf("label %q value exceeds %d bytes", k, MaxLabelValueLen)
+		}
+	}
+	return nil
 }
 
 // Validate validates lineage rules per CONTRACT_RUN.md:
 //   - attempt >= 1
 //   - attempt == 1 => parent_run_id must be nil (initial run)
 //   - attempt > 1 => parent_run_id must be present (retry run)
+//   - parent_run_id must differ from run_id
 func (r *RunMeta) Validate() error {
 	if r.RunID == "" {
 		return errors.New("run_id must be non-empty")
@@ -42,7 +67,11 @@ func (r *RunMeta) Validate() error {
 		return fmt.Errorf("retry run (attempt=%d) must have parent_run_id", r.Attempt)
 	}
 
-	return nil
+	if r.ParentRunID != nil && *r.ParentRunID == r.RunID {
+		return fmt.Errorf("run_id %q must differ from parent_run_id", r.RunID)
+	}
+
+	return ValidateLabels(r.Labels)
 }
 
 // OutcomeStatus represents the final status of a run per CONTRACT_RUN.md.
@@ -61,10 +90,79 @@ const (
 	OutcomeVersionMismatch OutcomeStatus = "version_mismatch"
 )
 
+// OutcomeReason is a machine-readable refinement of OutcomeStatus.
+// Status stays authoritative for exit codes; Reason distinguishes the
+// failures a status collapses (e.g. the many kinds of executor_crash) so
+// consumers can drive retry and alerting logic without parsing messages.
+type OutcomeReason string
+
+const (
+	// ReasonCompleted: the run completed successfully.
+	ReasonCompleted OutcomeReason = "completed"
+	// ReasonScriptError: the script emitted run_error.
+	ReasonScriptError OutcomeReason = "script_error"
+	// ReasonExecutorCrash: the executor reported a crash (exit code 2).
+	ReasonExecutorCrash OutcomeReason = "executor_crash"
+	// ReasonInvalidInput: the executor rejected its input (exit code 3).
+	ReasonInvalidInput OutcomeReason = "invalid_input"
+	// ReasonUnexpectedExit: the executor exited with an unknown code.
+	ReasonUnexpectedExit OutcomeReason = "unexpected_exit"
+	// ReasonMissingTerminal: the executor exited 0 or 1 without a terminal event.
+	ReasonMissingTerminal OutcomeReason = "missing_terminal"
+	// ReasonStartFailure: the executor process could not be started.
+	ReasonStartFailure OutcomeReason = "start_failure"
+	// ReasonBrowserLaunch: the shared browser failed to launch before the
+	// run started; a retryable infrastructure failure.
+	ReasonBrowserLaunch OutcomeReason = "browser_launch"
+	// ReasonWaitError: waiting for the executor process failed.
+	ReasonWaitError OutcomeReason = "wait_error"
+	// ReasonTimeout: the stall watchdog fired or a deadline expired.
+	ReasonTimeout OutcomeReason = "timeout"
+	// ReasonCanceled: the run context was canceled.
+	ReasonCanceled OutcomeReason = "canceled"
+	// ReasonInterrupted: the run was canceled by SIGINT/SIGTERM (an operator
+	// kill, as opposed to a timeout).
+	ReasonInterrupted OutcomeReason = "interrupted"
+	// ReasonDrained: ingestion stopped for graceful shutdown before the terminal event.
+	ReasonDrained OutcomeReason = "drained"
+	// ReasonStreamTruncated: the IPC stream ended mid-frame.
+	ReasonStreamTruncated OutcomeReason = "stream_truncated"
+	// ReasonOversizeFrame: an IPC frame exceeded the size limit.
+	ReasonOversizeFrame OutcomeReason = "oversize_frame"
+	// ReasonDecodeError: an IPC frame could not be decoded.
+	ReasonDecodeError OutcomeReason = "decode_error"
+	// ReasonStreamError: any other stream violation (envelope, sequence, artifact).
+	ReasonStreamError OutcomeReason = "stream_error"
+	// ReasonChecksumMismatch: an artifact failed --verify-artifacts.
+	ReasonChecksumMismatch OutcomeReason = "checksum_mismatch"
+	// ReasonVersionMismatch: SDK/CLI contract version skew.
+	ReasonVersionMismatch OutcomeReason = "version_mismatch"
+	// ReasonPolicyFailure: the ingestion policy failed to accept data.
+	ReasonPolicyFailure OutcomeReason = "policy_failure"
+	// ReasonFlushFailure: the final policy flush failed.
+	ReasonFlushFailure OutcomeReason = "flush_failure"
+	// ReasonBudgetExceeded: a per-run artifact budget was exceeded.
+	ReasonBudgetExceeded OutcomeReason = "budget_exceeded"
+	// ReasonQuotaExceeded: the run exceeded its --max-run-bytes cap.
+	ReasonQuotaExceeded OutcomeReason = "quota_exceeded"
+	// ReasonEventsDropped: the --fail-on-drops gate tripped.
+	ReasonEventsDropped OutcomeReason = "events_dropped"
+	// ReasonPreRunHook: the pre-run hook vetoed the run.
+	ReasonPreRunHook OutcomeReason = "pre_run_hook"
+	// ReasonDomainDenied: the job or an enqueue targeted a domain blocked by
+	// the domain policy.
+	ReasonDomainDenied OutcomeReason = "domain_denied"
+	// ReasonOutcomeEvaluator: a RunConfig.OutcomeEvaluator refined the
+	// outcome without naming a reason.
+	ReasonOutcomeEvaluator OutcomeReason = "outcome_evaluator"
+)
+
 // RunOutcome represents the final outcome of a run.
 type RunOutcome struct {
 	// Status is the outcome classification.
 	Status OutcomeStatus
+	// Reason refines Status with the specific cause.
+	Reason OutcomeReason
 	// Message is a human-readable description.
 	Message string
 	// ErrorType is populated for script errors (from run_error payload).
@@ -32,6 +32,11 @@ func TestRunMeta_Validate(t *testing.T) {
 			meta:    RunMeta{RunID: "run-001", Attempt: 2, ParentRunID: nil},
 			wantErr: true,
 		},
+		{
+			name:    "retry run reusing parent run_id",
+			meta:    RunMeta{RunID: parent, Attempt: 2, ParentRunID: &parent},
+			wantErr: true,
+		},
 		{
 			name:    "valid initial run",
 			meta:    RunMeta{RunID: "run-001", Attempt: 1},
@@ -42,6 +47,21 @@ func TestRunMeta_Validate(t *testing.T) {
 			meta:    RunMeta{RunID: "run-002", Attempt: 2, ParentRunID: &parent},
 			wantErr: false,
 		},
+		{
+			name:    "valid labels",
+			meta:    RunMeta{RunID: "run-001", Attempt: 1, Labels: map[string]string{"team": "growth", "cost-center": "cc.42"}},
+			wantErr: false,
+		},
+		{
+			name:    "label key with space",
+			meta:    RunMeta{RunID: "run-001", Attempt: 1, Labels: map[string]string{"my team": "growth"}},
+			wantErr: true,
+		},
+		{
+			name:    "label key starting with digit",
+			meta:    RunMeta{RunID: "run-001", Attempt: 1, Labels: map[string]string{"1team": "growth"}},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -22,9 +22,10 @@ type ProxyStrategy string
 
 // Supported proxy selection strategies.
 const (
-	ProxyStrategyRoundRobin ProxyStrategy = "round_robin"
-	ProxyStrategyRandom     ProxyStrategy = "random"
-	ProxyStrategySticky     ProxyStrategy = "sticky"
+	ProxyStrategyRoundRobin         ProxyStrategy = "round_robin"
+	ProxyStrategyWeightedRoundRobin ProxyStrategy = "weighted_round_robin"
+	ProxyStrategyRandom             ProxyStrategy = "random"
+	ProxyStrategySticky             ProxyStrategy = "sticky"
 )
 
 // ProxyStickyScope determines what key is used for sticky assignment.
@@ -50,6 +51,18 @@ type ProxyEndpoint struct {
 	Username *string `json:"username,omitempty" msgpack:"username,omitempty" yaml:"username,omitempty"`
 	// Password is the optional password for authentication.
 	Password *string `json:"password,omitempty" msgpack:"password,omitempty" yaml:"password,omitempty"`
+	// Weight is the optional relative share of selections under the
+	// weighted_round_robin strategy. Zero or absent means 1.
+	Weight int `json:"weight,omitempty" msgpack:"weight,omitempty" yaml:"weight,omitempty"`
+}
+
+// EffectiveWeight returns the endpoint's selection weight, defaulting
+// zero to 1.
+func (p *ProxyEndpoint) EffectiveWeight() int {
+	if p.Weight <= 0 {
+		return 1
+	}
+	return p.Weight
 }
 
 // Validate validates a proxy endpoint per CONTRACT_PROXY.md hard validation rules.
@@ -79,6 +92,10 @@ func (p *ProxyEndpoint) Validate() error {
 		return errors.New("username and password must be provided together")
 	}
 
+	if p.Weight < 0 {
+		return fmt.Errorf("invalid weight %d: must be >= 0", p.Weight)
+	}
+
 	return nil
 }
 
@@ -133,10 +150,10 @@ func (p *ProxyPool) Validate() error {
 	}
 
 	switch p.Strategy {
-	case ProxyStrategyRoundRobin, ProxyStrategyRandom, ProxyStrategySticky:
+	case ProxyStrategyRoundRobin, ProxyStrategyWeightedRoundRobin, ProxyStrategyRandom, ProxyStrategySticky:
 		// valid
 	default:
-		return fmt.Errorf("invalid strategy %q: must be round_robin, random, or sticky", p.Strategy)
+		return fmt.Errorf("invalid strategy %q: must be round_robin, weighted_round_robin, random, or sticky", p.Strategy)
 	}
 
 	if len(p.Endpoints) == 0 {
@@ -211,6 +228,16 @@ func (p *ProxyPool) Warnings() []string {
 		warnings = append(warnings, fmt.Sprintf("pool %q has recency_window set but strategy is %q; recency window only applies to random selection", p.Name, p.Strategy))
 	}
 
+	// Endpoint weights on a strategy that ignores them
+	if p.Strategy != ProxyStrategyWeightedRoundRobin {
+		for _, ep := range p.Endpoints {
+			if ep.Weight != 0 {
+				warnings = append(warnings, fmt.Sprintf("pool %q has endpoint weights but strategy is %q; weights only apply to weighted_round_robin", p.Name, p.Strategy))
+				break
+			}
+		}
+	}
+
 	// Check endpoint warnings
 	hasSocks5 := false
 	for _, ep := range p.Endpoints {
@@ -21,6 +21,32 @@ func TestProxyEndpoint_Validate_EmptyHost(t *testing.T) {
 	}
 }
 
+func TestProxyEndpoint_Validate_NegativeWeight(t *testing.T) {
+	ep := &ProxyEndpoint{
+		Protocol: ProxyProtocolHTTP,
+		Host:     "proxy.example.com",
+		Port:     8080,
+		Weight:   -1,
+	}
+
+	err := ep.Validate()
+	if err == nil {
+		t.Fatal("expected validation error for negative weight")
+	}
+	if !strings.Contains(err.Error(), "weight") {
+		t.Errorf("error should mention weight, got: %v", err)
+	}
+}
+
+func TestProxyEndpoint_EffectiveWeight(t *testing.T) {
+	for weight, want := range map[int]int{0: 1, 1: 1, 7: 7} {
+		ep := &ProxyEndpoint{Weight: weight}
+		if got := ep.EffectiveWeight(); got != want {
+			t.Errorf("EffectiveWeight() with weight %d = %d, want %d", weight, got, want)
+		}
+	}
+}
+
 func TestProxyEndpoint_Warnings_Socks5(t *testing.T) {
 	// socks5 should generate a warning
 	ep := &ProxyEndpoint{
@@ -220,6 +246,30 @@ func TestProxyPool_Warnings_RecencyWindow_NonRandom(t *testing.T) {
 	}
 }
 
+func TestProxyPool_Warnings_WeightsWithoutWeightedStrategy(t *testing.T) {
+	pool := &ProxyPool{
+		Name:     "weighted",
+		Strategy: ProxyStrategyRoundRobin,
+		Endpoints: []ProxyEndpoint{
+			{Protocol: ProxyProtocolHTTP, Host: "p1.example.com", Port: 8080, Weight: 3},
+			{Protocol: ProxyProtocolHTTP, Host: "p2.example.com", Port: 8080},
+		},
+	}
+	if err := pool.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	warnings := pool.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "weighted_round_robin") {
+		t.Errorf("expected weights warning, got %v", warnings)
+	}
+
+	pool.Strategy = ProxyStrategyWeightedRoundRobin
+	if warnings := pool.Warnings(); len(warnings) != 0 {
+		t.Errorf("expected 0 warnings for weighted_round_robin, got %v", warnings)
+	}
+}
+
 func TestProxyPool_Warnings_RecencyWindow_Random_NoWarning(t *testing.T) {
 	w := 3
 	pool := &ProxyPool{
@@ -41,4 +41,7 @@ type RunResultFrame struct {
 	Outcome RunResultOutcome `msgpack:"outcome"`
 	// ProxyUsed is the redacted proxy endpoint (no password).
 	ProxyUsed *ProxyEndpointRedacted `msgpack:"proxy_used,omitempty"`
+	// ExecutorVersion is the contract version the executor was built
+	// against. Empty for executors that predate the field.
+	ExecutorVersion string `msgpack:"executor_version,omitempty"`
 }
@@ -13,6 +13,8 @@ import type { QuarryContext, RunMeta } from './types/context'
  */
 export type CreateContextOptions<Job = unknown> = {
   job: Job
+  /** Initial state from --seed-state, if any. */
+  seedState?: Record<string, unknown>
   run: RunMeta
   page: Page
   browser: Browser
@@ -41,6 +43,7 @@ export function createContext<Job = unknown>(
 
   const ctx: QuarryContext<Job> = {
     job: options.job,
+    ...(options.seedState !== undefined && { seedState: Object.freeze(options.seedState) }),
     run: Object.freeze(options.run),
     page: options.page,
     browser: options.browser,
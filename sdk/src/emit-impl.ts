@@ -1,4 +1,4 @@
-import { randomUUID } from 'node:crypto'
+import { createHash, randomUUID } from 'node:crypto'
 import type {
   EmitAPI,
   EmitArtifactOptions,
@@ -55,9 +55,11 @@ export class StorageFilenameError extends Error {
  * Must exactly match Go's buildFilePath() in quarry/lode/file_writer.go.
  *
  * Format: datasets/{dataset}/partitions/source={source}/category={category}/day={day}/run_id={runID}/files/{filename}
+ * Hourly partitions add hour={hour} after day={day}.
  */
 export function buildStorageKey(partition: StoragePartitionMeta, filename: string): string {
-  return `datasets/${partition.dataset}/partitions/source=${partition.source}/category=${partition.category}/day=${partition.day}/run_id=${partition.run_id}/files/${filename}`
+  const hour = partition.hour ? `/hour=${partition.hour}` : ''
+  return `datasets/${partition.dataset}/partitions/source=${partition.source}/category=${partition.category}/day=${partition.day}${hour}/run_id=${partition.run_id}/files/${filename}`
 }
 
 /**
@@ -185,7 +187,10 @@ export function createAPIs(
           artifact_id,
           name: options.name,
           content_type: options.content_type,
-          size_bytes
+          size_bytes,
+          sha256: createHash('sha256').update(options.data).digest('hex'),
+          ...(options.retention_class !== undefined && { retention_class: options.retention_class }),
+          ...(options.ttl_seconds !== undefined && { ttl_seconds: options.ttl_seconds })
         })
         await writeEnvelope(envelope)
 
@@ -205,7 +210,8 @@ export function createAPIs(
         target: options.target,
         params: options.params,
         ...(options.source !== undefined && { source: options.source }),
-        ...(options.category !== undefined && { category: options.category })
+        ...(options.category !== undefined && { category: options.category }),
+        ...(options.priority !== undefined && { priority: options.priority })
       })
     },
 
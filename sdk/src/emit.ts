@@ -21,6 +21,10 @@ export type EmitArtifactOptions = {
   content_type: string
   /** The binary data (Buffer or Uint8Array) */
   data: Buffer | Uint8Array
+  /** Optional retention label (1-64 chars of [A-Za-z0-9._-]), e.g. "debug" */
+  retention_class?: string
+  /** Optional positive lifetime after commit, in seconds */
+  ttl_seconds?: number
 }
 
 /**
@@ -45,6 +49,8 @@ export type EmitEnqueueOptions = {
   source?: string
   /** Optional category partition override for the child run */
   category?: string
+  /** Optional fan-out dispatch priority, 0-9 (higher runs first; default 5) */
+  priority?: number
 }
 
 /**
@@ -206,6 +212,8 @@ export type StoragePartitionMeta = {
   readonly source: string
   readonly category: string
   readonly day: string
+  /** Partition hour (HH UTC); present only for hourly partitions. */
+  readonly hour?: string
   readonly run_id: string
 }
 
@@ -30,7 +30,12 @@ export type ProxyValidationResult = {
 }
 
 const VALID_PROTOCOLS: readonly ProxyProtocol[] = ['http', 'https', 'socks5']
-const VALID_STRATEGIES: readonly ProxyStrategy[] = ['round_robin', 'random', 'sticky']
+const VALID_STRATEGIES: readonly ProxyStrategy[] = [
+  'round_robin',
+  'weighted_round_robin',
+  'random',
+  'sticky'
+]
 const MIN_PORT = 1
 const MAX_PORT = 65535
 const LARGE_POOL_THRESHOLD = 100
@@ -62,6 +67,7 @@ function buildValidationResult(
  * - port is within 1-65535
  * - protocol is one of http|https|socks5
  * - username and password must be provided together if either is set
+ * - weight, if set, is a non-negative integer
  */
 export function validateProxyEndpoint(endpoint: ProxyEndpoint, prefix = ''): ProxyValidationResult {
   const errors: ProxyValidationError[] = []
@@ -110,6 +116,16 @@ export function validateProxyEndpoint(endpoint: ProxyEndpoint, prefix = ''): Pro
     )
   }
 
+  // Weight validation
+  if (
+    endpoint.weight !== undefined &&
+    (typeof endpoint.weight !== 'number' ||
+      !Number.isInteger(endpoint.weight) ||
+      endpoint.weight < 0)
+  ) {
+    errors.push(validationError(`${fieldPrefix}weight`, 'Weight must be a non-negative integer'))
+  }
+
   // Soft warning: socks5 with Puppeteer
   if (endpoint.protocol === 'socks5') {
     warnings.push(
@@ -130,6 +146,7 @@ export function validateProxyEndpoint(endpoint: ProxyEndpoint, prefix = ''): Pro
  *
  * Soft warnings:
  * - very large endpoint lists with round_robin
+ * - endpoint weights on a strategy other than weighted_round_robin
  */
 export function validateProxyPool(pool: ProxyPool): ProxyValidationResult {
   const errors: ProxyValidationError[] = []
@@ -172,6 +189,19 @@ export function validateProxyPool(pool: ProxyPool): ProxyValidationResult {
     }
   }
 
+  // Soft warning: endpoint weights are only used by weighted_round_robin
+  if (
+    pool.strategy !== 'weighted_round_robin' &&
+    pool.endpoints?.some((endpoint) => endpoint.weight !== undefined && endpoint.weight !== 0)
+  ) {
+    warnings.push(
+      validationWarning(
+        'endpoints',
+        `Endpoint weights are set but strategy is "${pool.strategy}"; weights only apply to weighted_round_robin`
+      )
+    )
+  }
+
   // Recency window validation
   if (pool.recencyWindow !== undefined) {
     if (
@@ -32,6 +32,13 @@ export type QuarryContext<Job = unknown> = {
    */
   readonly job: Job
 
+  /**
+   * Initial state handed to the run via `quarry run --seed-state`, kept
+   * separate from the job payload (e.g. a pagination cursor from another
+   * system). Undefined when not provided; never set for fan-out children.
+   */
+  readonly seedState?: Readonly<Record<string, unknown>>
+
   /**
    * Run metadata (run_id, job_id, attempt, etc.)
    */
@@ -66,6 +66,12 @@ export type ArtifactPayload = {
   content_type: string
   /** Total size in bytes */
   size_bytes: number
+  /** Lowercase hex SHA-256 of the artifact bytes, checked by `--verify-artifacts` */
+  sha256?: string
+  /** Optional retention label (e.g. "debug") applied by storage */
+  retention_class?: string
+  /** Optional lifetime after commit, in seconds */
+  ttl_seconds?: number
 }
 
 /**
@@ -92,6 +98,8 @@ export type EnqueuePayload = {
   source?: string
   /** Optional category partition override for the child run */
   category?: string
+  /** Optional fan-out dispatch priority, 0-9 (higher runs first; default 5) */
+  priority?: number
 }
 
 /**
@@ -11,7 +11,7 @@ export type ProxyProtocol = 'http' | 'https' | 'socks5'
 /**
  * Proxy selection strategies for pools.
  */
-export type ProxyStrategy = 'round_robin' | 'random' | 'sticky'
+export type ProxyStrategy = 'round_robin' | 'weighted_round_robin' | 'random' | 'sticky'
 
 /**
  * A resolved proxy endpoint the executor can dial.
@@ -28,6 +28,8 @@ export type ProxyEndpoint = {
   readonly username?: string
   /** Optional password for authentication */
   readonly password?: string
+  /** Optional relative share of selections under weighted_round_robin (0 or absent = 1) */
+  readonly weight?: number
 }
 
 /**
@@ -98,6 +98,41 @@ describe('emit.artifact() envelope correctness', () => {
     expect(sink.envelopes[0].payload).toMatchObject({ size_bytes: 11 })
   })
 
+  it('includes the sha256 of the data', async () => {
+    const emit = createEmitAPI(run, sink)
+
+    await emit.artifact({
+      name: 'test.txt',
+      content_type: 'text/plain',
+      data: Buffer.from('hello world')
+    })
+
+    expect(sink.envelopes[0].payload).toMatchObject({
+      sha256: 'b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9'
+    })
+  })
+
+  it('includes retention hints only when set', async () => {
+    const emit = createEmitAPI(run, sink)
+
+    await emit.artifact({
+      name: 'debug.png',
+      content_type: 'image/png',
+      data: Buffer.from('x'),
+      retention_class: 'debug',
+      ttl_seconds: 86400
+    })
+    await emit.artifact({
+      name: 'report.pdf',
+      content_type: 'application/pdf',
+      data: Buffer.from('y')
+    })
+
+    expect(sink.envelopes[0].payload).toMatchObject({ retention_class: 'debug', ttl_seconds: 86400 })
+    expect(sink.envelopes[1].payload).not.toHaveProperty('retention_class')
+    expect(sink.envelopes[1].payload).not.toHaveProperty('ttl_seconds')
+  })
+
   it('works with Uint8Array data', async () => {
     const emit = createEmitAPI(run, sink)
     const data = new Uint8Array([1, 2, 3, 4, 5])
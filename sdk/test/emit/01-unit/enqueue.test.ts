@@ -135,5 +135,17 @@ describe('emit.enqueue() envelope correctness', () => {
     const payload = sink.envelopes[0].payload as Record<string, unknown>
     expect(payload).not.toHaveProperty('source')
     expect(payload).not.toHaveProperty('category')
+    expect(payload).not.toHaveProperty('priority')
+  })
+
+  it('includes priority when provided', async () => {
+    const emit = createEmitAPI(run, sink)
+
+    await emit.enqueue({ target: 'detail', params: {}, priority: 9 })
+
+    expect(sink.envelopes[0].payload).toMatchObject({
+      target: 'detail',
+      priority: 9
+    })
   })
 })
@@ -47,6 +47,13 @@ describe('buildStorageKey()', () => {
       'datasets/custom-ds/partitions/source=my-source/category=screenshots/day=2026-02-23/run_id=run-001/files/page.png'
     )
   })
+
+  it('adds the hour segment for hourly partitions', () => {
+    const key = buildStorageKey({ ...testPartition, hour: '07' }, 'page.png')
+    expect(key).toBe(
+      'datasets/quarry/partitions/source=my-source/category=default/day=2026-02-23/hour=07/run_id=run-001/files/page.png'
+    )
+  })
 })
 
 describe('storage.put() return value', () => {
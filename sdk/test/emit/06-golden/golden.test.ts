@@ -244,7 +244,7 @@ describe('golden schema invariants', () => {
     expect(Object.keys(payload).sort()).toEqual(['data', 'item_type'])
   })
 
-  it('artifact payload has exactly artifact_id, name, content_type, size_bytes', async () => {
+  it('artifact payload has exactly artifact_id, name, content_type, size_bytes, sha256', async () => {
     const sink = new FakeSink()
     const emit = createEmitAPI(createDeterministicRunMeta(), sink)
 
@@ -255,6 +255,7 @@ describe('golden schema invariants', () => {
       'artifact_id',
       'content_type',
       'name',
+      'sha256',
       'size_bytes'
     ])
   })
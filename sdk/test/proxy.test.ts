@@ -115,6 +115,14 @@ describe('validateProxyEndpoint', () => {
     expect(result.errors[0].field).toContain('username')
     expect(result.errors[0].field).toContain('password')
   })
+
+  it('negative weight returns invalid', () => {
+    const result = validateProxyEndpoint(validEndpoint({ weight: -1 }))
+
+    expect(result.valid).toBe(false)
+    expect(result.errors).toHaveLength(1)
+    expect(result.errors[0].field).toBe('weight')
+  })
 })
 
 describe('validateProxyPool', () => {